@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 
 	"menlo.ai/jan-api-gateway/app/domain/apikey"
 	"menlo.ai/jan-api-gateway/app/domain/invite"
@@ -247,6 +249,26 @@ func (s *AuthService) RegisteredUserMiddleware() gin.HandlerFunc {
 	}
 }
 
+// ResolveAppUser returns the user behind the request's JWT or API key without rejecting the
+// request, for routes where authentication is optional.
+func (s *AuthService) ResolveAppUser(reqCtx *gin.Context) (*user.User, bool) {
+	if u, ok := GetUserFromContext(reqCtx); ok && u != nil {
+		return u, true
+	}
+	userPublicId, ok := s.getUserPublicIDFromJWT(reqCtx)
+	if !ok {
+		userPublicId, ok = s.getUserIDFromApikey(reqCtx)
+	}
+	if !ok || userPublicId == "" {
+		return nil, false
+	}
+	u, err := s.userService.FindByPublicID(reqCtx.Request.Context(), userPublicId)
+	if err != nil || u == nil {
+		return nil, false
+	}
+	return u, true
+}
+
 var OrganizationMemberRuleOwnerOnly = map[string]bool{
 	string(organization.OrganizationMemberRoleOwner): true,
 }
@@ -308,6 +330,49 @@ func (s *AuthService) OrganizationMemberRoleMiddleware(rolesAllowed map[string]b
 	}
 }
 
+// ErrCodeNotPlatformAdmin is returned with 403 when a user who is not a platform admin calls a
+// platform admin endpoint.
+const ErrCodeNotPlatformAdmin = "d41a7e93-5c2f-4b86-a0e8-93f6c1b27d4a"
+
+// PlatformAdminMiddleware admits only platform admins, the users listed in PLATFORM_ADMIN_EMAILS.
+// It must run after RegisteredUserMiddleware.
+func (s *AuthService) PlatformAdminMiddleware() gin.HandlerFunc {
+	return func(reqCtx *gin.Context) {
+		user, ok := GetUserFromContext(reqCtx)
+		if !ok {
+			reqCtx.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{
+				Code: "7c2e9f14-b6a8-4d53-9e07-1f4b8a6d2c95",
+			})
+			return
+		}
+		if !IsPlatformAdmin(user) {
+			reqCtx.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{
+				Code:  ErrCodeNotPlatformAdmin,
+				Error: "platform admin access required",
+			})
+			return
+		}
+		reqCtx.Next()
+	}
+}
+
+// IsPlatformAdmin reports whether the user's email is listed in PLATFORM_ADMIN_EMAILS.
+func IsPlatformAdmin(u *user.User) bool {
+	if u == nil {
+		return false
+	}
+	email := strings.TrimSpace(u.Email)
+	if email == "" {
+		return false
+	}
+	for _, adminEmail := range environment_variables.EnvironmentVariables.PLATFORM_ADMIN_EMAILS {
+		if strings.EqualFold(strings.TrimSpace(adminEmail), email) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *AuthService) getUserPublicIDFromJWT(reqCtx *gin.Context) (string, bool) {
 	tokenString, ok := requests.GetTokenFromBearer(reqCtx)
 	if !ok {
@@ -327,27 +392,46 @@ func (s *AuthService) getUserPublicIDFromJWT(reqCtx *gin.Context) (string, bool)
 }
 
 func (s *AuthService) getUserIDFromApikey(reqCtx *gin.Context) (string, bool) {
-	tokenString, ok := requests.GetTokenFromBearer(reqCtx)
+	apikeyEntity, ok := s.getAppApikey(reqCtx)
 	if !ok {
 		return "", false
 	}
-	if !strings.HasPrefix(tokenString, apikey.ApikeyPrefix) {
-		return "", false
-	}
-	token, ok := requests.GetTokenFromBearer(reqCtx)
+	return apikeyEntity.OwnerPublicID, true
+}
+
+// getAppApikey returns the non-admin API key the request is authenticated with.
+func (s *AuthService) getAppApikey(reqCtx *gin.Context) (*apikey.ApiKey, bool) {
+	tokenString, ok := requests.GetTokenFromBearer(reqCtx)
 	if !ok {
-		return "", false
+		return nil, false
+	}
+	if !strings.HasPrefix(tokenString, apikey.ApikeyPrefix) {
+		return nil, false
 	}
 	ctx := reqCtx.Request.Context()
-	hashed := s.apiKeyService.HashKey(reqCtx, token)
+	hashed := s.apiKeyService.HashKey(reqCtx, tokenString)
 	apikeyEntity, err := s.apiKeyService.FindByKeyHash(ctx, hashed)
 	if err != nil {
-		return "", false
+		return nil, false
 	}
 	if apikeyEntity == nil || apikeyEntity.ApikeyType == string(apikey.ApikeyTypeAdmin) {
-		return "", false
+		return nil, false
 	}
-	return apikeyEntity.OwnerPublicID, true
+	return apikeyEntity, true
+}
+
+// ResolveApikeyProject returns the project of the project-scoped API key the request is
+// authenticated with, or nil when the request carries no such key.
+func (s *AuthService) ResolveApikeyProject(reqCtx *gin.Context) (*project.Project, error) {
+	apikeyEntity, ok := s.getAppApikey(reqCtx)
+	if !ok || apikeyEntity.ProjectID == nil {
+		return nil, nil
+	}
+	proj, err := s.projectService.FindProjectByID(reqCtx.Request.Context(), *apikeyEntity.ProjectID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return proj, err
 }
 
 func (s *AuthService) getUserIDFromAdminkey(reqCtx *gin.Context) (string, bool) {
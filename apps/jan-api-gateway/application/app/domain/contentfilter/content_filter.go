@@ -0,0 +1,103 @@
+package contentfilter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+const (
+	// MetadataKeyFilters lists the filters applied to a provider, e.g. "email,ssn".
+	MetadataKeyFilters = "content_filters"
+	// MetadataKeyFilterResponse enables filtering of non-streaming responses as well as requests.
+	// Streamed responses are never filtered.
+	MetadataKeyFilterResponse = "content_filter_response"
+)
+
+// Filter rewrites message content before it leaves (or returns to) the gateway.
+type Filter interface {
+	Name() string
+	// Apply returns the rewritten content and the number of replacements made.
+	Apply(content string) (string, int)
+}
+
+// RegexRedactor replaces every match of a pattern with a fixed placeholder.
+type RegexRedactor struct {
+	name        string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func NewRegexRedactor(name string, pattern *regexp.Regexp, replacement string) *RegexRedactor {
+	return &RegexRedactor{
+		name:        name,
+		pattern:     pattern,
+		replacement: replacement,
+	}
+}
+
+func (r *RegexRedactor) Name() string {
+	return r.name
+}
+
+func (r *RegexRedactor) Apply(content string) (string, int) {
+	count := 0
+	result := r.pattern.ReplaceAllStringFunc(content, func(string) string {
+		count++
+		return r.replacement
+	})
+	return result, count
+}
+
+var builtinFilters = map[string]Filter{
+	"email": NewRegexRedactor("email", regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"),
+	"ssn":   NewRegexRedactor("ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), "[REDACTED_SSN]"),
+}
+
+// Policy is the set of filters configured for a provider.
+type Policy struct {
+	Filters         []Filter
+	ApplyToResponse bool
+}
+
+// Enabled reports whether the policy performs any filtering.
+func (p Policy) Enabled() bool {
+	return len(p.Filters) > 0
+}
+
+// PolicyForProvider reads the filter configuration from the provider metadata.
+// Unknown filter names are ignored; an empty policy means no filtering.
+func PolicyForProvider(provider *domainmodel.Provider) Policy {
+	policy := Policy{}
+	if provider == nil || len(provider.Metadata) == 0 {
+		return policy
+	}
+	for _, name := range strings.Split(provider.Metadata[MetadataKeyFilters], ",") {
+		if filter, ok := builtinFilters[strings.ToLower(strings.TrimSpace(name))]; ok {
+			policy.Filters = append(policy.Filters, filter)
+		}
+	}
+	if value, ok := provider.Metadata[MetadataKeyFilterResponse]; ok {
+		policy.ApplyToResponse, _ = strconv.ParseBool(value)
+	}
+	return policy
+}
+
+// Redaction records how many replacements a filter made, for auditing.
+type Redaction struct {
+	Filter string
+	Count  int
+}
+
+func (p Policy) apply(content string, counts map[string]int) string {
+	for _, filter := range p.Filters {
+		var n int
+		content, n = filter.Apply(content)
+		if n > 0 {
+			counts[filter.Name()] += n
+		}
+	}
+	return content
+}
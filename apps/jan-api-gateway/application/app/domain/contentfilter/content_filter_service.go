@@ -0,0 +1,106 @@
+package contentfilter
+
+import (
+	"context"
+	"slices"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/utils/contextkeys"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// ContentFilterService applies provider content-filter policies to completion traffic.
+type ContentFilterService struct {
+}
+
+func NewContentFilterService() *ContentFilterService {
+	return &ContentFilterService{}
+}
+
+// FilterRequest redacts the outgoing messages according to the provider policy. The messages are
+// replaced with redacted copies, so slices shared with the caller's original request (content
+// parts and tool calls) are never rewritten.
+func (s *ContentFilterService) FilterRequest(ctx context.Context, provider *domainmodel.Provider, request *openai.ChatCompletionRequest) []Redaction {
+	policy := PolicyForProvider(provider)
+	if !policy.Enabled() || request == nil {
+		return nil
+	}
+	request.Messages = cloneMessages(request.Messages)
+	counts := map[string]int{}
+	for i := range request.Messages {
+		filterMessage(policy, &request.Messages[i], counts)
+	}
+	redactions := toRedactions(counts)
+	s.audit(ctx, provider, "request", redactions)
+	return redactions
+}
+
+// FilterResponse redacts the returned choices in place when the policy opts into response filtering.
+// Streamed responses are relayed chunk by chunk and are not filtered.
+func (s *ContentFilterService) FilterResponse(ctx context.Context, provider *domainmodel.Provider, response *openai.ChatCompletionResponse) []Redaction {
+	policy := PolicyForProvider(provider)
+	if !policy.Enabled() || !policy.ApplyToResponse || response == nil {
+		return nil
+	}
+	counts := map[string]int{}
+	for i := range response.Choices {
+		filterMessage(policy, &response.Choices[i].Message, counts)
+	}
+	redactions := toRedactions(counts)
+	s.audit(ctx, provider, "response", redactions)
+	return redactions
+}
+
+func filterMessage(policy Policy, message *openai.ChatCompletionMessage, counts map[string]int) {
+	if message.Content != "" {
+		message.Content = policy.apply(message.Content, counts)
+	}
+	for i := range message.MultiContent {
+		if message.MultiContent[i].Text != "" {
+			message.MultiContent[i].Text = policy.apply(message.MultiContent[i].Text, counts)
+		}
+	}
+	for i := range message.ToolCalls {
+		if message.ToolCalls[i].Function.Arguments != "" {
+			message.ToolCalls[i].Function.Arguments = policy.apply(message.ToolCalls[i].Function.Arguments, counts)
+		}
+	}
+	if message.FunctionCall != nil && message.FunctionCall.Arguments != "" {
+		functionCall := *message.FunctionCall
+		functionCall.Arguments = policy.apply(functionCall.Arguments, counts)
+		message.FunctionCall = &functionCall
+	}
+}
+
+// cloneMessages copies the messages together with the content parts and tool calls that filtering
+// rewrites.
+func cloneMessages(messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	cloned := slices.Clone(messages)
+	for i := range cloned {
+		cloned[i].MultiContent = slices.Clone(cloned[i].MultiContent)
+		cloned[i].ToolCalls = slices.Clone(cloned[i].ToolCalls)
+	}
+	return cloned
+}
+
+func toRedactions(counts map[string]int) []Redaction {
+	redactions := make([]Redaction, 0, len(counts))
+	for name, count := range counts {
+		redactions = append(redactions, Redaction{Filter: name, Count: count})
+	}
+	return redactions
+}
+
+func (s *ContentFilterService) audit(ctx context.Context, provider *domainmodel.Provider, direction string, redactions []Redaction) {
+	for _, redaction := range redactions {
+		logger.GetLogger().WithFields(logrus.Fields{
+			"request_id":   ctx.Value(contextkeys.RequestId{}),
+			"provider_id":  provider.PublicID,
+			"direction":    direction,
+			"filter":       redaction.Filter,
+			"replacements": redaction.Count,
+		}).Info("content filter applied")
+	}
+}
@@ -0,0 +1,153 @@
+package contentfilter_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/contentfilter"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"resty.dev/v3"
+)
+
+const prompt = "Mail jane.doe@example.com about SSN 123-45-6789 and bob@example.org."
+
+func filteredProvider(filters string, response bool) *domainmodel.Provider {
+	provider := &domainmodel.Provider{PublicID: "prov-filtered", Metadata: map[string]string{}}
+	if filters != "" {
+		provider.Metadata[contentfilter.MetadataKeyFilters] = filters
+	}
+	if response {
+		provider.Metadata[contentfilter.MetadataKeyFilterResponse] = "true"
+	}
+	return provider
+}
+
+func TestFilterRequestRedactsUpstreamRequest(t *testing.T) {
+	var upstreamBody string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		upstreamBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","choices":[]}`))
+	}))
+	defer upstream.Close()
+
+	request := openai.ChatCompletionRequest{
+		Model: "gpt-test",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: prompt},
+			}},
+		},
+	}
+	service := contentfilter.NewContentFilterService()
+	redactions := service.FilterRequest(context.Background(), filteredProvider("email, SSN", false), &request)
+
+	client := chatclient.NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+	if _, err := client.CreateChatCompletion(context.Background(), "", request); err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+	for _, leaked := range []string{"jane.doe@example.com", "bob@example.org", "123-45-6789"} {
+		if strings.Contains(upstreamBody, leaked) {
+			t.Fatalf("upstream request contains %q: %s", leaked, upstreamBody)
+		}
+	}
+	if !strings.Contains(upstreamBody, "Mail [REDACTED_EMAIL] about SSN [REDACTED_SSN] and [REDACTED_EMAIL].") {
+		t.Fatalf("upstream request is missing the redacted prompt: %s", upstreamBody)
+	}
+
+	slices.SortFunc(redactions, func(a, b contentfilter.Redaction) int { return strings.Compare(a.Filter, b.Filter) })
+	want := []contentfilter.Redaction{{Filter: "email", Count: 2}, {Filter: "ssn", Count: 1}}
+	if !slices.Equal(redactions, want) {
+		t.Fatalf("redactions = %v, want %v", redactions, want)
+	}
+}
+
+func TestFilterRequestWithoutPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *domainmodel.Provider
+	}{
+		{name: "no provider"},
+		{name: "no filters", provider: filteredProvider("", false)},
+		{name: "unknown filter", provider: filteredProvider("phone", false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: prompt}}}
+			redactions := contentfilter.NewContentFilterService().FilterRequest(context.Background(), tt.provider, &request)
+			if len(redactions) != 0 || request.Messages[0].Content != prompt {
+				t.Fatalf("request was filtered to %q", request.Messages[0].Content)
+			}
+		})
+	}
+}
+
+func TestFilterResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *domainmodel.Provider
+		want     string
+	}{
+		{name: "request-only policy", provider: filteredProvider("email", false), want: prompt},
+		{name: "response filtering enabled", provider: filteredProvider("email", true), want: "Mail [REDACTED_EMAIL] about SSN 123-45-6789 and [REDACTED_EMAIL]."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: prompt}},
+			}}
+			contentfilter.NewContentFilterService().FilterResponse(context.Background(), tt.provider, &response)
+			if got := response.Choices[0].Message.Content; got != tt.want {
+				t.Fatalf("response content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRequestLeavesTheCallerRequestUntouched(t *testing.T) {
+	arguments := `{"to":"jane.doe@example.com"}`
+	request := openai.ChatCompletionRequest{
+		Model: "gpt-test",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: prompt},
+			}},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{
+				{ID: "call-1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "send_mail", Arguments: arguments}},
+			}},
+			{Role: openai.ChatMessageRoleAssistant, FunctionCall: &openai.FunctionCall{Name: "send_mail", Arguments: arguments}},
+		},
+	}
+
+	attempt := request
+	contentfilter.NewContentFilterService().FilterRequest(context.Background(), filteredProvider("email", false), &attempt)
+
+	if got := attempt.Messages[0].MultiContent[0].Text; got != "Mail [REDACTED_EMAIL] about SSN 123-45-6789 and [REDACTED_EMAIL]." {
+		t.Fatalf("filtered content part = %q", got)
+	}
+	if got := attempt.Messages[1].ToolCalls[0].Function.Arguments; got != `{"to":"[REDACTED_EMAIL]"}` {
+		t.Fatalf("filtered tool call arguments = %q", got)
+	}
+	if got := attempt.Messages[2].FunctionCall.Arguments; got != `{"to":"[REDACTED_EMAIL]"}` {
+		t.Fatalf("filtered function call arguments = %q", got)
+	}
+
+	if got := request.Messages[0].MultiContent[0].Text; got != prompt {
+		t.Fatalf("caller content part was rewritten to %q", got)
+	}
+	if got := request.Messages[1].ToolCalls[0].Function.Arguments; got != arguments {
+		t.Fatalf("caller tool call arguments were rewritten to %q", got)
+	}
+	if got := request.Messages[2].FunctionCall.Arguments; got != arguments {
+		t.Fatalf("caller function call arguments were rewritten to %q", got)
+	}
+}
@@ -98,6 +98,7 @@ type Item struct {
 	IncompleteDetails *IncompleteDetails `json:"incomplete_details,omitempty"`
 	CompletedAt       *time.Time         `json:"completed_at,omitempty"`
 	ResponseID        *uint              `json:"-"`
+	ParentItemID      *uint              `json:"-"` // previous item on the item's branch; nil follows the linear history
 	CreatedAt         time.Time          `json:"created_at"`
 }
 
@@ -203,6 +204,9 @@ type ConversationRepository interface {
 	Update(ctx context.Context, conversation *Conversation) error
 	Delete(ctx context.Context, id uint) error
 	DeleteByWorkspacePublicID(ctx context.Context, workspacePublicID string) error
+	// CountByWorkspacePublicIDs counts the conversations of each workspace in a single query;
+	// workspaces without conversations are absent from the result.
+	CountByWorkspacePublicIDs(ctx context.Context, workspacePublicIDs []string) (map[string]int64, error)
 	AddItem(ctx context.Context, conversationID uint, item *Item) error
 	SearchItems(ctx context.Context, conversationID uint, query string) ([]*Item, error)
 	BulkAddItems(ctx context.Context, conversationID uint, items []*Item) error
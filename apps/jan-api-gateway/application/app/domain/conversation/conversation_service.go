@@ -1,7 +1,9 @@
 package conversation
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -189,6 +191,12 @@ func (s *ConversationService) AddItem(ctx context.Context, conversation *Convers
 
 // AddItemWithID adds an item to a conversation with a custom public ID
 func (s *ConversationService) AddItemWithID(ctx context.Context, conversation *Conversation, userID uint, itemType ItemType, role *ItemRole, content []Content, customPublicID string) (*Item, *common.Error) {
+	return s.AddBranchItemWithID(ctx, conversation, userID, itemType, role, content, customPublicID, nil)
+}
+
+// AddBranchItemWithID adds an item with a custom public ID as the child of parentItemID, starting or
+// extending a branch. A nil parent appends the item to the linear history.
+func (s *ConversationService) AddBranchItemWithID(ctx context.Context, conversation *Conversation, userID uint, itemType ItemType, role *ItemRole, content []Content, customPublicID string, parentItemID *uint) (*Item, *common.Error) {
 	// Check access permissions
 	if conversation.IsPrivate && conversation.UserID != userID {
 		return nil, common.NewErrorWithMessage("Private conversation access denied", "n4o5p6q7-r8s9-0123-nopq-456789012345")
@@ -199,11 +207,12 @@ func (s *ConversationService) AddItemWithID(ctx context.Context, conversation *C
 	}
 
 	item := &Item{
-		PublicID: customPublicID,
-		Type:     itemType,
-		Role:     role,
-		Content:  content,
-		Status:   ToItemStatusPtr(ItemStatusCompleted),
+		PublicID:     customPublicID,
+		Type:         itemType,
+		Role:         role,
+		Content:      content,
+		Status:       ToItemStatusPtr(ItemStatusCompleted),
+		ParentItemID: parentItemID,
 	}
 
 	if err := s.conversationRepo.AddItem(ctx, conversation.ID, item); err != nil {
@@ -218,6 +227,51 @@ func (s *ConversationService) AddItemWithID(ctx context.Context, conversation *C
 	return item, nil
 }
 
+// ErrCodeBranchItemNotFound is returned by GetBranchItems when the conversation has no item with the
+// requested public ID.
+const ErrCodeBranchItemNotFound = "2e9b7d3a-6f1c-4a85-9b4e-d0c6a8f2e371"
+
+// GetBranchItems returns the ancestor chain ending at the item with the given public ID, oldest first.
+// Items with a parent follow it; items without one follow the item created before them.
+func (s *ConversationService) GetBranchItems(ctx context.Context, conversation *Conversation, itemPublicID string) ([]*Item, *common.Error) {
+	items, err := s.itemRepo.FindByConversationID(ctx, conversation.ID)
+	if err != nil {
+		return nil, common.NewError(err, "8c4f2a6e-1d9b-4e73-a5c0-b7e3d9f1a248")
+	}
+
+	positions := make(map[uint]int, len(items))
+	current := -1
+	for i, item := range items {
+		positions[item.ID] = i
+		if item.PublicID == itemPublicID {
+			current = i
+		}
+	}
+	if current < 0 {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("Item with ID '%s' not found in conversation", itemPublicID), ErrCodeBranchItemNotFound)
+	}
+
+	var chain []*Item
+	visited := make(map[uint]bool, len(items))
+	for current >= 0 && !visited[items[current].ID] {
+		item := items[current]
+		visited[item.ID] = true
+		chain = append(chain, item)
+		if item.ParentItemID == nil {
+			current--
+			continue
+		}
+		parent, ok := positions[*item.ParentItemID]
+		if !ok {
+			break
+		}
+		current = parent
+	}
+
+	slices.Reverse(chain)
+	return chain, nil
+}
+
 // DeleteItemWithConversation deletes an item by its ID and updates the conversation accordingly.
 func (s *ConversationService) DeleteItemWithConversation(ctx context.Context, conversation *Conversation, item *Item) (*Item, *common.Error) {
 	if err := s.itemRepo.Delete(ctx, item.ID); err != nil {
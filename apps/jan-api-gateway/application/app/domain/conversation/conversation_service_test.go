@@ -0,0 +1,81 @@
+package conversation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/conversation"
+)
+
+// itemsRepository serves FindByConversationID from a fixed list of items.
+type itemsRepository struct {
+	conversation.ItemRepository
+	items []*conversation.Item
+	err   error
+}
+
+func (r *itemsRepository) FindByConversationID(ctx context.Context, conversationID uint) ([]*conversation.Item, error) {
+	return r.items, r.err
+}
+
+func TestGetBranchItems(t *testing.T) {
+	ctx := context.Background()
+	parent := func(id uint) *uint { return &id }
+	// 1 ← 2 ← 3 is the original history; 4 branches from 1 and 5 follows 4 linearly.
+	items := []*conversation.Item{
+		{ID: 1, PublicID: "msg_1"},
+		{ID: 2, PublicID: "msg_2", ParentItemID: parent(1)},
+		{ID: 3, PublicID: "msg_3", ParentItemID: parent(2)},
+		{ID: 4, PublicID: "msg_4", ParentItemID: parent(1)},
+		{ID: 5, PublicID: "msg_5"},
+	}
+
+	tests := []struct {
+		name string
+		item string
+		want []string
+	}{
+		{name: "first item", item: "msg_1", want: []string{"msg_1"}},
+		{name: "original branch", item: "msg_3", want: []string{"msg_1", "msg_2", "msg_3"}},
+		{name: "branch skips the sibling history", item: "msg_4", want: []string{"msg_1", "msg_4"}},
+		{name: "item without parent follows the previous item", item: "msg_5", want: []string{"msg_1", "msg_4", "msg_5"}},
+	}
+	service := conversation.NewService(nil, &itemsRepository{items: items})
+	conv := &conversation.Conversation{ID: 1}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, err := service.GetBranchItems(ctx, conv, tt.item)
+			if err != nil {
+				t.Fatalf("GetBranchItems: %v", err)
+			}
+			got := make([]string, 0, len(chain))
+			for _, item := range chain {
+				got = append(got, item.PublicID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("chain = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("chain = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("unknown item", func(t *testing.T) {
+		_, err := service.GetBranchItems(ctx, conv, "msg_missing")
+		if err == nil || err.GetCode() != conversation.ErrCodeBranchItemNotFound {
+			t.Fatalf("GetBranchItems error = %v, want not found", err)
+		}
+	})
+
+	t.Run("repository failure is not reported as not found", func(t *testing.T) {
+		failing := conversation.NewService(nil, &itemsRepository{err: errors.New("connection reset")})
+		_, err := failing.GetBranchItems(ctx, conv, "msg_1")
+		if err == nil || err.GetCode() == conversation.ErrCodeBranchItemNotFound {
+			t.Fatalf("GetBranchItems error = %v, want a repository error", err)
+		}
+	})
+}
@@ -2,21 +2,80 @@ package cron
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/mileusna/crontab"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/config/environment_variables"
 )
 
+const (
+	defaultRefreshSchedule             = "* * * * *"
+	defaultProviderHealthCheckSchedule = "*/5 * * * *"
+)
+
 type CronService struct {
+	providerHealthChecker *domainmodel.ProviderHealthChecker
 }
 
-func NewCronService() *CronService {
-	return &CronService{}
+func NewCronService(providerHealthChecker *domainmodel.ProviderHealthChecker) *CronService {
+	return &CronService{
+		providerHealthChecker: providerHealthChecker,
+	}
 }
 
-func (cs *CronService) Start(ctx context.Context, ctab *crontab.Crontab) {
-
-	ctab.AddJob("* * * * *", func() {
+// Start schedules the background jobs and returns an error when a configured schedule is
+// invalid, since without its job configuration changes or provider outages would silently go
+// unnoticed. Each run is delayed by a random jitter so replicas do not run their jobs at the same
+// instant.
+func (cs *CronService) Start(ctx context.Context, ctab *crontab.Crontab) error {
+	refreshSchedule := refreshSchedule()
+	if err := ctab.AddJob(refreshSchedule, func() {
+		if !Jitter(ctx) {
+			return
+		}
 		environment_variables.EnvironmentVariables.LoadFromEnv()
-	})
+	}); err != nil {
+		return fmt.Errorf("invalid MODELS_REFRESH_CRON %q: %w", refreshSchedule, err)
+	}
+
+	healthCheckSchedule := providerHealthCheckSchedule()
+	if err := ctab.AddJob(healthCheckSchedule, func() {
+		if !Jitter(ctx) {
+			return
+		}
+		cs.providerHealthChecker.CheckAll(ctx)
+	}); err != nil {
+		return fmt.Errorf("invalid PROVIDER_HEALTH_CHECK_SCHEDULE %q: %w", healthCheckSchedule, err)
+	}
+	return nil
+}
+
+// ValidateSchedules checks MODELS_REFRESH_CRON and PROVIDER_HEALTH_CHECK_SCHEDULE so a
+// misconfigured schedule is reported when the configuration is loaded, before anything starts.
+func ValidateSchedules() error {
+	ctab := crontab.New()
+	defer ctab.Shutdown()
+	if err := ctab.AddJob(refreshSchedule(), func() {}); err != nil {
+		return fmt.Errorf("invalid MODELS_REFRESH_CRON %q: %w", refreshSchedule(), err)
+	}
+	if err := ctab.AddJob(providerHealthCheckSchedule(), func() {}); err != nil {
+		return fmt.Errorf("invalid PROVIDER_HEALTH_CHECK_SCHEDULE %q: %w", providerHealthCheckSchedule(), err)
+	}
+	return nil
+}
+
+func refreshSchedule() string {
+	if schedule := strings.TrimSpace(environment_variables.EnvironmentVariables.MODELS_REFRESH_CRON); schedule != "" {
+		return schedule
+	}
+	return defaultRefreshSchedule
+}
+
+func providerHealthCheckSchedule() string {
+	if schedule := strings.TrimSpace(environment_variables.EnvironmentVariables.PROVIDER_HEALTH_CHECK_SCHEDULE); schedule != "" {
+		return schedule
+	}
+	return defaultProviderHealthCheckSchedule
 }
@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// DefaultJitterWindow bounds the random delay before background jobs when CRON_JITTER_WINDOW is
+// unset.
+const DefaultJitterWindow = 20 * time.Second
+
+// JitterWindow returns CRON_JITTER_WINDOW, a Go duration such as 30s, falling back to the default
+// when it is unset or invalid. Zero disables the jitter.
+func JitterWindow() time.Duration {
+	value := strings.TrimSpace(environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW)
+	if value == "" {
+		return DefaultJitterWindow
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		logger.GetLogger().Errorf("invalid CRON_JITTER_WINDOW %q, using %s", value, DefaultJitterWindow)
+		return DefaultJitterWindow
+	}
+	return window
+}
+
+// jitterDelay returns a random delay in [0, window).
+func jitterDelay(window time.Duration) time.Duration {
+	if window <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(window)))
+}
+
+// Jitter waits a random delay within the jitter window. Every replica runs the cron jobs on the
+// same wall-clock boundaries, so without it they would all call the upstreams at once. It reports
+// false when ctx ends first.
+func Jitter(ctx context.Context) bool {
+	delay := jitterDelay(JitterWindow())
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,61 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestJitterDelay(t *testing.T) {
+	for _, window := range []time.Duration{time.Nanosecond, time.Millisecond, DefaultJitterWindow} {
+		for range 1000 {
+			if delay := jitterDelay(window); delay < 0 || delay >= window {
+				t.Fatalf("jitterDelay(%s) = %s, want within [0, %s)", window, delay, window)
+			}
+		}
+	}
+	for _, window := range []time.Duration{0, -time.Second} {
+		if delay := jitterDelay(window); delay != 0 {
+			t.Fatalf("jitterDelay(%s) = %s, want 0", window, delay)
+		}
+	}
+}
+
+func TestJitterWindow(t *testing.T) {
+	previous := environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW = previous })
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: DefaultJitterWindow},
+		{value: "45s", want: 45 * time.Second},
+		{value: "0", want: 0},
+		{value: "soon", want: DefaultJitterWindow},
+		{value: "-5s", want: DefaultJitterWindow},
+	}
+	for _, tt := range tests {
+		environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW = tt.value
+		if got := JitterWindow(); got != tt.want {
+			t.Errorf("JitterWindow() with %q = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestJitterWithoutWindow(t *testing.T) {
+	previous := environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW
+	environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW = "0"
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.CRON_JITTER_WINDOW = previous })
+
+	if !Jitter(context.Background()) {
+		t.Fatal("Jitter() = false with a zero window, want true")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if Jitter(ctx) {
+		t.Fatal("Jitter() = true for a cancelled context, want false")
+	}
+}
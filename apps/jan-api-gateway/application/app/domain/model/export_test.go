@@ -0,0 +1,13 @@
+package model
+
+import (
+	"context"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+// SyncProviderModelsPerRow exposes the per-row sync so tests can compare it with the batched one.
+func (s *ProviderRegistryService) SyncProviderModelsPerRow(ctx context.Context, provider *Provider, models []chatclient.Model) ([]ProviderModelSyncResult, *common.Error) {
+	return s.syncProviderModelsPerRow(ctx, provider, models)
+}
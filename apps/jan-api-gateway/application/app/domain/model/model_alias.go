@@ -0,0 +1,37 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/domain/query"
+)
+
+// ModelAlias maps a friendly model name within an organization to a concrete provider model.
+type ModelAlias struct {
+	ID             uint
+	PublicID       string
+	OrganizationID uint
+	Alias          string
+	ProviderID     uint
+	ModelKey       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// ModelAliasFilter defines optional conditions for querying model aliases.
+type ModelAliasFilter struct {
+	PublicID       *string
+	OrganizationID *uint
+	Alias          *string
+	ProviderID     *uint
+}
+
+// ModelAliasRepository abstracts persistence for model aliases.
+type ModelAliasRepository interface {
+	Create(ctx context.Context, alias *ModelAlias) error
+	Update(ctx context.Context, alias *ModelAlias) error
+	DeleteByID(ctx context.Context, id uint) error
+	FindByFilter(ctx context.Context, filter ModelAliasFilter, p *query.Pagination) ([]*ModelAlias, error)
+	Count(ctx context.Context, filter ModelAliasFilter) (int64, error)
+}
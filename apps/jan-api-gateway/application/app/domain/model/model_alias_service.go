@@ -0,0 +1,152 @@
+package model
+
+import (
+	"context"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/utils/idgen"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+// ErrCodeModelAliasNotFound is returned when an alias does not exist in the organization.
+const ErrCodeModelAliasNotFound = "3c9e7a1d-5f2b-4e86-a0d4-b8f1c6e2d975"
+
+// ErrCodeModelAliasExists is returned when an alias name is already used in the organization.
+const ErrCodeModelAliasExists = "e7b2d4f9-1a6c-4c38-9e05-d3a8f2b1c764"
+
+// ModelAliasService persists the per-organization model aliases.
+type ModelAliasService struct {
+	modelAliasRepo ModelAliasRepository
+}
+
+func NewModelAliasService(modelAliasRepo ModelAliasRepository) *ModelAliasService {
+	return &ModelAliasService{
+		modelAliasRepo: modelAliasRepo,
+	}
+}
+
+func (s *ModelAliasService) ListByOrganization(ctx context.Context, organizationID uint) ([]*ModelAlias, *common.Error) {
+	aliases, err := s.modelAliasRepo.FindByFilter(ctx, ModelAliasFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+	}, nil)
+	if err != nil {
+		return nil, common.NewError(err, "8d1f4b6a-2e9c-4a57-b3d0-f6c2e8a1d943")
+	}
+	return aliases, nil
+}
+
+func (s *ModelAliasService) FindByPublicID(ctx context.Context, organizationID uint, publicID string) (*ModelAlias, *common.Error) {
+	aliases, err := s.modelAliasRepo.FindByFilter(ctx, ModelAliasFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+		PublicID:       ptr.ToString(publicID),
+	}, nil)
+	if err != nil {
+		return nil, common.NewError(err, "b5e2a8c3-7d1f-4f94-8a6b-c0d9e3f7a218")
+	}
+	if len(aliases) == 0 {
+		return nil, common.NewErrorWithMessage("model alias not found", ErrCodeModelAliasNotFound)
+	}
+	return aliases[0], nil
+}
+
+// Resolve returns the alias with the given name in the organization, or nil when there is none.
+func (s *ModelAliasService) Resolve(ctx context.Context, organizationID uint, alias string) (*ModelAlias, error) {
+	name := strings.TrimSpace(alias)
+	if name == "" {
+		return nil, nil
+	}
+	aliases, err := s.modelAliasRepo.FindByFilter(ctx, ModelAliasFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+		Alias:          &name,
+	}, nil)
+	if err != nil || len(aliases) == 0 {
+		return nil, err
+	}
+	return aliases[0], nil
+}
+
+// CreateAlias maps alias to the model key on the provider. The caller checks that the provider
+// belongs to the organization and serves the model.
+func (s *ModelAliasService) CreateAlias(ctx context.Context, organizationID uint, alias string, provider *Provider, modelKey string) (*ModelAlias, *common.Error) {
+	name := strings.TrimSpace(alias)
+	if name == "" {
+		return nil, common.NewErrorWithMessage("alias is required", "4a7c2e9f-6b3d-4d15-a8e0-f1b5c9d2e386")
+	}
+	if err := s.ensureAliasAvailable(ctx, organizationID, name, 0); err != nil {
+		return nil, err
+	}
+	publicID, err := idgen.GenerateSecureID("malias", 24)
+	if err != nil {
+		return nil, common.NewError(err, "c2f8d6a4-9e1b-4b73-a5c0-e8d3f7b2a619")
+	}
+	entity := &ModelAlias{
+		PublicID:       publicID,
+		OrganizationID: organizationID,
+		Alias:          name,
+		ProviderID:     provider.ID,
+		ModelKey:       strings.TrimSpace(modelKey),
+	}
+	if err := s.modelAliasRepo.Create(ctx, entity); err != nil {
+		return nil, common.NewError(err, "f1d9b3e7-4c2a-4e68-9b05-a7e6c1d8f342")
+	}
+	return entity, nil
+}
+
+// UpdateAlias renames the alias and/or repoints it to another provider model.
+func (s *ModelAliasService) UpdateAlias(ctx context.Context, entity *ModelAlias, alias *string, provider *Provider, modelKey *string) (*ModelAlias, *common.Error) {
+	if alias != nil {
+		name := strings.TrimSpace(*alias)
+		if name == "" {
+			return nil, common.NewErrorWithMessage("alias is required", "9e3b5d7a-1f8c-4a26-b4e9-d2c6a0f8e157")
+		}
+		if name != entity.Alias {
+			if err := s.ensureAliasAvailable(ctx, entity.OrganizationID, name, entity.ID); err != nil {
+				return nil, err
+			}
+		}
+		entity.Alias = name
+	}
+	if provider != nil {
+		entity.ProviderID = provider.ID
+	}
+	if modelKey != nil {
+		entity.ModelKey = strings.TrimSpace(*modelKey)
+	}
+	if err := s.modelAliasRepo.Update(ctx, entity); err != nil {
+		return nil, common.NewError(err, "6a8e2c4f-3d7b-4f91-8c05-b1e9d4a7f263")
+	}
+	return entity, nil
+}
+
+func (s *ModelAliasService) DeleteAlias(ctx context.Context, entity *ModelAlias) *common.Error {
+	if err := s.modelAliasRepo.DeleteByID(ctx, entity.ID); err != nil {
+		return common.NewError(err, "d4b7f1a9-8c2e-4e53-a6d0-f9c3e5b8a142")
+	}
+	return nil
+}
+
+// DeleteByProviderID removes every alias that routes to the provider.
+func (s *ModelAliasService) DeleteByProviderID(ctx context.Context, providerID uint) *common.Error {
+	aliases, err := s.modelAliasRepo.FindByFilter(ctx, ModelAliasFilter{ProviderID: ptr.ToUint(providerID)}, nil)
+	if err != nil {
+		return common.NewError(err, "a3f6c1e8-9b4d-4d27-8e50-c2b7f9d4a316")
+	}
+	for _, alias := range aliases {
+		if err := s.DeleteAlias(ctx, alias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ModelAliasService) ensureAliasAvailable(ctx context.Context, organizationID uint, alias string, excludeID uint) *common.Error {
+	existing, err := s.Resolve(ctx, organizationID, alias)
+	if err != nil {
+		return common.NewError(err, "7f2c9e4b-5a1d-4b86-9e3f-c8a6d2b0e471")
+	}
+	if existing != nil && existing.ID != excludeID {
+		return common.NewErrorWithMessage("model alias already exists", ErrCodeModelAliasExists)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	decimal "github.com/shopspring/decimal"
@@ -10,8 +12,44 @@ import (
 
 // Parameters supported/defaults for a provider or model.
 type SupportedParameters struct {
-	Names   []string                    `json:"names"`   // e.g., ["include_reasoning","max_tokens",...]
-	Default map[string]*decimal.Decimal `json:"default"` // temperature/top_p/frequency_penalty, null allowed
+	Names   []string                   `json:"names"`   // e.g., ["include_reasoning","max_tokens",...]
+	Default map[string]*ParameterValue `json:"default"` // temperature/top_p/frequency_penalty, null allowed
+}
+
+// ParameterValue is a numeric parameter default that remembers whether it is integer-valued,
+// so a max_tokens default is sent upstream as 4 rather than 4.0 or "4".
+type ParameterValue struct {
+	Value   decimal.Decimal
+	Integer bool
+}
+
+// Any returns the value as an int64 for integer parameters and a float64 otherwise.
+func (p ParameterValue) Any() any {
+	if p.Integer {
+		return p.Value.IntPart()
+	}
+	return p.Value.InexactFloat64()
+}
+
+func (p ParameterValue) MarshalJSON() ([]byte, error) {
+	if p.Integer {
+		return []byte(strconv.FormatInt(p.Value.IntPart(), 10)), nil
+	}
+	// Keep a fractional digit on whole floats so they are not read back as integers.
+	if p.Value.IsInteger() {
+		return []byte(p.Value.StringFixed(1)), nil
+	}
+	return []byte(p.Value.String()), nil
+}
+
+// UnmarshalJSON accepts numbers as well as the quoted decimals stored by earlier versions.
+func (p *ParameterValue) UnmarshalJSON(data []byte) error {
+	if err := p.Value.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	literal := strings.Trim(string(data), `"`)
+	p.Integer = p.Value.IsInteger() && !strings.ContainsAny(literal, ".eE")
+	return nil
 }
 
 // Architecture metadata.
@@ -46,6 +84,10 @@ type ModelCatalog struct {
 	// Moderation at the model level (overrides provider if set)
 	IsModerated *bool `json:"is_moderated,omitempty"`
 
+	// Deprecation announced by the provider; either may be set alone. See IsDeprecated.
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationReason *string    `json:"deprecation_reason,omitempty"`
+
 	// Extensibility bucket for provider/model-specific extras
 	Extras map[string]any `json:"extras,omitempty"`
 
@@ -59,8 +101,10 @@ type ModelCatalog struct {
 type ModelCatalogFilter struct {
 	IDs              *[]uint
 	PublicID         *string
+	PublicIDs        *[]string
 	IsModerated      *bool
 	Status           *ModelCatalogStatus
+	Deprecated       *bool
 	LastSyncedAfter  *time.Time
 	LastSyncedBefore *time.Time
 }
@@ -69,6 +113,8 @@ type ModelCatalogFilter struct {
 type ModelCatalogRepository interface {
 	Create(ctx context.Context, catalog *ModelCatalog) error
 	Update(ctx context.Context, catalog *ModelCatalog) error
+	// BatchUpsert inserts catalogs without an ID and updates the rest, grouping writes by batchSize.
+	BatchUpsert(ctx context.Context, catalogs []*ModelCatalog, batchSize int) error
 	DeleteByID(ctx context.Context, id uint) error
 	FindByID(ctx context.Context, id uint) (*ModelCatalog, error)
 	FindByPublicID(ctx context.Context, publicID string) (*ModelCatalog, error)
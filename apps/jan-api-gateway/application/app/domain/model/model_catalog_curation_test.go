@@ -0,0 +1,105 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+func TestCuratedCatalogSurvivesSync(t *testing.T) {
+	ctx := context.Background()
+	registry := modeltest.NewRegistry()
+	kind := domainmodel.ProviderOpenAI
+	if _, err := registry.ModelCatalogService.BatchUpsertCatalogs(ctx, kind, []chatclient.Model{{ID: "gpt-4o"}, {ID: "gpt-4o-mini"}}); err != nil {
+		t.Fatalf("BatchUpsertCatalogs: %v", err)
+	}
+	catalog := func(publicID string) *domainmodel.ModelCatalog {
+		t.Helper()
+		for _, c := range registry.Catalogs.All() {
+			if c.PublicID == publicID {
+				return c
+			}
+		}
+		t.Fatalf("catalog %s was not synced", publicID)
+		return nil
+	}
+	if status := catalog("gpt-4o").Status; status != domainmodel.ModelCatalogStatusInit {
+		t.Fatalf("synced catalog status = %s, want init", status)
+	}
+
+	updated, err := registry.ModelCatalogService.UpdateCatalog(ctx, "gpt-4o", domainmodel.UpdateModelCatalogInput{
+		Notes:       ptr.ToString("Curated by the platform team."),
+		IsModerated: ptr.ToBool(true),
+	})
+	if err != nil {
+		t.Fatalf("UpdateCatalog: %v", err)
+	}
+	if updated.Status != domainmodel.ModelCatalogStatusUpdated {
+		t.Fatalf("edited catalog status = %s, want updated", updated.Status)
+	}
+
+	// Upstream now describes both models and reports them unmoderated.
+	upstream := []chatclient.Model{
+		{ID: "gpt-4o", Raw: map[string]any{"description": "Upstream description", "top_provider": map[string]any{"is_moderated": false}}},
+		{ID: "gpt-4o-mini", Raw: map[string]any{"description": "Upstream description", "top_provider": map[string]any{"is_moderated": false}}},
+	}
+	syncs := []struct {
+		name string
+		sync func() *common.Error
+	}{
+		{name: "upsert", sync: func() *common.Error {
+			for _, model := range upstream {
+				if _, err := registry.ModelCatalogService.UpsertCatalog(ctx, kind, model); err != nil {
+					return err
+				}
+			}
+			return nil
+		}},
+		{name: "batch upsert", sync: func() *common.Error {
+			_, err := registry.ModelCatalogService.BatchUpsertCatalogs(ctx, kind, upstream)
+			return err
+		}},
+	}
+	for _, tt := range syncs {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.sync(); err != nil {
+				t.Fatalf("sync: %v", err)
+			}
+			curated := catalog("gpt-4o")
+			if curated.Status != domainmodel.ModelCatalogStatusUpdated {
+				t.Fatalf("curated catalog status = %s, want updated", curated.Status)
+			}
+			if curated.Notes == nil || *curated.Notes != "Curated by the platform team." {
+				t.Fatalf("curated notes = %v, want the admin's notes", curated.Notes)
+			}
+			if curated.IsModerated == nil || !*curated.IsModerated {
+				t.Fatalf("curated is_moderated = %v, want the admin's override", curated.IsModerated)
+			}
+		})
+	}
+
+	// The uncurated entry shows the same syncs do overwrite catalogs nobody edited.
+	if notes := catalog("gpt-4o-mini").Notes; notes == nil || *notes != "Upstream description" {
+		t.Fatalf("uncurated notes = %v, want the upstream description", notes)
+	}
+
+	t.Run("only updated can be set", func(t *testing.T) {
+		status := domainmodel.ModelCatalogStatusInit
+		_, err := registry.ModelCatalogService.UpdateCatalog(ctx, "gpt-4o", domainmodel.UpdateModelCatalogInput{Status: &status})
+		if err == nil || err.GetCode() != domainmodel.ErrCodeInvalidModelCatalogStatus {
+			t.Fatalf("UpdateCatalog error = %v, want invalid status", err)
+		}
+	})
+
+	t.Run("unknown catalog", func(t *testing.T) {
+		_, err := registry.ModelCatalogService.UpdateCatalog(ctx, "missing", domainmodel.UpdateModelCatalogInput{Notes: ptr.ToString("x")})
+		if err == nil || err.GetCode() != domainmodel.ErrCodeModelCatalogNotFound {
+			t.Fatalf("UpdateCatalog error = %v, want not found", err)
+		}
+	})
+}
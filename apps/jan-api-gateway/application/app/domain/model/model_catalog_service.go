@@ -2,12 +2,21 @@ package model
 
 import (
 	"context"
+	"errors"
 
+	"gorm.io/gorm"
 	"menlo.ai/jan-api-gateway/app/domain/common"
 	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
 	"menlo.ai/jan-api-gateway/app/utils/ptr"
 )
 
+// ErrCodeModelCatalogNotFound is returned when a catalog entry does not exist.
+const ErrCodeModelCatalogNotFound = "6d1f8b3a-9c47-4e2d-a5b0-e3c7f2d8a914"
+
+// ErrCodeInvalidModelCatalogStatus is returned when a catalog is manually moved to a status other
+// than updated.
+const ErrCodeInvalidModelCatalogStatus = "b4e9a2c6-1f73-4d58-8e0b-7a3d5c9f1e62"
+
 type ModelCatalogService struct {
 	modelCatalogRepo ModelCatalogRepository
 }
@@ -18,6 +27,72 @@ func NewModelCatalogService(modelCatalogRepo ModelCatalogRepository) *ModelCatal
 	}
 }
 
+func (s *ModelCatalogService) DeleteByID(ctx context.Context, id uint) error {
+	return s.modelCatalogRepo.DeleteByID(ctx, id)
+}
+
+// FindByID returns the catalog entry, or nil when it does not exist.
+func (s *ModelCatalogService) FindByID(ctx context.Context, id uint) (*ModelCatalog, error) {
+	catalog, err := s.modelCatalogRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return catalog, err
+}
+
+// FindDeprecatedByIDs returns the deprecated catalog entries among the IDs, keyed by ID.
+func (s *ModelCatalogService) FindDeprecatedByIDs(ctx context.Context, ids []uint) (map[uint]*ModelCatalog, error) {
+	result := make(map[uint]*ModelCatalog)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	catalogs, err := s.modelCatalogRepo.FindByFilter(ctx, ModelCatalogFilter{IDs: &ids, Deprecated: ptr.ToBool(true)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, catalog := range catalogs {
+		result[catalog.ID] = catalog
+	}
+	return result, nil
+}
+
+// UpdateModelCatalogInput holds the curated fields of a catalog entry; nil fields are left as is.
+type UpdateModelCatalogInput struct {
+	Notes       *string
+	IsModerated *bool
+	Status      *ModelCatalogStatus
+}
+
+// UpdateCatalog applies an admin's edits to a catalog entry. The entry is marked updated, which
+// UpsertCatalog and BatchUpsertCatalogs never overwrite, so the edits survive later syncs.
+func (s *ModelCatalogService) UpdateCatalog(ctx context.Context, publicID string, input UpdateModelCatalogInput) (*ModelCatalog, *common.Error) {
+	if input.Status != nil && *input.Status != ModelCatalogStatusUpdated {
+		return nil, common.NewErrorWithMessage("status can only be set to updated", ErrCodeInvalidModelCatalogStatus)
+	}
+	catalog, err := s.modelCatalogRepo.FindByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, common.NewError(err, "2a7c5e9f-4b18-4d63-9f0e-c1d8b6a3e527")
+	}
+	if catalog == nil {
+		return nil, common.NewErrorWithMessage("model catalog not found", ErrCodeModelCatalogNotFound)
+	}
+
+	if input.Notes != nil {
+		catalog.Notes = input.Notes
+		if *input.Notes == "" {
+			catalog.Notes = nil
+		}
+	}
+	if input.IsModerated != nil {
+		catalog.IsModerated = input.IsModerated
+	}
+	catalog.Status = ModelCatalogStatusUpdated
+	if err := s.modelCatalogRepo.Update(ctx, catalog); err != nil {
+		return nil, common.NewError(err, "f8b3d1a6-7e25-4c9a-b4d0-5e2a9c7f3b18")
+	}
+	return catalog, nil
+}
+
 // UpsertCatalog ensures the catalog entry for the model exists and is up to date.
 func (s *ModelCatalogService) UpsertCatalog(ctx context.Context, kind ProviderKind, model chatclient.Model) (*ModelCatalog, *common.Error) {
 	publicID := catalogPublicID(model)
@@ -33,6 +108,11 @@ func (s *ModelCatalogService) UpsertCatalog(ctx context.Context, kind ProviderKi
 		catalog.ID = existing.ID
 		catalog.CreatedAt = existing.CreatedAt
 		if existing.Status == ModelCatalogStatusFilled || existing.Status == ModelCatalogStatusUpdated {
+			if refreshDeprecation(existing, catalog) {
+				if err := s.modelCatalogRepo.Update(ctx, existing); err != nil {
+					return nil, common.NewError(err, "d4b8e2a6-1f7c-4c93-a5e0-9b3f6d1c8a27")
+				}
+			}
 			return existing, nil
 		}
 		if catalog.Status == ModelCatalogStatusFilled && existing.Status == ModelCatalogStatusUpdated {
@@ -50,6 +130,105 @@ func (s *ModelCatalogService) UpsertCatalog(ctx context.Context, kind ProviderKi
 	return catalog, nil
 }
 
+// BatchUpsertCatalogs is the batched counterpart of UpsertCatalog used by large syncs.
+// The returned map is keyed by catalog public ID.
+func (s *ModelCatalogService) BatchUpsertCatalogs(ctx context.Context, kind ProviderKind, models []chatclient.Model) (map[string]*ModelCatalog, *common.Error) {
+	publicIDs := make([]string, 0, len(models))
+	built := make(map[string]*ModelCatalog, len(models))
+	for _, model := range models {
+		publicID := catalogPublicID(model)
+		if _, exists := built[publicID]; exists {
+			continue
+		}
+		catalog := buildModelCatalogFromModel(kind, model)
+		catalog.PublicID = publicID
+		built[publicID] = catalog
+		publicIDs = append(publicIDs, publicID)
+	}
+	if len(publicIDs) == 0 {
+		return map[string]*ModelCatalog{}, nil
+	}
+
+	existing, err := s.modelCatalogRepo.FindByFilter(ctx, ModelCatalogFilter{PublicIDs: &publicIDs}, nil)
+	if err != nil {
+		return nil, common.NewError(err, "c7d0f3a2-5e1b-4b8e-9a61-2f4c8d7e0b13")
+	}
+	existingByPublicID := make(map[string]*ModelCatalog, len(existing))
+	for _, catalog := range existing {
+		existingByPublicID[catalog.PublicID] = catalog
+	}
+
+	result := make(map[string]*ModelCatalog, len(publicIDs))
+	pending := make([]*ModelCatalog, 0, len(publicIDs))
+	for _, publicID := range publicIDs {
+		catalog := built[publicID]
+		if current, ok := existingByPublicID[publicID]; ok {
+			if current.Status == ModelCatalogStatusFilled || current.Status == ModelCatalogStatusUpdated {
+				if refreshDeprecation(current, catalog) {
+					pending = append(pending, current)
+				}
+				result[publicID] = current
+				continue
+			}
+			catalog.ID = current.ID
+			catalog.CreatedAt = current.CreatedAt
+		}
+		pending = append(pending, catalog)
+		result[publicID] = catalog
+	}
+
+	if err := s.modelCatalogRepo.BatchUpsert(ctx, pending, providerModelBatchSize); err != nil {
+		return nil, common.NewError(err, "4e92b6d1-0a3f-47c5-b8e2-91d6f5a3c027")
+	}
+	return result, nil
+}
+
+// PreviewCatalogs reports the public IDs of the catalogs that BatchUpsertCatalogs would create and
+// update for the models, without writing anything.
+func (s *ModelCatalogService) PreviewCatalogs(ctx context.Context, models []chatclient.Model) ([]string, []string, *common.Error) {
+	publicIDs := make([]string, 0, len(models))
+	seen := make(map[string]chatclient.Model, len(models))
+	for _, model := range models {
+		publicID := catalogPublicID(model)
+		if _, exists := seen[publicID]; exists {
+			continue
+		}
+		seen[publicID] = model
+		publicIDs = append(publicIDs, publicID)
+	}
+	if len(publicIDs) == 0 {
+		return []string{}, []string{}, nil
+	}
+
+	existing, err := s.modelCatalogRepo.FindByFilter(ctx, ModelCatalogFilter{PublicIDs: &publicIDs}, nil)
+	if err != nil {
+		return nil, nil, common.NewError(err, "a8c4e1f7-3d2b-4e96-b5a0-7f1d9c6e2b84")
+	}
+	existingByPublicID := make(map[string]*ModelCatalog, len(existing))
+	for _, catalog := range existing {
+		existingByPublicID[catalog.PublicID] = catalog
+	}
+
+	created := make([]string, 0)
+	updated := make([]string, 0)
+	for _, publicID := range publicIDs {
+		current, ok := existingByPublicID[publicID]
+		if !ok {
+			created = append(created, publicID)
+			continue
+		}
+		if current.Status == ModelCatalogStatusFilled || current.Status == ModelCatalogStatusUpdated {
+			deprecatedAt, deprecationReason := deprecationFromRaw(seen[publicID].Raw)
+			if refreshDeprecation(current, &ModelCatalog{DeprecatedAt: deprecatedAt, DeprecationReason: deprecationReason}) {
+				updated = append(updated, publicID)
+			}
+			continue
+		}
+		updated = append(updated, publicID)
+	}
+	return created, updated, nil
+}
+
 func catalogPublicID(model chatclient.Model) string {
 	if slug := slugify(model.CanonicalSlug); slug != "" {
 		return slug
@@ -59,7 +238,7 @@ func catalogPublicID(model chatclient.Model) string {
 
 func buildModelCatalogFromModel(kind ProviderKind, model chatclient.Model) *ModelCatalog {
 	status := ModelCatalogStatusInit
-	if kind == ProviderOpenRouter {
+	if kind == ProviderOpenRouter || capabilitiesProbed(model) {
 		status = ModelCatalogStatusFilled
 	}
 
@@ -91,6 +270,8 @@ func buildModelCatalogFromModel(kind ProviderKind, model chatclient.Model) *Mode
 		}
 	}
 
+	deprecatedAt, deprecationReason := deprecationFromRaw(model.Raw)
+
 	extras := copyMap(model.Raw)
 
 	return &ModelCatalog{
@@ -98,6 +279,8 @@ func buildModelCatalogFromModel(kind ProviderKind, model chatclient.Model) *Mode
 		Architecture:        architecture,
 		Notes:               notes,
 		IsModerated:         isModerated,
+		DeprecatedAt:        deprecatedAt,
+		DeprecationReason:   deprecationReason,
 		Extras:              extras,
 		Status:              status,
 	}
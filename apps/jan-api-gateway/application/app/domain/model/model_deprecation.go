@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+// defaultDeprecationReason is recorded for models flagged deprecated without a reason.
+const defaultDeprecationReason = "deprecated by the provider"
+
+// deprecationDateKeys and deprecationReasonKeys are the raw model fields providers use to announce
+// a deprecation, in order of preference. OpenRouter sets expiration_date on models it is retiring.
+var (
+	deprecationDateKeys   = []string{"deprecated_at", "deprecation_date", "expiration_date"}
+	deprecationReasonKeys = []string{"deprecation_reason", "deprecation_message"}
+)
+
+// IsDeprecated reports whether the provider announced a deprecation of the model.
+func (c *ModelCatalog) IsDeprecated() bool {
+	return c != nil && (c.DeprecatedAt != nil || c.DeprecationReason != nil)
+}
+
+// deprecationFromRaw extracts the deprecation date and reason from a raw upstream model. A model
+// flagged "deprecated": true without either is given a generic reason so it still reads as
+// deprecated.
+func deprecationFromRaw(raw map[string]any) (*time.Time, *string) {
+	var deprecatedAt *time.Time
+	for _, key := range deprecationDateKeys {
+		if at, ok := parseDeprecationDate(raw[key]); ok {
+			deprecatedAt = &at
+			break
+		}
+	}
+	var reason *string
+	for _, key := range deprecationReasonKeys {
+		if value, ok := getString(raw, key); ok && strings.TrimSpace(value) != "" {
+			reason = ptr.ToString(strings.TrimSpace(value))
+			break
+		}
+	}
+	if deprecatedAt == nil && reason == nil {
+		if flagged, ok := raw["deprecated"].(bool); ok && flagged {
+			reason = ptr.ToString(defaultDeprecationReason)
+		}
+	}
+	return deprecatedAt, reason
+}
+
+// parseDeprecationDate accepts RFC 3339 timestamps, plain dates and Unix seconds.
+func parseDeprecationDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		if at, err := time.Parse(time.RFC3339, v); err == nil {
+			return at.UTC(), true
+		}
+		if at, err := time.Parse(time.DateOnly, v); err == nil {
+			return at, true
+		}
+	case float64:
+		if v > 0 {
+			return time.Unix(int64(v), 0).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// refreshDeprecation copies the deprecation of a freshly built catalog onto an existing entry and
+// reports whether it changed. Filled and curated entries are otherwise left alone by syncs, but a
+// deprecation announced after the first sync still has to reach them.
+func refreshDeprecation(existing, built *ModelCatalog) bool {
+	sameDate := (existing.DeprecatedAt == nil) == (built.DeprecatedAt == nil) &&
+		(existing.DeprecatedAt == nil || existing.DeprecatedAt.Equal(*built.DeprecatedAt))
+	sameReason := (existing.DeprecationReason == nil) == (built.DeprecationReason == nil) &&
+		(existing.DeprecationReason == nil || *existing.DeprecationReason == *built.DeprecationReason)
+	if sameDate && sameReason {
+		return false
+	}
+	existing.DeprecatedAt = built.DeprecatedAt
+	existing.DeprecationReason = built.DeprecationReason
+	return true
+}
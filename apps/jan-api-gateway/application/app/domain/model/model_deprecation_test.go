@@ -0,0 +1,115 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+func TestDeprecationFromRawPayload(t *testing.T) {
+	ctx := context.Background()
+	registry := modeltest.NewRegistry()
+	date := func(year int, month time.Month, day int) *time.Time {
+		at := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	tests := []struct {
+		name       string
+		raw        map[string]any
+		deprecated bool
+		wantAt     *time.Time
+		wantReason string
+	}{
+		{name: "not deprecated", raw: map[string]any{}},
+		{name: "deprecated false", raw: map[string]any{"deprecated": false}},
+		{name: "deprecated_at timestamp", raw: map[string]any{"deprecated_at": "2026-03-01T00:00:00Z"}, deprecated: true, wantAt: date(2026, time.March, 1)},
+		{name: "deprecation_date plain date", raw: map[string]any{"deprecation_date": "2026-04-15"}, deprecated: true, wantAt: date(2026, time.April, 15)},
+		{name: "openrouter expiration_date", raw: map[string]any{"expiration_date": "2026-05-31"}, deprecated: true, wantAt: date(2026, time.May, 31)},
+		{name: "unix seconds", raw: map[string]any{"deprecated_at": float64(date(2026, time.June, 1).Unix())}, deprecated: true, wantAt: date(2026, time.June, 1)},
+		{name: "deprecated_at preferred over expiration_date", raw: map[string]any{"deprecated_at": "2026-03-01", "expiration_date": "2026-05-31"}, deprecated: true, wantAt: date(2026, time.March, 1)},
+		{name: "unparsable date", raw: map[string]any{"deprecated_at": "soon"}},
+		{name: "deprecation_reason", raw: map[string]any{"deprecation_reason": " Replaced by gpt-5. "}, deprecated: true, wantReason: "Replaced by gpt-5."},
+		{name: "deprecation_message", raw: map[string]any{"deprecation_message": "Use the new model."}, deprecated: true, wantReason: "Use the new model."},
+		{name: "blank reason", raw: map[string]any{"deprecation_reason": "  "}},
+		{name: "date and reason", raw: map[string]any{"deprecation_date": "2026-04-15", "deprecation_reason": "Retired."}, deprecated: true, wantAt: date(2026, time.April, 15), wantReason: "Retired."},
+		{name: "bare deprecated flag", raw: map[string]any{"deprecated": true}, deprecated: true, wantReason: "deprecated by the provider"},
+		{name: "flag with a date", raw: map[string]any{"deprecated": true, "expiration_date": "2026-05-31"}, deprecated: true, wantAt: date(2026, time.May, 31)},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := chatclient.Model{ID: "model-" + string(rune('a'+i)), Raw: tt.raw}
+			catalog, err := registry.ModelCatalogService.UpsertCatalog(ctx, domainmodel.ProviderOpenAI, model)
+			if err != nil {
+				t.Fatalf("UpsertCatalog: %v", err)
+			}
+			if catalog.IsDeprecated() != tt.deprecated {
+				t.Fatalf("IsDeprecated = %t, want %t", catalog.IsDeprecated(), tt.deprecated)
+			}
+			if (catalog.DeprecatedAt == nil) != (tt.wantAt == nil) || (tt.wantAt != nil && !catalog.DeprecatedAt.Equal(*tt.wantAt)) {
+				t.Fatalf("DeprecatedAt = %v, want %v", catalog.DeprecatedAt, tt.wantAt)
+			}
+			reason := ""
+			if catalog.DeprecationReason != nil {
+				reason = *catalog.DeprecationReason
+			}
+			if reason != tt.wantReason {
+				t.Fatalf("DeprecationReason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestDeprecationReachesFilledCatalogs(t *testing.T) {
+	ctx := context.Background()
+	registry := modeltest.NewRegistry()
+	kind := domainmodel.ProviderOpenRouter
+	upsert := func(raw map[string]any) *domainmodel.ModelCatalog {
+		t.Helper()
+		catalogs, err := registry.ModelCatalogService.BatchUpsertCatalogs(ctx, kind, []chatclient.Model{{ID: "vendor/old-model", Raw: raw}})
+		if err != nil {
+			t.Fatalf("BatchUpsertCatalogs: %v", err)
+		}
+		for _, catalog := range catalogs {
+			return catalog
+		}
+		t.Fatal("BatchUpsertCatalogs returned no catalog")
+		return nil
+	}
+
+	catalog := upsert(map[string]any{"description": "An old model."})
+	if catalog.Status != domainmodel.ModelCatalogStatusFilled || catalog.IsDeprecated() {
+		t.Fatalf("first sync: status %s, deprecated %t; want filled and not deprecated", catalog.Status, catalog.IsDeprecated())
+	}
+
+	// A later sync announcing the retirement updates the filled entry.
+	announced := upsert(map[string]any{"description": "A changed description.", "expiration_date": "2026-05-31"})
+	if !announced.IsDeprecated() || announced.DeprecatedAt.Format(time.DateOnly) != "2026-05-31" {
+		t.Fatalf("deprecation after a later sync = %v, want 2026-05-31", announced.DeprecatedAt)
+	}
+	if stored := registry.Catalogs.All()[0]; !stored.IsDeprecated() {
+		t.Fatal("the announced deprecation was not written to the repository")
+	}
+	if announced.Notes == nil || *announced.Notes != "An old model." {
+		t.Fatalf("notes = %v, want the filled entry left alone", announced.Notes)
+	}
+
+	// The preview reports the entry as updated only when the deprecation changes.
+	models := []chatclient.Model{{ID: "vendor/old-model", Raw: map[string]any{"expiration_date": "2026-05-31"}}}
+	if _, updated, err := registry.ModelCatalogService.PreviewCatalogs(ctx, models); err != nil || len(updated) != 0 {
+		t.Fatalf("preview of an unchanged deprecation = %v, %v; want nothing updated", updated, err)
+	}
+	models[0].Raw = map[string]any{}
+	if _, updated, err := registry.ModelCatalogService.PreviewCatalogs(ctx, models); err != nil || len(updated) != 1 {
+		t.Fatalf("preview of a withdrawn deprecation = %v, %v; want the entry updated", updated, err)
+	}
+
+	// A withdrawn deprecation is cleared by the next sync.
+	if withdrawn := upsert(map[string]any{}); withdrawn.IsDeprecated() {
+		t.Fatalf("deprecation was kept after the provider withdrew it: %v", withdrawn.DeprecatedAt)
+	}
+}
@@ -1,14 +1,25 @@
 package model
 
 import (
+	"encoding/json"
 	"strings"
 
 	decimal "github.com/shopspring/decimal"
 )
 
+// integerParameters lists sampling parameters that upstreams only accept as integers.
+var integerParameters = map[string]struct{}{
+	"max_tokens":            {},
+	"max_completion_tokens": {},
+	"top_k":                 {},
+	"seed":                  {},
+	"n":                     {},
+	"top_logprobs":          {},
+}
+
 // we can reuse these utility functions in both model_catalog and provider_model
-func extractDefaultParameters(value any) map[string]*decimal.Decimal {
-	result := map[string]*decimal.Decimal{}
+func extractDefaultParameters(value any) map[string]*ParameterValue {
+	result := map[string]*ParameterValue{}
 	params, ok := value.(map[string]any)
 	if !ok {
 		return result
@@ -18,6 +29,8 @@ func extractDefaultParameters(value any) map[string]*decimal.Decimal {
 			result[key] = nil
 			continue
 		}
+		// JSON numbers arrive as float64, so 4 and 4.0 are only told apart for known integer params.
+		_, integerParam := integerParameters[key]
 		switch v := raw.(type) {
 		case string:
 			if strings.TrimSpace(v) == "" {
@@ -25,15 +38,24 @@ func extractDefaultParameters(value any) map[string]*decimal.Decimal {
 				continue
 			}
 			if d, err := decimal.NewFromString(v); err == nil {
-				val := d
-				result[key] = &val
+				literalInteger := !strings.ContainsAny(v, ".eE")
+				result[key] = &ParameterValue{Value: d, Integer: d.IsInteger() && (integerParam || literalInteger)}
+			}
+		case json.Number:
+			if d, err := decimal.NewFromString(v.String()); err == nil {
+				literalInteger := !strings.ContainsAny(v.String(), ".eE")
+				result[key] = &ParameterValue{Value: d, Integer: d.IsInteger() && (integerParam || literalInteger)}
 			}
 		case float64:
 			d := decimal.NewFromFloat(v)
-			result[key] = &d
+			result[key] = &ParameterValue{Value: d, Integer: integerParam && d.IsInteger()}
 		case float32:
 			d := decimal.NewFromFloat32(v)
-			result[key] = &d
+			result[key] = &ParameterValue{Value: d, Integer: integerParam && d.IsInteger()}
+		case int:
+			result[key] = &ParameterValue{Value: decimal.NewFromInt(int64(v)), Integer: true}
+		case int64:
+			result[key] = &ParameterValue{Value: decimal.NewFromInt(v), Integer: true}
 		default:
 			// ignore unsupported types
 		}
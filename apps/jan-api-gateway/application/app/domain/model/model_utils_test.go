@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExtractDefaultParameters(t *testing.T) {
+	params := extractDefaultParameters(map[string]any{
+		"max_tokens":        float64(4),
+		"seed":              json.Number("42"),
+		"temperature":       float64(1),
+		"top_p":             "0.9",
+		"top_k":             "40",
+		"frequency_penalty": json.Number("0"),
+		"presence_penalty":  nil,
+		"logit_bias":        map[string]any{},
+	})
+
+	tests := []struct {
+		name    string
+		integer bool
+		json    string
+	}{
+		{name: "max_tokens", integer: true, json: "4"},
+		{name: "seed", integer: true, json: "42"},
+		{name: "temperature", json: "1.0"},
+		{name: "top_p", json: "0.9"},
+		{name: "top_k", integer: true, json: "40"},
+		{name: "frequency_penalty", integer: true, json: "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := params[tt.name]
+			if value == nil {
+				t.Fatalf("%s was not extracted", tt.name)
+			}
+			if value.Integer != tt.integer {
+				t.Fatalf("Integer = %t, want %t", value.Integer, tt.integer)
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != tt.json {
+				t.Fatalf("JSON = %s, want %s", data, tt.json)
+			}
+		})
+	}
+	if value, ok := params["presence_penalty"]; !ok || value != nil {
+		t.Fatal("a null default was not kept as nil")
+	}
+	if _, ok := params["logit_bias"]; ok {
+		t.Fatal("a non-numeric default was extracted")
+	}
+}
+
+func TestParameterValueRoundTrip(t *testing.T) {
+	tests := []struct {
+		stored  string
+		integer bool
+		json    string
+	}{
+		{stored: "4", integer: true, json: "4"},
+		{stored: "1.0", json: "1.0"},
+		{stored: "0.75", json: "0.75"},
+		// Earlier versions stored every default as a quoted decimal.
+		{stored: `"4"`, integer: true, json: "4"},
+		{stored: `"0.7"`, json: "0.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.stored, func(t *testing.T) {
+			var value ParameterValue
+			if err := json.Unmarshal([]byte(tt.stored), &value); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if value.Integer != tt.integer {
+				t.Fatalf("Integer = %t, want %t", value.Integer, tt.integer)
+			}
+			data, _ := json.Marshal(value)
+			if string(data) != tt.json {
+				t.Fatalf("JSON = %s, want %s", data, tt.json)
+			}
+		})
+	}
+}
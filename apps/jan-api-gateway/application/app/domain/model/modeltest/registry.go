@@ -0,0 +1,152 @@
+package modeltest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+// ModelLister serves fixed upstream model lists per provider slug.
+type ModelLister struct {
+	mu     sync.Mutex
+	models map[string][]chatclient.Model
+	errors map[string]error
+	calls  map[string]int
+}
+
+var _ domainmodel.ProviderModelLister = (*ModelLister)(nil)
+
+func NewModelLister() *ModelLister {
+	return &ModelLister{models: map[string][]chatclient.Model{}, errors: map[string]error{}, calls: map[string]int{}}
+}
+
+// SetModels makes the provider with the slug serve models with the given IDs.
+func (l *ModelLister) SetModels(slug string, ids ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	models := make([]chatclient.Model, 0, len(ids))
+	for _, id := range ids {
+		models = append(models, chatclient.Model{ID: id, Object: "model"})
+	}
+	l.models[slug] = models
+}
+
+// SetError makes listing the models of the provider with the slug fail.
+func (l *ModelLister) SetError(slug string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors[slug] = err
+}
+
+// Calls returns how often the models of the provider with the slug were listed.
+func (l *ModelLister) Calls(slug string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.calls[slug]
+}
+
+func (l *ModelLister) ListModels(ctx context.Context, provider *domainmodel.Provider) ([]chatclient.Model, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls[provider.Slug]++
+	if err := l.errors[provider.Slug]; err != nil {
+		return nil, err
+	}
+	models, ok := l.models[provider.Slug]
+	if !ok {
+		return nil, fmt.Errorf("no models configured for provider %s", provider.Slug)
+	}
+	return append([]chatclient.Model(nil), models...), nil
+}
+
+// Availability reports every provider as available unless it was marked unavailable.
+type Availability struct {
+	mu          sync.Mutex
+	unavailable map[uint]bool
+}
+
+var _ domainmodel.ProviderAvailability = (*Availability)(nil)
+
+func NewAvailability() *Availability {
+	return &Availability{unavailable: map[uint]bool{}}
+}
+
+// SetAvailable opens or closes the provider for completion traffic.
+func (a *Availability) SetAvailable(providerID uint, available bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.unavailable[providerID] = !available
+}
+
+func (a *Availability) IsProviderAvailable(providerID uint) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return !a.unavailable[providerID]
+}
+
+// Registry is a ProviderRegistryService backed by in-memory repositories, without Redis or
+// webhooks.
+type Registry struct {
+	*domainmodel.ProviderRegistryService
+	Providers     *ProviderRepository
+	Models        *ProviderModelRepository
+	Catalogs      *ModelCatalogRepository
+	Aliases       *ModelAliasRepository
+	Organizations *OrganizationRepository
+	Lister        *ModelLister
+	Availability  *Availability
+	Transactor    *Transactor
+
+	ProviderModelService *domainmodel.ProviderModelService
+	ModelCatalogService  *domainmodel.ModelCatalogService
+	ModelAliasService    *domainmodel.ModelAliasService
+	OrganizationService  *organization.OrganizationService
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{
+		Providers:     NewProviderRepository(),
+		Models:        NewProviderModelRepository(),
+		Catalogs:      NewModelCatalogRepository(),
+		Aliases:       NewModelAliasRepository(),
+		Organizations: NewOrganizationRepository(),
+		Lister:        NewModelLister(),
+		Availability:  NewAvailability(),
+	}
+	r.Transactor = NewTransactor(r.Providers, r.Models, r.Catalogs, r.Aliases, r.Organizations)
+	r.ProviderModelService = domainmodel.NewProviderModelService(r.Models)
+	r.ModelCatalogService = domainmodel.NewModelCatalogService(r.Catalogs)
+	r.ModelAliasService = domainmodel.NewModelAliasService(r.Aliases)
+	r.OrganizationService = organization.NewService(r.Organizations)
+	r.ProviderRegistryService = domainmodel.NewProviderRegistryService(
+		r.Providers,
+		r.ProviderModelService,
+		r.ModelCatalogService,
+		r.Lister,
+		r.Availability,
+		r.ModelAliasService,
+		r.OrganizationService,
+		nil,
+		nil,
+		r.Transactor,
+	)
+	return r
+}
+
+// ResetCalls clears the call counters of every repository.
+func (r *Registry) ResetCalls() {
+	r.Providers.ResetCalls()
+	r.Models.ResetCalls()
+	r.Catalogs.ResetCalls()
+	r.Aliases.ResetCalls()
+	r.Organizations.ResetCalls()
+}
+
+// RepoCalls returns the number of repository calls made since the last reset.
+func (r *Registry) RepoCalls() int {
+	return r.Providers.TotalCalls() + r.Models.TotalCalls() + r.Catalogs.TotalCalls() + r.Aliases.TotalCalls() + r.Organizations.TotalCalls()
+}
@@ -0,0 +1,707 @@
+package modeltest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+)
+
+// ProviderRepository is an in-memory domainmodel.ProviderRepository. Deleted providers are kept
+// aside like soft-deleted rows and only match filters with WithDeleted.
+type ProviderRepository struct {
+	*store[domainmodel.Provider]
+	deleted map[uint]*domainmodel.Provider
+}
+
+var _ domainmodel.ProviderRepository = (*ProviderRepository)(nil)
+
+func NewProviderRepository() *ProviderRepository {
+	return &ProviderRepository{store: newStore[domainmodel.Provider](), deleted: map[uint]*domainmodel.Provider{}}
+}
+
+// Add stores the providers as they are, bypassing the call counters.
+func (r *ProviderRepository) Add(providers ...*domainmodel.Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, provider := range providers {
+		r.insert(provider, func(p *domainmodel.Provider, id uint) { p.ID = id })
+	}
+}
+
+func (r *ProviderRepository) Create(ctx context.Context, provider *domainmodel.Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Create"); err != nil {
+		return err
+	}
+	provider.CreatedAt = time.Now()
+	provider.UpdatedAt = provider.CreatedAt
+	r.insert(provider, func(p *domainmodel.Provider, id uint) { p.ID = id })
+	return nil
+}
+
+func (r *ProviderRepository) Update(ctx context.Context, provider *domainmodel.Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Update"); err != nil {
+		return err
+	}
+	provider.UpdatedAt = time.Now()
+	r.put(provider.ID, provider)
+	return nil
+}
+
+func (r *ProviderRepository) DeleteByID(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByID"); err != nil {
+		return err
+	}
+	if row, ok := r.rows[id]; ok {
+		r.deleted[id] = row
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *ProviderRepository) FindByID(ctx context.Context, id uint) (*domainmodel.Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByID"); err != nil {
+		return nil, err
+	}
+	return r.get(id)
+}
+
+func (r *ProviderRepository) FindByPublicID(ctx context.Context, publicID string) (*domainmodel.Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByPublicID"); err != nil {
+		return nil, err
+	}
+	return first(r.filter(func(p *domainmodel.Provider) bool { return p.PublicID == publicID }))
+}
+
+func (r *ProviderRepository) FindBySlug(ctx context.Context, slug string) (*domainmodel.Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindBySlug"); err != nil {
+		return nil, err
+	}
+	return first(r.filter(func(p *domainmodel.Provider) bool { return p.Slug == slug }))
+}
+
+func (r *ProviderRepository) FindByFilter(ctx context.Context, filter domainmodel.ProviderFilter, p *query.Pagination) ([]*domainmodel.Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByFilter"); err != nil {
+		return nil, err
+	}
+	rows := r.filter(func(provider *domainmodel.Provider) bool { return matchProvider(provider, filter) })
+	if filter.WithDeleted != nil && *filter.WithDeleted {
+		for _, provider := range r.deleted {
+			if matchProvider(provider, filter) {
+				copied := *provider
+				rows = append(rows, &copied)
+			}
+		}
+		slices.SortFunc(rows, func(a, b *domainmodel.Provider) int { return cmp.Compare(a.ID, b.ID) })
+	}
+	if p != nil && p.After != nil {
+		after := *p.After
+		rows = slices.DeleteFunc(rows, func(provider *domainmodel.Provider) bool {
+			if p.Order == "desc" {
+				return provider.ID >= after
+			}
+			return provider.ID <= after
+		})
+	}
+	return paginate(rows, p), nil
+}
+
+func (r *ProviderRepository) Count(ctx context.Context, filter domainmodel.ProviderFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Count"); err != nil {
+		return 0, err
+	}
+	return int64(len(r.filter(func(provider *domainmodel.Provider) bool { return matchProvider(provider, filter) }))), nil
+}
+
+func (r *ProviderRepository) UpdateHealth(ctx context.Context, id uint, checkedAt time.Time, healthError *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("UpdateHealth"); err != nil {
+		return err
+	}
+	row, ok := r.rows[id]
+	if !ok {
+		return nil
+	}
+	row.LastHealthCheckAt = &checkedAt
+	row.LastHealthError = healthError
+	return nil
+}
+
+func matchProvider(p *domainmodel.Provider, f domainmodel.ProviderFilter) bool {
+	switch {
+	case f.IDs != nil && len(*f.IDs) > 0 && !slices.Contains(*f.IDs, p.ID),
+		f.PublicID != nil && p.PublicID != *f.PublicID,
+		f.Slug != nil && p.Slug != *f.Slug,
+		f.OrganizationID != nil && (p.OrganizationID == nil || *p.OrganizationID != *f.OrganizationID),
+		f.Kind != nil && p.Kind != *f.Kind,
+		f.BaseURL != nil && p.BaseURL != *f.BaseURL,
+		f.ProjectID != nil && (p.ProjectID == nil || *p.ProjectID != *f.ProjectID),
+		f.ProjectIDs != nil && len(*f.ProjectIDs) > 0 && (p.ProjectID == nil || !slices.Contains(*f.ProjectIDs, *p.ProjectID)),
+		f.WithoutProject != nil && (p.ProjectID == nil) != *f.WithoutProject,
+		f.AccessibleProjectIDs != nil && p.ProjectID != nil && !slices.Contains(*f.AccessibleProjectIDs, *p.ProjectID),
+		f.Active != nil && p.Active != *f.Active,
+		f.IsModerated != nil && p.IsModerated != *f.IsModerated,
+		f.Shadow != nil && p.Shadow != *f.Shadow,
+		f.HasAPIKey != nil && (p.EncryptedAPIKey != "") != *f.HasAPIKey,
+		f.LastSyncedAfter != nil && (p.LastSyncedAt == nil || p.LastSyncedAt.Before(*f.LastSyncedAfter)),
+		f.LastSyncedBefore != nil && (p.LastSyncedAt == nil || p.LastSyncedAt.After(*f.LastSyncedBefore)):
+		return false
+	}
+	if f.HealthStatus != nil {
+		switch *f.HealthStatus {
+		case domainmodel.ProviderHealthHealthy:
+			return p.LastHealthCheckAt != nil && p.LastHealthError == nil
+		case domainmodel.ProviderHealthUnhealthy:
+			return p.LastHealthError != nil
+		case domainmodel.ProviderHealthUnchecked:
+			return p.LastHealthCheckAt == nil && p.LastHealthError == nil
+		}
+	}
+	return true
+}
+
+// ProviderModelRepository is an in-memory domainmodel.ProviderModelRepository.
+type ProviderModelRepository struct {
+	*store[domainmodel.ProviderModel]
+	// FailingModelKeys makes every write of a model with the key fail, and with it any batch the
+	// model is part of, like a constraint violation would.
+	FailingModelKeys map[string]error
+	// BatchSizes records the size of every batch written by BatchUpsert.
+	BatchSizes []int
+}
+
+var _ domainmodel.ProviderModelRepository = (*ProviderModelRepository)(nil)
+
+func NewProviderModelRepository() *ProviderModelRepository {
+	return &ProviderModelRepository{store: newStore[domainmodel.ProviderModel](), FailingModelKeys: map[string]error{}}
+}
+
+// Add stores the models as they are, bypassing the call counters.
+func (r *ProviderModelRepository) Add(models ...*domainmodel.ProviderModel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, model := range models {
+		r.insert(model, func(m *domainmodel.ProviderModel, id uint) { m.ID = id })
+	}
+}
+
+// All returns every stored model ordered by ID.
+func (r *ProviderModelRepository) All() []*domainmodel.ProviderModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter(func(*domainmodel.ProviderModel) bool { return true })
+}
+
+func (r *ProviderModelRepository) write(model *domainmodel.ProviderModel) {
+	if model.ID == 0 {
+		model.CreatedAt = time.Now()
+		model.UpdatedAt = model.CreatedAt
+		r.insert(model, func(m *domainmodel.ProviderModel, id uint) { m.ID = id })
+		return
+	}
+	r.put(model.ID, model)
+}
+
+func (r *ProviderModelRepository) Create(ctx context.Context, model *domainmodel.ProviderModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Create"); err != nil {
+		return err
+	}
+	if err := r.FailingModelKeys[model.ModelKey]; err != nil {
+		return err
+	}
+	model.ID = 0
+	r.write(model)
+	return nil
+}
+
+func (r *ProviderModelRepository) Update(ctx context.Context, model *domainmodel.ProviderModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Update"); err != nil {
+		return err
+	}
+	if err := r.FailingModelKeys[model.ModelKey]; err != nil {
+		return err
+	}
+	r.write(model)
+	return nil
+}
+
+func (r *ProviderModelRepository) BatchUpsert(ctx context.Context, models []*domainmodel.ProviderModel, batchSize int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("BatchUpsert"); err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(models)
+	}
+	for start := 0; start < len(models); start += batchSize {
+		batch := models[start:min(start+batchSize, len(models))]
+		for _, model := range batch {
+			if err := r.FailingModelKeys[model.ModelKey]; err != nil {
+				return err
+			}
+		}
+		r.BatchSizes = append(r.BatchSizes, len(batch))
+		for _, model := range batch {
+			r.write(model)
+		}
+	}
+	return nil
+}
+
+func (r *ProviderModelRepository) DeleteByID(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByID"); err != nil {
+		return err
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *ProviderModelRepository) DeleteByProviderID(ctx context.Context, providerID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByProviderID"); err != nil {
+		return err
+	}
+	for id, row := range r.rows {
+		if row.ProviderID == providerID {
+			delete(r.rows, id)
+		}
+	}
+	return nil
+}
+
+func (r *ProviderModelRepository) FindByID(ctx context.Context, id uint) (*domainmodel.ProviderModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByID"); err != nil {
+		return nil, err
+	}
+	return r.get(id)
+}
+
+func (r *ProviderModelRepository) FindByFilter(ctx context.Context, filter domainmodel.ProviderModelFilter, p *query.Pagination) ([]*domainmodel.ProviderModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByFilter"); err != nil {
+		return nil, err
+	}
+	return paginate(r.filter(func(model *domainmodel.ProviderModel) bool { return matchProviderModel(model, filter) }), p), nil
+}
+
+func (r *ProviderModelRepository) Count(ctx context.Context, filter domainmodel.ProviderModelFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Count"); err != nil {
+		return 0, err
+	}
+	return int64(len(r.filter(func(model *domainmodel.ProviderModel) bool { return matchProviderModel(model, filter) }))), nil
+}
+
+func matchProviderModel(m *domainmodel.ProviderModel, f domainmodel.ProviderModelFilter) bool {
+	switch {
+	case f.IDs != nil && len(*f.IDs) > 0 && !slices.Contains(*f.IDs, m.ID),
+		f.ProviderID != nil && m.ProviderID != *f.ProviderID,
+		f.ProviderIDs != nil && len(*f.ProviderIDs) > 0 && !slices.Contains(*f.ProviderIDs, m.ProviderID),
+		f.PublicID != nil && m.PublicID != *f.PublicID,
+		f.ModelCatalogID != nil && (m.ModelCatalogID == nil || *m.ModelCatalogID != *f.ModelCatalogID),
+		f.ModelKey != nil && m.ModelKey != *f.ModelKey,
+		f.ModelKeys != nil && len(*f.ModelKeys) > 0 && !slices.Contains(*f.ModelKeys, m.ModelKey),
+		f.Active != nil && m.Active != *f.Active,
+		f.SupportsImages != nil && m.SupportsImages != *f.SupportsImages,
+		f.SupportsEmbeddings != nil && m.SupportsEmbeddings != *f.SupportsEmbeddings,
+		f.SupportsReasoning != nil && m.SupportsReasoning != *f.SupportsReasoning:
+		return false
+	}
+	return true
+}
+
+// ModelCatalogRepository is an in-memory domainmodel.ModelCatalogRepository.
+type ModelCatalogRepository struct {
+	*store[domainmodel.ModelCatalog]
+	// FailingPublicIDs makes every write of a catalog with the public ID fail, and with it any
+	// batch the catalog is part of.
+	FailingPublicIDs map[string]error
+	// BatchSizes records the size of every batch written by BatchUpsert.
+	BatchSizes []int
+}
+
+var _ domainmodel.ModelCatalogRepository = (*ModelCatalogRepository)(nil)
+
+func NewModelCatalogRepository() *ModelCatalogRepository {
+	return &ModelCatalogRepository{store: newStore[domainmodel.ModelCatalog](), FailingPublicIDs: map[string]error{}}
+}
+
+// Add stores the catalogs as they are, bypassing the call counters.
+func (r *ModelCatalogRepository) Add(catalogs ...*domainmodel.ModelCatalog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, catalog := range catalogs {
+		r.insert(catalog, func(c *domainmodel.ModelCatalog, id uint) { c.ID = id })
+	}
+}
+
+// All returns every stored catalog ordered by ID.
+func (r *ModelCatalogRepository) All() []*domainmodel.ModelCatalog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter(func(*domainmodel.ModelCatalog) bool { return true })
+}
+
+func (r *ModelCatalogRepository) write(catalog *domainmodel.ModelCatalog) {
+	if catalog.ID == 0 {
+		catalog.CreatedAt = time.Now()
+		catalog.UpdatedAt = catalog.CreatedAt
+		r.insert(catalog, func(c *domainmodel.ModelCatalog, id uint) { c.ID = id })
+		return
+	}
+	r.put(catalog.ID, catalog)
+}
+
+func (r *ModelCatalogRepository) Create(ctx context.Context, catalog *domainmodel.ModelCatalog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Create"); err != nil {
+		return err
+	}
+	if err := r.FailingPublicIDs[catalog.PublicID]; err != nil {
+		return err
+	}
+	catalog.ID = 0
+	r.write(catalog)
+	return nil
+}
+
+func (r *ModelCatalogRepository) Update(ctx context.Context, catalog *domainmodel.ModelCatalog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Update"); err != nil {
+		return err
+	}
+	if err := r.FailingPublicIDs[catalog.PublicID]; err != nil {
+		return err
+	}
+	r.write(catalog)
+	return nil
+}
+
+func (r *ModelCatalogRepository) BatchUpsert(ctx context.Context, catalogs []*domainmodel.ModelCatalog, batchSize int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("BatchUpsert"); err != nil {
+		return err
+	}
+	if len(catalogs) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(catalogs)
+	}
+	for start := 0; start < len(catalogs); start += batchSize {
+		batch := catalogs[start:min(start+batchSize, len(catalogs))]
+		for _, catalog := range batch {
+			if err := r.FailingPublicIDs[catalog.PublicID]; err != nil {
+				return err
+			}
+		}
+		r.BatchSizes = append(r.BatchSizes, len(batch))
+		for _, catalog := range batch {
+			r.write(catalog)
+		}
+	}
+	return nil
+}
+
+func (r *ModelCatalogRepository) DeleteByID(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByID"); err != nil {
+		return err
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *ModelCatalogRepository) FindByID(ctx context.Context, id uint) (*domainmodel.ModelCatalog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByID"); err != nil {
+		return nil, err
+	}
+	return r.get(id)
+}
+
+func (r *ModelCatalogRepository) FindByPublicID(ctx context.Context, publicID string) (*domainmodel.ModelCatalog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByPublicID"); err != nil {
+		return nil, err
+	}
+	// Like the gorm repository, a missing catalog is not an error.
+	catalogs := r.filter(func(c *domainmodel.ModelCatalog) bool { return c.PublicID == publicID })
+	if len(catalogs) == 0 {
+		return nil, nil
+	}
+	return catalogs[0], nil
+}
+
+func (r *ModelCatalogRepository) FindByFilter(ctx context.Context, filter domainmodel.ModelCatalogFilter, p *query.Pagination) ([]*domainmodel.ModelCatalog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByFilter"); err != nil {
+		return nil, err
+	}
+	return paginate(r.filter(func(c *domainmodel.ModelCatalog) bool { return matchCatalog(c, filter) }), p), nil
+}
+
+func (r *ModelCatalogRepository) Count(ctx context.Context, filter domainmodel.ModelCatalogFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Count"); err != nil {
+		return 0, err
+	}
+	return int64(len(r.filter(func(c *domainmodel.ModelCatalog) bool { return matchCatalog(c, filter) }))), nil
+}
+
+func matchCatalog(c *domainmodel.ModelCatalog, f domainmodel.ModelCatalogFilter) bool {
+	switch {
+	case f.IDs != nil && len(*f.IDs) > 0 && !slices.Contains(*f.IDs, c.ID),
+		f.PublicID != nil && c.PublicID != *f.PublicID,
+		f.PublicIDs != nil && len(*f.PublicIDs) > 0 && !slices.Contains(*f.PublicIDs, c.PublicID),
+		f.IsModerated != nil && (c.IsModerated == nil || *c.IsModerated != *f.IsModerated),
+		f.Status != nil && c.Status != *f.Status,
+		f.Deprecated != nil && c.IsDeprecated() != *f.Deprecated,
+		f.LastSyncedAfter != nil && (c.LastSyncedAt == nil || c.LastSyncedAt.Before(*f.LastSyncedAfter)),
+		f.LastSyncedBefore != nil && (c.LastSyncedAt == nil || c.LastSyncedAt.After(*f.LastSyncedBefore)):
+		return false
+	}
+	return true
+}
+
+// ModelAliasRepository is an in-memory domainmodel.ModelAliasRepository.
+type ModelAliasRepository struct {
+	*store[domainmodel.ModelAlias]
+}
+
+var _ domainmodel.ModelAliasRepository = (*ModelAliasRepository)(nil)
+
+func NewModelAliasRepository() *ModelAliasRepository {
+	return &ModelAliasRepository{newStore[domainmodel.ModelAlias]()}
+}
+
+// Add stores the aliases as they are, bypassing the call counters.
+func (r *ModelAliasRepository) Add(aliases ...*domainmodel.ModelAlias) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, alias := range aliases {
+		r.insert(alias, func(a *domainmodel.ModelAlias, id uint) { a.ID = id })
+	}
+}
+
+func (r *ModelAliasRepository) Create(ctx context.Context, alias *domainmodel.ModelAlias) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Create"); err != nil {
+		return err
+	}
+	r.insert(alias, func(a *domainmodel.ModelAlias, id uint) { a.ID = id })
+	return nil
+}
+
+func (r *ModelAliasRepository) Update(ctx context.Context, alias *domainmodel.ModelAlias) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Update"); err != nil {
+		return err
+	}
+	r.put(alias.ID, alias)
+	return nil
+}
+
+func (r *ModelAliasRepository) DeleteByID(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByID"); err != nil {
+		return err
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *ModelAliasRepository) FindByFilter(ctx context.Context, filter domainmodel.ModelAliasFilter, p *query.Pagination) ([]*domainmodel.ModelAlias, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByFilter"); err != nil {
+		return nil, err
+	}
+	return paginate(r.filter(func(a *domainmodel.ModelAlias) bool { return matchAlias(a, filter) }), p), nil
+}
+
+func (r *ModelAliasRepository) Count(ctx context.Context, filter domainmodel.ModelAliasFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Count"); err != nil {
+		return 0, err
+	}
+	return int64(len(r.filter(func(a *domainmodel.ModelAlias) bool { return matchAlias(a, filter) }))), nil
+}
+
+func matchAlias(a *domainmodel.ModelAlias, f domainmodel.ModelAliasFilter) bool {
+	return (f.PublicID == nil || a.PublicID == *f.PublicID) &&
+		(f.OrganizationID == nil || a.OrganizationID == *f.OrganizationID) &&
+		(f.Alias == nil || a.Alias == *f.Alias) &&
+		(f.ProviderID == nil || a.ProviderID == *f.ProviderID)
+}
+
+// OrganizationRepository is an in-memory organization.OrganizationRepository.
+type OrganizationRepository struct {
+	*store[organization.Organization]
+}
+
+var _ organization.OrganizationRepository = (*OrganizationRepository)(nil)
+
+func NewOrganizationRepository() *OrganizationRepository {
+	return &OrganizationRepository{newStore[organization.Organization]()}
+}
+
+// Add stores the organizations as they are, bypassing the call counters.
+func (r *OrganizationRepository) Add(orgs ...*organization.Organization) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, org := range orgs {
+		r.insert(org, func(o *organization.Organization, id uint) { o.ID = id })
+	}
+}
+
+func (r *OrganizationRepository) Create(ctx context.Context, o *organization.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Create"); err != nil {
+		return err
+	}
+	r.insert(o, func(org *organization.Organization, id uint) { org.ID = id })
+	return nil
+}
+
+func (r *OrganizationRepository) Update(ctx context.Context, o *organization.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Update"); err != nil {
+		return err
+	}
+	r.put(o.ID, o)
+	return nil
+}
+
+func (r *OrganizationRepository) DeleteByID(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("DeleteByID"); err != nil {
+		return err
+	}
+	delete(r.rows, id)
+	return nil
+}
+
+func (r *OrganizationRepository) FindByID(ctx context.Context, id uint) (*organization.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByID"); err != nil {
+		return nil, err
+	}
+	return r.get(id)
+}
+
+func (r *OrganizationRepository) FindByPublicID(ctx context.Context, publicID string) (*organization.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByPublicID"); err != nil {
+		return nil, err
+	}
+	return first(r.filter(func(o *organization.Organization) bool { return o.PublicID == publicID }))
+}
+
+func (r *OrganizationRepository) FindByFilter(ctx context.Context, filter organization.OrganizationFilter, p *query.Pagination) ([]*organization.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("FindByFilter"); err != nil {
+		return nil, err
+	}
+	return paginate(r.filter(func(o *organization.Organization) bool { return matchOrganization(o, filter) }), p), nil
+}
+
+func (r *OrganizationRepository) Count(ctx context.Context, filter organization.OrganizationFilter) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.call("Count"); err != nil {
+		return 0, err
+	}
+	return int64(len(r.filter(func(o *organization.Organization) bool { return matchOrganization(o, filter) }))), nil
+}
+
+func (r *OrganizationRepository) AddMember(ctx context.Context, m *organization.OrganizationMember) error {
+	return r.call("AddMember")
+}
+
+func (r *OrganizationRepository) FindMemberByFilter(ctx context.Context, filter organization.OrganizationMemberFilter, p *query.Pagination) ([]*organization.OrganizationMember, error) {
+	return nil, r.call("FindMemberByFilter")
+}
+
+func matchOrganization(o *organization.Organization, f organization.OrganizationFilter) bool {
+	return (f.PublicID == nil || o.PublicID == *f.PublicID) && (f.Enabled == nil || o.Enabled == *f.Enabled)
+}
+
+func first[T any](rows []*T) (*T, error) {
+	if len(rows) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rows[0], nil
+}
+
+// paginate applies the limit, offset and ID cursor of the pagination like the gorm repositories.
+func paginate[T any](rows []*T, p *query.Pagination) []*T {
+	if p == nil {
+		return rows
+	}
+	if p.Order == "desc" {
+		slices.Reverse(rows)
+	}
+	if p.Offset != nil && *p.Offset > 0 {
+		rows = rows[min(*p.Offset, len(rows)):]
+	}
+	if p.Limit != nil && *p.Limit > 0 && len(rows) > *p.Limit {
+		rows = rows[:*p.Limit]
+	}
+	return rows
+}
@@ -0,0 +1,159 @@
+// Package modeltest provides in-memory implementations of the model domain repositories for
+// tests. Rows are copied on the way in and out like a database would, every call is counted, and
+// failures can be injected per method.
+package modeltest
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// store keeps rows by ID and hands out copies, so callers cannot change stored rows in place.
+type store[T any] struct {
+	mu     sync.Mutex
+	rows   map[uint]*T
+	nextID uint
+	calls  map[string]int
+	// Errors makes the named method fail with the error, e.g. Errors["DeleteByID"].
+	Errors map[string]error
+}
+
+func newStore[T any]() *store[T] {
+	return &store[T]{rows: map[uint]*T{}, nextID: 1, calls: map[string]int{}, Errors: map[string]error{}}
+}
+
+// call counts an invocation of method and returns the error injected for it, if any.
+func (s *store[T]) call(method string) error {
+	s.calls[method]++
+	return s.Errors[method]
+}
+
+// Calls returns how often the method was called.
+func (s *store[T]) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// TotalCalls returns the number of calls across all methods.
+func (s *store[T]) TotalCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, count := range s.calls {
+		total += count
+	}
+	return total
+}
+
+// ResetCalls clears the call counters.
+func (s *store[T]) ResetCalls() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = map[string]int{}
+}
+
+// insert stores a copy of the row under the next ID, which is set on the row as well.
+func (s *store[T]) insert(row *T, setID func(*T, uint)) {
+	id := s.nextID
+	s.nextID++
+	setID(row, id)
+	s.put(id, row)
+}
+
+func (s *store[T]) get(id uint) (*T, error) {
+	row, ok := s.rows[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (s *store[T]) put(id uint, row *T) {
+	stored := *row
+	s.rows[id] = &stored
+}
+
+// filter returns copies of the rows matching keep, ordered by ID.
+func (s *store[T]) filter(keep func(*T) bool) []*T {
+	ids := slices.Sorted(maps.Keys(s.rows))
+	result := []*T{}
+	for _, id := range ids {
+		if keep(s.rows[id]) {
+			copied := *s.rows[id]
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+func (s *store[T]) snapshotState() any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := make(map[uint]*T, len(s.rows))
+	for id, row := range s.rows {
+		copied := *row
+		rows[id] = &copied
+	}
+	return storeSnapshot[T]{rows: rows, nextID: s.nextID}
+}
+
+func (s *store[T]) restoreState(state any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := state.(storeSnapshot[T])
+	s.rows = snap.rows
+	s.nextID = snap.nextID
+}
+
+type storeSnapshot[T any] struct {
+	rows   map[uint]*T
+	nextID uint
+}
+
+// Snapshotter is a repository whose rows a Transactor can roll back.
+type Snapshotter interface {
+	snapshotState() any
+	restoreState(state any)
+}
+
+// Transactor runs functions as transactions over the given repositories: when the function fails,
+// every repository is restored to its state before the call.
+type Transactor struct {
+	mu    sync.Mutex
+	repos []Snapshotter
+	// Transactions counts the transactions that were started.
+	Transactions int
+	// RolledBack counts the transactions that failed and were rolled back.
+	RolledBack int
+}
+
+func NewTransactor(repos ...Snapshotter) *Transactor {
+	return &Transactor{repos: repos}
+}
+
+func (t *Transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	t.Transactions++
+	states := make([]any, len(t.repos))
+	for i, repo := range t.repos {
+		states[i] = repo.snapshotState()
+	}
+	t.mu.Unlock()
+
+	if err := fn(ctx); err != nil {
+		t.mu.Lock()
+		t.RolledBack++
+		for i, repo := range t.repos {
+			repo.restoreState(states[i])
+		}
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -32,22 +32,39 @@ const (
 
 // Provider is the aggregate root.
 type Provider struct {
-	ID              uint   `json:"id"`
-	PublicID        string `json:"public_id"`
-	Slug            string `json:"slug"` // unique, lowercase handle
-	OrganizationID  *uint
-	ProjectID       *uint
-	DisplayName     string       `json:"display_name"`
-	Kind            ProviderKind `json:"kind"`
-	BaseURL         string       `json:"base_url"` // e.g., https://api.openai.com/v1
-	EncryptedAPIKey string
-	APIKeyHint      *string `json:"api_key_hint,omitempty"` // last4 or source name, not the secret
-	IsModerated     bool    `json:"is_moderated"`           // whether provider enforces moderation upstream
-	Active          bool
-	Metadata        map[string]string `json:"metadata,omitempty"`
-	LastSyncedAt    *time.Time
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID                 uint   `json:"id"`
+	PublicID           string `json:"public_id"`
+	Slug               string `json:"slug"` // unique, lowercase handle
+	OrganizationID     *uint
+	ProjectID          *uint
+	DisplayName        string       `json:"display_name"`
+	Kind               ProviderKind `json:"kind"`
+	BaseURL            string       `json:"base_url"` // e.g., https://api.openai.com/v1
+	PathPrefix         string       `json:"path_prefix,omitempty"`
+	EncryptedAPIKey    string
+	APIKeyHint         *string `json:"api_key_hint,omitempty"` // last4 or source name, not the secret
+	IsModerated        bool    `json:"is_moderated"`           // whether provider enforces moderation upstream
+	Active             bool
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Headers            map[string]string // extra request headers sent to the upstream
+	ModelAllowlist     []string          `json:"model_allowlist,omitempty"` // model key patterns synced as active; empty allows all
+	ModelDenylist      []string          `json:"model_denylist,omitempty"`  // model key patterns synced as inactive
+	LastSyncedAt       *time.Time
+	KeyRotatedAt       *time.Time
+	PreviousAPIKeyHint *string // hint of the key replaced by the last rotation
+	Shadow             bool    // receives sampled copies of live traffic but never serves clients
+	Priority           int     `json:"priority"` // orders providers within a scope, highest first
+	KeyMode            ProviderKeyMode
+	LastHealthCheckAt  *time.Time
+	LastHealthError    *string // error of the last health check, nil when it succeeded
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// ServesModels reports whether the provider's models can be routed to and listed. A deactivated
+// provider serves nothing even while its models are still marked active until the next sync.
+func (p *Provider) ServesModels() bool {
+	return p != nil && p.Active
 }
 
 // ProviderFilter defines optional conditions for querying providers.
@@ -60,10 +77,19 @@ type ProviderFilter struct {
 	ProjectIDs       *[]uint
 	WithoutProject   *bool
 	Kind             *ProviderKind
+	BaseURL          *string
 	Active           *bool
 	IsModerated      *bool
+	Shadow           *bool
+	HasAPIKey        *bool
 	LastSyncedAfter  *time.Time
 	LastSyncedBefore *time.Time
+	HealthStatus     *ProviderHealthStatus
+	// AccessibleProjectIDs limits results to providers without a project or of one of the listed
+	// projects, the providers a member of those projects can reach within an organization.
+	AccessibleProjectIDs *[]uint
+	// WithDeleted also matches deleted providers, so a pagination cursor outlives its row.
+	WithDeleted *bool
 }
 
 // ProviderRepository abstracts persistence for provider aggregate roots.
@@ -76,4 +102,5 @@ type ProviderRepository interface {
 	FindBySlug(ctx context.Context, slug string) (*Provider, error)
 	FindByFilter(ctx context.Context, filter ProviderFilter, p *query.Pagination) ([]*Provider, error)
 	Count(ctx context.Context, filter ProviderFilter) (int64, error)
+	UpdateHealth(ctx context.Context, id uint, checkedAt time.Time, healthError *string) error
 }
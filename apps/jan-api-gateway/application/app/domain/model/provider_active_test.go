@@ -0,0 +1,117 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestDeactivatedProviderIsNotRouted(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	org := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	global := &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Active: true}
+	registry.Providers.Add(org, global)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: org.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: global.ID, ModelKey: "gpt", Active: true},
+	)
+
+	setActive := func(active bool) {
+		t.Helper()
+		stored, _ := registry.Providers.FindByID(ctx, org.ID)
+		if _, err := registry.UpdateProvider(ctx, stored, domainmodel.UpdateProviderInput{Active: &active}); err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+	}
+	routed := func() *domainmodel.Provider {
+		t.Helper()
+		provider, err := registry.GetProviderForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel: %v", err)
+		}
+		return provider
+	}
+
+	if got := routed(); got.ID != org.ID {
+		t.Fatalf("routed to %s, want the organization provider", got.Slug)
+	}
+
+	// The models of the deactivated provider stay active until the next sync.
+	setActive(false)
+	if got := routed(); got.ID != global.ID {
+		t.Fatalf("routed to %s after deactivation, want the global provider", got.Slug)
+	}
+	if _, err := registry.GetProviderOverrideForModel(ctx, org.PublicID, "gpt", orgID, nil); err == nil {
+		t.Fatal("a deactivated provider was accepted as an override")
+	}
+
+	setActive(true)
+	if got := routed(); got.ID != org.ID {
+		t.Fatalf("routed to %s after reactivation, want the organization provider", got.Slug)
+	}
+}
+
+func TestDisabledProviderModelIsNotRouted(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	org := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	global := &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Active: true}
+	registry.Providers.Add(org, global)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{PublicID: "pmdl-org-gpt", ProviderID: org.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{PublicID: "pmdl-global-gpt", ProviderID: global.ID, ModelKey: "gpt", Active: true},
+	)
+
+	setActive := func(provider *domainmodel.Provider, modelPublicID string, active bool) {
+		t.Helper()
+		if _, err := registry.UpdateProviderModel(ctx, provider, modelPublicID, domainmodel.UpdateProviderModelInput{Active: &active}); err != nil {
+			t.Fatalf("UpdateProviderModel(%s): %v", modelPublicID, err)
+		}
+	}
+	routed := func() *domainmodel.Provider {
+		t.Helper()
+		provider, err := registry.GetProviderForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel: %v", err)
+		}
+		return provider
+	}
+
+	if got := routed(); got.ID != org.ID {
+		t.Fatalf("routed to %s, want the organization provider", got.Slug)
+	}
+
+	setActive(org, "pmdl-org-gpt", false)
+	if got := routed(); got.ID != global.ID {
+		t.Fatalf("routed to %s after disabling its model, want the global provider", got.Slug)
+	}
+	if _, err := registry.GetProviderOverrideForModel(ctx, org.PublicID, "gpt", orgID, nil); err == nil {
+		t.Fatal("a provider whose model is disabled was accepted as an override")
+	}
+
+	setActive(global, "pmdl-global-gpt", false)
+	if _, err := registry.GetProviderForModel(ctx, "gpt", orgID, nil); err == nil {
+		t.Fatal("a model disabled on every provider still resolves")
+	}
+
+	setActive(org, "pmdl-org-gpt", true)
+	if got := routed(); got.ID != org.ID {
+		t.Fatalf("routed to %s after re-enabling its model, want the organization provider", got.Slug)
+	}
+}
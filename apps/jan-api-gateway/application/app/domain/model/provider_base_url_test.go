@@ -0,0 +1,143 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestProviderBaseURLScheme(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	tests := []struct {
+		baseURL string
+		allowed bool
+	}{
+		{baseURL: "https://api.example.com/v1", allowed: true},
+		{baseURL: "HTTP://inference.internal:8000/v1", allowed: true},
+		{baseURL: "file:///etc/passwd"},
+		{baseURL: "ftp://files.example.com/v1"},
+		{baseURL: "https://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			registry := modeltest.NewRegistry()
+			registry.Lister.SetModels("example", "gpt-4o")
+			_, err := registry.RegisterProvider(ctx, domainmodel.RegisterProviderInput{
+				OrganizationID: orgID,
+				Name:           "Example",
+				Vendor:         "custom",
+				BaseURL:        tt.baseURL,
+				Active:         true,
+			})
+			checkBaseURLErr(t, "RegisterProvider", err, tt.allowed)
+
+			existing := &domainmodel.Provider{PublicID: "prov-existing", Slug: "existing", Kind: domainmodel.ProviderCustom, BaseURL: "https://existing.example.com/v1", OrganizationID: &orgID, Active: true}
+			registry.Providers.Add(existing)
+			baseURL := tt.baseURL
+			_, err = registry.UpdateProvider(ctx, existing, domainmodel.UpdateProviderInput{BaseURL: &baseURL})
+			checkBaseURLErr(t, "UpdateProvider", err, tt.allowed)
+			if !tt.allowed {
+				stored, _ := registry.Providers.FindByID(ctx, existing.ID)
+				if stored.BaseURL != "https://existing.example.com/v1" {
+					t.Fatalf("stored base_url = %q, want it unchanged", stored.BaseURL)
+				}
+			}
+		})
+	}
+}
+
+func checkBaseURLErr(t *testing.T, op string, err *common.Error, allowed bool) {
+	t.Helper()
+	if allowed {
+		if err != nil {
+			t.Fatalf("%s rejected the base_url: %v", op, err)
+		}
+		return
+	}
+	if err == nil || err.GetCode() != domainmodel.ErrCodeUnsupportedBaseURLScheme {
+		t.Fatalf("%s error = %v, want %s", op, err, domainmodel.ErrCodeUnsupportedBaseURLScheme)
+	}
+}
+
+func TestProviderBaseURLHost(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	env := &environment_variables.EnvironmentVariables
+	previousAllowed := env.MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS
+	defer func() { env.MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS = previousAllowed }()
+
+	tests := []struct {
+		name         string
+		baseURL      string
+		allowedHosts []string
+		allowed      bool
+	}{
+		{name: "public host", baseURL: "https://api.example.com/v1", allowed: true},
+		{name: "cluster hostname", baseURL: "http://inference.internal:8000/v1", allowed: true},
+		{name: "loopback address", baseURL: "http://127.0.0.1:11434/v1"},
+		{name: "ipv6 loopback", baseURL: "http://[::1]:11434/v1"},
+		{name: "localhost", baseURL: "http://LocalHost:11434/v1"},
+		{name: "private address", baseURL: "http://10.0.0.5/v1"},
+		{name: "metadata endpoint", baseURL: "http://169.254.169.254/latest"},
+		{name: "unspecified address", baseURL: "http://0.0.0.0:8000/v1"},
+		{name: "allowlisted name", baseURL: "http://localhost:11434/v1", allowedHosts: []string{" localhost "}, allowed: true},
+		{name: "allowlisted address", baseURL: "http://127.0.0.1:11434/v1", allowedHosts: []string{"127.0.0.1"}, allowed: true},
+		{name: "allowlisted prefix", baseURL: "http://10.0.3.7/v1", allowedHosts: []string{"10.0.0.0/16"}, allowed: true},
+		{name: "address outside the allowlisted prefix", baseURL: "http://10.1.3.7/v1", allowedHosts: []string{"10.0.0.0/16"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS = tt.allowedHosts
+			registry := modeltest.NewRegistry()
+			input := domainmodel.RegisterProviderInput{
+				OrganizationID: orgID,
+				Name:           "Example",
+				Vendor:         "custom",
+				BaseURL:        tt.baseURL,
+				Active:         true,
+			}
+
+			_, err := registry.RegisterProvider(ctx, input)
+			checkBaseURLHostErr(t, "RegisterProvider", err, tt.allowed)
+
+			_, err = registry.TestProviderConnection(ctx, input)
+			checkBaseURLHostErr(t, "TestProviderConnection", err, tt.allowed)
+
+			existing := &domainmodel.Provider{PublicID: "prov-existing", Slug: "existing", Kind: domainmodel.ProviderCustom, BaseURL: "https://existing.example.com/v1", OrganizationID: &orgID, Active: true}
+			registry.Providers.Add(existing)
+			baseURL := tt.baseURL
+			_, err = registry.UpdateProvider(ctx, existing, domainmodel.UpdateProviderInput{BaseURL: &baseURL})
+			checkBaseURLHostErr(t, "UpdateProvider", err, tt.allowed)
+
+			results, err := registry.ImportProviders(ctx, orgID, domainmodel.ProviderExport{
+				Version:   domainmodel.ProviderExportVersion,
+				Providers: []domainmodel.ProviderExportEntry{{Slug: "imported", Name: "Imported", Vendor: "custom", BaseURL: tt.baseURL, APIKey: "none", Active: true}},
+			})
+			if err != nil {
+				t.Fatalf("ImportProviders: %v", err)
+			}
+			checkBaseURLHostErr(t, "ImportProviders", results[0].Error, tt.allowed)
+		})
+	}
+}
+
+func checkBaseURLHostErr(t *testing.T, op string, err *common.Error, allowed bool) {
+	t.Helper()
+	rejected := err != nil && err.GetCode() == domainmodel.ErrCodeNonPublicBaseURL
+	if rejected == allowed {
+		t.Fatalf("%s error = %v, want the host allowed %t", op, err, allowed)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLocalProviderCacheRowsCheckVersion(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newLocalProviderCache()
+	cache.store("", accessibleProviderIDs{}, []*Provider{{ID: 1, BaseURL: "https://old.example.com", UpdatedAt: updatedAt}}, time.Minute)
+
+	tests := []struct {
+		name     string
+		versions map[uint]time.Time
+		wantHit  bool
+	}{
+		{name: "same version", versions: map[uint]time.Time{1: updatedAt}, wantHit: true},
+		{name: "row changed on another replica", versions: map[uint]time.Time{1: updatedAt.Add(time.Second)}},
+		{name: "scope without versions", versions: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, missing := cache.rows([]uint{1}, tt.versions)
+			if _, hit := rows[1]; hit != tt.wantHit {
+				t.Fatalf("hit = %t, want %t", hit, tt.wantHit)
+			}
+			if tt.wantHit == (len(missing) == 1) {
+				t.Fatalf("missing = %v", missing)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package model
+
+import (
+	"strings"
+
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+// capabilitySourceKey records in the model's raw payload where the probed capabilities came from.
+const capabilitySourceKey = "capability_source"
+
+const (
+	capabilitySourceUpstream = "upstream"
+	capabilitySourceTable    = "table"
+)
+
+// modelCapabilities describes a model family whose listing does not report its capabilities.
+type modelCapabilities struct {
+	prefix              string
+	images              bool
+	reasoning           bool
+	contextLength       int
+	maxCompletionTokens int
+}
+
+// knownModelCapabilities is the fallback used when the upstream listing carries no capability
+// detail. Entries are matched by model ID prefix and the longest matching prefix wins.
+var knownModelCapabilities = []modelCapabilities{
+	// OpenAI
+	{prefix: "gpt-3.5-turbo", contextLength: 16385, maxCompletionTokens: 4096},
+	{prefix: "gpt-4", contextLength: 8192, maxCompletionTokens: 8192},
+	{prefix: "gpt-4-turbo", images: true, contextLength: 128000, maxCompletionTokens: 4096},
+	{prefix: "gpt-4o", images: true, contextLength: 128000, maxCompletionTokens: 16384},
+	{prefix: "chatgpt-4o", images: true, contextLength: 128000, maxCompletionTokens: 16384},
+	{prefix: "gpt-4.1", images: true, contextLength: 1047576, maxCompletionTokens: 32768},
+	{prefix: "gpt-4.5", images: true, contextLength: 128000, maxCompletionTokens: 16384},
+	{prefix: "gpt-5", images: true, reasoning: true, contextLength: 400000, maxCompletionTokens: 128000},
+	{prefix: "gpt-5-chat", images: true, contextLength: 128000, maxCompletionTokens: 16384},
+	{prefix: "o1", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 100000},
+	{prefix: "o1-mini", reasoning: true, contextLength: 128000, maxCompletionTokens: 65536},
+	{prefix: "o3", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 100000},
+	{prefix: "o3-mini", reasoning: true, contextLength: 200000, maxCompletionTokens: 100000},
+	{prefix: "o4-mini", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 100000},
+	// Anthropic
+	{prefix: "claude-3", images: true, contextLength: 200000, maxCompletionTokens: 4096},
+	{prefix: "claude-3-5", images: true, contextLength: 200000, maxCompletionTokens: 8192},
+	{prefix: "claude-3-7", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 64000},
+	{prefix: "claude-sonnet-4", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 64000},
+	{prefix: "claude-opus-4", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 32000},
+	{prefix: "claude-haiku-4", images: true, reasoning: true, contextLength: 200000, maxCompletionTokens: 64000},
+	// Google
+	{prefix: "gemini-1.5", images: true},
+	{prefix: "gemini-2.0", images: true},
+	{prefix: "gemini-2.5", images: true, reasoning: true},
+	// Mistral
+	{prefix: "pixtral", images: true},
+	{prefix: "magistral", reasoning: true},
+}
+
+// ProbeProviderCapabilities fills in the image and reasoning capabilities of models listed by
+// providers other than OpenRouter, whose listings already describe them. Detail exposed by the
+// upstream listing is normalized first; otherwise the model family is looked up in a static
+// table. Models that gain capability detail are recorded so their catalog is marked filled.
+func ProbeProviderCapabilities(kind ProviderKind, models []chatclient.Model) []chatclient.Model {
+	if kind == ProviderOpenRouter {
+		return models
+	}
+	probed := make([]chatclient.Model, len(models))
+	for i, model := range models {
+		probed[i] = probeModelCapabilities(kind, model)
+	}
+	return probed
+}
+
+func probeModelCapabilities(kind ProviderKind, model chatclient.Model) chatclient.Model {
+	raw := copyMap(model.Raw)
+	if raw == nil {
+		raw = map[string]any{}
+	}
+
+	source := ""
+	if kind == ProviderGemini {
+		// The Gemini listing reports token limits and thinking support per model.
+		if maxOutput, ok := floatFromAny(raw["max_output_tokens"]); ok && maxOutput > 0 {
+			if _, exists := raw["max_completion_tokens"]; !exists {
+				raw["max_completion_tokens"] = maxOutput
+			}
+			source = capabilitySourceUpstream
+		}
+		if thinking, ok := raw["thinking"].(bool); ok && thinking {
+			addSupportedParameter(raw, "include_reasoning")
+		}
+	}
+
+	if capabilities, ok := lookupModelCapabilities(model.ID); ok {
+		if _, exists := raw["architecture"]; !exists {
+			inputModalities := []any{"text"}
+			if capabilities.images {
+				inputModalities = append(inputModalities, "image")
+			}
+			raw["architecture"] = map[string]any{
+				"input_modalities":  inputModalities,
+				"output_modalities": []any{"text"},
+			}
+		}
+		if capabilities.reasoning {
+			addSupportedParameter(raw, "include_reasoning")
+		}
+		if _, exists := raw["context_length"]; !exists && capabilities.contextLength > 0 {
+			raw["context_length"] = capabilities.contextLength
+		}
+		if _, exists := raw["max_completion_tokens"]; !exists && capabilities.maxCompletionTokens > 0 {
+			raw["max_completion_tokens"] = capabilities.maxCompletionTokens
+		}
+		if source == "" {
+			source = capabilitySourceTable
+		}
+	}
+
+	if source == "" {
+		return model
+	}
+	raw[capabilitySourceKey] = source
+	model.Raw = raw
+	return model
+}
+
+// lookupModelCapabilities matches the model ID, ignoring any vendor namespace, against the
+// longest known family prefix.
+func lookupModelCapabilities(modelID string) (modelCapabilities, bool) {
+	id := strings.ToLower(strings.TrimSpace(modelID))
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		id = id[idx+1:]
+	}
+
+	var match modelCapabilities
+	found := false
+	for _, candidate := range knownModelCapabilities {
+		if !strings.HasPrefix(id, candidate.prefix) {
+			continue
+		}
+		// gpt-4 must not claim gpt-4o-style IDs through a shorter prefix
+		rest := id[len(candidate.prefix):]
+		if rest != "" && rest[0] != '-' && rest[0] != '.' && rest[0] != ':' && rest[0] != '@' {
+			continue
+		}
+		if !found || len(candidate.prefix) > len(match.prefix) {
+			match = candidate
+			found = true
+		}
+	}
+	return match, found
+}
+
+func addSupportedParameter(raw map[string]any, name string) {
+	params := extractStringSlice(raw["supported_parameters"])
+	if containsString(params, name) {
+		return
+	}
+	values := make([]any, 0, len(params)+1)
+	for _, param := range params {
+		values = append(values, param)
+	}
+	raw["supported_parameters"] = append(values, name)
+}
+
+// capabilitiesProbed reports whether ProbeProviderCapabilities found detail for the model.
+func capabilitiesProbed(model chatclient.Model) bool {
+	source, _ := getString(model.Raw, capabilitySourceKey)
+	return source != ""
+}
@@ -0,0 +1,62 @@
+package model_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestGetProvidersForModelOrdersTheChain(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	projectID := uint(7)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	global := &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Active: true}
+	org := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	proj := &domainmodel.Provider{PublicID: "prov-project", Slug: "project", OrganizationID: &orgID, ProjectID: &projectID, Active: true}
+	other := &domainmodel.Provider{PublicID: "prov-other", Slug: "other", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(global, org, proj, other)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: global.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: org.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: proj.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: other.ID, ModelKey: "claude", Active: true},
+	)
+
+	tests := []struct {
+		name       string
+		projectIDs []uint
+		want       []string
+	}{
+		{name: "project member", projectIDs: []uint{projectID}, want: []string{"project", "org", "global"}},
+		{name: "organization only", want: []string{"org", "global"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers, err := registry.GetProvidersForModel(ctx, "gpt", orgID, tt.projectIDs)
+			if err != nil {
+				t.Fatalf("GetProvidersForModel: %v", err)
+			}
+			got := make([]string, 0, len(providers))
+			for _, provider := range providers {
+				got = append(got, provider.Slug)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("chain = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := registry.GetProvidersForModel(ctx, "unknown", orgID, nil); err == nil {
+		t.Fatal("GetProvidersForModel returned a chain for a model nobody serves")
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// ErrCodeInvalidDefaultProvider is returned when the provider chosen as an organization default
+// is not an organization-level provider of that organization.
+const ErrCodeInvalidDefaultProvider = "9d4f1b7e-3a6c-4e82-b5d0-c7e2a8f3b619"
+
+// SetOrganizationDefaultProvider makes the organization provider with the given public ID the
+// organization's fallback for models no accessible provider advertises. An empty ID clears the
+// default so the Jan provider is used again. The provider is returned, or nil when cleared.
+func (s *ProviderRegistryService) SetOrganizationDefaultProvider(ctx context.Context, org *organization.Organization, providerPublicID string) (*Provider, *common.Error) {
+	publicID := strings.TrimSpace(providerPublicID)
+	var provider *Provider
+	if publicID != "" {
+		found, err := s.FindByPublicID(ctx, publicID)
+		if err != nil {
+			if err.GetCode() == "d16271bf-54f5-4b25-bbd2-2353f1d5265c" {
+				return nil, common.NewErrorWithMessage("default provider not found", ErrCodeInvalidDefaultProvider)
+			}
+			return nil, err
+		}
+		if found.OrganizationID == nil || *found.OrganizationID != org.ID || found.ProjectID != nil {
+			return nil, common.NewErrorWithMessage("default provider must be an organization provider", ErrCodeInvalidDefaultProvider)
+		}
+		if found.Shadow {
+			return nil, common.NewErrorWithMessage("shadow providers cannot be the default provider", ErrCodeInvalidDefaultProvider)
+		}
+		provider = found
+	}
+
+	org.DefaultProviderID = nil
+	if provider != nil {
+		org.DefaultProviderID = &provider.ID
+	}
+	if _, err := s.organizationService.UpdateOrganization(ctx, org); err != nil {
+		return nil, common.NewError(err, "2c8e5a3f-7b1d-4f96-a0e4-d6b9c3f1e857")
+	}
+	return provider, nil
+}
+
+// FindOrganizationDefaultProvider returns the organization's configured default provider, or nil
+// when none is set or it no longer exists.
+func (s *ProviderRegistryService) FindOrganizationDefaultProvider(ctx context.Context, org *organization.Organization) (*Provider, error) {
+	if org == nil || org.DefaultProviderID == nil {
+		return nil, nil
+	}
+	provider, err := s.providerRepo.FindByID(ctx, *org.DefaultProviderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return provider, nil
+}
+
+// clearOrganizationDefaultProvider unsets the provider as its organization's default provider.
+func (s *ProviderRegistryService) clearOrganizationDefaultProvider(ctx context.Context, provider *Provider) *common.Error {
+	if s.organizationService == nil || provider.OrganizationID == nil {
+		return nil
+	}
+	org, err := s.organizationService.FindOrganizationByID(ctx, *provider.OrganizationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return common.NewError(err, "f1c8a4e6-2d9b-4b73-a5e0-8d3f6c9b2e417")
+	}
+	if org.DefaultProviderID == nil || *org.DefaultProviderID != provider.ID {
+		return nil
+	}
+	org.DefaultProviderID = nil
+	if _, err := s.organizationService.UpdateOrganization(ctx, org); err != nil {
+		return common.NewError(err, "8b5d2f9c-4e1a-4c36-9f07-e6a3c8d1b952")
+	}
+	return nil
+}
+
+// organizationDefaultProvider returns the organization's default provider when it can take traffic.
+// Lookup failures are logged and treated as no default, so routing falls back to the Jan provider.
+func (s *ProviderRegistryService) organizationDefaultProvider(ctx context.Context, organizationID uint) *Provider {
+	if s.organizationService == nil {
+		return nil
+	}
+	org, err := s.organizationService.FindOrganizationByID(ctx, organizationID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.GetLogger().Errorf("failed to load organization %d for its default provider: %v", organizationID, err)
+		}
+		return nil
+	}
+	provider, err := s.FindOrganizationDefaultProvider(ctx, org)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to load default provider of organization %d: %v", organizationID, err)
+		return nil
+	}
+	if provider == nil || !provider.Active || provider.OrganizationID == nil || *provider.OrganizationID != organizationID {
+		return nil
+	}
+	if !s.isProviderAvailable(provider) {
+		return nil
+	}
+	return provider
+}
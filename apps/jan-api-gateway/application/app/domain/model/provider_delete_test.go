@@ -0,0 +1,129 @@
+package model_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestDeleteProvider(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(1)
+
+	setup := func() (*modeltest.Registry, *domainmodel.Provider, *domainmodel.Provider) {
+		registry := modeltest.NewRegistry()
+		deleted := &domainmodel.Provider{PublicID: "prov-deleted", Slug: "deleted", OrganizationID: &orgID, Active: true}
+		kept := &domainmodel.Provider{PublicID: "prov-kept", Slug: "kept", OrganizationID: &orgID, Active: true}
+		registry.Providers.Add(deleted, kept)
+		registry.Organizations.Add(&organization.Organization{PublicID: "org", DefaultProviderID: &deleted.ID})
+
+		shared := &domainmodel.ModelCatalog{PublicID: "shared"}
+		orphan := &domainmodel.ModelCatalog{PublicID: "orphan"}
+		curated := &domainmodel.ModelCatalog{PublicID: "curated", Status: domainmodel.ModelCatalogStatusUpdated}
+		registry.Catalogs.Add(shared, orphan, curated)
+		registry.Models.Add(
+			&domainmodel.ProviderModel{ProviderID: deleted.ID, ModelKey: "shared", ModelCatalogID: &shared.ID, Active: true},
+			&domainmodel.ProviderModel{ProviderID: deleted.ID, ModelKey: "orphan", ModelCatalogID: &orphan.ID, Active: true},
+			&domainmodel.ProviderModel{ProviderID: deleted.ID, ModelKey: "curated", ModelCatalogID: &curated.ID, Active: true},
+			&domainmodel.ProviderModel{ProviderID: kept.ID, ModelKey: "shared", ModelCatalogID: &shared.ID, Active: true},
+		)
+		registry.Aliases.Add(
+			&domainmodel.ModelAlias{OrganizationID: orgID, Alias: "fast", ProviderID: deleted.ID, ModelKey: "orphan"},
+			&domainmodel.ModelAlias{OrganizationID: orgID, Alias: "stable", ProviderID: kept.ID, ModelKey: "shared"},
+		)
+		return registry, deleted, kept
+	}
+
+	t.Run("cascades to models, aliases, catalogs and the organization default", func(t *testing.T) {
+		registry, deleted, kept := setup()
+		if err := registry.DeleteProvider(ctx, deleted); err != nil {
+			t.Fatalf("DeleteProvider: %v", err)
+		}
+
+		if _, err := registry.Providers.FindByID(ctx, deleted.ID); err == nil {
+			t.Fatal("deleted provider still exists")
+		}
+		for _, pm := range registry.Models.All() {
+			if pm.ProviderID == deleted.ID {
+				t.Fatalf("model %s of the deleted provider still exists", pm.ModelKey)
+			}
+		}
+		aliases, _ := registry.Aliases.FindByFilter(ctx, domainmodel.ModelAliasFilter{}, nil)
+		if len(aliases) != 1 || aliases[0].ProviderID != kept.ID {
+			t.Fatalf("aliases = %+v, want only the alias of the kept provider", aliases)
+		}
+		catalogs := registry.Catalogs.All()
+		if len(catalogs) != 2 || catalogs[0].PublicID != "shared" || catalogs[1].PublicID != "curated" {
+			t.Fatalf("catalogs = %+v, want the shared and the curated catalog", catalogs)
+		}
+		org, _ := registry.Organizations.FindByID(ctx, orgID)
+		if org.DefaultProviderID != nil {
+			t.Fatalf("organization default provider = %d, want cleared", *org.DefaultProviderID)
+		}
+	})
+
+	t.Run("keeps the default of another provider", func(t *testing.T) {
+		registry, deleted, kept := setup()
+		org, _ := registry.Organizations.FindByID(ctx, orgID)
+		org.DefaultProviderID = &kept.ID
+		_ = registry.Organizations.Update(ctx, org)
+
+		if err := registry.DeleteProvider(ctx, deleted); err != nil {
+			t.Fatalf("DeleteProvider: %v", err)
+		}
+		org, _ = registry.Organizations.FindByID(ctx, orgID)
+		if org.DefaultProviderID == nil || *org.DefaultProviderID != kept.ID {
+			t.Fatalf("organization default provider = %v, want %d", org.DefaultProviderID, kept.ID)
+		}
+	})
+
+	t.Run("rolls back everything when a step fails", func(t *testing.T) {
+		registry, deleted, _ := setup()
+		registry.Catalogs.Errors["DeleteByID"] = errors.New("catalog is locked")
+
+		if err := registry.DeleteProvider(ctx, deleted); err == nil {
+			t.Fatal("DeleteProvider succeeded, want the catalog error")
+		}
+		if registry.Transactor.RolledBack != 1 {
+			t.Fatalf("rolled back %d transactions, want 1", registry.Transactor.RolledBack)
+		}
+		if _, err := registry.Providers.FindByID(ctx, deleted.ID); err != nil {
+			t.Fatalf("provider was deleted despite the rollback: %v", err)
+		}
+		if models := registry.Models.All(); len(models) != 4 {
+			t.Fatalf("got %d models, want all 4 restored", len(models))
+		}
+		if count, _ := registry.Aliases.Count(ctx, domainmodel.ModelAliasFilter{}); count != 2 {
+			t.Fatalf("got %d aliases, want both restored", count)
+		}
+		org, _ := registry.Organizations.FindByID(ctx, orgID)
+		if org.DefaultProviderID == nil || *org.DefaultProviderID != deleted.ID {
+			t.Fatal("organization default provider was cleared despite the rollback")
+		}
+	})
+	t.Run("works without model aliases", func(t *testing.T) {
+		registry, deleted, _ := setup()
+		service := domainmodel.NewProviderRegistryService(
+			registry.Providers,
+			registry.ProviderModelService,
+			registry.ModelCatalogService,
+			registry.Lister,
+			registry.Availability,
+			nil,
+			registry.OrganizationService,
+			nil,
+			nil,
+			registry.Transactor,
+		)
+		if err := service.DeleteProvider(ctx, deleted); err != nil {
+			t.Fatalf("DeleteProvider: %v", err)
+		}
+		if _, err := registry.Providers.FindByID(ctx, deleted.ID); err == nil {
+			t.Fatal("deleted provider still exists")
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+// ProviderExportVersion is the version of the provider export document. Imports of any other
+// version are rejected.
+const ProviderExportVersion = 1
+
+// ErrCodeInvalidProviderImport is returned when an import document has an unsupported version or
+// an entry lacks the secrets that exports leave out.
+const ErrCodeInvalidProviderImport = "5c8e2a7f-3d91-4b46-a0e5-b7f1d4c9e623"
+
+// ProviderExport is the document produced by ExportProviders and consumed by ImportProviders.
+type ProviderExport struct {
+	Version    int                   `json:"version"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Providers  []ProviderExportEntry `json:"providers"`
+}
+
+// ProviderExportEntry is one exported provider. Secrets never leave the gateway: secret metadata
+// values and every custom header value are masked and only the API key hint is exported.
+type ProviderExportEntry struct {
+	Slug           string            `json:"slug"`
+	Name           string            `json:"name"`
+	Vendor         string            `json:"vendor"`
+	BaseURL        string            `json:"base_url"`
+	PathPrefix     string            `json:"path_prefix,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	ModelAllowlist []string          `json:"model_allowlist,omitempty"`
+	ModelDenylist  []string          `json:"model_denylist,omitempty"`
+	Active         bool              `json:"active"`
+	Shadow         bool              `json:"shadow"`
+	Priority       int               `json:"priority"`
+	KeyMode        string            `json:"key_mode,omitempty"`
+	APIKeyHint     *string           `json:"api_key_hint,omitempty"`
+	// APIKey is never exported; an import must supply a fresh key, or "none" for upstreams that
+	// need none, unless the key mode is passthrough.
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// ProviderImportResult reports the outcome of importing one entry. Error is set when the provider
+// could not be created; Models holds the models synced for a created provider.
+type ProviderImportResult struct {
+	Slug     string
+	Provider *Provider
+	Models   []ProviderModelSyncResult
+	Error    *common.Error
+}
+
+// ExportProviders returns the organization-level providers of the organization as an export
+// document. Project providers are left out because project IDs do not carry over between
+// environments.
+func (s *ProviderRegistryService) ExportProviders(ctx context.Context, organizationID uint) (*ProviderExport, *common.Error) {
+	providers, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+		WithoutProject: ptr.ToBool(true),
+	}, &query.Pagination{Order: "asc"})
+	if err != nil {
+		return nil, common.NewError(err, "e2b6d9a4-7c13-4f58-9a0e-c5d1f8b3a746")
+	}
+
+	export := &ProviderExport{
+		Version:    ProviderExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Providers:  make([]ProviderExportEntry, 0, len(providers)),
+	}
+	for _, provider := range providers {
+		export.Providers = append(export.Providers, ProviderExportEntry{
+			Slug:           provider.Slug,
+			Name:           provider.DisplayName,
+			Vendor:         strings.ToLower(string(provider.Kind)),
+			BaseURL:        provider.BaseURL,
+			PathPrefix:     provider.PathPrefix,
+			Metadata:       MaskedMetadata(provider.Metadata),
+			Headers:        maskedHeaders(provider.Headers),
+			ModelAllowlist: provider.ModelAllowlist,
+			ModelDenylist:  provider.ModelDenylist,
+			Active:         provider.Active,
+			Shadow:         provider.Shadow,
+			Priority:       provider.Priority,
+			KeyMode:        string(provider.KeyMode),
+			APIKeyHint:     provider.APIKeyHint,
+		})
+	}
+	return export, nil
+}
+
+// ImportProviders recreates the providers of an export document in the organization and syncs
+// their models. The whole document is validated first: every entry needs a fresh API key, unless
+// its key mode is passthrough, and real values for its secret metadata and custom headers. Entries are then imported one by one, so a failing entry,
+// for example one whose slug is taken, does not stop the others.
+func (s *ProviderRegistryService) ImportProviders(ctx context.Context, organizationID uint, export ProviderExport) ([]ProviderImportResult, *common.Error) {
+	if export.Version != ProviderExportVersion {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("unsupported export version %d", export.Version), ErrCodeInvalidProviderImport)
+	}
+	for i, entry := range export.Providers {
+		passthrough := ProviderKeyMode(strings.ToLower(strings.TrimSpace(entry.KeyMode))) == ProviderKeyModePassthrough
+		if strings.TrimSpace(entry.APIKey) == "" && !passthrough {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("providers[%d]: api_key is required, exports do not include secrets", i), ErrCodeInvalidProviderImport)
+		}
+		for key, value := range entry.Metadata {
+			if IsSecretMetadataKey(key) && strings.TrimSpace(value) == MaskedMetadataValue {
+				return nil, common.NewErrorWithMessage(fmt.Sprintf("providers[%d]: metadata %s must be supplied, exports do not include secrets", i, key), ErrCodeInvalidProviderImport)
+			}
+		}
+		for name, value := range entry.Headers {
+			if strings.TrimSpace(value) == MaskedMetadataValue {
+				return nil, common.NewErrorWithMessage(fmt.Sprintf("providers[%d]: header %s must be supplied, exports do not include header values", i, name), ErrCodeInvalidProviderImport)
+			}
+		}
+	}
+
+	results := make([]ProviderImportResult, 0, len(export.Providers))
+	for _, entry := range export.Providers {
+		result := ProviderImportResult{Slug: entry.Slug}
+		registration, err := s.RegisterProvider(ctx, RegisterProviderInput{
+			OrganizationID: organizationID,
+			Name:           entry.Name,
+			Vendor:         entry.Vendor,
+			BaseURL:        entry.BaseURL,
+			APIKey:         entry.APIKey,
+			Metadata:       entry.Metadata,
+			Headers:        entry.Headers,
+			PathPrefix:     entry.PathPrefix,
+			ModelAllowlist: entry.ModelAllowlist,
+			ModelDenylist:  entry.ModelDenylist,
+			Active:         entry.Active,
+			Shadow:         entry.Shadow,
+			Priority:       entry.Priority,
+			KeyMode:        entry.KeyMode,
+			Slug:           entry.Slug,
+		})
+		if err != nil {
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
+		result.Provider = registration.Provider
+		result.Models, result.Error = s.RefreshProviderModels(ctx, registration.Provider)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// maskedHeaders returns the header names with masked values. Custom headers often carry tokens,
+// so unlike metadata none of their values are exported.
+func maskedHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	masked := make(map[string]string, len(headers))
+	for name := range headers {
+		masked[name] = MaskedMetadataValue
+	}
+	return masked
+}
@@ -0,0 +1,198 @@
+package model_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestProviderExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret"
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+
+	sourceOrgID, targetOrgID := uint(2), uint(3)
+	inputs := []domainmodel.RegisterProviderInput{
+		{
+			OrganizationID: sourceOrgID,
+			Slug:           "acme-openai",
+			Name:           "Acme OpenAI",
+			Vendor:         "openai",
+			BaseURL:        "https://api.openai.com/v1",
+			APIKey:         "sk-source-openai-1234",
+			Headers:        map[string]string{"X-Internal-Token": "do-not-export"},
+			ModelDenylist:  []string{"gpt-3.5-turbo"},
+			Active:         true,
+			Priority:       5,
+		},
+		{
+			OrganizationID: sourceOrgID,
+			Slug:           "acme-gateway",
+			Name:           "Acme Gateway",
+			Vendor:         "custom",
+			BaseURL:        "https://gateway.acme.example/",
+			PathPrefix:     "/api/v1",
+			APIKey:         "none",
+			Metadata:       map[string]string{"region": "eu-west-1", "aws_secret_access_key_secret": "wJalrXUtnFEMI"},
+			Shadow:         true,
+		},
+		{
+			OrganizationID: sourceOrgID,
+			Slug:           "acme-byok",
+			Name:           "Acme BYOK",
+			Vendor:         "custom",
+			BaseURL:        "https://byok.acme.example/v1",
+			KeyMode:        string(domainmodel.ProviderKeyModePassthrough),
+			Active:         true,
+		},
+	}
+	newRegistry := func() *modeltest.Registry {
+		registry := modeltest.NewRegistry()
+		registry.Lister.SetModels("acme-openai", "gpt-4o", "gpt-3.5-turbo")
+		registry.Lister.SetModels("acme-gateway", "llama-3.1-70b")
+		registry.Lister.SetModels("acme-byok", "gpt-4o-mini")
+		return registry
+	}
+
+	source := newRegistry()
+	for _, input := range inputs {
+		if _, err := source.RegisterProvider(ctx, input); err != nil {
+			t.Fatalf("RegisterProvider(%s): %v", input.Slug, err)
+		}
+	}
+	exported, err := source.ExportProviders(ctx, sourceOrgID)
+	if err != nil {
+		t.Fatalf("ExportProviders: %v", err)
+	}
+	document, marshalErr := json.Marshal(exported)
+	if marshalErr != nil {
+		t.Fatalf("Marshal: %v", marshalErr)
+	}
+	for _, secret := range []string{"sk-source-openai-1234", "do-not-export", "wJalrXUtnFEMI", `"api_key"`} {
+		if strings.Contains(string(document), secret) {
+			t.Fatalf("export contains %s: %s", secret, document)
+		}
+	}
+
+	// The document as an operator would edit it: fresh keys and real secret values.
+	var export domainmodel.ProviderExport
+	if err := json.Unmarshal(document, &export); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if export.Version != domainmodel.ProviderExportVersion || len(export.Providers) != len(inputs) {
+		t.Fatalf("export = version %d with %d providers, want version %d with %d", export.Version, len(export.Providers), domainmodel.ProviderExportVersion, len(inputs))
+	}
+	if hint := export.Providers[0].APIKeyHint; hint == nil || !strings.HasSuffix(*hint, "1234") {
+		t.Fatalf("api_key_hint = %v, want the hint of the source key", hint)
+	}
+	if export.Providers[1].Metadata["aws_secret_access_key_secret"] != domainmodel.MaskedMetadataValue {
+		t.Fatalf("secret metadata = %q, want it masked", export.Providers[1].Metadata["aws_secret_access_key_secret"])
+	}
+	// Custom headers are named so the operator knows to supply them, but their values are masked
+	if value, ok := export.Providers[0].Headers["X-Internal-Token"]; !ok || value != domainmodel.MaskedMetadataValue {
+		t.Fatalf("headers = %v, want X-Internal-Token masked", export.Providers[0].Headers)
+	}
+	withSecrets := func(export domainmodel.ProviderExport) domainmodel.ProviderExport {
+		providers := make([]domainmodel.ProviderExportEntry, len(export.Providers))
+		copy(providers, export.Providers)
+		providers[0].APIKey = "sk-target-openai-5678"
+		providers[0].Headers = map[string]string{"X-Internal-Token": "rotated-token"}
+		providers[1].APIKey = "none"
+		providers[1].Metadata = map[string]string{"region": "eu-west-1", "aws_secret_access_key_secret": "rotated-secret"}
+		export.Providers = providers
+		return export
+	}
+
+	invalid := []struct {
+		name   string
+		export func() domainmodel.ProviderExport
+	}{
+		{name: "missing api key", export: func() domainmodel.ProviderExport { return export }},
+		{name: "masked secret metadata", export: func() domainmodel.ProviderExport {
+			edited := withSecrets(export)
+			edited.Providers[1].Metadata = export.Providers[1].Metadata
+			return edited
+		}},
+		{name: "masked header", export: func() domainmodel.ProviderExport {
+			edited := withSecrets(export)
+			edited.Providers[0].Headers = export.Providers[0].Headers
+			return edited
+		}},
+		{name: "other version", export: func() domainmodel.ProviderExport {
+			edited := withSecrets(export)
+			edited.Version = domainmodel.ProviderExportVersion + 1
+			return edited
+		}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newRegistry()
+			_, err := target.ImportProviders(ctx, targetOrgID, tt.export())
+			if err == nil || err.GetCode() != domainmodel.ErrCodeInvalidProviderImport {
+				t.Fatalf("ImportProviders error = %v, want invalid import", err)
+			}
+			if count, _ := target.Providers.Count(ctx, domainmodel.ProviderFilter{}); count != 0 {
+				t.Fatalf("a rejected import created %d providers", count)
+			}
+		})
+	}
+
+	target := newRegistry()
+	results, importErr := target.ImportProviders(ctx, targetOrgID, withSecrets(export))
+	if importErr != nil {
+		t.Fatalf("ImportProviders: %v", importErr)
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			t.Fatalf("import of %s: %v", result.Slug, result.Error)
+		}
+	}
+	reexported, err := target.ExportProviders(ctx, targetOrgID)
+	if err != nil {
+		t.Fatalf("ExportProviders: %v", err)
+	}
+	for i, want := range export.Providers {
+		got := reexported.Providers[i]
+		if got.Slug != want.Slug || got.Name != want.Name || got.Vendor != want.Vendor || got.BaseURL != want.BaseURL ||
+			got.PathPrefix != want.PathPrefix || got.Active != want.Active || got.Shadow != want.Shadow || got.Priority != want.Priority ||
+			strings.Join(got.ModelDenylist, ",") != strings.Join(want.ModelDenylist, ",") || got.Metadata["region"] != want.Metadata["region"] {
+			t.Fatalf("round trip of %s = %+v, want %+v", want.Slug, got, want)
+		}
+	}
+	if _, ok := reexported.Providers[0].Headers["X-Internal-Token"]; !ok {
+		t.Fatalf("imported headers = %v, want X-Internal-Token", reexported.Providers[0].Headers)
+	}
+	if got := reexported.Providers[2]; got.KeyMode != string(domainmodel.ProviderKeyModePassthrough) || got.APIKeyHint != nil {
+		t.Fatalf("imported passthrough provider has key mode %q and hint %v, want passthrough without a key", got.KeyMode, got.APIKeyHint)
+	}
+	if hint := reexported.Providers[0].APIKeyHint; hint == nil || !strings.HasSuffix(*hint, "5678") {
+		t.Fatalf("imported api_key_hint = %v, want the hint of the fresh key", hint)
+	}
+	if models := results[0].Models; len(models) == 0 {
+		t.Fatal("the imported provider was not synced")
+	}
+
+	t.Run("taken slugs fail per entry", func(t *testing.T) {
+		results, err := target.ImportProviders(ctx, targetOrgID, withSecrets(export))
+		if err != nil {
+			t.Fatalf("ImportProviders: %v", err)
+		}
+		for _, result := range results {
+			if result.Error == nil {
+				t.Fatalf("import of %s into an organization that already has it succeeded", result.Slug)
+			}
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// FallbackReason is a sanitized explanation of why SelectProviderForModel fell back, safe to send
+// to clients.
+type FallbackReason string
+
+const (
+	// FallbackReasonNoAccessibleProviders means the caller has no accessible providers.
+	FallbackReasonNoAccessibleProviders FallbackReason = "no_accessible_providers"
+	// FallbackReasonModelNotServed means no accessible provider serves the model.
+	FallbackReasonModelNotServed FallbackReason = "model_not_served"
+	// FallbackReasonProviderUnavailable means the providers serving the model are unavailable,
+	// e.g. because their circuit is open.
+	FallbackReasonProviderUnavailable FallbackReason = "provider_unavailable"
+	// FallbackReasonResolutionFailed covers any other resolution error.
+	FallbackReasonResolutionFailed FallbackReason = "resolution_failed"
+)
+
+// providerFallbackFailuresTTL is how long a provider's fallback failure count is kept after its
+// last failure.
+const providerFallbackFailuresTTL = 24 * time.Hour
+
+// ProviderResolutionError is returned by GetProvidersForModel when no provider can serve a model.
+// Providers lists the providers that serve the model but were unavailable.
+type ProviderResolutionError struct {
+	Reason    FallbackReason
+	Providers []*Provider
+	message   string
+}
+
+func (e *ProviderResolutionError) Error() string {
+	return e.message
+}
+
+// FallbackReasonOf maps a provider resolution error to its sanitized reason.
+func FallbackReasonOf(err error) FallbackReason {
+	var resolutionErr *ProviderResolutionError
+	if errors.As(err, &resolutionErr) {
+		return resolutionErr.Reason
+	}
+	return FallbackReasonResolutionFailed
+}
+
+// recordFallbackFailures increments the fallback failure count of each unavailable provider in
+// the resolution error. Cache errors are logged and otherwise ignored.
+func (s *ProviderRegistryService) recordFallbackFailures(ctx context.Context, resolveErr error) {
+	var resolutionErr *ProviderResolutionError
+	if s.cache == nil || !errors.As(resolveErr, &resolutionErr) {
+		return
+	}
+	expireAt := time.Now().Add(providerFallbackFailuresTTL)
+	for _, provider := range resolutionErr.Providers {
+		if provider == nil || provider.ID == 0 {
+			continue
+		}
+		if _, err := s.cache.IncrBy(ctx, fmt.Sprintf(cache.ProviderFallbackFailuresKey, provider.ID), 1, expireAt); err != nil {
+			logger.GetLogger().Errorf("failed to record fallback failure for provider %s: %v", provider.PublicID, err)
+		}
+	}
+}
+
+// ProviderFallbackFailures returns how many requests fell back to a default provider because the
+// provider was unavailable, counted since the provider last went a day without such a failure.
+func (s *ProviderRegistryService) ProviderFallbackFailures(ctx context.Context, providerID uint) (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.GetInt64(ctx, fmt.Sprintf(cache.ProviderFallbackFailuresKey, providerID))
+}
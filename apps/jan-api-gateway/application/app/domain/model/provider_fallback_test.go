@@ -0,0 +1,150 @@
+package model_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache/cachetest"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestSelectProviderForModelFallbackReasons(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previousURL := environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL
+	environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = "http://jan-inference:8000/v1"
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = previousURL })
+
+	tests := []struct {
+		name        string
+		model       string
+		noProviders bool
+		unavailable bool
+		reason      domainmodel.FallbackReason
+	}{
+		{name: "matched", model: "gpt-4o"},
+		{name: "no accessible providers", model: "gpt-4o", noProviders: true, reason: domainmodel.FallbackReasonNoAccessibleProviders},
+		{name: "model not served", model: "claude-3", reason: domainmodel.FallbackReasonModelNotServed},
+		{name: "provider unavailable", model: "gpt-4o", unavailable: true, reason: domainmodel.FallbackReasonProviderUnavailable},
+		{name: "resolution failed", model: " ", reason: domainmodel.FallbackReasonResolutionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := modeltest.NewRegistry()
+			openai := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, BaseURL: "https://api.openai.com/v1", OrganizationID: &orgID, Active: true}
+			if !tt.noProviders {
+				registry.Providers.Add(openai)
+				registry.Models.Add(&domainmodel.ProviderModel{ProviderID: openai.ID, ModelKey: "gpt-4o", Active: true})
+				registry.Availability.SetAvailable(openai.ID, !tt.unavailable)
+			}
+
+			selection, err := registry.SelectProviderForModel(ctx, tt.model, orgID, nil)
+			if err != nil {
+				t.Fatalf("SelectProviderForModel: %v", err)
+			}
+			if selection.FallbackReason != tt.reason {
+				t.Fatalf("reason = %q, want %q", selection.FallbackReason, tt.reason)
+			}
+			providers, reason, err := registry.SelectProvidersForModel(ctx, tt.model, orgID, nil)
+			if err != nil {
+				t.Fatalf("SelectProvidersForModel: %v", err)
+			}
+			if reason != tt.reason {
+				t.Fatalf("chain reason = %q, want %q", reason, tt.reason)
+			}
+			wantKind := domainmodel.ProviderJan
+			if tt.reason == "" {
+				wantKind = domainmodel.ProviderOpenAI
+			}
+			if len(providers) != 1 || providers[0].Kind != wantKind || selection.Provider.Kind != wantKind {
+				t.Fatalf("selected %s, want a single %s provider", selection.Provider.Slug, wantKind)
+			}
+		})
+	}
+
+	t.Run("no fallback provider", func(t *testing.T) {
+		environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = ""
+		registry := modeltest.NewRegistry()
+		if _, err := registry.SelectProviderForModel(ctx, "gpt-4o", orgID, nil); err == nil {
+			t.Fatal("SelectProviderForModel fell back without a Jan provider")
+		}
+		if _, _, err := registry.SelectProvidersForModel(ctx, "gpt-4o", orgID, nil); err == nil {
+			t.Fatal("SelectProvidersForModel fell back without a Jan provider")
+		}
+	})
+}
+
+func TestFallbackFailuresAreCountedPerUnavailableProvider(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previousURL := environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL
+	environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = "http://jan-inference:8000/v1"
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = previousURL })
+
+	registry := modeltest.NewRegistry()
+	openai := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, BaseURL: "https://api.openai.com/v1", OrganizationID: &orgID, Active: true}
+	mistral := &domainmodel.Provider{PublicID: "prov-mistral", Slug: "mistral", Kind: domainmodel.ProviderMistral, BaseURL: "https://api.mistral.ai/v1", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(openai, mistral)
+	registry.Models.Add(&domainmodel.ProviderModel{ProviderID: openai.ID, ModelKey: "gpt-4o", Active: true})
+	registry.Models.Add(&domainmodel.ProviderModel{ProviderID: mistral.ID, ModelKey: "mistral-large", Active: true})
+	registry.Availability.SetAvailable(openai.ID, false)
+	registry.Availability.SetAvailable(mistral.ID, false)
+	redisCache, store := cachetest.NewCache()
+	service := domainmodel.NewProviderRegistryService(
+		registry.Providers,
+		registry.ProviderModelService,
+		registry.ModelCatalogService,
+		registry.Lister,
+		registry.Availability,
+		registry.ModelAliasService,
+		registry.OrganizationService,
+		nil,
+		redisCache,
+		registry.Transactor,
+	)
+
+	before := time.Now()
+	if _, err := service.SelectProviderForModel(ctx, "gpt-4o", orgID, nil); err != nil {
+		t.Fatalf("SelectProviderForModel: %v", err)
+	}
+	if _, _, err := service.SelectProvidersForModel(ctx, "gpt-4o", orgID, nil); err != nil {
+		t.Fatalf("SelectProvidersForModel: %v", err)
+	}
+	// A model nobody serves is not the fault of any provider
+	if _, err := service.SelectProviderForModel(ctx, "claude-3", orgID, nil); err != nil {
+		t.Fatalf("SelectProviderForModel: %v", err)
+	}
+
+	tests := []struct {
+		provider *domainmodel.Provider
+		want     int64
+	}{
+		{provider: openai, want: 2},
+		{provider: mistral, want: 0},
+	}
+	for _, tt := range tests {
+		got, err := service.ProviderFallbackFailures(ctx, tt.provider.ID)
+		if err != nil {
+			t.Fatalf("ProviderFallbackFailures(%s): %v", tt.provider.Slug, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s fallback failures = %d, want %d", tt.provider.Slug, got, tt.want)
+		}
+	}
+	expireAt, ok := store.ExpireAt(fmt.Sprintf(cache.ProviderFallbackFailuresKey, openai.ID))
+	if !ok || expireAt.Before(before.Add(23*time.Hour)) {
+		t.Fatalf("fallback failures expire at %v, want about a day from now", expireAt)
+	}
+}
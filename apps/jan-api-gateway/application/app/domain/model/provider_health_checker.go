@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/metrics"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+const (
+	providerHealthCheckTimeout     = 10 * time.Second
+	providerHealthCheckConcurrency = 8
+)
+
+// ProviderHealthChecker lists the models of every active provider and records the outcome on
+// the provider so admins can see which upstreams are failing.
+type ProviderHealthChecker struct {
+	providerRepo   ProviderRepository
+	modelLister    ProviderModelLister
+	webhookService *webhook.WebhookService
+	running        atomic.Bool
+}
+
+func NewProviderHealthChecker(providerRepo ProviderRepository, modelLister ProviderModelLister, webhookService *webhook.WebhookService) *ProviderHealthChecker {
+	return &ProviderHealthChecker{
+		providerRepo:   providerRepo,
+		modelLister:    modelLister,
+		webhookService: webhookService,
+	}
+}
+
+// CheckAll runs one sweep over all active providers. Checks run with bounded concurrency and a
+// per-provider timeout so a slow upstream cannot hold up the rest. A sweep that starts while the
+// previous one is still running is skipped.
+func (c *ProviderHealthChecker) CheckAll(ctx context.Context) {
+	if !c.running.CompareAndSwap(false, true) {
+		logger.GetLogger().Warn("provider health check skipped: previous sweep still running")
+		return
+	}
+	defer c.running.Store(false)
+
+	providers, err := c.providerRepo.FindByFilter(ctx, ProviderFilter{Active: ptr.ToBool(true)}, nil)
+	if err != nil {
+		logger.GetLogger().Errorf("provider health check: failed to list providers: %v", err)
+		return
+	}
+
+	sem := make(chan struct{}, providerHealthCheckConcurrency)
+	var wg sync.WaitGroup
+	for _, provider := range providers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(provider *Provider) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.check(ctx, provider)
+		}(provider)
+	}
+	wg.Wait()
+}
+
+func (c *ProviderHealthChecker) check(ctx context.Context, provider *Provider) {
+	checkCtx, cancel := context.WithTimeout(ctx, providerHealthCheckTimeout)
+	defer cancel()
+
+	var healthError *string
+	if _, err := c.modelLister.ListModels(checkCtx, provider); err != nil {
+		healthError = ptr.ToString(err.Error())
+		logger.GetLogger().Warnf("provider health check failed for %s: %v", provider.Slug, err)
+		metrics.ProviderUp.Set(0, provider.Slug)
+	} else {
+		metrics.ProviderUp.Set(1, provider.Slug)
+	}
+	if err := c.providerRepo.UpdateHealth(ctx, provider.ID, time.Now().UTC(), healthError); err != nil {
+		logger.GetLogger().Errorf("provider health check: failed to record result for %s: %v", provider.Slug, err)
+		return
+	}
+
+	// A provider that was never checked counts as healthy, so only a failing first check is reported.
+	wasHealthy := provider.LastHealthError == nil
+	if healthy := healthError == nil; healthy != wasHealthy && provider.OrganizationID != nil {
+		c.webhookService.Notify(ctx, *provider.OrganizationID, webhook.EventProviderHealthChanged, providerHealthEventData{
+			ProviderID:   provider.PublicID,
+			ProviderSlug: provider.Slug,
+			Healthy:      healthy,
+			Error:        healthError,
+		})
+	}
+}
+
+// ProviderHealthStatus summarizes the outcome of the last health check of a provider.
+type ProviderHealthStatus string
+
+const (
+	ProviderHealthHealthy   ProviderHealthStatus = "healthy"
+	ProviderHealthUnhealthy ProviderHealthStatus = "unhealthy"
+	// ProviderHealthUnchecked is reported until the first health check has run.
+	ProviderHealthUnchecked ProviderHealthStatus = "unchecked"
+)
+
+// HealthStatus reports the health of the provider as recorded by the last health check.
+func (p *Provider) HealthStatus() ProviderHealthStatus {
+	switch {
+	case p.LastHealthError != nil:
+		return ProviderHealthUnhealthy
+	case p.LastHealthCheckAt == nil:
+		return ProviderHealthUnchecked
+	default:
+		return ProviderHealthHealthy
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ForwardUserMetadataKey is the provider metadata key that, when "true", makes chat completions
+// carry the caller's stable user ID in the request's user field for upstream abuse tracking.
+// Providers without it never see user identifiers.
+const ForwardUserMetadataKey = "forward_user"
+
+// OpenAIOrganizationMetadataKey is the provider metadata key whose value is sent upstream as the
+// OpenAI-Organization header.
+const OpenAIOrganizationMetadataKey = "openai_organization"
+
+// ForwardsUser reports whether the provider opted in to receiving the caller's user ID.
+func (p *Provider) ForwardsUser() bool {
+	if p == nil {
+		return false
+	}
+	forward, err := strconv.ParseBool(strings.TrimSpace(p.Metadata[ForwardUserMetadataKey]))
+	return err == nil && forward
+}
+
+// OpenAIOrganization returns the organization the provider sends as OpenAI-Organization, or an
+// empty string when none is configured.
+func (p *Provider) OpenAIOrganization() string {
+	if p == nil {
+		return ""
+	}
+	return strings.TrimSpace(p.Metadata[OpenAIOrganizationMetadataKey])
+}
@@ -0,0 +1,82 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestJanProviderWithoutCustomProviders(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previousURL := environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL
+	environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = "http://jan-inference:8000/v1"
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = previousURL })
+
+	registry := modeltest.NewRegistry()
+	jan := &domainmodel.Provider{PublicID: "prov-jan", Slug: "jan", Kind: domainmodel.ProviderJan, OrganizationID: &globalOrgID, Active: true}
+	registry.Providers.Add(jan)
+	registry.Models.Add(&domainmodel.ProviderModel{ProviderID: jan.ID, ModelKey: "jan-v1-4b", Active: true})
+
+	providers, err := registry.ListAccessibleProviders(ctx, orgID, nil)
+	if err != nil {
+		t.Fatalf("ListAccessibleProviders: %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != jan.ID {
+		t.Fatalf("got %d accessible providers, want only the Jan provider", len(providers))
+	}
+
+	tests := []struct {
+		name       string
+		model      string
+		resolution domainmodel.ProviderResolution
+	}{
+		{name: "Jan model", model: "jan-v1-4b", resolution: domainmodel.ProviderResolutionMatched},
+		{name: "model nobody serves", model: "unknown", resolution: domainmodel.ProviderResolutionJan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, resolution, err := registry.GetProviderForModelOrDefault(ctx, tt.model, orgID, nil)
+			if err != nil {
+				t.Fatalf("GetProviderForModelOrDefault: %v", err)
+			}
+			if provider.ID != jan.ID || resolution != tt.resolution {
+				t.Fatalf("resolved %s (%s), want the Jan provider (%s)", provider.Slug, resolution, tt.resolution)
+			}
+		})
+	}
+}
+
+func TestHardcodedJanDefault(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previousURL := environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = previousURL })
+
+	registry := modeltest.NewRegistry()
+
+	environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = "http://jan-inference:8000/v1/"
+	provider, resolution, err := registry.GetProviderForModelOrDefault(ctx, "jan-v1-4b", orgID, nil)
+	if err != nil {
+		t.Fatalf("GetProviderForModelOrDefault: %v", err)
+	}
+	if resolution != domainmodel.ProviderResolutionDefault || provider.Kind != domainmodel.ProviderJan || provider.ID != 0 {
+		t.Fatalf("resolved %s (%s), want an unpersisted Jan provider (%s)", provider.Slug, resolution, domainmodel.ProviderResolutionDefault)
+	}
+
+	environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL = ""
+	if _, _, err := registry.GetProviderForModelOrDefault(ctx, "jan-v1-4b", orgID, nil); err == nil {
+		t.Fatal("resolved a provider without a Jan provider or JAN_INFERENCE_MODEL_URL")
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+)
+
+// ProviderKeyMode selects where the API key of completion requests comes from.
+type ProviderKeyMode string
+
+const (
+	// ProviderKeyModeStored authenticates with the API key stored on the provider.
+	ProviderKeyModeStored ProviderKeyMode = "stored"
+	// ProviderKeyModePassthrough authenticates completions with the key the caller sends in the
+	// x-jan-provider-key header. A stored key, if any, is only used for model sync and health checks.
+	ProviderKeyModePassthrough ProviderKeyMode = "passthrough"
+)
+
+// ErrCodeInvalidProviderKeyMode is returned when a provider is given an unknown key mode.
+const ErrCodeInvalidProviderKeyMode = "a6d2f8c4-3e1b-4b79-9c05-d7e4b1a93f62"
+
+// parseProviderKeyMode validates a requested key mode; an empty value selects stored.
+func parseProviderKeyMode(value string) (ProviderKeyMode, *common.Error) {
+	switch mode := ProviderKeyMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return ProviderKeyModeStored, nil
+	case ProviderKeyModeStored, ProviderKeyModePassthrough:
+		return mode, nil
+	default:
+		return "", common.NewErrorWithMessage(fmt.Sprintf("key_mode must be %q or %q", ProviderKeyModeStored, ProviderKeyModePassthrough), ErrCodeInvalidProviderKeyMode)
+	}
+}
+
+// UsesPassthroughKey reports whether completions sent to the provider must carry the caller's key.
+func (p *Provider) UsesPassthroughKey() bool {
+	return p.KeyMode == ProviderKeyModePassthrough
+}
@@ -0,0 +1,57 @@
+package model
+
+import "strings"
+
+// ProviderKindInfo describes a provider kind for clients building a registration form.
+type ProviderKindInfo struct {
+	Kind        ProviderKind
+	DisplayName string
+	// Vendors are the vendor values accepted on registration for the kind; the first is canonical.
+	Vendors          []string
+	RequiresAPIKey   bool
+	RequiredMetadata []string
+}
+
+// providerKinds lists the supported provider kinds. providerKindFromVendor resolves vendors from
+// it, so a kind added here is both accepted on registration and listed by ProviderKinds.
+var providerKinds = []ProviderKindInfo{
+	{Kind: ProviderJan, DisplayName: "Jan", Vendors: []string{"jan"}},
+	{Kind: ProviderOpenRouter, DisplayName: "OpenRouter", Vendors: []string{"openrouter"}, RequiresAPIKey: true},
+	{Kind: ProviderOpenAI, DisplayName: "OpenAI", Vendors: []string{"openai"}, RequiresAPIKey: true},
+	{Kind: ProviderAnthropic, DisplayName: "Anthropic", Vendors: []string{"anthropic"}, RequiresAPIKey: true},
+	{Kind: ProviderGemini, DisplayName: "Google Gemini", Vendors: []string{"gemini", "google", "googleai"}, RequiresAPIKey: true},
+	{Kind: ProviderMistral, DisplayName: "Mistral", Vendors: []string{"mistral"}, RequiresAPIKey: true},
+	{Kind: ProviderGroq, DisplayName: "Groq", Vendors: []string{"groq"}, RequiresAPIKey: true},
+	{Kind: ProviderCohere, DisplayName: "Cohere", Vendors: []string{"cohere"}, RequiresAPIKey: true},
+	{Kind: ProviderOllama, DisplayName: "Ollama", Vendors: []string{"ollama"}},
+	{Kind: ProviderReplicate, DisplayName: "Replicate", Vendors: []string{"replicate"}, RequiresAPIKey: true},
+	{Kind: ProviderAzureOpenAI, DisplayName: "Azure OpenAI", Vendors: []string{"azure_openai", "azure-openai"}, RequiresAPIKey: true, RequiredMetadata: []string{"api_version"}},
+	{Kind: ProviderAWSBedrock, DisplayName: "AWS Bedrock", Vendors: []string{"aws_bedrock", "bedrock"}, RequiresAPIKey: true, RequiredMetadata: []string{"region"}},
+	{Kind: ProviderPerplexity, DisplayName: "Perplexity", Vendors: []string{"perplexity"}, RequiresAPIKey: true},
+	{Kind: ProviderTogetherAI, DisplayName: "Together AI", Vendors: []string{"togetherai", "together"}, RequiresAPIKey: true},
+	{Kind: ProviderHuggingFace, DisplayName: "Hugging Face", Vendors: []string{"huggingface"}, RequiresAPIKey: true},
+	{Kind: ProviderVercelAI, DisplayName: "Vercel AI", Vendors: []string{"vercel_ai", "vercel-ai", "vercel"}, RequiresAPIKey: true},
+	{Kind: ProviderDeepInfra, DisplayName: "DeepInfra", Vendors: []string{"deepinfra"}, RequiresAPIKey: true},
+	{Kind: ProviderCustom, DisplayName: "Custom (OpenAI-compatible)", Vendors: []string{"custom"}},
+}
+
+// ProviderKinds returns the supported provider kinds in registration order.
+func ProviderKinds() []ProviderKindInfo {
+	kinds := make([]ProviderKindInfo, len(providerKinds))
+	copy(kinds, providerKinds)
+	return kinds
+}
+
+// providerKindFromVendor maps a registration vendor to its kind. Unknown vendors are treated as
+// custom OpenAI-compatible providers.
+func providerKindFromVendor(vendor string) ProviderKind {
+	vendor = strings.ToLower(strings.TrimSpace(vendor))
+	for _, info := range providerKinds {
+		for _, candidate := range info.Vendors {
+			if candidate == vendor {
+				return info.Kind
+			}
+		}
+	}
+	return ProviderCustom
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/utils/crypto"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// SecretMetadataKeySuffix marks provider metadata values that hold credentials, such as the
+// aws_secret_access_key_secret AWS Bedrock requests are signed with. These values are encrypted with
+// MODEL_PROVIDER_SECRET when written, decrypted only to build the upstream client and masked in
+// API responses.
+const SecretMetadataKeySuffix = "_secret"
+
+// MaskedMetadataValue replaces secret metadata values in API responses. Sending it back on
+// update keeps the stored value.
+const MaskedMetadataValue = "****"
+
+// IsSecretMetadataKey reports whether the metadata key follows the secret naming convention.
+func IsSecretMetadataKey(key string) bool {
+	return strings.HasSuffix(strings.ToLower(key), SecretMetadataKeySuffix)
+}
+
+// MaskedMetadata returns the provider metadata with secret values masked, for API responses.
+func MaskedMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if IsSecretMetadataKey(key) {
+			value = MaskedMetadataValue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// DecryptMetadata returns the provider metadata with secret values decrypted.
+func DecryptMetadata(metadata map[string]string) (map[string]string, error) {
+	if len(metadata) == 0 {
+		return metadata, nil
+	}
+	var secret string
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if IsSecretMetadataKey(key) {
+			if secret == "" {
+				secret = strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+				if secret == "" {
+					return nil, fmt.Errorf("MODEL_PROVIDER_SECRET not configured")
+				}
+			}
+			plain, err := crypto.DecryptString(secret, value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt metadata %s: %w", key, err)
+			}
+			value = plain
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// sanitizeMetadata trims the metadata, drops empty entries and encrypts secret values. A secret
+// sent back as MaskedMetadataValue keeps its value from existing.
+func sanitizeMetadata(metadata map[string]string, existing map[string]string) (map[string]string, *common.Error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		k := strings.TrimSpace(key)
+		v := strings.TrimSpace(value)
+		if k == "" || v == "" {
+			continue
+		}
+		if k == RoutingWeightMetadataKey && !validRoutingWeight(v) {
+			return nil, common.NewErrorWithMessage("routing_weight must be a non-negative integer", ErrCodeInvalidRoutingWeight)
+		}
+		if IsSecretMetadataKey(k) {
+			if v == MaskedMetadataValue {
+				if stored, ok := existing[k]; ok {
+					result[k] = stored
+				}
+				continue
+			}
+			secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+			if secret == "" {
+				return nil, common.NewErrorWithMessage("model provider secret is not configured", "6d1f3b8e-2a47-4c95-9e0b-f7c4a2d8e531")
+			}
+			cipher, err := crypto.EncryptString(secret, v)
+			if err != nil {
+				return nil, common.NewError(err, "a8c2e5f9-4b13-4d76-b0e8-3f9d1c6a7b24")
+			}
+			v = cipher
+		}
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestSecretMetadataRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret"
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+
+	registry := modeltest.NewRegistry()
+	registry.Lister.SetModels("example", "gpt-4o")
+	result, err := registry.RegisterProvider(ctx, domainmodel.RegisterProviderInput{
+		OrganizationID: 2,
+		Name:           "Example",
+		Vendor:         "custom",
+		BaseURL:        "https://api.example.com/v1",
+		Metadata: map[string]string{
+			"region":                       "us-east-1",
+			"aws_secret_access_key_secret": " wJalrXUtnFEMI ",
+		},
+		Active: true,
+	})
+	if err != nil {
+		t.Fatalf("RegisterProvider: %v", err)
+	}
+
+	stored, _ := registry.Providers.FindByID(ctx, result.Provider.ID)
+	cipher := stored.Metadata["aws_secret_access_key_secret"]
+	if cipher == "" || cipher == "wJalrXUtnFEMI" {
+		t.Fatalf("stored secret = %q, want it encrypted", cipher)
+	}
+	if stored.Metadata["region"] != "us-east-1" {
+		t.Fatalf("stored region = %q, want it in clear", stored.Metadata["region"])
+	}
+	checkDecrypted(t, stored.Metadata, "wJalrXUtnFEMI")
+
+	masked := domainmodel.MaskedMetadata(stored.Metadata)
+	if masked["aws_secret_access_key_secret"] != domainmodel.MaskedMetadataValue || masked["region"] != "us-east-1" {
+		t.Fatalf("masked metadata = %v, want only the secret masked", masked)
+	}
+
+	t.Run("masked value keeps the stored secret", func(t *testing.T) {
+		metadata := map[string]string{"region": "eu-west-1", "aws_secret_access_key_secret": domainmodel.MaskedMetadataValue}
+		updated, err := registry.UpdateProvider(ctx, stored, domainmodel.UpdateProviderInput{Metadata: &metadata})
+		if err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+		if updated.Metadata["aws_secret_access_key_secret"] != cipher {
+			t.Fatalf("secret = %q, want the stored ciphertext", updated.Metadata["aws_secret_access_key_secret"])
+		}
+		if updated.Metadata["region"] != "eu-west-1" {
+			t.Fatalf("region = %q, want eu-west-1", updated.Metadata["region"])
+		}
+		checkDecrypted(t, updated.Metadata, "wJalrXUtnFEMI")
+	})
+
+	t.Run("new value replaces the secret", func(t *testing.T) {
+		metadata := map[string]string{"aws_secret_access_key_secret": "rotated"}
+		updated, err := registry.UpdateProvider(ctx, stored, domainmodel.UpdateProviderInput{Metadata: &metadata})
+		if err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+		checkDecrypted(t, updated.Metadata, "rotated")
+	})
+
+	t.Run("secret requires MODEL_PROVIDER_SECRET", func(t *testing.T) {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = ""
+		defer func() { environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret" }()
+		metadata := map[string]string{"aws_secret_access_key_secret": "plain"}
+		if _, err := registry.UpdateProvider(ctx, stored, domainmodel.UpdateProviderInput{Metadata: &metadata}); err == nil {
+			t.Fatal("UpdateProvider succeeded, want an error without MODEL_PROVIDER_SECRET")
+		}
+		if _, err := domainmodel.DecryptMetadata(stored.Metadata); err == nil {
+			t.Fatal("DecryptMetadata succeeded, want an error without MODEL_PROVIDER_SECRET")
+		}
+	})
+}
+
+func checkDecrypted(t *testing.T, metadata map[string]string, want string) {
+	t.Helper()
+	decrypted, err := domainmodel.DecryptMetadata(metadata)
+	if err != nil {
+		t.Fatalf("DecryptMetadata: %v", err)
+	}
+	if decrypted["aws_secret_access_key_secret"] != want {
+		t.Fatalf("decrypted secret = %q, want %q", decrypted["aws_secret_access_key_secret"], want)
+	}
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"menlo.ai/jan-api-gateway/app/domain/query"
@@ -50,6 +52,7 @@ type ProviderModel struct {
 	ModelKey       string       `json:"model_key"` // provider's canonical id, e.g., "gpt-4o-mini"
 	DisplayName    string       `json:"display_name"`
 	Pricing        Pricing      `json:"pricing"`
+	PriceOverride  *Pricing     `json:"price_override,omitempty"`
 	TokenLimits    *TokenLimits `json:"token_limits,omitempty"` // override provider top caps
 	Family         *string      `json:"family,omitempty"`       // e.g., "gpt-4o", "llama-3.1"
 
@@ -60,15 +63,54 @@ type ProviderModel struct {
 
 	// Lifecycle & audit
 	Active    bool      `json:"active"`
+	Disabled  bool      `json:"disabled"` // turned off by an admin; stays inactive across syncs
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// EffectivePricing returns the admin's price override when set, and the synced pricing otherwise.
+func (pm *ProviderModel) EffectivePricing() Pricing {
+	if pm.PriceOverride != nil {
+		return *pm.PriceOverride
+	}
+	return pm.Pricing
+}
+
+// EstimateCost sums the token and per-request price lines of the effective pricing for a request
+// with the given token counts. Units that do not depend on tokens, such as images or web searches,
+// are ignored, and a model without pricing costs zero.
+func (pm *ProviderModel) EstimateCost(promptTokens, completionTokens int) (MicroUSD, error) {
+	if promptTokens < 0 || completionTokens < 0 {
+		return 0, fmt.Errorf("token counts must not be negative")
+	}
+	var total MicroUSD
+	for _, line := range pm.EffectivePricing().Lines {
+		if line.Currency != "" && !strings.EqualFold(line.Currency, "USD") {
+			return 0, fmt.Errorf("unsupported pricing currency %q", line.Currency)
+		}
+		switch line.Unit {
+		case Per1KPromptTokens:
+			total += perThousand(line.Amount, promptTokens)
+		case Per1KCompletionTokens:
+			total += perThousand(line.Amount, completionTokens)
+		case PerRequest:
+			total += line.Amount
+		}
+	}
+	return total, nil
+}
+
+// perThousand prices tokens at amount per 1K tokens, rounding half up to the nearest micro-USD.
+func perThousand(amount MicroUSD, tokens int) MicroUSD {
+	return (amount*MicroUSD(tokens) + 500) / 1000
+}
+
 // ProviderModelFilter defines optional conditions for querying provider models.
 type ProviderModelFilter struct {
 	IDs                *[]uint
 	ProviderIDs        *[]uint
 	ProviderID         *uint
+	PublicID           *string
 	ModelCatalogID     *uint
 	ModelKey           *string
 	ModelKeys          *[]string
@@ -82,7 +124,10 @@ type ProviderModelFilter struct {
 type ProviderModelRepository interface {
 	Create(ctx context.Context, model *ProviderModel) error
 	Update(ctx context.Context, model *ProviderModel) error
+	// BatchUpsert inserts models without an ID and updates the rest, grouping writes by batchSize.
+	BatchUpsert(ctx context.Context, models []*ProviderModel, batchSize int) error
 	DeleteByID(ctx context.Context, id uint) error
+	DeleteByProviderID(ctx context.Context, providerID uint) error
 	FindByID(ctx context.Context, id uint) (*ProviderModel, error)
 	FindByFilter(ctx context.Context, filter ProviderModelFilter, p *query.Pagination) ([]*ProviderModel, error)
 	Count(ctx context.Context, filter ProviderModelFilter) (int64, error)
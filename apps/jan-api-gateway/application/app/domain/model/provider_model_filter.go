@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+)
+
+// ErrCodeInvalidModelPattern is returned when a provider model allowlist or denylist entry is not a
+// valid glob pattern.
+const ErrCodeInvalidModelPattern = "c3e7a1d9-5b2f-4f64-8a0c-e9d4b6f2a817"
+
+// AllowsModel reports whether the provider's model lists let the model be synced as active. A model
+// matching the denylist is rejected; otherwise it must match the allowlist unless that is empty.
+// Patterns use path.Match syntax, so "*" does not cross a "/": "openai/gpt-4*" matches
+// "openai/gpt-4o" while "gpt-4*" does not.
+func (p *Provider) AllowsModel(modelKey string) bool {
+	if matchesModelPattern(p.ModelDenylist, modelKey) {
+		return false
+	}
+	return len(p.ModelAllowlist) == 0 || matchesModelPattern(p.ModelAllowlist, modelKey)
+}
+
+func matchesModelPattern(patterns []string, modelKey string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, modelKey); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeModelPatterns trims the patterns, drops empty and duplicate entries and rejects malformed globs.
+func sanitizeModelPatterns(patterns []string) ([]string, *common.Error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	result := make([]string, 0, len(patterns))
+	seen := make(map[string]struct{}, len(patterns))
+	for _, raw := range patterns {
+		pattern := strings.TrimSpace(raw)
+		if pattern == "" {
+			continue
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("model pattern %q is not a valid glob", pattern), ErrCodeInvalidModelPattern)
+		}
+		seen[pattern] = struct{}{}
+		result = append(result, pattern)
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+)
+
+func TestProviderAllowsModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		model     string
+		want      bool
+	}{
+		{name: "no lists", model: "openai/gpt-4o", want: true},
+		{name: "allowlist match", allowlist: []string{"openai/gpt-4*"}, model: "openai/gpt-4o", want: true},
+		{name: "allowlist miss", allowlist: []string{"openai/gpt-4*"}, model: "openai/o1", want: false},
+		{name: "glob does not cross a slash", allowlist: []string{"gpt-4*"}, model: "openai/gpt-4o", want: false},
+		{name: "denylist match", denylist: []string{"*/o1*"}, model: "openai/o1-mini", want: false},
+		{name: "denylist miss", denylist: []string{"*/o1*"}, model: "openai/gpt-4o", want: true},
+		{name: "denylist wins over allowlist", allowlist: []string{"openai/*"}, denylist: []string{"openai/o1"}, model: "openai/o1", want: false},
+	}
+	for _, tt := range tests {
+		provider := &domainmodel.Provider{ModelAllowlist: tt.allowlist, ModelDenylist: tt.denylist}
+		if got := provider.AllowsModel(tt.model); got != tt.want {
+			t.Errorf("%s: AllowsModel(%q) = %t, want %t", tt.name, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestModelListsApplyToRoutingBeforeSync(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(provider)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "openai/gpt-4o", Active: true},
+		&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "openai/o1", Active: true},
+	)
+
+	update := func(input domainmodel.UpdateProviderInput) {
+		t.Helper()
+		stored, _ := registry.Providers.FindByID(ctx, provider.ID)
+		if _, err := registry.UpdateProvider(ctx, stored, input); err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+	}
+	routes := func(model string) bool {
+		_, err := registry.GetProviderForModel(ctx, model, orgID, nil)
+		return err == nil
+	}
+
+	update(domainmodel.UpdateProviderInput{ModelAllowlist: &[]string{"openai/gpt-*"}})
+	if !routes("openai/gpt-4o") {
+		t.Fatal("allowlisted model is not routed")
+	}
+	if routes("openai/o1") {
+		t.Fatal("model missing from the allowlist is still routed")
+	}
+
+	update(domainmodel.UpdateProviderInput{ModelAllowlist: &[]string{}, ModelDenylist: &[]string{"openai/gpt-*"}})
+	if routes("openai/gpt-4o") {
+		t.Fatal("denylisted model is still routed")
+	}
+	if !routes("openai/o1") {
+		t.Fatal("model no longer on the allowlist is not routed")
+	}
+}
+
+func TestShadowProvidersApplyModelLists(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+
+	registry := modeltest.NewRegistry()
+	open := &domainmodel.Provider{PublicID: "prov-open", Slug: "open", OrganizationID: &orgID, Active: true, Shadow: true}
+	denying := &domainmodel.Provider{PublicID: "prov-denying", Slug: "denying", OrganizationID: &orgID, Active: true, Shadow: true, ModelDenylist: []string{"gpt-*"}}
+	registry.Providers.Add(open, denying)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: open.ID, ModelKey: "gpt-4o", Active: true},
+		&domainmodel.ProviderModel{ProviderID: denying.ID, ModelKey: "gpt-4o", Active: true},
+	)
+
+	shadows, err := registry.GetShadowProvidersForModel(ctx, "gpt-4o", orgID, nil)
+	if err != nil {
+		t.Fatalf("GetShadowProvidersForModel: %v", err)
+	}
+	if len(shadows) != 1 || shadows[0].PublicID != open.PublicID {
+		t.Fatalf("shadows = %+v, want only the provider whose lists allow the model", shadows)
+	}
+}
@@ -1,7 +1,10 @@
 package model
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -49,6 +52,78 @@ func (s *ProviderModelService) FindActiveByProviderIDsAndKey(ctx context.Context
 	}, nil)
 }
 
+func (s *ProviderModelService) ListByProviderID(ctx context.Context, providerID uint) ([]*ProviderModel, error) {
+	return s.providerModelRepo.FindByFilter(ctx, ProviderModelFilter{
+		ProviderID: ptr.ToUint(providerID),
+	}, nil)
+}
+
+func (s *ProviderModelService) ListByProviderIDAndActive(ctx context.Context, providerID uint, active *bool) ([]*ProviderModel, error) {
+	return s.providerModelRepo.FindByFilter(ctx, ProviderModelFilter{
+		ProviderID: ptr.ToUint(providerID),
+		Active:     active,
+	}, nil)
+}
+
+// FindByProviderIDAndPublicID returns the provider's model with the given public ID, or nil when
+// the provider has no such model.
+func (s *ProviderModelService) FindByProviderIDAndPublicID(ctx context.Context, providerID uint, publicID string) (*ProviderModel, error) {
+	id := publicID
+	models, err := s.providerModelRepo.FindByFilter(ctx, ProviderModelFilter{
+		ProviderID: ptr.ToUint(providerID),
+		PublicID:   &id,
+	}, &query.Pagination{Limit: ptr.ToInt(1)})
+	if err != nil || len(models) == 0 {
+		return nil, err
+	}
+	return models[0], nil
+}
+
+func (s *ProviderModelService) Update(ctx context.Context, pm *ProviderModel) error {
+	return s.providerModelRepo.Update(ctx, pm)
+}
+
+// DeactivateMissing marks the provider's active models whose key is not in modelKeys as inactive.
+// Rows are kept rather than deleted so usage history still resolves.
+func (s *ProviderModelService) DeactivateMissing(ctx context.Context, providerID uint, modelKeys []string) ([]*ProviderModel, *common.Error) {
+	present := make(map[string]struct{}, len(modelKeys))
+	for _, key := range modelKeys {
+		present[strings.TrimSpace(key)] = struct{}{}
+	}
+	existing, err := s.ListByProviderIDAndActive(ctx, providerID, ptr.ToBool(true))
+	if err != nil {
+		return nil, common.NewError(err, "b9e3d7a1-6c2f-4f84-a0d5-e7c1b4f9a326")
+	}
+	deactivated := make([]*ProviderModel, 0)
+	for _, pm := range existing {
+		if _, ok := present[pm.ModelKey]; ok {
+			continue
+		}
+		pm.Active = false
+		if err := s.providerModelRepo.Update(ctx, pm); err != nil {
+			return nil, common.NewError(err, "4d1a8f6c-2b9e-4c57-8e03-a6f2d9b7c148")
+		}
+		deactivated = append(deactivated, pm)
+	}
+	return deactivated, nil
+}
+
+func (s *ProviderModelService) DeleteByProviderID(ctx context.Context, providerID uint) error {
+	return s.providerModelRepo.DeleteByProviderID(ctx, providerID)
+}
+
+func (s *ProviderModelService) CountByProviderID(ctx context.Context, providerID uint) (int64, error) {
+	return s.providerModelRepo.Count(ctx, ProviderModelFilter{
+		ProviderID: ptr.ToUint(providerID),
+	})
+}
+
+func (s *ProviderModelService) CountByCatalogID(ctx context.Context, catalogID uint) (int64, error) {
+	return s.providerModelRepo.Count(ctx, ProviderModelFilter{
+		ModelCatalogID: ptr.ToUint(catalogID),
+	})
+}
+
 func (s *ProviderModelService) UpsertProviderModel(ctx context.Context, provider *Provider, catalog *ModelCatalog, model chatclient.Model) (*ProviderModel, *common.Error) {
 	modelKey := strings.TrimSpace(model.ID)
 	if modelKey == "" {
@@ -91,6 +166,144 @@ func (s *ProviderModelService) UpsertProviderModel(ctx context.Context, provider
 	return pm, nil
 }
 
+// BatchUpsertProviderModels is the batched counterpart of UpsertProviderModel. Catalogs are looked up
+// by catalog public ID and the returned models follow the order of the deduplicated input.
+func (s *ProviderModelService) BatchUpsertProviderModels(ctx context.Context, provider *Provider, catalogs map[string]*ModelCatalog, models []chatclient.Model) ([]*ProviderModel, []*ModelCatalog, *common.Error) {
+	keys := make([]string, 0, len(models))
+	byKey := make(map[string]chatclient.Model, len(models))
+	for i, model := range models {
+		modelKey := strings.TrimSpace(model.ID)
+		if modelKey == "" {
+			return nil, nil, common.NewErrorWithMessage(fmt.Sprintf("model identifier missing at index %d", i), "1c5c6609-6df1-41b0-8fd9-2fa337eb0050")
+		}
+		if _, exists := byKey[modelKey]; !exists {
+			keys = append(keys, modelKey)
+		}
+		byKey[modelKey] = model
+	}
+	if len(keys) == 0 {
+		return []*ProviderModel{}, []*ModelCatalog{}, nil
+	}
+
+	existing, err := s.providerModelRepo.FindByFilter(ctx, ProviderModelFilter{
+		ProviderID: ptr.ToUint(provider.ID),
+		ModelKeys:  &keys,
+	}, nil)
+	if err != nil {
+		return nil, nil, common.NewError(err, "8a3e5c71-2d94-4f0b-a6c8-3b7e1d9f5a42")
+	}
+	existingByKey := make(map[string]*ProviderModel, len(existing))
+	for _, pm := range existing {
+		existingByKey[pm.ModelKey] = pm
+	}
+
+	result := make([]*ProviderModel, 0, len(keys))
+	resultCatalogs := make([]*ModelCatalog, 0, len(keys))
+	for _, key := range keys {
+		model := byKey[key]
+		catalog := catalogs[catalogPublicID(model)]
+		var catalogID *uint
+		if catalog != nil {
+			catalogID = &catalog.ID
+		}
+		if pm, ok := existingByKey[key]; ok {
+			updateProviderModelFromRaw(pm, provider, catalogID, model)
+			result = append(result, pm)
+			resultCatalogs = append(resultCatalogs, catalog)
+			continue
+		}
+		publicID, err := idgen.GenerateSecureID("pmdl", 32)
+		if err != nil {
+			return nil, nil, common.NewError(err, "62e9b0fb-a7f6-435c-9436-955f57843c73")
+		}
+		pm := buildProviderModelFromRaw(provider, catalogID, model)
+		pm.PublicID = publicID
+		result = append(result, pm)
+		resultCatalogs = append(resultCatalogs, catalog)
+	}
+
+	if err := s.providerModelRepo.BatchUpsert(ctx, result, providerModelBatchSize); err != nil {
+		return nil, nil, common.NewError(err, "d5f18b3c-7e26-4a09-b4d1-6c2a8e0f9b75")
+	}
+	return result, resultCatalogs, nil
+}
+
+// PreviewProviderModels reports the model keys that a sync of models would create, update and
+// deactivate for the provider, without writing anything. Existing models only count as updated
+// when the sync changes them; catalogsCreated lists the catalog public IDs the sync would create,
+// whose IDs are not known yet.
+func (s *ProviderModelService) PreviewProviderModels(ctx context.Context, provider *Provider, models []chatclient.Model, catalogsCreated []string) ([]string, []string, []string, *common.Error) {
+	keys := make([]string, 0, len(models))
+	byKey := make(map[string]chatclient.Model, len(models))
+	for i, model := range models {
+		modelKey := strings.TrimSpace(model.ID)
+		if modelKey == "" {
+			return nil, nil, nil, common.NewErrorWithMessage(fmt.Sprintf("model identifier missing at index %d", i), "1c5c6609-6df1-41b0-8fd9-2fa337eb0050")
+		}
+		if _, exists := byKey[modelKey]; !exists {
+			keys = append(keys, modelKey)
+		}
+		byKey[modelKey] = model
+	}
+
+	existing, err := s.ListByProviderID(ctx, provider.ID)
+	if err != nil {
+		return nil, nil, nil, common.NewError(err, "e3b7d2a9-5c1f-4a68-9d04-b6f8c2e1a759")
+	}
+	existingByKey := make(map[string]*ProviderModel, len(existing))
+	for _, pm := range existing {
+		existingByKey[pm.ModelKey] = pm
+	}
+	newCatalogs := make(map[string]struct{}, len(catalogsCreated))
+	for _, publicID := range catalogsCreated {
+		newCatalogs[publicID] = struct{}{}
+	}
+
+	created := make([]string, 0)
+	updated := make([]string, 0)
+	for _, key := range keys {
+		model := byKey[key]
+		pm, ok := existingByKey[key]
+		if !ok {
+			created = append(created, key)
+			continue
+		}
+		if _, ok := newCatalogs[catalogPublicID(model)]; ok {
+			updated = append(updated, key)
+			continue
+		}
+		preview := *pm
+		updateProviderModelFromRaw(&preview, provider, pm.ModelCatalogID, model)
+		preview.UpdatedAt = pm.UpdatedAt
+		if providerModelChanged(pm, &preview) {
+			updated = append(updated, key)
+		}
+	}
+
+	// Mirrors SyncProviderModels, which keeps the previous models when the listing is empty.
+	deactivated := make([]string, 0)
+	if len(keys) > 0 {
+		for _, pm := range existing {
+			if _, ok := byKey[pm.ModelKey]; ok || !pm.Active {
+				continue
+			}
+			deactivated = append(deactivated, pm.ModelKey)
+		}
+	}
+	return created, updated, deactivated, nil
+}
+
+// providerModelChanged compares the serialized models so that pricing and limits decoded from
+// storage compare equal to freshly extracted ones.
+func providerModelChanged(before, after *ProviderModel) bool {
+	beforeJSON, beforeErr := json.Marshal(before)
+	afterJSON, afterErr := json.Marshal(after)
+	if beforeErr != nil || afterErr != nil {
+		return true
+	}
+	return !bytes.Equal(beforeJSON, afterJSON)
+}
+
 func buildProviderModelFromRaw(provider *Provider, catalogID *uint, model chatclient.Model) *ProviderModel {
 	pricing := extractPricing(model.Raw["pricing"])
 	tokenLimits := extractTokenLimits(model.Raw)
@@ -114,7 +327,7 @@ func buildProviderModelFromRaw(provider *Provider, catalogID *uint, model chatcl
 		SupportsImages:     supportsImages,
 		SupportsEmbeddings: strings.Contains(strings.ToLower(model.ID), "embed"),
 		SupportsReasoning:  supportsReasoning,
-		Active:             provider.Active,
+		Active:             provider.Active && provider.AllowsModel(model.ID),
 	}
 }
 
@@ -130,7 +343,7 @@ func updateProviderModelFromRaw(pm *ProviderModel, provider *Provider, catalogID
 	pm.SupportsImages = containsString(extractStringSliceFromMap(model.Raw, "architecture", "input_modalities"), "image")
 	pm.SupportsEmbeddings = strings.Contains(strings.ToLower(model.ID), "embed")
 	pm.SupportsReasoning = containsString(extractStringSlice(model.Raw["supported_parameters"]), "include_reasoning")
-	pm.Active = provider.Active
+	pm.Active = provider.Active && !pm.Disabled && provider.AllowsModel(pm.ModelKey)
 	pm.UpdatedAt = time.Now().UTC()
 }
 
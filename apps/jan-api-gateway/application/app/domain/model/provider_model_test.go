@@ -0,0 +1,85 @@
+package model_test
+
+import (
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name             string
+		lines            []domainmodel.PriceLine
+		promptTokens     int
+		completionTokens int
+		want             domainmodel.MicroUSD
+		wantErr          bool
+	}{
+		{name: "no pricing", promptTokens: 1000, completionTokens: 500},
+		{
+			name:             "missing completion line",
+			lines:            []domainmodel.PriceLine{{Unit: domainmodel.Per1KPromptTokens, Amount: 2000, Currency: "USD"}},
+			promptTokens:     1000,
+			completionTokens: 500,
+			want:             2000,
+		},
+		{
+			name:             "missing prompt line",
+			lines:            []domainmodel.PriceLine{{Unit: domainmodel.Per1KCompletionTokens, Amount: 8000}},
+			promptTokens:     1000,
+			completionTokens: 500,
+			want:             4000,
+		},
+		{
+			name: "mixed units",
+			lines: []domainmodel.PriceLine{
+				{Unit: domainmodel.Per1KPromptTokens, Amount: 2000, Currency: "USD"},
+				{Unit: domainmodel.Per1KCompletionTokens, Amount: 8000, Currency: "usd"},
+				{Unit: domainmodel.PerRequest, Amount: 100, Currency: "USD"},
+				{Unit: domainmodel.PerImage, Amount: 40000, Currency: "USD"},
+				{Unit: domainmodel.PerWebSearch, Amount: 25000, Currency: "USD"},
+				{Unit: domainmodel.PerInternalReasoning, Amount: 9000, Currency: "USD"},
+			},
+			promptTokens:     1500,
+			completionTokens: 250,
+			want:             3000 + 2000 + 100,
+		},
+		{
+			name:             "only units without token counts",
+			lines:            []domainmodel.PriceLine{{Unit: domainmodel.PerImage, Amount: 40000}},
+			promptTokens:     1000,
+			completionTokens: 500,
+		},
+		{
+			name:         "rounds half up to the micro-USD",
+			lines:        []domainmodel.PriceLine{{Unit: domainmodel.Per1KPromptTokens, Amount: 3}},
+			promptTokens: 500,
+			want:         2,
+		},
+		{
+			name:         "other currency",
+			lines:        []domainmodel.PriceLine{{Unit: domainmodel.Per1KPromptTokens, Amount: 2000, Currency: "EUR"}},
+			promptTokens: 1000,
+			wantErr:      true,
+		},
+		{name: "negative tokens", promptTokens: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := &domainmodel.ProviderModel{Pricing: domainmodel.Pricing{Lines: tt.lines}}
+			got, err := pm.EstimateCost(tt.promptTokens, tt.completionTokens)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EstimateCost = %d, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EstimateCost: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("EstimateCost = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestGetProviderOverrideForModel(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	otherOrgID := uint(3)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	org := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	global := &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Active: true}
+	shadow := &domainmodel.Provider{PublicID: "prov-shadow", Slug: "shadow", OrganizationID: &orgID, Active: true, Shadow: true}
+	foreign := &domainmodel.Provider{PublicID: "prov-foreign", Slug: "foreign", OrganizationID: &otherOrgID, Active: true}
+	registry.Providers.Add(org, global, shadow, foreign)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: org.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: global.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: shadow.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: foreign.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: global.ID, ModelKey: "claude", Active: true},
+	)
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		want     string
+	}{
+		{name: "organization provider", provider: org.PublicID, model: "gpt", want: org.PublicID},
+		{name: "lower-priority global provider", provider: global.PublicID, model: "gpt", want: global.PublicID},
+		{name: "provider without the model", provider: org.PublicID, model: "claude"},
+		{name: "shadow provider", provider: shadow.PublicID, model: "gpt"},
+		{name: "another organization's provider", provider: foreign.PublicID, model: "gpt"},
+		{name: "unknown provider", provider: "prov-unknown", model: "gpt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := registry.GetProviderOverrideForModel(ctx, tt.provider, tt.model, orgID, nil)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("override to %s was accepted", provider.Slug)
+				}
+				if err.GetCode() != domainmodel.ErrCodeProviderOverrideRejected {
+					t.Fatalf("error code = %s, want %s", err.GetCode(), domainmodel.ErrCodeProviderOverrideRejected)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProviderOverrideForModel: %v", err)
+			}
+			if provider.PublicID != tt.want {
+				t.Fatalf("override resolved to %s, want %s", provider.PublicID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+)
+
+// ErrCodeInvalidPathPrefix is returned when a provider path prefix does not start with "/".
+const ErrCodeInvalidPathPrefix = "d4a9c2f6-1e8b-4b37-95d0-a3f7e1c6b982"
+
+// sanitizePathPrefix validates a provider path prefix such as "/api/v1", which gateways use to
+// expose OpenAI-compatible APIs below the base URL. Trailing slashes are dropped, and an empty
+// prefix or "/" leaves the paths unprefixed.
+func sanitizePathPrefix(prefix string) (string, *common.Error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(prefix, "/") {
+		return "", common.NewErrorWithMessage(fmt.Sprintf("path_prefix %q must start with /", prefix), ErrCodeInvalidPathPrefix)
+	}
+	if strings.ContainsAny(prefix, "?#") {
+		return "", common.NewErrorWithMessage(fmt.Sprintf("path_prefix %q must not contain a query or fragment", prefix), ErrCodeInvalidPathPrefix)
+	}
+	return strings.TrimRight(prefix, "/"), nil
+}
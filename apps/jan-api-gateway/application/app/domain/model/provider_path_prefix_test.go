@@ -0,0 +1,37 @@
+package model
+
+import "testing"
+
+func TestSanitizePathPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		want    string
+		invalid bool
+	}{
+		{name: "empty", prefix: "", want: ""},
+		{name: "root", prefix: "/", want: ""},
+		{name: "prefix", prefix: "/api/v1", want: "/api/v1"},
+		{name: "trailing slash and spaces", prefix: "  /api/v1// ", want: "/api/v1"},
+		{name: "missing leading slash", prefix: "api/v1", invalid: true},
+		{name: "query", prefix: "/api/v1?key=1", invalid: true},
+		{name: "fragment", prefix: "/api#v1", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sanitizePathPrefix(tt.prefix)
+			if tt.invalid {
+				if err == nil || err.GetCode() != ErrCodeInvalidPathPrefix {
+					t.Fatalf("sanitizePathPrefix(%q) error = %v, want invalid path prefix", tt.prefix, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("sanitizePathPrefix(%q): %v", tt.prefix, err)
+			}
+			if got != tt.want {
+				t.Fatalf("sanitizePathPrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
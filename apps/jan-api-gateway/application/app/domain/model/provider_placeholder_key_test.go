@@ -0,0 +1,101 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestPlaceholderKeysWithoutProviderSecret(t *testing.T) {
+	ctx := context.Background()
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = ""
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+
+	registry := modeltest.NewRegistry()
+	register := func(name, apiKey string) (*domainmodel.ProviderRegistrationResult, error) {
+		result, err := registry.RegisterProvider(ctx, domainmodel.RegisterProviderInput{
+			OrganizationID: 2,
+			Name:           name,
+			Vendor:         "custom",
+			BaseURL:        "http://jan-inference:8101/v1",
+			APIKey:         apiKey,
+			Active:         true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	tests := []struct {
+		name   string
+		apiKey string
+	}{
+		{name: "none", apiKey: "none"},
+		{name: "placeholder with another case", apiKey: " NONE "},
+		{name: "empty", apiKey: ""},
+		{name: "blank", apiKey: "   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := register("Jan "+tt.name, tt.apiKey)
+			if err != nil {
+				t.Fatalf("RegisterProvider with %q: %v", tt.apiKey, err)
+			}
+			if result.Provider.EncryptedAPIKey != "" || result.Provider.APIKeyHint != nil {
+				t.Fatalf("provider stored key %q and hint %v, want neither", result.Provider.EncryptedAPIKey, result.Provider.APIKeyHint)
+			}
+		})
+	}
+
+	t.Run("a real key still requires the secret", func(t *testing.T) {
+		if _, err := register("Keyed", "sk-live-1234"); err == nil {
+			t.Fatal("RegisterProvider succeeded without MODEL_PROVIDER_SECRET")
+		}
+	})
+
+	t.Run("updating to the placeholder clears the key", func(t *testing.T) {
+		result, err := register("Updated", "none")
+		if err != nil {
+			t.Fatalf("RegisterProvider: %v", err)
+		}
+		provider := result.Provider
+		provider.EncryptedAPIKey = "stale-ciphertext"
+		placeholder := "none"
+		updated, updateErr := registry.UpdateProvider(ctx, provider, domainmodel.UpdateProviderInput{APIKey: &placeholder})
+		if updateErr != nil {
+			t.Fatalf("UpdateProvider: %v", updateErr)
+		}
+		if updated.EncryptedAPIKey != "" {
+			t.Fatalf("EncryptedAPIKey = %q, want it cleared", updated.EncryptedAPIKey)
+		}
+	})
+
+	t.Run("rotating to the placeholder is rejected", func(t *testing.T) {
+		result, err := register("Rotated", "")
+		if err != nil {
+			t.Fatalf("RegisterProvider: %v", err)
+		}
+		if _, rotateErr := registry.RotateAPIKey(ctx, result.Provider, "none"); rotateErr == nil {
+			t.Fatal("RotateAPIKey accepted the placeholder key")
+		}
+	})
+
+	count, err := registry.CountProvidersWithAPIKey(ctx)
+	if err != nil {
+		t.Fatalf("CountProvidersWithAPIKey: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("CountProvidersWithAPIKey = %d, want 0 with only placeholder keys", count)
+	}
+}
@@ -0,0 +1,123 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestEstimateCostPrefersPriceOverride(t *testing.T) {
+	synced := domainmodel.Pricing{Lines: []domainmodel.PriceLine{
+		{Unit: domainmodel.Per1KPromptTokens, Amount: 2000, Currency: "USD"},
+		{Unit: domainmodel.Per1KCompletionTokens, Amount: 8000, Currency: "USD"},
+	}}
+	tests := []struct {
+		name     string
+		override *domainmodel.Pricing
+		want     domainmodel.MicroUSD
+	}{
+		{name: "synced pricing", want: 2000 + 4000},
+		{
+			name: "override",
+			override: &domainmodel.Pricing{Lines: []domainmodel.PriceLine{
+				{Unit: domainmodel.Per1KPromptTokens, Amount: 1000, Currency: "USD"},
+				{Unit: domainmodel.Per1KCompletionTokens, Amount: 4000, Currency: "USD"},
+			}},
+			want: 1000 + 2000,
+		},
+		{
+			name:     "override replaces every synced line",
+			override: &domainmodel.Pricing{Lines: []domainmodel.PriceLine{{Unit: domainmodel.PerRequest, Amount: 50, Currency: "USD"}}},
+			want:     50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := &domainmodel.ProviderModel{Pricing: synced, PriceOverride: tt.override}
+			got, err := pm.EstimateCost(1000, 500)
+			if err != nil {
+				t.Fatalf("EstimateCost: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("EstimateCost = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceOverrideSurvivesSync(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, BaseURL: "https://api.openai.com/v1", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(provider)
+	pm := &domainmodel.ProviderModel{
+		PublicID:   "pmdl-gpt-4o",
+		ProviderID: provider.ID,
+		ModelKey:   "gpt-4o",
+		Active:     true,
+		Pricing:    domainmodel.Pricing{Lines: []domainmodel.PriceLine{{Unit: domainmodel.Per1KPromptTokens, Amount: 2500, Currency: "USD"}}},
+	}
+	registry.Models.Add(pm)
+	registry.Lister.SetModels("openai", "gpt-4o")
+
+	negotiated := domainmodel.Pricing{Lines: []domainmodel.PriceLine{{Unit: domainmodel.Per1KPromptTokens, Amount: 1500}}}
+	updated, err := registry.UpdateProviderModel(ctx, provider, pm.PublicID, domainmodel.UpdateProviderModelInput{PriceOverride: &negotiated})
+	if err != nil {
+		t.Fatalf("UpdateProviderModel: %v", err)
+	}
+	if updated.PriceOverride == nil || updated.PriceOverride.Lines[0].Currency != "USD" {
+		t.Fatalf("price override = %+v, want it stored with the USD currency filled in", updated.PriceOverride)
+	}
+	if !updated.Active {
+		t.Fatal("setting a price override changed the active flag")
+	}
+
+	if _, refreshErr := registry.RefreshProviderModels(ctx, provider); refreshErr != nil {
+		t.Fatalf("RefreshProviderModels: %v", refreshErr)
+	}
+	synced, findErr := registry.Models.FindByID(ctx, pm.ID)
+	if findErr != nil || synced == nil {
+		t.Fatalf("FindByID: %v", findErr)
+	}
+	if synced.PriceOverride == nil || synced.PriceOverride.Lines[0].Amount != 1500 {
+		t.Fatalf("price override after sync = %+v, want the negotiated rate", synced.PriceOverride)
+	}
+	if cost, _ := synced.EstimateCost(1000, 0); cost != 1500 {
+		t.Fatalf("EstimateCost after sync = %d, want the negotiated 1500", cost)
+	}
+
+	invalid := []struct {
+		name  string
+		input domainmodel.UpdateProviderModelInput
+	}{
+		{name: "nothing to update", input: domainmodel.UpdateProviderModelInput{}},
+		{name: "negative amount", input: domainmodel.UpdateProviderModelInput{PriceOverride: &domainmodel.Pricing{Lines: []domainmodel.PriceLine{{Unit: domainmodel.PerRequest, Amount: -1}}}}},
+		{name: "other currency", input: domainmodel.UpdateProviderModelInput{PriceOverride: &domainmodel.Pricing{Lines: []domainmodel.PriceLine{{Unit: domainmodel.PerRequest, Amount: 1, Currency: "EUR"}}}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.UpdateProviderModel(ctx, provider, pm.PublicID, tt.input)
+			if err == nil || err.GetCode() != domainmodel.ErrCodeInvalidProviderModelUpdate {
+				t.Fatalf("UpdateProviderModel error = %v, want invalid update", err)
+			}
+		})
+	}
+
+	t.Run("an override without lines removes it", func(t *testing.T) {
+		cleared, err := registry.UpdateProviderModel(ctx, provider, pm.PublicID, domainmodel.UpdateProviderModelInput{PriceOverride: &domainmodel.Pricing{}})
+		if err != nil {
+			t.Fatalf("UpdateProviderModel: %v", err)
+		}
+		if cleared.PriceOverride != nil {
+			t.Fatalf("price override = %+v, want it removed", cleared.PriceOverride)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package model_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestProviderPriorityWithinScope(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	projectID := uint(7)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	// Added in the order the repository returns them, so insertion order alone would pick "first".
+	first := &domainmodel.Provider{PublicID: "prov-first", Slug: "first", DisplayName: "Zeta", OrganizationID: &orgID, Active: true}
+	preferred := &domainmodel.Provider{PublicID: "prov-preferred", Slug: "preferred", DisplayName: "Omega", OrganizationID: &orgID, Priority: 10, Active: true}
+	named := &domainmodel.Provider{PublicID: "prov-named", Slug: "named", DisplayName: "Alpha", OrganizationID: &orgID, Active: true}
+	proj := &domainmodel.Provider{PublicID: "prov-project", Slug: "project", OrganizationID: &orgID, ProjectID: &projectID, Priority: -5, Active: true}
+	global := &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Priority: 100, Active: true}
+	registry.Providers.Add(first, preferred, named, proj, global)
+	for _, provider := range []*domainmodel.Provider{first, preferred, named, proj, global} {
+		registry.Models.Add(&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "gpt", Active: true})
+	}
+
+	chain := func(projectIDs []uint) []string {
+		t.Helper()
+		providers, err := registry.GetProvidersForModel(ctx, "gpt", orgID, projectIDs)
+		if err != nil {
+			t.Fatalf("GetProvidersForModel: %v", err)
+		}
+		slugs := make([]string, 0, len(providers))
+		for _, provider := range providers {
+			slugs = append(slugs, provider.Slug)
+		}
+		return slugs
+	}
+
+	t.Run("higher priority wins within the organization", func(t *testing.T) {
+		winner, err := registry.GetProviderForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel: %v", err)
+		}
+		if winner.Slug != "preferred" {
+			t.Fatalf("GetProviderForModel = %s, want preferred", winner.Slug)
+		}
+		// Equal priorities fall back to the display name.
+		if got, want := chain(nil), []string{"preferred", "named", "first", "global"}; !slices.Equal(got, want) {
+			t.Fatalf("chain = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("priority does not cross scopes", func(t *testing.T) {
+		if got, want := chain([]uint{projectID}), []string{"project", "preferred", "named", "first", "global"}; !slices.Equal(got, want) {
+			t.Fatalf("chain = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("updated priority takes effect", func(t *testing.T) {
+		priority := 20
+		if _, err := registry.UpdateProvider(ctx, first, domainmodel.UpdateProviderInput{Priority: &priority}); err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+		winner, err := registry.GetProviderForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel: %v", err)
+		}
+		if winner.Slug != "first" {
+			t.Fatalf("GetProviderForModel = %s, want first after raising its priority", winner.Slug)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package model_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestRefreshProviderModels(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	openai := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, BaseURL: "https://api.openai.com/v1", OrganizationID: &orgID, Active: true}
+	mistral := &domainmodel.Provider{PublicID: "prov-mistral", Slug: "mistral", Kind: domainmodel.ProviderMistral, BaseURL: "https://api.mistral.ai/v1", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(openai, mistral)
+	registry.Lister.SetModels("openai", "gpt-4o", "gpt-4o-mini")
+	registry.Lister.SetModels("mistral", "mistral-large")
+
+	activeKeys := func(provider *domainmodel.Provider) []string {
+		t.Helper()
+		models, err := registry.ListProviderModels(ctx, []uint{provider.ID})
+		if err != nil {
+			t.Fatalf("ListProviderModels: %v", err)
+		}
+		keys := make([]string, 0, len(models))
+		for _, model := range models {
+			keys = append(keys, model.ModelKey)
+		}
+		slices.Sort(keys)
+		return keys
+	}
+	routed := func(modelKey string) *domainmodel.Provider {
+		t.Helper()
+		provider, err := registry.GetProviderForModel(ctx, modelKey, orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel(%s): %v", modelKey, err)
+		}
+		return provider
+	}
+
+	for _, provider := range []*domainmodel.Provider{openai, mistral} {
+		if _, err := registry.RefreshProviderModels(ctx, provider); err != nil {
+			t.Fatalf("RefreshProviderModels(%s): %v", provider.Slug, err)
+		}
+	}
+	if got := activeKeys(openai); !slices.Equal(got, []string{"gpt-4o", "gpt-4o-mini"}) {
+		t.Fatalf("openai models = %v", got)
+	}
+	if got := activeKeys(mistral); !slices.Equal(got, []string{"mistral-large"}) {
+		t.Fatalf("mistral models = %v", got)
+	}
+	if got := routed("gpt-4o"); got.ID != openai.ID {
+		t.Fatalf("gpt-4o routed to %s, want openai", got.Slug)
+	}
+	if got := routed("mistral-large"); got.ID != mistral.ID {
+		t.Fatalf("mistral-large routed to %s, want mistral", got.Slug)
+	}
+
+	// Refreshing one provider leaves the other's models alone.
+	registry.Lister.SetModels("openai", "gpt-4o")
+	if _, err := registry.RefreshProviderModels(ctx, openai); err != nil {
+		t.Fatalf("second RefreshProviderModels: %v", err)
+	}
+	if got := activeKeys(openai); !slices.Equal(got, []string{"gpt-4o"}) {
+		t.Fatalf("openai models after refresh = %v, want only gpt-4o", got)
+	}
+	if got := activeKeys(mistral); !slices.Equal(got, []string{"mistral-large"}) {
+		t.Fatalf("mistral models after openai refresh = %v", got)
+	}
+
+	registry.Lister.SetError("mistral", errors.New("upstream unavailable"))
+	_, err := registry.RefreshProviderModels(ctx, mistral)
+	if err == nil || err.GetCode() != domainmodel.ErrCodeProviderModelFetchFailed {
+		t.Fatalf("RefreshProviderModels error = %v, want %s", err, domainmodel.ErrCodeProviderModelFetchFailed)
+	}
+	if got := activeKeys(mistral); !slices.Equal(got, []string{"mistral-large"}) {
+		t.Fatalf("mistral models after a failed refresh = %v", got)
+	}
+}
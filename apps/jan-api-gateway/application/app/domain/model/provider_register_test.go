@@ -0,0 +1,75 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestRegisterProviderCustomBaseURLUniqueness(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = previous
+	})
+
+	register := func(registry *modeltest.Registry, name, baseURL string, projectID uint) (*domainmodel.ProviderRegistrationResult, *common.Error) {
+		return registry.RegisterProvider(ctx, domainmodel.RegisterProviderInput{
+			OrganizationID: orgID,
+			ProjectID:      projectID,
+			Name:           name,
+			Vendor:         "custom",
+			BaseURL:        baseURL,
+			APIKey:         "none",
+			Active:         true,
+		})
+	}
+
+	tests := []struct {
+		name      string
+		unique    bool
+		projectID uint
+		rejected  bool
+	}{
+		{name: "check enabled", unique: true, rejected: true},
+		{name: "check enabled in another scope", unique: true, projectID: 7},
+		{name: "check disabled", unique: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = tt.unique
+			registry := modeltest.NewRegistry()
+			if _, err := register(registry, "First Gateway", "https://gateway.example.com/v1", 0); err != nil {
+				t.Fatalf("RegisterProvider(first): %v", err)
+			}
+
+			// The same upstream with a trailing slash is still the same base URL
+			second, err := register(registry, "Second Gateway", "https://gateway.example.com/v1/", tt.projectID)
+			count, _ := registry.Providers.Count(ctx, domainmodel.ProviderFilter{})
+			if tt.rejected {
+				if err == nil || err.GetCode() != domainmodel.ErrCodeDuplicateCustomProvider {
+					t.Fatalf("RegisterProvider(second) error = %v, want duplicate custom provider", err)
+				}
+				if count != 1 {
+					t.Fatalf("got %d providers, want only the first", count)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RegisterProvider(second): %v", err)
+			}
+			if count != 2 || second.Provider.Slug == "" {
+				t.Fatalf("got %d providers, want both registered", count)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redsync/redsync/v4"
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// ProviderRegistrationIdempotencyTTL is how long a registration response is replayed for retries
+// carrying the same Idempotency-Key.
+const ProviderRegistrationIdempotencyTTL = 10 * time.Minute
+
+// providerRegistrationLockTTL bounds how long a registration holds its idempotency key, covering
+// the upstream model listing and sync it performs.
+const providerRegistrationLockTTL = 2 * time.Minute
+
+// ErrCodeIdempotencyKeyReused is returned when an Idempotency-Key is replayed with a different body.
+const ErrCodeIdempotencyKeyReused = "6f2d8b4a-1c7e-4a53-9e06-b3d5f8a2c417"
+
+// ErrCodeIdempotencyKeyInProgress is returned when a request with the same Idempotency-Key is
+// still being processed.
+const ErrCodeIdempotencyKeyInProgress = "c9e4a7d2-5b1f-4e86-a3c0-7d2f6b9e1a58"
+
+// IdempotentResponse is a stored registration response, replayed to retries of the same request.
+type IdempotentResponse struct {
+	Fingerprint string          `json:"fingerprint"`
+	Status      int             `json:"status"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// IdempotentRegistration serializes provider registrations sharing an Idempotency-Key within an
+// organization and remembers the response of the first successful one.
+type IdempotentRegistration struct {
+	service     *ProviderRegistryService
+	key         string
+	fingerprint string
+	mutex       *redsync.Mutex
+}
+
+// BeginIdempotentRegistration claims the Idempotency-Key for a request body. When an earlier
+// request with the same key already completed, its response is returned for replay and the
+// registration handle is nil. Without a cache the request proceeds without idempotency.
+func (s *ProviderRegistryService) BeginIdempotentRegistration(ctx context.Context, organizationID uint, idempotencyKey string, requestBody any) (*IdempotentRegistration, *IdempotentResponse, *common.Error) {
+	if s.cache == nil {
+		return nil, nil, nil
+	}
+	bodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, nil, common.NewError(err, "0b7e3f9c-2d46-4a18-b5e1-c8f4a6d2e973")
+	}
+	keyHash := sha256.Sum256([]byte(idempotencyKey))
+	bodyHash := sha256.Sum256(bodyJSON)
+	registration := &IdempotentRegistration{
+		service:     s,
+		key:         fmt.Sprintf(cache.ProviderRegistrationIdempotencyKey, organizationID, hex.EncodeToString(keyHash[:])),
+		fingerprint: hex.EncodeToString(bodyHash[:]),
+	}
+
+	registration.mutex = s.cache.NewMutex(registration.key+":lock", redsync.WithExpiry(providerRegistrationLockTTL))
+	if err := registration.mutex.LockContext(ctx); err != nil {
+		return nil, nil, common.NewErrorWithMessage("a request with this idempotency key is already in progress", ErrCodeIdempotencyKeyInProgress)
+	}
+
+	stored, found := registration.load(ctx)
+	if !found {
+		return registration, nil, nil
+	}
+	registration.Release(ctx)
+	if stored.Fingerprint != registration.fingerprint {
+		return nil, nil, common.NewErrorWithMessage("idempotency key was already used with a different request", ErrCodeIdempotencyKeyReused)
+	}
+	return nil, stored, nil
+}
+
+func (r *IdempotentRegistration) load(ctx context.Context) (*IdempotentResponse, bool) {
+	var stored IdempotentResponse
+	if !r.service.cache.GetJSON(ctx, r.key, &stored) {
+		return nil, false
+	}
+	return &stored, true
+}
+
+// Complete stores the response so retries within ProviderRegistrationIdempotencyTTL replay it.
+// Failing to store it only costs idempotency, so errors are logged.
+func (r *IdempotentRegistration) Complete(ctx context.Context, status int, body any) {
+	if r == nil {
+		return
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to encode idempotent provider registration response: %v", err)
+		return
+	}
+	entry, err := json.Marshal(IdempotentResponse{
+		Fingerprint: r.fingerprint,
+		Status:      status,
+		Body:        bodyJSON,
+	})
+	if err != nil {
+		logger.GetLogger().Errorf("failed to encode idempotent provider registration entry: %v", err)
+		return
+	}
+	if err := r.service.cache.Set(ctx, r.key, string(entry), ProviderRegistrationIdempotencyTTL); err != nil {
+		logger.GetLogger().Errorf("failed to store idempotent provider registration response: %v", err)
+	}
+}
+
+// Release frees the Idempotency-Key so later requests can replay or retry it.
+func (r *IdempotentRegistration) Release(ctx context.Context) {
+	if r == nil || r.mutex == nil {
+		return
+	}
+	if _, err := r.mutex.UnlockContext(context.WithoutCancel(ctx)); err != nil {
+		logger.GetLogger().Errorf("failed to release provider registration idempotency lock: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// DefaultAccessibleProviderModelsCacheTTL bounds how long a cached provider lookup is served when
+// MODELS_CACHE_TTL is unset. With Redis, changes made through the registry on any replica take
+// effect right away; without it they only reach other replicas once their entries expire.
+const DefaultAccessibleProviderModelsCacheTTL = 30 * time.Second
+
+// accessibleProviderModelsCacheTTL returns MODELS_CACHE_TTL, a Go duration such as 2m, falling
+// back to the default when it is unset or invalid.
+func accessibleProviderModelsCacheTTL() time.Duration {
+	value := strings.TrimSpace(environment_variables.EnvironmentVariables.MODELS_CACHE_TTL)
+	if value == "" {
+		return DefaultAccessibleProviderModelsCacheTTL
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		logger.GetLogger().Errorf("invalid MODELS_CACHE_TTL %q, using %s", value, DefaultAccessibleProviderModelsCacheTTL)
+		return DefaultAccessibleProviderModelsCacheTTL
+	}
+	return ttl
+}
+
+// accessibleProviderModels is the result of resolving the providers accessible to an organization
+// and project scope together with the model keys each of them actively serves.
+type accessibleProviderModels struct {
+	Providers []*Provider
+	ModelKeys map[uint][]string
+}
+
+func (m *accessibleProviderModels) serves(providerID uint, modelKey string) bool {
+	return slices.Contains(m.ModelKeys[providerID], modelKey)
+}
+
+// accessibleProviderIDs is what Redis holds for a scope. Providers carry their encrypted API key
+// and headers, so only their IDs are shared, with the UpdatedAt of each row as its version; the
+// rows are rehydrated in-process when the version matches, or from the database.
+type accessibleProviderIDs struct {
+	ProviderIDs []uint             `json:"provider_ids"`
+	ModelKeys   map[uint][]string  `json:"model_keys"`
+	Versions    map[uint]time.Time `json:"versions"`
+}
+
+// localProviderCache keeps the accessible scopes and provider rows of this process for the cache
+// TTL, so repeated lookups need neither Redis nor the database.
+type localProviderCache struct {
+	mu        sync.Mutex
+	scopes    map[string]localCacheEntry[accessibleProviderIDs]
+	providers map[uint]localCacheEntry[Provider]
+}
+
+type localCacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newLocalProviderCache() *localProviderCache {
+	return &localProviderCache{
+		scopes:    map[string]localCacheEntry[accessibleProviderIDs]{},
+		providers: map[uint]localCacheEntry[Provider]{},
+	}
+}
+
+func (c *localProviderCache) scope(key string) (accessibleProviderIDs, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.scopes[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return accessibleProviderIDs{}, false
+	}
+	return entry.value, true
+}
+
+// rows returns copies of the cached providers with the given IDs and the IDs that were missing.
+// A row whose UpdatedAt differs from its version was changed by another replica and counts as
+// missing.
+func (c *localProviderCache) rows(ids []uint, versions map[uint]time.Time) (map[uint]*Provider, []uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	found := make(map[uint]*Provider, len(ids))
+	var missing []uint
+	for _, id := range ids {
+		entry, ok := c.providers[id]
+		if !ok || now.After(entry.expiresAt) || !entry.value.UpdatedAt.Equal(versions[id]) {
+			missing = append(missing, id)
+			continue
+		}
+		provider := entry.value
+		found[id] = &provider
+	}
+	return found, missing
+}
+
+func (c *localProviderCache) store(key string, ids accessibleProviderIDs, providers []*Provider, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiresAt := time.Now().Add(ttl)
+	if key != "" {
+		c.scopes[key] = localCacheEntry[accessibleProviderIDs]{value: ids, expiresAt: expiresAt}
+	}
+	for _, provider := range providers {
+		c.providers[provider.ID] = localCacheEntry[Provider]{value: *provider, expiresAt: expiresAt}
+	}
+}
+
+func (c *localProviderCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.scopes)
+	clear(c.providers)
+}
+
+// accessibleProviderModelsKey builds the cache key for an organization and project scope. Project
+// IDs are sorted so the same scope always maps to the same key.
+func accessibleProviderModelsKey(organizationID uint, projectIDs []uint) string {
+	ids := slices.Clone(projectIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return fmt.Sprintf(cache.AccessibleProviderModelsKey, organizationID, strings.Join(parts, ","))
+}
+
+// loadAccessibleProviderModels returns the accessible providers and their model keys. The provider
+// IDs of a scope are served from Redis when a fresh entry exists, or from this process when Redis
+// is not configured, and the rows are rehydrated from the in-process cache before falling back to
+// the database. Cache failures, including corrupt entries, fall back to the database.
+func (s *ProviderRegistryService) loadAccessibleProviderModels(ctx context.Context, organizationID uint, projectIDs []uint) (*accessibleProviderModels, error) {
+	key := accessibleProviderModelsKey(organizationID, projectIDs)
+	var ids accessibleProviderIDs
+	found := false
+	if s.cache != nil {
+		found = s.cache.GetJSON(ctx, key, &ids)
+	} else {
+		ids, found = s.localCache.scope(key)
+	}
+	if found {
+		entry, err := s.rehydrateAccessibleProviders(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			return entry, nil
+		}
+	}
+
+	providers, err := s.ListAccessibleProviders(ctx, organizationID, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	entry := &accessibleProviderModels{
+		Providers: make([]*Provider, 0, len(providers)),
+		ModelKeys: make(map[uint][]string, len(providers)),
+	}
+	providerIDs := make([]uint, 0, len(providers))
+	activeIDs := make([]uint, 0, len(providers))
+	active := make(map[uint]*Provider, len(providers))
+	versions := make(map[uint]time.Time, len(providers))
+	for _, provider := range providers {
+		if provider == nil {
+			continue
+		}
+		entry.Providers = append(entry.Providers, provider)
+		providerIDs = append(providerIDs, provider.ID)
+		versions[provider.ID] = provider.UpdatedAt
+		if provider.ServesModels() {
+			activeIDs = append(activeIDs, provider.ID)
+			active[provider.ID] = provider
+		}
+	}
+	if len(activeIDs) > 0 {
+		providerModels, err := s.providerModelService.ListActiveByProviderIDs(ctx, activeIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, pm := range providerModels {
+			// Allowlist and denylist edits apply here right away; sync only catches the stored
+			// model state up with them.
+			if provider, ok := active[pm.ProviderID]; ok && provider.AllowsModel(pm.ModelKey) {
+				entry.ModelKeys[pm.ProviderID] = append(entry.ModelKeys[pm.ProviderID], pm.ModelKey)
+			}
+		}
+	}
+
+	ids = accessibleProviderIDs{ProviderIDs: providerIDs, ModelKeys: entry.ModelKeys, Versions: versions}
+	ttl := accessibleProviderModelsCacheTTL()
+	scopeKey := key
+	if s.cache != nil {
+		scopeKey = ""
+		if idsJSON, jsonErr := json.Marshal(ids); jsonErr == nil {
+			if cacheErr := s.cache.Set(ctx, key, string(idsJSON), ttl); cacheErr != nil {
+				logger.GetLogger().Errorf("failed to cache accessible providers for organization %d: %v", organizationID, cacheErr)
+			}
+		}
+	}
+	s.localCache.store(scopeKey, ids, entry.Providers, ttl)
+	return entry, nil
+}
+
+// rehydrateAccessibleProviders loads the providers of a cached scope, reading the rows this process
+// does not hold at the scope's version in one query. It returns nil when a provider no longer
+// exists or changed since the scope was cached, so the scope is resolved again.
+func (s *ProviderRegistryService) rehydrateAccessibleProviders(ctx context.Context, ids accessibleProviderIDs) (*accessibleProviderModels, error) {
+	rows, missing := s.localCache.rows(ids.ProviderIDs, ids.Versions)
+	if len(missing) > 0 {
+		loaded, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{IDs: &missing}, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.localCache.store("", accessibleProviderIDs{}, loaded, accessibleProviderModelsCacheTTL())
+		for _, provider := range loaded {
+			rows[provider.ID] = provider
+		}
+	}
+	entry := &accessibleProviderModels{
+		Providers: make([]*Provider, 0, len(ids.ProviderIDs)),
+		ModelKeys: ids.ModelKeys,
+	}
+	if entry.ModelKeys == nil {
+		entry.ModelKeys = map[uint][]string{}
+	}
+	for _, id := range ids.ProviderIDs {
+		provider, ok := rows[id]
+		if !ok || !provider.UpdatedAt.Equal(ids.Versions[id]) {
+			return nil, nil
+		}
+		entry.Providers = append(entry.Providers, provider)
+	}
+	return entry, nil
+}
+
+// WarmAccessibleProviderModels loads the accessible providers of the organization into the cache
+// so the first model lookup after startup does not have to go to the database.
+func (s *ProviderRegistryService) WarmAccessibleProviderModels(ctx context.Context, organizationID uint) error {
+	_, err := s.loadAccessibleProviderModels(ctx, organizationID, nil)
+	return err
+}
+
+// invalidateAccessibleProviderModels drops every cached provider lookup. Global providers are
+// visible to all organizations, so any provider change can affect any entry.
+func (s *ProviderRegistryService) invalidateAccessibleProviderModels(ctx context.Context) {
+	s.localCache.clear()
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.DeletePattern(ctx, cache.AccessibleProviderModelsPattern); err != nil {
+		logger.GetLogger().Errorf("failed to invalidate accessible provider cache: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package model_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache/cachetest"
+)
+
+func TestAccessibleProviderCacheHealsCorruptEntries(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(3)
+	key := fmt.Sprintf(cache.AccessibleProviderModelsKey, orgID, "")
+
+	tests := []struct {
+		name    string
+		corrupt string
+	}{
+		{name: "truncated json", corrupt: `{"provider_ids":[1,`},
+		{name: "json of another shape", corrupt: `{"provider_ids":"all"}`},
+		{name: "json array", corrupt: `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := modeltest.NewRegistry()
+			primary := &domainmodel.Provider{PublicID: "prov-primary", Slug: "primary", OrganizationID: &orgID, Active: true}
+			registry.Providers.Add(primary)
+			registry.Models.Add(&domainmodel.ProviderModel{ProviderID: primary.ID, ModelKey: "gpt", Active: true})
+			redisCache, store := cachetest.NewCache()
+			service := domainmodel.NewProviderRegistryService(
+				registry.Providers,
+				registry.ProviderModelService,
+				registry.ModelCatalogService,
+				registry.Lister,
+				registry.Availability,
+				registry.ModelAliasService,
+				registry.OrganizationService,
+				nil,
+				redisCache,
+				registry.Transactor,
+			)
+			if _, err := service.GetProvidersForModel(ctx, "gpt", orgID, nil); err != nil {
+				t.Fatalf("first resolution: %v", err)
+			}
+			if _, ok := store.Get(key); !ok {
+				t.Fatalf("resolution did not cache %s, stored keys: %v", key, store.Keys())
+			}
+
+			store.Set(key, tt.corrupt)
+			registry.ResetCalls()
+			providers, err := service.GetProvidersForModel(ctx, "gpt", orgID, nil)
+			if err != nil {
+				t.Fatalf("resolution with a corrupt entry: %v", err)
+			}
+			if len(providers) != 1 || providers[0].ID != primary.ID {
+				t.Fatalf("got %d providers, want the primary provider rebuilt from the repository", len(providers))
+			}
+			if registry.Providers.TotalCalls() == 0 {
+				t.Fatal("the corrupt entry was not rebuilt from the repository")
+			}
+
+			healed, _ := store.Get(key)
+			var ids struct {
+				ProviderIDs []uint `json:"provider_ids"`
+			}
+			if err := json.Unmarshal([]byte(healed), &ids); err != nil || len(ids.ProviderIDs) != 1 || ids.ProviderIDs[0] != primary.ID {
+				t.Fatalf("cache entry after rebuild = %s, want the provider IDs", healed)
+			}
+
+			registry.ResetCalls()
+			if _, err := service.GetProvidersForModel(ctx, "gpt", orgID, nil); err != nil {
+				t.Fatalf("resolution after healing: %v", err)
+			}
+			if calls := registry.Providers.TotalCalls() + registry.Models.TotalCalls(); calls != 0 {
+				t.Fatalf("resolution after healing made %d repository calls, want it served from the cache", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package model_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache/cachetest"
+)
+
+func TestRefreshingOneProviderKeepsTheOthersCached(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	key := fmt.Sprintf(cache.AccessibleProviderModelsKey, orgID, "")
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	openai := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, BaseURL: "https://api.openai.com/v1", OrganizationID: &orgID, Active: true}
+	mistral := &domainmodel.Provider{PublicID: "prov-mistral", Slug: "mistral", Kind: domainmodel.ProviderMistral, BaseURL: "https://api.mistral.ai/v1", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(openai, mistral)
+	redisCache, store := cachetest.NewCache()
+	service := domainmodel.NewProviderRegistryService(
+		registry.Providers,
+		registry.ProviderModelService,
+		registry.ModelCatalogService,
+		registry.Lister,
+		registry.Availability,
+		registry.ModelAliasService,
+		registry.OrganizationService,
+		nil,
+		redisCache,
+		registry.Transactor,
+	)
+
+	routedTo := func(modelKey string) string {
+		t.Helper()
+		provider, err := service.GetProviderForModel(ctx, modelKey, orgID, nil)
+		if err != nil {
+			t.Fatalf("GetProviderForModel(%s): %v", modelKey, err)
+		}
+		return provider.Slug
+	}
+	cachedProviderIDs := func() []uint {
+		t.Helper()
+		value, ok := store.Get(key)
+		if !ok {
+			t.Fatalf("%s is not cached, stored keys: %v", key, store.Keys())
+		}
+		var entry struct {
+			ProviderIDs []uint `json:"provider_ids"`
+		}
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			t.Fatalf("decode %s: %v", key, err)
+		}
+		slices.Sort(entry.ProviderIDs)
+		return entry.ProviderIDs
+	}
+
+	// The two providers refresh alternately; each refresh must leave the other's models routable
+	// and the cached entry listing both providers.
+	steps := []struct {
+		provider *domainmodel.Provider
+		models   []string
+	}{
+		{provider: openai, models: []string{"gpt-4o"}},
+		{provider: mistral, models: []string{"mistral-large"}},
+		{provider: openai, models: []string{"gpt-4o", "gpt-4.1"}},
+		{provider: mistral, models: []string{"mistral-large", "codestral"}},
+	}
+	want := map[string]string{}
+	for i, step := range steps {
+		registry.Lister.SetModels(step.provider.Slug, step.models...)
+		if _, err := service.RefreshProviderModels(ctx, step.provider); err != nil {
+			t.Fatalf("step %d: RefreshProviderModels(%s): %v", i, step.provider.Slug, err)
+		}
+		for _, modelKey := range step.models {
+			want[modelKey] = step.provider.Slug
+		}
+		for modelKey, slug := range want {
+			if got := routedTo(modelKey); got != slug {
+				t.Fatalf("step %d: %s routed to %s, want %s", i, modelKey, got, slug)
+			}
+		}
+		if got := cachedProviderIDs(); !slices.Equal(got, []uint{openai.ID, mistral.ID}) {
+			t.Fatalf("step %d: cached provider IDs = %v, want both providers", i, got)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+)
+
+func TestAccessibleProviderCache(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(3)
+
+	setup := func() (*modeltest.Registry, *domainmodel.Provider, *domainmodel.Provider) {
+		registry := modeltest.NewRegistry()
+		primary := &domainmodel.Provider{PublicID: "prov-primary", Slug: "primary", OrganizationID: &orgID, Active: true, Priority: 2,
+			EncryptedAPIKey: "cipher", Headers: map[string]string{"X-Team": "a"}}
+		secondary := &domainmodel.Provider{PublicID: "prov-secondary", Slug: "secondary", OrganizationID: &orgID, Active: true, Priority: 1}
+		registry.Providers.Add(primary, secondary)
+		registry.Models.Add(
+			&domainmodel.ProviderModel{ProviderID: primary.ID, ModelKey: "gpt", Active: true},
+			&domainmodel.ProviderModel{ProviderID: secondary.ID, ModelKey: "gpt", Active: true},
+		)
+		return registry, primary, secondary
+	}
+
+	t.Run("second resolution within the TTL does not call the repositories", func(t *testing.T) {
+		registry, primary, _ := setup()
+		if _, err := registry.GetProvidersForModel(ctx, "gpt", orgID, nil); err != nil {
+			t.Fatalf("first resolution: %v", err)
+		}
+		registry.ResetCalls()
+
+		providers, err := registry.GetProvidersForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("second resolution: %v", err)
+		}
+		if calls := registry.Providers.TotalCalls() + registry.Models.TotalCalls(); calls != 0 {
+			t.Fatalf("second resolution made %d provider repository calls, want 0", calls)
+		}
+		if len(providers) != 2 || providers[0].ID != primary.ID {
+			t.Fatalf("got %d providers, want primary first of 2", len(providers))
+		}
+		if providers[0].EncryptedAPIKey != "cipher" || providers[0].Headers["X-Team"] != "a" {
+			t.Fatal("rehydrated provider lost its key or headers")
+		}
+	})
+
+	t.Run("cached providers are copies", func(t *testing.T) {
+		registry, _, _ := setup()
+		providers, _ := registry.GetProvidersForModel(ctx, "gpt", orgID, nil)
+		providers[0].BaseURL = "https://changed.example.com"
+
+		providers, _ = registry.GetProvidersForModel(ctx, "gpt", orgID, nil)
+		if providers[0].BaseURL == "https://changed.example.com" {
+			t.Fatal("a caller's change leaked into the cache")
+		}
+	})
+
+	t.Run("registry changes invalidate the cache", func(t *testing.T) {
+		registry, primary, secondary := setup()
+		if _, err := registry.GetProvidersForModel(ctx, "gpt", orgID, nil); err != nil {
+			t.Fatalf("first resolution: %v", err)
+		}
+		if err := registry.DeleteProvider(ctx, primary); err != nil {
+			t.Fatalf("DeleteProvider: %v", err)
+		}
+		registry.ResetCalls()
+
+		providers, err := registry.GetProvidersForModel(ctx, "gpt", orgID, nil)
+		if err != nil {
+			t.Fatalf("resolution after delete: %v", err)
+		}
+		if registry.Providers.TotalCalls() == 0 {
+			t.Fatal("resolution after delete was served from the cache")
+		}
+		if len(providers) != 1 || providers[0].ID != secondary.ID {
+			t.Fatalf("got %d providers, want only the secondary provider", len(providers))
+		}
+	})
+}
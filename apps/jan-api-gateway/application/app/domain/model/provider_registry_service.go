@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/netip"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,29 +17,128 @@ import (
 	"menlo.ai/jan-api-gateway/app/domain/common"
 	"menlo.ai/jan-api-gateway/app/domain/organization"
 	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
 	"menlo.ai/jan-api-gateway/app/utils/crypto"
 	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	webhookclient "menlo.ai/jan-api-gateway/app/utils/httpclients/webhook"
 	"menlo.ai/jan-api-gateway/app/utils/idgen"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
 	"menlo.ai/jan-api-gateway/app/utils/ptr"
 	environment_variables "menlo.ai/jan-api-gateway/config/environment_variables"
 )
 
+// ErrCodeDuplicateCustomProvider is returned when a custom provider reuses a registered base URL
+// while MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL is enabled.
+const ErrCodeDuplicateCustomProvider = "7e5d1b3c-8a2f-4f69-b4c0-e9a6d2f8c351"
+
+// ErrCodeDuplicateProviderKind is returned when the scope already has a provider of the requested
+// non-custom kind. The error wraps a ProviderConflictError naming that provider.
+const ErrCodeDuplicateProviderKind = "323d2e23-4a8a-4f89-b090-4d49a0b0ca12"
+
+// ProviderConflictError identifies the registered provider that a registration conflicts with.
+type ProviderConflictError struct {
+	Message          string
+	ProviderPublicID string
+}
+
+func (e *ProviderConflictError) Error() string {
+	return e.Message
+}
+
+// ErrCodeInvalidProviderSlug is returned when a requested provider slug is not in slug form.
+const ErrCodeInvalidProviderSlug = "3a9f6c2e-8d41-4b57-b1e0-c4d7a2f9e865"
+
+// ErrCodeProviderSlugTaken is returned when a requested provider slug belongs to another provider.
+const ErrCodeProviderSlugTaken = "8c2e5a1f-6b9d-4f03-a7c4-e1d8b3f6a290"
+
+// ErrCodeUnsupportedBaseURLScheme is returned when a provider base URL is not http or https.
+const ErrCodeUnsupportedBaseURLScheme = "f4c8a2e6-9b3d-4e71-a5f0-d2b7c9e1a364"
+
+// ErrCodeNonPublicBaseURL is returned when a provider base URL names a host that is not publicly
+// routable and is not listed in MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS.
+const ErrCodeNonPublicBaseURL = "9d4b7e2a-1c68-4f35-b8a0-e6c3f9d2a157"
+
+// ErrCodeProviderKeyValidationFailed is returned when the upstream rejects the API key supplied at registration.
+const ErrCodeProviderKeyValidationFailed = "a8e2c6f1-4d9b-4b37-9c05-7f3e1d8b2a96"
+
+// ErrCodeInvalidProviderHeader is returned when a custom provider header is malformed or reserved.
+const ErrCodeInvalidProviderHeader = "5b8e1f3a-7c2d-4a96-b0e4-9d6f2c8a1e57"
+
+// ErrCodeProviderModelFetchFailed is returned when the upstream model list cannot be fetched.
+const ErrCodeProviderModelFetchFailed = "1d6a9f4e-3b7c-4e25-a8d1-f2c5b0e9a763"
+
+const (
+	// providerModelBatchThreshold is the model count above which syncs switch to batched writes.
+	providerModelBatchThreshold = 50
+	providerModelBatchSize      = 100
+)
+
+// ProviderModelLister fetches the models an upstream provider currently serves.
+type ProviderModelLister interface {
+	ListModels(ctx context.Context, provider *Provider) ([]chatclient.Model, error)
+}
+
+// ProviderAvailability reports whether a provider is currently accepting completion traffic.
+type ProviderAvailability interface {
+	IsProviderAvailable(providerID uint) bool
+}
+
+// ProviderTransactor runs fn in a database transaction; repository calls made with the context
+// passed to fn are committed or rolled back together.
+type ProviderTransactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type ProviderRegistryService struct {
 	providerRepo         ProviderRepository
 	providerModelService *ProviderModelService
 	modelCatalogService  *ModelCatalogService
+	modelLister          ProviderModelLister
+	availability         ProviderAvailability
+	modelAliasService    *ModelAliasService
+	organizationService  *organization.OrganizationService
+	webhookService       *webhook.WebhookService
+	cache                *cache.RedisCacheService
+	transactor           ProviderTransactor
+	localCache           *localProviderCache
+	// routingRandom drives weighted provider selection; it returns values in [0, 1).
+	routingRandom func() float64
 }
 
 func NewProviderRegistryService(
 	providerRepo ProviderRepository,
 	providerModelService *ProviderModelService,
 	modelCatalogService *ModelCatalogService,
+	modelLister ProviderModelLister,
+	availability ProviderAvailability,
+	modelAliasService *ModelAliasService,
+	organizationService *organization.OrganizationService,
+	webhookService *webhook.WebhookService,
+	cacheService *cache.RedisCacheService,
+	transactor ProviderTransactor,
 ) *ProviderRegistryService {
 	return &ProviderRegistryService{
 		providerRepo:         providerRepo,
 		providerModelService: providerModelService,
 		modelCatalogService:  modelCatalogService,
+		modelLister:          modelLister,
+		availability:         availability,
+		modelAliasService:    modelAliasService,
+		organizationService:  organizationService,
+		webhookService:       webhookService,
+		cache:                cacheService,
+		transactor:           transactor,
+		localCache:           newLocalProviderCache(),
+		routingRandom:        rand.Float64,
+	}
+}
+
+func (s *ProviderRegistryService) isProviderAvailable(provider *Provider) bool {
+	if s.availability == nil || provider.ID == 0 {
+		return true
 	}
+	return s.availability.IsProviderAvailable(provider.ID)
 }
 
 type RegisterProviderInput struct {
@@ -46,7 +149,25 @@ type RegisterProviderInput struct {
 	BaseURL        string
 	APIKey         string
 	Metadata       map[string]string
+	Headers        map[string]string
+	// PathPrefix, e.g. "/api/v1", is inserted between BaseURL and the operation paths.
+	PathPrefix string
+	// ModelAllowlist and ModelDenylist restrict which upstream models are synced as active; see
+	// Provider.AllowsModel.
+	ModelAllowlist []string
+	ModelDenylist  []string
 	Active         bool
+	Shadow         bool
+	// Priority orders the provider above others at the same scope when higher; see
+	// ListAccessibleProvidersByFilter.
+	Priority int
+	// KeyMode is "stored" (the default) or "passthrough"; see ProviderKeyMode.
+	KeyMode string
+	// ValidateKey checks the API key against the upstream /models endpoint before the provider is stored.
+	ValidateKey bool
+	// Slug, when set, is used as-is instead of a slug derived from the kind and name. Registration
+	// fails if it is already taken.
+	Slug string
 }
 
 type UpdateProviderInput struct {
@@ -54,12 +175,26 @@ type UpdateProviderInput struct {
 	BaseURL  *string
 	APIKey   *string
 	Metadata *map[string]string
+	Headers  *map[string]string
 	Active   *bool
+	Shadow   *bool
+	Priority *int
+	// PathPrefix replaces the provider's path prefix; an empty string removes it.
+	PathPrefix *string
+	// ModelAllowlist and ModelDenylist replace the provider's lists. Routing honors them right away;
+	// the stored model state follows on the next sync.
+	ModelAllowlist *[]string
+	ModelDenylist  *[]string
+	KeyMode        *string
 }
 
+// ProviderModelSyncResult is the outcome of syncing one model. Error is set, and ProviderModel and
+// Catalog are nil, when the model could not be written.
 type ProviderModelSyncResult struct {
+	ModelKey      string
 	ProviderModel *ProviderModel
 	Catalog       *ModelCatalog
+	Error         *common.Error
 }
 
 type ProviderRegistrationResult struct {
@@ -77,11 +212,24 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 	if baseURL == "" {
 		return nil, common.NewErrorWithMessage("base_url is required", "9f0f7d62-4bbd-4d61-980e-dfc4d67a45f1")
 	}
-	if _, err := url.ParseRequestURI(baseURL); err != nil {
-		return nil, common.NewError(err, "6c04d2f8-c39a-41a4-8d4a-0c2787b6ee2f")
+	if err := validateProviderBaseURL(baseURL, "6c04d2f8-c39a-41a4-8d4a-0c2787b6ee2f"); err != nil {
+		return nil, err
+	}
+	pathPrefix, pathPrefixErr := sanitizePathPrefix(input.PathPrefix)
+	if pathPrefixErr != nil {
+		return nil, pathPrefixErr
+	}
+
+	requestedSlug := strings.TrimSpace(input.Slug)
+	if requestedSlug != "" && slugify(requestedSlug) != requestedSlug {
+		return nil, common.NewErrorWithMessage("slug may only contain lowercase letters, digits and single hyphens", ErrCodeInvalidProviderSlug)
 	}
 
 	kind := providerKindFromVendor(input.Vendor)
+	keyMode, keyModeErr := parseProviderKeyMode(input.KeyMode)
+	if keyModeErr != nil {
+		return nil, keyModeErr
+	}
 
 	orgIDValue := organization.DEFAULT_ORGANIZATION.ID
 	if input.OrganizationID != 0 {
@@ -101,18 +249,35 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 		} else {
 			filter.WithoutProject = ptr.ToBool(true)
 		}
-		count, err := s.providerRepo.Count(ctx, filter)
+		existing, err := s.providerRepo.FindByFilter(ctx, filter, &query.Pagination{Limit: ptr.ToInt(1)})
 		if err != nil {
 			return nil, common.NewError(err, "5dc6de3c-d6df-410c-9329-48a306d0e4f7")
 		}
-		if count > 0 {
-			return nil, common.NewErrorWithMessage("provider kind already exists", "323d2e23-4a8a-4f89-b090-4d49a0b0ca12")
+		if len(existing) > 0 {
+			return nil, common.NewError(&ProviderConflictError{
+				Message:          "provider kind already exists",
+				ProviderPublicID: existing[0].PublicID,
+			}, ErrCodeDuplicateProviderKind)
 		}
+	} else if err := s.checkCustomBaseURLUnique(ctx, baseURL, organizationID, projectID); err != nil {
+		return nil, err
 	}
 
-	slug, err := s.generateUniqueSlug(ctx, slugCandidate(kind, name))
-	if err != nil {
-		return nil, common.NewError(err, "6df1386c-5aa0-4105-9366-74ad8637bd1a")
+	slug := requestedSlug
+	if slug != "" {
+		taken, err := s.slugTaken(ctx, slug)
+		if err != nil {
+			return nil, common.NewError(err, "e1a7c4f9-3b2d-4d68-a0e5-6f9c2b8d4a13")
+		}
+		if taken {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("slug %q is already in use", slug), ErrCodeProviderSlugTaken)
+		}
+	} else {
+		generated, err := s.generateUniqueSlug(ctx, slugCandidate(kind, name))
+		if err != nil {
+			return nil, common.NewError(err, "6df1386c-5aa0-4105-9366-74ad8637bd1a")
+		}
+		slug = generated
 	}
 
 	publicID, err := idgen.GenerateSecureID("prov", 24)
@@ -120,7 +285,7 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 		return nil, common.NewError(err, "2d3d6c9a-5f36-4de2-8f5f-77f8401d5dd4")
 	}
 
-	plainAPIKey := strings.TrimSpace(input.APIKey)
+	plainAPIKey := providerAPIKey(input.APIKey)
 	apiKeyHint := apiKeyHint(plainAPIKey)
 	var encryptedAPIKey string
 	if plainAPIKey != "" {
@@ -135,7 +300,22 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 		encryptedAPIKey = cipher
 	}
 
-	metadata := sanitizeMetadata(input.Metadata)
+	metadata, metadataErr := sanitizeMetadata(input.Metadata, nil)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+	headers, headersErr := sanitizeHeaders(input.Headers, encryptedAPIKey != "")
+	if headersErr != nil {
+		return nil, headersErr
+	}
+	allowlist, allowlistErr := sanitizeModelPatterns(input.ModelAllowlist)
+	if allowlistErr != nil {
+		return nil, allowlistErr
+	}
+	denylist, denylistErr := sanitizeModelPatterns(input.ModelDenylist)
+	if denylistErr != nil {
+		return nil, denylistErr
+	}
 
 	provider := &Provider{
 		PublicID:        publicID,
@@ -145,16 +325,30 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 		DisplayName:     name,
 		Kind:            kind,
 		BaseURL:         normalizeURL(baseURL),
+		PathPrefix:      pathPrefix,
 		EncryptedAPIKey: encryptedAPIKey,
 		APIKeyHint:      apiKeyHint,
 		IsModerated:     false,
 		Active:          input.Active,
+		Shadow:          input.Shadow,
+		Priority:        input.Priority,
+		KeyMode:         keyMode,
 		Metadata:        metadata,
+		Headers:         headers,
+		ModelAllowlist:  allowlist,
+		ModelDenylist:   denylist,
+	}
+
+	if input.ValidateKey {
+		if err := s.validateProviderKey(ctx, provider); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.providerRepo.Create(ctx, provider); err != nil {
 		return nil, common.NewError(err, "5c1db208-0f8c-4c2b-90d9-5112e9cf2a47")
 	}
+	s.invalidateAccessibleProviderModels(ctx)
 
 	return &ProviderRegistrationResult{
 		Provider: provider,
@@ -162,45 +356,190 @@ func (s *ProviderRegistryService) RegisterProvider(ctx context.Context, input Re
 	}, nil
 }
 
-func providerKindFromVendor(vendor string) ProviderKind {
-	switch strings.ToLower(strings.TrimSpace(vendor)) {
-	case "jan":
-		return ProviderJan
-	case "openrouter":
-		return ProviderOpenRouter
-	case "openai":
-		return ProviderOpenAI
-	case "anthropic":
-		return ProviderAnthropic
-	case "gemini", "google", "googleai":
-		return ProviderGemini
-	case "mistral":
-		return ProviderMistral
-	case "groq":
-		return ProviderGroq
-	case "cohere":
-		return ProviderCohere
-	case "ollama":
-		return ProviderOllama
-	case "replicate":
-		return ProviderReplicate
-	case "azure_openai", "azure-openai":
-		return ProviderAzureOpenAI
-	case "aws_bedrock", "bedrock":
-		return ProviderAWSBedrock
-	case "perplexity":
-		return ProviderPerplexity
-	case "togetherai", "together":
-		return ProviderTogetherAI
-	case "huggingface":
-		return ProviderHuggingFace
-	case "vercel_ai", "vercel-ai", "vercel":
-		return ProviderVercelAI
-	case "deepinfra":
-		return ProviderDeepInfra
-	default:
-		return ProviderCustom
+// ProviderConnectionResult describes the outcome of a connection dry-run.
+type ProviderConnectionResult struct {
+	Reachable      bool
+	ModelCount     int
+	Latency        time.Duration
+	UpstreamStatus int
+	Error          string
+}
+
+// TestProviderConnection checks that the base URL and API key in the input can list models,
+// using a transient provider that is never persisted. Metadata and headers are validated and
+// applied as on registration, so vendors configured through them can be tested. Validation
+// failures are returned as errors; upstream failures are reported on the result.
+func (s *ProviderRegistryService) TestProviderConnection(ctx context.Context, input RegisterProviderInput) (*ProviderConnectionResult, *common.Error) {
+	baseURL := strings.TrimSpace(input.BaseURL)
+	if baseURL == "" {
+		return nil, common.NewErrorWithMessage("base_url is required", "0b8e3f51-5d0c-4f0e-a6a4-5c1f0a7e2d93")
+	}
+	if err := validateProviderBaseURL(baseURL, "7c2d9a14-3e6b-4b8f-9d51-e0a4c6f8b217"); err != nil {
+		return nil, err
+	}
+	pathPrefix, pathPrefixErr := sanitizePathPrefix(input.PathPrefix)
+	if pathPrefixErr != nil {
+		return nil, pathPrefixErr
+	}
+
+	provider := &Provider{
+		DisplayName: strings.TrimSpace(input.Name),
+		Kind:        providerKindFromVendor(input.Vendor),
+		BaseURL:     normalizeURL(baseURL),
+		PathPrefix:  pathPrefix,
 	}
+	if provider.DisplayName == "" {
+		provider.DisplayName = string(provider.Kind)
+	}
+
+	plainAPIKey := providerAPIKey(input.APIKey)
+	if plainAPIKey != "" {
+		secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+		if secret == "" {
+			return nil, common.NewErrorWithMessage("model provider secret is not configured", "e4a1b7c2-9f3d-4c58-b06e-2d8f5a9c1e74")
+		}
+		cipher, err := crypto.EncryptString(secret, plainAPIKey)
+		if err != nil {
+			return nil, common.NewError(err, "5f9c2e8a-1b4d-4a7e-8c36-f0d2b9e5a418")
+		}
+		provider.EncryptedAPIKey = cipher
+	}
+
+	metadata, metadataErr := sanitizeMetadata(input.Metadata, nil)
+	if metadataErr != nil {
+		return nil, metadataErr
+	}
+	headers, headersErr := sanitizeHeaders(input.Headers, provider.EncryptedAPIKey != "")
+	if headersErr != nil {
+		return nil, headersErr
+	}
+	provider.Metadata = metadata
+	provider.Headers = headers
+
+	start := time.Now()
+	models, err := s.modelLister.ListModels(ctx, provider)
+	result := &ProviderConnectionResult{Latency: time.Since(start)}
+	if err != nil {
+		result.Error = err.Error()
+		var upstreamErr *chatclient.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			result.UpstreamStatus = upstreamErr.StatusCode
+		}
+		return result, nil
+	}
+	result.Reachable = true
+	result.ModelCount = len(models)
+	return result, nil
+}
+
+// validateProviderKey lists the upstream models with the provider's credentials so a mistyped
+// key is reported at registration rather than on the first completion.
+func (s *ProviderRegistryService) validateProviderKey(ctx context.Context, provider *Provider) *common.Error {
+	if _, err := s.modelLister.ListModels(ctx, provider); err != nil {
+		var upstreamErr *chatclient.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			return common.NewErrorWithMessage(fmt.Sprintf("api key validation failed: upstream returned status %d", upstreamErr.StatusCode), ErrCodeProviderKeyValidationFailed)
+		}
+		return common.NewErrorWithMessage(fmt.Sprintf("api key validation failed: %v", err), ErrCodeProviderKeyValidationFailed)
+	}
+	return nil
+}
+
+// ErrCodeProviderKeyDecryptFailed is returned when a provider's stored credentials cannot be
+// decrypted with the current MODEL_PROVIDER_SECRET, typically because they were encrypted with an
+// earlier secret.
+const ErrCodeProviderKeyDecryptFailed = "c3f8a1d6-4e2b-4b79-9d05-a7e2c6b1f843"
+
+// ErrCodeProviderKeyAuthFailed is returned when a provider's credentials decrypt but the upstream
+// rejects them or cannot be reached.
+const ErrCodeProviderKeyAuthFailed = "8b5e2d9f-1c6a-4f37-a4e0-d2b7f3c9e561"
+
+// ProviderKeyVerification is the outcome of VerifyProviderKey. It never carries the plaintext key.
+type ProviderKeyVerification struct {
+	Decryptable    bool
+	AuthOK         bool
+	UpstreamStatus int
+}
+
+// VerifyProviderKey checks that the provider's stored API key and secret metadata decrypt with the
+// current MODEL_PROVIDER_SECRET and that the upstream accepts them for listing models. Decryption
+// failures return ErrCodeProviderKeyDecryptFailed and upstream failures
+// ErrCodeProviderKeyAuthFailed; the verification is returned alongside either error.
+func (s *ProviderRegistryService) VerifyProviderKey(ctx context.Context, provider *Provider) (*ProviderKeyVerification, *common.Error) {
+	result := &ProviderKeyVerification{}
+	if provider.EncryptedAPIKey != "" {
+		secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+		if secret == "" {
+			return result, common.NewErrorWithMessage("model provider secret is not configured", ErrCodeProviderKeyDecryptFailed)
+		}
+		if _, err := crypto.DecryptString(secret, provider.EncryptedAPIKey); err != nil {
+			return result, common.NewErrorWithMessage("stored api key cannot be decrypted with the current secret", ErrCodeProviderKeyDecryptFailed)
+		}
+	}
+	if _, err := DecryptMetadata(provider.Metadata); err != nil {
+		return result, common.NewErrorWithMessage("stored secret metadata cannot be decrypted with the current secret", ErrCodeProviderKeyDecryptFailed)
+	}
+	result.Decryptable = true
+
+	if _, err := s.modelLister.ListModels(ctx, provider); err != nil {
+		var upstreamErr *chatclient.UpstreamError
+		if errors.As(err, &upstreamErr) {
+			result.UpstreamStatus = upstreamErr.StatusCode
+			return result, common.NewErrorWithMessage(fmt.Sprintf("upstream rejected the api key with status %d", upstreamErr.StatusCode), ErrCodeProviderKeyAuthFailed)
+		}
+		return result, common.NewErrorWithMessage(fmt.Sprintf("upstream check failed: %v", err), ErrCodeProviderKeyAuthFailed)
+	}
+	result.AuthOK = true
+	return result, nil
+}
+
+// validateProviderBaseURL rejects base URLs that do not parse, any scheme other than http and
+// https, which the chat clients cannot talk to, and hosts that name a non-public address, with the
+// same guard as webhooks, so an organization cannot point the gateway at its own network.
+// MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS lets self-hosted upstreams through.
+func validateProviderBaseURL(baseURL string, parseErrCode string) *common.Error {
+	parsed, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return common.NewError(err, parseErrCode)
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return common.NewErrorWithMessage(fmt.Sprintf("base_url scheme %q is not supported, use http or https", parsed.Scheme), ErrCodeUnsupportedBaseURLScheme)
+	}
+	if parsed.Host == "" {
+		return common.NewErrorWithMessage("base_url must include a host", ErrCodeUnsupportedBaseURLScheme)
+	}
+	host := parsed.Hostname()
+	if err := webhookclient.CheckHost(host); err != nil && !privateHostAllowed(host) {
+		return common.NewErrorWithMessage(fmt.Sprintf("base_url host %q is not publicly routable", host), ErrCodeNonPublicBaseURL)
+	}
+	return nil
+}
+
+// privateHostAllowed reports whether MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS lists the host, by name,
+// by address or through a CIDR prefix containing it.
+func privateHostAllowed(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	ip, ipErr := netip.ParseAddr(host)
+	for _, entry := range environment_variables.EnvironmentVariables.MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS {
+		entry = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+		if entry == "" {
+			continue
+		}
+		if entry == host {
+			return true
+		}
+		if ipErr != nil {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil && prefix.Contains(ip.Unmap()) {
+			return true
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil && addr.Unmap() == ip.Unmap() {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *ProviderRegistryService) generateUniqueSlug(ctx context.Context, base string) (string, error) {
@@ -211,12 +550,11 @@ func (s *ProviderRegistryService) generateUniqueSlug(ctx context.Context, base s
 	slug := candidate
 	counter := 1
 	for {
-		filter := ProviderFilter{Slug: &slug}
-		result, err := s.providerRepo.FindByFilter(ctx, filter, &query.Pagination{Limit: ptr.ToInt(1)})
+		taken, err := s.slugTaken(ctx, slug)
 		if err != nil {
 			return "", err
 		}
-		if len(result) == 0 {
+		if !taken {
 			return slug, nil
 		}
 		counter++
@@ -224,12 +562,81 @@ func (s *ProviderRegistryService) generateUniqueSlug(ctx context.Context, base s
 	}
 }
 
+func (s *ProviderRegistryService) slugTaken(ctx context.Context, slug string) (bool, error) {
+	filter := ProviderFilter{Slug: &slug}
+	result, err := s.providerRepo.FindByFilter(ctx, filter, &query.Pagination{Limit: ptr.ToInt(1)})
+	if err != nil {
+		return false, err
+	}
+	return len(result) > 0, nil
+}
+
+// ReslugResult records a slug that was regenerated by RepairSlugs.
+type ReslugResult struct {
+	Provider *Provider
+	OldSlug  string
+	NewSlug  string
+}
+
+// RepairSlugs regenerates slugs for the organization's providers whose slug is empty or shared with
+// another provider. The earliest provider keeps a duplicated slug, so running it again is a no-op.
+func (s *ProviderRegistryService) RepairSlugs(ctx context.Context, organizationID uint) ([]ReslugResult, *common.Error) {
+	providers, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+	}, &query.Pagination{Order: "asc"})
+	if err != nil {
+		return nil, common.NewError(err, "7b3e9d1f-5a28-4c64-b0e7-1f9a3c5d8e42")
+	}
+
+	results := []ReslugResult{}
+	for _, provider := range providers {
+		slug := strings.TrimSpace(provider.Slug)
+		if slug != "" {
+			// Compare against every provider so collisions with other organizations are repaired too.
+			holders, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{Slug: &slug}, &query.Pagination{Order: "asc"})
+			if err != nil {
+				return nil, common.NewError(err, "c2f6a8e4-9d13-4b57-a3e0-6b8d2f4c1a95")
+			}
+			if len(holders) == 0 || holders[0].ID == provider.ID {
+				continue
+			}
+		}
+
+		newSlug, err := s.generateUniqueSlug(ctx, slugCandidate(provider.Kind, provider.DisplayName))
+		if err != nil {
+			return nil, common.NewError(err, "4a8c2e6f-1b39-4d75-9e0a-8f3b5d7c2e16")
+		}
+		oldSlug := provider.Slug
+		provider.Slug = newSlug
+		if err := s.providerRepo.Update(ctx, provider); err != nil {
+			return nil, common.NewError(err, "e9d1b5f3-6c47-4a28-b2e8-3d7f9a1c5e04")
+		}
+		s.invalidateAccessibleProviderModels(ctx)
+		results = append(results, ReslugResult{
+			Provider: provider,
+			OldSlug:  oldSlug,
+			NewSlug:  newSlug,
+		})
+	}
+	return results, nil
+}
+
 func slugCandidate(kind ProviderKind, name string) string {
 	return fmt.Sprintf("%s-%s", string(kind), name)
 }
 
 var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
 
+// providerAPIKey trims the API key and maps the "none" placeholder, used for upstreams without
+// authentication, to no key, so it is neither encrypted nor requires MODEL_PROVIDER_SECRET.
+func providerAPIKey(apiKey string) string {
+	key := strings.TrimSpace(apiKey)
+	if strings.EqualFold(key, "none") {
+		return ""
+	}
+	return key
+}
+
 func apiKeyHint(apiKey string) *string {
 	key := strings.TrimSpace(apiKey)
 	if len(key) < 4 {
@@ -250,6 +657,30 @@ func (s *ProviderRegistryService) FindByPublicID(ctx context.Context, publicID s
 	return provider, nil
 }
 
+// checkCustomBaseURLUnique rejects a base URL already used by a custom provider of the same
+// organization or project scope while MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL is enabled.
+func (s *ProviderRegistryService) checkCustomBaseURLUnique(ctx context.Context, baseURL string, organizationID *uint, projectID *uint) *common.Error {
+	if !environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL {
+		return nil
+	}
+	kind := ProviderCustom
+	filter := ProviderFilter{Kind: &kind, BaseURL: ptr.ToString(normalizeURL(baseURL))}
+	filter.OrganizationID = organizationID
+	if projectID != nil {
+		filter.ProjectID = projectID
+	} else {
+		filter.WithoutProject = ptr.ToBool(true)
+	}
+	count, err := s.providerRepo.Count(ctx, filter)
+	if err != nil {
+		return common.NewError(err, "b3f7a9d2-4c6e-4e18-9a5b-2d0c8f1e6a74")
+	}
+	if count > 0 {
+		return common.NewErrorWithMessage("custom provider with this base_url already exists", ErrCodeDuplicateCustomProvider)
+	}
+	return nil
+}
+
 func (s *ProviderRegistryService) UpdateProvider(ctx context.Context, provider *Provider, input UpdateProviderInput) (*Provider, *common.Error) {
 	if input.Name != nil {
 		name := strings.TrimSpace(*input.Name)
@@ -263,13 +694,25 @@ func (s *ProviderRegistryService) UpdateProvider(ctx context.Context, provider *
 		if baseURL == "" {
 			return nil, common.NewErrorWithMessage("base_url is required", "6eaf9ef7-281b-45f7-9b8d-668f6d2f5d8e")
 		}
-		if _, err := url.ParseRequestURI(baseURL); err != nil {
-			return nil, common.NewError(err, "1fbfba8e-4fa9-4e06-8132-8d6754d88d5f")
+		if err := validateProviderBaseURL(baseURL, "1fbfba8e-4fa9-4e06-8132-8d6754d88d5f"); err != nil {
+			return nil, err
+		}
+		if provider.Kind == ProviderCustom && normalizeURL(baseURL) != provider.BaseURL {
+			if err := s.checkCustomBaseURLUnique(ctx, baseURL, provider.OrganizationID, provider.ProjectID); err != nil {
+				return nil, err
+			}
 		}
 		provider.BaseURL = normalizeURL(baseURL)
 	}
+	if input.PathPrefix != nil {
+		pathPrefix, err := sanitizePathPrefix(*input.PathPrefix)
+		if err != nil {
+			return nil, err
+		}
+		provider.PathPrefix = pathPrefix
+	}
 	if input.APIKey != nil {
-		key := strings.TrimSpace(*input.APIKey)
+		key := providerAPIKey(*input.APIKey)
 		if key == "" {
 			provider.EncryptedAPIKey = ""
 			provider.APIKeyHint = nil
@@ -287,23 +730,179 @@ func (s *ProviderRegistryService) UpdateProvider(ctx context.Context, provider *
 		}
 	}
 	if input.Metadata != nil {
-		provider.Metadata = sanitizeMetadata(*input.Metadata)
+		metadata, err := sanitizeMetadata(*input.Metadata, provider.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		provider.Metadata = metadata
+	}
+	if input.Headers != nil || input.APIKey != nil {
+		headers := provider.Headers
+		if input.Headers != nil {
+			headers = *input.Headers
+		}
+		sanitized, err := sanitizeHeaders(headers, provider.EncryptedAPIKey != "")
+		if err != nil {
+			return nil, err
+		}
+		provider.Headers = sanitized
+	}
+	if input.ModelAllowlist != nil {
+		allowlist, err := sanitizeModelPatterns(*input.ModelAllowlist)
+		if err != nil {
+			return nil, err
+		}
+		provider.ModelAllowlist = allowlist
+	}
+	if input.ModelDenylist != nil {
+		denylist, err := sanitizeModelPatterns(*input.ModelDenylist)
+		if err != nil {
+			return nil, err
+		}
+		provider.ModelDenylist = denylist
 	}
 	if input.Active != nil {
 		provider.Active = *input.Active
 	}
+	if input.Shadow != nil {
+		provider.Shadow = *input.Shadow
+	}
+	if input.Priority != nil {
+		provider.Priority = *input.Priority
+	}
+	if input.KeyMode != nil {
+		keyMode, err := parseProviderKeyMode(*input.KeyMode)
+		if err != nil {
+			return nil, err
+		}
+		provider.KeyMode = keyMode
+	}
 	if err := s.providerRepo.Update(ctx, provider); err != nil {
 		return nil, common.NewError(err, "3f3a055d-a4d7-4dd2-8795-2b5e9b6d7677")
 	}
+	s.invalidateAccessibleProviderModels(ctx)
 	return provider, nil
 }
 
+// RotateAPIKey replaces the provider API key, keeping the hint of the previous key and the rotation
+// time so a compromised key can be shown to have been retired. Unlike UpdateProvider, an empty key is rejected.
+func (s *ProviderRegistryService) RotateAPIKey(ctx context.Context, provider *Provider, newKey string) (*Provider, *common.Error) {
+	key := providerAPIKey(newKey)
+	if key == "" {
+		return nil, common.NewErrorWithMessage("api_key is required", "9a4e1c7b-2d5f-4e83-b6a0-c3f8d2e7a154")
+	}
+	secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+	if secret == "" {
+		return nil, common.NewErrorWithMessage("model provider secret is not configured", "3e7b9d2a-5c1f-4a68-9e04-b8d6f1c2a937")
+	}
+	cipher, err := crypto.EncryptString(secret, key)
+	if err != nil {
+		return nil, common.NewError(err, "c6d2f8a1-7e3b-4b95-a0c4-e5f9b3d1a826")
+	}
+
+	rotatedAt := time.Now()
+	provider.PreviousAPIKeyHint = provider.APIKeyHint
+	provider.EncryptedAPIKey = cipher
+	provider.APIKeyHint = apiKeyHint(key)
+	provider.KeyRotatedAt = &rotatedAt
+	if err := s.providerRepo.Update(ctx, provider); err != nil {
+		return nil, common.NewError(err, "e2a8c4f6-1b9d-4d37-8f5e-a7c3b0d6e192")
+	}
+	s.invalidateAccessibleProviderModels(ctx)
+	return provider, nil
+}
+
+// DeleteProvider removes the provider together with its provider models and the model aliases
+// pointing at it, and clears it as its organization's default provider. Catalog entries that were
+// only referenced by the deleted models are removed as well; entries shared with other providers
+// and entries an admin curated are kept. All changes are made in one transaction.
+func (s *ProviderRegistryService) DeleteProvider(ctx context.Context, provider *Provider) *common.Error {
+	var cErr *common.Error
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		cErr = s.deleteProvider(ctx, provider)
+		if cErr != nil {
+			return cErr
+		}
+		return nil
+	})
+	if cErr != nil {
+		return cErr
+	}
+	if err != nil {
+		return common.NewError(err, "4e7b2d9a-6c1f-4a83-b5e0-d9f3a8c2e614")
+	}
+	s.invalidateAccessibleProviderModels(ctx)
+	return nil
+}
+
+func (s *ProviderRegistryService) deleteProvider(ctx context.Context, provider *Provider) *common.Error {
+	providerModels, err := s.providerModelService.ListByProviderID(ctx, provider.ID)
+	if err != nil {
+		return common.NewError(err, "b8e4d2a1-3c7f-4e95-a0b6-5d1f9c2e7a38")
+	}
+	catalogIDs := map[uint]struct{}{}
+	for _, pm := range providerModels {
+		if pm.ModelCatalogID != nil {
+			catalogIDs[*pm.ModelCatalogID] = struct{}{}
+		}
+	}
+
+	if s.modelAliasService != nil {
+		if err := s.modelAliasService.DeleteByProviderID(ctx, provider.ID); err != nil {
+			return err
+		}
+	}
+	if err := s.clearOrganizationDefaultProvider(ctx, provider); err != nil {
+		return err
+	}
+	if err := s.providerModelService.DeleteByProviderID(ctx, provider.ID); err != nil {
+		return common.NewError(err, "6f2a9c4e-1b8d-4a37-9e05-c3d7b1f8e264")
+	}
+	if err := s.providerRepo.DeleteByID(ctx, provider.ID); err != nil {
+		return common.NewError(err, "0d7e3b5f-a412-4c86-b9f1-8e2c6a4d0f57")
+	}
+
+	for catalogID := range catalogIDs {
+		count, err := s.providerModelService.CountByCatalogID(ctx, catalogID)
+		if err != nil {
+			return common.NewError(err, "9c1f5e3a-7d28-4b60-a4e9-2b8d0c6f1e73")
+		}
+		if count > 0 {
+			continue
+		}
+		catalog, err := s.modelCatalogService.FindByID(ctx, catalogID)
+		if err != nil {
+			return common.NewError(err, "3b9e6d1f-8a24-4c57-b0f3-e6d2a8c4f915")
+		}
+		if catalog == nil || catalog.Status == ModelCatalogStatusUpdated {
+			continue
+		}
+		if err := s.modelCatalogService.DeleteByID(ctx, catalogID); err != nil {
+			return common.NewError(err, "e5a3c7d9-4f16-4b82-8d0e-7a9b1c3f5e26")
+		}
+	}
+	return nil
+}
+
 // ListAccessibleProviders returns providers accessible to the caller ordered by priority:
 // project-scoped providers first, followed by organization-level and finally global providers.
+// Within a scope, providers are ordered by descending Priority and then by name.
 func (s *ProviderRegistryService) ListAccessibleProviders(ctx context.Context, organizationID uint, projectIDs []uint) ([]*Provider, error) {
+	return s.ListAccessibleProvidersByFilter(ctx, organizationID, projectIDs, ProviderFilter{})
+}
+
+// ListAccessibleProvidersByFilter lists the accessible providers narrowed by the Kind and Active
+// conditions of the filter; scope conditions on the filter are ignored.
+func (s *ProviderRegistryService) ListAccessibleProvidersByFilter(ctx context.Context, organizationID uint, projectIDs []uint, filter ProviderFilter) ([]*Provider, error) {
+	scoped := func(scope ProviderFilter) ProviderFilter {
+		scope.Kind = filter.Kind
+		scope.Active = filter.Active
+		return scope
+	}
 	result := []*Provider{}
 	seen := map[uint]struct{}{}
 	appendUnique := func(items []*Provider) {
+		sortProvidersByPriority(items)
 		for _, provider := range items {
 			if provider == nil {
 				continue
@@ -318,41 +917,257 @@ func (s *ProviderRegistryService) ListAccessibleProviders(ctx context.Context, o
 	orgID := ptr.ToUint(organizationID)
 	if len(projectIDs) > 0 {
 		ids := projectIDs
-		projectProviders, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{
+		projectProviders, err := s.providerRepo.FindByFilter(ctx, scoped(ProviderFilter{
 			OrganizationID: orgID,
 			ProjectIDs:     &ids,
-		}, nil)
+		}), nil)
 		if err != nil {
 			return nil, err
 		}
 		appendUnique(projectProviders)
 	}
-	orgProviders, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{
+	orgProviders, err := s.providerRepo.FindByFilter(ctx, scoped(ProviderFilter{
 		OrganizationID: orgID,
 		WithoutProject: ptr.ToBool(true),
-	}, nil)
+	}), nil)
 	if err != nil {
 		return nil, err
 	}
 	appendUnique(orgProviders)
 	if organization.DEFAULT_ORGANIZATION != nil {
-		globalProviders, err := s.providerRepo.FindByFilter(ctx, ProviderFilter{
+		globalProviders, err := s.providerRepo.FindByFilter(ctx, scoped(ProviderFilter{
 			OrganizationID: ptr.ToUint(organization.DEFAULT_ORGANIZATION.ID),
 			WithoutProject: ptr.ToBool(true),
-		}, nil)
+		}), nil)
 		if err != nil {
 			return nil, err
 		}
 		appendUnique(globalProviders)
 	}
+	janProvider, err := s.FindJanProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if janProvider != nil && (filter.Kind == nil || *filter.Kind == janProvider.Kind) && (filter.Active == nil || *filter.Active == janProvider.Active) {
+		appendUnique([]*Provider{janProvider})
+	}
 	return result, nil
 }
 
+// sortProvidersByPriority orders providers of a single scope by descending Priority, then by
+// display name, keeping the repository order for ties.
+func sortProvidersByPriority(providers []*Provider) {
+	sort.SliceStable(providers, func(i, j int) bool {
+		if providers[i].Priority != providers[j].Priority {
+			return providers[i].Priority > providers[j].Priority
+		}
+		return providers[i].DisplayName < providers[j].DisplayName
+	})
+}
+
+// FindJanProvider returns the active global Jan provider, or nil when none has been registered.
+func (s *ProviderRegistryService) FindJanProvider(ctx context.Context) (*Provider, error) {
+	kind := ProviderJan
+	filter := ProviderFilter{
+		Kind:           &kind,
+		WithoutProject: ptr.ToBool(true),
+		Active:         ptr.ToBool(true),
+	}
+	if organization.DEFAULT_ORGANIZATION != nil {
+		filter.OrganizationID = ptr.ToUint(organization.DEFAULT_ORGANIZATION.ID)
+	}
+	providers, err := s.providerRepo.FindByFilter(ctx, filter, &query.Pagination{Limit: ptr.ToInt(1)})
+	if err != nil {
+		return nil, err
+	}
+	if len(providers) == 0 {
+		return nil, nil
+	}
+	return providers[0], nil
+}
+
+// ListModelsForProvider lists the synced models of a single provider, optionally filtered by active state.
+func (s *ProviderRegistryService) ListModelsForProvider(ctx context.Context, providerID uint, active *bool) ([]*ProviderModel, error) {
+	return s.providerModelService.ListByProviderIDAndActive(ctx, providerID, active)
+}
+
+// FindProviderModel returns the provider's active model with the given key, or nil if the
+// provider does not serve it.
+func (s *ProviderRegistryService) FindProviderModel(ctx context.Context, providerID uint, modelKey string) (*ProviderModel, error) {
+	models, err := s.providerModelService.FindActiveByProviderIDsAndKey(ctx, []uint{providerID}, modelKey)
+	if err != nil || len(models) == 0 {
+		return nil, err
+	}
+	return models[0], nil
+}
+
+// FindModelCatalog returns the catalog entry of the provider model, or nil when it has none.
+func (s *ProviderRegistryService) FindModelCatalog(ctx context.Context, providerModel *ProviderModel) (*ModelCatalog, error) {
+	if providerModel == nil || providerModel.ModelCatalogID == nil {
+		return nil, nil
+	}
+	return s.modelCatalogService.FindByID(ctx, *providerModel.ModelCatalogID)
+}
+
+// ErrCodeProviderModelNotFound is returned when the provider has no model with the given public ID.
+const ErrCodeProviderModelNotFound = "6e3b9d1f-4a72-4c85-b0e6-2f8d5a1c7e94"
+
+// ErrCodeInvalidProviderModelUpdate is returned when a provider model update sets nothing or
+// carries an invalid price override.
+const ErrCodeInvalidProviderModelUpdate = "d3a8f6c1-5b92-4e07-a4d1-9c6e2b7f8a35"
+
+// UpdateProviderModelInput holds the admin-managed fields of a provider model; nil fields are left
+// as is.
+type UpdateProviderModelInput struct {
+	Active *bool
+	// PriceOverride replaces the synced pricing for cost estimation; an override without lines
+	// removes it.
+	PriceOverride *Pricing
+}
+
+// UpdateProviderModel applies an admin's changes to a single model of the provider. A model turned
+// off is excluded from routing and model listings and stays off across syncs; turning it back on
+// only makes it active while the provider itself is active and its model lists allow it. Syncs
+// never touch the price override.
+func (s *ProviderRegistryService) UpdateProviderModel(ctx context.Context, provider *Provider, modelPublicID string, input UpdateProviderModelInput) (*ProviderModel, *common.Error) {
+	if input.Active == nil && input.PriceOverride == nil {
+		return nil, common.NewErrorWithMessage("active or price_override is required", ErrCodeInvalidProviderModelUpdate)
+	}
+	var priceOverride *Pricing
+	if input.PriceOverride != nil && len(input.PriceOverride.Lines) > 0 {
+		override, err := sanitizePriceOverride(*input.PriceOverride)
+		if err != nil {
+			return nil, err
+		}
+		priceOverride = &override
+	}
+
+	pm, err := s.providerModelService.FindByProviderIDAndPublicID(ctx, provider.ID, strings.TrimSpace(modelPublicID))
+	if err != nil {
+		return nil, common.NewError(err, "b2f7c4e9-1d6a-4e38-9a05-c8e3f1b6d742")
+	}
+	if pm == nil {
+		return nil, common.NewErrorWithMessage("provider model not found", ErrCodeProviderModelNotFound)
+	}
+	if input.Active != nil {
+		pm.Disabled = !*input.Active
+		pm.Active = *input.Active && provider.Active && provider.AllowsModel(pm.ModelKey)
+	}
+	if input.PriceOverride != nil {
+		pm.PriceOverride = priceOverride
+	}
+	pm.UpdatedAt = time.Now().UTC()
+	if err := s.providerModelService.Update(ctx, pm); err != nil {
+		return nil, common.NewError(err, "4a9d2e6c-7f1b-4b53-8e0a-d5c1f7b3e926")
+	}
+	s.invalidateAccessibleProviderModels(ctx)
+	return pm, nil
+}
+
+// sanitizePriceOverride rejects negative amounts and currencies other than USD, which is the only
+// one EstimateCost supports, and fills in the currency when it is omitted.
+func sanitizePriceOverride(pricing Pricing) (Pricing, *common.Error) {
+	lines := make([]PriceLine, 0, len(pricing.Lines))
+	for _, line := range pricing.Lines {
+		if line.Amount < 0 {
+			return Pricing{}, common.NewErrorWithMessage("price_override amounts must not be negative", ErrCodeInvalidProviderModelUpdate)
+		}
+		if line.Currency == "" {
+			line.Currency = "USD"
+		}
+		if !strings.EqualFold(line.Currency, "USD") {
+			return Pricing{}, common.NewErrorWithMessage(fmt.Sprintf("unsupported price_override currency %q", line.Currency), ErrCodeInvalidProviderModelUpdate)
+		}
+		lines = append(lines, line)
+	}
+	return Pricing{Lines: lines}, nil
+}
+
+// ListProviders lists providers across all organizations and projects. It is meant for platform
+// admins only; organization-facing listings go through ListAccessibleProviders.
+func (s *ProviderRegistryService) ListProviders(ctx context.Context, filter ProviderFilter, pagination *query.Pagination) ([]*Provider, *common.Error) {
+	providers, err := s.providerRepo.FindByFilter(ctx, filter, pagination)
+	if err != nil {
+		return nil, common.NewError(err, "5b8e2d47-a1c3-4f96-8e0d-7c4a9b3f61e2")
+	}
+	return providers, nil
+}
+
+// CountProviders counts the providers matching the filter across all organizations.
+func (s *ProviderRegistryService) CountProviders(ctx context.Context, filter ProviderFilter) (int64, *common.Error) {
+	count, err := s.providerRepo.Count(ctx, filter)
+	if err != nil {
+		return 0, common.NewError(err, "e3a96c1f-7d52-4b08-b4e7-2f9d6a0c83b5")
+	}
+	return count, nil
+}
+
+// CountProvidersWithAPIKey counts the providers that store an encrypted API key.
+func (s *ProviderRegistryService) CountProvidersWithAPIKey(ctx context.Context) (int64, error) {
+	return s.providerRepo.Count(ctx, ProviderFilter{HasAPIKey: ptr.ToBool(true)})
+}
+
+func (s *ProviderRegistryService) CountProviderModels(ctx context.Context, providerID uint) (int64, error) {
+	return s.providerModelService.CountByProviderID(ctx, providerID)
+}
+
 func (s *ProviderRegistryService) ListProviderModels(ctx context.Context, providerIDs []uint) ([]*ProviderModel, error) {
 	return s.providerModelService.ListActiveByProviderIDs(ctx, providerIDs)
 }
 
 func (s *ProviderRegistryService) SyncProviderModels(ctx context.Context, provider *Provider, models []chatclient.Model) ([]ProviderModelSyncResult, *common.Error) {
+	models = ProbeProviderCapabilities(provider.Kind, models)
+	previousModelKeys := s.activeModelKeysForWebhook(ctx, provider)
+
+	var results []ProviderModelSyncResult
+	var syncErr *common.Error
+	if len(models) > providerModelBatchThreshold {
+		results, syncErr = s.syncProviderModelsBatched(ctx, provider, models)
+	} else {
+		results, syncErr = s.syncProviderModelsPerRow(ctx, provider, models)
+	}
+	if syncErr != nil {
+		return nil, syncErr
+	}
+
+	// Models that disappeared upstream must stop being routed to. An empty listing is more likely
+	// an upstream hiccup than a provider dropping every model, so the previous models are kept.
+	modelKeys := make([]string, 0, len(models))
+	for _, model := range models {
+		if strings.TrimSpace(model.ID) != "" {
+			modelKeys = append(modelKeys, model.ID)
+		}
+	}
+	if len(modelKeys) == 0 {
+		logger.GetLogger().Warnf("provider %s listed no models, keeping its previously synced models", provider.Slug)
+	} else if _, err := s.providerModelService.DeactivateMissing(ctx, provider.ID, modelKeys); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	provider.LastSyncedAt = &now
+	if err := s.providerRepo.Update(ctx, provider); err != nil {
+		return nil, common.NewError(err, "7fce47f4-67dd-47a3-93d6-3569b9d6d4f3")
+	}
+	s.invalidateAccessibleProviderModels(ctx)
+	if len(modelKeys) > 0 {
+		s.notifyProviderSync(ctx, provider, previousModelKeys, results)
+	}
+
+	return results, nil
+}
+
+// RefreshProviderModels fetches the current model list from the given provider and syncs it into
+// the registry, so each registered provider's models can be refreshed independently.
+func (s *ProviderRegistryService) RefreshProviderModels(ctx context.Context, provider *Provider) ([]ProviderModelSyncResult, *common.Error) {
+	models, err := s.modelLister.ListModels(ctx, provider)
+	if err != nil {
+		return nil, common.NewError(err, ErrCodeProviderModelFetchFailed)
+	}
+	return s.SyncProviderModels(ctx, provider, models)
+}
+
+func (s *ProviderRegistryService) syncProviderModelsPerRow(ctx context.Context, provider *Provider, models []chatclient.Model) ([]ProviderModelSyncResult, *common.Error) {
 	results := make([]ProviderModelSyncResult, 0, len(models))
 	for _, model := range models {
 		catalog, err := s.modelCatalogService.UpsertCatalog(ctx, provider.Kind, model)
@@ -364,86 +1179,483 @@ func (s *ProviderRegistryService) SyncProviderModels(ctx context.Context, provid
 			return nil, err
 		}
 		results = append(results, ProviderModelSyncResult{
+			ModelKey:      model.ID,
 			ProviderModel: providerModel,
 			Catalog:       catalog,
 		})
 	}
+	return results, nil
+}
 
-	now := time.Now().UTC()
-	provider.LastSyncedAt = &now
-	if err := s.providerRepo.Update(ctx, provider); err != nil {
-		return nil, common.NewError(err, "7fce47f4-67dd-47a3-93d6-3569b9d6d4f3")
+// syncProviderModelsBatched writes catalogs and provider models in grouped upserts so that
+// providers exposing hundreds of models do not cost one round-trip per model. A failed batch does not tell which model
+// broke it, so its models are then written one at a time and failures are reported per model
+// rather than failing the whole sync.
+func (s *ProviderRegistryService) syncProviderModelsBatched(ctx context.Context, provider *Provider, models []chatclient.Model) ([]ProviderModelSyncResult, *common.Error) {
+	results := make([]ProviderModelSyncResult, 0, len(models))
+	valid := make([]chatclient.Model, 0, len(models))
+	for i, model := range models {
+		if strings.TrimSpace(model.ID) == "" {
+			results = append(results, ProviderModelSyncResult{
+				Error: common.NewErrorWithMessage(fmt.Sprintf("model identifier missing at index %d", i), "1c5c6609-6df1-41b0-8fd9-2fa337eb0050"),
+			})
+			continue
+		}
+		valid = append(valid, model)
 	}
 
+	var providerModels []*ProviderModel
+	var providerModelCatalogs []*ModelCatalog
+	batchErr := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		catalogs, err := s.modelCatalogService.BatchUpsertCatalogs(ctx, provider.Kind, valid)
+		if err != nil {
+			return err
+		}
+		providerModels, providerModelCatalogs, err = s.providerModelService.BatchUpsertProviderModels(ctx, provider, catalogs, valid)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+	if batchErr != nil {
+		logger.GetLogger().Warnf("batched sync of provider %s failed, syncing its models one at a time: %v", provider.Slug, batchErr)
+		for _, model := range valid {
+			results = append(results, s.syncProviderModel(ctx, provider, model))
+		}
+		return results, nil
+	}
+	for i, providerModel := range providerModels {
+		results = append(results, ProviderModelSyncResult{
+			ModelKey:      providerModel.ModelKey,
+			ProviderModel: providerModel,
+			Catalog:       providerModelCatalogs[i],
+		})
+	}
 	return results, nil
 }
 
+// syncProviderModel writes a single model in its own transaction, so a failing model leaves the
+// others intact, and reports the failure on the result.
+func (s *ProviderRegistryService) syncProviderModel(ctx context.Context, provider *Provider, model chatclient.Model) ProviderModelSyncResult {
+	result := ProviderModelSyncResult{ModelKey: model.ID}
+	var cErr *common.Error
+	err := s.transactor.Transaction(ctx, func(ctx context.Context) error {
+		catalog, err := s.modelCatalogService.UpsertCatalog(ctx, provider.Kind, model)
+		if err != nil {
+			cErr = err
+			return err
+		}
+		providerModel, err := s.providerModelService.UpsertProviderModel(ctx, provider, catalog, model)
+		if err != nil {
+			cErr = err
+			return err
+		}
+		result.ProviderModel = providerModel
+		result.Catalog = catalog
+		return nil
+	})
+	if cErr == nil && err != nil {
+		cErr = common.NewError(err, "8d4b2f6a-1c93-4e57-a0d8-f3b6c9e2a715")
+	}
+	if cErr != nil {
+		return ProviderModelSyncResult{ModelKey: model.ID, Error: cErr}
+	}
+	return result
+}
+
 func (s *ProviderRegistryService) GetProviderForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) (*Provider, error) {
-	if strings.TrimSpace(modelKey) == "" {
-		return nil, errors.New("model key is required")
+	providers, err := s.GetProvidersForModel(ctx, modelKey, organizationID, projectIDs)
+	if err != nil {
+		return nil, err
 	}
+	return providers[0], nil
+}
+
+// ResolveModelAlias returns the organization's alias named modelKey, or nil when modelKey is not an alias.
+func (s *ProviderRegistryService) ResolveModelAlias(ctx context.Context, modelKey string, organizationID uint) (*ModelAlias, error) {
+	if s.modelAliasService == nil {
+		return nil, nil
+	}
+	return s.modelAliasService.Resolve(ctx, organizationID, modelKey)
+}
 
-	providers, err := s.ListAccessibleProviders(ctx, organizationID, projectIDs)
+// GetProvidersForModel returns every accessible provider that serves the model, ordered
+// project → organization → global, so callers can fall back along the chain. Providers
+// with an open circuit and shadow providers, which only receive replayed traffic, are left out.
+// An organization model alias pins the chain to the aliased provider. When providers of the
+// leading scope carry a routing_weight, the first of them is chosen by weighted random selection.
+func (s *ProviderRegistryService) GetProvidersForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) ([]*Provider, error) {
+	chain, _, err := s.providerChainForModel(ctx, modelKey, organizationID, projectIDs)
 	if err != nil {
 		return nil, err
 	}
+	return weightedChain(chain, s.routingRandom), nil
+}
+
+// providerChainForModel builds the chain GetProvidersForModel samples from, before any weighted
+// reordering, together with every accessible provider as a candidate for tracing.
+func (s *ProviderRegistryService) providerChainForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) ([]*Provider, []ProviderCandidate, error) {
+	if strings.TrimSpace(modelKey) == "" {
+		return nil, nil, errors.New("model key is required")
+	}
+
+	accessible, err := s.loadAccessibleProviderModels(ctx, organizationID, projectIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	providers := accessible.Providers
 
 	if len(providers) == 0 {
-		return nil, errors.New("no accessible providers found")
+		return nil, nil, &ProviderResolutionError{Reason: FallbackReasonNoAccessibleProviders, message: "no accessible providers found"}
 	}
 
-	providerIDs := make([]uint, 0, len(providers))
+	alias, err := s.ResolveModelAlias(ctx, modelKey, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	hasModel := func(provider *Provider) bool {
+		if alias != nil {
+			return provider.ID == alias.ProviderID
+		}
+		return accessible.serves(provider.ID, modelKey)
+	}
+
+	candidates := make([]ProviderCandidate, 0, len(providers))
+	chain := make([]*Provider, 0, len(providers))
+	var unavailable []*Provider
+	served := false
 	for _, provider := range providers {
 		if provider == nil {
 			continue
 		}
-		providerIDs = append(providerIDs, provider.ID)
+		candidate := ProviderCandidate{
+			Provider:    provider,
+			HasModel:    hasModel(provider),
+			CircuitOpen: !s.isProviderAvailable(provider),
+		}
+		candidates = append(candidates, candidate)
+		if !candidate.HasModel {
+			continue
+		}
+		served = true
+		if provider.Shadow {
+			continue
+		}
+		if candidate.CircuitOpen {
+			unavailable = append(unavailable, provider)
+		} else {
+			chain = append(chain, provider)
+		}
+	}
+	if alias != nil && len(chain) == 0 {
+		return nil, candidates, &ProviderResolutionError{
+			Reason:    FallbackReasonProviderUnavailable,
+			Providers: unavailable,
+			message:   fmt.Sprintf("provider for model alias '%s' is not available", modelKey),
+		}
+	}
+	if !served {
+		return nil, candidates, &ProviderResolutionError{Reason: FallbackReasonModelNotServed, message: fmt.Sprintf("model '%s' not found in accessible providers", modelKey)}
 	}
 
-	if len(providerIDs) == 0 {
-		return nil, errors.New("no accessible providers found")
+	if len(chain) == 0 {
+		return nil, candidates, &ProviderResolutionError{
+			Reason:    FallbackReasonProviderUnavailable,
+			Providers: unavailable,
+			message:   fmt.Sprintf("no valid provider found for model '%s'", modelKey),
+		}
 	}
+	return chain, candidates, nil
+}
+
+// ErrCodeProviderOverrideRejected is returned when a forced provider is unknown, not accessible, a
+// shadow provider or does not serve the model.
+const ErrCodeProviderOverrideRejected = "f1c6a9e3-8b2d-4e57-9a04-d7e3b5c8f216"
 
-	providerModels, err := s.providerModelService.FindActiveByProviderIDsAndKey(ctx, providerIDs, modelKey)
+// GetProviderOverrideForModel returns the accessible provider with the given public ID for a
+// caller forcing one, bypassing the project → organization → global ordering, weights and
+// circuit breakers. The provider must serve the model, or the aliased model key when modelKey is
+// an alias. Shadow providers never serve clients, so they cannot be forced either.
+func (s *ProviderRegistryService) GetProviderOverrideForModel(ctx context.Context, providerPublicID string, modelKey string, organizationID uint, projectIDs []uint) (*Provider, *common.Error) {
+	accessible, err := s.loadAccessibleProviderModels(ctx, organizationID, projectIDs)
 	if err != nil {
-		return nil, err
+		return nil, common.NewError(err, "0e4b8d2a-6f17-4c93-b5a1-c8d2e6f9a347")
+	}
+	var provider *Provider
+	for _, candidate := range accessible.Providers {
+		if candidate != nil && candidate.PublicID == providerPublicID {
+			provider = candidate
+			break
+		}
+	}
+	if provider == nil {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("provider '%s' not found or not accessible", providerPublicID), ErrCodeProviderOverrideRejected)
 	}
-	if len(providerModels) == 0 {
-		return nil, fmt.Errorf("model '%s' not found in accessible providers", modelKey)
+	if provider.Shadow {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("provider '%s' is a shadow provider and does not serve clients", providerPublicID), ErrCodeProviderOverrideRejected)
 	}
 
-	hasModel := make(map[uint]struct{}, len(providerModels))
-	for _, pm := range providerModels {
-		hasModel[pm.ProviderID] = struct{}{}
+	alias, err := s.ResolveModelAlias(ctx, modelKey, organizationID)
+	if err != nil {
+		return nil, common.NewError(err, "7b3f1e9c-2a56-4d08-8e4b-a9c1f5d7e362")
+	}
+	if alias != nil {
+		modelKey = alias.ModelKey
 	}
+	if !accessible.serves(provider.ID, modelKey) {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("provider '%s' does not serve model '%s'", providerPublicID, modelKey), ErrCodeProviderOverrideRejected)
+	}
+	return provider, nil
+}
 
+// GetShadowProvidersForModel returns the accessible shadow providers that serve the model.
+func (s *ProviderRegistryService) GetShadowProvidersForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) ([]*Provider, error) {
+	providers, err := s.ListAccessibleProvidersByFilter(ctx, organizationID, projectIDs, ProviderFilter{Active: ptr.ToBool(true)})
+	if err != nil {
+		return nil, err
+	}
+	shadowIDs := make([]uint, 0)
+	shadows := make(map[uint]*Provider)
 	for _, provider := range providers {
-		if provider == nil {
+		if provider == nil || !provider.Shadow || !provider.AllowsModel(modelKey) {
 			continue
 		}
-		if _, ok := hasModel[provider.ID]; ok {
-			return provider, nil
+		shadowIDs = append(shadowIDs, provider.ID)
+		shadows[provider.ID] = provider
+	}
+	if len(shadowIDs) == 0 {
+		return nil, nil
+	}
+	providerModels, err := s.providerModelService.FindActiveByProviderIDsAndKey(ctx, shadowIDs, modelKey)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Provider, 0, len(providerModels))
+	for _, pm := range providerModels {
+		if provider, ok := shadows[pm.ProviderID]; ok {
+			result = append(result, provider)
+			delete(shadows, pm.ProviderID)
+		}
+	}
+	return result, nil
+}
+
+// ProviderResolution describes how GetProviderForModelOrDefault picked its provider.
+type ProviderResolution string
+
+const (
+	// ProviderResolutionMatched means an accessible provider advertises the requested model.
+	ProviderResolutionMatched ProviderResolution = "matched"
+	// ProviderResolutionOrganizationDefault means no provider advertises the model and the organization's default provider was used.
+	ProviderResolutionOrganizationDefault ProviderResolution = "organization_default"
+	// ProviderResolutionJan means no provider advertises the model and the registered Jan provider was used.
+	ProviderResolutionJan ProviderResolution = "jan"
+	// ProviderResolutionDefault means no Jan provider is registered and one was built from JAN_INFERENCE_MODEL_URL.
+	ProviderResolutionDefault ProviderResolution = "default"
+	// ProviderResolutionNone means no provider serves the model and no fallback is configured. It is
+	// only reported by TraceProviderForModel; routing fails instead.
+	ProviderResolutionNone ProviderResolution = "none"
+)
+
+// GetProviderForModelOrDefault resolves the provider for a model, falling back to the organization's
+// default provider, or the Jan provider when none is set, when no accessible provider advertises
+// it. The returned resolution tells callers which path was taken.
+func (s *ProviderRegistryService) GetProviderForModelOrDefault(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) (*Provider, ProviderResolution, error) {
+	provider, resolution, _, err := s.resolveProviderForModel(ctx, modelKey, organizationID, projectIDs)
+	return provider, resolution, err
+}
+
+// ProviderSelection is the outcome of SelectProviderForModel. FallbackReason is empty when the
+// resolution is ProviderResolutionMatched.
+type ProviderSelection struct {
+	Provider       *Provider
+	Resolution     ProviderResolution
+	FallbackReason FallbackReason
+}
+
+// SelectProviderForModel resolves the provider for a model like GetProviderForModelOrDefault and,
+// when it falls back, reports why and records a fallback failure for each provider that serves the
+// model but was unavailable.
+func (s *ProviderRegistryService) SelectProviderForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) (*ProviderSelection, error) {
+	provider, resolution, resolveErr, err := s.resolveProviderForModel(ctx, modelKey, organizationID, projectIDs)
+	if err != nil {
+		return nil, err
+	}
+	selection := &ProviderSelection{Provider: provider, Resolution: resolution}
+	if resolveErr != nil {
+		selection.FallbackReason = FallbackReasonOf(resolveErr)
+		s.recordFallbackFailures(ctx, resolveErr)
+	}
+	return selection, nil
+}
+
+// SelectProvidersForModel returns the provider chain for a model like GetProvidersForModel and,
+// when no accessible provider can serve it, falls back to a single default provider the way
+// SelectProviderForModel does. The reason is empty unless it fell back.
+func (s *ProviderRegistryService) SelectProvidersForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) ([]*Provider, FallbackReason, error) {
+	providers, resolveErr := s.GetProvidersForModel(ctx, modelKey, organizationID, projectIDs)
+	if resolveErr == nil {
+		return providers, "", nil
+	}
+	provider, _, resolveErr, err := s.fallbackProvider(ctx, organizationID, resolveErr)
+	if err != nil {
+		return nil, "", err
+	}
+	s.recordFallbackFailures(ctx, resolveErr)
+	return []*Provider{provider}, FallbackReasonOf(resolveErr), nil
+}
+
+// resolveProviderForModel implements GetProviderForModelOrDefault. When it falls back it also
+// returns the error that prevented a matched provider from being used.
+func (s *ProviderRegistryService) resolveProviderForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) (*Provider, ProviderResolution, error, error) {
+	provider, resolveErr := s.GetProviderForModel(ctx, modelKey, organizationID, projectIDs)
+	if resolveErr == nil {
+		return provider, ProviderResolutionMatched, nil, nil
+	}
+	return s.fallbackProvider(ctx, organizationID, resolveErr)
+}
+
+// fallbackProvider returns the provider used when resolveErr kept every accessible provider from
+// serving the model: the organization's default provider, then the Jan provider.
+func (s *ProviderRegistryService) fallbackProvider(ctx context.Context, organizationID uint, resolveErr error) (*Provider, ProviderResolution, error, error) {
+	if orgDefault := s.organizationDefaultProvider(ctx, organizationID); orgDefault != nil {
+		return orgDefault, ProviderResolutionOrganizationDefault, resolveErr, nil
+	}
+
+	janProvider, err := s.FindJanProvider(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if janProvider != nil {
+		return janProvider, ProviderResolutionJan, resolveErr, nil
+	}
+
+	if fallback := defaultJanProvider(); fallback != nil {
+		return fallback, ProviderResolutionDefault, resolveErr, nil
+	}
+	return nil, "", nil, resolveErr
+}
+
+// ProviderCandidate is one accessible provider considered while resolving a model.
+type ProviderCandidate struct {
+	Provider    *Provider
+	HasModel    bool
+	CircuitOpen bool
+}
+
+// WeightedProvider is a provider the first pick is sampled from, with its routing weight.
+type WeightedProvider struct {
+	Provider *Provider
+	Weight   int
+}
+
+// ProviderResolutionTrace records the steps GetProviderForModelOrDefault would take for a model.
+// When the first provider is picked by weighted random selection, Chosen is nil and Weighted holds
+// the providers the pick is sampled from.
+type ProviderResolutionTrace struct {
+	Candidates []ProviderCandidate
+	Chosen     *Provider
+	Weighted   []WeightedProvider
+	Resolution ProviderResolution
+}
+
+// TraceProviderForModel runs the same resolution as GetProviderForModelOrDefault without calling
+// any upstream, returning the ordered candidates alongside the provider that would be chosen. When
+// nothing would serve the model the trace has no chosen provider and ProviderResolutionNone.
+func (s *ProviderRegistryService) TraceProviderForModel(ctx context.Context, modelKey string, organizationID uint, projectIDs []uint) (*ProviderResolutionTrace, error) {
+	chain, candidates, resolveErr := s.providerChainForModel(ctx, modelKey, organizationID, projectIDs)
+	trace := &ProviderResolutionTrace{Candidates: candidates}
+	if resolveErr == nil {
+		trace.Resolution = ProviderResolutionMatched
+		weighted, weights, _ := weightedTier(chain)
+		switch len(weighted) {
+		case 0:
+			trace.Chosen = chain[0]
+		case 1:
+			trace.Chosen = weighted[0]
+		default:
+			for i, provider := range weighted {
+				trace.Weighted = append(trace.Weighted, WeightedProvider{Provider: provider, Weight: weights[i]})
+			}
 		}
+		return trace, nil
 	}
 
-	return nil, fmt.Errorf("no valid provider found for model '%s'", modelKey)
+	chosen, resolution, _, err := s.fallbackProvider(ctx, organizationID, resolveErr)
+	if err != nil {
+		var resolutionErr *ProviderResolutionError
+		if errors.As(err, &resolutionErr) {
+			trace.Resolution = ProviderResolutionNone
+			return trace, nil
+		}
+		return nil, err
+	}
+	trace.Chosen = chosen
+	trace.Resolution = resolution
+	return trace, nil
 }
 
-func sanitizeMetadata(metadata map[string]string) map[string]string {
-	if len(metadata) == 0 {
+// defaultJanProvider builds an unpersisted Jan provider from the environment configuration.
+func defaultJanProvider() *Provider {
+	baseURL := strings.TrimSpace(environment_variables.EnvironmentVariables.JAN_INFERENCE_MODEL_URL)
+	if baseURL == "" {
 		return nil
 	}
-	result := make(map[string]string, len(metadata))
-	for key, value := range metadata {
+	return &Provider{
+		Slug:        "jan-default",
+		DisplayName: "Jan",
+		Kind:        ProviderJan,
+		BaseURL:     normalizeURL(baseURL),
+		Active:      true,
+	}
+}
+
+// reservedProviderHeaders are managed by the HTTP client and cannot be overridden per provider.
+var reservedProviderHeaders = map[string]struct{}{
+	"Host":              {},
+	"Content-Length":    {},
+	"Content-Type":      {},
+	"Transfer-Encoding": {},
+	"Connection":        {},
+}
+
+// sanitizeHeaders trims and canonicalizes custom provider headers, dropping empty entries. Reserved
+// headers are rejected, as is Authorization when the provider also has an API key.
+func sanitizeHeaders(headers map[string]string, hasAPIKey bool) (map[string]string, *common.Error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string, len(headers))
+	for key, value := range headers {
 		k := strings.TrimSpace(key)
 		v := strings.TrimSpace(value)
 		if k == "" || v == "" {
 			continue
 		}
+		if !validHeaderName(k) {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("header %q is not a valid header name", k), ErrCodeInvalidProviderHeader)
+		}
+		k = http.CanonicalHeaderKey(k)
+		if _, reserved := reservedProviderHeaders[k]; reserved {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("header %q cannot be set on a provider", k), ErrCodeInvalidProviderHeader)
+		}
+		if k == "Authorization" && hasAPIKey {
+			return nil, common.NewErrorWithMessage("Authorization header cannot be set together with api_key", ErrCodeInvalidProviderHeader)
+		}
 		result[k] = v
 	}
 	if len(result) == 0 {
-		return nil
+		return nil, nil
 	}
-	return result
+	return result, nil
+}
+
+// validHeaderName reports whether name is an RFC 7230 token.
+func validHeaderName(name string) bool {
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r) {
+			return false
+		}
+	}
+	return true
 }
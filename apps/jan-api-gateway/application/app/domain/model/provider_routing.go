@@ -0,0 +1,150 @@
+package model
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+// RoutingWeightMetadataKey is the provider metadata key holding the provider's share of traffic
+// among providers of the same scope that serve the same model, e.g. 80 and 20 for an 80/20
+// split. Providers without a weight next to weighted peers only serve as fallbacks.
+const RoutingWeightMetadataKey = "routing_weight"
+
+// ErrCodeInvalidRoutingWeight is returned when routing_weight is not a non-negative integer.
+const ErrCodeInvalidRoutingWeight = "d4a8e2c6-7f19-4b35-a6e0-1c9b3f7d5e28"
+
+// routingWeight returns the provider's routing weight and whether one is configured.
+func routingWeight(provider *Provider) (int, bool) {
+	if provider == nil {
+		return 0, false
+	}
+	value, ok := provider.Metadata[RoutingWeightMetadataKey]
+	if !ok {
+		return 0, false
+	}
+	weight, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || weight < 0 {
+		return 0, false
+	}
+	return weight, true
+}
+
+func validRoutingWeight(value string) bool {
+	weight, err := strconv.Atoi(value)
+	return err == nil && weight >= 0
+}
+
+// providerScopeRank orders scopes the way the fallback chain does: project, organization, global.
+// Global providers are stored under the default organization, so they rank as global as well.
+func providerScopeRank(provider *Provider) int {
+	switch {
+	case provider.ProjectID != nil:
+		return 2
+	case provider.OrganizationID == nil:
+		return 0
+	case organization.DEFAULT_ORGANIZATION != nil && *provider.OrganizationID == organization.DEFAULT_ORGANIZATION.ID:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// ProviderScope names the scope a provider is accessible from.
+type ProviderScope string
+
+const (
+	ProviderScopeProject      ProviderScope = "project"
+	ProviderScopeOrganization ProviderScope = "organization"
+	ProviderScopeGlobal       ProviderScope = "global"
+	// ProviderScopeJan is the global Jan provider, which is reported apart from other global providers.
+	ProviderScopeJan ProviderScope = "jan"
+)
+
+// Scope reports the scope of the provider, ranked the same way as providerScopeRank.
+func (p *Provider) Scope() ProviderScope {
+	switch providerScopeRank(p) {
+	case 2:
+		return ProviderScopeProject
+	case 1:
+		return ProviderScopeOrganization
+	}
+	if p.Kind == ProviderJan {
+		return ProviderScopeJan
+	}
+	return ProviderScopeGlobal
+}
+
+// weightedTier returns the providers of the chain's leading scope that carry a positive routing
+// weight, with their weights, along with the size of that scope. Nothing is returned when the
+// leading scope holds a single provider, since there is nothing to choose between.
+func weightedTier(chain []*Provider) ([]*Provider, []int, int) {
+	if len(chain) < 2 {
+		return nil, nil, len(chain)
+	}
+	rank := providerScopeRank(chain[0])
+	tierSize := 1
+	for tierSize < len(chain) && providerScopeRank(chain[tierSize]) == rank {
+		tierSize++
+	}
+	if tierSize < 2 {
+		return nil, nil, tierSize
+	}
+	weighted := make([]*Provider, 0, tierSize)
+	weights := make([]int, 0, tierSize)
+	for _, provider := range chain[:tierSize] {
+		if weight, ok := routingWeight(provider); ok && weight > 0 {
+			weighted = append(weighted, provider)
+			weights = append(weights, weight)
+		}
+	}
+	return weighted, weights, tierSize
+}
+
+// weightedChain reorders the leading providers of the chain that share the first provider's
+// scope by weighted random sampling without replacement, so the first provider is picked in
+// proportion to its weight and the rest stay available as fallbacks. random returns values in
+// [0, 1) and is injected so the selection can be reproduced with a seeded source. Chains without
+// weighted providers in the leading scope are returned unchanged.
+func weightedChain(chain []*Provider, random func() float64) []*Provider {
+	if random == nil {
+		return chain
+	}
+	weighted, weights, tierSize := weightedTier(chain)
+	if len(weighted) == 0 {
+		return chain
+	}
+	weighted = append([]*Provider(nil), weighted...)
+	weights = append([]int(nil), weights...)
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+	unweighted := make([]*Provider, 0, tierSize-len(weighted))
+	for _, provider := range chain[:tierSize] {
+		if !slices.Contains(weighted, provider) {
+			unweighted = append(unweighted, provider)
+		}
+	}
+
+	result := make([]*Provider, 0, len(chain))
+	for len(weighted) > 0 {
+		target := random() * float64(total)
+		picked := len(weighted) - 1
+		for i, weight := range weights {
+			if target < float64(weight) {
+				picked = i
+				break
+			}
+			target -= float64(weight)
+		}
+		result = append(result, weighted[picked])
+		total -= weights[picked]
+		weighted = append(weighted[:picked], weighted[picked+1:]...)
+		weights = append(weights[:picked], weights[picked+1:]...)
+	}
+	result = append(result, unweighted...)
+	return append(result, chain[tierSize:]...)
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestWeightedChain(t *testing.T) {
+	previous := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previous }()
+
+	orgID := uint(5)
+	defaultOrgID := uint(1)
+	projectID := uint(9)
+	provider := func(slug string, org *uint, project *uint, weight string) *Provider {
+		p := &Provider{Slug: slug, OrganizationID: org, ProjectID: project}
+		if weight != "" {
+			p.Metadata = map[string]string{RoutingWeightMetadataKey: weight}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name   string
+		chain  []*Provider
+		random []float64
+		want   []string
+	}{
+		{
+			name:  "single provider",
+			chain: []*Provider{provider("a", &orgID, nil, "10")},
+			want:  []string{"a"},
+		},
+		{
+			name:   "no weights keeps priority order",
+			chain:  []*Provider{provider("a", &orgID, nil, ""), provider("b", &orgID, nil, "")},
+			random: []float64{0.9},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "low draw picks the first weighted provider",
+			chain:  []*Provider{provider("a", &orgID, nil, "80"), provider("b", &orgID, nil, "20")},
+			random: []float64{0.1, 0.5},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "high draw picks the second weighted provider",
+			chain:  []*Provider{provider("a", &orgID, nil, "80"), provider("b", &orgID, nil, "20")},
+			random: []float64{0.9, 0.5},
+			want:   []string{"b", "a"},
+		},
+		{
+			name: "unweighted and zero weight providers are fallbacks",
+			chain: []*Provider{
+				provider("a", &orgID, nil, ""),
+				provider("b", &orgID, nil, "0"),
+				provider("c", &orgID, nil, "5"),
+			},
+			random: []float64{0.3},
+			want:   []string{"c", "a", "b"},
+		},
+		{
+			name: "only the leading scope is reordered",
+			chain: []*Provider{
+				provider("project", &orgID, &projectID, "1"),
+				provider("a", &orgID, nil, "1"),
+				provider("b", &orgID, nil, "99"),
+			},
+			random: []float64{0.99},
+			want:   []string{"project", "a", "b"},
+		},
+		{
+			name: "global providers of the default organization are a separate scope",
+			chain: []*Provider{
+				provider("org", &orgID, nil, "50"),
+				provider("global", &defaultOrgID, nil, "50"),
+			},
+			random: []float64{0.99},
+			want:   []string{"org", "global"},
+		},
+		{
+			name: "global providers are weighted among themselves",
+			chain: []*Provider{
+				provider("global-a", &defaultOrgID, nil, "50"),
+				provider("global-b", nil, nil, "50"),
+			},
+			random: []float64{0.99, 0.5},
+			want:   []string{"global-b", "global-a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draws := tt.random
+			random := func() float64 {
+				if len(draws) == 0 {
+					t.Fatal("random called more often than expected")
+				}
+				value := draws[0]
+				draws = draws[1:]
+				return value
+			}
+			got := weightedChain(tt.chain, random)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d providers, want %d", len(got), len(tt.want))
+			}
+			for i, p := range got {
+				if p.Slug != tt.want[i] {
+					t.Fatalf("position %d = %s, want %s", i, p.Slug, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProviderScope(t *testing.T) {
+	previous := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previous }()
+
+	defaultOrgID := uint(1)
+	orgID := uint(5)
+	projectID := uint(9)
+	tests := []struct {
+		name     string
+		provider *Provider
+		want     ProviderScope
+	}{
+		{name: "project", provider: &Provider{OrganizationID: &orgID, ProjectID: &projectID}, want: ProviderScopeProject},
+		{name: "organization", provider: &Provider{OrganizationID: &orgID}, want: ProviderScopeOrganization},
+		{name: "default organization", provider: &Provider{OrganizationID: &defaultOrgID}, want: ProviderScopeGlobal},
+		{name: "without organization", provider: &Provider{}, want: ProviderScopeGlobal},
+		{name: "jan", provider: &Provider{Kind: ProviderJan, OrganizationID: &defaultOrgID}, want: ProviderScopeJan},
+		{name: "organization jan", provider: &Provider{Kind: ProviderJan, OrganizationID: &orgID}, want: ProviderScopeOrganization},
+	}
+	for _, tt := range tests {
+		if got := tt.provider.Scope(); got != tt.want {
+			t.Errorf("%s: Scope() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
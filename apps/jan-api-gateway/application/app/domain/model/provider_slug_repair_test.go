@@ -0,0 +1,57 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+)
+
+func TestRepairSlugs(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+
+	registry := modeltest.NewRegistry()
+	first := &domainmodel.Provider{PublicID: "prov-first", Slug: "gateway", DisplayName: "Gateway", Kind: domainmodel.ProviderCustom, OrganizationID: &orgID, Active: true}
+	second := &domainmodel.Provider{PublicID: "prov-second", Slug: "gateway", DisplayName: "Gateway", Kind: domainmodel.ProviderCustom, OrganizationID: &orgID, Active: true}
+	unique := &domainmodel.Provider{PublicID: "prov-unique", Slug: "openai", DisplayName: "OpenAI", Kind: domainmodel.ProviderOpenAI, OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(first, second, unique)
+
+	results, err := registry.RepairSlugs(ctx, orgID)
+	if err != nil {
+		t.Fatalf("RepairSlugs: %v", err)
+	}
+	if len(results) != 1 || results[0].Provider.ID != second.ID || results[0].OldSlug != "gateway" {
+		t.Fatalf("repaired %+v, want only the later of the colliding providers", results)
+	}
+
+	slugs := map[string]string{}
+	for _, provider := range []*domainmodel.Provider{first, second, unique} {
+		stored, err := registry.Providers.FindByID(ctx, provider.ID)
+		if err != nil {
+			t.Fatalf("FindByID(%s): %v", provider.PublicID, err)
+		}
+		if holder, taken := slugs[stored.Slug]; taken {
+			t.Fatalf("%s and %s share the slug %q", holder, stored.PublicID, stored.Slug)
+		}
+		slugs[stored.Slug] = stored.PublicID
+	}
+	if slugs["gateway"] != first.PublicID || slugs["openai"] != unique.PublicID || slugs[results[0].NewSlug] != second.PublicID {
+		t.Fatalf("slugs = %v, want the earliest provider to keep gateway and the new slug on the later one", slugs)
+	}
+
+	again, err := registry.RepairSlugs(ctx, orgID)
+	if err != nil {
+		t.Fatalf("second RepairSlugs: %v", err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("second run repaired %+v, want no changes", again)
+	}
+	for slug, publicID := range slugs {
+		stored, _ := registry.Providers.FindByPublicID(ctx, publicID)
+		if stored.Slug != slug {
+			t.Fatalf("second run changed %s from %q to %q", publicID, slug, stored.Slug)
+		}
+	}
+}
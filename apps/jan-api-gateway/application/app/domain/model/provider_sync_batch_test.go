@@ -0,0 +1,190 @@
+package model_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+func upstreamModels(n int) []chatclient.Model {
+	models := make([]chatclient.Model, 0, n)
+	for i := range n {
+		models = append(models, chatclient.Model{
+			ID:          fmt.Sprintf("vendor/model-%03d", i),
+			Object:      "model",
+			DisplayName: fmt.Sprintf("Model %d", i),
+		})
+	}
+	return models
+}
+
+// storedModel is a stored provider model with its catalog referenced by public ID.
+type storedModel struct {
+	domainmodel.ProviderModel
+	CatalogPublicID string
+}
+
+// storedSync returns the stored provider models and catalogs keyed by model key and public ID,
+// with the generated IDs and timestamps cleared so two syncs can be compared.
+func storedSync(t *testing.T, registry *modeltest.Registry) (map[string]storedModel, map[string]domainmodel.ModelCatalog) {
+	t.Helper()
+	catalogs := map[string]domainmodel.ModelCatalog{}
+	catalogByID := map[uint]string{}
+	for _, catalog := range registry.Catalogs.All() {
+		catalogByID[catalog.ID] = catalog.PublicID
+		c := *catalog
+		c.ID, c.CreatedAt, c.UpdatedAt, c.LastSyncedAt = 0, time.Time{}, time.Time{}, nil
+		catalogs[c.PublicID] = c
+	}
+	models := map[string]storedModel{}
+	for _, model := range registry.Models.All() {
+		if model.ModelCatalogID == nil {
+			t.Fatalf("model %s has no catalog", model.ModelKey)
+		}
+		m := storedModel{ProviderModel: *model, CatalogPublicID: catalogByID[*model.ModelCatalogID]}
+		m.ID, m.PublicID, m.ModelCatalogID, m.CreatedAt, m.UpdatedAt = 0, "", nil, time.Time{}, time.Time{}
+		models[m.ModelKey] = m
+	}
+	return models, catalogs
+}
+
+func TestSyncProviderModelsBatched(t *testing.T) {
+	ctx := context.Background()
+	models := upstreamModels(200)
+
+	batched := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, Active: true}
+	batched.Providers.Add(provider)
+	results, err := batched.SyncProviderModels(ctx, provider, models)
+	if err != nil {
+		t.Fatalf("SyncProviderModels: %v", err)
+	}
+	if len(results) != len(models) {
+		t.Fatalf("got %d results, want %d", len(results), len(models))
+	}
+	for _, repo := range []interface{ Calls(string) int }{batched.Models, batched.Catalogs} {
+		if creates, updates := repo.Calls("Create"), repo.Calls("Update"); creates != 0 || updates != 0 {
+			t.Fatalf("batched sync made %d creates and %d updates, want none", creates, updates)
+		}
+	}
+	if want := []int{100, 100}; !slices.Equal(batched.Models.BatchSizes, want) {
+		t.Fatalf("provider model batches = %v, want %v", batched.Models.BatchSizes, want)
+	}
+	if want := []int{100, 100}; !slices.Equal(batched.Catalogs.BatchSizes, want) {
+		t.Fatalf("catalog batches = %v, want %v", batched.Catalogs.BatchSizes, want)
+	}
+
+	perRow := modeltest.NewRegistry()
+	perRowProvider := *provider
+	perRow.Providers.Add(&perRowProvider)
+	if _, err := perRow.SyncProviderModelsPerRow(ctx, &perRowProvider, models); err != nil {
+		t.Fatalf("SyncProviderModelsPerRow: %v", err)
+	}
+
+	batchedModels, batchedCatalogs := storedSync(t, batched)
+	perRowModels, perRowCatalogs := storedSync(t, perRow)
+	if len(batchedModels) != len(models) {
+		t.Fatalf("stored %d provider models, want %d", len(batchedModels), len(models))
+	}
+	if !reflect.DeepEqual(batchedModels, perRowModels) {
+		t.Fatal("batched sync stored different provider models than per-row writes")
+	}
+	if !reflect.DeepEqual(batchedCatalogs, perRowCatalogs) {
+		t.Fatal("batched sync stored different catalogs than per-row writes")
+	}
+
+	// A second sync updates the stored rows instead of adding new ones.
+	if _, err := batched.SyncProviderModels(ctx, provider, models); err != nil {
+		t.Fatalf("second SyncProviderModels: %v", err)
+	}
+	if got := len(batched.Models.All()); got != len(models) {
+		t.Fatalf("stored %d provider models after resync, want %d", got, len(models))
+	}
+}
+
+func TestSyncProviderModelsBatchedReportsFailuresPerModel(t *testing.T) {
+	ctx := context.Background()
+	models := upstreamModels(200)
+	models = append(models, chatclient.Model{ID: " ", Object: "model"})
+	failing := models[7].ID
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, Active: true}
+	registry.Providers.Add(provider)
+	registry.Models.FailingModelKeys[failing] = errors.New("value too long for column")
+
+	results, err := registry.SyncProviderModels(ctx, provider, models)
+	if err != nil {
+		t.Fatalf("SyncProviderModels: %v", err)
+	}
+	if len(results) != len(models) {
+		t.Fatalf("got %d results, want one per model (%d)", len(results), len(models))
+	}
+	failed := map[string]bool{}
+	for _, result := range results {
+		if result.Error == nil {
+			if result.ProviderModel == nil || result.ProviderModel.ModelKey != result.ModelKey {
+				t.Fatalf("result for %q has no matching provider model", result.ModelKey)
+			}
+			continue
+		}
+		if result.ProviderModel != nil {
+			t.Fatalf("failed result for %q carries a provider model", result.ModelKey)
+		}
+		failed[result.ModelKey] = true
+	}
+	if len(failed) != 2 || !failed[failing] || !failed[""] {
+		t.Fatalf("failed models = %v, want %s and the model without an identifier", failed, failing)
+	}
+
+	// The failing model's catalog is rolled back with it; every other model is stored
+	stored, catalogs := storedSync(t, registry)
+	if len(stored) != 199 || len(catalogs) != 199 {
+		t.Fatalf("stored %d provider models and %d catalogs, want 199 of each", len(stored), len(catalogs))
+	}
+	if _, ok := stored[failing]; ok {
+		t.Fatalf("the failing model %s was stored", failing)
+	}
+	if provider.LastSyncedAt == nil {
+		t.Fatal("a sync with per-model failures did not record the sync")
+	}
+}
+
+func BenchmarkSyncProviderModels(b *testing.B) {
+	ctx := context.Background()
+	models := upstreamModels(200)
+	sync := map[string]func(*modeltest.Registry, *domainmodel.Provider) error{
+		"batched": func(r *modeltest.Registry, p *domainmodel.Provider) error {
+			if _, err := r.SyncProviderModels(ctx, p, models); err != nil {
+				return err
+			}
+			return nil
+		},
+		"per_row": func(r *modeltest.Registry, p *domainmodel.Provider) error {
+			if _, err := r.SyncProviderModelsPerRow(ctx, p, models); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	for _, name := range []string{"batched", "per_row"} {
+		b.Run(name, func(b *testing.B) {
+			for range b.N {
+				registry := modeltest.NewRegistry()
+				provider := &domainmodel.Provider{Slug: "openai", Kind: domainmodel.ProviderOpenAI, Active: true}
+				registry.Providers.Add(provider)
+				if err := sync[name](registry, provider); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+func TestSyncDeactivatesMissingModels(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(2)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-openai", Slug: "openai", Kind: domainmodel.ProviderOpenAI, OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(provider)
+
+	sync := func(ids ...string) {
+		t.Helper()
+		models := make([]chatclient.Model, 0, len(ids))
+		for _, id := range ids {
+			models = append(models, chatclient.Model{ID: id, Object: "model"})
+		}
+		if _, err := registry.SyncProviderModels(ctx, provider, models); err != nil {
+			t.Fatalf("SyncProviderModels: %v", err)
+		}
+	}
+	active := func() map[string]bool {
+		t.Helper()
+		state := map[string]bool{}
+		for _, model := range registry.Models.All() {
+			state[model.ModelKey] = model.Active
+		}
+		return state
+	}
+
+	sync("gpt-4o", "gpt-4o-mini", "gpt-3.5-turbo")
+	sync("gpt-4o", "gpt-4o-mini")
+	state := active()
+	if len(state) != 3 {
+		t.Fatalf("stored %d models, want the removed model kept for history", len(state))
+	}
+	if !state["gpt-4o"] || !state["gpt-4o-mini"] || state["gpt-3.5-turbo"] {
+		t.Fatalf("active models = %v, want gpt-3.5-turbo inactive", state)
+	}
+	if _, err := registry.GetProviderForModel(ctx, "gpt-3.5-turbo", orgID, nil); err == nil {
+		t.Fatal("a model that disappeared upstream is still routed")
+	}
+
+	// An empty listing keeps the previous models.
+	sync()
+	if state := active(); !state["gpt-4o"] || !state["gpt-4o-mini"] {
+		t.Fatalf("active models after an empty listing = %v, want them kept", state)
+	}
+
+	// A model that comes back is routed again.
+	sync("gpt-4o", "gpt-4o-mini", "gpt-3.5-turbo")
+	routed, err := registry.GetProviderForModel(ctx, "gpt-3.5-turbo", orgID, nil)
+	if err != nil || routed.ID != provider.ID {
+		t.Fatalf("returning model is not routed: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+)
+
+// ProviderSyncDiff describes what a sync of a provider's model list would change. Catalogs are
+// identified by public ID and provider models by model key.
+type ProviderSyncDiff struct {
+	CatalogsCreated   []string `json:"catalogs_created"`
+	CatalogsUpdated   []string `json:"catalogs_updated"`
+	ModelsCreated     []string `json:"models_created"`
+	ModelsUpdated     []string `json:"models_updated"`
+	ModelsDeactivated []string `json:"models_deactivated"`
+}
+
+// PreviewSync computes the changes SyncProviderModels would make for the models without writing
+// anything, so admins can confirm a sync before running it.
+func (s *ProviderRegistryService) PreviewSync(ctx context.Context, provider *Provider, models []chatclient.Model) (*ProviderSyncDiff, *common.Error) {
+	models = ProbeProviderCapabilities(provider.Kind, models)
+
+	catalogsCreated, catalogsUpdated, err := s.modelCatalogService.PreviewCatalogs(ctx, models)
+	if err != nil {
+		return nil, err
+	}
+	modelsCreated, modelsUpdated, modelsDeactivated, err := s.providerModelService.PreviewProviderModels(ctx, provider, models, catalogsCreated)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderSyncDiff{
+		CatalogsCreated:   catalogsCreated,
+		CatalogsUpdated:   catalogsUpdated,
+		ModelsCreated:     modelsCreated,
+		ModelsUpdated:     modelsUpdated,
+		ModelsDeactivated: modelsDeactivated,
+	}, nil
+}
+
+// PreviewRefresh fetches the provider's current model list and previews syncing it; it is the
+// dry-run counterpart of RefreshProviderModels.
+func (s *ProviderRegistryService) PreviewRefresh(ctx context.Context, provider *Provider) (*ProviderSyncDiff, *common.Error) {
+	models, err := s.modelLister.ListModels(ctx, provider)
+	if err != nil {
+		return nil, common.NewError(err, ErrCodeProviderModelFetchFailed)
+	}
+	return s.PreviewSync(ctx, provider, models)
+}
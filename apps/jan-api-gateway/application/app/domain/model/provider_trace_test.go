@@ -0,0 +1,241 @@
+package model_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+)
+
+func TestTraceProviderForModel(t *testing.T) {
+	ctx := context.Background()
+	globalOrgID := uint(1)
+	orgID := uint(2)
+	projectID := uint(7)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: globalOrgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	type setup struct {
+		registry *modeltest.Registry
+		project  *domainmodel.Provider
+		org      *domainmodel.Provider
+		global   *domainmodel.Provider
+	}
+	newSetup := func() setup {
+		registry := modeltest.NewRegistry()
+		registry.Organizations.Add(&organization.Organization{ID: globalOrgID}, &organization.Organization{ID: orgID})
+		s := setup{
+			registry: registry,
+			project:  &domainmodel.Provider{PublicID: "prov-project", Slug: "project", OrganizationID: &orgID, ProjectID: &projectID, Active: true},
+			org:      &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true},
+			global:   &domainmodel.Provider{PublicID: "prov-global", Slug: "global", OrganizationID: &globalOrgID, Active: true},
+		}
+		// Added in reverse precedence so the trace order cannot come from insertion order.
+		registry.Providers.Add(s.global, s.org, s.project)
+		return s
+	}
+	serve := func(s setup, provider *domainmodel.Provider, keys ...string) {
+		for _, key := range keys {
+			s.registry.Models.Add(&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: key, Active: true})
+		}
+	}
+
+	tests := []struct {
+		name           string
+		model          string
+		prepare        func(s setup)
+		wantChosen     func(s setup) *domainmodel.Provider
+		wantResolution domainmodel.ProviderResolution
+		wantHasModel   []bool
+		wantWeighted   map[string]int
+	}{
+		{
+			name:  "project provider wins over organization and global",
+			model: "gpt",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+				serve(s, s.org, "gpt")
+				serve(s, s.project, "gpt")
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.project },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{true, true, true},
+		},
+		{
+			name:  "organization provider wins over global",
+			model: "gpt",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+				serve(s, s.org, "gpt")
+				serve(s, s.project, "other")
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.org },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{false, true, true},
+		},
+		{
+			name:  "global provider when no narrower scope serves the model",
+			model: "gpt",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.global },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{false, false, true},
+		},
+		{
+			name:  "open circuit skips to the next scope",
+			model: "gpt",
+			prepare: func(s setup) {
+				serve(s, s.org, "gpt")
+				serve(s, s.project, "gpt")
+				s.registry.Availability.SetAvailable(s.project.ID, false)
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.org },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{true, true, false},
+		},
+		{
+			name:  "alias pins the global provider over narrower scopes",
+			model: "fast",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+				serve(s, s.project, "gpt")
+				s.registry.Aliases.Add(&domainmodel.ModelAlias{OrganizationID: orgID, Alias: "fast", ProviderID: s.global.ID, ModelKey: "gpt"})
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.global },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{false, false, true},
+		},
+		{
+			name:  "shadow provider is a candidate but never chosen",
+			model: "gpt",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+				serve(s, s.project, "gpt")
+				s.project.Shadow = true
+				_ = s.registry.Providers.Update(ctx, s.project)
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.global },
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{true, false, true},
+		},
+		{
+			name:  "weighted organization providers report the weighted set",
+			model: "gpt",
+			prepare: func(s setup) {
+				second := &domainmodel.Provider{PublicID: "prov-org-2", Slug: "org-2", OrganizationID: &orgID, Active: true,
+					Metadata: map[string]string{domainmodel.RoutingWeightMetadataKey: "20"}}
+				s.registry.Providers.Add(second)
+				s.org.Metadata = map[string]string{domainmodel.RoutingWeightMetadataKey: "80"}
+				_ = s.registry.Providers.Update(ctx, s.org)
+				serve(s, s.org, "gpt")
+				serve(s, second, "gpt")
+				serve(s, s.global, "gpt")
+			},
+			wantResolution: domainmodel.ProviderResolutionMatched,
+			wantHasModel:   []bool{false, true, true, true},
+			wantWeighted:   map[string]int{"org": 80, "org-2": 20},
+		},
+		{
+			name:  "organization default when no provider serves the model",
+			model: "unknown",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+				org, _ := s.registry.Organizations.FindByID(ctx, orgID)
+				org.DefaultProviderID = &s.org.ID
+				_ = s.registry.Organizations.Update(ctx, org)
+			},
+			wantChosen:     func(s setup) *domainmodel.Provider { return s.org },
+			wantResolution: domainmodel.ProviderResolutionOrganizationDefault,
+			wantHasModel:   []bool{false, false, false},
+		},
+		{
+			name:  "nothing chosen when no provider serves the model and there is no fallback",
+			model: "unknown",
+			prepare: func(s setup) {
+				serve(s, s.global, "gpt")
+			},
+			wantResolution: domainmodel.ProviderResolutionNone,
+			wantHasModel:   []bool{false, false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSetup()
+			tt.prepare(s)
+
+			trace, err := s.registry.TraceProviderForModel(ctx, tt.model, orgID, []uint{projectID})
+			if err != nil {
+				t.Fatalf("TraceProviderForModel: %v", err)
+			}
+			if trace.Resolution != tt.wantResolution {
+				t.Fatalf("resolution = %s, want %s", trace.Resolution, tt.wantResolution)
+			}
+			if len(trace.Candidates) != len(tt.wantHasModel) {
+				t.Fatalf("got %d candidates, want %d", len(trace.Candidates), len(tt.wantHasModel))
+			}
+			wantOrder := []string{"project", "org", "global"}
+			wantScopes := []domainmodel.ProviderScope{domainmodel.ProviderScopeProject, domainmodel.ProviderScopeOrganization, domainmodel.ProviderScopeGlobal}
+			if tt.wantWeighted != nil {
+				wantOrder = []string{"project", "org", "org-2", "global"}
+				wantScopes = slices.Insert(wantScopes, 2, domainmodel.ProviderScopeOrganization)
+			}
+			for i, candidate := range trace.Candidates {
+				if candidate.Provider.Slug != wantOrder[i] {
+					t.Fatalf("candidate %d = %s, want %s", i, candidate.Provider.Slug, wantOrder[i])
+				}
+				if scope := candidate.Provider.Scope(); scope != wantScopes[i] {
+					t.Fatalf("candidate %s scope = %s, want %s", candidate.Provider.Slug, scope, wantScopes[i])
+				}
+				if candidate.HasModel != tt.wantHasModel[i] {
+					t.Fatalf("candidate %s has model = %t, want %t", candidate.Provider.Slug, candidate.HasModel, tt.wantHasModel[i])
+				}
+			}
+
+			if tt.wantWeighted != nil {
+				if trace.Chosen != nil {
+					t.Fatalf("chosen = %s, want no single pick for a weighted set", trace.Chosen.Slug)
+				}
+				if len(trace.Weighted) != len(tt.wantWeighted) {
+					t.Fatalf("got %d weighted providers, want %d", len(trace.Weighted), len(tt.wantWeighted))
+				}
+				for _, weighted := range trace.Weighted {
+					if weighted.Weight != tt.wantWeighted[weighted.Provider.Slug] {
+						t.Fatalf("weight of %s = %d, want %d", weighted.Provider.Slug, weighted.Weight, tt.wantWeighted[weighted.Provider.Slug])
+					}
+				}
+				return
+			}
+			if tt.wantChosen == nil {
+				if trace.Chosen != nil {
+					t.Fatalf("chosen = %s, want none", trace.Chosen.Slug)
+				}
+				if _, _, err := s.registry.GetProviderForModelOrDefault(ctx, tt.model, orgID, []uint{projectID}); err == nil {
+					t.Fatal("GetProviderForModelOrDefault resolved a provider the trace did not report")
+				}
+				return
+			}
+			want := tt.wantChosen(s)
+			if trace.Chosen == nil || trace.Chosen.ID != want.ID {
+				t.Fatalf("chosen = %v, want %s", trace.Chosen, want.Slug)
+			}
+			if len(trace.Weighted) != 0 {
+				t.Fatalf("got %d weighted providers, want none", len(trace.Weighted))
+			}
+
+			// The trace must agree with the provider routing actually picks.
+			chosen, resolution, err := s.registry.GetProviderForModelOrDefault(ctx, tt.model, orgID, []uint{projectID})
+			if err != nil {
+				t.Fatalf("GetProviderForModelOrDefault: %v", err)
+			}
+			if chosen.ID != trace.Chosen.ID || resolution != trace.Resolution {
+				t.Fatalf("routing picked %s (%s), trace reported %s (%s)", chosen.Slug, resolution, trace.Chosen.Slug, trace.Resolution)
+			}
+		})
+	}
+}
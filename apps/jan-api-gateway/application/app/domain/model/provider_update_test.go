@@ -0,0 +1,64 @@
+package model_test
+
+import (
+	"context"
+	"testing"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestUpdateProviderCustomBaseURLUniqueness(t *testing.T) {
+	ctx := context.Background()
+	orgID := uint(1)
+
+	setup := func() (*modeltest.Registry, *domainmodel.Provider) {
+		registry := modeltest.NewRegistry()
+		taken := &domainmodel.Provider{PublicID: "prov-taken", Slug: "taken", Kind: domainmodel.ProviderCustom, BaseURL: "https://taken.example.com/v1", OrganizationID: &orgID, Active: true}
+		moved := &domainmodel.Provider{PublicID: "prov-moved", Slug: "moved", Kind: domainmodel.ProviderCustom, BaseURL: "https://moved.example.com/v1", OrganizationID: &orgID, Active: true}
+		registry.Providers.Add(taken, moved)
+		return registry, moved
+	}
+	update := func(baseURL string) domainmodel.UpdateProviderInput {
+		return domainmodel.UpdateProviderInput{BaseURL: &baseURL}
+	}
+
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = previous
+	})
+
+	t.Run("rejects the base_url of another custom provider", func(t *testing.T) {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = true
+		registry, moved := setup()
+		_, err := registry.UpdateProvider(ctx, moved, update("https://taken.example.com/v1/"))
+		if err == nil || err.GetCode() != domainmodel.ErrCodeDuplicateCustomProvider {
+			t.Fatalf("UpdateProvider error = %v, want duplicate custom provider", err)
+		}
+		stored, _ := registry.Providers.FindByID(ctx, moved.ID)
+		if stored.BaseURL != "https://moved.example.com/v1" {
+			t.Fatalf("stored base_url = %q, want it unchanged", stored.BaseURL)
+		}
+	})
+
+	t.Run("keeps its own base_url", func(t *testing.T) {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = true
+		registry, moved := setup()
+		if _, err := registry.UpdateProvider(ctx, moved, update("https://moved.example.com/v1")); err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+	})
+
+	t.Run("allows duplicates when the check is disabled", func(t *testing.T) {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL = false
+		registry, moved := setup()
+		updated, err := registry.UpdateProvider(ctx, moved, update("https://taken.example.com/v1"))
+		if err != nil {
+			t.Fatalf("UpdateProvider: %v", err)
+		}
+		if updated.BaseURL != "https://taken.example.com/v1" {
+			t.Fatalf("base_url = %q, want the new one", updated.BaseURL)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"context"
+	"sort"
+
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// providerSyncEventData is the data of a provider.sync.completed webhook event.
+type providerSyncEventData struct {
+	ProviderID    string   `json:"provider_id"`
+	ProviderSlug  string   `json:"provider_slug"`
+	AddedModels   []string `json:"added_models"`
+	RemovedModels []string `json:"removed_models"`
+}
+
+// providerHealthEventData is the data of a provider.health.changed webhook event.
+type providerHealthEventData struct {
+	ProviderID   string  `json:"provider_id"`
+	ProviderSlug string  `json:"provider_slug"`
+	Healthy      bool    `json:"healthy"`
+	Error        *string `json:"error,omitempty"`
+}
+
+// activeModelKeysForWebhook returns the keys of the provider's active models before a sync, or
+// nil when no event can be sent for the provider. A lookup failure is logged and disables the
+// event rather than the sync.
+func (s *ProviderRegistryService) activeModelKeysForWebhook(ctx context.Context, provider *Provider) map[string]struct{} {
+	if s.webhookService == nil || provider.OrganizationID == nil || provider.ID == 0 {
+		return nil
+	}
+	models, err := s.providerModelService.ListActiveByProviderIDs(ctx, []uint{provider.ID})
+	if err != nil {
+		logger.GetLogger().Errorf("failed to load active models of provider %s for its sync webhook: %v", provider.Slug, err)
+		return nil
+	}
+	keys := make(map[string]struct{}, len(models))
+	for _, pm := range models {
+		keys[pm.ModelKey] = struct{}{}
+	}
+	return keys
+}
+
+// notifyProviderSync sends provider.sync.completed when the sync changed the provider's set of
+// active models. Models missing from results were deactivated by the sync; models that failed to
+// sync keep their previous state.
+func (s *ProviderRegistryService) notifyProviderSync(ctx context.Context, provider *Provider, previous map[string]struct{}, results []ProviderModelSyncResult) {
+	if previous == nil {
+		return
+	}
+	current := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		if result.Error != nil {
+			if _, ok := previous[result.ModelKey]; ok {
+				current[result.ModelKey] = struct{}{}
+			}
+			continue
+		}
+		if result.ProviderModel != nil && result.ProviderModel.Active {
+			current[result.ProviderModel.ModelKey] = struct{}{}
+		}
+	}
+
+	data := providerSyncEventData{
+		ProviderID:    provider.PublicID,
+		ProviderSlug:  provider.Slug,
+		AddedModels:   []string{},
+		RemovedModels: []string{},
+	}
+	for key := range current {
+		if _, ok := previous[key]; !ok {
+			data.AddedModels = append(data.AddedModels, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			data.RemovedModels = append(data.RemovedModels, key)
+		}
+	}
+	if len(data.AddedModels) == 0 && len(data.RemovedModels) == 0 {
+		return
+	}
+	sort.Strings(data.AddedModels)
+	sort.Strings(data.RemovedModels)
+	s.webhookService.Notify(ctx, *provider.OrganizationID, webhook.EventProviderSyncCompleted, data)
+}
@@ -0,0 +1,98 @@
+package moderation
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	moderationclient "menlo.ai/jan-api-gateway/app/utils/httpclients/moderation"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// Verdict is the outcome of moderating a request's input.
+type Verdict struct {
+	Flagged bool
+	// Categories lists the flagged categories, sorted.
+	Categories []string
+}
+
+// ModerationService screens completion input with the configured moderation endpoint before it
+// is sent to providers that do not moderate upstream.
+type ModerationService struct {
+	client *moderationclient.ModerationClient
+}
+
+func NewModerationService() *ModerationService {
+	return &ModerationService{
+		client: moderationclient.NewModerationClient(),
+	}
+}
+
+// Required reports whether the input must be moderated before dispatch: ENABLE_INPUT_MODERATION
+// is set, an endpoint is configured and at least one provider in the chain does not moderate
+// upstream. A chain made only of moderated providers is skipped to avoid checking twice.
+func (s *ModerationService) Required(providers []*domainmodel.Provider) bool {
+	if !environment_variables.EnvironmentVariables.ENABLE_INPUT_MODERATION || !s.client.Configured() {
+		return false
+	}
+	for _, provider := range providers {
+		if provider != nil && !provider.IsModerated {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckInput moderates the text of the request's user messages.
+func (s *ModerationService) CheckInput(ctx context.Context, request openai.ChatCompletionRequest) (*Verdict, error) {
+	input := userMessageText(request.Messages)
+	if len(input) == 0 {
+		return &Verdict{}, nil
+	}
+	response, err := s.client.Moderate(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict := &Verdict{}
+	seen := map[string]struct{}{}
+	for _, result := range response.Results {
+		if !result.Flagged {
+			continue
+		}
+		verdict.Flagged = true
+		for category, flagged := range result.Categories {
+			if _, ok := seen[category]; flagged && !ok {
+				seen[category] = struct{}{}
+				verdict.Categories = append(verdict.Categories, category)
+			}
+		}
+	}
+	sort.Strings(verdict.Categories)
+	return verdict, nil
+}
+
+// userMessageText collects the text of every user message, including the text parts of
+// multi-part content.
+func userMessageText(messages []openai.ChatCompletionMessage) []string {
+	input := make([]string, 0, len(messages))
+	for _, message := range messages {
+		if message.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		if text := strings.TrimSpace(message.Content); text != "" {
+			input = append(input, text)
+		}
+		for _, part := range message.MultiContent {
+			if part.Type != openai.ChatMessagePartTypeText {
+				continue
+			}
+			if text := strings.TrimSpace(part.Text); text != "" {
+				input = append(input, text)
+			}
+		}
+	}
+	return input
+}
@@ -14,6 +14,17 @@ type Organization struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Enabled   bool
+	// MonthlySpendLimitMicroUSD caps estimated inference spend per UTC calendar month.
+	// Nil falls back to MONTHLY_SPEND_LIMIT_MICRO_USD.
+	MonthlySpendLimitMicroUSD *int64
+	// DefaultProviderID is the organization provider that serves models no accessible provider
+	// advertises. Nil falls back to the Jan provider.
+	DefaultProviderID *uint
+	// WebhookURL receives signed provider sync and health events. Nil disables webhooks.
+	WebhookURL *string
+	// EncryptedWebhookSecret is the per-organization key signing webhook events, encrypted with
+	// MODEL_PROVIDER_SECRET. It is replaced whenever WebhookURL is set.
+	EncryptedWebhookSecret string
 }
 
 type OrganizationMemberRole string
@@ -17,6 +17,10 @@ type Project struct {
 	UpdatedAt      time.Time
 	ArchivedAt     *time.Time
 	IsDefault      bool
+	// MonthlySpendLimitMicroUSD caps the project's monthly spend within the organization's limit.
+	MonthlySpendLimitMicroUSD *int64
+	// ModelPolicy restricts the models the project may request. Nil permits every model.
+	ModelPolicy *ProjectModelPolicy
 }
 
 type ProjectMember struct {
@@ -0,0 +1,81 @@
+package project
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+)
+
+// ErrCodeInvalidModelPolicy is returned when a project model policy entry is not a valid glob pattern.
+const ErrCodeInvalidModelPolicy = "e1a7c3f9-2b6d-4d85-9c04-7f3b8a2e6d15"
+
+// ErrCodeModelNotPermitted is returned when a request uses a model its project's policy does not permit.
+const ErrCodeModelNotPermitted = "5f9b2d7e-4c1a-4e36-8b0d-a6e3c9f1b724"
+
+// ProjectModelPolicy restricts the models a project may request, independently of what its
+// providers serve. Entries are model key globs in path.Match syntax, e.g. "openai/gpt-4*".
+type ProjectModelPolicy struct {
+	// Allow lists the permitted models; empty permits every model not denied.
+	Allow []string `json:"allow"`
+	// Deny lists models that are never permitted, even when allowed.
+	Deny []string `json:"deny"`
+}
+
+// NewProjectModelPolicy builds a policy from raw allow and deny lists, trimming entries and
+// rejecting malformed patterns. Nil is returned when both lists are empty.
+func NewProjectModelPolicy(allow []string, deny []string) (*ProjectModelPolicy, *common.Error) {
+	allowed, err := sanitizePolicyPatterns(allow)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := sanitizePolicyPatterns(deny)
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil, nil
+	}
+	return &ProjectModelPolicy{Allow: allowed, Deny: denied}, nil
+}
+
+// Permits reports whether the policy lets the project request the model. A nil policy permits every model.
+func (p *ProjectModelPolicy) Permits(modelKey string) bool {
+	if p == nil {
+		return true
+	}
+	if matchesPolicyPattern(p.Deny, modelKey) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchesPolicyPattern(p.Allow, modelKey)
+}
+
+func matchesPolicyPattern(patterns []string, modelKey string) bool {
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, modelKey); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+func sanitizePolicyPatterns(patterns []string) ([]string, *common.Error) {
+	result := make([]string, 0, len(patterns))
+	seen := make(map[string]struct{}, len(patterns))
+	for _, raw := range patterns {
+		pattern := strings.TrimSpace(raw)
+		if pattern == "" {
+			continue
+		}
+		if _, ok := seen[pattern]; ok {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, common.NewErrorWithMessage(fmt.Sprintf("model pattern %q is not a valid glob", pattern), ErrCodeInvalidModelPolicy)
+		}
+		seen[pattern] = struct{}{}
+		result = append(result, pattern)
+	}
+	return result, nil
+}
@@ -32,6 +32,7 @@ type ResponseCreationResult struct {
 	APIKey                string
 	IsStreaming           bool
 	Provider              *domainmodel.Provider
+	FallbackReason        domainmodel.FallbackReason
 }
 
 // ResponseModelService handles the business logic for response API endpoints
@@ -93,9 +94,13 @@ func (h *ResponseModelService) CreateResponse(ctx context.Context, userID uint,
 	}
 
 	// Get provider based on the requested model
-	provider, providerErr := h.providerRegistry.GetProviderForModel(ctx, request.Model, organization.DEFAULT_ORGANIZATION.ID, nil)
+	selection, providerErr := h.providerRegistry.SelectProviderForModel(ctx, request.Model, organization.DEFAULT_ORGANIZATION.ID, nil)
 	if providerErr != nil {
-		logger.GetLogger().Warnf("Failed to find provider for model '%s': %v, using default provider", request.Model, providerErr)
+		return nil, common.NewError(providerErr, "0b6e3f1d-94a2-4c57-8e1b-2d7f5a9c3e60")
+	}
+	provider := selection.Provider
+	if selection.Resolution != domainmodel.ProviderResolutionMatched {
+		logger.GetLogger().Warnf("No provider advertises model '%s' (%s), using %s provider", request.Model, selection.FallbackReason, selection.Resolution)
 	}
 
 	// Create model client for validation
@@ -200,6 +205,7 @@ func (h *ResponseModelService) CreateResponse(ctx context.Context, userID uint,
 		APIKey:                key,
 		IsStreaming:           isStreaming,
 		Provider:              provider,
+		FallbackReason:        selection.FallbackReason,
 	}, nil
 }
 
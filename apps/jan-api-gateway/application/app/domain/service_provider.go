@@ -4,15 +4,20 @@ import (
 	"github.com/google/wire"
 	"menlo.ai/jan-api-gateway/app/domain/apikey"
 	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/contentfilter"
 	"menlo.ai/jan-api-gateway/app/domain/conversation"
 	"menlo.ai/jan-api-gateway/app/domain/cron"
 	"menlo.ai/jan-api-gateway/app/domain/invite"
 	"menlo.ai/jan-api-gateway/app/domain/mcp/serpermcp"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/moderation"
 	"menlo.ai/jan-api-gateway/app/domain/organization"
 	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/ratelimit"
 	"menlo.ai/jan-api-gateway/app/domain/response"
+	"menlo.ai/jan-api-gateway/app/domain/spend"
 	"menlo.ai/jan-api-gateway/app/domain/user"
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
 	"menlo.ai/jan-api-gateway/app/domain/workspace"
 )
 
@@ -28,10 +33,17 @@ var ServiceProvider = wire.NewSet(
 	domainmodel.NewProviderModelService,
 	domainmodel.NewModelCatalogService,
 	domainmodel.NewProviderRegistryService,
+	domainmodel.NewModelAliasService,
+	domainmodel.NewProviderHealthChecker,
 	response.NewResponseService,
 	response.NewResponseModelService,
 	response.NewStreamModelService,
 	response.NewNonStreamModelService,
 	serpermcp.NewSerperService,
 	cron.NewCronService,
+	contentfilter.NewContentFilterService,
+	spend.NewSpendTracker,
+	ratelimit.NewModelRateLimiter,
+	moderation.NewModerationService,
+	webhook.NewWebhookService,
 )
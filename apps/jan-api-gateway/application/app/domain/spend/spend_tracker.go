@@ -0,0 +1,145 @@
+package spend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// ErrorCodeMonthlySpendLimitExceeded is returned once the monthly spend limit has been reached.
+const ErrorCodeMonthlySpendLimitExceeded = "6f3b9d2e-8a41-4c7d-b5e0-2d9c7a1f4e38"
+
+// SpendTracker accumulates the estimated inference cost per organization and project in Redis.
+// Counters are keyed by UTC calendar month and expire shortly after the month ends.
+type SpendTracker struct {
+	cache               *cache.RedisCacheService
+	organizationService *organization.OrganizationService
+	projectService      *project.ProjectService
+	authService         *auth.AuthService
+	now                 func() time.Time
+}
+
+func NewSpendTracker(
+	cacheService *cache.RedisCacheService,
+	organizationService *organization.OrganizationService,
+	projectService *project.ProjectService,
+	authService *auth.AuthService,
+) *SpendTracker {
+	return &SpendTracker{
+		cache:               cacheService,
+		organizationService: organizationService,
+		projectService:      projectService,
+		authService:         authService,
+		now:                 time.Now,
+	}
+}
+
+// Record adds cost to the current month's spend of the organization and, when set, the project.
+func (t *SpendTracker) Record(ctx context.Context, organizationID uint, projectID *uint, cost domainmodel.MicroUSD) error {
+	if cost <= 0 {
+		return nil
+	}
+	month, expireAt := t.currentMonth()
+	if _, err := t.cache.IncrBy(ctx, fmt.Sprintf(cache.OrganizationMonthlySpendKey, organizationID, month), int64(cost), expireAt); err != nil {
+		return err
+	}
+	if projectID != nil {
+		if _, err := t.cache.IncrBy(ctx, fmt.Sprintf(cache.ProjectMonthlySpendKey, *projectID, month), int64(cost), expireAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LimitExceeded reports whether the current month's spend has reached a limit that applies. A
+// project with its own limit is checked against its own spend, and the organization's limit, or
+// MONTHLY_SPEND_LIMIT_MICRO_USD when unset, is always checked against the organization's spend
+// so a project limit can never lift the organization's cap. Limits of zero or less disable the
+// check.
+func (t *SpendTracker) LimitExceeded(ctx context.Context, organizationID uint, projectID *uint) (bool, error) {
+	month, _ := t.currentMonth()
+	if projectID != nil {
+		proj, err := t.projectService.FindProjectByID(ctx, *projectID)
+		if err != nil {
+			return false, err
+		}
+		if proj != nil && proj.MonthlySpendLimitMicroUSD != nil {
+			exceeded, err := t.exceeds(ctx, fmt.Sprintf(cache.ProjectMonthlySpendKey, *projectID, month), *proj.MonthlySpendLimitMicroUSD)
+			if err != nil || exceeded {
+				return exceeded, err
+			}
+		}
+	}
+
+	limit := int64(environment_variables.EnvironmentVariables.MONTHLY_SPEND_LIMIT_MICRO_USD)
+	org, err := t.organizationService.FindOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return false, err
+	}
+	if org != nil && org.MonthlySpendLimitMicroUSD != nil {
+		limit = *org.MonthlySpendLimitMicroUSD
+	}
+	return t.exceeds(ctx, fmt.Sprintf(cache.OrganizationMonthlySpendKey, organizationID, month), limit)
+}
+
+// SpendLimitMiddleware rejects requests from the default organization with 429 once its monthly
+// spend limit, or that of the project of the project API key the request is authenticated with,
+// has been reached. Lookup failures let the request through.
+func (t *SpendTracker) SpendLimitMiddleware() gin.HandlerFunc {
+	return func(reqCtx *gin.Context) {
+		proj, err := t.authService.ResolveApikeyProject(reqCtx)
+		if err != nil {
+			logger.GetLogger().Errorf("failed to resolve project for monthly spend limit: %v", err)
+			reqCtx.Next()
+			return
+		}
+		var projectID *uint
+		if proj != nil {
+			projectID = &proj.ID
+		}
+		exceeded, err := t.LimitExceeded(reqCtx.Request.Context(), organization.DEFAULT_ORGANIZATION.ID, projectID)
+		if err != nil {
+			logger.GetLogger().Errorf("failed to check monthly spend limit: %v", err)
+			reqCtx.Next()
+			return
+		}
+		if exceeded {
+			reqCtx.AbortWithStatusJSON(http.StatusTooManyRequests, responses.ErrorResponse{
+				Code:  ErrorCodeMonthlySpendLimitExceeded,
+				Error: "monthly spend limit exceeded",
+			})
+			return
+		}
+		reqCtx.Next()
+	}
+}
+
+func (t *SpendTracker) exceeds(ctx context.Context, key string, limit int64) (bool, error) {
+	if limit <= 0 {
+		return false, nil
+	}
+	spent, err := t.cache.GetInt64(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	return spent >= limit, nil
+}
+
+// currentMonth returns the UTC month label used in spend keys and the time its counters expire,
+// a day after the month ends so late writes near the boundary are not lost.
+func (t *SpendTracker) currentMonth() (string, time.Time) {
+	now := t.now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return monthStart.Format("2006-01"), monthStart.AddDate(0, 1, 1)
+}
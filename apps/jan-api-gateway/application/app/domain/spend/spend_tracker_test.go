@@ -0,0 +1,174 @@
+package spend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache/cachetest"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// projectRepository serves FindByID from a fixed set of projects.
+type projectRepository struct {
+	project.ProjectRepository
+	projects map[uint]*project.Project
+}
+
+func (r *projectRepository) FindByID(ctx context.Context, id uint) (*project.Project, error) {
+	return r.projects[id], nil
+}
+
+func newTestTracker(now time.Time, orgLimit, projectLimit *int64) (*SpendTracker, *cachetest.Store) {
+	cacheService, store := cachetest.NewCache()
+	organizations := modeltest.NewOrganizationRepository()
+	organizations.Add(&organization.Organization{ID: 1, MonthlySpendLimitMicroUSD: orgLimit})
+	projects := &projectRepository{projects: map[uint]*project.Project{
+		7: {ID: 7, MonthlySpendLimitMicroUSD: projectLimit},
+	}}
+	tracker := NewSpendTracker(cacheService, organization.NewService(organizations), project.NewService(projects), &auth.AuthService{})
+	tracker.now = func() time.Time { return now }
+	return tracker, store
+}
+
+func TestRecordUsesTheUTCMonth(t *testing.T) {
+	ctx := context.Background()
+	projectID := uint(7)
+	// Still January in UTC although it is already February in UTC+2
+	lastSecond := time.Date(2026, time.February, 1, 1, 59, 59, 0, time.FixedZone("UTC+2", 2*60*60))
+	tracker, store := newTestTracker(lastSecond, nil, nil)
+
+	if err := tracker.Record(ctx, 1, &projectID, 250); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	tracker.now = func() time.Time { return lastSecond.Add(time.Second) }
+	if err := tracker.Record(ctx, 1, &projectID, 100); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	tests := []struct {
+		key      string
+		want     string
+		expireAt time.Time
+	}{
+		{key: fmt.Sprintf(cache.OrganizationMonthlySpendKey, 1, "2026-01"), want: "250", expireAt: time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{key: fmt.Sprintf(cache.ProjectMonthlySpendKey, 7, "2026-01"), want: "250", expireAt: time.Date(2026, time.February, 2, 0, 0, 0, 0, time.UTC)},
+		{key: fmt.Sprintf(cache.OrganizationMonthlySpendKey, 1, "2026-02"), want: "100", expireAt: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)},
+		{key: fmt.Sprintf(cache.ProjectMonthlySpendKey, 7, "2026-02"), want: "100", expireAt: time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got, _ := store.Get(tt.key); got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+		}
+		if got, ok := store.ExpireAt(tt.key); !ok || !got.Equal(tt.expireAt) {
+			t.Errorf("%s expires at %v, want %v", tt.key, got, tt.expireAt)
+		}
+	}
+}
+
+func TestLimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+	previousLimit := environment_variables.EnvironmentVariables.MONTHLY_SPEND_LIMIT_MICRO_USD
+	t.Cleanup(func() { environment_variables.EnvironmentVariables.MONTHLY_SPEND_LIMIT_MICRO_USD = previousLimit })
+	limit := func(v int64) *int64 { return &v }
+	projectID := uint(7)
+
+	tests := []struct {
+		name         string
+		envLimit     int
+		orgLimit     *int64
+		projectLimit *int64
+		orgSpend     string
+		projectSpend string
+		projectID    *uint
+		want         bool
+	}{
+		{name: "no limit", orgSpend: "1000"},
+		{name: "under the organization limit", orgLimit: limit(1000), orgSpend: "999"},
+		{name: "organization limit reached", orgLimit: limit(1000), orgSpend: "1000", want: true},
+		{name: "environment limit applies without an organization limit", envLimit: 500, orgSpend: "500", want: true},
+		{name: "organization limit replaces the environment limit", envLimit: 500, orgLimit: limit(1000), orgSpend: "500"},
+		{name: "project limit reached", orgLimit: limit(1000), projectLimit: limit(100), orgSpend: "100", projectSpend: "100", projectID: &projectID, want: true},
+		{name: "project under its limit", orgLimit: limit(1000), projectLimit: limit(100), orgSpend: "99", projectSpend: "99", projectID: &projectID},
+		{name: "project limit does not lift the organization cap", orgLimit: limit(1000), projectLimit: limit(5000), orgSpend: "1000", projectSpend: "10", projectID: &projectID, want: true},
+		{name: "project without its own limit", orgLimit: limit(1000), orgSpend: "1000", projectSpend: "10", projectID: &projectID, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment_variables.EnvironmentVariables.MONTHLY_SPEND_LIMIT_MICRO_USD = tt.envLimit
+			tracker, store := newTestTracker(now, tt.orgLimit, tt.projectLimit)
+			if tt.orgSpend != "" {
+				store.Set(fmt.Sprintf(cache.OrganizationMonthlySpendKey, 1, "2026-03"), tt.orgSpend)
+			}
+			if tt.projectSpend != "" {
+				store.Set(fmt.Sprintf(cache.ProjectMonthlySpendKey, 7, "2026-03"), tt.projectSpend)
+			}
+			// Last month's spend never counts
+			store.Set(fmt.Sprintf(cache.OrganizationMonthlySpendKey, 1, "2026-02"), "1000000")
+
+			got, err := tracker.LimitExceeded(ctx, 1, tt.projectID)
+			if err != nil {
+				t.Fatalf("LimitExceeded: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("LimitExceeded = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpendLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	t.Cleanup(func() { organization.DEFAULT_ORGANIZATION = previousDefault })
+	orgLimit := int64(1000)
+
+	tests := []struct {
+		name   string
+		spend  string
+		status int
+	}{
+		{name: "under the limit", spend: "999", status: http.StatusOK},
+		{name: "limit reached", spend: "1000", status: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, store := newTestTracker(time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC), &orgLimit, nil)
+			store.Set(fmt.Sprintf(cache.OrganizationMonthlySpendKey, 1, "2026-03"), tt.spend)
+
+			router := gin.New()
+			router.POST("/v1/chat/completions", tracker.SpendLimitMiddleware(), func(reqCtx *gin.Context) {
+				reqCtx.Status(http.StatusOK)
+			})
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if tt.status != http.StatusTooManyRequests {
+				return
+			}
+			var body responses.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+			}
+			if body.Code != ErrorCodeMonthlySpendLimitExceeded {
+				t.Fatalf("code = %q, want %q", body.Code, ErrorCodeMonthlySpendLimitExceeded)
+			}
+		})
+	}
+}
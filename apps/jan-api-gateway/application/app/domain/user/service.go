@@ -84,12 +84,9 @@ func (s *UserService) FindByPublicID(ctx context.Context, publicID string) (*Use
 	cacheKey := fmt.Sprintf(cache.UserByPublicIDKey, publicID)
 
 	// Try to get from cache first
-	cachedUserJSON, err := s.cache.Get(ctx, cacheKey)
-	if err == nil && cachedUserJSON != "" {
-		var cachedUser User
-		if jsonErr := json.Unmarshal([]byte(cachedUserJSON), &cachedUser); jsonErr == nil {
-			return &cachedUser, nil
-		}
+	var cachedUser User
+	if s.cache.GetJSON(ctx, cacheKey, &cachedUser) {
+		return &cachedUser, nil
 	}
 
 	// Cache miss or error - fetch from database
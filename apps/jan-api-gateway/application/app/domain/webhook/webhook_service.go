@@ -0,0 +1,177 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/utils/crypto"
+	webhookclient "menlo.ai/jan-api-gateway/app/utils/httpclients/webhook"
+	"menlo.ai/jan-api-gateway/app/utils/idgen"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/retry"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+const (
+	// EventProviderSyncCompleted is sent when a model sync adds or removes active models.
+	EventProviderSyncCompleted = "provider.sync.completed"
+	// EventProviderHealthChanged is sent when a health check flips a provider between healthy and failing.
+	EventProviderHealthChanged = "provider.health.changed"
+)
+
+// ErrCodeInvalidWebhookURL is returned when an organization webhook URL is not an absolute http or
+// https URL or names a non-public address.
+const ErrCodeInvalidWebhookURL = "8f2d6b4a-1e7c-4c93-a5d8-3b9e0f7c2a61"
+
+// deliveryPolicy retries failed deliveries for roughly a minute before the event is dropped. A
+// host resolving to a non-public address is not retried.
+var deliveryPolicy = retry.Policy{
+	Attempts:  5,
+	BaseDelay: 2 * time.Second,
+	MaxDelay:  30 * time.Second,
+	Retryable: func(err error) bool {
+		return !errors.Is(err, webhookclient.ErrNonPublicAddress)
+	},
+}
+
+// Event is the JSON body posted to organization webhooks.
+type Event struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	CreatedAt      int64  `json:"created_at"`
+	OrganizationID string `json:"organization_id"`
+	Data           any    `json:"data"`
+}
+
+// WebhookService delivers provider events to the webhook URL configured on an organization.
+type WebhookService struct {
+	organizationService *organization.OrganizationService
+}
+
+func NewWebhookService(organizationService *organization.OrganizationService) *WebhookService {
+	return &WebhookService{
+		organizationService: organizationService,
+	}
+}
+
+// SetOrganizationWebhookURL stores the URL events of the organization are posted to together with
+// a new signing secret, which is returned so it can be shown once. An empty URL turns webhooks off
+// and drops the secret.
+func (s *WebhookService) SetOrganizationWebhookURL(ctx context.Context, org *organization.Organization, rawURL string) (string, *common.Error) {
+	webhookURL := strings.TrimSpace(rawURL)
+	var signingSecret, encryptedSecret string
+	if webhookURL != "" {
+		parsed, err := url.ParseRequestURI(webhookURL)
+		if err != nil {
+			return "", common.NewErrorWithMessage("webhook_url is not a valid URL", ErrCodeInvalidWebhookURL)
+		}
+		scheme := strings.ToLower(parsed.Scheme)
+		if (scheme != "http" && scheme != "https") || parsed.Host == "" {
+			return "", common.NewErrorWithMessage("webhook_url must be an absolute http or https URL", ErrCodeInvalidWebhookURL)
+		}
+		if err := webhookclient.CheckHost(parsed.Hostname()); err != nil {
+			return "", common.NewErrorWithMessage("webhook_url must not point to a loopback, private, link-local or metadata address", ErrCodeInvalidWebhookURL)
+		}
+
+		secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+		if secret == "" {
+			return "", common.NewErrorWithMessage("model provider secret is not configured", "d6a3f8b1-2c7e-4e94-8b50-1f9c4a7e3d26")
+		}
+		signingSecret, err = idgen.GenerateSecureID("whsec", 32)
+		if err != nil {
+			return "", common.NewError(err, "5e8b2d7c-9a41-4f63-b0d8-7c3e1a6f4b95")
+		}
+		encryptedSecret, err = crypto.EncryptString(secret, signingSecret)
+		if err != nil {
+			return "", common.NewError(err, "a1f7c4e9-3b6d-4a28-9e5f-2d8b6c0e7a13")
+		}
+	}
+
+	previousURL, previousSecret := org.WebhookURL, org.EncryptedWebhookSecret
+	org.WebhookURL = nil
+	if webhookURL != "" {
+		org.WebhookURL = &webhookURL
+	}
+	org.EncryptedWebhookSecret = encryptedSecret
+	if _, err := s.organizationService.UpdateOrganization(ctx, org); err != nil {
+		org.WebhookURL, org.EncryptedWebhookSecret = previousURL, previousSecret
+		return "", common.NewError(err, "4c7e1a9d-6b3f-4d28-9e05-a2f8c6d1b743")
+	}
+	return signingSecret, nil
+}
+
+// Notify posts the event to the organization's webhook in the background, retrying non-2xx
+// responses with backoff. Delivery never fails the caller: organizations without a webhook are
+// skipped and delivery errors are only logged.
+func (s *WebhookService) Notify(ctx context.Context, organizationID uint, eventType string, data any) {
+	if s == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	go s.deliver(ctx, organizationID, eventType, data)
+}
+
+func (s *WebhookService) deliver(ctx context.Context, organizationID uint, eventType string, data any) {
+	org, err := s.organizationService.FindOrganizationByID(ctx, organizationID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.GetLogger().Errorf("webhook %s: failed to load organization %d: %v", eventType, organizationID, err)
+		}
+		return
+	}
+	if org.WebhookURL == nil || *org.WebhookURL == "" {
+		return
+	}
+	secret, err := s.signingSecret(org)
+	if err != nil {
+		logger.GetLogger().Warnf("webhook %s for organization %s not sent: %v", eventType, org.PublicID, err)
+		return
+	}
+
+	body, err := s.encodeEvent(org, eventType, data)
+	if err != nil {
+		logger.GetLogger().Errorf("webhook %s: failed to encode event: %v", eventType, err)
+		return
+	}
+	err = retry.Do(ctx, deliveryPolicy, func(ctx context.Context) error {
+		return webhookclient.Deliver(ctx, *org.WebhookURL, eventType, body, secret)
+	})
+	if err != nil {
+		logger.GetLogger().Warnf("webhook %s for organization %s dropped after %d attempts: %v", eventType, org.PublicID, deliveryPolicy.Attempts, err)
+	}
+}
+
+// signingSecret decrypts the organization's webhook signing secret.
+func (s *WebhookService) signingSecret(org *organization.Organization) (string, error) {
+	if org.EncryptedWebhookSecret == "" {
+		return "", errors.New("the organization has no signing secret, set webhook_url again to create one")
+	}
+	secret := strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET)
+	signingSecret, err := crypto.DecryptString(secret, org.EncryptedWebhookSecret)
+	if err != nil {
+		return "", fmt.Errorf("decrypt signing secret: %w", err)
+	}
+	return signingSecret, nil
+}
+
+func (s *WebhookService) encodeEvent(org *organization.Organization, eventType string, data any) ([]byte, error) {
+	id, err := idgen.GenerateSecureID("evt", 32)
+	if err != nil {
+		return nil, fmt.Errorf("generate event id: %w", err)
+	}
+	return json.Marshal(Event{
+		ID:             id,
+		Type:           eventType,
+		CreatedAt:      time.Now().Unix(),
+		OrganizationID: org.PublicID,
+		Data:           data,
+	})
+}
@@ -0,0 +1,95 @@
+package webhook_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
+	"menlo.ai/jan-api-gateway/app/utils/crypto"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestSetOrganizationWebhookURL(t *testing.T) {
+	ctx := context.Background()
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret"
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+
+	setup := func() (*webhook.WebhookService, *modeltest.OrganizationRepository, *organization.Organization) {
+		repo := modeltest.NewOrganizationRepository()
+		org := &organization.Organization{PublicID: "org"}
+		repo.Add(org)
+		return webhook.NewWebhookService(organization.NewService(repo)), repo, org
+	}
+
+	t.Run("stores an encrypted secret and returns it once", func(t *testing.T) {
+		service, repo, org := setup()
+		secret, err := service.SetOrganizationWebhookURL(ctx, org, " https://hooks.example.com/jan ")
+		if err != nil {
+			t.Fatalf("SetOrganizationWebhookURL: %v", err)
+		}
+		if !strings.HasPrefix(secret, "whsec_") {
+			t.Fatalf("secret = %q, want a whsec_ secret", secret)
+		}
+		stored, _ := repo.FindByID(ctx, org.ID)
+		if stored.WebhookURL == nil || *stored.WebhookURL != "https://hooks.example.com/jan" {
+			t.Fatalf("stored webhook_url = %v", stored.WebhookURL)
+		}
+		if stored.EncryptedWebhookSecret == secret {
+			t.Fatal("signing secret stored in plaintext")
+		}
+		decrypted, decryptErr := crypto.DecryptString("test-secret", stored.EncryptedWebhookSecret)
+		if decryptErr != nil || decrypted != secret {
+			t.Fatalf("decrypted secret = %q, %v, want %q", decrypted, decryptErr, secret)
+		}
+
+		rotated, err := service.SetOrganizationWebhookURL(ctx, org, "https://hooks.example.com/jan")
+		if err != nil {
+			t.Fatalf("SetOrganizationWebhookURL: %v", err)
+		}
+		if rotated == secret {
+			t.Fatal("setting the URL again kept the old secret")
+		}
+	})
+
+	t.Run("clearing the URL drops the secret", func(t *testing.T) {
+		service, repo, org := setup()
+		if _, err := service.SetOrganizationWebhookURL(ctx, org, "https://hooks.example.com/jan"); err != nil {
+			t.Fatalf("SetOrganizationWebhookURL: %v", err)
+		}
+		secret, err := service.SetOrganizationWebhookURL(ctx, org, "")
+		if err != nil || secret != "" {
+			t.Fatalf("SetOrganizationWebhookURL = %q, %v, want no secret", secret, err)
+		}
+		stored, _ := repo.FindByID(ctx, org.ID)
+		if stored.WebhookURL != nil || stored.EncryptedWebhookSecret != "" {
+			t.Fatalf("stored webhook = %v, %q, want both cleared", stored.WebhookURL, stored.EncryptedWebhookSecret)
+		}
+	})
+
+	t.Run("rejects non-public addresses", func(t *testing.T) {
+		for _, rawURL := range []string{
+			"http://169.254.169.254/latest/meta-data",
+			"http://127.0.0.1:8080/hook",
+			"http://localhost/hook",
+			"https://10.1.2.3/hook",
+			"http://[::1]/hook",
+			"ftp://hooks.example.com/jan",
+		} {
+			service, repo, org := setup()
+			_, err := service.SetOrganizationWebhookURL(ctx, org, rawURL)
+			if err == nil || err.GetCode() != webhook.ErrCodeInvalidWebhookURL {
+				t.Errorf("SetOrganizationWebhookURL(%q) error = %v, want invalid webhook URL", rawURL, err)
+			}
+			stored, _ := repo.FindByID(ctx, org.ID)
+			if stored.WebhookURL != nil {
+				t.Errorf("SetOrganizationWebhookURL(%q) stored the URL", rawURL)
+			}
+		}
+	})
+}
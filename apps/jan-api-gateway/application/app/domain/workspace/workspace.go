@@ -19,6 +19,12 @@ type Workspace struct {
 	UpdatedAt   time.Time
 }
 
+// WorkspaceWithConversationCount pairs a workspace with the number of its conversations.
+type WorkspaceWithConversationCount struct {
+	Workspace         *Workspace
+	ConversationCount int64
+}
+
 func (w *Workspace) Normalize() error {
 	trimmedName := strings.TrimSpace(w.Name)
 	if trimmedName == "" {
@@ -39,6 +45,10 @@ type WorkspaceFilter struct {
 
 type WorkspaceRepository interface {
 	Create(ctx context.Context, workspace *Workspace) error
+	// CreateWithinLimit creates the workspace unless its user already owns limit workspaces and
+	// reports whether it did. Concurrent calls for the same user are serialized, so together they
+	// never exceed the limit.
+	CreateWithinLimit(ctx context.Context, workspace *Workspace, limit int) (bool, error)
 	Update(ctx context.Context, workspace *Workspace) error
 	Delete(ctx context.Context, id uint) error
 	FindByID(ctx context.Context, id uint) (*Workspace, error)
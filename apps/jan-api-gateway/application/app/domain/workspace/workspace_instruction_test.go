@@ -0,0 +1,75 @@
+package workspace_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/domain/workspace"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// updateRepository accepts every update.
+type updateRepository struct {
+	limitedRepository
+	updates int
+}
+
+func (r *updateRepository) Update(ctx context.Context, ws *workspace.Workspace) error {
+	r.updates++
+	return nil
+}
+
+func TestWorkspaceInstructionLength(t *testing.T) {
+	ctx := context.Background()
+	previous := environment_variables.EnvironmentVariables.MAX_WORKSPACE_INSTRUCTION_LENGTH
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MAX_WORKSPACE_INSTRUCTION_LENGTH = previous
+	})
+
+	tests := []struct {
+		name        string
+		limit       int
+		instruction string
+		tooLong     bool
+	}{
+		// "é" is two bytes, so a byte count would reject these at the limit.
+		{name: "at the limit", limit: 10, instruction: strings.Repeat("é", 10)},
+		{name: "one over the limit", limit: 10, instruction: strings.Repeat("é", 11), tooLong: true},
+		{name: "at the default limit", instruction: strings.Repeat("a", workspace.DefaultMaxWorkspaceInstructionLength)},
+		{name: "over the default limit", instruction: strings.Repeat("a", workspace.DefaultMaxWorkspaceInstructionLength+1), tooLong: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environment_variables.EnvironmentVariables.MAX_WORKSPACE_INSTRUCTION_LENGTH = tt.limit
+			repo := &updateRepository{limitedRepository: limitedRepository{byUser: map[uint]int{}}}
+			service := workspace.NewWorkspaceService(repo, nil)
+			check := func(operation string, err *common.Error, stored bool) {
+				t.Helper()
+				if tt.tooLong {
+					if err == nil || err.GetCode() != workspace.ErrCodeWorkspaceInstructionTooLong {
+						t.Fatalf("%s error = %v, want instruction too long", operation, err)
+					}
+					if stored {
+						t.Fatalf("%s stored an instruction over the limit", operation)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", operation, err)
+				}
+			}
+
+			instruction := tt.instruction
+			_, createErr := service.CreateWorkspace(ctx, &workspace.Workspace{UserID: 7, Name: "ws", Instruction: &instruction})
+			check("CreateWorkspace", createErr, repo.byUser[7] > 0)
+
+			ws := &workspace.Workspace{ID: 1, UserID: 7, Name: "ws"}
+			// Surrounding whitespace is trimmed before the length is checked.
+			padded := "  " + tt.instruction + "\n"
+			_, updateErr := service.UpdateWorkspaceInstruction(ctx, ws, &padded)
+			check("UpdateWorkspaceInstruction", updateErr, repo.updates > 0 || ws.Instruction != nil)
+		})
+	}
+}
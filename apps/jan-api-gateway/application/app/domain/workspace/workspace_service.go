@@ -2,8 +2,11 @@ package workspace
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,6 +16,7 @@ import (
 	"menlo.ai/jan-api-gateway/app/domain/query"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
 	"menlo.ai/jan-api-gateway/app/utils/idgen"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
 )
 
 type WorkspaceContextKey string
@@ -22,6 +26,21 @@ const (
 	WorkspaceContextEntity      WorkspaceContextKey = "WorkspaceContextEntity"
 )
 
+// DefaultMaxWorkspacesPerUser caps the workspaces of a user when MAX_WORKSPACES_PER_USER is unset.
+const DefaultMaxWorkspacesPerUser = 100
+
+// ErrCodeWorkspaceLimitReached is returned when a user who already owns the maximum number of
+// workspaces creates another one.
+const ErrCodeWorkspaceLimitReached = "b4e8a2d6-7c3f-4a91-9d05-e1c7f3b9a264"
+
+// DefaultMaxWorkspaceInstructionLength caps workspace instructions, in runes, when
+// MAX_WORKSPACE_INSTRUCTION_LENGTH is unset.
+const DefaultMaxWorkspaceInstructionLength = 8000
+
+// ErrCodeWorkspaceInstructionTooLong is returned when a workspace instruction exceeds the maximum
+// length.
+const ErrCodeWorkspaceInstructionTooLong = "6f1c9e3a-8b4d-4d27-a5f0-c2e7b9d4a158"
+
 type WorkspaceService struct {
 	repo             WorkspaceRepository
 	conversationRepo conversation.ConversationRepository
@@ -42,6 +61,45 @@ func (s *WorkspaceService) FindWorkspacesByFilter(ctx context.Context, filter Wo
 	return workspaces, nil
 }
 
+// CountWorkspacesByFilter counts the workspaces matching the filter.
+func (s *WorkspaceService) CountWorkspacesByFilter(ctx context.Context, filter WorkspaceFilter) (int64, *common.Error) {
+	count, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		return 0, common.NewError(err, "f2b8d4a6-9c1e-4e73-a5d0-6b3f7c9e2d18")
+	}
+	return count, nil
+}
+
+// ListWithConversationCounts lists the user's workspaces together with their conversation counts,
+// which are loaded with one query for the whole page.
+func (s *WorkspaceService) ListWithConversationCounts(ctx context.Context, userID uint, pagination *query.Pagination) ([]*WorkspaceWithConversationCount, *common.Error) {
+	workspaces, err := s.repo.FindByFilter(ctx, WorkspaceFilter{UserID: &userID}, pagination)
+	if err != nil {
+		return nil, common.NewError(err, "a1d7c3e9-6b2f-4f58-8e04-d9c5b1a7f362")
+	}
+
+	publicIDs := make([]string, 0, len(workspaces))
+	for _, ws := range workspaces {
+		publicIDs = append(publicIDs, ws.PublicID)
+	}
+	counts := map[string]int64{}
+	if s.conversationRepo != nil && len(publicIDs) > 0 {
+		counts, err = s.conversationRepo.CountByWorkspacePublicIDs(ctx, publicIDs)
+		if err != nil {
+			return nil, common.NewError(err, "7e4b9f2c-3a8d-4c61-b0e5-f6a2d8c4b973")
+		}
+	}
+
+	result := make([]*WorkspaceWithConversationCount, 0, len(workspaces))
+	for _, ws := range workspaces {
+		result = append(result, &WorkspaceWithConversationCount{
+			Workspace:         ws,
+			ConversationCount: counts[ws.PublicID],
+		})
+	}
+	return result, nil
+}
+
 func (s *WorkspaceService) CreateWorkspace(ctx context.Context, workspace *Workspace) (*Workspace, *common.Error) {
 
 	publicID, err := idgen.GenerateSecureID("ws", 24)
@@ -54,10 +112,18 @@ func (s *WorkspaceService) CreateWorkspace(ctx context.Context, workspace *Works
 	if err := workspace.Normalize(); err != nil {
 		return nil, common.NewError(err, "26f0e93a-ff64-443f-8221-d18d36280336")
 	}
+	if err := validateInstruction(workspace.Instruction); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.Create(ctx, workspace); err != nil {
+	limit := maxWorkspacesPerUser()
+	created, err := s.repo.CreateWithinLimit(ctx, workspace, limit)
+	if err != nil {
 		return nil, common.NewError(err, "7ef72c57-90f8-4d59-8d08-2b2edf61d8da")
 	}
+	if !created {
+		return nil, common.NewErrorWithMessage(fmt.Sprintf("workspace limit of %d reached", limit), ErrCodeWorkspaceLimitReached)
+	}
 
 	return workspace, nil
 }
@@ -95,7 +161,11 @@ func (s *WorkspaceService) UpdateWorkspaceName(ctx context.Context, workspace *W
 }
 
 func (s *WorkspaceService) UpdateWorkspaceInstruction(ctx context.Context, workspace *Workspace, instruction *string) (*Workspace, *common.Error) {
-	workspace.Instruction = sanitizeInstruction(instruction)
+	sanitized := sanitizeInstruction(instruction)
+	if err := validateInstruction(sanitized); err != nil {
+		return nil, err
+	}
+	workspace.Instruction = sanitized
 	if err := s.repo.Update(ctx, workspace); err != nil {
 		return nil, common.NewError(err, "1c59f37a-56fa-4f64-9d8c-8a6c99b2e3ee")
 	}
@@ -120,6 +190,36 @@ func (s *WorkspaceService) DeleteWorkspaceWithConversations(ctx context.Context,
 	return nil
 }
 
+// DeleteAllForUser deletes every workspace of the user together with its conversations and
+// returns how many workspaces were removed. A workspace whose conversations fail to delete is
+// kept and the remaining ones are still processed; the failures are returned together.
+func (s *WorkspaceService) DeleteAllForUser(ctx context.Context, userID uint) (int, *common.Error) {
+	workspaces, err := s.repo.FindByFilter(ctx, WorkspaceFilter{UserID: &userID}, nil)
+	if err != nil {
+		return 0, common.NewError(err, "9b3e6d1f-4a7c-4e28-b5d0-c2f8a1e7d649")
+	}
+
+	deleted := 0
+	var failures []error
+	for _, ws := range workspaces {
+		if s.conversationRepo != nil {
+			if err := s.conversationRepo.DeleteByWorkspacePublicID(ctx, ws.PublicID); err != nil {
+				failures = append(failures, fmt.Errorf("workspace %s conversations: %w", ws.PublicID, err))
+				continue
+			}
+		}
+		if err := s.repo.Delete(ctx, ws.ID); err != nil {
+			failures = append(failures, fmt.Errorf("workspace %s: %w", ws.PublicID, err))
+			continue
+		}
+		deleted++
+	}
+	if len(failures) > 0 {
+		return deleted, common.NewError(fmt.Errorf("deleted %d of %d workspaces: %w", deleted, len(workspaces), errors.Join(failures...)), "e6a2c8f4-1d9b-4b57-a3e0-7f5d2c9b1a86")
+	}
+	return deleted, nil
+}
+
 func (s *WorkspaceService) GetWorkspaceMiddleware() gin.HandlerFunc {
 	return func(reqCtx *gin.Context) {
 		ctx := reqCtx.Request.Context()
@@ -159,6 +259,31 @@ func (s *WorkspaceService) GetWorkspaceMiddleware() gin.HandlerFunc {
 	}
 }
 
+// maxWorkspacesPerUser returns MAX_WORKSPACES_PER_USER, falling back to the default when it is
+// unset or not positive.
+func maxWorkspacesPerUser() int {
+	if limit := environment_variables.EnvironmentVariables.MAX_WORKSPACES_PER_USER; limit > 0 {
+		return limit
+	}
+	return DefaultMaxWorkspacesPerUser
+}
+
+// validateInstruction rejects instructions longer than MAX_WORKSPACE_INSTRUCTION_LENGTH runes, as
+// they are later sent as system prompts.
+func validateInstruction(instruction *string) *common.Error {
+	if instruction == nil {
+		return nil
+	}
+	limit := environment_variables.EnvironmentVariables.MAX_WORKSPACE_INSTRUCTION_LENGTH
+	if limit <= 0 {
+		limit = DefaultMaxWorkspaceInstructionLength
+	}
+	if utf8.RuneCountInString(*instruction) > limit {
+		return common.NewErrorWithMessage(fmt.Sprintf("workspace instruction is too long, the maximum is %d characters", limit), ErrCodeWorkspaceInstructionTooLong)
+	}
+	return nil
+}
+
 func sanitizeInstruction(instruction *string) *string {
 	if instruction == nil {
 		return nil
@@ -0,0 +1,81 @@
+package workspace_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/domain/workspace"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// limitedRepository serializes CreateWithinLimit the way the database does by locking the user row.
+type limitedRepository struct {
+	workspace.WorkspaceRepository
+	mu      sync.Mutex
+	byUser  map[uint]int
+	failErr error
+}
+
+func (r *limitedRepository) CreateWithinLimit(ctx context.Context, ws *workspace.Workspace, limit int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failErr != nil {
+		return false, r.failErr
+	}
+	if r.byUser[ws.UserID] >= limit {
+		return false, nil
+	}
+	r.byUser[ws.UserID]++
+	ws.ID = uint(r.byUser[ws.UserID])
+	return true, nil
+}
+
+func TestCreateWorkspaceLimit(t *testing.T) {
+	ctx := context.Background()
+	previous := environment_variables.EnvironmentVariables.MAX_WORKSPACES_PER_USER
+	environment_variables.EnvironmentVariables.MAX_WORKSPACES_PER_USER = 3
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MAX_WORKSPACES_PER_USER = previous
+	})
+
+	t.Run("concurrent creations stop at the limit", func(t *testing.T) {
+		repo := &limitedRepository{byUser: map[uint]int{}}
+		service := workspace.NewWorkspaceService(repo, nil)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		created, limited := 0, 0
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := service.CreateWorkspace(ctx, &workspace.Workspace{UserID: 7, Name: "ws"})
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					created++
+				case err.GetCode() == workspace.ErrCodeWorkspaceLimitReached:
+					limited++
+				default:
+					t.Errorf("CreateWorkspace: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if created != 3 || limited != 7 {
+			t.Fatalf("created %d and limited %d workspaces, want 3 and 7", created, limited)
+		}
+	})
+
+	t.Run("repository errors are not reported as the limit", func(t *testing.T) {
+		repo := &limitedRepository{byUser: map[uint]int{}, failErr: errors.New("connection reset")}
+		service := workspace.NewWorkspaceService(repo, nil)
+		_, err := service.CreateWorkspace(ctx, &workspace.Workspace{UserID: 7, Name: "ws"})
+		if err == nil || err.GetCode() == workspace.ErrCodeWorkspaceLimitReached {
+			t.Fatalf("CreateWorkspace error = %v, want a repository error", err)
+		}
+	})
+}
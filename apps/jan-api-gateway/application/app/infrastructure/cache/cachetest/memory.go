@@ -0,0 +1,205 @@
+// Package cachetest provides an in-memory Redis for tests of code that uses the cache service.
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
+)
+
+// Store holds the values of an in-memory Redis. It serves the commands the cache service uses for
+// plain string values and counters. EXPIREAT deadlines are recorded for ExpireAt; no expiration
+// is ever applied.
+type Store struct {
+	mu       sync.Mutex
+	values   map[string]string
+	expireAt map[string]time.Time
+}
+
+// NewCache returns a cache service backed by a fresh in-memory store that never opens a connection.
+func NewCache() (*cache.RedisCacheService, *Store) {
+	store := &Store{values: map[string]string{}, expireAt: map[string]time.Time{}}
+	client := redis.NewClient(&redis.Options{Addr: "cachetest:6379"})
+	client.AddHook(store)
+	return cache.NewRedisCacheServiceWithClient(client), store
+}
+
+// Set stores a raw value, for example a corrupt one.
+func (s *Store) Set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Get returns the raw value stored at key.
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// ExpireAt returns the deadline last set on key with EXPIREAT.
+func (s *Store) ExpireAt(key string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deadline, ok := s.expireAt[key]
+	return deadline, ok
+}
+
+// Keys returns the stored keys in order.
+func (s *Store) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Store answers every command itself, so the client never connects.
+var _ redis.Hook = (*Store)(nil)
+
+func (s *Store) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, fmt.Errorf("cachetest: the in-memory store does not dial %s", addr)
+	}
+}
+
+func (s *Store) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return s.process(cmd)
+	}
+}
+
+func (s *Store) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := s.process(cmd); err != nil && err != redis.Nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func (s *Store) process(cmd redis.Cmder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	args := cmd.Args()
+	switch c := cmd.(type) {
+	case *redis.StringCmd:
+		if cmd.Name() == "get" {
+			value, ok := s.values[fmt.Sprint(args[1])]
+			if !ok {
+				c.SetErr(redis.Nil)
+				return redis.Nil
+			}
+			c.SetVal(value)
+			return nil
+		}
+	case *redis.StatusCmd:
+		switch cmd.Name() {
+		case "set":
+			s.values[fmt.Sprint(args[1])] = fmt.Sprint(args[2])
+			c.SetVal("OK")
+			return nil
+		case "ping":
+			c.SetVal("PONG")
+			return nil
+		case "multi":
+			c.SetVal("OK")
+			return nil
+		}
+	case *redis.SliceCmd:
+		if cmd.Name() == "exec" {
+			return nil
+		}
+	case *redis.BoolCmd:
+		if cmd.Name() == "expireat" {
+			key := fmt.Sprint(args[1])
+			seconds, err := strconv.ParseInt(fmt.Sprint(args[2]), 10, 64)
+			if err != nil {
+				c.SetErr(err)
+				return err
+			}
+			_, ok := s.values[key]
+			if ok {
+				s.expireAt[key] = time.Unix(seconds, 0).UTC()
+			}
+			c.SetVal(ok)
+			return nil
+		}
+	case *redis.IntCmd:
+		switch cmd.Name() {
+		case "del", "unlink", "exists":
+			var n int64
+			for _, arg := range args[1:] {
+				key := fmt.Sprint(arg)
+				if _, ok := s.values[key]; ok {
+					n++
+					if cmd.Name() != "exists" {
+						delete(s.values, key)
+						delete(s.expireAt, key)
+					}
+				}
+			}
+			c.SetVal(n)
+			return nil
+		case "incrby":
+			key := fmt.Sprint(args[1])
+			current, err := strconv.ParseInt(s.valueOr(key, "0"), 10, 64)
+			if err != nil {
+				c.SetErr(err)
+				return err
+			}
+			delta, err := strconv.ParseInt(fmt.Sprint(args[2]), 10, 64)
+			if err != nil {
+				c.SetErr(err)
+				return err
+			}
+			s.values[key] = strconv.FormatInt(current+delta, 10)
+			c.SetVal(current + delta)
+			return nil
+		}
+	case *redis.ScanCmd:
+		if cmd.Name() == "scan" {
+			pattern := "*"
+			for i := 2; i+1 < len(args); i++ {
+				if strings.EqualFold(fmt.Sprint(args[i]), "match") {
+					pattern = fmt.Sprint(args[i+1])
+				}
+			}
+			var keys []string
+			for key := range s.values {
+				if matched, _ := path.Match(pattern, key); matched {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			c.SetVal(keys, 0)
+			return nil
+		}
+	}
+	err := fmt.Errorf("cachetest: unsupported command %v", args)
+	cmd.SetErr(err)
+	return err
+}
+
+func (s *Store) valueOr(key, fallback string) string {
+	if value, ok := s.values[key]; ok {
+		return value
+	}
+	return fallback
+}
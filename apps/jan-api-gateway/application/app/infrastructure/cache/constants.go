@@ -6,4 +6,28 @@ const (
 
 	// UserByPublicIDKey is the cache key template for user lookups by public ID.
 	UserByPublicIDKey = CacheVersion + ":user:public_id:%s"
+
+	// OrganizationMonthlySpendKey is the cache key template for an organization's spend in a UTC month (YYYY-MM).
+	OrganizationMonthlySpendKey = CacheVersion + ":spend:organization:%d:%s"
+
+	// ProjectMonthlySpendKey is the cache key template for a project's spend in a UTC month (YYYY-MM).
+	ProjectMonthlySpendKey = CacheVersion + ":spend:project:%d:%s"
+
+	// AccessibleProviderModelsKey is the cache key template for the IDs of the providers accessible
+	// to an organization and comma-separated sorted project IDs, with the model keys each one serves.
+	AccessibleProviderModelsKey = CacheVersion + ":providers:accessible:ids:organization:%d:projects:%s"
+
+	// AccessibleProviderModelsPattern matches every accessible-provider cache entry.
+	AccessibleProviderModelsPattern = CacheVersion + ":providers:accessible:*"
+
+	// ModelRateLimitKey is the cache key template for an organization's token bucket for a model key.
+	ModelRateLimitKey = CacheVersion + ":ratelimit:organization:%d:model:%s"
+
+	// ProviderRegistrationIdempotencyKey is the cache key template for the stored response of a
+	// provider registration, by organization and hashed Idempotency-Key header.
+	ProviderRegistrationIdempotencyKey = CacheVersion + ":idempotency:providers:organization:%d:%s"
+
+	// ProviderFallbackFailuresKey is the cache key template for the number of requests that fell
+	// back to a default provider because the provider with the given ID was unavailable.
+	ProviderFallbackFailuresKey = CacheVersion + ":providers:fallback_failures:%d"
 )
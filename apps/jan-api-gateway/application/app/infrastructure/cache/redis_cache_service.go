@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -53,11 +54,14 @@ func NewRedisCacheService() *RedisCacheService {
 
 	logger.GetLogger().Info("Successfully connected to Redis")
 
-	rs := redsync.New(goredis.NewPool(client))
+	return NewRedisCacheServiceWithClient(client)
+}
 
+// NewRedisCacheServiceWithClient wraps a client that is already configured and connected.
+func NewRedisCacheServiceWithClient(client redis.UniversalClient) *RedisCacheService {
 	return &RedisCacheService{
 		client: client,
-		rs:     rs,
+		rs:     redsync.New(goredis.NewPool(client)),
 	}
 }
 
@@ -142,6 +146,24 @@ func (r *RedisCacheService) Get(ctx context.Context, key string) (string, error)
 	return val, nil
 }
 
+// GetJSON decodes the JSON value stored at key into v and reports whether it did. A value that
+// fails to decode is corrupt: it is logged, deleted so the caller's rebuild can replace it, and
+// reported as a miss.
+func (r *RedisCacheService) GetJSON(ctx context.Context, key string, v any) bool {
+	cached, err := r.Get(ctx, key)
+	if err != nil || cached == "" {
+		return false
+	}
+	if err := json.Unmarshal([]byte(cached), v); err != nil {
+		logger.GetLogger().Warnf("deleting corrupt cache entry %s: %v", key, err)
+		if delErr := r.Delete(ctx, key); delErr != nil {
+			logger.GetLogger().Errorf("failed to delete corrupt cache entry %s: %v", key, delErr)
+		}
+		return false
+	}
+	return true
+}
+
 func (r *RedisCacheService) GetWithFallback(ctx context.Context, key string, fallback func() (string, error), expiration time.Duration) (string, error) {
 	result, err := r.Get(ctx, key)
 	if err == nil {
@@ -160,6 +182,74 @@ func (r *RedisCacheService) GetWithFallback(ctx context.Context, key string, fal
 	return result, nil
 }
 
+// IncrBy atomically adds value to the integer stored at key and makes the key expire at expireAt.
+// It returns the new value.
+func (r *RedisCacheService) IncrBy(ctx context.Context, key string, value int64, expireAt time.Time) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, value)
+	pipe.ExpireAt(ctx, key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment value: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// tokenBucketScript refills the bucket at KEYS[1] for the time elapsed since it was last touched,
+// using the Redis clock so replicas agree, then takes one token if available. It returns
+// {1, 0} when a token was taken and {0, wait_ms} otherwise.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate) + 1000)
+return {allowed, wait}
+`)
+
+// TakeToken takes one token from the token bucket at key, which holds up to capacity tokens and
+// refills at refillPerMinute. When the bucket is empty it returns false and how long until the
+// next token is available.
+func (r *RedisCacheService) TakeToken(ctx context.Context, key string, capacity int64, refillPerMinute int64) (bool, time.Duration, error) {
+	rate := float64(refillPerMinute) / float64(time.Minute/time.Millisecond)
+	result, err := tokenBucketScript.Run(ctx, r.client, []string{key}, capacity, rate).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to take token: %w", err)
+	}
+	if len(result) != 2 {
+		return false, 0, fmt.Errorf("failed to take token: unexpected script result %v", result)
+	}
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// GetInt64 returns the integer stored at key, or zero when the key does not exist.
+func (r *RedisCacheService) GetInt64(ctx context.Context, key string) (int64, error) {
+	val, err := r.client.Get(ctx, key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get value: %w", err)
+	}
+	return val, nil
+}
+
 func (r *RedisCacheService) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
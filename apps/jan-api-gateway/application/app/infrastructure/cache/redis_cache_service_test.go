@@ -0,0 +1,47 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"menlo.ai/jan-api-gateway/app/infrastructure/cache/cachetest"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+func TestGetJSON(t *testing.T) {
+	ctx := context.Background()
+	type entry struct {
+		IDs []uint `json:"ids"`
+	}
+
+	tests := []struct {
+		name   string
+		stored *string
+		found  bool
+		kept   bool
+	}{
+		{name: "valid value", stored: ptr.ToString(`{"ids":[1,2]}`), found: true, kept: true},
+		{name: "missing key"},
+		{name: "truncated value", stored: ptr.ToString(`{"ids":[1,`)},
+		{name: "value of another shape", stored: ptr.ToString(`{"ids":"all"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store := cachetest.NewCache()
+			if tt.stored != nil {
+				store.Set("jan:test", *tt.stored)
+			}
+
+			var got entry
+			if found := service.GetJSON(ctx, "jan:test", &got); found != tt.found {
+				t.Fatalf("GetJSON = %v, want %v", found, tt.found)
+			}
+			if tt.found && len(got.IDs) != 2 {
+				t.Fatalf("decoded %+v, want two IDs", got)
+			}
+			if _, ok := store.Get("jan:test"); ok != tt.kept {
+				t.Fatalf("key kept = %v, want %v; corrupt entries must be deleted", ok, tt.kept)
+			}
+		})
+	}
+}
@@ -33,6 +33,7 @@ type Item struct {
 	PublicID          string       `gorm:"type:varchar(50);uniqueIndex;not null"`
 	ConversationID    uint         `gorm:"not null;index"`
 	ResponseID        *uint        `gorm:"index"`
+	ParentItemID      *uint        `gorm:"index"`
 	Type              string       `gorm:"type:varchar(50);not null;index"`
 	Role              string       `gorm:"type:varchar(20);index"`
 	Content           string       `gorm:"type:text"`
@@ -113,6 +114,7 @@ func NewSchemaItem(i *conversation.Item) *Item {
 		PublicID:          i.PublicID,
 		ConversationID:    i.ConversationID,
 		ResponseID:        i.ResponseID,
+		ParentItemID:      i.ParentItemID,
 		Type:              string(i.Type),
 		Role:              string(*i.Role),
 		Content:           contentJSON,
@@ -149,6 +151,7 @@ func (i *Item) EtoD() *conversation.Item {
 		CompletedAt:       i.CompletedAt,
 		ConversationID:    i.ConversationID,
 		ResponseID:        i.ResponseID,
+		ParentItemID:      i.ParentItemID,
 		CreatedAt:         i.CreatedAt,
 	}
 }
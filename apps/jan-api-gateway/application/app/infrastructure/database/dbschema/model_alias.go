@@ -0,0 +1,55 @@
+package dbschema
+
+import (
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/infrastructure/database"
+)
+
+func init() {
+	database.RegisterSchemaForAutoMigrate(ModelAlias{})
+}
+
+// ModelAlias represents the model_aliases table in the database.
+type ModelAlias struct {
+	BaseModel
+	PublicID       string `gorm:"size:64;not null;uniqueIndex"`
+	OrganizationID uint   `gorm:"not null;index:idx_model_alias_org_alias"`
+	Alias          string `gorm:"size:128;not null;index:idx_model_alias_org_alias"`
+	ProviderID     uint   `gorm:"not null;index"`
+	ModelKey       string `gorm:"size:255;not null"`
+}
+
+// TableName enforces snake_case table naming.
+func (ModelAlias) TableName() string {
+	return "model_aliases"
+}
+
+// NewSchemaModelAlias converts a domain model alias into its database representation.
+func NewSchemaModelAlias(a *domainmodel.ModelAlias) *ModelAlias {
+	return &ModelAlias{
+		BaseModel: BaseModel{
+			ID:        a.ID,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+		},
+		PublicID:       a.PublicID,
+		OrganizationID: a.OrganizationID,
+		Alias:          a.Alias,
+		ProviderID:     a.ProviderID,
+		ModelKey:       a.ModelKey,
+	}
+}
+
+// EtoD converts a database model alias into its domain representation.
+func (a *ModelAlias) EtoD() *domainmodel.ModelAlias {
+	return &domainmodel.ModelAlias{
+		ID:             a.ID,
+		PublicID:       a.PublicID,
+		OrganizationID: a.OrganizationID,
+		Alias:          a.Alias,
+		ProviderID:     a.ProviderID,
+		ModelKey:       a.ModelKey,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
@@ -2,6 +2,7 @@ package dbschema
 
 import (
 	"encoding/json"
+	"time"
 
 	"gorm.io/datatypes"
 
@@ -24,6 +25,8 @@ type ModelCatalog struct {
 	IsModerated         *bool
 	Status              string         `gorm:"size:32;not null;default:'init'"`
 	Extras              datatypes.JSON `gorm:"type:jsonb"`
+	DeprecatedAt        *time.Time
+	DeprecationReason   *string `gorm:"type:text"`
 }
 
 // TableName enforces snake_case table naming.
@@ -84,6 +87,8 @@ func NewSchemaModelCatalog(m *domainmodel.ModelCatalog) (*ModelCatalog, error) {
 		IsModerated:         m.IsModerated,
 		Status:              status,
 		Extras:              extrasJSON,
+		DeprecatedAt:        m.DeprecatedAt,
+		DeprecationReason:   m.DeprecationReason,
 	}, nil
 }
 
@@ -126,6 +131,8 @@ func (m *ModelCatalog) EtoD() (*domainmodel.ModelCatalog, error) {
 		Tags:                tags,
 		Notes:               m.Notes,
 		IsModerated:         m.IsModerated,
+		DeprecatedAt:        m.DeprecatedAt,
+		DeprecationReason:   m.DeprecationReason,
 		Extras:              extras,
 		Status: func() domainmodel.ModelCatalogStatus {
 			status := domainmodel.ModelCatalogStatus(m.Status)
@@ -12,10 +12,14 @@ func init() {
 
 type Organization struct {
 	BaseModel
-	Name     string               `gorm:"size:128;not null"`
-	PublicID string               `gorm:"size:64;not null;uniqueIndex"`
-	Enabled  bool                 `gorm:"default:true;index"`
-	Members  []OrganizationMember `gorm:"foreignKey:OrganizationID"`
+	Name                      string `gorm:"size:128;not null"`
+	PublicID                  string `gorm:"size:64;not null;uniqueIndex"`
+	Enabled                   bool   `gorm:"default:true;index"`
+	MonthlySpendLimitMicroUSD *int64
+	DefaultProviderID         *uint
+	WebhookURL                *string              `gorm:"size:2048"`
+	EncryptedWebhookSecret    string               `gorm:"type:text"`
+	Members                   []OrganizationMember `gorm:"foreignKey:OrganizationID"`
 }
 
 type OrganizationMember struct {
@@ -30,9 +34,13 @@ func NewSchemaOrganization(o *organization.Organization) *Organization {
 		BaseModel: BaseModel{
 			ID: o.ID,
 		},
-		Name:     o.Name,
-		PublicID: o.PublicID,
-		Enabled:  o.Enabled,
+		Name:                      o.Name,
+		PublicID:                  o.PublicID,
+		Enabled:                   o.Enabled,
+		MonthlySpendLimitMicroUSD: o.MonthlySpendLimitMicroUSD,
+		DefaultProviderID:         o.DefaultProviderID,
+		WebhookURL:                o.WebhookURL,
+		EncryptedWebhookSecret:    o.EncryptedWebhookSecret,
 	}
 }
 
@@ -49,12 +57,16 @@ func NewSchemaOrganizationMember(o *organization.OrganizationMember) *Organizati
 
 func (o *Organization) EtoD() *organization.Organization {
 	return &organization.Organization{
-		ID:        o.ID,
-		Name:      o.Name,
-		PublicID:  o.PublicID,
-		Enabled:   o.Enabled,
-		CreatedAt: o.CreatedAt,
-		UpdatedAt: o.UpdatedAt,
+		ID:                        o.ID,
+		Name:                      o.Name,
+		PublicID:                  o.PublicID,
+		Enabled:                   o.Enabled,
+		MonthlySpendLimitMicroUSD: o.MonthlySpendLimitMicroUSD,
+		DefaultProviderID:         o.DefaultProviderID,
+		WebhookURL:                o.WebhookURL,
+		EncryptedWebhookSecret:    o.EncryptedWebhookSecret,
+		CreatedAt:                 o.CreatedAt,
+		UpdatedAt:                 o.UpdatedAt,
 	}
 }
 
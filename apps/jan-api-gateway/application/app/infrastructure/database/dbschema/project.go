@@ -1,8 +1,10 @@
 package dbschema
 
 import (
+	"encoding/json"
 	"time"
 
+	"gorm.io/datatypes"
 	"menlo.ai/jan-api-gateway/app/domain/project"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database"
 )
@@ -14,12 +16,14 @@ func init() {
 
 type Project struct {
 	BaseModel
-	Name           string          `gorm:"size:128;not null"`
-	PublicID       string          `gorm:"type:varchar(50);uniqueIndex;not null"`
-	Status         string          `gorm:"type:varchar(20);not null;default:'active';index"`
-	OrganizationID uint            `gorm:"not null;index"`
-	ArchivedAt     *time.Time      `gorm:"column:archived_at;index"`
-	Members        []ProjectMember `gorm:"foreignKey:ProjectID"`
+	Name                      string     `gorm:"size:128;not null"`
+	PublicID                  string     `gorm:"type:varchar(50);uniqueIndex;not null"`
+	Status                    string     `gorm:"type:varchar(20);not null;default:'active';index"`
+	OrganizationID            uint       `gorm:"not null;index"`
+	ArchivedAt                *time.Time `gorm:"column:archived_at;index"`
+	MonthlySpendLimitMicroUSD *int64
+	ModelPolicy               datatypes.JSON  `gorm:"type:jsonb"`
+	Members                   []ProjectMember `gorm:"foreignKey:ProjectID"`
 }
 
 type ProjectMemberRole string
@@ -46,28 +50,45 @@ func (p *ProjectMember) EtoD() *project.ProjectMember {
 }
 
 func NewSchemaProject(p *project.Project) *Project {
+	var modelPolicyJSON datatypes.JSON
+	if p.ModelPolicy != nil {
+		if data, err := json.Marshal(p.ModelPolicy); err == nil {
+			modelPolicyJSON = datatypes.JSON(data)
+		}
+	}
 	return &Project{
 		BaseModel: BaseModel{
 			ID: p.ID,
 		},
-		Name:           p.Name,
-		PublicID:       p.PublicID,
-		Status:         p.Status,
-		ArchivedAt:     p.ArchivedAt,
-		OrganizationID: p.OrganizationID,
+		Name:                      p.Name,
+		PublicID:                  p.PublicID,
+		Status:                    p.Status,
+		ArchivedAt:                p.ArchivedAt,
+		OrganizationID:            p.OrganizationID,
+		MonthlySpendLimitMicroUSD: p.MonthlySpendLimitMicroUSD,
+		ModelPolicy:               modelPolicyJSON,
 	}
 }
 
 func (p *Project) EtoD() *project.Project {
+	var modelPolicy *project.ProjectModelPolicy
+	if len(p.ModelPolicy) > 0 {
+		var policy project.ProjectModelPolicy
+		if err := json.Unmarshal(p.ModelPolicy, &policy); err == nil {
+			modelPolicy = &policy
+		}
+	}
 	return &project.Project{
-		ID:             p.ID,
-		Name:           p.Name,
-		PublicID:       p.PublicID,
-		ArchivedAt:     p.ArchivedAt,
-		Status:         p.Status,
-		OrganizationID: p.OrganizationID,
-		CreatedAt:      p.CreatedAt,
-		UpdatedAt:      p.UpdatedAt,
+		ID:                        p.ID,
+		Name:                      p.Name,
+		PublicID:                  p.PublicID,
+		ArchivedAt:                p.ArchivedAt,
+		Status:                    p.Status,
+		OrganizationID:            p.OrganizationID,
+		MonthlySpendLimitMicroUSD: p.MonthlySpendLimitMicroUSD,
+		ModelPolicy:               modelPolicy,
+		CreatedAt:                 p.CreatedAt,
+		UpdatedAt:                 p.UpdatedAt,
 	}
 }
 
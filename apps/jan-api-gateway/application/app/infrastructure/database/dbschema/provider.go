@@ -16,19 +16,30 @@ func init() {
 // Provider represents the providers table in the database.
 type Provider struct {
 	BaseModel
-	PublicID        string         `gorm:"size:64;not null;uniqueIndex"`
-	Slug            string         `gorm:"size:128;not null;uniqueIndex"`
-	OrganizationID  *uint          `gorm:"index"`
-	ProjectID       *uint          `gorm:"index"`
-	DisplayName     string         `gorm:"size:255;not null"`
-	Kind            string         `gorm:"size:64;not null;index"`
-	BaseURL         string         `gorm:"size:512"`
-	EncryptedAPIKey string         `gorm:"type:text"`
-	APIKeyHint      *string        `gorm:"size:128"`
-	IsModerated     bool           `gorm:"not null;default:false"`
-	Active          bool           `gorm:"not null;default:true"`
-	Metadata        datatypes.JSON `gorm:"type:jsonb"`
-	LastSyncedAt    *time.Time
+	PublicID           string         `gorm:"size:64;not null;uniqueIndex"`
+	Slug               string         `gorm:"size:128;not null;uniqueIndex"`
+	OrganizationID     *uint          `gorm:"index"`
+	ProjectID          *uint          `gorm:"index"`
+	DisplayName        string         `gorm:"size:255;not null"`
+	Kind               string         `gorm:"size:64;not null;index"`
+	BaseURL            string         `gorm:"size:512"`
+	PathPrefix         string         `gorm:"size:256"`
+	EncryptedAPIKey    string         `gorm:"type:text"`
+	APIKeyHint         *string        `gorm:"size:128"`
+	IsModerated        bool           `gorm:"not null;default:false"`
+	Active             bool           `gorm:"not null;default:true"`
+	Metadata           datatypes.JSON `gorm:"type:jsonb"`
+	Headers            datatypes.JSON `gorm:"type:jsonb"`
+	ModelAllowlist     datatypes.JSON `gorm:"type:jsonb"`
+	ModelDenylist      datatypes.JSON `gorm:"type:jsonb"`
+	LastSyncedAt       *time.Time
+	KeyRotatedAt       *time.Time
+	PreviousAPIKeyHint *string `gorm:"size:128"`
+	Shadow             bool    `gorm:"not null;default:false"`
+	Priority           int     `gorm:"not null;default:0"`
+	LastHealthCheckAt  *time.Time
+	LastHealthError    *string `gorm:"type:text"`
+	KeyMode            string  `gorm:"size:16;not null;default:'stored'"`
 }
 
 // TableName enforces snake_case table naming.
@@ -44,6 +55,24 @@ func NewSchemaProvider(p *domainmodel.Provider) *Provider {
 			metadataJSON = datatypes.JSON(data)
 		}
 	}
+	var headersJSON datatypes.JSON
+	if len(p.Headers) > 0 {
+		if data, err := json.Marshal(p.Headers); err == nil {
+			headersJSON = datatypes.JSON(data)
+		}
+	}
+	var allowlistJSON datatypes.JSON
+	if len(p.ModelAllowlist) > 0 {
+		if data, err := json.Marshal(p.ModelAllowlist); err == nil {
+			allowlistJSON = datatypes.JSON(data)
+		}
+	}
+	var denylistJSON datatypes.JSON
+	if len(p.ModelDenylist) > 0 {
+		if data, err := json.Marshal(p.ModelDenylist); err == nil {
+			denylistJSON = datatypes.JSON(data)
+		}
+	}
 
 	return &Provider{
 		BaseModel: BaseModel{
@@ -51,19 +80,30 @@ func NewSchemaProvider(p *domainmodel.Provider) *Provider {
 			CreatedAt: p.CreatedAt,
 			UpdatedAt: p.UpdatedAt,
 		},
-		PublicID:        p.PublicID,
-		Slug:            p.Slug,
-		OrganizationID:  p.OrganizationID,
-		ProjectID:       p.ProjectID,
-		DisplayName:     p.DisplayName,
-		Kind:            string(p.Kind),
-		BaseURL:         p.BaseURL,
-		EncryptedAPIKey: p.EncryptedAPIKey,
-		APIKeyHint:      p.APIKeyHint,
-		IsModerated:     p.IsModerated,
-		Active:          p.Active,
-		Metadata:        metadataJSON,
-		LastSyncedAt:    p.LastSyncedAt,
+		PublicID:           p.PublicID,
+		Slug:               p.Slug,
+		OrganizationID:     p.OrganizationID,
+		ProjectID:          p.ProjectID,
+		DisplayName:        p.DisplayName,
+		Kind:               string(p.Kind),
+		BaseURL:            p.BaseURL,
+		PathPrefix:         p.PathPrefix,
+		EncryptedAPIKey:    p.EncryptedAPIKey,
+		APIKeyHint:         p.APIKeyHint,
+		IsModerated:        p.IsModerated,
+		Active:             p.Active,
+		Metadata:           metadataJSON,
+		Headers:            headersJSON,
+		ModelAllowlist:     allowlistJSON,
+		ModelDenylist:      denylistJSON,
+		LastSyncedAt:       p.LastSyncedAt,
+		KeyRotatedAt:       p.KeyRotatedAt,
+		PreviousAPIKeyHint: p.PreviousAPIKeyHint,
+		Shadow:             p.Shadow,
+		Priority:           p.Priority,
+		LastHealthCheckAt:  p.LastHealthCheckAt,
+		LastHealthError:    p.LastHealthError,
+		KeyMode:            string(p.KeyMode),
 	}
 }
 
@@ -73,23 +113,46 @@ func (p *Provider) EtoD() *domainmodel.Provider {
 	if len(p.Metadata) > 0 {
 		_ = json.Unmarshal(p.Metadata, &metadata)
 	}
+	var headers map[string]string
+	if len(p.Headers) > 0 {
+		_ = json.Unmarshal(p.Headers, &headers)
+	}
+	var allowlist []string
+	if len(p.ModelAllowlist) > 0 {
+		_ = json.Unmarshal(p.ModelAllowlist, &allowlist)
+	}
+	var denylist []string
+	if len(p.ModelDenylist) > 0 {
+		_ = json.Unmarshal(p.ModelDenylist, &denylist)
+	}
 
 	return &domainmodel.Provider{
-		ID:              p.ID,
-		PublicID:        p.PublicID,
-		Slug:            p.Slug,
-		OrganizationID:  p.OrganizationID,
-		ProjectID:       p.ProjectID,
-		DisplayName:     p.DisplayName,
-		Kind:            domainmodel.ProviderKind(p.Kind),
-		BaseURL:         p.BaseURL,
-		EncryptedAPIKey: p.EncryptedAPIKey,
-		APIKeyHint:      p.APIKeyHint,
-		IsModerated:     p.IsModerated,
-		Active:          p.Active,
-		Metadata:        metadata,
-		LastSyncedAt:    p.LastSyncedAt,
-		CreatedAt:       p.CreatedAt,
-		UpdatedAt:       p.UpdatedAt,
+		ID:                 p.ID,
+		PublicID:           p.PublicID,
+		Slug:               p.Slug,
+		OrganizationID:     p.OrganizationID,
+		ProjectID:          p.ProjectID,
+		DisplayName:        p.DisplayName,
+		Kind:               domainmodel.ProviderKind(p.Kind),
+		BaseURL:            p.BaseURL,
+		PathPrefix:         p.PathPrefix,
+		EncryptedAPIKey:    p.EncryptedAPIKey,
+		APIKeyHint:         p.APIKeyHint,
+		IsModerated:        p.IsModerated,
+		Active:             p.Active,
+		Metadata:           metadata,
+		Headers:            headers,
+		ModelAllowlist:     allowlist,
+		ModelDenylist:      denylist,
+		LastSyncedAt:       p.LastSyncedAt,
+		KeyRotatedAt:       p.KeyRotatedAt,
+		PreviousAPIKeyHint: p.PreviousAPIKeyHint,
+		Shadow:             p.Shadow,
+		Priority:           p.Priority,
+		LastHealthCheckAt:  p.LastHealthCheckAt,
+		LastHealthError:    p.LastHealthError,
+		KeyMode:            domainmodel.ProviderKeyMode(p.KeyMode),
+		CreatedAt:          p.CreatedAt,
+		UpdatedAt:          p.UpdatedAt,
 	}
 }
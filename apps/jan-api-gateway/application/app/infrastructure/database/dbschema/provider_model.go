@@ -22,12 +22,14 @@ type ProviderModel struct {
 	ModelKey           string         `gorm:"size:128;not null;uniqueIndex:ux_provider_model_key,priority:2"`
 	DisplayName        string         `gorm:"size:255;not null"`
 	Pricing            datatypes.JSON `gorm:"type:jsonb;not null"`
+	PriceOverride      datatypes.JSON `gorm:"type:jsonb"`
 	TokenLimits        datatypes.JSON `gorm:"type:jsonb"`
 	Family             *string        `gorm:"size:128"`
 	SupportsImages     bool           `gorm:"not null;default:false"`
 	SupportsEmbeddings bool           `gorm:"not null;default:false"`
 	SupportsReasoning  bool           `gorm:"not null;default:false"`
 	Active             bool           `gorm:"not null;default:true"`
+	Disabled           bool           `gorm:"not null;default:false"`
 }
 
 // TableName enforces snake_case table naming.
@@ -43,6 +45,15 @@ func NewSchemaProviderModel(m *domainmodel.ProviderModel) (*ProviderModel, error
 		return nil, err
 	}
 
+	var priceOverrideJSON datatypes.JSON
+	if m.PriceOverride != nil {
+		data, err := json.Marshal(m.PriceOverride)
+		if err != nil {
+			return nil, err
+		}
+		priceOverrideJSON = datatypes.JSON(data)
+	}
+
 	var tokenLimitsJSON datatypes.JSON
 	if m.TokenLimits != nil {
 		data, err := json.Marshal(m.TokenLimits)
@@ -64,12 +75,14 @@ func NewSchemaProviderModel(m *domainmodel.ProviderModel) (*ProviderModel, error
 		ModelKey:           m.ModelKey,
 		DisplayName:        m.DisplayName,
 		Pricing:            datatypes.JSON(pricingJSON),
+		PriceOverride:      priceOverrideJSON,
 		TokenLimits:        tokenLimitsJSON,
 		Family:             m.Family,
 		SupportsImages:     m.SupportsImages,
 		SupportsEmbeddings: m.SupportsEmbeddings,
 		SupportsReasoning:  m.SupportsReasoning,
 		Active:             m.Active,
+		Disabled:           m.Disabled,
 	}, nil
 }
 
@@ -82,6 +95,15 @@ func (m *ProviderModel) EtoD() (*domainmodel.ProviderModel, error) {
 		}
 	}
 
+	var priceOverride *domainmodel.Pricing
+	if len(m.PriceOverride) > 0 {
+		var override domainmodel.Pricing
+		if err := json.Unmarshal(m.PriceOverride, &override); err != nil {
+			return nil, err
+		}
+		priceOverride = &override
+	}
+
 	var tokenLimits *domainmodel.TokenLimits
 	if len(m.TokenLimits) > 0 {
 		var limits domainmodel.TokenLimits
@@ -99,12 +121,14 @@ func (m *ProviderModel) EtoD() (*domainmodel.ProviderModel, error) {
 		ModelKey:           m.ModelKey,
 		DisplayName:        m.DisplayName,
 		Pricing:            pricing,
+		PriceOverride:      priceOverride,
 		TokenLimits:        tokenLimits,
 		Family:             m.Family,
 		SupportsImages:     m.SupportsImages,
 		SupportsEmbeddings: m.SupportsEmbeddings,
 		SupportsReasoning:  m.SupportsReasoning,
 		Active:             m.Active,
+		Disabled:           m.Disabled,
 		CreatedAt:          m.CreatedAt,
 		UpdatedAt:          m.UpdatedAt,
 	}, nil
@@ -21,6 +21,7 @@ var (
 	Conversation       *conversation
 	Invite             *invite
 	Item               *item
+	ModelAlias         *modelAlias
 	ModelCatalog       *modelCatalog
 	Organization       *organization
 	OrganizationMember *organizationMember
@@ -39,6 +40,7 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	Conversation = &Q.Conversation
 	Invite = &Q.Invite
 	Item = &Q.Item
+	ModelAlias = &Q.ModelAlias
 	ModelCatalog = &Q.ModelCatalog
 	Organization = &Q.Organization
 	OrganizationMember = &Q.OrganizationMember
@@ -58,6 +60,7 @@ func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 		Conversation:       newConversation(db, opts...),
 		Invite:             newInvite(db, opts...),
 		Item:               newItem(db, opts...),
+		ModelAlias:         newModelAlias(db, opts...),
 		ModelCatalog:       newModelCatalog(db, opts...),
 		Organization:       newOrganization(db, opts...),
 		OrganizationMember: newOrganizationMember(db, opts...),
@@ -78,6 +81,7 @@ type Query struct {
 	Conversation       conversation
 	Invite             invite
 	Item               item
+	ModelAlias         modelAlias
 	ModelCatalog       modelCatalog
 	Organization       organization
 	OrganizationMember organizationMember
@@ -99,6 +103,7 @@ func (q *Query) clone(db *gorm.DB) *Query {
 		Conversation:       q.Conversation.clone(db),
 		Invite:             q.Invite.clone(db),
 		Item:               q.Item.clone(db),
+		ModelAlias:         q.ModelAlias.clone(db),
 		ModelCatalog:       q.ModelCatalog.clone(db),
 		Organization:       q.Organization.clone(db),
 		OrganizationMember: q.OrganizationMember.clone(db),
@@ -127,6 +132,7 @@ func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 		Conversation:       q.Conversation.replaceDB(db),
 		Invite:             q.Invite.replaceDB(db),
 		Item:               q.Item.replaceDB(db),
+		ModelAlias:         q.ModelAlias.replaceDB(db),
 		ModelCatalog:       q.ModelCatalog.replaceDB(db),
 		Organization:       q.Organization.replaceDB(db),
 		OrganizationMember: q.OrganizationMember.replaceDB(db),
@@ -145,6 +151,7 @@ type queryCtx struct {
 	Conversation       IConversationDo
 	Invite             IInviteDo
 	Item               IItemDo
+	ModelAlias         IModelAliasDo
 	ModelCatalog       IModelCatalogDo
 	Organization       IOrganizationDo
 	OrganizationMember IOrganizationMemberDo
@@ -163,6 +170,7 @@ func (q *Query) WithContext(ctx context.Context) *queryCtx {
 		Conversation:       q.Conversation.WithContext(ctx),
 		Invite:             q.Invite.WithContext(ctx),
 		Item:               q.Item.WithContext(ctx),
+		ModelAlias:         q.ModelAlias.WithContext(ctx),
 		ModelCatalog:       q.ModelCatalog.WithContext(ctx),
 		Organization:       q.Organization.WithContext(ctx),
 		OrganizationMember: q.OrganizationMember.WithContext(ctx),
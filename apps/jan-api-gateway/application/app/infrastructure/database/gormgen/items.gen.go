@@ -35,6 +35,7 @@ func newItem(db *gorm.DB, opts ...gen.DOOption) item {
 	_item.PublicID = field.NewString(tableName, "public_id")
 	_item.ConversationID = field.NewUint(tableName, "conversation_id")
 	_item.ResponseID = field.NewUint(tableName, "response_id")
+	_item.ParentItemID = field.NewUint(tableName, "parent_item_id")
 	_item.Type = field.NewString(tableName, "type")
 	_item.Role = field.NewString(tableName, "role")
 	_item.Content = field.NewString(tableName, "content")
@@ -166,6 +167,7 @@ type item struct {
 	PublicID          field.String
 	ConversationID    field.Uint
 	ResponseID        field.Uint
+	ParentItemID      field.Uint
 	Type              field.String
 	Role              field.String
 	Content           field.String
@@ -199,6 +201,7 @@ func (i *item) updateTableName(table string) *item {
 	i.PublicID = field.NewString(table, "public_id")
 	i.ConversationID = field.NewUint(table, "conversation_id")
 	i.ResponseID = field.NewUint(table, "response_id")
+	i.ParentItemID = field.NewUint(table, "parent_item_id")
 	i.Type = field.NewString(table, "type")
 	i.Role = field.NewString(table, "role")
 	i.Content = field.NewString(table, "content")
@@ -222,7 +225,7 @@ func (i *item) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (i *item) fillFieldMap() {
-	i.fieldMap = make(map[string]field.Expr, 16)
+	i.fieldMap = make(map[string]field.Expr, 17)
 	i.fieldMap["id"] = i.ID
 	i.fieldMap["created_at"] = i.CreatedAt
 	i.fieldMap["updated_at"] = i.UpdatedAt
@@ -230,6 +233,7 @@ func (i *item) fillFieldMap() {
 	i.fieldMap["public_id"] = i.PublicID
 	i.fieldMap["conversation_id"] = i.ConversationID
 	i.fieldMap["response_id"] = i.ResponseID
+	i.fieldMap["parent_item_id"] = i.ParentItemID
 	i.fieldMap["type"] = i.Type
 	i.fieldMap["role"] = i.Role
 	i.fieldMap["content"] = i.Content
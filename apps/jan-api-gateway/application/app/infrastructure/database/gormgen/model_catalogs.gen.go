@@ -40,6 +40,8 @@ func newModelCatalog(db *gorm.DB, opts ...gen.DOOption) modelCatalog {
 	_modelCatalog.IsModerated = field.NewBool(tableName, "is_moderated")
 	_modelCatalog.Status = field.NewString(tableName, "status")
 	_modelCatalog.Extras = field.NewField(tableName, "extras")
+	_modelCatalog.DeprecatedAt = field.NewTime(tableName, "deprecated_at")
+	_modelCatalog.DeprecationReason = field.NewString(tableName, "deprecation_reason")
 
 	_modelCatalog.fillFieldMap()
 
@@ -62,6 +64,8 @@ type modelCatalog struct {
 	IsModerated         field.Bool
 	Status              field.String
 	Extras              field.Field
+	DeprecatedAt        field.Time
+	DeprecationReason   field.String
 
 	fieldMap map[string]field.Expr
 }
@@ -90,6 +94,8 @@ func (m *modelCatalog) updateTableName(table string) *modelCatalog {
 	m.IsModerated = field.NewBool(table, "is_moderated")
 	m.Status = field.NewString(table, "status")
 	m.Extras = field.NewField(table, "extras")
+	m.DeprecatedAt = field.NewTime(table, "deprecated_at")
+	m.DeprecationReason = field.NewString(table, "deprecation_reason")
 
 	m.fillFieldMap()
 
@@ -106,7 +112,7 @@ func (m *modelCatalog) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (m *modelCatalog) fillFieldMap() {
-	m.fieldMap = make(map[string]field.Expr, 12)
+	m.fieldMap = make(map[string]field.Expr, 14)
 	m.fieldMap["id"] = m.ID
 	m.fieldMap["created_at"] = m.CreatedAt
 	m.fieldMap["updated_at"] = m.UpdatedAt
@@ -119,6 +125,8 @@ func (m *modelCatalog) fillFieldMap() {
 	m.fieldMap["is_moderated"] = m.IsModerated
 	m.fieldMap["status"] = m.Status
 	m.fieldMap["extras"] = m.Extras
+	m.fieldMap["deprecated_at"] = m.DeprecatedAt
+	m.fieldMap["deprecation_reason"] = m.DeprecationReason
 }
 
 func (m modelCatalog) clone(db *gorm.DB) modelCatalog {
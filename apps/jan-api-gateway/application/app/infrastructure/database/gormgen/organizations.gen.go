@@ -35,6 +35,10 @@ func newOrganization(db *gorm.DB, opts ...gen.DOOption) organization {
 	_organization.Name = field.NewString(tableName, "name")
 	_organization.PublicID = field.NewString(tableName, "public_id")
 	_organization.Enabled = field.NewBool(tableName, "enabled")
+	_organization.MonthlySpendLimitMicroUSD = field.NewInt64(tableName, "monthly_spend_limit_micro_usd")
+	_organization.DefaultProviderID = field.NewUint(tableName, "default_provider_id")
+	_organization.WebhookURL = field.NewString(tableName, "webhook_url")
+	_organization.EncryptedWebhookSecret = field.NewString(tableName, "encrypted_webhook_secret")
 	_organization.Members = organizationHasManyMembers{
 		db: db.Session(&gorm.Session{}),
 
@@ -49,15 +53,19 @@ func newOrganization(db *gorm.DB, opts ...gen.DOOption) organization {
 type organization struct {
 	organizationDo
 
-	ALL       field.Asterisk
-	ID        field.Uint
-	CreatedAt field.Time
-	UpdatedAt field.Time
-	DeletedAt field.Field
-	Name      field.String
-	PublicID  field.String
-	Enabled   field.Bool
-	Members   organizationHasManyMembers
+	ALL                       field.Asterisk
+	ID                        field.Uint
+	CreatedAt                 field.Time
+	UpdatedAt                 field.Time
+	DeletedAt                 field.Field
+	Name                      field.String
+	PublicID                  field.String
+	Enabled                   field.Bool
+	MonthlySpendLimitMicroUSD field.Int64
+	DefaultProviderID         field.Uint
+	WebhookURL                field.String
+	EncryptedWebhookSecret    field.String
+	Members                   organizationHasManyMembers
 
 	fieldMap map[string]field.Expr
 }
@@ -81,6 +89,10 @@ func (o *organization) updateTableName(table string) *organization {
 	o.Name = field.NewString(table, "name")
 	o.PublicID = field.NewString(table, "public_id")
 	o.Enabled = field.NewBool(table, "enabled")
+	o.MonthlySpendLimitMicroUSD = field.NewInt64(table, "monthly_spend_limit_micro_usd")
+	o.DefaultProviderID = field.NewUint(table, "default_provider_id")
+	o.WebhookURL = field.NewString(table, "webhook_url")
+	o.EncryptedWebhookSecret = field.NewString(table, "encrypted_webhook_secret")
 
 	o.fillFieldMap()
 
@@ -97,7 +109,7 @@ func (o *organization) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (o *organization) fillFieldMap() {
-	o.fieldMap = make(map[string]field.Expr, 8)
+	o.fieldMap = make(map[string]field.Expr, 12)
 	o.fieldMap["id"] = o.ID
 	o.fieldMap["created_at"] = o.CreatedAt
 	o.fieldMap["updated_at"] = o.UpdatedAt
@@ -105,6 +117,10 @@ func (o *organization) fillFieldMap() {
 	o.fieldMap["name"] = o.Name
 	o.fieldMap["public_id"] = o.PublicID
 	o.fieldMap["enabled"] = o.Enabled
+	o.fieldMap["monthly_spend_limit_micro_usd"] = o.MonthlySpendLimitMicroUSD
+	o.fieldMap["default_provider_id"] = o.DefaultProviderID
+	o.fieldMap["webhook_url"] = o.WebhookURL
+	o.fieldMap["encrypted_webhook_secret"] = o.EncryptedWebhookSecret
 
 }
 
@@ -37,6 +37,8 @@ func newProject(db *gorm.DB, opts ...gen.DOOption) project {
 	_project.Status = field.NewString(tableName, "status")
 	_project.OrganizationID = field.NewUint(tableName, "organization_id")
 	_project.ArchivedAt = field.NewTime(tableName, "archived_at")
+	_project.MonthlySpendLimitMicroUSD = field.NewInt64(tableName, "monthly_spend_limit_micro_usd")
+	_project.ModelPolicy = field.NewField(tableName, "model_policy")
 	_project.Members = projectHasManyMembers{
 		db: db.Session(&gorm.Session{}),
 
@@ -51,17 +53,19 @@ func newProject(db *gorm.DB, opts ...gen.DOOption) project {
 type project struct {
 	projectDo
 
-	ALL            field.Asterisk
-	ID             field.Uint
-	CreatedAt      field.Time
-	UpdatedAt      field.Time
-	DeletedAt      field.Field
-	Name           field.String
-	PublicID       field.String
-	Status         field.String
-	OrganizationID field.Uint
-	ArchivedAt     field.Time
-	Members        projectHasManyMembers
+	ALL                       field.Asterisk
+	ID                        field.Uint
+	CreatedAt                 field.Time
+	UpdatedAt                 field.Time
+	DeletedAt                 field.Field
+	Name                      field.String
+	PublicID                  field.String
+	Status                    field.String
+	OrganizationID            field.Uint
+	ArchivedAt                field.Time
+	MonthlySpendLimitMicroUSD field.Int64
+	ModelPolicy               field.Field
+	Members                   projectHasManyMembers
 
 	fieldMap map[string]field.Expr
 }
@@ -87,6 +91,8 @@ func (p *project) updateTableName(table string) *project {
 	p.Status = field.NewString(table, "status")
 	p.OrganizationID = field.NewUint(table, "organization_id")
 	p.ArchivedAt = field.NewTime(table, "archived_at")
+	p.MonthlySpendLimitMicroUSD = field.NewInt64(table, "monthly_spend_limit_micro_usd")
+	p.ModelPolicy = field.NewField(table, "model_policy")
 
 	p.fillFieldMap()
 
@@ -103,7 +109,7 @@ func (p *project) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (p *project) fillFieldMap() {
-	p.fieldMap = make(map[string]field.Expr, 10)
+	p.fieldMap = make(map[string]field.Expr, 12)
 	p.fieldMap["id"] = p.ID
 	p.fieldMap["created_at"] = p.CreatedAt
 	p.fieldMap["updated_at"] = p.UpdatedAt
@@ -113,6 +119,8 @@ func (p *project) fillFieldMap() {
 	p.fieldMap["status"] = p.Status
 	p.fieldMap["organization_id"] = p.OrganizationID
 	p.fieldMap["archived_at"] = p.ArchivedAt
+	p.fieldMap["monthly_spend_limit_micro_usd"] = p.MonthlySpendLimitMicroUSD
+	p.fieldMap["model_policy"] = p.ModelPolicy
 
 }
 
@@ -38,12 +38,14 @@ func newProviderModel(db *gorm.DB, opts ...gen.DOOption) providerModel {
 	_providerModel.ModelKey = field.NewString(tableName, "model_key")
 	_providerModel.DisplayName = field.NewString(tableName, "display_name")
 	_providerModel.Pricing = field.NewField(tableName, "pricing")
+	_providerModel.PriceOverride = field.NewField(tableName, "price_override")
 	_providerModel.TokenLimits = field.NewField(tableName, "token_limits")
 	_providerModel.Family = field.NewString(tableName, "family")
 	_providerModel.SupportsImages = field.NewBool(tableName, "supports_images")
 	_providerModel.SupportsEmbeddings = field.NewBool(tableName, "supports_embeddings")
 	_providerModel.SupportsReasoning = field.NewBool(tableName, "supports_reasoning")
 	_providerModel.Active = field.NewBool(tableName, "active")
+	_providerModel.Disabled = field.NewBool(tableName, "disabled")
 
 	_providerModel.fillFieldMap()
 
@@ -64,12 +66,14 @@ type providerModel struct {
 	ModelKey           field.String
 	DisplayName        field.String
 	Pricing            field.Field
+	PriceOverride      field.Field
 	TokenLimits        field.Field
 	Family             field.String
 	SupportsImages     field.Bool
 	SupportsEmbeddings field.Bool
 	SupportsReasoning  field.Bool
 	Active             field.Bool
+	Disabled           field.Bool
 
 	fieldMap map[string]field.Expr
 }
@@ -96,12 +100,14 @@ func (p *providerModel) updateTableName(table string) *providerModel {
 	p.ModelKey = field.NewString(table, "model_key")
 	p.DisplayName = field.NewString(table, "display_name")
 	p.Pricing = field.NewField(table, "pricing")
+	p.PriceOverride = field.NewField(table, "price_override")
 	p.TokenLimits = field.NewField(table, "token_limits")
 	p.Family = field.NewString(table, "family")
 	p.SupportsImages = field.NewBool(table, "supports_images")
 	p.SupportsEmbeddings = field.NewBool(table, "supports_embeddings")
 	p.SupportsReasoning = field.NewBool(table, "supports_reasoning")
 	p.Active = field.NewBool(table, "active")
+	p.Disabled = field.NewBool(table, "disabled")
 
 	p.fillFieldMap()
 
@@ -118,7 +124,7 @@ func (p *providerModel) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (p *providerModel) fillFieldMap() {
-	p.fieldMap = make(map[string]field.Expr, 16)
+	p.fieldMap = make(map[string]field.Expr, 18)
 	p.fieldMap["id"] = p.ID
 	p.fieldMap["created_at"] = p.CreatedAt
 	p.fieldMap["updated_at"] = p.UpdatedAt
@@ -129,12 +135,14 @@ func (p *providerModel) fillFieldMap() {
 	p.fieldMap["model_key"] = p.ModelKey
 	p.fieldMap["display_name"] = p.DisplayName
 	p.fieldMap["pricing"] = p.Pricing
+	p.fieldMap["price_override"] = p.PriceOverride
 	p.fieldMap["token_limits"] = p.TokenLimits
 	p.fieldMap["family"] = p.Family
 	p.fieldMap["supports_images"] = p.SupportsImages
 	p.fieldMap["supports_embeddings"] = p.SupportsEmbeddings
 	p.fieldMap["supports_reasoning"] = p.SupportsReasoning
 	p.fieldMap["active"] = p.Active
+	p.fieldMap["disabled"] = p.Disabled
 }
 
 func (p providerModel) clone(db *gorm.DB) providerModel {
@@ -39,12 +39,23 @@ func newProvider(db *gorm.DB, opts ...gen.DOOption) provider {
 	_provider.DisplayName = field.NewString(tableName, "display_name")
 	_provider.Kind = field.NewString(tableName, "kind")
 	_provider.BaseURL = field.NewString(tableName, "base_url")
+	_provider.PathPrefix = field.NewString(tableName, "path_prefix")
 	_provider.EncryptedAPIKey = field.NewString(tableName, "encrypted_api_key")
 	_provider.APIKeyHint = field.NewString(tableName, "api_key_hint")
 	_provider.IsModerated = field.NewBool(tableName, "is_moderated")
 	_provider.Active = field.NewBool(tableName, "active")
 	_provider.Metadata = field.NewField(tableName, "metadata")
+	_provider.Headers = field.NewField(tableName, "headers")
+	_provider.ModelAllowlist = field.NewField(tableName, "model_allowlist")
+	_provider.ModelDenylist = field.NewField(tableName, "model_denylist")
 	_provider.LastSyncedAt = field.NewTime(tableName, "last_synced_at")
+	_provider.KeyRotatedAt = field.NewTime(tableName, "key_rotated_at")
+	_provider.PreviousAPIKeyHint = field.NewString(tableName, "previous_api_key_hint")
+	_provider.Shadow = field.NewBool(tableName, "shadow")
+	_provider.Priority = field.NewInt(tableName, "priority")
+	_provider.LastHealthCheckAt = field.NewTime(tableName, "last_health_check_at")
+	_provider.LastHealthError = field.NewString(tableName, "last_health_error")
+	_provider.KeyMode = field.NewString(tableName, "key_mode")
 
 	_provider.fillFieldMap()
 
@@ -54,24 +65,35 @@ func newProvider(db *gorm.DB, opts ...gen.DOOption) provider {
 type provider struct {
 	providerDo
 
-	ALL             field.Asterisk
-	ID              field.Uint
-	CreatedAt       field.Time
-	UpdatedAt       field.Time
-	DeletedAt       field.Field
-	PublicID        field.String
-	Slug            field.String
-	OrganizationID  field.Uint
-	ProjectID       field.Uint
-	DisplayName     field.String
-	Kind            field.String
-	BaseURL         field.String
-	EncryptedAPIKey field.String
-	APIKeyHint      field.String
-	IsModerated     field.Bool
-	Active          field.Bool
-	Metadata        field.Field
-	LastSyncedAt    field.Time
+	ALL                field.Asterisk
+	ID                 field.Uint
+	CreatedAt          field.Time
+	UpdatedAt          field.Time
+	DeletedAt          field.Field
+	PublicID           field.String
+	Slug               field.String
+	OrganizationID     field.Uint
+	ProjectID          field.Uint
+	DisplayName        field.String
+	Kind               field.String
+	BaseURL            field.String
+	PathPrefix         field.String
+	EncryptedAPIKey    field.String
+	APIKeyHint         field.String
+	IsModerated        field.Bool
+	Active             field.Bool
+	Metadata           field.Field
+	Headers            field.Field
+	ModelAllowlist     field.Field
+	ModelDenylist      field.Field
+	LastSyncedAt       field.Time
+	KeyRotatedAt       field.Time
+	PreviousAPIKeyHint field.String
+	Shadow             field.Bool
+	Priority           field.Int
+	LastHealthCheckAt  field.Time
+	LastHealthError    field.String
+	KeyMode            field.String
 
 	fieldMap map[string]field.Expr
 }
@@ -99,12 +121,23 @@ func (p *provider) updateTableName(table string) *provider {
 	p.DisplayName = field.NewString(table, "display_name")
 	p.Kind = field.NewString(table, "kind")
 	p.BaseURL = field.NewString(table, "base_url")
+	p.PathPrefix = field.NewString(table, "path_prefix")
 	p.EncryptedAPIKey = field.NewString(table, "encrypted_api_key")
 	p.APIKeyHint = field.NewString(table, "api_key_hint")
 	p.IsModerated = field.NewBool(table, "is_moderated")
 	p.Active = field.NewBool(table, "active")
 	p.Metadata = field.NewField(table, "metadata")
+	p.Headers = field.NewField(table, "headers")
+	p.ModelAllowlist = field.NewField(table, "model_allowlist")
+	p.ModelDenylist = field.NewField(table, "model_denylist")
 	p.LastSyncedAt = field.NewTime(table, "last_synced_at")
+	p.KeyRotatedAt = field.NewTime(table, "key_rotated_at")
+	p.PreviousAPIKeyHint = field.NewString(table, "previous_api_key_hint")
+	p.Shadow = field.NewBool(table, "shadow")
+	p.Priority = field.NewInt(table, "priority")
+	p.LastHealthCheckAt = field.NewTime(table, "last_health_check_at")
+	p.LastHealthError = field.NewString(table, "last_health_error")
+	p.KeyMode = field.NewString(table, "key_mode")
 
 	p.fillFieldMap()
 
@@ -121,7 +154,7 @@ func (p *provider) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (p *provider) fillFieldMap() {
-	p.fieldMap = make(map[string]field.Expr, 17)
+	p.fieldMap = make(map[string]field.Expr, 28)
 	p.fieldMap["id"] = p.ID
 	p.fieldMap["created_at"] = p.CreatedAt
 	p.fieldMap["updated_at"] = p.UpdatedAt
@@ -133,12 +166,23 @@ func (p *provider) fillFieldMap() {
 	p.fieldMap["display_name"] = p.DisplayName
 	p.fieldMap["kind"] = p.Kind
 	p.fieldMap["base_url"] = p.BaseURL
+	p.fieldMap["path_prefix"] = p.PathPrefix
 	p.fieldMap["encrypted_api_key"] = p.EncryptedAPIKey
 	p.fieldMap["api_key_hint"] = p.APIKeyHint
 	p.fieldMap["is_moderated"] = p.IsModerated
 	p.fieldMap["active"] = p.Active
 	p.fieldMap["metadata"] = p.Metadata
+	p.fieldMap["headers"] = p.Headers
+	p.fieldMap["model_allowlist"] = p.ModelAllowlist
+	p.fieldMap["model_denylist"] = p.ModelDenylist
 	p.fieldMap["last_synced_at"] = p.LastSyncedAt
+	p.fieldMap["key_rotated_at"] = p.KeyRotatedAt
+	p.fieldMap["previous_api_key_hint"] = p.PreviousAPIKeyHint
+	p.fieldMap["shadow"] = p.Shadow
+	p.fieldMap["priority"] = p.Priority
+	p.fieldMap["last_health_check_at"] = p.LastHealthCheckAt
+	p.fieldMap["last_health_error"] = p.LastHealthError
+	p.fieldMap["key_mode"] = p.KeyMode
 }
 
 func (p provider) clone(db *gorm.DB) provider {
@@ -80,6 +80,30 @@ func (r *ConversationGormRepository) DeleteByWorkspacePublicID(ctx context.Conte
 	return err
 }
 
+func (r *ConversationGormRepository) CountByWorkspacePublicIDs(ctx context.Context, workspacePublicIDs []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(workspacePublicIDs))
+	if len(workspacePublicIDs) == 0 {
+		return counts, nil
+	}
+	query := r.db.GetQuery(ctx)
+	var rows []struct {
+		WorkspacePublicID string
+		Count             int64
+	}
+	err := query.Conversation.WithContext(ctx).
+		Select(query.Conversation.WorkspacePublicID, query.Conversation.ID.Count().As("count")).
+		Where(query.Conversation.WorkspacePublicID.In(workspacePublicIDs...)).
+		Group(query.Conversation.WorkspacePublicID).
+		Scan(&rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.WorkspacePublicID] = row.Count
+	}
+	return counts, nil
+}
+
 func (r *ConversationGormRepository) AddItem(ctx context.Context, conversationID uint, item *domain.Item) error {
 	model := dbschema.NewSchemaItem(item)
 	model.ConversationID = conversationID
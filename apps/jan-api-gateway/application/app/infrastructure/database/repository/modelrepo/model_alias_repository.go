@@ -0,0 +1,98 @@
+package modelrepo
+
+import (
+	"context"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/infrastructure/database/dbschema"
+	"menlo.ai/jan-api-gateway/app/infrastructure/database/gormgen"
+	"menlo.ai/jan-api-gateway/app/infrastructure/database/repository/transaction"
+	"menlo.ai/jan-api-gateway/app/utils/functional"
+)
+
+type ModelAliasGormRepository struct {
+	db *transaction.Database
+}
+
+var _ domainmodel.ModelAliasRepository = (*ModelAliasGormRepository)(nil)
+
+func NewModelAliasGormRepository(db *transaction.Database) domainmodel.ModelAliasRepository {
+	return &ModelAliasGormRepository{db: db}
+}
+
+func (repo *ModelAliasGormRepository) applyFilter(query *gormgen.Query, sql gormgen.IModelAliasDo, filter domainmodel.ModelAliasFilter) gormgen.IModelAliasDo {
+	if filter.PublicID != nil {
+		sql = sql.Where(query.ModelAlias.PublicID.Eq(*filter.PublicID))
+	}
+	if filter.OrganizationID != nil {
+		sql = sql.Where(query.ModelAlias.OrganizationID.Eq(*filter.OrganizationID))
+	}
+	if filter.Alias != nil {
+		sql = sql.Where(query.ModelAlias.Alias.Eq(*filter.Alias))
+	}
+	if filter.ProviderID != nil {
+		sql = sql.Where(query.ModelAlias.ProviderID.Eq(*filter.ProviderID))
+	}
+	return sql
+}
+
+func (repo *ModelAliasGormRepository) Create(ctx context.Context, alias *domainmodel.ModelAlias) error {
+	model := dbschema.NewSchemaModelAlias(alias)
+	query := repo.db.GetQuery(ctx)
+	if err := query.ModelAlias.WithContext(ctx).Create(model); err != nil {
+		return err
+	}
+	alias.ID = model.ID
+	alias.CreatedAt = model.CreatedAt
+	alias.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+func (repo *ModelAliasGormRepository) Update(ctx context.Context, alias *domainmodel.ModelAlias) error {
+	model := dbschema.NewSchemaModelAlias(alias)
+	query := repo.db.GetQuery(ctx)
+	return query.ModelAlias.WithContext(ctx).Save(model)
+}
+
+func (repo *ModelAliasGormRepository) DeleteByID(ctx context.Context, id uint) error {
+	query := repo.db.GetQuery(ctx)
+	_, err := query.ModelAlias.WithContext(ctx).
+		Where(query.ModelAlias.ID.Eq(id)).
+		Delete(&dbschema.ModelAlias{})
+	return err
+}
+
+func (repo *ModelAliasGormRepository) FindByFilter(ctx context.Context, filter domainmodel.ModelAliasFilter, p *query.Pagination) ([]*domainmodel.ModelAlias, error) {
+	query := repo.db.GetQuery(ctx)
+	sql := query.ModelAlias.WithContext(ctx)
+	sql = repo.applyFilter(query, sql, filter)
+	if p != nil {
+		if p.Limit != nil && *p.Limit > 0 {
+			sql = sql.Limit(*p.Limit)
+		}
+		if p.Offset != nil && *p.Offset >= 0 {
+			sql = sql.Offset(*p.Offset)
+		}
+		if p.Order == "desc" {
+			sql = sql.Order(query.ModelAlias.CreatedAt.Desc())
+		} else {
+			sql = sql.Order(query.ModelAlias.CreatedAt.Asc())
+		}
+	}
+	rows, err := sql.Find()
+	if err != nil {
+		return nil, err
+	}
+	aliases := functional.Map(rows, func(item *dbschema.ModelAlias) *domainmodel.ModelAlias {
+		return item.EtoD()
+	})
+	return aliases, nil
+}
+
+func (repo *ModelAliasGormRepository) Count(ctx context.Context, filter domainmodel.ModelAliasFilter) (int64, error) {
+	query := repo.db.GetQuery(ctx)
+	sql := query.ModelAlias.WithContext(ctx)
+	sql = repo.applyFilter(query, sql, filter)
+	return sql.Count()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	"gorm.io/gen/field"
 	"gorm.io/gorm"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/domain/query"
@@ -29,12 +30,22 @@ func (repo *ModelCatalogGormRepository) applyFilter(query *gormgen.Query, sql go
 	if filter.PublicID != nil {
 		sql = sql.Where(query.ModelCatalog.PublicID.Eq(*filter.PublicID))
 	}
+	if filter.PublicIDs != nil && len(*filter.PublicIDs) > 0 {
+		sql = sql.Where(query.ModelCatalog.PublicID.In((*filter.PublicIDs)...))
+	}
 	if filter.IsModerated != nil {
 		sql = sql.Where(query.ModelCatalog.IsModerated.Is(*filter.IsModerated))
 	}
 	if filter.Status != nil {
 		sql = sql.Where(query.ModelCatalog.Status.Eq(string(*filter.Status)))
 	}
+	if filter.Deprecated != nil {
+		deprecated := field.Or(query.ModelCatalog.DeprecatedAt.IsNotNull(), query.ModelCatalog.DeprecationReason.IsNotNull())
+		if !*filter.Deprecated {
+			deprecated = field.And(query.ModelCatalog.DeprecatedAt.IsNull(), query.ModelCatalog.DeprecationReason.IsNull())
+		}
+		sql = sql.Where(deprecated)
+	}
 	return sql
 }
 
@@ -63,6 +74,49 @@ func (repo *ModelCatalogGormRepository) Update(ctx context.Context, catalog *dom
 	return query.ModelCatalog.WithContext(ctx).Save(model)
 }
 
+func (repo *ModelCatalogGormRepository) BatchUpsert(ctx context.Context, catalogs []*domainmodel.ModelCatalog, batchSize int) error {
+	if len(catalogs) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(catalogs)
+	}
+	creates := make([]*dbschema.ModelCatalog, 0, len(catalogs))
+	created := make([]*domainmodel.ModelCatalog, 0, len(catalogs))
+	updates := make([]*dbschema.ModelCatalog, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		model, err := dbschema.NewSchemaModelCatalog(catalog)
+		if err != nil {
+			return err
+		}
+		if catalog.ID == 0 {
+			creates = append(creates, model)
+			created = append(created, catalog)
+			continue
+		}
+		updates = append(updates, model)
+	}
+	query := repo.db.GetQuery(ctx)
+	if len(creates) > 0 {
+		if err := query.ModelCatalog.WithContext(ctx).CreateInBatches(creates, batchSize); err != nil {
+			return err
+		}
+		for i, model := range creates {
+			created[i].ID = model.ID
+			created[i].CreatedAt = model.CreatedAt
+			created[i].UpdatedAt = model.UpdatedAt
+			created[i].Status = domainmodel.ModelCatalogStatus(model.Status)
+		}
+	}
+	for start := 0; start < len(updates); start += batchSize {
+		end := min(start+batchSize, len(updates))
+		if err := query.ModelCatalog.WithContext(ctx).Save(updates[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (repo *ModelCatalogGormRepository) DeleteByID(ctx context.Context, id uint) error {
 	query := repo.db.GetQuery(ctx)
 	_, err := query.ModelCatalog.WithContext(ctx).
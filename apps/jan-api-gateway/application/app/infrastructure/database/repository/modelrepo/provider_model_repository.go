@@ -30,6 +30,9 @@ func (repo *ProviderModelGormRepository) applyFilter(query *gormgen.Query, sql g
 	if filter.ProviderIDs != nil && len(*filter.ProviderIDs) > 0 {
 		sql = sql.Where(query.ProviderModel.ProviderID.In((*filter.ProviderIDs)...))
 	}
+	if filter.PublicID != nil {
+		sql = sql.Where(query.ProviderModel.PublicID.Eq(*filter.PublicID))
+	}
 	if filter.ModelCatalogID != nil {
 		sql = sql.Where(query.ProviderModel.ModelCatalogID.Eq(*filter.ModelCatalogID))
 	}
@@ -78,12 +81,60 @@ func (repo *ProviderModelGormRepository) Update(ctx context.Context, model *doma
 	return query.ProviderModel.WithContext(ctx).Save(schemaModel)
 }
 
+func (repo *ProviderModelGormRepository) BatchUpsert(ctx context.Context, models []*domainmodel.ProviderModel, batchSize int) error {
+	if len(models) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(models)
+	}
+	creates := make([]*dbschema.ProviderModel, 0, len(models))
+	created := make([]*domainmodel.ProviderModel, 0, len(models))
+	updates := make([]*dbschema.ProviderModel, 0, len(models))
+	for _, model := range models {
+		schemaModel, err := dbschema.NewSchemaProviderModel(model)
+		if err != nil {
+			return err
+		}
+		if model.ID == 0 {
+			creates = append(creates, schemaModel)
+			created = append(created, model)
+			continue
+		}
+		updates = append(updates, schemaModel)
+	}
+	query := repo.db.GetQuery(ctx)
+	if len(creates) > 0 {
+		if err := query.ProviderModel.WithContext(ctx).CreateInBatches(creates, batchSize); err != nil {
+			return err
+		}
+		for i, schemaModel := range creates {
+			created[i].ID = schemaModel.ID
+			created[i].CreatedAt = schemaModel.CreatedAt
+			created[i].UpdatedAt = schemaModel.UpdatedAt
+		}
+	}
+	for start := 0; start < len(updates); start += batchSize {
+		end := min(start+batchSize, len(updates))
+		if err := query.ProviderModel.WithContext(ctx).Save(updates[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (repo *ProviderModelGormRepository) DeleteByID(ctx context.Context, id uint) error {
 	query := repo.db.GetQuery(ctx)
 	_, err := query.ProviderModel.WithContext(ctx).Where(query.ProviderModel.ID.Eq(id)).Delete(&dbschema.ProviderModel{})
 	return err
 }
 
+func (repo *ProviderModelGormRepository) DeleteByProviderID(ctx context.Context, providerID uint) error {
+	query := repo.db.GetQuery(ctx)
+	_, err := query.ProviderModel.WithContext(ctx).Where(query.ProviderModel.ProviderID.Eq(providerID)).Delete(&dbschema.ProviderModel{})
+	return err
+}
+
 func (repo *ProviderModelGormRepository) FindByID(ctx context.Context, id uint) (*domainmodel.ProviderModel, error) {
 	query := repo.db.GetQuery(ctx)
 	schemaModel, err := query.ProviderModel.WithContext(ctx).Where(query.ProviderModel.ID.Eq(id)).First()
@@ -2,7 +2,9 @@ package modelrepo
 
 import (
 	"context"
+	"time"
 
+	"gorm.io/gen/field"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/domain/query"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database/dbschema"
@@ -37,12 +39,22 @@ func (repo *ProviderGormRepository) applyFilter(query *gormgen.Query, sql gormge
 	if filter.Kind != nil {
 		sql = sql.Where(query.Provider.Kind.Eq(string(*filter.Kind)))
 	}
+	if filter.BaseURL != nil {
+		sql = sql.Where(query.Provider.BaseURL.Eq(*filter.BaseURL))
+	}
 	if filter.ProjectID != nil {
 		sql = sql.Where(query.Provider.ProjectID.Eq(*filter.ProjectID))
 	}
 	if filter.ProjectIDs != nil && len(*filter.ProjectIDs) > 0 {
 		sql = sql.Where(query.Provider.ProjectID.In((*filter.ProjectIDs)...))
 	}
+	if filter.AccessibleProjectIDs != nil {
+		if ids := *filter.AccessibleProjectIDs; len(ids) > 0 {
+			sql = sql.Where(field.Or(query.Provider.ProjectID.IsNull(), query.Provider.ProjectID.In(ids...)))
+		} else {
+			sql = sql.Where(query.Provider.ProjectID.IsNull())
+		}
+	}
 	if filter.WithoutProject != nil {
 		if *filter.WithoutProject {
 			sql = sql.Where(query.Provider.ProjectID.IsNull())
@@ -56,12 +68,35 @@ func (repo *ProviderGormRepository) applyFilter(query *gormgen.Query, sql gormge
 	if filter.IsModerated != nil {
 		sql = sql.Where(query.Provider.IsModerated.Is(*filter.IsModerated))
 	}
+	if filter.Shadow != nil {
+		sql = sql.Where(query.Provider.Shadow.Is(*filter.Shadow))
+	}
+	if filter.HasAPIKey != nil {
+		if *filter.HasAPIKey {
+			sql = sql.Where(query.Provider.EncryptedAPIKey.Neq(""))
+		} else {
+			sql = sql.Where(field.Or(query.Provider.EncryptedAPIKey.IsNull(), query.Provider.EncryptedAPIKey.Eq("")))
+		}
+	}
 	if filter.LastSyncedAfter != nil {
 		sql = sql.Where(query.Provider.LastSyncedAt.Gte(*filter.LastSyncedAfter))
 	}
 	if filter.LastSyncedBefore != nil {
 		sql = sql.Where(query.Provider.LastSyncedAt.Lte(*filter.LastSyncedBefore))
 	}
+	if filter.HealthStatus != nil {
+		switch *filter.HealthStatus {
+		case domainmodel.ProviderHealthHealthy:
+			sql = sql.Where(query.Provider.LastHealthCheckAt.IsNotNull(), query.Provider.LastHealthError.IsNull())
+		case domainmodel.ProviderHealthUnhealthy:
+			sql = sql.Where(query.Provider.LastHealthError.IsNotNull())
+		case domainmodel.ProviderHealthUnchecked:
+			sql = sql.Where(query.Provider.LastHealthCheckAt.IsNull(), query.Provider.LastHealthError.IsNull())
+		}
+	}
+	if filter.WithDeleted != nil && *filter.WithDeleted {
+		sql = sql.Unscoped()
+	}
 	return sql
 }
 
@@ -80,7 +115,24 @@ func (repo *ProviderGormRepository) Create(ctx context.Context, provider *domain
 func (repo *ProviderGormRepository) Update(ctx context.Context, provider *domainmodel.Provider) error {
 	model := dbschema.NewSchemaProvider(provider)
 	query := repo.db.GetQuery(ctx)
-	return query.Provider.WithContext(ctx).Save(model)
+	if err := query.Provider.WithContext(ctx).Save(model); err != nil {
+		return err
+	}
+	// UpdatedAt versions the provider rows cached by the registry
+	provider.UpdatedAt = model.UpdatedAt
+	return nil
+}
+
+// UpdateHealth stores the outcome of a health check without touching the rest of the row.
+func (repo *ProviderGormRepository) UpdateHealth(ctx context.Context, id uint, checkedAt time.Time, healthError *string) error {
+	query := repo.db.GetQuery(ctx)
+	_, err := query.Provider.WithContext(ctx).
+		Where(query.Provider.ID.Eq(id)).
+		Updates(map[string]any{
+			"last_health_check_at": checkedAt,
+			"last_health_error":    healthError,
+		})
+	return err
 }
 
 func (repo *ProviderGormRepository) DeleteByID(ctx context.Context, id uint) error {
@@ -129,10 +181,17 @@ func (repo *ProviderGormRepository) FindByFilter(ctx context.Context, filter dom
 		if p.Offset != nil && *p.Offset >= 0 {
 			sql = sql.Offset(*p.Offset)
 		}
+		if p.After != nil {
+			if p.Order == "desc" {
+				sql = sql.Where(query.Provider.ID.Lt(*p.After))
+			} else {
+				sql = sql.Where(query.Provider.ID.Gt(*p.After))
+			}
+		}
 		if p.Order == "desc" {
-			sql = sql.Order(query.Provider.CreatedAt.Desc())
+			sql = sql.Order(query.Provider.CreatedAt.Desc(), query.Provider.ID.Desc())
 		} else {
-			sql = sql.Order(query.Provider.CreatedAt.Asc())
+			sql = sql.Order(query.Provider.CreatedAt.Asc(), query.Provider.ID.Asc())
 		}
 	}
 	rows, err := sql.Find()
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"github.com/google/wire"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database/repository/apikeyrepo"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database/repository/conversationrepo"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database/repository/inviterepo"
@@ -26,7 +27,9 @@ var RepositoryProvider = wire.NewSet(
 	modelrepo.NewProviderGormRepository,
 	modelrepo.NewProviderModelGormRepository,
 	modelrepo.NewModelCatalogGormRepository,
+	modelrepo.NewModelAliasGormRepository,
 	responserepo.NewResponseGormRepository,
 	workspacerepo.NewWorkspaceGormRepository,
 	transaction.NewDatabase,
+	wire.Bind(new(domainmodel.ProviderTransactor), new(*transaction.Database)),
 )
@@ -28,6 +28,14 @@ func (t *Database) GetQuery(ctx context.Context) *gormgen.Query {
 	return gormgen.Use(db)
 }
 
+// Transaction runs fn in a transaction, nested in the transaction already on the context if any.
+// Repositories called with the context passed to fn take part in the transaction.
+func (t *Database) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return t.GetTx(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
 func NewDatabase(db *gorm.DB) *Database {
 	return &Database{db}
 }
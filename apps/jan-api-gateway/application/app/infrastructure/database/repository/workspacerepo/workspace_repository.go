@@ -3,6 +3,7 @@ package workspacerepo
 import (
 	"context"
 
+	"gorm.io/gorm/clause"
 	"menlo.ai/jan-api-gateway/app/domain/query"
 	domain "menlo.ai/jan-api-gateway/app/domain/workspace"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database/dbschema"
@@ -49,6 +50,35 @@ func (repo *WorkspaceGormRepository) Create(ctx context.Context, workspace *doma
 	return nil
 }
 
+// CreateWithinLimit locks the user's row for the count and the insert, so concurrent creations
+// for the same user wait for each other instead of both passing the count.
+func (repo *WorkspaceGormRepository) CreateWithinLimit(ctx context.Context, workspace *domain.Workspace, limit int) (bool, error) {
+	created := false
+	err := repo.db.Transaction(ctx, func(ctx context.Context) error {
+		query := repo.db.GetQuery(ctx)
+		if _, err := query.User.WithContext(ctx).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select(query.User.ID).
+			Where(query.User.ID.Eq(workspace.UserID)).
+			First(); err != nil {
+			return err
+		}
+		count, err := repo.applyFilter(query, query.Workspace.WithContext(ctx), domain.WorkspaceFilter{UserID: &workspace.UserID}).Count()
+		if err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return nil
+		}
+		if err := repo.Create(ctx, workspace); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
 func (repo *WorkspaceGormRepository) Update(ctx context.Context, workspace *domain.Workspace) error {
 	model := dbschema.NewSchemaWorkspace(workspace)
 	query := repo.db.GetQuery(ctx)
@@ -0,0 +1,243 @@
+package inference
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"resty.dev/v3"
+)
+
+// Provider metadata keys holding the AWS credentials of a Bedrock provider. The secret access key
+// and session token follow the secret metadata convention, so they are stored encrypted.
+const (
+	bedrockAccessKeyIDKey     = "aws_access_key_id"
+	bedrockSecretAccessKeyKey = "aws_secret_access_key" + domainmodel.SecretMetadataKeySuffix
+	bedrockSessionTokenKey    = "aws_session_token" + domainmodel.SecretMetadataKeySuffix
+	bedrockRegionKey          = "region"
+)
+
+const (
+	sigV4Algorithm = "AWS4-HMAC-SHA256"
+	// bedrockSigningName is the SigV4 service name of both the Bedrock runtime and control plane.
+	bedrockSigningName = "bedrock"
+	amzDateFormat      = "20060102T150405Z"
+)
+
+// bedrockCredentials are the AWS credentials requests to a Bedrock provider are signed with.
+type bedrockCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+}
+
+// bedrockCredentialsFromMetadata reads the AWS credentials from decrypted provider metadata. It
+// returns nil when no access key is configured, in which case the provider's stored key is sent as
+// a Bedrock API key instead.
+func bedrockCredentialsFromMetadata(metadata map[string]string) (*bedrockCredentials, error) {
+	accessKeyID := strings.TrimSpace(metadata[bedrockAccessKeyIDKey])
+	if accessKeyID == "" {
+		return nil, nil
+	}
+	credentials := &bedrockCredentials{
+		accessKeyID:     accessKeyID,
+		secretAccessKey: strings.TrimSpace(metadata[bedrockSecretAccessKeyKey]),
+		sessionToken:    strings.TrimSpace(metadata[bedrockSessionTokenKey]),
+		region:          strings.TrimSpace(metadata[bedrockRegionKey]),
+	}
+	if credentials.secretAccessKey == "" {
+		return nil, fmt.Errorf("bedrock provider metadata sets %s without %s", bedrockAccessKeyIDKey, bedrockSecretAccessKeyKey)
+	}
+	if credentials.region == "" {
+		return nil, fmt.Errorf("bedrock provider metadata is missing %s", bedrockRegionKey)
+	}
+	return credentials, nil
+}
+
+// sigV4Transport signs every request with AWS Signature Version 4 before handing it to the next
+// transport. Signing at the transport sees the final URL, headers and body of the request.
+type sigV4Transport struct {
+	next        http.RoundTripper
+	credentials bedrockCredentials
+	now         func() time.Time
+}
+
+func (t *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+		signed.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	signSigV4(signed, body, t.credentials, t.now())
+	return t.next.RoundTrip(signed)
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the wrapped transport.
+func (t *sigV4Transport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// signSigV4 sets the X-Amz-Date, X-Amz-Security-Token and Authorization headers of req.
+func signSigV4(req *http.Request, body []byte, credentials bedrockCredentials, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := now.Format("20060102")
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.sessionToken)
+	}
+
+	headers := map[string]string{
+		"host":       host,
+		"x-amz-date": amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = strings.TrimSpace(contentType)
+	}
+	if credentials.sessionToken != "" {
+		headers["x-amz-security-token"] = credentials.sessionToken
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4CanonicalURI(req.URL),
+		sigV4CanonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + credentials.region + "/" + bedrockSigningName + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{sigV4Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.secretAccessKey), date)
+	key = hmacSHA256(key, credentials.region)
+	key = hmacSHA256(key, bedrockSigningName)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, credentials.accessKeyID, scope, signedHeaders, signature))
+}
+
+// sigV4CanonicalURI encodes every segment of the already escaped path once more, as SigV4 requires
+// for every service but S3. Bedrock model IDs such as anthropic.claude-3-haiku-20240307-v1:0 end up
+// with their colon encoded twice.
+func sigV4CanonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// sigV4CanonicalQuery sorts the query parameters by name and value and encodes them.
+func sigV4CanonicalQuery(u *url.URL) string {
+	query := u.Query()
+	if len(query) == 0 {
+		return ""
+	}
+	encoded := make(map[string][]string, len(query))
+	names := make([]string, 0, len(query))
+	for name, values := range query {
+		name = awsURIEncode(name)
+		names = append(names, name)
+		for _, value := range values {
+			encoded[name] = append(encoded[name], awsURIEncode(value))
+		}
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(query))
+	for _, name := range names {
+		values := encoded[name]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, name+"="+value)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte except the unreserved characters A-Z, a-z, 0-9, '-',
+// '.', '_' and '~'.
+func awsURIEncode(value string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			encoded.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%%%02X", c)
+	}
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// signBedrockRequests makes the client sign its requests with the AWS credentials of a Bedrock
+// provider. Providers without credentials in their metadata keep authenticating with their stored
+// key, sent as a Bedrock API key.
+func signBedrockRequests(client *resty.Client, provider *domainmodel.Provider) error {
+	metadata, err := domainmodel.DecryptMetadata(provider.Metadata)
+	if err != nil {
+		return err
+	}
+	credentials, err := bedrockCredentialsFromMetadata(metadata)
+	if err != nil || credentials == nil {
+		return err
+	}
+	client.SetTransport(&sigV4Transport{
+		next:        client.Transport(),
+		credentials: *credentials,
+		now:         time.Now,
+	})
+	return nil
+}
@@ -0,0 +1,145 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/utils/crypto"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+func TestSignSigV4(t *testing.T) {
+	body := []byte(`{"max_tokens":1}`)
+	req, err := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	credentials := bedrockCredentials{
+		accessKeyID:     "AKIDEXAMPLE",
+		secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:          "us-east-1",
+	}
+
+	signSigV4(req, body, credentials, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	// Computed independently from the SigV4 specification, with the colon of the model ID
+	// encoded twice in the canonical URI.
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240102/us-east-1/bedrock/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=1eaeb0840daf628dbacd17c8a0b30280ae5c640c0c8b1f71f0477d0baa1e8b4f"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Fatalf("Authorization = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20240102T030405Z" {
+		t.Fatalf("X-Amz-Date = %q, want 20240102T030405Z", got)
+	}
+}
+
+func TestBedrockCredentialsFromMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantNil  bool
+		wantErr  bool
+	}{
+		{name: "no access key", metadata: map[string]string{"region": "us-east-1"}, wantNil: true},
+		{name: "complete", metadata: map[string]string{"aws_access_key_id": "AKID", "aws_secret_access_key_secret": "secret", "region": "us-east-1"}},
+		{name: "missing secret", metadata: map[string]string{"aws_access_key_id": "AKID", "region": "us-east-1"}, wantErr: true},
+		{name: "missing region", metadata: map[string]string{"aws_access_key_id": "AKID", "aws_secret_access_key_secret": "secret"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			credentials, err := bedrockCredentialsFromMetadata(tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (credentials == nil) != tt.wantNil {
+				t.Fatalf("credentials = %+v, want nil %v", credentials, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestSignBedrockRequests(t *testing.T) {
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret"
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+	encrypt := func(value string) string {
+		cipher, err := crypto.EncryptString("test-secret", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cipher
+	}
+
+	tests := []struct {
+		name      string
+		metadata  map[string]string
+		wantAuth  string
+		wantToken string
+	}{
+		{
+			name: "signed with the decrypted credentials",
+			metadata: map[string]string{
+				"aws_access_key_id":            "AKIDEXAMPLE",
+				"aws_secret_access_key_secret": encrypt("wJalrXUtnFEMI"),
+				"aws_session_token_secret":     encrypt("session-token"),
+				"region":                       "us-west-2",
+			},
+			wantAuth:  "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/",
+			wantToken: "session-token",
+		},
+		{
+			name:     "without credentials the stored key is sent",
+			metadata: map[string]string{"region": "us-west-2"},
+			wantAuth: "Bearer bedrock-api-key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer upstream.Close()
+
+			client := resty.New()
+			provider := &domainmodel.Provider{Kind: domainmodel.ProviderAWSBedrock, Metadata: tt.metadata}
+			if err := signBedrockRequests(client, provider); err != nil {
+				t.Fatalf("signBedrockRequests: %v", err)
+			}
+			_, err := client.R().
+				SetContext(context.Background()).
+				SetAuthToken("bedrock-api-key").
+				SetBody(map[string]int{"max_tokens": 1}).
+				Post(upstream.URL + "/model/anthropic.claude-3-haiku-20240307-v1%3A0/invoke")
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got := header.Get("Authorization"); !strings.HasPrefix(got, tt.wantAuth) {
+				t.Fatalf("Authorization = %q, want prefix %q", got, tt.wantAuth)
+			}
+			if got := header.Get("X-Amz-Security-Token"); got != tt.wantToken {
+				t.Fatalf("X-Amz-Security-Token = %q, want %q", got, tt.wantToken)
+			}
+			if strings.TrimSpace(string(body)) != `{"max_tokens":1}` {
+				t.Fatalf("upstream body = %q, want the request body", body)
+			}
+		})
+	}
+}
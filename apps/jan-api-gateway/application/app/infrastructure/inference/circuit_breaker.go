@@ -0,0 +1,181 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitWindow           = 60 * time.Second
+	defaultCircuitCooldown         = 30 * time.Second
+)
+
+// ErrProviderCircuitOpen is returned instead of a client while a provider's circuit is open.
+var ErrProviderCircuitOpen = errors.New("provider circuit breaker is open")
+
+// CircuitState is the breaker state of a single provider.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+type providerCircuit struct {
+	state        CircuitState
+	failures     int
+	windowStart  time.Time
+	openedAt     time.Time
+	probeRunning bool
+	probeStarted time.Time
+}
+
+// circuitBreaker tracks consecutive upstream failures per provider ID. A circuit opens once
+// failureThreshold failures happen within window, rejects calls for cooldown, then lets a
+// single probe through: a successful probe closes it, a failed one opens it again. A probe whose
+// outcome is never recorded stops blocking others after another cooldown.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	circuits         map[uint]*providerCircuit
+	failureThreshold int
+	window           time.Duration
+	cooldown         time.Duration
+	now              func() time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, window, cooldown time.Duration) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = defaultCircuitFailureThreshold
+	}
+	if window <= 0 {
+		window = defaultCircuitWindow
+	}
+	if cooldown <= 0 {
+		cooldown = defaultCircuitCooldown
+	}
+	return &circuitBreaker{
+		circuits:         make(map[uint]*providerCircuit),
+		failureThreshold: failureThreshold,
+		window:           window,
+		cooldown:         cooldown,
+		now:              time.Now,
+	}
+}
+
+func newCircuitBreakerFromEnv() *circuitBreaker {
+	env := environment_variables.EnvironmentVariables
+	return newCircuitBreaker(
+		env.PROVIDER_CIRCUIT_FAILURE_THRESHOLD,
+		time.Duration(env.PROVIDER_CIRCUIT_WINDOW_SECONDS)*time.Second,
+		time.Duration(env.PROVIDER_CIRCUIT_COOLDOWN_SECONDS)*time.Second,
+	)
+}
+
+// state reports the provider's current state, moving an open circuit to half-open once the
+// cooldown has elapsed.
+func (cb *circuitBreaker) state(providerID uint) CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	circuit, ok := cb.circuits[providerID]
+	if !ok {
+		return CircuitClosed
+	}
+	cb.advance(circuit)
+	return circuit.state
+}
+
+// allow reports whether a call to the provider may proceed. In the half-open state only one
+// probe is let through until its outcome is recorded or it is released.
+func (cb *circuitBreaker) allow(providerID uint) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	circuit, ok := cb.circuits[providerID]
+	if !ok {
+		return true
+	}
+	cb.advance(circuit)
+	switch circuit.state {
+	case CircuitOpen:
+		return false
+	case CircuitHalfOpen:
+		now := cb.now()
+		if circuit.probeRunning && now.Sub(circuit.probeStarted) < cb.cooldown {
+			return false
+		}
+		circuit.probeRunning = true
+		circuit.probeStarted = now
+		return true
+	default:
+		return true
+	}
+}
+
+func (cb *circuitBreaker) recordSuccess(providerID uint) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	delete(cb.circuits, providerID)
+}
+
+// release frees a half-open probe whose outcome says nothing about the provider.
+func (cb *circuitBreaker) release(providerID uint) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if circuit, ok := cb.circuits[providerID]; ok {
+		circuit.probeRunning = false
+	}
+}
+
+func (cb *circuitBreaker) recordFailure(providerID uint) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := cb.now()
+	circuit, ok := cb.circuits[providerID]
+	if !ok {
+		circuit = &providerCircuit{state: CircuitClosed, windowStart: now}
+		cb.circuits[providerID] = circuit
+	}
+	cb.advance(circuit)
+
+	switch circuit.state {
+	case CircuitHalfOpen:
+		cb.open(circuit, now)
+	case CircuitClosed:
+		if now.Sub(circuit.windowStart) > cb.window {
+			circuit.failures = 0
+			circuit.windowStart = now
+		}
+		circuit.failures++
+		if circuit.failures >= cb.failureThreshold {
+			cb.open(circuit, now)
+		}
+	}
+}
+
+func (cb *circuitBreaker) open(circuit *providerCircuit, now time.Time) {
+	circuit.state = CircuitOpen
+	circuit.openedAt = now
+	circuit.failures = 0
+	circuit.probeRunning = false
+}
+
+func (cb *circuitBreaker) advance(circuit *providerCircuit) {
+	if circuit.state == CircuitOpen && cb.now().Sub(circuit.openedAt) >= cb.cooldown {
+		circuit.state = CircuitHalfOpen
+		circuit.probeRunning = false
+	}
+}
+
+// isCircuitFailure reports whether an upstream outcome should count against the provider.
+// Cancellations come from the caller going away and say nothing about upstream health.
+func isCircuitFailure(statusCode int, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return statusCode >= 500
+}
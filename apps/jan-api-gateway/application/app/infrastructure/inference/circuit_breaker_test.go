@@ -0,0 +1,195 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+// fakeClock is a settable time source for the breaker.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+func newTestBreaker(clock *fakeClock) *circuitBreaker {
+	breaker := newCircuitBreaker(3, time.Minute, 10*time.Second)
+	breaker.now = clock.Now
+	return breaker
+}
+
+// openCircuit fails the provider until its circuit opens.
+func openCircuit(breaker *circuitBreaker, providerID uint) {
+	for range breaker.failureThreshold {
+		breaker.recordFailure(providerID)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	breaker := newTestBreaker(clock)
+	providerID := uint(7)
+
+	breaker.recordFailure(providerID)
+	breaker.recordFailure(providerID)
+	if state := breaker.state(providerID); state != CircuitClosed {
+		t.Fatalf("state after 2 failures = %s, want closed", state)
+	}
+	breaker.recordFailure(providerID)
+	if state := breaker.state(providerID); state != CircuitOpen {
+		t.Fatalf("state after 3 failures = %s, want open", state)
+	}
+	if breaker.allow(providerID) {
+		t.Fatal("open circuit let a call through")
+	}
+
+	clock.Advance(10 * time.Second)
+	if state := breaker.state(providerID); state != CircuitHalfOpen {
+		t.Fatalf("state after cooldown = %s, want half_open", state)
+	}
+	if !breaker.allow(providerID) {
+		t.Fatal("half-open circuit rejected the probe")
+	}
+	if breaker.allow(providerID) {
+		t.Fatal("half-open circuit let a second call through while the probe runs")
+	}
+	breaker.recordSuccess(providerID)
+	if state := breaker.state(providerID); state != CircuitClosed {
+		t.Fatalf("state after successful probe = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerFailuresOutsideWindowDoNotOpen(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	breaker := newTestBreaker(clock)
+
+	breaker.recordFailure(1)
+	breaker.recordFailure(1)
+	clock.Advance(2 * time.Minute)
+	breaker.recordFailure(1)
+	if state := breaker.state(1); state != CircuitClosed {
+		t.Fatalf("state = %s, want closed once the window restarted", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	tests := []struct {
+		name        string
+		finish      func(breaker *circuitBreaker, clock *fakeClock, providerID uint)
+		wantState   CircuitState
+		wantAllowed bool
+	}{
+		{
+			name:        "failed probe opens the circuit again",
+			finish:      func(b *circuitBreaker, _ *fakeClock, id uint) { b.recordFailure(id) },
+			wantState:   CircuitOpen,
+			wantAllowed: false,
+		},
+		{
+			name:        "released probe lets the next call probe",
+			finish:      func(b *circuitBreaker, _ *fakeClock, id uint) { b.release(id) },
+			wantState:   CircuitHalfOpen,
+			wantAllowed: true,
+		},
+		{
+			name:        "abandoned probe expires after a cooldown",
+			finish:      func(_ *circuitBreaker, c *fakeClock, _ uint) { c.Advance(10 * time.Second) },
+			wantState:   CircuitHalfOpen,
+			wantAllowed: true,
+		},
+		{
+			name:        "abandoned probe blocks within the cooldown",
+			finish:      func(_ *circuitBreaker, c *fakeClock, _ uint) { c.Advance(5 * time.Second) },
+			wantState:   CircuitHalfOpen,
+			wantAllowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+			breaker := newTestBreaker(clock)
+			providerID := uint(3)
+			openCircuit(breaker, providerID)
+			clock.Advance(10 * time.Second)
+			if !breaker.allow(providerID) {
+				t.Fatal("half-open circuit rejected the probe")
+			}
+
+			tt.finish(breaker, clock, providerID)
+			if state := breaker.state(providerID); state != tt.wantState {
+				t.Fatalf("state = %s, want %s", state, tt.wantState)
+			}
+			if allowed := breaker.allow(providerID); allowed != tt.wantAllowed {
+				t.Fatalf("allow = %t, want %t", allowed, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestUnsentProbeIsReleased(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	ip := &InferenceProvider{
+		breaker: newTestBreaker(clock),
+		metrics: newProviderMetrics(),
+		clients: newProviderClientCache(4),
+	}
+
+	tests := []struct {
+		name     string
+		provider *domainmodel.Provider
+		call     func(provider *domainmodel.Provider) error
+	}{
+		{
+			name:     "client options error",
+			provider: &domainmodel.Provider{ID: 11, Slug: "bedrock", Kind: domainmodel.ProviderAWSBedrock, BaseURL: "http://127.0.0.1:1"},
+			call: func(provider *domainmodel.Provider) error {
+				_, err := ip.GetChatCompletionClient(provider)
+				return err
+			},
+		},
+		{
+			name: "unsupported bedrock model",
+			provider: &domainmodel.Provider{ID: 12, Slug: "bedrock-east", Kind: domainmodel.ProviderAWSBedrock, BaseURL: "http://127.0.0.1:1",
+				Metadata: map[string]string{bedrockRegionKey: "us-east-1"}},
+			call: func(provider *domainmodel.Provider) error {
+				client, err := ip.GetChatCompletionClient(provider)
+				if err != nil {
+					return err
+				}
+				_, err = client.CreateChatCompletion(context.Background(), "", openai.ChatCompletionRequest{Model: "meta.llama3"})
+				return err
+			},
+		},
+		{
+			name:     "invalid request",
+			provider: &domainmodel.Provider{ID: 13, Slug: "broken", Kind: domainmodel.ProviderOpenAI, BaseURL: "http://bad host"},
+			call: func(provider *domainmodel.Provider) error {
+				client, err := ip.GetChatCompletionClient(provider)
+				if err != nil {
+					return err
+				}
+				_, err = client.CreateChatCompletion(context.Background(), "", openai.ChatCompletionRequest{Model: "gpt"})
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			openCircuit(ip.breaker, tt.provider.ID)
+			clock.Advance(10 * time.Second)
+
+			if err := tt.call(tt.provider); err == nil || errors.Is(err, ErrProviderCircuitOpen) {
+				t.Fatalf("probe error = %v, want a failure before the upstream", err)
+			}
+			if state := ip.breaker.state(tt.provider.ID); state != CircuitHalfOpen {
+				t.Fatalf("state = %s, want half_open", state)
+			}
+			if !ip.breaker.allow(tt.provider.ID) {
+				t.Fatal("the unsent probe still blocks the provider")
+			}
+		})
+	}
+}
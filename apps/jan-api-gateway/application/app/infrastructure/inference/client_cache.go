@@ -0,0 +1,145 @@
+package inference
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+const (
+	defaultProviderClientCacheSize     = 256
+	defaultProviderMaxIdleConnsPerHost = 64
+)
+
+// providerTransportSettings sizes the connection pool of a provider client. Resty keeps only
+// GOMAXPROCS+1 idle connections per host, which makes concurrent completions to one upstream
+// dial new connections under load. A provider client talks to a single host, so the overall idle
+// limit matches the per-host one. Dialing and the TLS handshake are bounded by the connect timeout
+// so an unreachable upstream fails fast.
+func providerTransportSettings() *resty.TransportSettings {
+	perHost := environment_variables.EnvironmentVariables.PROVIDER_MAX_IDLE_CONNS_PER_HOST
+	if perHost <= 0 {
+		perHost = defaultProviderMaxIdleConnsPerHost
+	}
+	connectTimeout := providerConnectTimeout()
+	return &resty.TransportSettings{
+		DialerTimeout:       connectTimeout,
+		TLSHandshakeTimeout: connectTimeout,
+		MaxIdleConns:        perHost,
+		MaxIdleConnsPerHost: perHost,
+	}
+}
+
+// providerClientCache keeps the configured resty client of each provider so requests reuse its
+// upstream connections instead of building a client per call. Entries are keyed by a fingerprint
+// of everything the client is built from, so an updated provider gets a new client while the stale
+// one ages out; the least recently used client is evicted once capacity is reached.
+type providerClientCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// order holds the entries from most to least recently used.
+	order *list.List
+}
+
+type providerClientEntry struct {
+	key    string
+	client *resty.Client
+}
+
+func newProviderClientCache(capacity int) *providerClientCache {
+	if capacity <= 0 {
+		capacity = defaultProviderClientCacheSize
+	}
+	return &providerClientCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func newProviderClientCacheFromEnv() *providerClientCache {
+	return newProviderClientCache(environment_variables.EnvironmentVariables.PROVIDER_CLIENT_CACHE_SIZE)
+}
+
+// get returns the client cached for the provider, building it with create on a miss. Clients are
+// built outside the lock; when two callers race, the first one stored wins.
+func (c *providerClientCache) get(provider *domainmodel.Provider, create func() (*resty.Client, error)) (*resty.Client, error) {
+	key := providerClientKey(provider)
+	if client, ok := c.lookup(key); ok {
+		return client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*providerClientEntry).client, nil
+	}
+	c.entries[key] = c.order.PushFront(&providerClientEntry{key: key, client: client})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*providerClientEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		// Requests still running on the evicted client keep their connections.
+		entry.client.Client().CloseIdleConnections()
+	}
+	return client, nil
+}
+
+func (c *providerClientCache) lookup(key string) (*resty.Client, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*providerClientEntry).client, true
+}
+
+// providerClientKey fingerprints the provider fields createRestyClient reads.
+func providerClientKey(provider *domainmodel.Provider) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s\x00%s",
+		provider.ID,
+		provider.Kind,
+		provider.Slug,
+		provider.DisplayName,
+		provider.BaseURL,
+		provider.EncryptedAPIKey,
+		provider.Metadata["anthropic_version"],
+		provider.OpenAIOrganization(),
+	)
+	if provider.Kind == domainmodel.ProviderAWSBedrock {
+		// the signing credentials are part of the client's transport
+		fmt.Fprintf(hash, "\x00%s\x00%s\x00%s\x00%s",
+			provider.Metadata[bedrockAccessKeyIDKey],
+			provider.Metadata[bedrockSecretAccessKeyKey],
+			provider.Metadata[bedrockSessionTokenKey],
+			provider.Metadata[bedrockRegionKey],
+		)
+	}
+	names := make([]string, 0, len(provider.Headers))
+	for name := range provider.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(hash, "\x00%s=%s", name, provider.Headers[name])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"resty.dev/v3"
+)
+
+func TestProviderClientCache(t *testing.T) {
+	created := 0
+	create := func() (*resty.Client, error) {
+		created++
+		return resty.New(), nil
+	}
+	provider := func(mutate func(*domainmodel.Provider)) *domainmodel.Provider {
+		p := &domainmodel.Provider{
+			ID:              1,
+			Kind:            domainmodel.ProviderOpenAI,
+			Slug:            "openai",
+			BaseURL:         "https://api.openai.com/v1",
+			EncryptedAPIKey: "key-v1",
+			Headers:         map[string]string{"X-Team": "core"},
+		}
+		if mutate != nil {
+			mutate(p)
+		}
+		return p
+	}
+
+	cache := newProviderClientCache(8)
+	first, _ := cache.get(provider(nil), create)
+	if again, _ := cache.get(provider(nil), create); again != first || created != 1 {
+		t.Fatalf("the same provider built %d clients, want one reused client", created)
+	}
+
+	changes := map[string]func(*domainmodel.Provider){
+		"base_url":          func(p *domainmodel.Provider) { p.BaseURL = "https://proxy.example.com/v1" },
+		"rotated key":       func(p *domainmodel.Provider) { p.EncryptedAPIKey = "key-v2" },
+		"header":            func(p *domainmodel.Provider) { p.Headers["X-Team"] = "research" },
+		"anthropic_version": func(p *domainmodel.Provider) { p.Metadata = map[string]string{"anthropic_version": "2024-10-22"} },
+		"bedrock region": func(p *domainmodel.Provider) {
+			p.Kind = domainmodel.ProviderAWSBedrock
+			p.Metadata = map[string]string{"region": "eu-west-1"}
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			client, _ := cache.get(provider(change), create)
+			if client == first {
+				t.Fatalf("a provider with a changed %s reused the old client", name)
+			}
+		})
+	}
+
+	t.Run("failed builds are not cached", func(t *testing.T) {
+		failing := provider(func(p *domainmodel.Provider) { p.ID = 99 })
+		if _, err := cache.get(failing, func() (*resty.Client, error) { return nil, errors.New("bad key") }); err == nil {
+			t.Fatal("get succeeded, want the build error")
+		}
+		if client, err := cache.get(failing, create); err != nil || client == nil {
+			t.Fatalf("get after a failed build = %v, %v, want a client", client, err)
+		}
+	})
+}
+
+func TestProviderClientCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newProviderClientCache(2)
+	providers := []*domainmodel.Provider{{ID: 1}, {ID: 2}, {ID: 3}}
+	clients := make([]*resty.Client, len(providers))
+	for i, provider := range providers[:2] {
+		clients[i], _ = cache.get(provider, func() (*resty.Client, error) { return resty.New(), nil })
+	}
+	// Touch the first provider, so the second is the least recently used when the third arrives.
+	_, _ = cache.get(providers[0], nil)
+	clients[2], _ = cache.get(providers[2], func() (*resty.Client, error) { return resty.New(), nil })
+
+	if _, ok := cache.lookup(providerClientKey(providers[1])); ok {
+		t.Fatal("the least recently used client was kept")
+	}
+	for _, i := range []int{0, 2} {
+		if client, ok := cache.lookup(providerClientKey(providers[i])); !ok || client != clients[i] {
+			t.Fatalf("client of provider %d was evicted", providers[i].ID)
+		}
+	}
+}
+
+// BenchmarkChatCompletionClient compares building a resty client for every completion with
+// reusing the provider's pooled client. Per-request clients pay for a new connection each time.
+func BenchmarkChatCompletionClient(b *testing.B) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"}}]}`))
+	}))
+	defer upstream.Close()
+	provider := &domainmodel.Provider{DisplayName: "bench", Kind: domainmodel.ProviderOpenAI, BaseURL: upstream.URL}
+	request := openai.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+	}
+	ctx := context.Background()
+
+	b.Run("per-request", func(b *testing.B) {
+		ip := NewInferenceProvider()
+		for b.Loop() {
+			client, err := ip.createRestyClient(provider)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := chatclient.NewChatCompletionClient(client, "bench", provider.BaseURL).CreateChatCompletion(ctx, "", request); err != nil {
+				b.Fatal(err)
+			}
+			client.Client().CloseIdleConnections()
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		ip := NewInferenceProvider()
+		for b.Loop() {
+			client, err := ip.GetChatCompletionClient(provider)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := client.CreateChatCompletion(ctx, "", request); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
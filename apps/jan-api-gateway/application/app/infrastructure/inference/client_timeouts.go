@@ -0,0 +1,45 @@
+package inference
+
+import (
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+const (
+	// DefaultProviderConnectTimeout bounds dialing a provider and its TLS handshake when
+	// PROVIDER_CONNECT_TIMEOUT is unset.
+	DefaultProviderConnectTimeout = 5 * time.Second
+	// DefaultProviderRequestTimeout bounds a whole provider request, generation included, when
+	// PROVIDER_REQUEST_TIMEOUT is unset.
+	DefaultProviderRequestTimeout = 120 * time.Second
+)
+
+// providerConnectTimeout returns PROVIDER_CONNECT_TIMEOUT. It is kept short so an unreachable
+// host fails fast instead of holding the request for the full request timeout.
+func providerConnectTimeout() time.Duration {
+	return durationFromEnv("PROVIDER_CONNECT_TIMEOUT", environment_variables.EnvironmentVariables.PROVIDER_CONNECT_TIMEOUT, DefaultProviderConnectTimeout)
+}
+
+// providerRequestTimeout returns PROVIDER_REQUEST_TIMEOUT. Resty applies it only to requests
+// whose context has no deadline, so callers with their own deadline keep it.
+func providerRequestTimeout() time.Duration {
+	return durationFromEnv("PROVIDER_REQUEST_TIMEOUT", environment_variables.EnvironmentVariables.PROVIDER_REQUEST_TIMEOUT, DefaultProviderRequestTimeout)
+}
+
+// durationFromEnv parses a positive Go duration such as 5s, falling back to the default when the
+// value is unset or invalid.
+func durationFromEnv(name string, value string, fallback time.Duration) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		logger.GetLogger().Errorf("invalid %s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return timeout
+}
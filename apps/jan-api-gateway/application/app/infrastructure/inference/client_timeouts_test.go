@@ -0,0 +1,89 @@
+package inference
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestDurationFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "unset", value: "", want: time.Minute},
+		{name: "duration", value: " 250ms ", want: 250 * time.Millisecond},
+		{name: "not a duration", value: "5", want: time.Minute},
+		{name: "negative", value: "-1s", want: time.Minute},
+	}
+	for _, tt := range tests {
+		if got := durationFromEnv("TEST_TIMEOUT", tt.value, time.Minute); got != tt.want {
+			t.Errorf("%s: durationFromEnv(%q) = %s, want %s", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+// stalledListener accepts connections but never answers, so a TLS handshake with it hangs.
+func stalledListener(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				break
+			}
+			conns = append(conns, conn)
+		}
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	t.Cleanup(func() { _ = listener.Close() })
+	return listener.Addr().String()
+}
+
+func TestUnreachableProviderFailsWithinConnectTimeout(t *testing.T) {
+	env := &environment_variables.EnvironmentVariables
+	previousConnect, previousRequest := env.PROVIDER_CONNECT_TIMEOUT, env.PROVIDER_REQUEST_TIMEOUT
+	env.PROVIDER_CONNECT_TIMEOUT, env.PROVIDER_REQUEST_TIMEOUT = "300ms", "10s"
+	t.Cleanup(func() {
+		env.PROVIDER_CONNECT_TIMEOUT, env.PROVIDER_REQUEST_TIMEOUT = previousConnect, previousRequest
+	})
+
+	tests := []struct {
+		name    string
+		baseURL string
+	}{
+		{name: "tls handshake never completes", baseURL: "https://" + stalledListener(t) + "/v1"},
+		// TEST-NET-1 is reserved for documentation and never routed.
+		{name: "dial to an unrouted address", baseURL: "http://192.0.2.1:81/v1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := NewInferenceProvider()
+			client, err := ip.GetChatModelClient(&domainmodel.Provider{DisplayName: "unreachable", Kind: domainmodel.ProviderOpenAI, BaseURL: tt.baseURL})
+			if err != nil {
+				t.Fatalf("GetChatModelClient: %v", err)
+			}
+			start := time.Now()
+			_, err = client.ListModels(context.Background())
+			elapsed := time.Since(start)
+			if err == nil {
+				t.Fatal("ListModels succeeded against an unreachable provider")
+			}
+			if elapsed > 3*time.Second {
+				t.Fatalf("ListModels failed after %s, want it to fail within the 300ms connect timeout rather than the 10s request timeout", elapsed)
+			}
+		})
+	}
+}
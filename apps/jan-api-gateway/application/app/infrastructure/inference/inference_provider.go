@@ -2,55 +2,126 @@ package inference
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"strings"
+	"time"
 
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/utils/crypto"
 	httpclients "menlo.ai/jan-api-gateway/app/utils/httpclients"
 	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"menlo.ai/jan-api-gateway/app/utils/retry"
 	"menlo.ai/jan-api-gateway/config/environment_variables"
 	"resty.dev/v3"
 )
 
 // InferenceProvider provides chat completion and model clients for providers
-type InferenceProvider struct{}
+type InferenceProvider struct {
+	breaker *circuitBreaker
+	metrics *providerMetrics
+	clients *providerClientCache
+}
+
+var _ domainmodel.ProviderAvailability = (*InferenceProvider)(nil)
 
 // NewInferenceProvider creates a new inference provider instance
 func NewInferenceProvider() *InferenceProvider {
-	return &InferenceProvider{}
+	return &InferenceProvider{
+		breaker: newCircuitBreakerFromEnv(),
+		metrics: newProviderMetrics(),
+		clients: newProviderClientCacheFromEnv(),
+	}
+}
+
+// WriteMetrics writes the per-provider request metrics in the Prometheus text format.
+func (ip *InferenceProvider) WriteMetrics(w io.Writer) error {
+	return ip.metrics.write(w)
+}
+
+// CircuitState returns the circuit breaker state for the provider.
+func (ip *InferenceProvider) CircuitState(providerID uint) CircuitState {
+	return ip.breaker.state(providerID)
 }
 
-// GetChatCompletionClient returns a chat completion client configured for the provider
+// IsProviderAvailable reports whether completions may currently be sent to the provider.
+func (ip *InferenceProvider) IsProviderAvailable(providerID uint) bool {
+	return ip.CircuitState(providerID) != CircuitOpen
+}
+
+// GetChatCompletionClient returns a chat completion client configured for the provider.
+// It fails fast with ErrProviderCircuitOpen while the provider's circuit is open. A half-open
+// probe claimed here is released on every path that ends without an upstream answer.
 func (ip *InferenceProvider) GetChatCompletionClient(provider *domainmodel.Provider) (*chatclient.ChatCompletionClient, error) {
-	client, err := ip.createRestyClient(provider)
+	if provider.ID != 0 && !ip.breaker.allow(provider.ID) {
+		ip.metrics.reject(provider.Slug)
+		return nil, fmt.Errorf("%s: %w", provider.DisplayName, ErrProviderCircuitOpen)
+	}
+
+	client, err := ip.restyClient(provider)
 	if err != nil {
+		ip.breaker.release(provider.ID)
 		return nil, err
 	}
 
+	options, err := clientOptions(provider)
+	if err != nil {
+		ip.breaker.release(provider.ID)
+		return nil, err
+	}
+	if provider.ID != 0 {
+		providerID := provider.ID
+		options = append(options, chatclient.WithUnsentRequestHook(func() {
+			ip.breaker.release(providerID)
+		}))
+	}
+
 	clientName := provider.DisplayName
-	return chatclient.NewChatCompletionClient(client, clientName, provider.BaseURL), nil
+	return chatclient.NewChatCompletionClient(client, clientName, provider.BaseURL, options...), nil
 }
 
 // GetChatModelClient returns a chat model client configured for the provider
 func (ip *InferenceProvider) GetChatModelClient(provider *domainmodel.Provider) (*chatclient.ChatModelClient, error) {
-	client, err := ip.createRestyClient(provider)
+	client, err := ip.restyClient(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := clientOptions(provider)
 	if err != nil {
 		return nil, err
 	}
 
 	clientName := provider.DisplayName
-	return chatclient.NewChatModelClient(client, clientName, provider.BaseURL), nil
+	return chatclient.NewChatModelClient(client, clientName, provider.BaseURL, options...), nil
 }
 
-// ListModels retrieves the available models for the given provider.
+// listModelsRetryPolicy retries transient model listing failures so a blip does not fail a
+// registration or refresh.
+var listModelsRetryPolicy = retry.Policy{
+	Attempts:  3,
+	BaseDelay: 250 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+	Retryable: isTransientListModelsError,
+}
+
+// ListModels retrieves the available models for the given provider, retrying transient failures
+// with jittered exponential backoff.
 func (ip *InferenceProvider) ListModels(ctx context.Context, provider *domainmodel.Provider) ([]chatclient.Model, error) {
 	modelClient, err := ip.GetChatModelClient(provider)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := modelClient.ListModels(ctx)
+	var resp *chatclient.ModelsResponse
+	err = retry.Do(ctx, listModelsRetryPolicy, func(ctx context.Context) error {
+		var listErr error
+		resp, listErr = modelClient.ListModels(ctx)
+		return listErr
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -58,11 +129,53 @@ func (ip *InferenceProvider) ListModels(ctx context.Context, provider *domainmod
 	return resp.Data, nil
 }
 
+// isTransientListModelsError reports whether a model listing failure may succeed when retried:
+// network errors, timeouts and upstream 5xx or 429 responses. An open circuit is not retried.
+func isTransientListModelsError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrProviderCircuitOpen) {
+		return false
+	}
+	var upstreamErr *chatclient.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.StatusCode >= http.StatusInternalServerError || upstreamErr.StatusCode == http.StatusTooManyRequests
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// restyClient returns the provider's pooled resty client, creating it on first use.
+func (ip *InferenceProvider) restyClient(provider *domainmodel.Provider) (*resty.Client, error) {
+	return ip.clients.get(provider, func() (*resty.Client, error) {
+		return ip.createRestyClient(provider)
+	})
+}
+
 // createRestyClient creates a configured resty client for the provider
 func (ip *InferenceProvider) createRestyClient(provider *domainmodel.Provider) (*resty.Client, error) {
 	clientName := fmt.Sprintf("%sClient", provider.DisplayName)
-	client := httpclients.NewClient(clientName)
+	client := httpclients.NewClientWithTransportSettings(clientName, providerTransportSettings())
 	client.SetBaseURL(provider.BaseURL)
+	client.SetTimeout(providerRequestTimeout())
+	ip.trackCircuit(client, provider)
+	if provider.Kind == domainmodel.ProviderAWSBedrock {
+		if err := signBedrockRequests(client, provider); err != nil {
+			return nil, err
+		}
+	}
+	if provider.ID != 0 {
+		client.SetTransport(&inFlightTransport{
+			next:    client.Transport(),
+			metrics: ip.metrics,
+			slug:    provider.Slug,
+		})
+	}
+
+	if len(provider.Headers) > 0 {
+		client.SetHeaders(provider.Headers)
+	}
 
 	// Set authorization header if API key exists
 	if provider.EncryptedAPIKey != "" {
@@ -72,13 +185,101 @@ func (ip *InferenceProvider) createRestyClient(provider *domainmodel.Provider) (
 		}
 
 		if strings.TrimSpace(apiKey) != "" && strings.ToLower(apiKey) != "none" {
-			client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+			switch provider.Kind {
+			case domainmodel.ProviderAzureOpenAI:
+				client.SetHeader("api-key", apiKey)
+			case domainmodel.ProviderAnthropic:
+				client.SetHeader("x-api-key", apiKey)
+			case domainmodel.ProviderGemini:
+				client.SetHeader("x-goog-api-key", apiKey)
+			default:
+				client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+			}
 		}
 	}
 
+	if organization := provider.OpenAIOrganization(); organization != "" {
+		client.SetHeader("OpenAI-Organization", organization)
+	}
+
+	if provider.Kind == domainmodel.ProviderAnthropic {
+		anthropicVersion := strings.TrimSpace(provider.Metadata["anthropic_version"])
+		if anthropicVersion == "" {
+			anthropicVersion = chatclient.DefaultAnthropicVersion
+		}
+		client.SetHeader("anthropic-version", anthropicVersion)
+	}
+
 	return client, nil
 }
 
+// clientOptions selects the URL scheme of the provider's upstream API. Azure OpenAI takes its
+// api-version and AWS Bedrock its region from the provider metadata; Anthropic, Gemini and Bedrock
+// are spoken to through their native APIs, and OpenRouter also receives its routing fields from
+// the original request body.
+// Secret metadata values are decrypted here, as this is the only place they leave the database
+// in clear. A configured path prefix applies to every kind.
+func clientOptions(provider *domainmodel.Provider) ([]chatclient.ClientOption, error) {
+	var options []chatclient.ClientOption
+	switch provider.Kind {
+	case domainmodel.ProviderAnthropic:
+		options = append(options, chatclient.WithAnthropicMessages())
+	case domainmodel.ProviderGemini:
+		options = append(options, chatclient.WithGeminiGenerateContent())
+	case domainmodel.ProviderOpenRouter:
+		options = append(options, chatclient.WithOpenRouterPassthrough())
+	case domainmodel.ProviderAWSBedrock:
+		region := strings.TrimSpace(provider.Metadata[bedrockRegionKey])
+		if region == "" {
+			return nil, fmt.Errorf("bedrock provider metadata is missing %s", bedrockRegionKey)
+		}
+		options = append(options, chatclient.WithBedrockInvoke(region))
+	case domainmodel.ProviderAzureOpenAI:
+		metadata, err := domainmodel.DecryptMetadata(provider.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		apiVersion := metadata["api_version"]
+		if apiVersion == "" {
+			apiVersion = metadata["api-version"]
+		}
+		options = append(options, chatclient.WithAzureDeployments(apiVersion))
+	}
+	if provider.PathPrefix != "" {
+		options = append(options, chatclient.WithPathPrefix(provider.PathPrefix))
+	}
+	return options, nil
+}
+
+// trackCircuit feeds the outcome of every call made through the client into the provider's
+// circuit. Transient providers that were never persisted are not tracked.
+func (ip *InferenceProvider) trackCircuit(client *resty.Client, provider *domainmodel.Provider) {
+	if provider.ID == 0 {
+		return
+	}
+	providerID := provider.ID
+	client.OnSuccess(func(c *resty.Client, resp *resty.Response) {
+		if isCircuitFailure(resp.StatusCode(), nil) {
+			ip.breaker.recordFailure(providerID)
+			return
+		}
+		ip.breaker.recordSuccess(providerID)
+	})
+	client.OnError(func(req *resty.Request, err error) {
+		if isCircuitFailure(0, err) {
+			ip.breaker.recordFailure(providerID)
+			return
+		}
+		ip.breaker.release(providerID)
+	})
+	// Requests rejected before they are sent, or that panic, say nothing about the upstream
+	release := func(req *resty.Request, err error) {
+		ip.breaker.release(providerID)
+	}
+	client.OnInvalid(release)
+	client.OnPanic(release)
+}
+
 // decryptAPIKey decrypts the provider's encrypted API key
 func (ip *InferenceProvider) decryptAPIKey(encryptedAPIKey string) (string, error) {
 	if encryptedAPIKey == "" {
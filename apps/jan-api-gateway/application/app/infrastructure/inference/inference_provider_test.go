@@ -0,0 +1,181 @@
+package inference
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/utils/crypto"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+func TestClientOptionsAzureAPIVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		want     string
+	}{
+		{name: "api_version", metadata: map[string]string{"api_version": "2025-01-01-preview"}, want: "2025-01-01-preview"},
+		{name: "api-version", metadata: map[string]string{"api-version": "2024-06-01"}, want: "2024-06-01"},
+		{name: "unset", want: chatclient.DefaultAzureAPIVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path, apiVersion string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, apiVersion = r.URL.Path, r.URL.Query().Get("api-version")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+			}))
+			defer upstream.Close()
+
+			provider := &domainmodel.Provider{Kind: domainmodel.ProviderAzureOpenAI, BaseURL: upstream.URL, Metadata: tt.metadata}
+			options, err := clientOptions(provider)
+			if err != nil {
+				t.Fatalf("clientOptions: %v", err)
+			}
+			client := chatclient.NewChatModelClient(resty.New(), "azure", provider.BaseURL, options...)
+			if _, err := client.ListModels(context.Background()); err != nil {
+				t.Fatalf("ListModels: %v", err)
+			}
+			if path != "/openai/models" || apiVersion != tt.want {
+				t.Fatalf("listed models at %s?api-version=%s, want /openai/models?api-version=%s", path, apiVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderAuthHeaders(t *testing.T) {
+	previous := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET
+	environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = "test-secret"
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET = previous
+	})
+	encryptedKey, err := crypto.EncryptString("test-secret", "sk-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		kind     domainmodel.ProviderKind
+		metadata map[string]string
+		want     map[string]string
+	}{
+		{
+			name: "openai",
+			kind: domainmodel.ProviderOpenAI,
+			want: map[string]string{"Authorization": "Bearer sk-test", "x-api-key": "", "anthropic-version": "", "OpenAI-Organization": ""},
+		},
+		{
+			name:     "openai with an organization",
+			kind:     domainmodel.ProviderOpenAI,
+			metadata: map[string]string{domainmodel.OpenAIOrganizationMetadataKey: " org-acme "},
+			want:     map[string]string{"Authorization": "Bearer sk-test", "OpenAI-Organization": "org-acme"},
+		},
+		{
+			name: "azure",
+			kind: domainmodel.ProviderAzureOpenAI,
+			want: map[string]string{"api-key": "sk-test", "Authorization": ""},
+		},
+		{
+			name: "anthropic",
+			kind: domainmodel.ProviderAnthropic,
+			want: map[string]string{"x-api-key": "sk-test", "anthropic-version": chatclient.DefaultAnthropicVersion, "Authorization": ""},
+		},
+		{
+			name:     "anthropic with a configured version",
+			kind:     domainmodel.ProviderAnthropic,
+			metadata: map[string]string{"anthropic_version": "2024-10-22"},
+			want:     map[string]string{"x-api-key": "sk-test", "anthropic-version": "2024-10-22"},
+		},
+		{
+			name: "gemini",
+			kind: domainmodel.ProviderGemini,
+			want: map[string]string{"x-goog-api-key": "sk-test", "Authorization": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer upstream.Close()
+
+			provider := &domainmodel.Provider{
+				DisplayName:     tt.name,
+				Kind:            tt.kind,
+				BaseURL:         upstream.URL,
+				EncryptedAPIKey: encryptedKey,
+				Metadata:        tt.metadata,
+			}
+			client, err := NewInferenceProvider().GetChatCompletionClient(provider)
+			if err != nil {
+				t.Fatalf("GetChatCompletionClient: %v", err)
+			}
+			if _, err := client.CreateChatCompletion(context.Background(), "", openai.ChatCompletionRequest{
+				Model:    "model",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			}); err != nil {
+				t.Fatalf("CreateChatCompletion: %v", err)
+			}
+			for name, want := range tt.want {
+				if got := header.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestListModelsRetriesTransientFailures(t *testing.T) {
+	previous := listModelsRetryPolicy
+	listModelsRetryPolicy.BaseDelay = 0
+	t.Cleanup(func() { listModelsRetryPolicy = previous })
+
+	tests := []struct {
+		name     string
+		statuses []int
+		calls    int
+		wantErr  bool
+	}{
+		{name: "transient then success", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, calls: 3},
+		{name: "persistent failure", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, calls: 3, wantErr: true},
+		{name: "rejected credentials are not retried", statuses: []int{http.StatusUnauthorized}, calls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				if calls <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[calls-1])
+					_, _ = w.Write([]byte(`{"error":{"message":"unavailable"}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o"}]}`))
+			}))
+			defer upstream.Close()
+
+			models, err := NewInferenceProvider().ListModels(context.Background(), &domainmodel.Provider{DisplayName: "upstream", BaseURL: upstream.URL})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListModels error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Fatalf("upstream called %d times, want %d", calls, tt.calls)
+			}
+			if !tt.wantErr && (len(models) != 1 || models[0].ID != "gpt-4o") {
+				t.Fatalf("models = %+v, want gpt-4o", models)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package inference
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// providerMetrics keeps per-provider request gauges and counters, keyed by provider slug.
+type providerMetrics struct {
+	mu         sync.Mutex
+	inFlight   map[string]int64
+	rejections map[string]int64
+}
+
+func newProviderMetrics() *providerMetrics {
+	return &providerMetrics{
+		inFlight:   make(map[string]int64),
+		rejections: make(map[string]int64),
+	}
+}
+
+func (m *providerMetrics) acquire(slug string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[slug]++
+}
+
+func (m *providerMetrics) release(slug string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight[slug] > 0 {
+		m.inFlight[slug]--
+	}
+}
+
+func (m *providerMetrics) reject(slug string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejections[slug]++
+}
+
+// inFlightTransport counts a request as in flight from the moment it is sent until its
+// response body is closed, so streams are tracked for their whole lifetime.
+type inFlightTransport struct {
+	next    http.RoundTripper
+	metrics *providerMetrics
+	slug    string
+}
+
+func (t *inFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.metrics.acquire(t.slug)
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		t.metrics.release(t.slug)
+		return resp, err
+	}
+	body := &inFlightBody{ReadCloser: resp.Body}
+	body.release = func() { t.metrics.release(t.slug) }
+	resp.Body = body
+	return resp, nil
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the wrapped transport.
+func (t *inFlightTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+type inFlightBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *inFlightBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// write renders the metrics in the Prometheus text exposition format.
+func (m *providerMetrics) write(w io.Writer) error {
+	m.mu.Lock()
+	inFlight := copyCounts(m.inFlight)
+	rejections := copyCounts(m.rejections)
+	m.mu.Unlock()
+
+	if err := writeMetricFamily(w, "jan_provider_in_flight_requests", "gauge",
+		"Upstream requests currently in flight per provider.", inFlight); err != nil {
+		return err
+	}
+	return writeMetricFamily(w, "jan_provider_rejections_total", "counter",
+		"Requests rejected without reaching the provider because its circuit was open.", rejections)
+}
+
+func copyCounts(src map[string]int64) map[string]int64 {
+	dst := make(map[string]int64, len(src))
+	for key, value := range src {
+		dst[key] = value
+	}
+	return dst
+}
+
+func writeMetricFamily(w io.Writer, name, kind, help string, values map[string]int64) error {
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind); err != nil {
+		return err
+	}
+	slugs := make([]string, 0, len(values))
+	for slug := range values {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	for _, slug := range slugs {
+		if _, err := fmt.Fprintf(w, "%s{provider=\"%s\"} %d\n", name, labelEscaper.Replace(slug), values[slug]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,125 @@
+package inference
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+func (m *providerMetrics) inFlightOf(slug string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight[slug]
+}
+
+// waitInFlight waits for the provider's in-flight gauge to reach want; stream bodies are closed
+// by a background goroutine once the upstream is drained.
+func waitInFlight(t *testing.T, ip *InferenceProvider, slug string, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for ip.metrics.inFlightOf(slug) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s in flight = %d, want %d", slug, ip.metrics.inFlightOf(slug), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInFlightGaugeTracksStreams(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, release <-chan struct{})
+		openErr bool
+		readErr bool
+	}{
+		{
+			name: "stream finishes",
+			handler: func(w http.ResponseWriter, release <-chan struct{}) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")
+				w.(http.Flusher).Flush()
+				<-release
+				_, _ = io.WriteString(w, "data: [DONE]\n\n")
+			},
+		},
+		{
+			name: "stream breaks off",
+			handler: func(w http.ResponseWriter, release <-chan struct{}) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set("Content-Length", "1000")
+				_, _ = io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n")
+				w.(http.Flusher).Flush()
+				<-release
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					_ = conn.Close()
+				}
+			},
+			readErr: true,
+		},
+		{
+			name: "upstream rejects the stream",
+			handler: func(w http.ResponseWriter, release <-chan struct{}) {
+				http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+			},
+			openErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(w, release)
+			}))
+			defer upstream.Close()
+
+			ip := NewInferenceProvider()
+			provider := &domainmodel.Provider{ID: 1, Slug: "upstream", DisplayName: "upstream", BaseURL: upstream.URL}
+			client, err := ip.GetChatCompletionClient(provider)
+			if err != nil {
+				t.Fatalf("GetChatCompletionClient: %v", err)
+			}
+			stream, err := client.CreateChatCompletionStream(context.Background(), "", openai.ChatCompletionRequest{
+				Model:    "model",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+				Stream:   true,
+			})
+			if tt.openErr {
+				if err == nil {
+					t.Fatal("CreateChatCompletionStream succeeded, want the upstream error")
+				}
+				waitInFlight(t, ip, provider.Slug, 0)
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream: %v", err)
+			}
+			defer stream.Close()
+
+			// The stream stays in flight until the upstream is done with it
+			waitInFlight(t, ip, provider.Slug, 1)
+			close(release)
+			_, readErr := io.ReadAll(stream)
+			if (readErr != nil) != tt.readErr {
+				t.Fatalf("reading the stream returned %v, want an error: %t", readErr, tt.readErr)
+			}
+			waitInFlight(t, ip, provider.Slug, 0)
+
+			var metrics bytes.Buffer
+			if err := ip.WriteMetrics(&metrics); err != nil {
+				t.Fatalf("WriteMetrics: %v", err)
+			}
+			if !strings.Contains(metrics.String(), `jan_provider_in_flight_requests{provider="upstream"} 0`) {
+				t.Fatalf("metrics do not report the stream as finished:\n%s", metrics.String())
+			}
+		})
+	}
+}
@@ -2,11 +2,14 @@ package infrastructure
 
 import (
 	"github.com/google/wire"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
 )
 
 var InfrastructureProvider = wire.NewSet(
 	inference.NewInferenceProvider,
+	wire.Bind(new(domainmodel.ProviderModelLister), new(*inference.InferenceProvider)),
+	wire.Bind(new(domainmodel.ProviderAvailability), new(*inference.InferenceProvider)),
 	cache.NewRedisCacheService,
 )
@@ -7,9 +7,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/middleware"
 	v1 "menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1"
 	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/metrics"
 	"menlo.ai/jan-api-gateway/config"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -18,8 +20,9 @@ import (
 )
 
 type HttpServer struct {
-	engine  *gin.Engine
-	v1Route *v1.V1Route
+	engine            *gin.Engine
+	v1Route           *v1.V1Route
+	inferenceProvider *inference.InferenceProvider
 }
 
 func (s *HttpServer) bindSwagger() {
@@ -44,13 +47,30 @@ func (s *HttpServer) bindDev() {
 
 }
 
-func NewHttpServer(v1Route *v1.V1Route) *HttpServer {
+// MetricsHandler serves the Prometheus metrics. The metrics are unauthenticated, so main mounts
+// the handler on the internal profiling listener rather than on the public engine.
+func (s *HttpServer) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(http.StatusOK)
+		if err := s.inferenceProvider.WriteMetrics(w); err != nil {
+			logger.GetLogger().Errorf("failed to write metrics: %v", err)
+			return
+		}
+		if err := metrics.Write(w); err != nil {
+			logger.GetLogger().Errorf("failed to write metrics: %v", err)
+		}
+	})
+}
+
+func NewHttpServer(v1Route *v1.V1Route, inferenceProvider *inference.InferenceProvider) *HttpServer {
 	if os.Getenv("local_dev") == "" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	server := HttpServer{
 		gin.New(),
 		v1Route,
+		inferenceProvider,
 	}
 	// TODO: we should enable cors later
 	server.engine.Use(middleware.CORS())
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// DefaultMaxRequestBodyBytes is the body size limit used when MAX_REQUEST_BODY_BYTES is unset.
+const DefaultMaxRequestBodyBytes = 10 << 20
+
+// ErrCodeRequestBodyTooLarge is returned with 413 when a request body exceeds the limit.
+const ErrCodeRequestBodyTooLarge = "8d3f6b1e-4a7c-4e92-b5d0-c2e9a7f41b36"
+
+// MaxRequestBodyBytes returns MAX_REQUEST_BODY_BYTES, falling back to the default when it is unset
+// or not positive.
+func MaxRequestBodyBytes() int64 {
+	if limit := environment_variables.EnvironmentVariables.MAX_REQUEST_BODY_BYTES; limit > 0 {
+		return int64(limit)
+	}
+	return DefaultMaxRequestBodyBytes
+}
+
+// BodyLimit rejects requests whose body is larger than the limit. A declared Content-Length over
+// the limit is rejected before anything is read; otherwise the body is wrapped so reading past the
+// limit fails, which handlers report with AbortBodyTooLarge.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			AbortBodyTooLarge(c, limit)
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge reports whether err comes from reading a body past the BodyLimit limit.
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// AbortBodyTooLarge aborts the request with 413.
+func AbortBodyTooLarge(c *gin.Context, limit int64) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, responses.ErrorResponse{
+		Code:  ErrCodeRequestBodyTooLarge,
+		Error: fmt.Sprintf("request body exceeds the limit of %d bytes", limit),
+	})
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// unsizedReader hides the body length so httptest sends it without a Content-Length.
+type unsizedReader struct {
+	io.Reader
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const limit = 16
+
+	tests := []struct {
+		name           string
+		body           io.Reader
+		status         int
+		reachesHandler bool
+	}{
+		{name: "declared length under the limit", body: strings.NewReader(`{"a":1}`), status: http.StatusOK, reachesHandler: true},
+		{name: "declared length at the limit", body: strings.NewReader(strings.Repeat("a", limit)), status: http.StatusOK, reachesHandler: true},
+		{name: "declared length over the limit", body: strings.NewReader(strings.Repeat("a", limit+1)), status: http.StatusRequestEntityTooLarge},
+		{name: "unknown length under the limit", body: unsizedReader{strings.NewReader(`{"a":1}`)}, status: http.StatusOK, reachesHandler: true},
+		{name: "unknown length over the limit", body: unsizedReader{strings.NewReader(strings.Repeat("a", 4*limit))}, status: http.StatusRequestEntityTooLarge, reachesHandler: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerRan := false
+			router := gin.New()
+			router.POST("/", BodyLimit(limit), func(c *gin.Context) {
+				handlerRan = true
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					if !IsBodyTooLarge(err) {
+						t.Errorf("read error %v is not a body limit error", err)
+					}
+					AbortBodyTooLarge(c, limit)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodPost, "/", tt.body)
+			if _, unsized := tt.body.(unsizedReader); unsized {
+				request.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			if handlerRan != tt.reachesHandler {
+				t.Fatalf("handler ran = %t, want %t", handlerRan, tt.reachesHandler)
+			}
+			if tt.status == http.StatusRequestEntityTooLarge {
+				var response responses.ErrorResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+					t.Fatalf("decode 413 body: %v", err)
+				}
+				if response.Code != ErrCodeRequestBodyTooLarge {
+					t.Fatalf("code = %q, want %q", response.Code, ErrCodeRequestBodyTooLarge)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxRequestBodyBytes(t *testing.T) {
+	env := &environment_variables.EnvironmentVariables
+	previous := env.MAX_REQUEST_BODY_BYTES
+	defer func() { env.MAX_REQUEST_BODY_BYTES = previous }()
+
+	tests := []struct {
+		name  string
+		value int
+		want  int64
+	}{
+		{name: "unset", want: DefaultMaxRequestBodyBytes},
+		{name: "negative", value: -1, want: DefaultMaxRequestBodyBytes},
+		{name: "configured", value: 2048, want: 2048},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.MAX_REQUEST_BODY_BYTES = tt.value
+			if got := MaxRequestBodyBytes(); got != tt.want {
+				t.Fatalf("MaxRequestBodyBytes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// DefaultCompressionMinBytes is the smallest response compressed when
+// RESPONSE_COMPRESSION_MIN_BYTES is unset.
+const DefaultCompressionMinBytes = 1024
+
+func compressionMinBytes() int {
+	if minBytes := environment_variables.EnvironmentVariables.RESPONSE_COMPRESSION_MIN_BYTES; minBytes > 0 {
+		return minBytes
+	}
+	return DefaultCompressionMinBytes
+}
+
+// Compress gzips JSON responses of at least RESPONSE_COMPRESSION_MIN_BYTES for clients that accept
+// gzip, when ENABLE_RESPONSE_COMPRESSION is set. Responses are buffered only up to the threshold.
+// Streams are never compressed: a response that flushes, sends its headers early or is not JSON is
+// written through unchanged, so SSE events still reach the client as they are produced.
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !environment_variables.EnvironmentVariables.ENABLE_RESPONSE_COMPRESSION ||
+			!strings.Contains(strings.ToLower(c.GetHeader("Accept-Encoding")), "gzip") {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer, minBytes: compressionMinBytes()}
+		c.Writer = writer
+		defer func() {
+			if err := writer.finish(); err != nil {
+				logger.GetLogger().Errorf("failed to write compressed response: %v", err)
+			}
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// gzipResponseWriter holds back the start of a response until it knows whether to compress it.
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	minBytes    int
+	buffer      bytes.Buffer
+	gzip        *gzip.Writer
+	passthrough bool
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	switch {
+	case w.gzip != nil:
+		return w.gzip.Write(data)
+	case w.passthrough:
+		return w.ResponseWriter.Write(data)
+	case !w.compressible():
+		if err := w.pass(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer.Write(data)
+	if w.buffer.Len() >= w.minBytes {
+		if err := w.startGzip(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow is how streaming handlers send their headers before the first event.
+func (w *gzipResponseWriter) WriteHeaderNow() {
+	if w.gzip == nil && !w.passthrough {
+		_ = w.pass()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gzip != nil {
+		_ = w.gzip.Flush()
+	} else if !w.passthrough {
+		_ = w.pass()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipResponseWriter) compressible() bool {
+	contentType := w.Header().Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/json") && w.Header().Get("Content-Encoding") == ""
+}
+
+// pass stops compressing and writes whatever was held back as is.
+func (w *gzipResponseWriter) pass() error {
+	w.passthrough = true
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+func (w *gzipResponseWriter) startGzip() error {
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	w.gzip = gzip.NewWriter(w.ResponseWriter)
+	_, err := w.gzip.Write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+// finish completes the response: the gzip stream is closed, and a response that stayed below the
+// threshold is written uncompressed.
+func (w *gzipResponseWriter) finish() error {
+	if w.gzip != nil {
+		return w.gzip.Close()
+	}
+	return w.pass()
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := &environment_variables.EnvironmentVariables
+	previousEnabled, previousMinBytes := env.ENABLE_RESPONSE_COMPRESSION, env.RESPONSE_COMPRESSION_MIN_BYTES
+	defer func() {
+		env.ENABLE_RESPONSE_COMPRESSION, env.RESPONSE_COMPRESSION_MIN_BYTES = previousEnabled, previousMinBytes
+	}()
+	env.RESPONSE_COMPRESSION_MIN_BYTES = 64
+
+	largeJSON := `{"data":"` + strings.Repeat("a", 256) + `"}`
+	events := []string{"data: {\"delta\":\"" + strings.Repeat("b", 64) + "\"}\n\n", "data: [DONE]\n\n"}
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		acceptEncoding string
+		handler        gin.HandlerFunc
+		want           string
+		compressed     bool
+	}{
+		{
+			name:           "large JSON is compressed",
+			enabled:        true,
+			acceptEncoding: "gzip, deflate",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(largeJSON)) },
+			want:           largeJSON,
+			compressed:     true,
+		},
+		{
+			name:           "small JSON is not compressed",
+			enabled:        true,
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(`{"ok":true}`)) },
+			want:           `{"ok":true}`,
+		},
+		{
+			name:           "client without gzip",
+			enabled:        true,
+			acceptEncoding: "",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(largeJSON)) },
+			want:           largeJSON,
+		},
+		{
+			name:           "compression disabled",
+			acceptEncoding: "gzip",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(largeJSON)) },
+			want:           largeJSON,
+		},
+		{
+			name:           "event stream is not compressed",
+			enabled:        true,
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "text/event-stream")
+				c.Writer.WriteHeaderNow()
+				for _, event := range events {
+					_, _ = c.Writer.WriteString(event)
+					c.Writer.Flush()
+				}
+			},
+			want: strings.Join(events, ""),
+		},
+		{
+			name:           "flushed JSON stream is not compressed",
+			enabled:        true,
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "application/json")
+				_, _ = c.Writer.WriteString(`{"part":1}`)
+				c.Writer.Flush()
+				_, _ = c.Writer.WriteString(largeJSON)
+			},
+			want: `{"part":1}` + largeJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.ENABLE_RESPONSE_COMPRESSION = tt.enabled
+			router := gin.New()
+			router.GET("/", Compress(), tt.handler)
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				request.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			compressed := recorder.Header().Get("Content-Encoding") == "gzip"
+			if compressed != tt.compressed {
+				t.Fatalf("compressed = %t, want %t", compressed, tt.compressed)
+			}
+			var body io.Reader = recorder.Body
+			if compressed {
+				reader, err := gzip.NewReader(recorder.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				body = reader
+			}
+			got, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
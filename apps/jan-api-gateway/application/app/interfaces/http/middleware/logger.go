@@ -34,12 +34,17 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		c.Request = c.Request.WithContext(ctx)
 		c.Writer.Header().Set("X-Request-ID", requestID)
 
-		// Read request body
+		// Read request body, at most up to the body size limit so oversized payloads are not
+		// buffered here before BodyLimit rejects them
 		var reqBody []byte
 		if c.Request.Body != nil {
-			reqBody, _ = io.ReadAll(c.Request.Body)
+			body := c.Request.Body
+			reqBody, _ = io.ReadAll(io.LimitReader(body, MaxRequestBodyBytes()+1))
 			// Restore body so Gin can read it again
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(reqBody))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(reqBody), body), body}
 		}
 
 		// Wrap writer only if not streaming
@@ -56,7 +61,8 @@ func LoggerMiddleware(logger *logrus.Logger) gin.HandlerFunc {
 		// Log everything
 		duration := time.Since(start)
 		responseBody := ""
-		if !isStream {
+		// Compressed bodies are not readable in the log
+		if !isStream && c.Writer.Header().Get("Content-Encoding") == "" {
 			responseBody = blw.body.String()
 		}
 		logger.WithFields(logrus.Fields{
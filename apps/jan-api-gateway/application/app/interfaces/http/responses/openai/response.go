@@ -44,3 +44,15 @@ type DeleteResponse struct {
 	ID      string `json:"id"`
 	Deleted bool   `json:"deleted"`
 }
+
+// ErrorResponse is the OpenAI error envelope returned by the OpenAI-compatible endpoints.
+type ErrorResponse struct {
+	Error ErrorObject `json:"error"`
+}
+
+type ErrorObject struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
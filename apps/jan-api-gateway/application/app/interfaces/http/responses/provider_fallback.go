@@ -0,0 +1,23 @@
+package responses
+
+import (
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+const (
+	// ProviderFallbackHeader is set to "true" when the request was served by a default provider
+	// because no matched provider could serve the model.
+	ProviderFallbackHeader = "x-jan-provider-fallback"
+	// FallbackReasonHeader carries the sanitized reason for the fallback.
+	FallbackReasonHeader = "x-jan-fallback-reason"
+)
+
+// SetProviderFallbackHeaders sets the fallback headers when reason is not empty.
+func SetProviderFallbackHeaders(reqCtx *gin.Context, reason domainmodel.FallbackReason) {
+	if reason == "" {
+		return
+	}
+	reqCtx.Header(ProviderFallbackHeader, "true")
+	reqCtx.Header(FallbackReasonHeader, string(reason))
+}
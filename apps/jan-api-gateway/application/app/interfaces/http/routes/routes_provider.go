@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/google/wire"
 	v1 "menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/admin"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/auth"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/auth/google"
 	chat "menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/chat"
@@ -24,10 +25,12 @@ var RouteProvider = wire.NewSet(
 	projects.NewProjectsRoute,
 	organization.NewAdminApiKeyAPI,
 	organization.NewModelProviderRoute,
+	organization.NewOrganizationSettingsRoute,
 	organization.NewOrganizationRoute,
 	mcp_impl.NewSerperMCP,
 	chat.NewChatRoute,
 	chat.NewCompletionAPI,
+	chat.NewEmbeddingsAPI,
 	conv_chat.NewConvChatRoute,
 	conv_chat.NewConvCompletionAPI,
 	conv_chat.NewConvMCPAPI,
@@ -38,6 +41,7 @@ var RouteProvider = wire.NewSet(
 	modelroute.NewModelAPI,
 	modelroute.NewProvidersAPI,
 	responses.NewResponseRoute,
+	admin.NewAdminProviderRoute,
 	v1.NewV1Route,
 	conversations.NewConversationAPI,
 	invites.NewInvitesRoute,
@@ -0,0 +1,254 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+// maxAdminProvidersPageLimit caps the limit query parameter of the provider listing.
+const maxAdminProvidersPageLimit = 100
+
+// AdminProviderRoute serves the platform admin view of providers across all organizations.
+type AdminProviderRoute struct {
+	authService         *auth.AuthService
+	providerRegistry    *domainmodel.ProviderRegistryService
+	organizationService *organization.OrganizationService
+	projectService      *project.ProjectService
+}
+
+func NewAdminProviderRoute(
+	authService *auth.AuthService,
+	providerRegistry *domainmodel.ProviderRegistryService,
+	organizationService *organization.OrganizationService,
+	projectService *project.ProjectService,
+) *AdminProviderRoute {
+	return &AdminProviderRoute{
+		authService:         authService,
+		providerRegistry:    providerRegistry,
+		organizationService: organizationService,
+		projectService:      projectService,
+	}
+}
+
+func (route *AdminProviderRoute) RegisterRouter(router *gin.RouterGroup) {
+	group := router.Group("/admin",
+		route.authService.AdminUserAuthMiddleware(),
+		route.authService.RegisteredUserMiddleware(),
+		route.authService.PlatformAdminMiddleware(),
+	)
+	group.GET("/providers", route.listProviders)
+}
+
+type adminProviderResponse struct {
+	ID                string            `json:"id"`
+	Slug              string            `json:"slug"`
+	Name              string            `json:"name"`
+	Vendor            string            `json:"vendor"`
+	BaseURL           string            `json:"base_url"`
+	OrganizationID    *string           `json:"organization_id,omitempty"`
+	ProjectID         *string           `json:"project_id,omitempty"`
+	Active            bool              `json:"active"`
+	Shadow            bool              `json:"shadow"`
+	KeyMode           string            `json:"key_mode"`
+	APIKeyHint        *string           `json:"api_key_hint,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	LastSyncedAt      *time.Time        `json:"last_synced_at,omitempty"`
+	HealthStatus      string            `json:"health_status"`
+	LastHealthCheckAt *time.Time        `json:"last_health_check_at,omitempty"`
+	LastHealthError   *string           `json:"last_health_error,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// listProviders godoc
+// @Summary List providers across all organizations
+// @Description Lists every registered provider regardless of organization or project. Only platform admins (PLATFORM_ADMIN_EMAILS) may call it. API keys are never returned, only their hints.
+// @Tags Platform Admin API
+// @Security BearerAuth
+// @Produce json
+// @Param vendor query string false "Filter by vendor"
+// @Param active query bool false "Filter by active state"
+// @Param health query string false "Filter by health status: healthy, unhealthy or unchecked"
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param last query string false "Provider ID to continue after"
+// @Param order query string false "asc or desc" default(asc)
+// @Success 200 {object} responses.ListResponse[adminProviderResponse]
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 401 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse
+// @Router /v1/admin/providers [get]
+func (route *AdminProviderRoute) listProviders(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+
+	filter := domainmodel.ProviderFilter{}
+	if vendor := strings.TrimSpace(reqCtx.Query("vendor")); vendor != "" {
+		kind := domainmodel.ProviderKind(strings.ToLower(vendor))
+		filter.Kind = &kind
+	}
+	if activeStr := reqCtx.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "2f9b4c71-6e3a-4d58-a0c2-b8e5d1f7a934",
+				Error: "invalid active value",
+			})
+			return
+		}
+		filter.Active = &active
+	}
+	if health := strings.ToLower(strings.TrimSpace(reqCtx.Query("health"))); health != "" {
+		status := domainmodel.ProviderHealthStatus(health)
+		switch status {
+		case domainmodel.ProviderHealthHealthy, domainmodel.ProviderHealthUnhealthy, domainmodel.ProviderHealthUnchecked:
+			filter.HealthStatus = &status
+		default:
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "c6a1e8f3-9b24-4f70-8d5e-3a7c0b92e1d6",
+				Error: "health must be healthy, unhealthy or unchecked",
+			})
+			return
+		}
+	}
+
+	pagination, err := query.GetCursorPaginationFromQuery(reqCtx, func(lastID string) (*uint, error) {
+		provider, findErr := route.providerRegistry.FindByPublicID(ctx, lastID)
+		if findErr != nil {
+			return nil, findErr
+		}
+		return &provider.ID, nil
+	})
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "81d5f2a9-4c6b-4e37-b9f0-d2a8e6c3715b",
+			Error: "invalid pagination parameters",
+		})
+		return
+	}
+	if pagination.Limit != nil && *pagination.Limit > maxAdminProvidersPageLimit {
+		pagination.Limit = ptr.ToInt(maxAdminProvidersPageLimit)
+	}
+
+	providers, listErr := route.providerRegistry.ListProviders(ctx, filter, pagination)
+	if listErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  listErr.GetCode(),
+			Error: listErr.Error(),
+		})
+		return
+	}
+	total, countErr := route.providerRegistry.CountProviders(ctx, filter)
+	if countErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  countErr.GetCode(),
+			Error: countErr.Error(),
+		})
+		return
+	}
+
+	organizationIDs := map[uint]*string{}
+	projectIDs := map[uint]*string{}
+	results := make([]adminProviderResponse, 0, len(providers))
+	for _, provider := range providers {
+		item := adminProviderResponse{
+			ID:                provider.PublicID,
+			Slug:              provider.Slug,
+			Name:              provider.DisplayName,
+			Vendor:            strings.ToLower(string(provider.Kind)),
+			BaseURL:           provider.BaseURL,
+			Active:            provider.Active,
+			Shadow:            provider.Shadow,
+			KeyMode:           string(provider.KeyMode),
+			APIKeyHint:        provider.APIKeyHint,
+			Metadata:          domainmodel.MaskedMetadata(provider.Metadata),
+			LastSyncedAt:      provider.LastSyncedAt,
+			HealthStatus:      string(provider.HealthStatus()),
+			LastHealthCheckAt: provider.LastHealthCheckAt,
+			LastHealthError:   provider.LastHealthError,
+			CreatedAt:         provider.CreatedAt,
+		}
+		if provider.OrganizationID != nil {
+			item.OrganizationID = route.organizationPublicID(ctx, organizationIDs, *provider.OrganizationID)
+		}
+		if provider.ProjectID != nil {
+			item.ProjectID = route.projectPublicID(ctx, projectIDs, *provider.ProjectID)
+		}
+		results = append(results, item)
+	}
+
+	var firstID *string
+	var lastID *string
+	hasMore := false
+	if len(providers) > 0 {
+		last := providers[len(providers)-1]
+		firstID = ptr.ToString(providers[0].PublicID)
+		lastID = ptr.ToString(last.PublicID)
+		more, moreErr := route.providerRegistry.ListProviders(ctx, filter, &query.Pagination{
+			Order: pagination.Order,
+			Limit: ptr.ToInt(1),
+			After: &last.ID,
+		})
+		if moreErr != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:  moreErr.GetCode(),
+				Error: moreErr.Error(),
+			})
+			return
+		}
+		hasMore = len(more) > 0
+	}
+
+	reqCtx.JSON(http.StatusOK, responses.ListResponse[adminProviderResponse]{
+		Status:  responses.ResponseCodeOk,
+		Total:   total,
+		Results: results,
+		FirstID: firstID,
+		LastID:  lastID,
+		HasMore: hasMore,
+	})
+}
+
+// organizationPublicID resolves an organization ID once per listing. The ID is informational, so a
+// lookup failure is logged and the field left out.
+func (route *AdminProviderRoute) organizationPublicID(ctx context.Context, seen map[uint]*string, id uint) *string {
+	if publicID, ok := seen[id]; ok {
+		return publicID
+	}
+	var publicID *string
+	org, err := route.organizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to find organization %d: %v", id, err)
+	} else if org != nil {
+		publicID = ptr.ToString(org.PublicID)
+	}
+	seen[id] = publicID
+	return publicID
+}
+
+// projectPublicID resolves a project ID once per listing, like organizationPublicID.
+func (route *AdminProviderRoute) projectPublicID(ctx context.Context, seen map[uint]*string, id uint) *string {
+	if publicID, ok := seen[id]; ok {
+		return publicID
+	}
+	var publicID *string
+	proj, err := route.projectService.FindProjectByID(ctx, id)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to find project %d: %v", id, err)
+	} else if proj != nil {
+		publicID = ptr.ToString(proj.PublicID)
+	}
+	seen[id] = publicID
+	return publicID
+}
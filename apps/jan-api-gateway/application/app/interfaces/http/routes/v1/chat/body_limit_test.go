@@ -0,0 +1,65 @@
+package chat
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/middleware"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestOversizedBodiesAreRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	env := &environment_variables.EnvironmentVariables
+	previous := env.MAX_REQUEST_BODY_BYTES
+	defer func() { env.MAX_REQUEST_BODY_BYTES = previous }()
+	env.MAX_REQUEST_BODY_BYTES = 64
+
+	body := `{"model":"gpt","messages":[{"role":"user","content":"` + strings.Repeat("a", 256) + `"}]}`
+	tests := []struct {
+		name    string
+		path    string
+		handler gin.HandlerFunc
+		chunked bool
+	}{
+		{name: "completion with a declared length", path: "/v1/chat/completions", handler: (&CompletionAPI{}).PostCompletion},
+		{name: "completion with a chunked body", path: "/v1/chat/completions", handler: (&CompletionAPI{}).PostCompletion, chunked: true},
+		{name: "embeddings with a declared length", path: "/v1/embeddings", handler: (&EmbeddingsAPI{}).PostEmbeddings},
+		{name: "embeddings with a chunked body", path: "/v1/embeddings", handler: (&EmbeddingsAPI{}).PostEmbeddings, chunked: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.POST(tt.path, middleware.BodyLimit(middleware.MaxRequestBodyBytes()), tt.handler)
+
+			var reader io.Reader = strings.NewReader(body)
+			if tt.chunked {
+				reader = io.MultiReader(reader)
+			}
+			request := httptest.NewRequest(http.MethodPost, tt.path, reader)
+			request.Header.Set("Content-Type", "application/json")
+			if tt.chunked {
+				request.ContentLength = -1
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want 413: %s", recorder.Code, recorder.Body.String())
+			}
+			var response responses.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode 413 body: %v", err)
+			}
+			if response.Code != middleware.ErrCodeRequestBodyTooLarge {
+				t.Fatalf("code = %q, want %q", response.Code, middleware.ErrCodeRequestBodyTooLarge)
+			}
+		})
+	}
+}
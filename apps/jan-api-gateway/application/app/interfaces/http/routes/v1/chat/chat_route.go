@@ -6,13 +6,16 @@ import (
 
 type ChatRoute struct {
 	completionAPI *CompletionAPI
+	embeddingsAPI *EmbeddingsAPI
 }
 
 func NewChatRoute(
 	completionAPI *CompletionAPI,
+	embeddingsAPI *EmbeddingsAPI,
 ) *ChatRoute {
 	return &ChatRoute{
 		completionAPI: completionAPI,
+		embeddingsAPI: embeddingsAPI,
 	}
 }
 
@@ -20,4 +23,6 @@ func (chatRoute *ChatRoute) RegisterRouter(router gin.IRouter) {
 	// Register /v1/chat routes
 	chatRouter := router.Group("/chat")
 	chatRoute.completionAPI.RegisterRouter(chatRouter)
+	// Register /v1/embeddings
+	chatRoute.embeddingsAPI.RegisterRouter(router)
 }
@@ -0,0 +1,40 @@
+package chat
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/tokenizer"
+)
+
+// skipContextCheckHeader disables the context length pre-flight for models whose recorded limits
+// are not trusted.
+const skipContextCheckHeader = "X-Jan-Skip-Context-Check"
+
+// checkContextLength rejects a request whose estimated prompt exceeds the context length recorded
+// for the model on the provider tried first, so it fails fast instead of at the upstream. Models
+// without recorded limits pass.
+func checkContextLength(reqCtx *gin.Context, model primaryModel, request openai.ChatCompletionRequest) bool {
+	if strings.EqualFold(strings.TrimSpace(reqCtx.GetHeader(skipContextCheckHeader)), "true") {
+		return true
+	}
+	providerModel := model.providerModel
+	if providerModel == nil || providerModel.TokenLimits == nil || providerModel.TokenLimits.ContextLength <= 0 {
+		return true
+	}
+
+	limit := providerModel.TokenLimits.ContextLength
+	estimate := tokenizer.EstimateTokens(request.Messages)
+	if estimate <= limit {
+		return true
+	}
+	reqCtx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, responses.ErrorResponse{
+		Code:  "9b3e7d1a-5c48-4f26-a0e9-d6f2b8c4a713",
+		Error: fmt.Sprintf("prompt is estimated at %d tokens, which exceeds the %d token context length of model %s", estimate, limit, request.Model),
+	})
+	return false
+}
@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/tokenizer"
+)
+
+func TestCheckContextLength(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	request := openai.ChatCompletionRequest{
+		Model: "tiny",
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "Summarize the history of the Roman Empire in three paragraphs."},
+		},
+	}
+	estimate := tokenizer.EstimateTokens(request.Messages)
+	withLimit := func(contextLength int) primaryModel {
+		return primaryModel{providerModel: &domainmodel.ProviderModel{
+			ModelKey:    "tiny",
+			TokenLimits: &domainmodel.TokenLimits{ContextLength: contextLength},
+		}}
+	}
+
+	tests := []struct {
+		name    string
+		model   primaryModel
+		header  string
+		allowed bool
+	}{
+		{name: "prompt over a tiny limit", model: withLimit(4)},
+		{name: "prompt at the limit", model: withLimit(estimate), allowed: true},
+		{name: "skip header", model: withLimit(4), header: "true", allowed: true},
+		{name: "skip header set to false", model: withLimit(4), header: "false"},
+		{name: "no recorded limits", model: primaryModel{providerModel: &domainmodel.ProviderModel{ModelKey: "tiny"}}, allowed: true},
+		{name: "unknown model", allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set(skipContextCheckHeader, tt.header)
+			}
+
+			if got := checkContextLength(reqCtx, tt.model, request); got != tt.allowed {
+				t.Fatalf("checkContextLength = %t, want %t", got, tt.allowed)
+			}
+			if tt.allowed {
+				if reqCtx.IsAborted() {
+					t.Fatal("permitted request was aborted")
+				}
+				return
+			}
+			if recorder.Code != http.StatusRequestEntityTooLarge {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusRequestEntityTooLarge)
+			}
+			var body responses.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			for _, want := range []string{"the 4 token context length", "estimated at " + strconv.Itoa(estimate) + " tokens"} {
+				if !strings.Contains(body.Error, want) {
+					t.Fatalf("error = %q, want it to mention %q", body.Error, want)
+				}
+			}
+		})
+	}
+}
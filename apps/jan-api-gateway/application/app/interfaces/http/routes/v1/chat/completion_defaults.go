@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// primaryModel is the requested model as served by the provider tried first, which the pre-flight
+// checks and catalog defaults are based on. Either field is nil when it could not be found.
+type primaryModel struct {
+	providerModel *domainmodel.ProviderModel
+	catalog       *domainmodel.ModelCatalog
+}
+
+// loadPrimaryModel looks up the provider model and its catalog entry. Lookup failures are only
+// logged, since the checks and defaults depending on them are best effort.
+func (cApi *CompletionAPI) loadPrimaryModel(ctx context.Context, provider *domainmodel.Provider, modelKey string) primaryModel {
+	var model primaryModel
+	if provider == nil || provider.ID == 0 {
+		return model
+	}
+	providerModel, err := cApi.providerRegistry.FindProviderModel(ctx, provider.ID, modelKey)
+	if err != nil {
+		logger.GetLogger().Warnf("unable to load model %s on provider %s: %v", modelKey, provider.Slug, err)
+		return model
+	}
+	model.providerModel = providerModel
+	catalog, err := cApi.providerRegistry.FindModelCatalog(ctx, providerModel)
+	if err != nil {
+		logger.GetLogger().Warnf("unable to load catalog of model %s: %v", modelKey, err)
+		return model
+	}
+	model.catalog = catalog
+	return model
+}
+
+// requestFields returns the top-level fields present in the raw request body, so parameters the
+// client sent as zero can be told apart from omitted ones.
+func requestFields(body []byte) map[string]struct{} {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+	fields := make(map[string]struct{}, len(raw))
+	for key := range raw {
+		fields[key] = struct{}{}
+	}
+	return fields
+}
+
+// applyCatalogDefaults fills the sampling parameters the client omitted with the defaults recorded
+// in the model's catalog. Parameters present in the request body are never overridden, and
+// defaults without an OpenAI request field are skipped.
+func applyCatalogDefaults(request *openai.ChatCompletionRequest, catalog *domainmodel.ModelCatalog, fields map[string]struct{}) {
+	if catalog == nil || fields == nil {
+		return
+	}
+	for name, value := range catalog.SupportedParameters.Default {
+		if value == nil {
+			continue
+		}
+		if _, sent := fields[name]; sent {
+			continue
+		}
+		switch name {
+		case "temperature":
+			request.Temperature = float32(value.Value.InexactFloat64())
+		case "top_p":
+			request.TopP = float32(value.Value.InexactFloat64())
+		case "frequency_penalty":
+			request.FrequencyPenalty = float32(value.Value.InexactFloat64())
+		case "presence_penalty":
+			request.PresencePenalty = float32(value.Value.InexactFloat64())
+		case "max_tokens":
+			if _, sent := fields["max_completion_tokens"]; !sent {
+				request.MaxTokens = int(value.Value.IntPart())
+			}
+		case "seed":
+			seed := int(value.Value.IntPart())
+			request.Seed = &seed
+		case "top_logprobs":
+			request.TopLogProbs = int(value.Value.IntPart())
+		}
+	}
+}
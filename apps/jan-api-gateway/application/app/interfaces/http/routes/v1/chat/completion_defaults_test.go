@@ -0,0 +1,135 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+)
+
+func TestApplyCatalogDefaultsKeepsIntegers(t *testing.T) {
+	// The catalog as stored: parameter defaults survive a JSON round trip through the database.
+	var parameters domainmodel.SupportedParameters
+	if err := json.Unmarshal([]byte(`{"names":["max_tokens","seed","temperature"],"default":{"max_tokens":4,"seed":"42","temperature":0.5}}`), &parameters); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	catalog := &domainmodel.ModelCatalog{SupportedParameters: parameters}
+
+	tests := []struct {
+		name   string
+		body   string
+		want   map[string]string
+		absent []string
+	}{
+		{
+			name: "omitted parameters take the defaults",
+			body: `{"model":"gpt","messages":[]}`,
+			want: map[string]string{"max_tokens": "4", "seed": "42", "temperature": "0.5"},
+		},
+		{
+			name: "sent parameters are kept",
+			body: `{"model":"gpt","messages":[],"max_tokens":100,"temperature":0}`,
+			want: map[string]string{"max_tokens": "100", "seed": "42"},
+			// A zero temperature is omitted from the outgoing request rather than replaced.
+			absent: []string{"temperature"},
+		},
+		{
+			name:   "max_completion_tokens suppresses the max_tokens default",
+			body:   `{"model":"gpt","messages":[],"max_completion_tokens":64}`,
+			want:   map[string]string{"max_completion_tokens": "64"},
+			absent: []string{"max_tokens"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request openai.ChatCompletionRequest
+			if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			applyCatalogDefaults(&request, catalog, requestFields([]byte(tt.body)))
+
+			data, err := json.Marshal(request)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var outgoing map[string]json.RawMessage
+			if err := json.Unmarshal(data, &outgoing); err != nil {
+				t.Fatalf("Unmarshal outgoing: %v", err)
+			}
+			for field, want := range tt.want {
+				if got := string(outgoing[field]); got != want {
+					t.Errorf("outgoing %s = %s, want %s", field, got, want)
+				}
+			}
+			for _, field := range tt.absent {
+				if got, ok := outgoing[field]; ok {
+					t.Errorf("outgoing request has %s = %s", field, got)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyCatalogDefaultTemperature(t *testing.T) {
+	var parameters domainmodel.SupportedParameters
+	if err := json.Unmarshal([]byte(`{"names":["temperature","top_p"],"default":{"temperature":0.3,"top_p":null}}`), &parameters); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	catalog := &domainmodel.ModelCatalog{SupportedParameters: parameters}
+
+	tests := []struct {
+		name        string
+		catalog     *domainmodel.ModelCatalog
+		body        string
+		temperature float32
+	}{
+		{name: "omitted temperature takes the default", catalog: catalog, body: `{"model":"gpt"}`, temperature: 0.3},
+		{name: "client temperature is kept", catalog: catalog, body: `{"model":"gpt","temperature":0.9}`, temperature: 0.9},
+		{name: "model without a catalog", body: `{"model":"gpt"}`},
+		{name: "unreadable body", catalog: catalog, body: `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request openai.ChatCompletionRequest
+			_ = json.Unmarshal([]byte(tt.body), &request)
+			applyCatalogDefaults(&request, tt.catalog, requestFields([]byte(tt.body)))
+			if request.Temperature != tt.temperature {
+				t.Fatalf("temperature = %v, want %v", request.Temperature, tt.temperature)
+			}
+			if request.TopP != 0 {
+				t.Fatalf("top_p = %v, want the null default skipped", request.TopP)
+			}
+		})
+	}
+}
+
+func TestLoadPrimaryModel(t *testing.T) {
+	ctx := context.Background()
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-1", Slug: "one", Active: true}
+	registry.Providers.Add(provider)
+	catalog := &domainmodel.ModelCatalog{PublicID: "cat-1"}
+	registry.Catalogs.Add(catalog)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "gpt", ModelCatalogID: &catalog.ID, Active: true},
+		&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "bare", Active: true},
+	)
+	cApi := &CompletionAPI{providerRegistry: registry.ProviderRegistryService}
+
+	model := cApi.loadPrimaryModel(ctx, provider, "gpt")
+	if model.providerModel == nil || model.catalog == nil || model.catalog.PublicID != "cat-1" {
+		t.Fatalf("model = %+v, want the provider model with its catalog", model)
+	}
+	if model := cApi.loadPrimaryModel(ctx, provider, "bare"); model.providerModel == nil || model.catalog != nil {
+		t.Fatalf("model = %+v, want the provider model without a catalog", model)
+	}
+	if model := cApi.loadPrimaryModel(ctx, provider, "missing"); model.providerModel != nil || model.catalog != nil {
+		t.Fatalf("model = %+v, want nothing for an unknown model", model)
+	}
+	if model := cApi.loadPrimaryModel(ctx, &domainmodel.Provider{}, "gpt"); model.providerModel != nil {
+		t.Fatalf("model = %+v, want nothing for a transient provider", model)
+	}
+}
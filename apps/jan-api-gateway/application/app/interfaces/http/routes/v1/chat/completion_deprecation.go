@@ -0,0 +1,26 @@
+package chat
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+// setDeprecationWarning sets a Warning header (code 299, a persistent warning) when the provider
+// announced a deprecation of the requested model, so clients notice before the model goes away.
+func setDeprecationWarning(reqCtx *gin.Context, modelKey string, catalog *domainmodel.ModelCatalog) {
+	if !catalog.IsDeprecated() {
+		return
+	}
+	message := fmt.Sprintf("model %s is deprecated", modelKey)
+	if catalog.DeprecatedAt != nil {
+		message += " as of " + catalog.DeprecatedAt.Format(time.DateOnly)
+	}
+	if catalog.DeprecationReason != nil {
+		message += ": " + *catalog.DeprecationReason
+	}
+	reqCtx.Header("Warning", "299 - "+strconv.QuoteToASCII(message))
+}
@@ -0,0 +1,42 @@
+package chat
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
+)
+
+func TestSetDeprecationWarning(t *testing.T) {
+	retiredAt := time.Date(2026, time.May, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		catalog *domainmodel.ModelCatalog
+		want    string
+	}{
+		{name: "no catalog"},
+		{name: "not deprecated", catalog: &domainmodel.ModelCatalog{}},
+		{name: "date only", catalog: &domainmodel.ModelCatalog{DeprecatedAt: &retiredAt}, want: `299 - "model gpt-old is deprecated as of 2026-05-31"`},
+		{name: "reason only", catalog: &domainmodel.ModelCatalog{DeprecationReason: ptr.ToString("Use gpt-new.")}, want: `299 - "model gpt-old is deprecated: Use gpt-new."`},
+		{
+			name:    "date and reason",
+			catalog: &domainmodel.ModelCatalog{DeprecatedAt: &retiredAt, DeprecationReason: ptr.ToString("Remplacé par gpt-new.")},
+			want:    `299 - "model gpt-old is deprecated as of 2026-05-31: Remplac\u00e9 par gpt-new."`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			setDeprecationWarning(reqCtx, "gpt-old", tt.catalog)
+			if got := recorder.Header().Get("Warning"); got != tt.want {
+				t.Fatalf("Warning = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
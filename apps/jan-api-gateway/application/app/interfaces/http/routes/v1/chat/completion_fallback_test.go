@@ -0,0 +1,65 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+func TestCompletionFallbackHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	t.Cleanup(func() { organization.DEFAULT_ORGANIZATION = previousDefault })
+
+	registry := modeltest.NewRegistry()
+	orgProvider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", DisplayName: "org", OrganizationID: &orgID, BaseURL: namedUpstream(t, "org").URL, Active: true}
+	downProvider := &domainmodel.Provider{PublicID: "prov-down", Slug: "down", DisplayName: "down", OrganizationID: &orgID, BaseURL: namedUpstream(t, "down").URL, Active: true}
+	jan := &domainmodel.Provider{PublicID: "prov-jan", Slug: "jan", DisplayName: "jan", Kind: domainmodel.ProviderJan, OrganizationID: &orgID, BaseURL: namedUpstream(t, "jan").URL, Active: true}
+	registry.Providers.Add(orgProvider, downProvider, jan)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: orgProvider.ID, ModelKey: "org-model", Active: true},
+		&domainmodel.ProviderModel{ProviderID: downProvider.ID, ModelKey: "down-model", Active: true},
+	)
+	registry.Availability.SetAvailable(downProvider.ID, false)
+
+	tests := []struct {
+		name   string
+		model  string
+		want   string
+		reason domainmodel.FallbackReason
+	}{
+		{name: "matched provider", model: "org-model", want: "org"},
+		{name: "model nobody serves", model: "unknown-model", want: "jan", reason: domainmodel.FallbackReasonModelNotServed},
+		{name: "provider unavailable", model: "down-model", want: "jan", reason: domainmodel.FallbackReasonProviderUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := postCompletion(newCompletionTestAPI(registry), `{"model":"`+tt.model+`","messages":[{"role":"user","content":"hi"}]}`, nil)
+
+			var response openai.ChatCompletionResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.ID != tt.want {
+				t.Fatalf("completion served by %q (status %d), want %q", response.ID, recorder.Code, tt.want)
+			}
+			if tt.reason == "" {
+				if got := recorder.Header().Get(responses.ProviderFallbackHeader); got != "" {
+					t.Fatalf("%s = %q on a matched provider", responses.ProviderFallbackHeader, got)
+				}
+				return
+			}
+			if got := recorder.Header().Get(responses.ProviderFallbackHeader); got != "true" {
+				t.Fatalf("%s = %q, want true", responses.ProviderFallbackHeader, got)
+			}
+			if got := recorder.Header().Get(responses.FallbackReasonHeader); got != string(tt.reason) {
+				t.Fatalf("%s = %q, want %q", responses.FallbackReasonHeader, got, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package chat
+
+import (
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/user"
+)
+
+// forwardedUser returns the authenticated caller when any of the providers opted in to receiving
+// user IDs, so the user is only looked up when it will be used.
+func (cApi *CompletionAPI) forwardedUser(reqCtx *gin.Context, providers []*domainmodel.Provider) *user.User {
+	for _, provider := range providers {
+		if provider.ForwardsUser() {
+			appUser, ok := cApi.authService.ResolveAppUser(reqCtx)
+			if !ok {
+				return nil
+			}
+			return appUser
+		}
+	}
+	return nil
+}
+
+// withForwardedUser sets the request's user field to the caller's public ID for providers that
+// opted in. A user field sent by the client is kept.
+func withForwardedUser(request *openai.ChatCompletionRequest, provider *domainmodel.Provider, appUser *user.User) {
+	if appUser == nil || request.User != "" || !provider.ForwardsUser() {
+		return
+	}
+	request.User = appUser.PublicID
+}
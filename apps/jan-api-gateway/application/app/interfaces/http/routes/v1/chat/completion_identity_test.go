@@ -0,0 +1,69 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/user"
+)
+
+func TestWithForwardedUser(t *testing.T) {
+	caller := &user.User{ID: 7, PublicID: "user-7"}
+	optedIn := &domainmodel.Provider{Metadata: map[string]string{domainmodel.ForwardUserMetadataKey: "true"}}
+
+	tests := []struct {
+		name       string
+		provider   *domainmodel.Provider
+		appUser    *user.User
+		clientUser string
+		want       string
+	}{
+		{name: "filled when absent", provider: optedIn, appUser: caller, want: "user-7"},
+		{name: "client user kept", provider: optedIn, appUser: caller, clientUser: "end-user-42", want: "end-user-42"},
+		{name: "provider without the metadata", provider: &domainmodel.Provider{}, appUser: caller},
+		{name: "provider opted out", provider: &domainmodel.Provider{Metadata: map[string]string{domainmodel.ForwardUserMetadataKey: "false"}}, appUser: caller},
+		{name: "unparsable opt-in", provider: &domainmodel.Provider{Metadata: map[string]string{domainmodel.ForwardUserMetadataKey: "yes please"}}, appUser: caller},
+		{name: "anonymous caller", provider: optedIn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := openai.ChatCompletionRequest{User: tt.clientUser}
+			withForwardedUser(&request, tt.provider, tt.appUser)
+			if request.User != tt.want {
+				t.Fatalf("user = %q, want %q", request.User, tt.want)
+			}
+		})
+	}
+}
+
+func TestForwardedUserOnlyResolvesForOptedInProviders(t *testing.T) {
+	cApi := &CompletionAPI{authService: &auth.AuthService{}}
+	caller := &user.User{ID: 7, PublicID: "user-7"}
+	optedIn := &domainmodel.Provider{Metadata: map[string]string{domainmodel.ForwardUserMetadataKey: "true"}}
+
+	tests := []struct {
+		name      string
+		providers []*domainmodel.Provider
+		want      *user.User
+	}{
+		{name: "no provider opted in", providers: []*domainmodel.Provider{{}, {}}},
+		{name: "a fallback opted in", providers: []*domainmodel.Provider{{}, optedIn}, want: caller},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			reqCtx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			auth.SetUserToContext(reqCtx, caller)
+
+			if got := cApi.forwardedUser(reqCtx, tt.providers); got != tt.want {
+				t.Fatalf("forwardedUser = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
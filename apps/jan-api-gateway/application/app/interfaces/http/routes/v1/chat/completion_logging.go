@@ -0,0 +1,78 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+const completionLogContextKey = "completion_log_entry"
+
+// completionLogEntry collects what the completion handler learned about a request so the
+// logging middleware can emit it once the response has been written.
+type completionLogEntry struct {
+	model    string
+	stream   bool
+	provider *domainmodel.Provider
+	usage    *openai.Usage
+}
+
+// completionLogMiddleware emits one structured log line per chat completion with the model, the
+// provider that served it, the response status, the duration and the token usage when known.
+// The line is logged at debug level unless COMPLETION_REQUEST_LOG_VERBOSE is set. Completions
+// that reached a provider are also counted in the completion metrics.
+func completionLogMiddleware() gin.HandlerFunc {
+	return func(reqCtx *gin.Context) {
+		start := time.Now()
+		entry := &completionLogEntry{}
+		reqCtx.Set(completionLogContextKey, entry)
+
+		reqCtx.Next()
+		duration := time.Since(start)
+
+		fields := logrus.Fields{
+			"model":       entry.model,
+			"stream":      entry.stream,
+			"status":      reqCtx.Writer.Status(),
+			"duration_ms": duration.Milliseconds(),
+		}
+		if organization.DEFAULT_ORGANIZATION != nil {
+			fields["organization_id"] = organization.DEFAULT_ORGANIZATION.ID
+		}
+		if entry.provider != nil {
+			recordCompletionMetrics(entry, reqCtx.Writer.Status(), duration)
+			fields["resolved_provider_slug"] = entry.provider.Slug
+			// The Jan provider is what requests fall back to when no other provider serves the model.
+			fields["used_default"] = entry.provider.Kind == domainmodel.ProviderJan
+		}
+		if entry.usage != nil {
+			fields["prompt_tokens"] = entry.usage.PromptTokens
+			fields["completion_tokens"] = entry.usage.CompletionTokens
+			fields["total_tokens"] = entry.usage.TotalTokens
+		}
+
+		log := logger.GetLogger().WithFields(fields)
+		if environment_variables.EnvironmentVariables.COMPLETION_REQUEST_LOG_VERBOSE {
+			log.Info("chat completion")
+		} else {
+			log.Debug("chat completion")
+		}
+	}
+}
+
+// completionLogEntryFrom returns the entry installed by completionLogMiddleware, or a detached one
+// when the handler runs without the middleware.
+func completionLogEntryFrom(reqCtx *gin.Context) *completionLogEntry {
+	if value, ok := reqCtx.Get(completionLogContextKey); ok {
+		if entry, ok := value.(*completionLogEntry); ok {
+			return entry
+		}
+	}
+	return &completionLogEntry{}
+}
@@ -0,0 +1,56 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"menlo.ai/jan-api-gateway/app/utils/metrics"
+)
+
+// recordCompletionMetrics counts a completion that reached a provider and observes its duration.
+// Only resolved models are recorded, which keeps the model label bounded by what providers serve.
+func recordCompletionMetrics(entry *completionLogEntry, status int, duration time.Duration) {
+	metrics.CompletionsTotal.Inc(entry.provider.Slug, entry.model, strconv.Itoa(status))
+	metrics.CompletionDuration.Observe(duration.Seconds(), entry.provider.Slug, entry.model)
+}
+
+// recordUpstreamError counts a failed call to the provider. Client disconnects are not upstream
+// failures and are left out.
+func recordUpstreamError(provider *domainmodel.Provider, err error) {
+	if provider == nil || err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	metrics.UpstreamErrorsTotal.Inc(provider.Slug, upstreamErrorType(err))
+}
+
+// upstreamErrorType classifies an upstream failure for the error metrics, preferring the error
+// type reported by the provider.
+func upstreamErrorType(err error) string {
+	var upstreamErr *chatclient.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		if upstreamErr.Type != "" {
+			return upstreamErr.Type
+		}
+		return "http_" + strconv.Itoa(upstreamErr.StatusCode)
+	}
+	if errors.Is(err, inference.ErrProviderCircuitOpen) {
+		return "circuit_open"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	}
+	return "other"
+}
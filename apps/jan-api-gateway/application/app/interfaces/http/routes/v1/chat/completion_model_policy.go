@@ -0,0 +1,46 @@
+package chat
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+// resolveApikeyProject returns the project of the project API key the request is authenticated
+// with, or nil for requests without such a key. It aborts with 500 when the lookup fails.
+func resolveApikeyProject(reqCtx *gin.Context, authService *auth.AuthService) (*project.Project, bool) {
+	proj, err := authService.ResolveApikeyProject(reqCtx)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "a3d8f1c6-9e2b-4b57-8d04-c7f2e5a9b136",
+			ErrorInstance: err,
+		})
+		return nil, false
+	}
+	return proj, true
+}
+
+// projectScope returns the project IDs whose providers serve a request made with proj's API key,
+// next to the organization's own providers.
+func projectScope(proj *project.Project) []uint {
+	if proj == nil {
+		return nil
+	}
+	return []uint{proj.ID}
+}
+
+// enforceModelPolicy aborts with 403 when the project's model policy does not permit the model.
+// A nil project is not restricted.
+func enforceModelPolicy(reqCtx *gin.Context, proj *project.Project, modelKey string) bool {
+	if proj == nil || proj.ModelPolicy.Permits(modelKey) {
+		return true
+	}
+	reqCtx.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{
+		Code:  project.ErrCodeModelNotPermitted,
+		Error: "model " + modelKey + " is not permitted for this project",
+	})
+	return false
+}
@@ -0,0 +1,209 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/apikey"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+func TestEnforceModelPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name     string
+		proj     *project.Project
+		modelKey string
+		allowed  bool
+	}{
+		{name: "request without project", modelKey: "gpt-4o", allowed: true},
+		{name: "project without policy", proj: &project.Project{}, modelKey: "gpt-4o", allowed: true},
+		{
+			name:     "model on the allow list",
+			proj:     &project.Project{ModelPolicy: &project.ProjectModelPolicy{Allow: []string{"gpt-4o*"}}},
+			modelKey: "gpt-4o-mini",
+			allowed:  true,
+		},
+		{
+			name:     "model missing from the allow list",
+			proj:     &project.Project{ModelPolicy: &project.ProjectModelPolicy{Allow: []string{"gpt-4o*"}}},
+			modelKey: "claude-3-opus",
+		},
+		{
+			name:     "model on the deny list",
+			proj:     &project.Project{ModelPolicy: &project.ProjectModelPolicy{Deny: []string{"*-preview"}}},
+			modelKey: "o1-preview",
+		},
+		{
+			name:     "deny list wins over allow list",
+			proj:     &project.Project{ModelPolicy: &project.ProjectModelPolicy{Allow: []string{"*"}, Deny: []string{"o1*"}}},
+			modelKey: "o1-mini",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+			if got := enforceModelPolicy(reqCtx, tt.proj, tt.modelKey); got != tt.allowed {
+				t.Fatalf("enforceModelPolicy(%q) = %t, want %t", tt.modelKey, got, tt.allowed)
+			}
+			if tt.allowed {
+				if reqCtx.IsAborted() {
+					t.Fatal("permitted request was aborted")
+				}
+				return
+			}
+			if recorder.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+			}
+			var body responses.ErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != project.ErrCodeModelNotPermitted {
+				t.Fatalf("code = %s, want %s", body.Code, project.ErrCodeModelNotPermitted)
+			}
+		})
+	}
+}
+
+// apikeyRepository resolves every key hash to the same project API key.
+type apikeyRepository struct {
+	apikey.ApiKeyRepository
+	key *apikey.ApiKey
+}
+
+func (r *apikeyRepository) FindByKeyHash(ctx context.Context, keyHash string) (*apikey.ApiKey, error) {
+	return r.key, nil
+}
+
+// projectRepository serves FindByID from a fixed set of projects.
+type projectRepository struct {
+	project.ProjectRepository
+	projects map[uint]*project.Project
+}
+
+func (r *projectRepository) FindByID(ctx context.Context, id uint) (*project.Project, error) {
+	return r.projects[id], nil
+}
+
+// namedUpstream answers chat completions and embeddings with its name as the response ID and
+// model, so tests can tell which provider served a request.
+func namedUpstream(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/embeddings") {
+			_ = json.NewEncoder(w).Encode(openai.EmbeddingResponse{
+				Object: "list",
+				Model:  openai.EmbeddingModel(name),
+				Data:   []openai.Embedding{{Object: "embedding", Embedding: []float32{1}}},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			ID:      name,
+			Object:  "chat.completion",
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: name}}},
+		})
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func TestProjectProvidersServeProjectKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	projectID := uint(7)
+	otherProjectID := uint(8)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	t.Cleanup(func() { organization.DEFAULT_ORGANIZATION = previousDefault })
+
+	registry := modeltest.NewRegistry()
+	orgProvider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", DisplayName: "org", OrganizationID: &orgID, BaseURL: namedUpstream(t, "org").URL, Active: true}
+	projectProvider := &domainmodel.Provider{PublicID: "prov-project", Slug: "project", DisplayName: "project", OrganizationID: &orgID, ProjectID: &projectID, BaseURL: namedUpstream(t, "project").URL, Active: true}
+	registry.Providers.Add(orgProvider, projectProvider)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: orgProvider.ID, ModelKey: "org-model", Active: true, SupportsEmbeddings: true},
+		&domainmodel.ProviderModel{ProviderID: projectProvider.ID, ModelKey: "project-model", Active: true, SupportsEmbeddings: true},
+	)
+
+	tests := []struct {
+		name       string
+		keyProject *uint
+		model      string
+		want       string
+	}{
+		{name: "organization model without a key", model: "org-model", want: "org"},
+		{name: "organization model with a project key", keyProject: &projectID, model: "org-model", want: "org"},
+		{name: "project model with the project key", keyProject: &projectID, model: "project-model", want: "project"},
+		{name: "project model without a key", model: "project-model"},
+		{name: "project model with another project key", keyProject: &otherProjectID, model: "project-model"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			authService := &auth.AuthService{}
+			if tt.keyProject != nil {
+				header.Set("Authorization", "Bearer "+apikey.ApikeyPrefix+"-test")
+				authService = auth.NewAuthService(
+					nil,
+					apikey.NewService(&apikeyRepository{key: &apikey.ApiKey{ApikeyType: string(apikey.ApikeyTypeProject), ProjectID: tt.keyProject}}, nil),
+					nil,
+					project.NewService(&projectRepository{projects: map[uint]*project.Project{
+						projectID:      {ID: projectID},
+						otherProjectID: {ID: otherProjectID},
+					}}),
+					nil,
+				)
+			}
+
+			cApi := newCompletionTestAPI(registry)
+			cApi.authService = authService
+			recorder := postCompletion(cApi, `{"model":"`+tt.model+`","messages":[{"role":"user","content":"hi"}]}`, header)
+			if tt.want == "" {
+				if recorder.Code != http.StatusBadRequest {
+					t.Fatalf("completion status = %d, want 400 for a model out of reach", recorder.Code)
+				}
+			} else {
+				var response openai.ChatCompletionResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || response.ID != tt.want {
+					t.Fatalf("completion served by %q (status %d), want %q", response.ID, recorder.Code, tt.want)
+				}
+			}
+
+			embeddingsAPI := NewEmbeddingsAPI(cApi.inferenceProvider, registry.ProviderRegistryService, registry.ProviderModelService, authService)
+			recorder = httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"`+tt.model+`","input":"hi"}`))
+			reqCtx.Request.Header = header.Clone()
+			reqCtx.Request.Header.Set("Content-Type", "application/json")
+			embeddingsAPI.PostEmbeddings(reqCtx)
+			if tt.want == "" {
+				// The Jan fallback serves models no accessible provider advertises
+				if recorder.Code == http.StatusOK && strings.Contains(recorder.Body.String(), `"project"`) {
+					t.Fatalf("embeddings served by the project provider without its key: %s", recorder.Body.String())
+				}
+				return
+			}
+			var embeddings openai.EmbeddingResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &embeddings); err != nil || string(embeddings.Model) != tt.want {
+				t.Fatalf("embeddings served by %q (status %d, body %s), want %q", embeddings.Model, recorder.Code, recorder.Body.String(), tt.want)
+			}
+		})
+	}
+}
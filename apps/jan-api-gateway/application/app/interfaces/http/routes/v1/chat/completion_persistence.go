@@ -0,0 +1,106 @@
+package chat
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/conversation"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+const (
+	// persistTranscriptHeader opts a completion into being stored as a conversation of the caller.
+	persistTranscriptHeader = "x-jan-persist"
+
+	persistedConversationTitle     = "Chat Completion"
+	persistedConversationTitleSize = 50
+)
+
+// persistRequested reports whether the client asked for the transcript to be stored.
+func persistRequested(reqCtx *gin.Context) bool {
+	return strings.EqualFold(strings.TrimSpace(reqCtx.GetHeader(persistTranscriptHeader)), "true")
+}
+
+// persistTranscript stores the request messages and the final assistant message as a new
+// conversation owned by the authenticated caller. Anonymous requests are not persisted, and
+// failures are only logged since the response has already been served.
+func (cApi *CompletionAPI) persistTranscript(reqCtx *gin.Context, request openai.ChatCompletionRequest, assistantContent string) {
+	user, ok := cApi.authService.ResolveAppUser(reqCtx)
+	if !ok {
+		logger.GetLogger().Warnf("%s requested without an authenticated user, transcript not persisted", persistTranscriptHeader)
+		return
+	}
+	// The transcript is written after the response, so a client disconnect must not cancel it
+	ctx := context.WithoutCancel(reqCtx.Request.Context())
+
+	title := transcriptTitle(request.Messages)
+	conv, err := cApi.conversationService.CreateConversation(ctx, user.ID, &title, true, map[string]string{
+		"model":  request.Model,
+		"source": "chat_completions",
+	}, nil)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to create conversation for completion transcript: %v", err)
+		return
+	}
+
+	items := transcriptItems(request.Messages)
+	assistantRole := conversation.ItemRoleAssistant
+	items = append(items, &conversation.Item{
+		Type:    conversation.ItemTypeMessage,
+		Role:    &assistantRole,
+		Content: []conversation.Content{conversation.NewTextContent(assistantContent)},
+	})
+	if _, err := cApi.conversationService.AddMultipleItems(ctx, conv, user.ID, items); err != nil {
+		logger.GetLogger().Errorf("failed to persist completion transcript to conversation %s: %v", conv.PublicID, err)
+	}
+}
+
+// transcriptItems converts the request messages into conversation items, keeping the text of
+// multi-part content. Messages with a role the conversation domain does not know are skipped.
+func transcriptItems(messages []openai.ChatCompletionMessage) []*conversation.Item {
+	items := make([]*conversation.Item, 0, len(messages)+1)
+	for _, message := range messages {
+		if !conversation.ValidateItemRole(message.Role) {
+			continue
+		}
+		content := make([]conversation.Content, 0, 1)
+		if message.Content != "" {
+			content = append(content, conversation.NewTextContent(message.Content))
+		}
+		for _, part := range message.MultiContent {
+			if part.Type == openai.ChatMessagePartTypeText && part.Text != "" {
+				content = append(content, conversation.NewTextContent(part.Text))
+			}
+		}
+		if len(content) == 0 {
+			continue
+		}
+		role := conversation.ItemRole(message.Role)
+		items = append(items, &conversation.Item{
+			Type:    conversation.ItemTypeMessage,
+			Role:    &role,
+			Content: content,
+		})
+	}
+	return items
+}
+
+// transcriptTitle uses the first user message as the conversation title.
+func transcriptTitle(messages []openai.ChatCompletionMessage) string {
+	for _, message := range messages {
+		if message.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		title := strings.TrimSpace(message.Content)
+		if title == "" {
+			continue
+		}
+		if len(title) > persistedConversationTitleSize {
+			return title[:persistedConversationTitleSize] + "..."
+		}
+		return title
+	}
+	return persistedConversationTitle
+}
@@ -0,0 +1,48 @@
+package chat
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+// providerKeyHeader carries the caller's own upstream API key for passthrough providers.
+const providerKeyHeader = "x-jan-provider-key"
+
+// passthroughProviders reads the caller's provider key and narrows the candidates to those it can
+// be sent to. The request is rejected with 400 when the primary provider takes the caller's key
+// and none was sent; passthrough fallbacks are dropped instead, so the request can still fall back
+// to providers with a stored key.
+func passthroughProviders(reqCtx *gin.Context, providers []*domainmodel.Provider) ([]*domainmodel.Provider, string, bool) {
+	providerKey := strings.TrimSpace(reqCtx.GetHeader(providerKeyHeader))
+	if providerKey != "" {
+		return providers, providerKey, true
+	}
+	if providers[0].UsesPassthroughKey() {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "d3a7e1f5-8c2b-4e96-a4d0-b6f9c2e8a153",
+			Error: fmt.Sprintf("provider %s requires your own API key in the %s header", providers[0].Slug, providerKeyHeader),
+		})
+		return nil, "", false
+	}
+	candidates := make([]*domainmodel.Provider, 0, len(providers))
+	for _, provider := range providers {
+		if !provider.UsesPassthroughKey() {
+			candidates = append(candidates, provider)
+		}
+	}
+	return candidates, "", true
+}
+
+// providerAPIKey returns the key a completion sends to the provider: the caller's key for
+// passthrough providers and none otherwise, so the stored key is used.
+func providerAPIKey(provider *domainmodel.Provider, providerKey string) string {
+	if provider.UsesPassthroughKey() {
+		return providerKey
+	}
+	return ""
+}
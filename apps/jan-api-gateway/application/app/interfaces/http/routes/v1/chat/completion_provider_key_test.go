@@ -0,0 +1,108 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+func TestPassthroughProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	stored := &domainmodel.Provider{Slug: "stored", KeyMode: domainmodel.ProviderKeyModeStored}
+	passthrough := &domainmodel.Provider{Slug: "passthrough", KeyMode: domainmodel.ProviderKeyModePassthrough}
+
+	tests := []struct {
+		name       string
+		header     string
+		providers  []*domainmodel.Provider
+		want       []string
+		wantKey    string
+		wantStatus int
+	}{
+		{
+			name:      "stored primary without key",
+			providers: []*domainmodel.Provider{stored},
+			want:      []string{"stored"},
+		},
+		{
+			name:       "passthrough primary without key is rejected",
+			providers:  []*domainmodel.Provider{passthrough, stored},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "blank key counts as missing",
+			header:     "   ",
+			providers:  []*domainmodel.Provider{passthrough},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "passthrough primary with key",
+			header:    " sk-caller ",
+			providers: []*domainmodel.Provider{passthrough, stored},
+			want:      []string{"passthrough", "stored"},
+			wantKey:   "sk-caller",
+		},
+		{
+			name:      "passthrough fallbacks are dropped without key",
+			providers: []*domainmodel.Provider{stored, passthrough},
+			want:      []string{"stored"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set(providerKeyHeader, tt.header)
+			}
+
+			candidates, key, ok := passthroughProviders(reqCtx, tt.providers)
+			if tt.wantStatus != 0 {
+				if ok {
+					t.Fatal("request was accepted, want rejection")
+				}
+				if recorder.Code != tt.wantStatus {
+					t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("request was rejected with %d", recorder.Code)
+			}
+			if key != tt.wantKey {
+				t.Fatalf("key = %q, want %q", key, tt.wantKey)
+			}
+			if len(candidates) != len(tt.want) {
+				t.Fatalf("got %d candidates, want %d", len(candidates), len(tt.want))
+			}
+			for i, provider := range candidates {
+				if provider.Slug != tt.want[i] {
+					t.Fatalf("candidate %d = %s, want %s", i, provider.Slug, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProviderAPIKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      domainmodel.ProviderKeyMode
+		callerKey string
+		want      string
+	}{
+		{name: "passthrough sends the caller's key", mode: domainmodel.ProviderKeyModePassthrough, callerKey: "sk-caller", want: "sk-caller"},
+		{name: "stored ignores the caller's key", mode: domainmodel.ProviderKeyModeStored, callerKey: "sk-caller", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := providerAPIKey(&domainmodel.Provider{KeyMode: tt.mode}, tt.callerKey); got != tt.want {
+				t.Fatalf("providerAPIKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,36 +2,92 @@ package chat
 
 import (
 	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
 	"menlo.ai/jan-api-gateway/app/domain/common"
+	"menlo.ai/jan-api-gateway/app/domain/contentfilter"
+	"menlo.ai/jan-api-gateway/app/domain/conversation"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/moderation"
 	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/ratelimit"
+	"menlo.ai/jan-api-gateway/app/domain/spend"
+	"menlo.ai/jan-api-gateway/app/domain/workspace"
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/middleware"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	openairesponses "menlo.ai/jan-api-gateway/app/interfaces/http/responses/openai"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
 	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
 )
 
+// estimatedCostHeader carries the estimated cost of a non-streaming completion in micro-USD.
+const estimatedCostHeader = "X-Jan-Estimated-Cost-Micro-USD"
+
+// providerOverrideHeader forces a completion onto one accessible provider, by public ID, for
+// troubleshooting.
+const providerOverrideHeader = "x-jan-provider"
+
+// defaultProviderFallbackAttempts caps the provider chain when MODEL_PROVIDER_FALLBACK_ATTEMPTS is unset.
+const defaultProviderFallbackAttempts = 3
+
 // CompletionAPI handles chat completion requests with streaming support by delegating to the shared chat completion client.
 type CompletionAPI struct {
-	inferenceProvider *inference.InferenceProvider
-	providerRegistry  *domainmodel.ProviderRegistryService
+	inferenceProvider    *inference.InferenceProvider
+	providerRegistry     *domainmodel.ProviderRegistryService
+	contentFilterService *contentfilter.ContentFilterService
+	spendTracker         *spend.SpendTracker
+	modelRateLimiter     *ratelimit.ModelRateLimiter
+	moderationService    *moderation.ModerationService
+	authService          *auth.AuthService
+	conversationService  *conversation.ConversationService
+	workspaceService     *workspace.WorkspaceService
 }
 
 func NewCompletionAPI(
 	inferenceProvider *inference.InferenceProvider,
 	providerRegistry *domainmodel.ProviderRegistryService,
+	contentFilterService *contentfilter.ContentFilterService,
+	spendTracker *spend.SpendTracker,
+	modelRateLimiter *ratelimit.ModelRateLimiter,
+	moderationService *moderation.ModerationService,
+	authService *auth.AuthService,
+	conversationService *conversation.ConversationService,
+	workspaceService *workspace.WorkspaceService,
 ) *CompletionAPI {
 	return &CompletionAPI{
-		inferenceProvider: inferenceProvider,
-		providerRegistry:  providerRegistry,
+		inferenceProvider:    inferenceProvider,
+		providerRegistry:     providerRegistry,
+		contentFilterService: contentFilterService,
+		spendTracker:         spendTracker,
+		modelRateLimiter:     modelRateLimiter,
+		moderationService:    moderationService,
+		authService:          authService,
+		conversationService:  conversationService,
+		workspaceService:     workspaceService,
 	}
 }
 
 func (completionAPI *CompletionAPI) RegisterRouter(router *gin.RouterGroup) {
-	router.POST("/completions", completionAPI.PostCompletion)
+	router.POST("/completions",
+		middleware.BodyLimit(middleware.MaxRequestBodyBytes()),
+		middleware.Compress(),
+		completionLogMiddleware(),
+		completionAPI.spendTracker.SpendLimitMiddleware(),
+		completionAPI.PostCompletion,
+	)
 }
 
 // PostCompletion
@@ -51,28 +107,58 @@ func (completionAPI *CompletionAPI) RegisterRouter(router *gin.RouterGroup) {
 // @Description - Supports all OpenAI ChatCompletionRequest parameters
 // @Description - User authentication required
 // @Description - Direct inference model integration
-// @Description - No conversation persistence (stateless)
+// @Description - No conversation persistence (stateless) unless the x-jan-persist header is set
+// @Description
+// @Description **Transcript persistence:**
+// @Description - Send `x-jan-persist: true` with an authenticated request to store the request messages and the final assistant message as a conversation of the caller
+// @Description - Persistence failures are logged and never affect the response
+// @Description
+// @Description **Workspace instructions:**
+// @Description - Send `x-jan-workspace: <workspace_id>` with an authenticated request to prepend the workspace instruction as a system message when the request has no system or developer message
 // @Tags Chat Completions API
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Produce text/event-stream
 // @Param request body openai.ChatCompletionRequest true "Chat completion request with streaming options"
+// @Param x-jan-persist header string false "Set to true to persist the transcript as a conversation of the authenticated user"
+// @Param X-Jan-Skip-Context-Check header string false "Set to true to skip the context length pre-flight for models with untrusted limits"
+// @Param x-jan-provider header string false "Public ID of an accessible provider serving the model to force, bypassing routing and fallback"
+// @Param x-jan-workspace header string false "Public ID of a workspace of the authenticated user whose instruction is used as the system prompt"
+// @Param x-jan-strict-params header string false "Set to true to reject parameters missing from the model's supported_parameters instead of passing them through"
+// @Param x-jan-provider-key header string false "Your own API key for the upstream, required when the primary provider uses passthrough keys"
 // @Success 200 {object} openai.ChatCompletionResponse "Successful non-streaming response (when stream=false)"
 // @Success 200 {string} string "Successful streaming response (when stream=true) - SSE format with data: {json} events"
-// @Failure 400 {object} responses.ErrorResponse "Invalid request payload, empty messages, or inference failure"
+// @Failure 400 {object} responses.ErrorResponse "Invalid request payload, empty messages, missing x-jan-provider-key for a passthrough provider, or inference failure"
+// @Failure 400 {object} moderationRejectedResponse "Input flagged by moderation"
+// @Failure 400 {object} unsupportedParamsResponse "Strict mode: the model does not support some request parameters"
 // @Failure 401 {object} responses.ErrorResponse "Unauthorized - missing or invalid authentication"
+// @Failure 403 {object} responses.ErrorResponse "The model is not permitted by the model policy of the API key's project"
+// @Failure 404 {object} responses.ErrorResponse "The workspace named by x-jan-workspace was not found"
+// @Failure 413 {object} responses.ErrorResponse "Request body exceeds MAX_REQUEST_BODY_BYTES"
+// @Failure 413 {object} responses.ErrorResponse "Estimated prompt exceeds the model's recorded context length"
+// @Failure 413 {object} openairesponses.ErrorResponse "Upstream rejected the request for exceeding the context length"
+// @Failure 429 {object} responses.ErrorResponse "Monthly spend limit or model rate limit exceeded, or the upstream rate limit was hit"
 // @Failure 500 {object} responses.ErrorResponse "Internal server error"
+// @Failure 502 {object} openairesponses.ErrorResponse "Upstream provider failed"
 // @Router /v1/chat/completions [post]
 func (cApi *CompletionAPI) PostCompletion(reqCtx *gin.Context) {
 	var request openai.ChatCompletionRequest
-	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+	// The body is kept so catalog defaults only fill parameters the client omitted
+	if err := reqCtx.ShouldBindBodyWith(&request, binding.JSON); err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			middleware.AbortBodyTooLarge(reqCtx, middleware.MaxRequestBodyBytes())
+			return
+		}
 		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
 			Code:          "0199600b-86d3-7339-8402-8ef1c7840475",
 			ErrorInstance: err,
 		})
 		return
 	}
+	logEntry := completionLogEntryFrom(reqCtx)
+	logEntry.model = request.Model
+	logEntry.stream = request.Stream
 
 	if len(request.Messages) == 0 {
 		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
@@ -81,40 +167,306 @@ func (cApi *CompletionAPI) PostCompletion(reqCtx *gin.Context) {
 		})
 		return
 	}
+	if !cApi.applyWorkspaceInstruction(reqCtx, &request) {
+		return
+	}
+	// A project API key also reaches the providers registered on its project
+	proj, ok := resolveApikeyProject(reqCtx, cApi.authService)
+	if !ok {
+		return
+	}
+	projectIDs := projectScope(proj)
 
-	// Get provider based on the requested model
-	provider, providerErr := cApi.providerRegistry.GetProviderForModel(reqCtx, request.Model, organization.DEFAULT_ORGANIZATION.ID, nil)
-	if providerErr != nil {
-		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
-			Code:          "b34bc6d8-6e51-44d9-af0b-35f7892112cc",
-			ErrorInstance: providerErr,
+	var providers []*domainmodel.Provider
+	if providerPublicID := strings.TrimSpace(reqCtx.GetHeader(providerOverrideHeader)); providerPublicID != "" {
+		// A forced provider is used alone, without fallback
+		forced, overrideErr := cApi.providerRegistry.GetProviderOverrideForModel(reqCtx, providerPublicID, request.Model, organization.DEFAULT_ORGANIZATION.ID, projectIDs)
+		if overrideErr != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  overrideErr.GetCode(),
+				Error: overrideErr.GetMessage(),
+			})
+			return
+		}
+		providers = []*domainmodel.Provider{forced}
+	} else {
+		// Resolve every provider serving the requested model so failures can fall through the chain
+		var fallbackReason domainmodel.FallbackReason
+		var providerErr error
+		providers, fallbackReason, providerErr = cApi.providerRegistry.SelectProvidersForModel(reqCtx, request.Model, organization.DEFAULT_ORGANIZATION.ID, projectIDs)
+		if providerErr != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:          "b34bc6d8-6e51-44d9-af0b-35f7892112cc",
+				ErrorInstance: providerErr,
+			})
+			return
+		}
+		responses.SetProviderFallbackHeaders(reqCtx, fallbackReason)
+		if attempts := providerFallbackAttempts(); len(providers) > attempts {
+			providers = providers[:attempts]
+		}
+	}
+	providers, providerKey, ok := passthroughProviders(reqCtx, providers)
+	if !ok {
+		return
+	}
+
+	// An alias is only a friendly name; the upstream expects the aliased model key
+	alias, aliasErr := cApi.providerRegistry.ResolveModelAlias(reqCtx, request.Model, organization.DEFAULT_ORGANIZATION.ID)
+	if aliasErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "3d7f1b94-2c6e-4a08-9e51-b7a0c4d82f63",
+			ErrorInstance: aliasErr,
+		})
+		return
+	}
+	if alias != nil {
+		request.Model = alias.ModelKey
+	}
+	if !enforceModelPolicy(reqCtx, proj, request.Model) {
+		return
+	}
+
+	// Throttle with the limit of the provider that will be tried first
+	if allowed, retryAfter := cApi.modelRateLimiter.Allow(reqCtx.Request.Context(), organization.DEFAULT_ORGANIZATION.ID, providers[0], request.Model); !allowed {
+		reqCtx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		reqCtx.AbortWithStatusJSON(http.StatusTooManyRequests, responses.ErrorResponse{
+			Code:  ratelimit.ErrorCodeModelRateLimitExceeded,
+			Error: "rate limit exceeded for model " + request.Model,
 		})
 		return
 	}
 
+	model := cApi.loadPrimaryModel(reqCtx.Request.Context(), providers[0], request.Model)
+	setDeprecationWarning(reqCtx, request.Model, model.catalog)
+	if !checkContextLength(reqCtx, model, request) {
+		return
+	}
+	if !checkSupportedParams(reqCtx, model, request) {
+		return
+	}
+	if body, ok := reqCtx.Get(gin.BodyBytesKey); ok {
+		if raw, ok := body.([]byte); ok {
+			applyCatalogDefaults(&request, model.catalog, requestFields(raw))
+			// OpenRouter clients forward the routing fields the OpenAI request struct drops
+			reqCtx.Request = reqCtx.Request.WithContext(chatclient.WithRequestBody(reqCtx.Request.Context(), raw))
+		}
+	}
+
+	if cApi.moderationService.Required(providers) {
+		verdict, moderationErr := cApi.moderationService.CheckInput(reqCtx.Request.Context(), request)
+		if moderationErr != nil {
+			// A moderation outage must not take completions down with it
+			logger.GetLogger().Errorf("input moderation failed for model %s: %v", request.Model, moderationErr)
+		} else if verdict.Flagged {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, moderationRejectedResponse{
+				Code:       "4c8f2b6e-1d9a-4e73-b5a0-e7c3d9f1a284",
+				Error:      "input was flagged by moderation",
+				Categories: verdict.Categories,
+			})
+			return
+		}
+	}
+
+	appUser := cApi.forwardedUser(reqCtx, providers)
+
+	var provider *domainmodel.Provider
 	var err *common.Error
 	var response *openai.ChatCompletionResponse
+	var streamResult *chatclient.StreamCompletionResult
+	var latency time.Duration
 
-	if request.Stream {
-		err = cApi.StreamCompletionResponse(reqCtx, provider, "", request)
-	} else {
-		response, err = cApi.CallCompletionAndGetRestResponse(reqCtx.Request.Context(), provider, "", request)
+	for i, candidate := range providers {
+		provider = candidate
+		start := time.Now()
+		attempt := request
+
+		withForwardedUser(&attempt, provider, appUser)
+
+		// Redact outgoing content according to the provider's content-filter policy; the attempt gets
+		// its own redacted messages, so the request persisted and replayed below stays untouched
+		cApi.contentFilterService.FilterRequest(reqCtx.Request.Context(), provider, &attempt)
+
+		if attempt.Stream {
+			streamResult, err = cApi.StreamCompletionResponse(reqCtx, provider, providerAPIKey(provider, providerKey), attempt)
+		} else {
+			response, err = cApi.CallCompletionAndGetRestResponse(reqCtx.Request.Context(), provider, providerAPIKey(provider, providerKey), attempt)
+		}
+		latency = time.Since(start)
+		if err == nil {
+			break
+		}
+		recordUpstreamError(provider, err.GetError())
+		// Streams can only fall back while nothing but heartbeats has reached the client yet.
+		if i == len(providers)-1 || !isProviderFallbackError(err.GetError()) || (attempt.Stream && chatclient.StreamDataWritten(reqCtx)) {
+			break
+		}
+		logger.GetLogger().Warnf("completion for model %s failed on provider %s, trying next provider: %v", request.Model, provider.Slug, err.GetError())
 	}
+	logEntry.provider = provider
 
 	if err != nil {
 		logger.GetLogger().Errorf("completion failed: %v", err)
-		reqCtx.AbortWithStatusJSON(
-			http.StatusBadRequest,
-			responses.ErrorResponse{
-				Code:          err.GetCode(),
-				ErrorInstance: err.GetError(),
-			})
+		abortCompletion(reqCtx, err)
 		return
 	}
+	logger.GetLogger().Infof("completion for model %s served by provider %s", request.Model, provider.Slug)
+
+	var usage openai.Usage
+	usageEstimated := false
+	content := ""
+	if streamResult != nil {
+		usage = streamResult.Usage
+		usageEstimated = streamResult.UsageEstimated
+		if len(streamResult.Choices) > 0 {
+			content = streamResult.Choices[0].Message.Content
+		}
+	} else if response != nil {
+		usage = response.Usage
+		if len(response.Choices) > 0 {
+			content = response.Choices[0].Message.Content
+		}
+	}
+	logEntry.usage = &usage
+	logger.GetLogger().Infof("completion usage: model=%s provider=%s stream=%t prompt_tokens=%d completion_tokens=%d total_tokens=%d estimated=%t",
+		request.Model, provider.Slug, request.Stream, usage.PromptTokens, usage.CompletionTokens, usage.TotalTokens, usageEstimated)
+
+	cost, costKnown := cApi.estimateCost(reqCtx.Request.Context(), provider, request.Model, usage)
+	if costKnown {
+		var projectID *uint
+		if proj != nil {
+			projectID = &proj.ID
+		}
+		if err := cApi.spendTracker.Record(reqCtx.Request.Context(), organization.DEFAULT_ORGANIZATION.ID, projectID, cost); err != nil {
+			logger.GetLogger().Errorf("failed to record spend for model %s: %v", request.Model, err)
+		}
+	}
 
 	if !request.Stream {
+		if costKnown {
+			reqCtx.Header(estimatedCostHeader, strconv.FormatInt(int64(cost), 10))
+		}
+		// Response filtering only applies here; streamed chunks are relayed unfiltered
+		cApi.contentFilterService.FilterResponse(reqCtx.Request.Context(), provider, response)
 		reqCtx.JSON(http.StatusOK, response)
 	}
+
+	if persistRequested(reqCtx) {
+		cApi.persistTranscript(reqCtx, request, content)
+	}
+
+	cApi.replayToShadowProviders(reqCtx.Request.Context(), request, projectIDs, shadowPrimaryResult{
+		provider:  provider,
+		latency:   latency,
+		content:   content,
+		usage:     usage,
+		cost:      cost,
+		costKnown: costKnown,
+	})
+}
+
+// estimateCost computes the request cost from the served model's pricing, or its price override
+// when an admin set one. Providers without a synced model, such as the Jan fallback, have no known
+// cost.
+func (cApi *CompletionAPI) estimateCost(ctx context.Context, provider *domainmodel.Provider, modelKey string, usage openai.Usage) (domainmodel.MicroUSD, bool) {
+	if provider.ID == 0 {
+		return 0, false
+	}
+	providerModel, err := cApi.providerRegistry.FindProviderModel(ctx, provider.ID, modelKey)
+	if err != nil || providerModel == nil {
+		return 0, false
+	}
+	cost, err := providerModel.EstimateCost(usage.PromptTokens, usage.CompletionTokens)
+	if err != nil {
+		logger.GetLogger().Warnf("unable to estimate cost for model %s on provider %s: %v", modelKey, provider.Slug, err)
+		return 0, false
+	}
+	return cost, true
+}
+
+func providerFallbackAttempts() int {
+	if attempts := environment_variables.EnvironmentVariables.MODEL_PROVIDER_FALLBACK_ATTEMPTS; attempts > 0 {
+		return attempts
+	}
+	return defaultProviderFallbackAttempts
+}
+
+// isProviderFallbackError reports whether a failed completion should be retried on the next
+// provider: connection failures, open circuits and 5xx answers qualify, client errors do not.
+func isProviderFallbackError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var upstreamErr *chatclient.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		return upstreamErr.StatusCode >= http.StatusInternalServerError
+	}
+	if errors.Is(err, inference.ErrProviderCircuitOpen) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// moderationRejectedResponse is returned when the input moderation flags a request.
+type moderationRejectedResponse struct {
+	Code       string   `json:"code"`
+	Error      string   `json:"error"`
+	Categories []string `json:"categories"`
+}
+
+// abortCompletion answers a failed completion. Upstream rejections keep their status and error
+// type so clients can tell them apart, and a rate limit keeps its Retry-After. A stream that failed before its first SSE byte still
+// gets the upstream status.
+func abortCompletion(reqCtx *gin.Context, err *common.Error) {
+	var upstreamErr *chatclient.UpstreamError
+	if errors.As(err.GetError(), &upstreamErr) {
+		if upstreamErr.StatusCode == http.StatusTooManyRequests && upstreamErr.RetryAfter != "" {
+			reqCtx.Header("Retry-After", upstreamErr.RetryAfter)
+		}
+		reqCtx.AbortWithStatusJSON(upstreamErrorStatus(upstreamErr), toOpenAIErrorResponse(upstreamErr))
+		return
+	}
+	reqCtx.AbortWithStatusJSON(
+		http.StatusBadRequest,
+		responses.ErrorResponse{
+			Code:          err.GetCode(),
+			ErrorInstance: err.GetError(),
+		})
+}
+
+// upstreamErrorStatus maps an upstream failure onto the status returned to the client. Client
+// errors pass through, with context-length overruns reported as 413; upstream server errors
+// become 502.
+func upstreamErrorStatus(err *chatclient.UpstreamError) int {
+	switch {
+	case err.Code == "context_length_exceeded":
+		return http.StatusRequestEntityTooLarge
+	case err.StatusCode >= http.StatusBadRequest && err.StatusCode < http.StatusInternalServerError:
+		return err.StatusCode
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func toOpenAIErrorResponse(err *chatclient.UpstreamError) openairesponses.ErrorResponse {
+	object := openairesponses.ErrorObject{
+		Message: err.Message,
+		Type:    err.Type,
+	}
+	if object.Message == "" {
+		object.Message = err.Error()
+	}
+	if object.Type == "" {
+		object.Type = "upstream_error"
+	}
+	if err.Param != "" {
+		object.Param = &err.Param
+	}
+	if err.Code != "" {
+		object.Code = &err.Code
+	}
+	return openairesponses.ErrorResponse{Error: object}
 }
 
 // CallCompletionAndGetRestResponse calls the shared chat client and returns a complete non-streaming response.
@@ -134,15 +486,20 @@ func (cApi *CompletionAPI) CallCompletionAndGetRestResponse(ctx context.Context,
 	return response, nil
 }
 
-// StreamCompletionResponse streams SSE events directly to the client via the shared chat client.
-func (cApi *CompletionAPI) StreamCompletionResponse(reqCtx *gin.Context, provider *domainmodel.Provider, apiKey string, request openai.ChatCompletionRequest) *common.Error {
+// StreamCompletionResponse streams SSE events directly to the client via the shared chat client
+// and returns the accumulated result, including the token usage of the stream.
+func (cApi *CompletionAPI) StreamCompletionResponse(reqCtx *gin.Context, provider *domainmodel.Provider, apiKey string, request openai.ChatCompletionRequest) (*chatclient.StreamCompletionResult, *common.Error) {
 	chatClient, err := cApi.inferenceProvider.GetChatCompletionClient(provider)
 	if err != nil {
-		return common.NewError(err, "bc82d69c-685b-4556-9d1f-2a4a80ae8ca3")
+		return nil, common.NewError(err, "bc82d69c-685b-4556-9d1f-2a4a80ae8ca3")
 	}
 
-	if _, err := chatClient.StreamChatCompletionToContext(reqCtx, apiKey, request); err != nil {
-		return common.NewError(err, "bc82d69c-685b-4556-9d1f-2a4a80ae8ca4")
+	result, err := chatClient.StreamChatCompletionToContext(reqCtx, apiKey, request)
+	if err != nil {
+		return nil, common.NewError(err, "bc82d69c-685b-4556-9d1f-2a4a80ae8ca4")
+	}
+	if result.JSONValidationError != nil {
+		logger.GetLogger().Warnf("streamed json_object completion for model %s failed validation: %v", request.Model, result.JSONValidationError)
 	}
-	return nil
+	return result, nil
 }
@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/contentfilter"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/moderation"
+	"menlo.ai/jan-api-gateway/app/domain/ratelimit"
+	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+)
+
+// newCompletionTestAPI wires a CompletionAPI to the in-memory registry and real upstream clients,
+// so PostCompletion can be driven end to end against httptest upstreams.
+func newCompletionTestAPI(registry *modeltest.Registry) *CompletionAPI {
+	return &CompletionAPI{
+		inferenceProvider:    inference.NewInferenceProvider(),
+		providerRegistry:     registry.ProviderRegistryService,
+		contentFilterService: contentfilter.NewContentFilterService(),
+		modelRateLimiter:     ratelimit.NewModelRateLimiter(nil),
+		moderationService:    moderation.NewModerationService(),
+		authService:          &auth.AuthService{},
+	}
+}
+
+// completionUpstream answers every chat completion with content and sends the requests it
+// received to requests when it is set.
+func completionUpstream(t *testing.T, content string, requests chan<- openai.ChatCompletionRequest) *httptest.Server {
+	t.Helper()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if requests != nil {
+			requests <- request
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			ID:      "chatcmpl-test",
+			Object:  "chat.completion",
+			Model:   request.Model,
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content}}},
+			Usage:   openai.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		})
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+// postCompletion runs PostCompletion on a JSON body with the given request headers.
+func postCompletion(cApi *CompletionAPI, body string, header http.Header) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	reqCtx, _ := gin.CreateTestContext(recorder)
+	reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	reqCtx.Request.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		for _, value := range values {
+			reqCtx.Request.Header.Add(name, value)
+		}
+	}
+	cApi.PostCompletion(reqCtx)
+	return recorder
+}
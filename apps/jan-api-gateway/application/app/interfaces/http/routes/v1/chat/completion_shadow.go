@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+const (
+	// defaultShadowSamplePercent applies when MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT is unset.
+	defaultShadowSamplePercent = 10
+	shadowRequestTimeout       = 60 * time.Second
+)
+
+// shadowPrimaryResult is what the client received from the primary provider. costKnown is false
+// when the primary has no pricing for the model.
+type shadowPrimaryResult struct {
+	provider  *domainmodel.Provider
+	latency   time.Duration
+	content   string
+	usage     openai.Usage
+	cost      domainmodel.MicroUSD
+	costKnown bool
+}
+
+// shadowSampleRoll returns a value in [0, 100) that decides whether a request is replayed.
+var shadowSampleRoll = func() int { return rand.IntN(100) }
+
+func shadowSamplePercent() int {
+	percent := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT
+	if percent <= 0 {
+		return defaultShadowSamplePercent
+	}
+	return min(percent, 100)
+}
+
+// shadowSampled reports whether the current request is replayed to shadow providers.
+func shadowSampled() bool {
+	return shadowSampleRoll() < shadowSamplePercent()
+}
+
+// replayToShadowProviders sends a sampled copy of a served request to every shadow provider for
+// the model that is accessible to the request's projects and logs how each compares with the
+// primary. The replay runs after the client has its response and its outcome is never returned
+// to the client.
+func (cApi *CompletionAPI) replayToShadowProviders(ctx context.Context, request openai.ChatCompletionRequest, projectIDs []uint, primary shadowPrimaryResult) {
+	if !shadowSampled() {
+		return
+	}
+	shadows, err := cApi.providerRegistry.GetShadowProvidersForModel(ctx, request.Model, organization.DEFAULT_ORGANIZATION.ID, projectIDs)
+	if err != nil {
+		logger.GetLogger().Warnf("unable to resolve shadow providers for model %s: %v", request.Model, err)
+		return
+	}
+	for _, shadow := range shadows {
+		// Replays carry no caller key, so passthrough shadows cannot be called
+		if shadow.UsesPassthroughKey() {
+			continue
+		}
+		replay := request
+		replay.Stream = false
+		replay.StreamOptions = nil
+		cApi.contentFilterService.FilterRequest(ctx, shadow, &replay)
+		go cApi.runShadowRequest(shadow, replay, primary)
+	}
+}
+
+func (cApi *CompletionAPI) runShadowRequest(shadow *domainmodel.Provider, request openai.ChatCompletionRequest, primary shadowPrimaryResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), shadowRequestTimeout)
+	defer cancel()
+
+	fields := logrus.Fields{
+		"model":            request.Model,
+		"primary_provider": primary.provider.Slug,
+		"shadow_provider":  shadow.Slug,
+		"primary_latency":  primary.latency.String(),
+	}
+
+	start := time.Now()
+	response, err := cApi.CallCompletionAndGetRestResponse(ctx, shadow, "", request)
+	fields["shadow_latency"] = time.Since(start).String()
+	if err != nil {
+		fields["error"] = err.GetError()
+		logger.GetLogger().WithFields(fields).Warn("shadow completion failed")
+		return
+	}
+
+	shadowCost, shadowCostKnown := cApi.estimateCost(ctx, shadow, request.Model, response.Usage)
+	for key, value := range shadowComparisonFields(primary, response, shadowCost, shadowCostKnown) {
+		fields[key] = value
+	}
+	logger.GetLogger().WithFields(fields).Info("shadow completion recorded")
+}
+
+// shadowComparisonFields compares a shadow response with what the primary returned. Costs are in
+// micro-USD and only logged when known; the delta needs both.
+func shadowComparisonFields(primary shadowPrimaryResult, response *openai.ChatCompletionResponse, shadowCost domainmodel.MicroUSD, shadowCostKnown bool) logrus.Fields {
+	shadowContent := ""
+	if len(response.Choices) > 0 {
+		shadowContent = response.Choices[0].Message.Content
+	}
+	fields := logrus.Fields{
+		"primary_completion_tokens": primary.usage.CompletionTokens,
+		"shadow_completion_tokens":  response.Usage.CompletionTokens,
+		"shadow_prompt_tokens":      response.Usage.PromptTokens,
+		"content_match":             shadowContent == primary.content,
+		"content_length_delta":      len(shadowContent) - len(primary.content),
+	}
+	if primary.costKnown {
+		fields["primary_cost_micro_usd"] = int64(primary.cost)
+	}
+	if shadowCostKnown {
+		fields["shadow_cost_micro_usd"] = int64(shadowCost)
+	}
+	if primary.costKnown && shadowCostKnown {
+		fields["cost_delta_micro_usd"] = int64(shadowCost - primary.cost)
+	}
+	return fields
+}
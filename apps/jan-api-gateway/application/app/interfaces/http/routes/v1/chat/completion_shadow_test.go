@@ -0,0 +1,187 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+func TestShadowSampled(t *testing.T) {
+	previousPercent := environment_variables.EnvironmentVariables.MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT
+	previousRoll := shadowSampleRoll
+	t.Cleanup(func() {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT = previousPercent
+		shadowSampleRoll = previousRoll
+	})
+
+	tests := []struct {
+		name    string
+		percent int
+		roll    int
+		want    bool
+	}{
+		{name: "default samples the lowest rolls", percent: 0, roll: defaultShadowSamplePercent - 1, want: true},
+		{name: "default skips the rest", percent: 0, roll: defaultShadowSamplePercent, want: false},
+		{name: "configured percent", percent: 25, roll: 24, want: true},
+		{name: "roll at the configured percent", percent: 25, roll: 25, want: false},
+		{name: "over 100 replays everything", percent: 500, roll: 99, want: true},
+	}
+	for _, tt := range tests {
+		environment_variables.EnvironmentVariables.MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT = tt.percent
+		shadowSampleRoll = func() int { return tt.roll }
+		if got := shadowSampled(); got != tt.want {
+			t.Errorf("%s: shadowSampled() = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShadowComparisonFields(t *testing.T) {
+	response := func(content string, promptTokens, completionTokens int) *openai.ChatCompletionResponse {
+		return &openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: content}}},
+			Usage:   openai.Usage{PromptTokens: promptTokens, CompletionTokens: completionTokens},
+		}
+	}
+
+	t.Run("compares content, tokens and cost", func(t *testing.T) {
+		primary := shadowPrimaryResult{content: "hello", usage: openai.Usage{CompletionTokens: 4}, cost: 300, costKnown: true}
+		fields := shadowComparisonFields(primary, response("hello there", 10, 6), 450, true)
+
+		want := map[string]any{
+			"primary_completion_tokens": 4,
+			"shadow_completion_tokens":  6,
+			"shadow_prompt_tokens":      10,
+			"content_match":             false,
+			"content_length_delta":      6,
+			"primary_cost_micro_usd":    int64(300),
+			"shadow_cost_micro_usd":     int64(450),
+			"cost_delta_micro_usd":      int64(150),
+		}
+		for key, value := range want {
+			if fields[key] != value {
+				t.Errorf("%s = %v, want %v", key, fields[key], value)
+			}
+		}
+	})
+
+	t.Run("matching content", func(t *testing.T) {
+		fields := shadowComparisonFields(shadowPrimaryResult{content: "same"}, response("same", 1, 1), 0, false)
+		if fields["content_match"] != true || fields["content_length_delta"] != 0 {
+			t.Fatalf("content_match = %v, content_length_delta = %v, want a match", fields["content_match"], fields["content_length_delta"])
+		}
+	})
+
+	t.Run("unknown costs are omitted", func(t *testing.T) {
+		fields := shadowComparisonFields(shadowPrimaryResult{cost: 100, costKnown: true}, response("", 1, 1), 0, false)
+		if fields["primary_cost_micro_usd"] != int64(100) {
+			t.Fatalf("primary_cost_micro_usd = %v, want 100", fields["primary_cost_micro_usd"])
+		}
+		for _, key := range []string{"shadow_cost_micro_usd", "cost_delta_micro_usd"} {
+			if _, ok := fields[key]; ok {
+				t.Errorf("%s logged without a known shadow cost", key)
+			}
+		}
+	})
+
+	t.Run("no choices", func(t *testing.T) {
+		fields := shadowComparisonFields(shadowPrimaryResult{content: "abc"}, &openai.ChatCompletionResponse{}, 0, false)
+		if fields["content_match"] != false || fields["content_length_delta"] != -3 {
+			t.Fatalf("content_match = %v, content_length_delta = %v", fields["content_match"], fields["content_length_delta"])
+		}
+	})
+}
+
+// shadowLogHook hands the logged outcome of shadow replays to the test.
+type shadowLogHook struct {
+	entries chan *logrus.Entry
+}
+
+func (h *shadowLogHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *shadowLogHook) Fire(entry *logrus.Entry) error {
+	if strings.HasPrefix(entry.Message, "shadow completion") {
+		select {
+		case h.entries <- entry:
+		default:
+		}
+	}
+	return nil
+}
+
+func TestShadowReplay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	previousRoll := shadowSampleRoll
+	shadowSampleRoll = func() int { return 0 }
+	hook := &shadowLogHook{entries: make(chan *logrus.Entry, 1)}
+	previousHooks := logger.GetLogger().ReplaceHooks(make(logrus.LevelHooks))
+	logger.GetLogger().AddHook(hook)
+	t.Cleanup(func() {
+		organization.DEFAULT_ORGANIZATION = previousDefault
+		shadowSampleRoll = previousRoll
+		logger.GetLogger().ReplaceHooks(previousHooks)
+	})
+
+	primaryUpstream := completionUpstream(t, "hello", nil)
+	shadowRequests := make(chan openai.ChatCompletionRequest, 1)
+	shadowUpstream := completionUpstream(t, "bonjour", shadowRequests)
+
+	registry := modeltest.NewRegistry()
+	primary := &domainmodel.Provider{PublicID: "prov-primary", Slug: "primary", DisplayName: "primary", OrganizationID: &orgID, BaseURL: primaryUpstream.URL, Active: true}
+	shadow := &domainmodel.Provider{PublicID: "prov-shadow", Slug: "shadow", DisplayName: "shadow", OrganizationID: &orgID, BaseURL: shadowUpstream.URL, Active: true, Shadow: true}
+	registry.Providers.Add(primary, shadow)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: primary.ID, ModelKey: "gpt", Active: true},
+		&domainmodel.ProviderModel{ProviderID: shadow.ID, ModelKey: "gpt", Active: true},
+	)
+
+	recorder := postCompletion(newCompletionTestAPI(registry), `{"model":"gpt","messages":[{"role":"user","content":"hi"}]}`, nil)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", recorder.Code, recorder.Body.String())
+	}
+	served := recorder.Body.String()
+
+	select {
+	case request := <-shadowRequests:
+		if request.Model != "gpt" || request.Stream || len(request.Messages) != 1 || request.Messages[0].Content != "hi" {
+			t.Fatalf("shadow request = %+v, want the client's non-streamed request", request)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request was not replayed to the shadow provider")
+	}
+	select {
+	case entry := <-hook.entries:
+		if entry.Message != "shadow completion recorded" {
+			t.Fatalf("logged %q with %v, want the shadow completion recorded", entry.Message, entry.Data)
+		}
+		if entry.Data["primary_provider"] != "primary" || entry.Data["shadow_provider"] != "shadow" || entry.Data["content_match"] != false {
+			t.Fatalf("comparison fields = %v", entry.Data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the shadow completion was not recorded")
+	}
+
+	if recorder.Body.String() != served {
+		t.Fatalf("client body changed after the replay: %s, was %s", recorder.Body.String(), served)
+	}
+	var response openai.ChatCompletionResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid body %s: %v", served, err)
+	}
+	if len(response.Choices) != 1 || response.Choices[0].Message.Content != "hello" {
+		t.Fatalf("client received %s, want the primary's answer", served)
+	}
+}
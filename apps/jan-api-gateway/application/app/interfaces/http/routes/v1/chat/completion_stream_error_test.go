@@ -0,0 +1,75 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/common"
+	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
+	"resty.dev/v3"
+)
+
+func TestStreamUpstreamErrorKeepsStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		wantStatus int
+	}{
+		{name: "rate limit", status: http.StatusTooManyRequests, retryAfter: "7", body: `{"error":{"message":"slow down","type":"rate_limit_error"}}`, wantStatus: http.StatusTooManyRequests},
+		{name: "invalid key", status: http.StatusUnauthorized, body: `{"error":{"message":"bad key","type":"invalid_request_error"}}`, wantStatus: http.StatusUnauthorized},
+		{name: "context length", status: http.StatusBadRequest, body: `{"error":{"message":"too long","code":"context_length_exceeded"}}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "server error", status: http.StatusServiceUnavailable, body: `{"error":{"message":"down"}}`, wantStatus: http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer upstream.Close()
+
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+			client := chatclient.NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+			_, err := client.StreamChatCompletionToContext(reqCtx, "", openai.ChatCompletionRequest{
+				Model:    "gpt-test",
+				Stream:   true,
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			})
+			if err == nil {
+				t.Fatal("stream succeeded, want upstream error")
+			}
+			if reqCtx.Writer.Written() {
+				t.Fatal("response was committed before the upstream answered")
+			}
+
+			abortCompletion(reqCtx, common.NewError(err, "test"))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if contentType := recorder.Header().Get("Content-Type"); contentType == "text/event-stream" {
+				t.Fatalf("error answered as %s", contentType)
+			}
+			if got := recorder.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Fatalf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+			if calls != 1 {
+				t.Fatalf("upstream called %d times, streams must not be retried", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package chat
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// strictParamsHeader opts a completion into rejecting parameters the model's catalog does not list
+// as supported, instead of passing them through to the upstream.
+const strictParamsHeader = "x-jan-strict-params"
+
+// unsupportedParamsResponse lists the request parameters the model does not support.
+type unsupportedParamsResponse struct {
+	Code                  string   `json:"code"`
+	Error                 string   `json:"error"`
+	UnsupportedParameters []string `json:"unsupported_parameters"`
+}
+
+// checkSupportedParams rejects, in strict mode, a request using parameters missing from the
+// supported parameters recorded in the catalog of the model on the provider tried first. Models
+// without a recorded parameter list pass.
+func checkSupportedParams(reqCtx *gin.Context, model primaryModel, request openai.ChatCompletionRequest) bool {
+	if !strings.EqualFold(strings.TrimSpace(reqCtx.GetHeader(strictParamsHeader)), "true") {
+		return true
+	}
+	catalog := model.catalog
+	if catalog == nil || len(catalog.SupportedParameters.Names) == 0 {
+		return true
+	}
+
+	unsupported := unsupportedParams(request, catalog.SupportedParameters.Names)
+	if len(unsupported) == 0 {
+		return true
+	}
+	reqCtx.AbortWithStatusJSON(http.StatusBadRequest, unsupportedParamsResponse{
+		Code:                  "2e7b9d4f-6a1c-4f83-b5d0-8c3a1e9f7b62",
+		Error:                 "model " + request.Model + " does not support: " + strings.Join(unsupported, ", "),
+		UnsupportedParameters: unsupported,
+	})
+	return false
+}
+
+// unsupportedParams returns the parameters set on the request that are not in supported. Request
+// fields are named as in supported_parameters, so max_completion_tokens counts as max_tokens and
+// reasoning_effort as reasoning.
+func unsupportedParams(request openai.ChatCompletionRequest, supported []string) []string {
+	used := []struct {
+		name string
+		set  bool
+	}{
+		{"max_tokens", request.MaxTokens > 0 || request.MaxCompletionTokens > 0},
+		{"temperature", request.Temperature != 0},
+		{"top_p", request.TopP != 0},
+		{"stop", len(request.Stop) > 0},
+		{"frequency_penalty", request.FrequencyPenalty != 0},
+		{"presence_penalty", request.PresencePenalty != 0},
+		{"seed", request.Seed != nil},
+		{"logit_bias", len(request.LogitBias) > 0},
+		{"logprobs", request.LogProbs},
+		{"top_logprobs", request.TopLogProbs > 0},
+		{"response_format", request.ResponseFormat != nil},
+		{"tools", len(request.Tools) > 0 || len(request.Functions) > 0},
+		{"tool_choice", request.ToolChoice != nil},
+		{"parallel_tool_calls", request.ParallelToolCalls != nil},
+		{"reasoning", request.ReasoningEffort != ""},
+	}
+
+	normalized := make([]string, 0, len(supported))
+	for _, name := range supported {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(name)))
+	}
+	var unsupported []string
+	for _, param := range used {
+		if param.set && !slices.Contains(normalized, param.name) {
+			unsupported = append(unsupported, param.name)
+		}
+	}
+	return unsupported
+}
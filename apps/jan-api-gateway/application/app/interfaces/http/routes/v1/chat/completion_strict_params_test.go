@@ -0,0 +1,99 @@
+package chat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+func TestCheckSupportedParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	restricted := primaryModel{catalog: &domainmodel.ModelCatalog{
+		SupportedParameters: domainmodel.SupportedParameters{Names: []string{"max_tokens", "Temperature ", "stop"}},
+	}}
+	seed := 42
+	tools := []openai.Tool{{Type: openai.ToolTypeFunction, Function: &openai.FunctionDefinition{Name: "weather"}}}
+
+	tests := []struct {
+		name        string
+		model       primaryModel
+		strict      string
+		request     openai.ChatCompletionRequest
+		unsupported []string
+	}{
+		{
+			name:    "supported parameters",
+			model:   restricted,
+			strict:  "true",
+			request: openai.ChatCompletionRequest{MaxCompletionTokens: 64, Temperature: 0.2, Stop: []string{"\n"}},
+		},
+		{
+			name:        "tools and seed on a restricted model",
+			model:       restricted,
+			strict:      "true",
+			request:     openai.ChatCompletionRequest{Temperature: 0.2, Seed: &seed, Tools: tools, ToolChoice: "auto"},
+			unsupported: []string{"seed", "tools", "tool_choice"},
+		},
+		{
+			name:        "reasoning effort",
+			model:       restricted,
+			strict:      "TRUE",
+			request:     openai.ChatCompletionRequest{ReasoningEffort: "high"},
+			unsupported: []string{"reasoning"},
+		},
+		{
+			name:    "strict mode off",
+			model:   restricted,
+			request: openai.ChatCompletionRequest{Tools: tools},
+		},
+		{
+			name:    "catalog without a parameter list",
+			model:   primaryModel{catalog: &domainmodel.ModelCatalog{}},
+			strict:  "true",
+			request: openai.ChatCompletionRequest{Tools: tools},
+		},
+		{
+			name:    "no catalog",
+			strict:  "true",
+			request: openai.ChatCompletionRequest{Tools: tools},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.strict != "" {
+				reqCtx.Request.Header.Set(strictParamsHeader, tt.strict)
+			}
+			tt.request.Model = "restricted"
+
+			allowed := len(tt.unsupported) == 0
+			if got := checkSupportedParams(reqCtx, tt.model, tt.request); got != allowed {
+				t.Fatalf("checkSupportedParams = %t, want %t", got, allowed)
+			}
+			if allowed {
+				if reqCtx.IsAborted() {
+					t.Fatal("permitted request was aborted")
+				}
+				return
+			}
+			if recorder.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+			var body unsupportedParamsResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if !slices.Equal(body.UnsupportedParameters, tt.unsupported) {
+				t.Fatalf("unsupported_parameters = %v, want %v", body.UnsupportedParameters, tt.unsupported)
+			}
+		})
+	}
+}
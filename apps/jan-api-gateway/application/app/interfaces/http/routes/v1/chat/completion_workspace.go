@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+// workspaceHeader names a workspace of the caller whose instruction becomes the system prompt.
+const workspaceHeader = "x-jan-workspace"
+
+// applyWorkspaceInstruction prepends the instruction of the workspace named by the
+// x-jan-workspace header as a system message. The workspace must belong to the authenticated
+// caller. It returns false after aborting the request.
+func (cApi *CompletionAPI) applyWorkspaceInstruction(reqCtx *gin.Context, request *openai.ChatCompletionRequest) bool {
+	workspaceID := strings.TrimSpace(reqCtx.GetHeader(workspaceHeader))
+	if workspaceID == "" {
+		return true
+	}
+	user, ok := cApi.authService.ResolveAppUser(reqCtx)
+	if !ok {
+		reqCtx.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{
+			Code:  "5e2a9c7d-3f1b-4d86-a0e4-c8b6f2d1a937",
+			Error: workspaceHeader + " requires an authenticated user",
+		})
+		return false
+	}
+
+	ws, err := cApi.workspaceService.GetWorkspaceByPublicIDAndUserID(reqCtx.Request.Context(), workspaceID, user.ID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.GetCode() == "c8bc424c-5b20-4cf9-8ca1-7d9ad1b098c8" {
+			status = http.StatusNotFound
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.Error(),
+		})
+		return false
+	}
+
+	if ws.Instruction != nil {
+		request.Messages = withWorkspaceInstruction(request.Messages, *ws.Instruction)
+	}
+	return true
+}
+
+// withWorkspaceInstruction prepends the instruction as a system message unless it is empty or the
+// client already supplied a system or developer message.
+func withWorkspaceInstruction(messages []openai.ChatCompletionMessage, instruction string) []openai.ChatCompletionMessage {
+	instruction = strings.TrimSpace(instruction)
+	if instruction == "" {
+		return messages
+	}
+	for _, message := range messages {
+		if message.Role == openai.ChatMessageRoleSystem || message.Role == openai.ChatMessageRoleDeveloper {
+			return messages
+		}
+	}
+	return append([]openai.ChatCompletionMessage{{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: instruction,
+	}}, messages...)
+}
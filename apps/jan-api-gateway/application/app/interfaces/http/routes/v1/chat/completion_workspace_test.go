@@ -0,0 +1,124 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/domain/user"
+	"menlo.ai/jan-api-gateway/app/domain/workspace"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+// workspaceRepository serves FindByFilter from a fixed set of workspaces.
+type workspaceRepository struct {
+	workspace.WorkspaceRepository
+	workspaces []*workspace.Workspace
+}
+
+func (r *workspaceRepository) FindByFilter(ctx context.Context, filter workspace.WorkspaceFilter, pagination *query.Pagination) ([]*workspace.Workspace, error) {
+	var found []*workspace.Workspace
+	for _, ws := range r.workspaces {
+		if filter.PublicID != nil && ws.PublicID != *filter.PublicID {
+			continue
+		}
+		if filter.UserID != nil && ws.UserID != *filter.UserID {
+			continue
+		}
+		found = append(found, ws)
+	}
+	return found, nil
+}
+
+func TestApplyWorkspaceInstruction(t *testing.T) {
+	instruction := "Answer in French."
+	blank := "   "
+	repo := &workspaceRepository{workspaces: []*workspace.Workspace{
+		{ID: 1, PublicID: "ws-french", UserID: 7, Instruction: &instruction},
+		{ID: 2, PublicID: "ws-blank", UserID: 7, Instruction: &blank},
+		{ID: 3, PublicID: "ws-plain", UserID: 7},
+		{ID: 4, PublicID: "ws-other", UserID: 8, Instruction: &instruction},
+	}}
+	cApi := &CompletionAPI{
+		authService:      &auth.AuthService{},
+		workspaceService: workspace.NewWorkspaceService(repo, nil),
+	}
+	userMessage := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: "hi"}
+
+	tests := []struct {
+		name      string
+		header    string
+		anonymous bool
+		messages  []openai.ChatCompletionMessage
+		status    int
+		want      []string
+	}{
+		{name: "no header", messages: []openai.ChatCompletionMessage{userMessage}, want: []string{"user"}},
+		{name: "instruction prepended", header: "ws-french", messages: []openai.ChatCompletionMessage{userMessage}, want: []string{"system:" + instruction, "user"}},
+		{
+			name:     "client system message wins",
+			header:   "ws-french",
+			messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleSystem, Content: "Be terse."}, userMessage},
+			want:     []string{"system:Be terse.", "user"},
+		},
+		{
+			name:     "client developer message wins",
+			header:   "ws-french",
+			messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleDeveloper, Content: "Be terse."}, userMessage},
+			want:     []string{"developer", "user"},
+		},
+		{name: "blank instruction", header: "ws-blank", messages: []openai.ChatCompletionMessage{userMessage}, want: []string{"user"}},
+		{name: "no instruction", header: "ws-plain", messages: []openai.ChatCompletionMessage{userMessage}, want: []string{"user"}},
+		{name: "unknown workspace", header: "ws-missing", status: http.StatusNotFound},
+		{name: "workspace of another user", header: "ws-other", status: http.StatusNotFound},
+		{name: "anonymous caller", header: "ws-french", anonymous: true, status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set(workspaceHeader, tt.header)
+			}
+			if !tt.anonymous {
+				auth.SetUserToContext(reqCtx, &user.User{ID: 7})
+			}
+			request := openai.ChatCompletionRequest{Messages: tt.messages}
+
+			ok := cApi.applyWorkspaceInstruction(reqCtx, &request)
+			if tt.status != 0 {
+				if ok || recorder.Code != tt.status {
+					t.Fatalf("applyWorkspaceInstruction = %v with status %d, want it aborted with %d", ok, recorder.Code, tt.status)
+				}
+				var body responses.ErrorResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Code == "" {
+					t.Fatalf("error body %s, want an error code", recorder.Body.String())
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("applyWorkspaceInstruction aborted with %d: %s", recorder.Code, recorder.Body.String())
+			}
+			got := make([]string, 0, len(request.Messages))
+			for _, message := range request.Messages {
+				if message.Role == openai.ChatMessageRoleSystem {
+					got = append(got, message.Role+":"+message.Content)
+					continue
+				}
+				got = append(got, message.Role)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package chat
+
+import (
+	"sort"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// DefaultMaxEmbeddingInputs caps the inputs of one embeddings request when
+// MAX_EMBEDDING_INPUTS is unset, matching the OpenAI limit.
+const DefaultMaxEmbeddingInputs = 2048
+
+// dimensionsParameter is the supported parameter name catalogs list for models that can shorten
+// their embeddings.
+const dimensionsParameter = "dimensions"
+
+// maxEmbeddingInputs returns MAX_EMBEDDING_INPUTS, falling back to the default when it is unset
+// or not positive.
+func maxEmbeddingInputs() int {
+	if limit := environment_variables.EnvironmentVariables.MAX_EMBEDDING_INPUTS; limit > 0 {
+		return limit
+	}
+	return DefaultMaxEmbeddingInputs
+}
+
+// embeddingInputCount returns the number of inputs embedded for a decoded input: one for a string
+// or a single token array, and the array length for a batch of strings or token arrays. Empty or
+// mixed batches and values of other types are reported as invalid.
+func embeddingInputCount(input any) (int, bool) {
+	switch value := input.(type) {
+	case string:
+		if value == "" {
+			return 0, false
+		}
+		return 1, true
+	case []any:
+		if len(value) == 0 {
+			return 0, false
+		}
+		switch value[0].(type) {
+		case float64:
+			for _, item := range value {
+				if _, ok := item.(float64); !ok {
+					return 0, false
+				}
+			}
+			return 1, true
+		case string:
+			for _, item := range value {
+				if text, ok := item.(string); !ok || text == "" {
+					return 0, false
+				}
+			}
+			return len(value), true
+		case []any:
+			for _, item := range value {
+				tokens, ok := item.([]any)
+				if !ok || len(tokens) == 0 {
+					return 0, false
+				}
+				for _, token := range tokens {
+					if _, ok := token.(float64); !ok {
+						return 0, false
+					}
+				}
+			}
+			return len(value), true
+		}
+	}
+	return 0, false
+}
+
+// supportsDimensions reports whether the model accepts the dimensions parameter. A catalog listing
+// its supported parameters decides; otherwise the OpenAI text-embedding-3 family, the first to
+// support it, is recognised by name.
+func supportsDimensions(modelKey string, catalog *domainmodel.ModelCatalog) bool {
+	if catalog != nil && len(catalog.SupportedParameters.Names) > 0 {
+		for _, name := range catalog.SupportedParameters.Names {
+			if name == dimensionsParameter {
+				return true
+			}
+		}
+		return false
+	}
+	name := modelKey
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.HasPrefix(strings.ToLower(name), "text-embedding-3")
+}
+
+// orderEmbeddings returns the embeddings in input order. Upstream indices are kept when they
+// cover every input exactly once; otherwise the upstream order is trusted and each embedding is
+// given its position as index.
+func orderEmbeddings(data []openai.Embedding) []openai.Embedding {
+	seen := make([]bool, len(data))
+	valid := true
+	for _, item := range data {
+		if item.Index < 0 || item.Index >= len(data) || seen[item.Index] {
+			valid = false
+			break
+		}
+		seen[item.Index] = true
+	}
+	if valid {
+		sort.SliceStable(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+		return data
+	}
+	for i := range data {
+		data[i].Index = i
+	}
+	return data
+}
@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+)
+
+func TestEmbeddingInputCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		count int
+		ok    bool
+	}{
+		{name: "single string", input: `"hello"`, count: 1, ok: true},
+		{name: "empty string", input: `""`, ok: false},
+		{name: "batch of strings", input: `["a","b","c"]`, count: 3, ok: true},
+		{name: "batch with empty string", input: `["a",""]`, ok: false},
+		{name: "single token array", input: `[1,2,3]`, count: 1, ok: true},
+		{name: "batch of token arrays", input: `[[1,2],[3]]`, count: 2, ok: true},
+		{name: "batch with empty token array", input: `[[1],[]]`, ok: false},
+		{name: "empty batch", input: `[]`, ok: false},
+		{name: "mixed batch", input: `["a",1]`, ok: false},
+		{name: "object", input: `{"text":"a"}`, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request openai.EmbeddingRequest
+			if err := json.Unmarshal([]byte(`{"model":"m","input":`+tt.input+`}`), &request); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			count, ok := embeddingInputCount(request.Input)
+			if ok != tt.ok || count != tt.count {
+				t.Fatalf("embeddingInputCount(%s) = %d, %t; want %d, %t", tt.input, count, ok, tt.count, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSupportsDimensions(t *testing.T) {
+	withParams := func(names ...string) *domainmodel.ModelCatalog {
+		return &domainmodel.ModelCatalog{SupportedParameters: domainmodel.SupportedParameters{Names: names}}
+	}
+	tests := []struct {
+		name     string
+		modelKey string
+		catalog  *domainmodel.ModelCatalog
+		want     bool
+	}{
+		{name: "catalog lists dimensions", modelKey: "acme-embed", catalog: withParams("input", "dimensions"), want: true},
+		{name: "catalog without dimensions", modelKey: "text-embedding-3-small", catalog: withParams("input"), want: false},
+		{name: "text-embedding-3 without catalog", modelKey: "text-embedding-3-large", want: true},
+		{name: "vendor prefixed text-embedding-3", modelKey: "openai/text-embedding-3-small", catalog: withParams(), want: true},
+		{name: "ada without catalog", modelKey: "text-embedding-ada-002", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := supportsDimensions(tt.modelKey, tt.catalog); got != tt.want {
+				t.Fatalf("supportsDimensions(%q) = %t, want %t", tt.modelKey, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderEmbeddings(t *testing.T) {
+	tests := []struct {
+		name    string
+		indices []int
+		want    []int
+		vectors []float32
+	}{
+		{name: "already ordered", indices: []int{0, 1, 2}, want: []int{0, 1, 2}, vectors: []float32{0, 1, 2}},
+		{name: "shuffled", indices: []int{2, 0, 1}, want: []int{0, 1, 2}, vectors: []float32{1, 2, 0}},
+		{name: "missing indices", indices: []int{0, 0, 0}, want: []int{0, 1, 2}, vectors: []float32{0, 1, 2}},
+		{name: "out of range", indices: []int{1, 5}, want: []int{0, 1}, vectors: []float32{0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]openai.Embedding, len(tt.indices))
+			for i, index := range tt.indices {
+				data[i] = openai.Embedding{Index: index, Embedding: []float32{float32(i)}}
+			}
+			got := orderEmbeddings(data)
+			for i, item := range got {
+				if item.Index != tt.want[i] || item.Embedding[0] != tt.vectors[i] {
+					t.Fatalf("position %d = index %d vector %v, want index %d vector %v", i, item.Index, item.Embedding[0], tt.want[i], tt.vectors[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package chat
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/middleware"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+)
+
+// EmbeddingsAPI proxies OpenAI-compatible embeddings requests to the provider serving the model.
+type EmbeddingsAPI struct {
+	inferenceProvider    *inference.InferenceProvider
+	providerRegistry     *domainmodel.ProviderRegistryService
+	providerModelService *domainmodel.ProviderModelService
+	authService          *auth.AuthService
+}
+
+func NewEmbeddingsAPI(
+	inferenceProvider *inference.InferenceProvider,
+	providerRegistry *domainmodel.ProviderRegistryService,
+	providerModelService *domainmodel.ProviderModelService,
+	authService *auth.AuthService,
+) *EmbeddingsAPI {
+	return &EmbeddingsAPI{
+		inferenceProvider:    inferenceProvider,
+		providerRegistry:     providerRegistry,
+		providerModelService: providerModelService,
+		authService:          authService,
+	}
+}
+
+func (embeddingsAPI *EmbeddingsAPI) RegisterRouter(router gin.IRouter) {
+	router.POST("/embeddings", middleware.BodyLimit(middleware.MaxRequestBodyBytes()), embeddingsAPI.PostEmbeddings)
+}
+
+// PostEmbeddings
+// @Summary Create embeddings
+// @Description Creates an embedding vector for each input, proxied to the provider that serves the requested model. Batched inputs are limited to MAX_EMBEDDING_INPUTS, dimensions is only accepted for models that support it, and embeddings are returned in input order.
+// @Tags Chat Completions API
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body openai.EmbeddingRequest true "Embeddings request"
+// @Param x-jan-provider-key header string false "Your own API key for the upstream, required when the serving provider uses passthrough keys"
+// @Success 200 {object} openai.EmbeddingResponse "Embeddings for the input"
+// @Failure 400 {object} responses.ErrorResponse "Invalid request payload, batch over MAX_EMBEDDING_INPUTS, dimensions for a model without support, model without embeddings support, or missing x-jan-provider-key for a passthrough provider"
+// @Failure 413 {object} responses.ErrorResponse "Request body exceeds MAX_REQUEST_BODY_BYTES"
+// @Failure 502 {object} responses.ErrorResponse "Upstream provider failure"
+// @Router /v1/embeddings [post]
+func (embeddingsAPI *EmbeddingsAPI) PostEmbeddings(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	var request openai.EmbeddingRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			middleware.AbortBodyTooLarge(reqCtx, middleware.MaxRequestBodyBytes())
+			return
+		}
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "3f8a1c6e-5b27-4d94-a0e3-7c2d9b4f1e58",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	modelKey := string(request.Model)
+	if modelKey == "" {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "a6d2e9f4-1c73-4b58-8e06-b3f7c1a5d942",
+			Error: "model is required",
+		})
+		return
+	}
+	if request.Input == nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "c1b7f3a9-6e42-4d08-9a5c-2f8e4d6b0a73",
+			Error: "input is required",
+		})
+		return
+	}
+	inputCount, ok := embeddingInputCount(request.Input)
+	if !ok {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "4e9c2a7d-8b15-4f63-a0d8-3c6f1e9b5a27",
+			Error: "input must be a non-empty string, array of strings, array of tokens or array of token arrays",
+		})
+		return
+	}
+	if limit := maxEmbeddingInputs(); inputCount > limit {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "b8d3f6a1-2c94-4e57-9f0b-6a1d7e4c2b85",
+			Error: fmt.Sprintf("input contains %d items; at most %d are allowed", inputCount, limit),
+		})
+		return
+	}
+	if request.Dimensions < 0 {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "f2a6c9e4-7d31-4b85-8e0a-5c3b9d1f6e72",
+			Error: "dimensions must be a positive integer",
+		})
+		return
+	}
+
+	// A project API key also reaches the providers registered on its project
+	proj, ok := resolveApikeyProject(reqCtx, embeddingsAPI.authService)
+	if !ok {
+		return
+	}
+	selection, err := embeddingsAPI.providerRegistry.SelectProviderForModel(ctx, modelKey, organization.DEFAULT_ORGANIZATION.ID, projectScope(proj))
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "e8c4a2d6-9f15-4b73-b0e1-5d7a3c9f2b84",
+			ErrorInstance: err,
+		})
+		return
+	}
+	provider := selection.Provider
+	responses.SetProviderFallbackHeaders(reqCtx, selection.FallbackReason)
+	_, providerKey, ok := passthroughProviders(reqCtx, []*domainmodel.Provider{provider})
+	if !ok {
+		return
+	}
+
+	// The Jan fallback has no synced model rows to check, so only matched providers are validated.
+	var catalog *domainmodel.ModelCatalog
+	if selection.Resolution == domainmodel.ProviderResolutionMatched {
+		providerModels, err := embeddingsAPI.providerModelService.FindActiveByProviderIDsAndKey(ctx, []uint{provider.ID}, modelKey)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:          "5d9b3e7f-2a68-4c41-9f0d-8e6c1b4a7d25",
+				ErrorInstance: err,
+			})
+			return
+		}
+		if len(providerModels) == 0 || !providerModels[0].SupportsEmbeddings {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "9a2f6d1b-7e84-4c3a-a5b9-0d4e8f2c6a17",
+				Error: fmt.Sprintf("model '%s' does not support embeddings", modelKey),
+			})
+			return
+		}
+		if request.Dimensions > 0 {
+			catalog, err = embeddingsAPI.providerRegistry.FindModelCatalog(ctx, providerModels[0])
+			if err != nil {
+				logger.GetLogger().Warnf("unable to load catalog of model %s: %v", modelKey, err)
+			}
+		}
+	}
+	if request.Dimensions > 0 && !supportsDimensions(modelKey, catalog) {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "6c1e8b3f-9a47-4d20-b5e6-2f7a4c9d1b38",
+			Error: fmt.Sprintf("model '%s' does not support dimensions", modelKey),
+		})
+		return
+	}
+
+	chatClient, err := embeddingsAPI.inferenceProvider.GetChatCompletionClient(provider)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to create chat client: %v", err)
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "2b7e4c9a-3d16-4f85-8a0c-6e1f9d3b5a48",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	response, err := chatClient.CreateEmbeddings(ctx, providerAPIKey(provider, providerKey), request)
+	if err != nil {
+		logger.GetLogger().Errorf("embeddings failed: %v", err)
+		reqCtx.AbortWithStatusJSON(http.StatusBadGateway, responses.ErrorResponse{
+			Code:          "7f3c9e2d-8b41-4a6e-b5d0-1c8a4f7e2b96",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	response.Data = orderEmbeddings(response.Data)
+	reqCtx.JSON(http.StatusOK, response)
+}
@@ -79,6 +79,9 @@ type ExtendedChatCompletionRequest struct {
 	Conversation   string `json:"conversation,omitempty"`
 	Store          bool   `json:"store,omitempty"`           // If true, the response will be stored in the conversation, default is false
 	StoreReasoning bool   `json:"store_reasoning,omitempty"` // If true, the reasoning will be stored in the conversation, default is false
+	// ParentMessageID branches the conversation from an earlier item: the context is built from that item's
+	// ancestors and the stored messages start a new branch under it.
+	ParentMessageID string `json:"parent_message_id,omitempty"`
 }
 
 // ResponseMetadata contains additional metadata about the completion response
@@ -124,6 +127,7 @@ type ModelsWithProviderResponse = modelroute.ModelsWithProviderResponse
 // @Description - `store=true`: Saves user message and assistant response to conversation
 // @Description - `store_reasoning=true`: Includes reasoning content in stored messages
 // @Description - `conversation`: ID of existing conversation or empty for new conversation
+// @Description - `parent_message_id`: ID of an earlier item to branch from; its ancestors are prepended to `messages`
 // @Description
 // @Description **Features:**
 // @Description - Conversation persistence and history management
@@ -140,7 +144,7 @@ type ModelsWithProviderResponse = modelroute.ModelsWithProviderResponse
 // @Success 200 {string} string "Successful streaming response (when stream=true) - SSE format with data: {json} events"
 // @Failure 400 {object} responses.ErrorResponse "Invalid request payload or conversation not found"
 // @Failure 401 {object} responses.ErrorResponse "Unauthorized - missing or invalid authentication"
-// @Failure 404 {object} responses.ErrorResponse "Conversation not found or user not found"
+// @Failure 404 {object} responses.ErrorResponse "Conversation, user or parent_message_id not found"
 // @Failure 500 {object} responses.ErrorResponse "Internal server error"
 // @Router /v1/conv/chat/completions [post]
 func (api *ConvCompletionAPI) PostCompletion(reqCtx *gin.Context) {
@@ -199,6 +203,32 @@ func (api *ConvCompletionAPI) PostCompletion(reqCtx *gin.Context) {
 		return
 	}
 
+	// Build the context from the ancestor chain when branching from an earlier message
+	var branchParent *conversation.Item
+	if request.ParentMessageID != "" {
+		if conversationCreated {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "5a8e2c4f-7b1d-4d96-a3e0-f9c6b2d8e417",
+				Error: "parent_message_id requires an existing conversation",
+			})
+			return
+		}
+		branchItems, branchErr := api.conversationService.GetBranchItems(reqCtx.Request.Context(), conv, request.ParentMessageID)
+		if branchErr != nil {
+			status := http.StatusInternalServerError
+			if branchErr.GetCode() == conversation.ErrCodeBranchItemNotFound {
+				status = http.StatusNotFound
+			}
+			reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+				Code:          branchErr.GetCode(),
+				ErrorInstance: branchErr.GetError(),
+			})
+			return
+		}
+		branchParent = branchItems[len(branchItems)-1]
+		request.Messages = append(itemsToChatMessages(branchItems), request.Messages...)
+	}
+
 	// Generate item IDs for tracking
 	askItemID, _ := idgen.GenerateSecureID("msg", 42)
 	completionItemID, _ := idgen.GenerateSecureID("msg", 42)
@@ -226,7 +256,7 @@ func (api *ConvCompletionAPI) PostCompletion(reqCtx *gin.Context) {
 	}
 
 	// Process response (common logic for both streaming and non-streaming)
-	modifiedResponse := api.processCompletionResponse(reqCtx, response, request, conv, user, askItemID, completionItemID, conversationCreated)
+	modifiedResponse := api.processCompletionResponse(reqCtx, response, request, conv, user, askItemID, completionItemID, conversationCreated, branchParent)
 
 	// Only send JSON response for non-streaming requests (streaming uses SSE)
 	if !request.Stream && modifiedResponse != nil {
@@ -288,13 +318,20 @@ func (api *ConvCompletionAPI) GetModels(reqCtx *gin.Context) {
 }
 
 // processCompletionResponse handles the common response processing logic for both streaming and non-streaming
-func (api *ConvCompletionAPI) processCompletionResponse(reqCtx *gin.Context, response *ExtendedCompletionResponse, request ExtendedChatCompletionRequest, conv *conversation.Conversation, user *userdomain.User, askItemID string, completionItemID string, conversationCreated bool) *ExtendedCompletionResponse {
+func (api *ConvCompletionAPI) processCompletionResponse(reqCtx *gin.Context, response *ExtendedCompletionResponse, request ExtendedChatCompletionRequest, conv *conversation.Conversation, user *userdomain.User, askItemID string, completionItemID string, conversationCreated bool, branchParent *conversation.Item) *ExtendedCompletionResponse {
 	var assistantItem *conversation.Item
 
 	// Store messages conditionally based on store flag
 	if request.Store {
+		// When branching, the input hangs off the branch parent and the answer off the input
+		var askParentID, completionParentID *uint
+		if branchParent != nil {
+			askParentID = &branchParent.ID
+		}
+
 		// Store last input message (user or tool)
-		if storeErr := api.StoreLastInputMessageIfRequested(reqCtx.Request.Context(), request.ChatCompletionRequest, conv, user.ID, askItemID, completionItemID, request.Store, request.StoreReasoning); storeErr != nil {
+		askItem, storeErr := api.StoreLastInputMessageIfRequested(reqCtx.Request.Context(), request.ChatCompletionRequest, conv, user.ID, askItemID, completionItemID, request.Store, request.StoreReasoning, askParentID)
+		if storeErr != nil {
 			reqCtx.AbortWithStatusJSON(
 				http.StatusBadRequest,
 				responses.ErrorResponse{
@@ -304,8 +341,12 @@ func (api *ConvCompletionAPI) processCompletionResponse(reqCtx *gin.Context, res
 			return nil
 		}
 
+		if branchParent != nil && askItem != nil {
+			completionParentID = &askItem.ID
+		}
+
 		// Store assistant response
-		if item, err := api.StoreAssistantResponseIfRequested(reqCtx.Request.Context(), response, conv, user.ID, completionItemID, request.Store, request.StoreReasoning); err != nil {
+		if item, err := api.StoreAssistantResponseIfRequested(reqCtx.Request.Context(), response, conv, user.ID, completionItemID, request.Store, request.StoreReasoning, completionParentID); err != nil {
 			reqCtx.AbortWithStatusJSON(
 				http.StatusBadRequest,
 				responses.ErrorResponse{
@@ -539,19 +580,19 @@ func (api *ConvCompletionAPI) saveAssistantMessageToConversation(ctx context.Con
 }
 
 // StoreLastInputMessageIfRequested conditionally stores the last input message (user or tool) based on the store flag
-func (api *ConvCompletionAPI) StoreLastInputMessageIfRequested(ctx context.Context, request openai.ChatCompletionRequest, conv *conversation.Conversation, userID uint, askItemID string, completionItemID string, store bool, storeReasoning bool) *common.Error {
+func (api *ConvCompletionAPI) StoreLastInputMessageIfRequested(ctx context.Context, request openai.ChatCompletionRequest, conv *conversation.Conversation, userID uint, askItemID string, completionItemID string, store bool, storeReasoning bool, parentItemID *uint) (*conversation.Item, *common.Error) {
 	if !store {
-		return nil // Don't store if store flag is false
+		return nil, nil // Don't store if store flag is false
 	}
 
 	// Validate required parameters
 	if conv == nil {
-		return common.NewError(nil, "c1d2e3f4-g5h6-7890-abcd-ef1234567890")
+		return nil, common.NewError(nil, "c1d2e3f4-g5h6-7890-abcd-ef1234567890")
 	}
 
 	// Store the latest input message (user or tool)
 	if len(request.Messages) == 0 {
-		return nil // No messages to store
+		return nil, nil // No messages to store
 	}
 
 	latestMessage := request.Messages[len(request.Messages)-1]
@@ -566,15 +607,11 @@ func (api *ConvCompletionAPI) StoreLastInputMessageIfRequested(ctx context.Conte
 		},
 	}
 
-	if _, err := api.conversationService.AddItemWithID(ctx, conv, userID, conversation.ItemTypeMessage, &role, content, askItemID); err != nil {
-		return err
-	}
-
-	return nil
+	return api.conversationService.AddBranchItemWithID(ctx, conv, userID, conversation.ItemTypeMessage, &role, content, askItemID, parentItemID)
 }
 
 // StoreAssistantResponseIfRequested conditionally stores the assistant response based on the store flag
-func (api *ConvCompletionAPI) StoreAssistantResponseIfRequested(ctx context.Context, response *ExtendedCompletionResponse, conv *conversation.Conversation, userID uint, completionItemID string, store bool, storeReasoning bool) (*conversation.Item, *common.Error) {
+func (api *ConvCompletionAPI) StoreAssistantResponseIfRequested(ctx context.Context, response *ExtendedCompletionResponse, conv *conversation.Conversation, userID uint, completionItemID string, store bool, storeReasoning bool, parentItemID *uint) (*conversation.Item, *common.Error) {
 	if !store {
 		return nil, nil // Don't store if store flag is false
 	}
@@ -613,7 +650,7 @@ func (api *ConvCompletionAPI) StoreAssistantResponseIfRequested(ctx context.Cont
 	}
 
 	role := conversation.ItemRoleAssistant
-	createdItem, err := api.conversationService.AddItemWithID(ctx, conv, userID, conversation.ItemTypeMessage, &role, contentArray, completionItemID)
+	createdItem, err := api.conversationService.AddBranchItemWithID(ctx, conv, userID, conversation.ItemTypeMessage, &role, contentArray, completionItemID, parentItemID)
 	if err != nil {
 		return nil, err
 	}
@@ -669,3 +706,34 @@ func (api *ConvCompletionAPI) createContentArray(choice openai.ChatCompletionCho
 		},
 	}, nil
 }
+
+// itemsToChatMessages converts stored conversation items into chat messages, skipping items without text
+func itemsToChatMessages(items []*conversation.Item) []openai.ChatCompletionMessage {
+	messages := make([]openai.ChatCompletionMessage, 0, len(items))
+	for _, item := range items {
+		if item.Role == nil || len(item.Content) == 0 {
+			continue
+		}
+
+		var content strings.Builder
+		for _, contentPart := range item.Content {
+			switch {
+			case contentPart.Text != nil:
+				content.WriteString(contentPart.Text.Value)
+			case contentPart.OutputText != nil:
+				content.WriteString(contentPart.OutputText.Text)
+			case contentPart.InputText != nil:
+				content.WriteString(*contentPart.InputText)
+			}
+		}
+		if content.Len() == 0 {
+			continue
+		}
+
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    string(*item.Role),
+			Content: content.String(),
+		})
+	}
+	return messages
+}
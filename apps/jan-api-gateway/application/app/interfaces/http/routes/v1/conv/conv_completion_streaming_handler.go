@@ -17,6 +17,7 @@ import (
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
 	chatclient "menlo.ai/jan-api-gateway/app/utils/httpclients/chat"
 	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/tokenizer"
 )
 
 // Constants for streaming configuration
@@ -421,8 +422,8 @@ func (s *CompletionStreamHandler) buildCompleteResponse(content string, reasonin
 	}
 
 	// Calculate token usage
-	promptTokens := s.estimateTokens(request.Messages)
-	completionTokens := s.estimateTokens([]openai.ChatCompletionMessage{message})
+	promptTokens := tokenizer.EstimateTokens(request.Messages)
+	completionTokens := tokenizer.EstimateTokens([]openai.ChatCompletionMessage{message})
 	totalTokens := promptTokens + completionTokens
 
 	return openai.ChatCompletionResponse{
@@ -438,35 +439,3 @@ func (s *CompletionStreamHandler) buildCompleteResponse(content string, reasonin
 		},
 	}
 }
-
-// TODO it's raw solution, we need to use the official openai tokenizer like tiktoken
-// estimateTokens provides a rough estimation of token count for messages
-func (s *CompletionStreamHandler) estimateTokens(messages []openai.ChatCompletionMessage) int {
-	var allText strings.Builder
-
-	for _, msg := range messages {
-		allText.WriteString(msg.Content)
-		allText.WriteString(" ")
-
-		if msg.FunctionCall != nil {
-			allText.WriteString(msg.FunctionCall.Name)
-			allText.WriteString(" ")
-			allText.WriteString(msg.FunctionCall.Arguments)
-			allText.WriteString(" ")
-		}
-
-		for _, toolCall := range msg.ToolCalls {
-			allText.WriteString(toolCall.ID)
-			allText.WriteString(" ")
-			allText.WriteString(toolCall.Function.Name)
-			allText.WriteString(" ")
-			allText.WriteString(toolCall.Function.Arguments)
-			allText.WriteString(" ")
-		}
-	}
-
-	// Split by spaces and count words, but normalize whitespace
-	normalized := strings.Join(strings.Fields(allText.String()), " ") // Collapse multiple spaces
-	words := strings.Fields(normalized)
-	return len(words)
-}
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/query"
 	"menlo.ai/jan-api-gateway/app/domain/workspace"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
 	"menlo.ai/jan-api-gateway/app/utils/ptr"
@@ -55,11 +56,20 @@ type WorkspaceResponse struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+type WorkspaceListItemResponse struct {
+	WorkspaceResponse
+	ConversationCount int64 `json:"conversation_count"`
+}
+
 type WorkspaceDeletedResponse struct {
 	ID      string `json:"id"`
 	Deleted bool   `json:"deleted"`
 }
 
+type WorkspacesDeletedResponse struct {
+	Deleted int `json:"deleted"`
+}
+
 func NewWorkspaceRoute(authService *auth.AuthService, workspaceService *workspace.WorkspaceService) *WorkspaceRoute {
 	return &WorkspaceRoute{
 		authService:      authService,
@@ -76,6 +86,7 @@ func (route *WorkspaceRoute) RegisterRouter(router gin.IRouter) {
 	workspacesRouter := convRouter.Group("/workspaces")
 	workspacesRouter.POST("", route.CreateWorkspace)
 	workspacesRouter.GET("", route.ListWorkspaces)
+	workspacesRouter.DELETE("", route.DeleteAllWorkspaces)
 
 	workspaceMiddleware := route.workspaceService.GetWorkspaceMiddleware()
 	workspacesRouter.PATCH(
@@ -106,6 +117,7 @@ func (route *WorkspaceRoute) RegisterRouter(router gin.IRouter) {
 // @Success 201 {object} WorkspaceCreateResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Failure 401 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse "The user already owns MAX_WORKSPACES_PER_USER workspaces"
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /v1/conv/workspaces [post]
 func (route *WorkspaceRoute) CreateWorkspace(reqCtx *gin.Context) {
@@ -128,13 +140,16 @@ func (route *WorkspaceRoute) CreateWorkspace(reqCtx *gin.Context) {
 	}
 
 	ctx := reqCtx.Request.Context()
-	workspace := request.ConvertToWorkspace(user.ID)
-	workspaceEntity, err := route.workspaceService.CreateWorkspace(ctx, workspace)
+	newWorkspace := request.ConvertToWorkspace(user.ID)
+	workspaceEntity, err := route.workspaceService.CreateWorkspace(ctx, newWorkspace)
 	if err != nil {
 		status := http.StatusInternalServerError
-		if err.GetCode() == "3a5dcb2f-9f1c-4f4b-8893-4a62f72f7a00" || err.GetCode() == "94a6a12b-d4f0-4594-8125-95de7f9ce3d6" {
+		if err.GetCode() == "3a5dcb2f-9f1c-4f4b-8893-4a62f72f7a00" || err.GetCode() == "94a6a12b-d4f0-4594-8125-95de7f9ce3d6" || err.GetCode() == workspace.ErrCodeWorkspaceInstructionTooLong {
 			status = http.StatusBadRequest
 		}
+		if err.GetCode() == workspace.ErrCodeWorkspaceLimitReached {
+			status = http.StatusConflict
+		}
 		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
 			Code:  err.GetCode(),
 			Error: err.Error(),
@@ -147,11 +162,15 @@ func (route *WorkspaceRoute) CreateWorkspace(reqCtx *gin.Context) {
 
 // ListWorkspaces godoc
 // @Summary List Workspaces
-// @Description Lists all workspaces for the authenticated user.
+// @Description Lists the workspaces of the authenticated user with the number of conversations in each.
 // @Tags conv Workspaces API
 // @Security BearerAuth
 // @Produce json
+// @Param limit query int false "Maximum number of workspaces to return (default 20)"
+// @Param last query string false "Public ID of the last workspace of the previous page"
+// @Param order query string false "Order by creation, asc or desc (default asc)"
 // @Success 200 {object} WorkspaceListResponse
+// @Failure 400 {object} responses.ErrorResponse
 // @Failure 401 {object} responses.ErrorResponse
 // @Failure 500 {object} responses.ErrorResponse
 // @Router /v1/conv/workspaces [get]
@@ -166,36 +185,78 @@ func (route *WorkspaceRoute) ListWorkspaces(reqCtx *gin.Context) {
 	}
 
 	ctx := reqCtx.Request.Context()
-	workspaces, err := route.workspaceService.FindWorkspacesByFilter(ctx, workspace.WorkspaceFilter{
+	filter := workspace.WorkspaceFilter{
 		UserID: &user.ID,
-	}, nil)
+	}
+	pagination, err := query.GetCursorPaginationFromQuery(reqCtx, func(lastID string) (*uint, error) {
+		entity, findErr := route.workspaceService.GetWorkspaceByPublicIDAndUserID(ctx, lastID, user.ID)
+		if findErr != nil {
+			return nil, findErr
+		}
+		return &entity.ID, nil
+	})
 	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "d8f3a1c6-2e7b-4d94-b5a0-c9e6f2d4a813",
+			Error: "invalid pagination parameters",
+		})
+		return
+	}
+
+	workspaces, listErr := route.workspaceService.ListWithConversationCounts(ctx, user.ID, pagination)
+	if listErr != nil {
 		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
-			Code:  err.GetCode(),
-			Error: err.Error(),
+			Code:  listErr.GetCode(),
+			Error: listErr.Error(),
+		})
+		return
+	}
+	total, countErr := route.workspaceService.CountWorkspacesByFilter(ctx, filter)
+	if countErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  countErr.GetCode(),
+			Error: countErr.Error(),
 		})
 		return
 	}
 
-	responsesList := make([]WorkspaceResponse, len(workspaces))
-	for i, entity := range workspaces {
-		responsesList[i] = toWorkspaceResponse(entity)
+	responsesList := make([]WorkspaceListItemResponse, len(workspaces))
+	for i, item := range workspaces {
+		responsesList[i] = WorkspaceListItemResponse{
+			WorkspaceResponse: toWorkspaceResponse(item.Workspace),
+			ConversationCount: item.ConversationCount,
+		}
 	}
 
 	var firstID *string
 	var lastID *string
+	hasMore := false
 	if len(workspaces) > 0 {
-		firstID = ptr.ToString(workspaces[0].PublicID)
-		lastID = ptr.ToString(workspaces[len(workspaces)-1].PublicID)
+		last := workspaces[len(workspaces)-1].Workspace
+		firstID = ptr.ToString(workspaces[0].Workspace.PublicID)
+		lastID = ptr.ToString(last.PublicID)
+		more, moreErr := route.workspaceService.FindWorkspacesByFilter(ctx, filter, &query.Pagination{
+			Order: pagination.Order,
+			Limit: ptr.ToInt(1),
+			After: &last.ID,
+		})
+		if moreErr != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:  moreErr.GetCode(),
+				Error: moreErr.Error(),
+			})
+			return
+		}
+		hasMore = len(more) > 0
 	}
 
-	reqCtx.JSON(http.StatusOK, responses.ListResponse[WorkspaceResponse]{
+	reqCtx.JSON(http.StatusOK, responses.ListResponse[WorkspaceListItemResponse]{
 		Status:  responses.ResponseCodeOk,
-		Total:   int64(len(workspaces)),
+		Total:   total,
 		Results: responsesList,
 		FirstID: firstID,
 		LastID:  lastID,
-		HasMore: false,
+		HasMore: hasMore,
 	})
 }
 
@@ -287,7 +348,11 @@ func (route *WorkspaceRoute) UpdateWorkspaceInstruction(reqCtx *gin.Context) {
 	ctx := reqCtx.Request.Context()
 	updated, err := route.workspaceService.UpdateWorkspaceInstruction(ctx, workspaceEntity, request.Instruction)
 	if err != nil {
-		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+		status := http.StatusInternalServerError
+		if err.GetCode() == workspace.ErrCodeWorkspaceInstructionTooLong {
+			status = http.StatusBadRequest
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
 			Code:  err.GetCode(),
 			Error: err.Error(),
 		})
@@ -335,6 +400,38 @@ func (route *WorkspaceRoute) DeleteWorkspace(reqCtx *gin.Context) {
 	reqCtx.JSON(http.StatusOK, result)
 }
 
+// DeleteAllWorkspaces godoc
+// @Summary Delete All Workspaces
+// @Description Deletes every workspace of the authenticated user and cascades to their conversations, e.g. before closing the account. Workspaces whose conversations cannot be deleted are kept.
+// @Tags conv Workspaces API
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} WorkspacesDeletedResponse
+// @Failure 401 {object} responses.ErrorResponse
+// @Failure 500 {object} responses.ErrorResponse "Some workspaces could not be deleted; the message reports how many were"
+// @Router /v1/conv/workspaces [delete]
+func (route *WorkspaceRoute) DeleteAllWorkspaces(reqCtx *gin.Context) {
+	user, ok := auth.GetUserFromContext(reqCtx)
+	if !ok {
+		reqCtx.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{
+			Code:  "3c7f1a9e-5d2b-4e86-9b04-a8e6d3f2c157",
+			Error: "user not found",
+		})
+		return
+	}
+
+	deleted, err := route.workspaceService.DeleteAllForUser(reqCtx.Request.Context(), user.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.Error(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusOK, WorkspacesDeletedResponse{Deleted: deleted})
+}
+
 func toWorkspaceResponse(entity *workspace.Workspace) WorkspaceResponse {
 	var instruction *string
 	if entity.Instruction != nil {
@@ -356,12 +453,12 @@ type WorkspaceCreateResponse struct {
 }
 
 type WorkspaceListResponse struct {
-	Status  string              `json:"status"`
-	Total   int64               `json:"total"`
-	Results []WorkspaceResponse `json:"results"`
-	FirstID *string             `json:"first_id"`
-	LastID  *string             `json:"last_id"`
-	HasMore bool                `json:"has_more"`
+	Status  string                      `json:"status"`
+	Total   int64                       `json:"total"`
+	Results []WorkspaceListItemResponse `json:"results"`
+	FirstID *string                     `json:"first_id"`
+	LastID  *string                     `json:"last_id"`
+	HasMore bool                        `json:"has_more"`
 }
 
 type WorkspaceDeleteResponse struct {
@@ -2,16 +2,24 @@ package modelroute
 
 import (
 	"net/http"
-	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"menlo.ai/jan-api-gateway/app/domain/auth"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
 	"menlo.ai/jan-api-gateway/app/utils/ptr"
 )
 
+const (
+	// defaultProvidersPageLimit is used when limit is absent or not a number; other values are clamped to [1, maxProvidersPageLimit].
+	defaultProvidersPageLimit = 20
+	maxProvidersPageLimit     = 100
+)
+
 type ProvidersAPI struct {
 	authService      *auth.AuthService
 	projectService   *project.ProjectService
@@ -47,25 +55,86 @@ type providerSummary struct {
 }
 
 type providersListResponse struct {
-	Object string            `json:"object"`
-	Data   []providerSummary `json:"data"`
+	Object  string            `json:"object"`
+	Data    []providerSummary `json:"data"`
+	HasMore bool              `json:"has_more"`
+	LastID  *string           `json:"last_id,omitempty"`
 }
 
+// listProviders returns the accessible providers in registration order, paged by the database.
+// Query params: limit (default 20, clamped to 1..100), after (provider id cursor), vendor, active.
+// A cursor keeps working after its provider was deleted or stopped matching the filters.
 func (api *ProvidersAPI) listProviders(reqCtx *gin.Context) {
-	_, projectPublicIDs, providers, ok := ResolveAccessibleProviders(reqCtx, api.authService, api.projectService, api.providerRegistry)
+	ctx := reqCtx.Request.Context()
+	limit := defaultProvidersPageLimit
+	if value, err := strconv.Atoi(reqCtx.Query("limit")); err == nil {
+		limit = min(max(value, 1), maxProvidersPageLimit)
+	}
+
+	filter := domainmodel.ProviderFilter{}
+	if vendor := strings.TrimSpace(reqCtx.Query("vendor")); vendor != "" {
+		kind := domainmodel.ProviderKind(strings.ToLower(vendor))
+		filter.Kind = &kind
+	}
+	if activeStr := reqCtx.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "3c7e1a9d-5f2b-4d84-b6e0-a8f4c2d9e153",
+				Error: "invalid active value",
+			})
+			return
+		}
+		filter.Active = &active
+	}
+
+	orgID, projectIDs, projectPublicIDs, ok := ResolveMemberProjects(reqCtx, api.projectService)
 	if !ok {
 		return
 	}
+	filter.OrganizationID = &orgID
+	filter.AccessibleProjectIDs = &projectIDs
+
+	// One extra row tells whether another page follows
+	pagination := &query.Pagination{Limit: ptr.ToInt(limit + 1)}
+	if after := reqCtx.Query("after"); after != "" {
+		cursors, err := api.providerRegistry.ListProviders(ctx, domainmodel.ProviderFilter{
+			PublicID:    &after,
+			WithDeleted: ptr.ToBool(true),
+		}, nil)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:  err.GetCode(),
+				Error: err.Error(),
+			})
+			return
+		}
+		if len(cursors) == 0 {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "9e2d6b4a-1c8f-4a73-a5d0-f7b3e9c1d682",
+				Error: "invalid after cursor",
+			})
+			return
+		}
+		pagination.After = &cursors[0].ID
+	}
+
+	providers, listErr := api.providerRegistry.ListProviders(ctx, filter, pagination)
+	if listErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  listErr.GetCode(),
+			Error: listErr.Error(),
+		})
+		return
+	}
 
 	resp := providersListResponse{
 		Object: "list",
-		Data:   make([]providerSummary, 0, len(providers)),
+		Data:   make([]providerSummary, 0, min(len(providers), limit)),
 	}
-
-	scopeOrder := map[string]int{
-		"jan":          0,
-		"organization": 1,
-		"project":      2,
+	if len(providers) > limit {
+		providers = providers[:limit]
+		resp.HasMore = true
 	}
 
 	for _, provider := range providers {
@@ -87,25 +156,14 @@ func (api *ProvidersAPI) listProviders(reqCtx *gin.Context) {
 			Vendor:    strings.ToLower(string(provider.Kind)),
 			BaseURL:   provider.BaseURL,
 			Active:    provider.Active,
-			Metadata:  provider.Metadata,
+			Metadata:  domainmodel.MaskedMetadata(provider.Metadata),
 			Scope:     scope,
 			ProjectID: projectID,
 		})
 	}
-
-	getScopeOrder := func(scope string) int {
-		if order, exists := scopeOrder[scope]; exists {
-			return order
-		}
-		return len(scopeOrder)
+	if len(resp.Data) > 0 {
+		resp.LastID = ptr.ToString(resp.Data[len(resp.Data)-1].ID)
 	}
 
-	sort.Slice(resp.Data, func(i, j int) bool {
-		if resp.Data[i].Scope == resp.Data[j].Scope {
-			return resp.Data[i].Name < resp.Data[j].Name
-		}
-		return getScopeOrder(resp.Data[i].Scope) < getScopeOrder(resp.Data[j].Scope)
-	})
-
 	reqCtx.JSON(http.StatusOK, resp)
 }
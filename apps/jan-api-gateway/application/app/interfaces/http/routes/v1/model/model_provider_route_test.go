@@ -0,0 +1,127 @@
+package modelroute
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/user"
+)
+
+func TestListProvidersPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	otherOrgID := uint(2)
+	memberProjectID := uint(7)
+	otherProjectID := uint(8)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	registry.Providers.Add(
+		&domainmodel.Provider{PublicID: "prov-a", Slug: "a", OrganizationID: &orgID, Active: true},
+		&domainmodel.Provider{PublicID: "prov-b", Slug: "b", OrganizationID: &orgID, ProjectID: &memberProjectID, Active: true},
+		&domainmodel.Provider{PublicID: "prov-hidden-project", Slug: "hidden-project", OrganizationID: &orgID, ProjectID: &otherProjectID, Active: true},
+		&domainmodel.Provider{PublicID: "prov-c", Slug: "c", OrganizationID: &orgID},
+		&domainmodel.Provider{PublicID: "prov-hidden-org", Slug: "hidden-org", OrganizationID: &otherOrgID, Active: true},
+		&domainmodel.Provider{PublicID: "prov-d", Slug: "d", OrganizationID: &orgID, Active: true},
+		&domainmodel.Provider{PublicID: "prov-e", Slug: "e", OrganizationID: &orgID, Active: true},
+	)
+	api := &ProvidersAPI{
+		projectService:   project.NewService(&projectLookup{projects: []*project.Project{{ID: memberProjectID, PublicID: "proj-member"}}}),
+		providerRegistry: registry.ProviderRegistryService,
+	}
+	list := func(query url.Values) (int, providersListResponse) {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		reqCtx, _ := gin.CreateTestContext(recorder)
+		reqCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models/providers?"+query.Encode(), nil)
+		auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+		api.listProviders(reqCtx)
+		var body providersListResponse
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+			}
+		}
+		return recorder.Code, body
+	}
+	ids := func(body providersListResponse) []string {
+		result := make([]string, 0, len(body.Data))
+		for _, item := range body.Data {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	t.Run("walks every accessible provider", func(t *testing.T) {
+		var got []string
+		query := url.Values{"limit": {"2"}}
+		for page := 0; page < 5; page++ {
+			status, body := list(query)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d", status)
+			}
+			got = append(got, ids(body)...)
+			if !body.HasMore {
+				break
+			}
+			query.Set("after", *body.LastID)
+		}
+		want := []string{"prov-a", "prov-b", "prov-c", "prov-d", "prov-e"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("providers = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("project providers carry their project", func(t *testing.T) {
+		_, body := list(url.Values{"limit": {"2"}})
+		if len(body.Data) != 2 || body.Data[1].Scope != "project" || body.Data[1].ProjectID == nil || *body.Data[1].ProjectID != "proj-member" {
+			t.Fatalf("second provider = %+v, want the member project's provider", body.Data[1])
+		}
+	})
+
+	t.Run("cursor filtered out", func(t *testing.T) {
+		status, body := list(url.Values{"active": {"true"}, "after": {"prov-c"}})
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200 after an inactive provider", status)
+		}
+		if got := ids(body); !slices.Equal(got, []string{"prov-d", "prov-e"}) || body.HasMore {
+			t.Fatalf("providers = %v (has_more %t), want the providers after prov-c", got, body.HasMore)
+		}
+	})
+
+	t.Run("cursor deleted", func(t *testing.T) {
+		stored, err := registry.Providers.FindByPublicID(context.Background(), "prov-d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.Providers.DeleteByID(context.Background(), stored.ID); err != nil {
+			t.Fatal(err)
+		}
+		status, body := list(url.Values{"after": {"prov-d"}})
+		if status != http.StatusOK {
+			t.Fatalf("status = %d, want 200 after a deleted provider", status)
+		}
+		if got := ids(body); !slices.Equal(got, []string{"prov-e"}) {
+			t.Fatalf("providers = %v, want the provider after prov-d", got)
+		}
+	})
+
+	t.Run("unknown cursor", func(t *testing.T) {
+		if status, _ := list(url.Values{"after": {"prov-missing"}}); status != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", status)
+		}
+	})
+}
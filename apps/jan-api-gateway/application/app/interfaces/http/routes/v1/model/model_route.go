@@ -1,23 +1,37 @@
 package modelroute
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"menlo.ai/jan-api-gateway/app/domain/auth"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/domain/project"
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/middleware"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
 )
 
+// modelsRequestTimeout bounds the time spent resolving providers and loading models for /v1/models.
+const modelsRequestTimeout = 10 * time.Second
+
+// degradedHeader is set on /v1/models responses served without any provider, e.g. on a fresh install.
+const degradedHeader = "X-Jan-Degraded"
+
 type ModelAPI struct {
 	inferenceProvider    *inference.InferenceProvider
 	authService          *auth.AuthService
 	projectService       *project.ProjectService
 	providerRegistry     *domainmodel.ProviderRegistryService
 	providerModelService *domainmodel.ProviderModelService
+	modelCatalogService  *domainmodel.ModelCatalogService
 }
 
 func NewModelAPI(
@@ -26,6 +40,7 @@ func NewModelAPI(
 	projectService *project.ProjectService,
 	providerRegistry *domainmodel.ProviderRegistryService,
 	providerModelService *domainmodel.ProviderModelService,
+	modelCatalogService *domainmodel.ModelCatalogService,
 ) *ModelAPI {
 	return &ModelAPI{
 		inferenceProvider:    inferenceProvider,
@@ -33,6 +48,7 @@ func NewModelAPI(
 		projectService:       projectService,
 		providerRegistry:     providerRegistry,
 		providerModelService: providerModelService,
+		modelCatalogService:  modelCatalogService,
 	}
 }
 
@@ -40,46 +56,102 @@ func (modelAPI *ModelAPI) RegisterRouter(router *gin.RouterGroup) {
 	group := router.Group("",
 		modelAPI.authService.AppUserAuthMiddleware(),
 		modelAPI.authService.RegisteredUserMiddleware(),
+		middleware.Compress(),
 	)
 	group.GET("models", modelAPI.GetModels)
+	group.GET("models/:model_id", modelAPI.GetModel)
+	// Model IDs such as openai/gpt-4o contain slashes.
+	group.GET("models/:model_id/*rest", modelAPI.GetModel)
 }
 
 // ListModels
 // @Summary List available models
 // @Description Retrieves a list of available models that can be used for chat completions or other tasks.
+// @Description Capability and family filters combine with AND: only models matching every given filter are returned.
+// @Description With group_by=family the data is a ModelFamiliesResponse: families sorted alphabetically, each with its models sorted by ID.
 // @Tags Chat Completions API
 // @Security BearerAuth
 // @Accept json
 // @Produce json
-// @Success 200 {object} ModelsResponse "Successful response"
+// @Param supports_images query bool false "Only models that do (true) or do not (false) accept image input"
+// @Param supports_reasoning query bool false "Only models that do (true) or do not (false) support reasoning"
+// @Param supports_embeddings query bool false "Only models that do (true) or do not (false) produce embeddings"
+// @Param family query string false "Only models of this family, case-insensitive"
+// @Param group_by query string false "Set to family to group the models by family; X-PROVIDER-DATA is ignored in this mode" Enums(family)
+// @Success 200 {object} ModelsResponse "Successful response; an empty list with the X-Jan-Degraded header when no provider is configured"
+// @Failure 400 {object} responses.ErrorResponse "Invalid filter or group_by value"
+// @Failure 504 {object} responses.ErrorResponse "Timed out while loading models"
 // @Router /v1/models [get]
 func (modelAPI *ModelAPI) GetModels(reqCtx *gin.Context) {
-	ctx := reqCtx.Request.Context()
+	ctx, cancel := context.WithTimeout(reqCtx.Request.Context(), modelsRequestTimeout)
+	defer cancel()
+	reqCtx.Request = reqCtx.Request.WithContext(ctx)
 	includeProviderData := strings.EqualFold(reqCtx.GetHeader("X-PROVIDER-DATA"), "true")
+	capabilityFilter, ok := parseModelCapabilityFilter(reqCtx)
+	if !ok {
+		return
+	}
+	groupByFamily, ok := parseModelsGroupBy(reqCtx)
+	if !ok {
+		return
+	}
 
 	_, _, providers, ok := ResolveAccessibleProviders(reqCtx, modelAPI.authService, modelAPI.projectService, modelAPI.providerRegistry)
 	if !ok {
 		return
 	}
 
-	providerByID := make(map[uint]*domainmodel.Provider, len(providers))
-	providerIDs := make([]uint, 0, len(providers))
-	for _, provider := range providers {
-		if provider == nil {
-			continue
+	providerByID, providerIDs := listedProviders(providers)
+
+	if len(providerIDs) == 0 {
+		reqCtx.Header(degradedHeader, "no-providers")
+		if groupByFamily {
+			reqCtx.JSON(http.StatusOK, ModelFamiliesResponse{
+				Object: "list",
+				Data:   []ModelFamily{},
+			})
+			return
 		}
-		providerByID[provider.ID] = provider
-		providerIDs = append(providerIDs, provider.ID)
+		if includeProviderData {
+			reqCtx.JSON(http.StatusOK, ModelsWithProviderResponse{
+				Object: "list",
+				Data:   []ModelWithProvider{},
+			})
+			return
+		}
+		reqCtx.JSON(http.StatusOK, ModelsResponse{
+			Object: "list",
+			Data:   []Model{},
+		})
+		return
 	}
 
 	providerModels, err := modelAPI.providerModelService.ListActiveByProviderIDs(ctx, providerIDs)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			reqCtx.AbortWithStatusJSON(http.StatusGatewayTimeout, responses.ErrorResponse{
+				Code:  "3a7c1e52-8f04-4d9b-b6e3-0c5d2f8a1b94",
+				Error: "timed out while loading models",
+			})
+			return
+		}
 		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
 			Code:          "f7f0f635-3f13-4c6f-b436-a78a5ccaa1af",
 			ErrorInstance: err,
 		})
 		return
 	}
+	providerModels = capabilityFilter.apply(listedProviderModels(providerModels, providerByID))
+
+	if groupByFamily {
+		models := MergeModels(providerModels, providerByID)
+		modelAPI.markDeprecated(ctx, models, providerModels)
+		reqCtx.JSON(http.StatusOK, ModelFamiliesResponse{
+			Object: "list",
+			Data:   GroupModelsByFamily(models, providerModels),
+		})
+		return
+	}
 
 	if includeProviderData {
 		models := BuildModelsWithProvider(providerModels, providerByID)
@@ -91,8 +163,313 @@ func (modelAPI *ModelAPI) GetModels(reqCtx *gin.Context) {
 	}
 
 	result := MergeModels(providerModels, providerByID)
+	modelAPI.markDeprecated(ctx, result, providerModels)
 	reqCtx.JSON(http.StatusOK, ModelsResponse{
 		Object: "list",
 		Data:   result,
 	})
 }
+
+// listedProviders indexes the accessible providers whose models are listed, applying the same
+// rule as routing so /v1/models never offers a model no request can reach. Shadow providers
+// only receive replayed traffic, so their models are not listed either.
+func listedProviders(providers []*domainmodel.Provider) (map[uint]*domainmodel.Provider, []uint) {
+	providerByID := make(map[uint]*domainmodel.Provider, len(providers))
+	providerIDs := make([]uint, 0, len(providers))
+	for _, provider := range providers {
+		if !provider.ServesModels() || provider.Shadow {
+			continue
+		}
+		providerByID[provider.ID] = provider
+		providerIDs = append(providerIDs, provider.ID)
+	}
+	return providerByID, providerIDs
+}
+
+// listedProviderModels drops the models the provider's allowlist or denylist excludes. Routing
+// applies list edits right away, while the stored models only catch up at the next sync.
+func listedProviderModels(providerModels []*domainmodel.ProviderModel, providerByID map[uint]*domainmodel.Provider) []*domainmodel.ProviderModel {
+	listed := make([]*domainmodel.ProviderModel, 0, len(providerModels))
+	for _, pm := range providerModels {
+		if pm == nil {
+			continue
+		}
+		if provider, ok := providerByID[pm.ProviderID]; ok && provider.AllowsModel(pm.ModelKey) {
+			listed = append(listed, pm)
+		}
+	}
+	return listed
+}
+
+// markDeprecated sets the deprecation of the models whose catalog entry is deprecated. The
+// deprecation is informational, so a failed lookup is logged and the models are listed without it.
+func (modelAPI *ModelAPI) markDeprecated(ctx context.Context, models []Model, providerModels []*domainmodel.ProviderModel) {
+	catalogIDs := make([]uint, 0, len(providerModels))
+	for _, pm := range providerModels {
+		if pm != nil && pm.ModelCatalogID != nil {
+			catalogIDs = append(catalogIDs, *pm.ModelCatalogID)
+		}
+	}
+	deprecated, err := modelAPI.modelCatalogService.FindDeprecatedByIDs(ctx, catalogIDs)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to load model deprecations: %v", err)
+		return
+	}
+	if len(deprecated) == 0 {
+		return
+	}
+
+	catalogByModel := make(map[string]*domainmodel.ModelCatalog, len(deprecated))
+	for _, pm := range providerModels {
+		if pm == nil || pm.ModelCatalogID == nil {
+			continue
+		}
+		if catalog, ok := deprecated[*pm.ModelCatalogID]; ok {
+			catalogByModel[pm.ModelKey] = catalog
+		}
+	}
+	for i := range models {
+		if catalog, ok := catalogByModel[models[i].ID]; ok {
+			models[i].DeprecatedAt = catalog.DeprecatedAt
+			models[i].DeprecationReason = catalog.DeprecationReason
+		}
+	}
+}
+
+// ModelDetail is a single model merged across the accessible providers serving it. Pricing,
+// token limits and capabilities come from the highest-priority provider, catalog details from
+// that provider model's catalog entry.
+type ModelDetail struct {
+	ID                  string                           `json:"id"`
+	Object              string                           `json:"object"`
+	Created             int                              `json:"created"`
+	OwnedBy             string                           `json:"owned_by"`
+	Family              *string                          `json:"family,omitempty"`
+	Architecture        *domainmodel.Architecture        `json:"architecture,omitempty"`
+	SupportedParameters *domainmodel.SupportedParameters `json:"supported_parameters,omitempty"`
+	Pricing             domainmodel.Pricing              `json:"pricing"`
+	TokenLimits         *domainmodel.TokenLimits         `json:"token_limits,omitempty"`
+	SupportsImages      bool                             `json:"supports_images"`
+	SupportsReasoning   bool                             `json:"supports_reasoning"`
+	SupportsEmbeddings  bool                             `json:"supports_embeddings"`
+	DeprecatedAt        *time.Time                       `json:"deprecated_at,omitempty"`
+	DeprecationReason   *string                          `json:"deprecation_reason,omitempty"`
+	Providers           []ModelDetailProvider            `json:"providers"`
+}
+
+type ModelDetailProvider struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Vendor string `json:"vendor"`
+	Scope  string `json:"scope"`
+}
+
+// GetModel
+// @Summary Retrieve a model
+// @Description Retrieves a single model by ID, with its catalog details and the accessible providers serving it.
+// @Tags Chat Completions API
+// @Security BearerAuth
+// @Produce json
+// @Param model_id path string true "Model ID; IDs containing slashes may be passed as-is"
+// @Success 200 {object} ModelDetail "Successful response"
+// @Failure 404 {object} responses.ErrorResponse "No accessible provider serves the model"
+// @Failure 504 {object} responses.ErrorResponse "Timed out while loading the model"
+// @Router /v1/models/{model_id} [get]
+func (modelAPI *ModelAPI) GetModel(reqCtx *gin.Context) {
+	ctx, cancel := context.WithTimeout(reqCtx.Request.Context(), modelsRequestTimeout)
+	defer cancel()
+	reqCtx.Request = reqCtx.Request.WithContext(ctx)
+	modelID := strings.TrimSpace(reqCtx.Param("model_id") + strings.TrimSuffix(reqCtx.Param("rest"), "/"))
+
+	_, _, providers, ok := ResolveAccessibleProviders(reqCtx, modelAPI.authService, modelAPI.projectService, modelAPI.providerRegistry)
+	if !ok {
+		return
+	}
+	providerByID, providerIDs := listedProviders(providers)
+
+	var providerModels []*domainmodel.ProviderModel
+	if modelID != "" && len(providerIDs) > 0 {
+		var err error
+		providerModels, err = modelAPI.providerModelService.FindActiveByProviderIDsAndKey(ctx, providerIDs, modelID)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				reqCtx.AbortWithStatusJSON(http.StatusGatewayTimeout, responses.ErrorResponse{
+					Code:  "5d1f8a3c-9e27-4b64-a0c5-e8b2d7f4a196",
+					Error: "timed out while loading the model",
+				})
+				return
+			}
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:          "b8e3c6a1-4f9d-4d27-95b0-2a7e1c8f3d64",
+				ErrorInstance: err,
+			})
+			return
+		}
+	}
+
+	detail, primary := buildModelDetail(modelID, listedProviderModels(providerModels, providerByID), providerByID)
+	if primary == nil {
+		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+			Code:  "e2c9a4f7-1b6d-4e83-8a50-c7f3d1b9e642",
+			Error: "model not found",
+		})
+		return
+	}
+
+	if primary.ModelCatalogID != nil {
+		catalog, err := modelAPI.modelCatalogService.FindByID(ctx, *primary.ModelCatalogID)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+				Code:          "7a4d2e9b-6c1f-4b58-b3e0-f9a6c2d8e157",
+				ErrorInstance: err,
+			})
+			return
+		}
+		if catalog != nil {
+			detail.Architecture = &catalog.Architecture
+			detail.SupportedParameters = &catalog.SupportedParameters
+			detail.DeprecatedAt = catalog.DeprecatedAt
+			detail.DeprecationReason = catalog.DeprecationReason
+		}
+	}
+
+	reqCtx.JSON(http.StatusOK, detail)
+}
+
+// buildModelDetail merges the provider models serving modelID, following MergeModels' priority
+// of project over organization providers. It returns the provider model the details come from,
+// or nil when no accessible provider serves the model.
+func buildModelDetail(
+	modelID string,
+	providerModels []*domainmodel.ProviderModel,
+	providerByID map[uint]*domainmodel.Provider,
+) (ModelDetail, *domainmodel.ProviderModel) {
+	detail := ModelDetail{
+		ID:        modelID,
+		Object:    "model",
+		Providers: []ModelDetailProvider{},
+	}
+	var primary *domainmodel.ProviderModel
+	primaryPriority := 0
+	for _, pm := range providerModels {
+		if pm == nil {
+			continue
+		}
+		provider := providerByID[pm.ProviderID]
+		if provider == nil {
+			continue
+		}
+		detail.Providers = append(detail.Providers, ModelDetailProvider{
+			ID:     provider.PublicID,
+			Name:   provider.DisplayName,
+			Vendor: strings.ToLower(string(provider.Kind)),
+			Scope:  providerScope(provider),
+		})
+		if p := providerPriority(provider); primary == nil || p > primaryPriority {
+			primary = pm
+			primaryPriority = p
+			detail.OwnedBy = provider.DisplayName
+		}
+	}
+	if primary == nil {
+		return detail, nil
+	}
+
+	sort.SliceStable(detail.Providers, func(i, j int) bool {
+		return providerTypePriority(detail.Providers[i].Scope) > providerTypePriority(detail.Providers[j].Scope)
+	})
+	created := primary.UpdatedAt.Unix()
+	if created == 0 {
+		created = time.Now().Unix()
+	}
+	detail.Created = int(created)
+	detail.Family = primary.Family
+	detail.Pricing = primary.Pricing
+	detail.TokenLimits = primary.TokenLimits
+	detail.SupportsImages = primary.SupportsImages
+	detail.SupportsReasoning = primary.SupportsReasoning
+	detail.SupportsEmbeddings = primary.SupportsEmbeddings
+	return detail, primary
+}
+
+// modelCapabilityFilter narrows /v1/models to the models matching every condition that is set.
+type modelCapabilityFilter struct {
+	supportsImages     *bool
+	supportsReasoning  *bool
+	supportsEmbeddings *bool
+	family             string
+}
+
+func parseModelCapabilityFilter(reqCtx *gin.Context) (modelCapabilityFilter, bool) {
+	var filter modelCapabilityFilter
+	flags := []struct {
+		param  string
+		target **bool
+	}{
+		{"supports_images", &filter.supportsImages},
+		{"supports_reasoning", &filter.supportsReasoning},
+		{"supports_embeddings", &filter.supportsEmbeddings},
+	}
+	for _, flag := range flags {
+		raw := strings.TrimSpace(reqCtx.Query(flag.param))
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "6e2a9c4f-8b1d-4f37-a5e0-d3c7b9f1e825",
+				Error: "invalid " + flag.param + " value",
+			})
+			return filter, false
+		}
+		*flag.target = &value
+	}
+	filter.family = strings.TrimSpace(reqCtx.Query("family"))
+	return filter, true
+}
+
+// parseModelsGroupBy reports whether /v1/models should group its models by family.
+func parseModelsGroupBy(reqCtx *gin.Context) (bool, bool) {
+	switch groupBy := strings.TrimSpace(reqCtx.Query("group_by")); {
+	case groupBy == "":
+		return false, true
+	case strings.EqualFold(groupBy, "family"):
+		return true, true
+	default:
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "b5d1e8a3-4c7f-4a92-9e06-f2c8a4d7b139",
+			Error: "invalid group_by value",
+		})
+		return false, false
+	}
+}
+
+func (f modelCapabilityFilter) matches(pm *domainmodel.ProviderModel) bool {
+	if f.supportsImages != nil && pm.SupportsImages != *f.supportsImages {
+		return false
+	}
+	if f.supportsReasoning != nil && pm.SupportsReasoning != *f.supportsReasoning {
+		return false
+	}
+	if f.supportsEmbeddings != nil && pm.SupportsEmbeddings != *f.supportsEmbeddings {
+		return false
+	}
+	if f.family != "" && (pm.Family == nil || !strings.EqualFold(*pm.Family, f.family)) {
+		return false
+	}
+	return true
+}
+
+func (f modelCapabilityFilter) apply(providerModels []*domainmodel.ProviderModel) []*domainmodel.ProviderModel {
+	if f.supportsImages == nil && f.supportsReasoning == nil && f.supportsEmbeddings == nil && f.family == "" {
+		return providerModels
+	}
+	filtered := make([]*domainmodel.ProviderModel, 0, len(providerModels))
+	for _, pm := range providerModels {
+		if pm != nil && f.matches(pm) {
+			filtered = append(filtered, pm)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,347 @@
+package modelroute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/query"
+	"menlo.ai/jan-api-gateway/app/domain/user"
+)
+
+// projectLookup answers project lookups with projects and err, or waits for the request to give
+// up when slow is set.
+type projectLookup struct {
+	project.ProjectRepository
+	projects []*project.Project
+	slow     bool
+	err      error
+}
+
+func (r *projectLookup) FindByFilter(ctx context.Context, filter project.ProjectFilter, p *query.Pagination) ([]*project.Project, error) {
+	if r.slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return r.projects, r.err
+}
+
+func TestGetModelsTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(provider)
+	registry.Models.Add(&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: "gpt", Active: true})
+
+	tests := []struct {
+		name    string
+		lookup  *projectLookup
+		expired bool
+		status  int
+	}{
+		{name: "models are listed", lookup: &projectLookup{}, status: http.StatusOK},
+		{name: "slow project lookup", lookup: &projectLookup{slow: true}, expired: true, status: http.StatusGatewayTimeout},
+		{name: "failed project lookup", lookup: &projectLookup{err: errors.New("connection reset")}, status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelAPI := &ModelAPI{
+				projectService:       project.NewService(tt.lookup),
+				providerRegistry:     registry.ProviderRegistryService,
+				providerModelService: registry.ProviderModelService,
+				modelCatalogService:  registry.ModelCatalogService,
+			}
+			ctx := context.Background()
+			if tt.expired {
+				// The handler's own deadline is derived from the request, so an expired request stands
+				// in for a lookup that outlasts it.
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, time.Now().Add(-time.Second))
+				defer cancel()
+			}
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(ctx)
+			auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+
+			modelAPI.GetModels(reqCtx)
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.status, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetModelsWithoutProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: 1}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	modelAPI := &ModelAPI{
+		projectService:       project.NewService(&projectLookup{}),
+		providerRegistry:     registry.ProviderRegistryService,
+		providerModelService: registry.ProviderModelService,
+		modelCatalogService:  registry.ModelCatalogService,
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+	}{
+		{name: "models", target: "/v1/models"},
+		{name: "models with provider data", target: "/v1/models", header: "true"},
+		{name: "models grouped by family", target: "/v1/models?group_by=family"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				reqCtx.Request.Header.Set("X-PROVIDER-DATA", tt.header)
+			}
+			auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+
+			modelAPI.GetModels(reqCtx)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", recorder.Code, recorder.Body.String())
+			}
+			if got := recorder.Header().Get(degradedHeader); got != "no-providers" {
+				t.Fatalf("%s = %q, want no-providers", degradedHeader, got)
+			}
+			var body struct {
+				Object string            `json:"object"`
+				Data   []json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+			}
+			if body.Object != "list" || body.Data == nil || len(body.Data) != 0 {
+				t.Fatalf("body = %s, want an empty list", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetModelsCapabilityFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	provider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true}
+	registry.Providers.Add(provider)
+	gpt, llama := "gpt-4o", "llama-3.1"
+	for _, pm := range []*domainmodel.ProviderModel{
+		{ModelKey: "gpt-4o", Family: &gpt, SupportsImages: true},
+		{ModelKey: "gpt-4o-mini", Family: &gpt, SupportsImages: true, SupportsReasoning: true},
+		{ModelKey: "llama-3.1-8b", Family: &llama},
+		{ModelKey: "llama-3.1-70b", Family: &llama, SupportsReasoning: true},
+		{ModelKey: "text-embedding-3-small", SupportsEmbeddings: true},
+	} {
+		pm.ProviderID = provider.ID
+		pm.Active = true
+		registry.Models.Add(pm)
+	}
+	modelAPI := &ModelAPI{
+		projectService:       project.NewService(&projectLookup{}),
+		providerRegistry:     registry.ProviderRegistryService,
+		providerModelService: registry.ProviderModelService,
+		modelCatalogService:  registry.ModelCatalogService,
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		want   []string
+	}{
+		{name: "no filter", query: "", status: http.StatusOK, want: []string{"gpt-4o", "gpt-4o-mini", "llama-3.1-70b", "llama-3.1-8b", "text-embedding-3-small"}},
+		{name: "images only", query: "supports_images=true", status: http.StatusOK, want: []string{"gpt-4o", "gpt-4o-mini"}},
+		{name: "without images", query: "supports_images=false", status: http.StatusOK, want: []string{"llama-3.1-70b", "llama-3.1-8b", "text-embedding-3-small"}},
+		{name: "images and reasoning", query: "supports_images=true&supports_reasoning=true", status: http.StatusOK, want: []string{"gpt-4o-mini"}},
+		{name: "family ignores case", query: "family=LLAMA-3.1", status: http.StatusOK, want: []string{"llama-3.1-70b", "llama-3.1-8b"}},
+		{name: "family and images", query: "family=llama-3.1&supports_images=true", status: http.StatusOK, want: []string{}},
+		{name: "embeddings", query: "supports_embeddings=1", status: http.StatusOK, want: []string{"text-embedding-3-small"}},
+		{name: "invalid flag", query: "supports_images=maybe", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models?"+tt.query, nil)
+			auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+
+			modelAPI.GetModels(reqCtx)
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.status, recorder.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var body ModelsResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+			}
+			got := make([]string, 0, len(body.Data))
+			for _, model := range body.Data {
+				got = append(got, model.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("models = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelListingsSkipDeactivatedAndShadowProviders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	registry := modeltest.NewRegistry()
+	active := &domainmodel.Provider{PublicID: "prov-active", Slug: "active", OrganizationID: &orgID, Active: true}
+	// The models of a deactivated provider stay marked active until the next sync.
+	inactive := &domainmodel.Provider{PublicID: "prov-inactive", Slug: "inactive", OrganizationID: &orgID}
+	shadow := &domainmodel.Provider{PublicID: "prov-shadow", Slug: "shadow", OrganizationID: &orgID, Active: true, Shadow: true}
+	registry.Providers.Add(active, inactive, shadow)
+	registry.Models.Add(
+		&domainmodel.ProviderModel{ProviderID: active.ID, ModelKey: "gpt-4o", Active: true},
+		&domainmodel.ProviderModel{ProviderID: inactive.ID, ModelKey: "gpt-4o", Active: true},
+		&domainmodel.ProviderModel{ProviderID: inactive.ID, ModelKey: "claude-3", Active: true},
+		&domainmodel.ProviderModel{ProviderID: shadow.ID, ModelKey: "gpt-4o", Active: true},
+		&domainmodel.ProviderModel{ProviderID: shadow.ID, ModelKey: "o1", Active: true},
+	)
+	modelAPI := &ModelAPI{
+		projectService:       project.NewService(&projectLookup{}),
+		providerRegistry:     registry.ProviderRegistryService,
+		providerModelService: registry.ProviderModelService,
+		modelCatalogService:  registry.ModelCatalogService,
+	}
+	serve := func(target string, handler gin.HandlerFunc, params ...gin.Param) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		reqCtx, _ := gin.CreateTestContext(recorder)
+		reqCtx.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		reqCtx.Params = params
+		auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+		handler(reqCtx)
+		return recorder
+	}
+
+	recorder := serve("/v1/models", modelAPI.GetModels)
+	var list ModelsResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != "gpt-4o" {
+		t.Fatalf("models = %s, want only gpt-4o", recorder.Body.String())
+	}
+
+	recorder = serve("/v1/models/gpt-4o", modelAPI.GetModel, gin.Param{Key: "model_id", Value: "gpt-4o"})
+	var detail ModelDetail
+	if err := json.Unmarshal(recorder.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+	}
+	if len(detail.Providers) != 1 || detail.Providers[0].ID != active.PublicID {
+		t.Fatalf("providers of gpt-4o = %+v, want only the active provider", detail.Providers)
+	}
+
+	if recorder = serve("/v1/models/claude-3", modelAPI.GetModel, gin.Param{Key: "model_id", Value: "claude-3"}); recorder.Code != http.StatusNotFound {
+		t.Fatalf("status for a model only a deactivated provider serves = %d, want 404", recorder.Code)
+	}
+	if recorder = serve("/v1/models/o1", modelAPI.GetModel, gin.Param{Key: "model_id", Value: "o1"}); recorder.Code != http.StatusNotFound {
+		t.Fatalf("status for a model only a shadow provider serves = %d, want 404", recorder.Code)
+	}
+}
+
+func TestModelListingsApplyModelLists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(1)
+	previousDefault := organization.DEFAULT_ORGANIZATION
+	organization.DEFAULT_ORGANIZATION = &organization.Organization{ID: orgID}
+	defer func() { organization.DEFAULT_ORGANIZATION = previousDefault }()
+
+	tests := []struct {
+		name      string
+		allowlist []string
+		denylist  []string
+		want      []string
+	}{
+		{name: "no lists", want: []string{"gpt-4o", "gpt-4o-mini", "o1"}},
+		{name: "allowlist", allowlist: []string{"gpt-4o*"}, want: []string{"gpt-4o", "gpt-4o-mini"}},
+		{name: "denylist", denylist: []string{"*-mini"}, want: []string{"gpt-4o", "o1"}},
+		{name: "denylist wins over allowlist", allowlist: []string{"gpt-4o*"}, denylist: []string{"gpt-4o-mini"}, want: []string{"gpt-4o"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := modeltest.NewRegistry()
+			// The lists were edited after the last sync, so every stored model is still active.
+			provider := &domainmodel.Provider{PublicID: "prov-org", Slug: "org", OrganizationID: &orgID, Active: true, ModelAllowlist: tt.allowlist, ModelDenylist: tt.denylist}
+			registry.Providers.Add(provider)
+			for _, modelKey := range []string{"gpt-4o", "gpt-4o-mini", "o1"} {
+				registry.Models.Add(&domainmodel.ProviderModel{ProviderID: provider.ID, ModelKey: modelKey, Active: true})
+			}
+			modelAPI := &ModelAPI{
+				projectService:       project.NewService(&projectLookup{}),
+				providerRegistry:     registry.ProviderRegistryService,
+				providerModelService: registry.ProviderModelService,
+				modelCatalogService:  registry.ModelCatalogService,
+			}
+
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+			auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+			modelAPI.GetModels(reqCtx)
+
+			var body ModelsResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid body %s: %v", recorder.Body.String(), err)
+			}
+			got := make([]string, 0, len(body.Data))
+			for _, model := range body.Data {
+				got = append(got, model.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("models = %v, want %v", got, tt.want)
+			}
+
+			for _, modelKey := range []string{"gpt-4o", "gpt-4o-mini", "o1"} {
+				recorder := httptest.NewRecorder()
+				reqCtx, _ := gin.CreateTestContext(recorder)
+				reqCtx.Request = httptest.NewRequest(http.MethodGet, "/v1/models/"+modelKey, nil)
+				reqCtx.Params = gin.Params{{Key: "model_id", Value: modelKey}}
+				auth.SetUserToContext(reqCtx, &user.User{ID: 3})
+				modelAPI.GetModel(reqCtx)
+				if found := recorder.Code == http.StatusOK; found != slices.Contains(tt.want, modelKey) {
+					t.Fatalf("GET /v1/models/%s status = %d, want listed %t", modelKey, recorder.Code, slices.Contains(tt.want, modelKey))
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package modelroute
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"sort"
 	"strings"
@@ -21,7 +23,43 @@ func ResolveAccessibleProviders(
 	projectService *project.ProjectService,
 	providerRegistry *domainmodel.ProviderRegistryService,
 ) (uint, map[uint]string, []*domainmodel.Provider, bool) {
-	ctx := reqCtx.Request.Context()
+	return ResolveAccessibleProvidersByFilter(reqCtx, authService, projectService, providerRegistry, domainmodel.ProviderFilter{})
+}
+
+func ResolveAccessibleProvidersByFilter(
+	reqCtx *gin.Context,
+	authService *auth.AuthService,
+	projectService *project.ProjectService,
+	providerRegistry *domainmodel.ProviderRegistryService,
+	filter domainmodel.ProviderFilter,
+) (uint, map[uint]string, []*domainmodel.Provider, bool) {
+	orgID, projectIDs, projectPublicIDs, ok := ResolveMemberProjects(reqCtx, projectService)
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	providers, err := providerRegistry.ListAccessibleProvidersByFilter(reqCtx.Request.Context(), orgID, projectIDs, filter)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			reqCtx.AbortWithStatusJSON(http.StatusGatewayTimeout, responses.ErrorResponse{
+				Code:  "e41b9d07-2c6a-4f38-a5d1-7b8e3c0f6a29",
+				Error: "timed out while resolving providers",
+			})
+			return 0, nil, nil, false
+		}
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "7c88a4d8-d244-4f0d-8199-9851bc9f2df7",
+			ErrorInstance: err,
+		})
+		return 0, nil, nil, false
+	}
+
+	return orgID, projectPublicIDs, providers, true
+}
+
+// ResolveMemberProjects returns the default organization and the projects of it the caller is a
+// member of, as IDs and as a map from ID to public ID.
+func ResolveMemberProjects(reqCtx *gin.Context, projectService *project.ProjectService) (uint, []uint, map[uint]string, bool) {
 	user, ok := auth.GetUserFromContext(reqCtx)
 	if !ok || user == nil {
 		reqCtx.AbortWithStatusJSON(http.StatusUnauthorized, responses.ErrorResponse{
@@ -34,11 +72,18 @@ func ResolveAccessibleProviders(
 	orgID := organization.DEFAULT_ORGANIZATION.ID
 	orgIDPtr := ptr.ToUint(orgID)
 	memberID := user.ID
-	projects, err := projectService.Find(ctx, project.ProjectFilter{
+	projects, err := projectService.Find(reqCtx.Request.Context(), project.ProjectFilter{
 		OrganizationID: orgIDPtr,
 		MemberID:       &memberID,
 	}, nil)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			reqCtx.AbortWithStatusJSON(http.StatusGatewayTimeout, responses.ErrorResponse{
+				Code:  "8b2e6f41-d7a3-4c95-9e08-1f5c3a7d2b64",
+				Error: "timed out while resolving projects",
+			})
+			return 0, nil, nil, false
+		}
 		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
 			Code:          "d22f5fb5-7d09-4f61-8180-803f21722200",
 			ErrorInstance: err,
@@ -52,17 +97,7 @@ func ResolveAccessibleProviders(
 		projectIDs = append(projectIDs, proj.ID)
 		projectPublicIDs[proj.ID] = proj.PublicID
 	}
-
-	providers, err := providerRegistry.ListAccessibleProviders(ctx, orgID, projectIDs)
-	if err != nil {
-		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
-			Code:          "7c88a4d8-d244-4f0d-8199-9851bc9f2df7",
-			ErrorInstance: err,
-		})
-		return 0, nil, nil, false
-	}
-
-	return orgID, projectPublicIDs, providers, true
+	return orgID, projectIDs, projectPublicIDs, true
 }
 
 type Model struct {
@@ -70,6 +105,9 @@ type Model struct {
 	Object  string `json:"object"`
 	Created int    `json:"created"`
 	OwnedBy string `json:"owned_by"`
+	// Set when the provider announced a deprecation of the model.
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty"`
+	DeprecationReason *string    `json:"deprecation_reason,omitempty"`
 }
 
 type ModelsResponse struct {
@@ -77,6 +115,19 @@ type ModelsResponse struct {
 	Data   []Model `json:"data"`
 }
 
+// otherModelFamily groups the models whose ID carries no family prefix.
+const otherModelFamily = "other"
+
+type ModelFamily struct {
+	Family string  `json:"family"`
+	Models []Model `json:"models"`
+}
+
+type ModelFamiliesResponse struct {
+	Object string        `json:"object"`
+	Data   []ModelFamily `json:"data"`
+}
+
 type ModelWithProvider struct {
 	ID             string `json:"id"`
 	Object         string `json:"object"`
@@ -178,6 +229,46 @@ func MergeModels(
 	return list
 }
 
+// GroupModelsByFamily groups merged models by the family of their provider models. Families are
+// sorted alphabetically and models within a family by ID.
+func GroupModelsByFamily(models []Model, providerModels []*domainmodel.ProviderModel) []ModelFamily {
+	familyByID := make(map[string]string, len(providerModels))
+	for _, pm := range providerModels {
+		if pm == nil || pm.Family == nil || strings.TrimSpace(*pm.Family) == "" {
+			continue
+		}
+		if _, ok := familyByID[pm.ModelKey]; !ok {
+			familyByID[pm.ModelKey] = strings.TrimSpace(*pm.Family)
+		}
+	}
+
+	index := map[string]int{}
+	groups := make([]ModelFamily, 0)
+	for _, model := range models {
+		family, ok := familyByID[model.ID]
+		if !ok {
+			family = otherModelFamily
+		}
+		i, ok := index[family]
+		if !ok {
+			i = len(groups)
+			index[family] = i
+			groups = append(groups, ModelFamily{Family: family})
+		}
+		groups[i].Models = append(groups[i].Models, model)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Family < groups[j].Family
+	})
+	for _, group := range groups {
+		sort.Slice(group.Models, func(i, j int) bool {
+			return group.Models[i].ID < group.Models[j].ID
+		})
+	}
+	return groups
+}
+
 func providerScope(provider *domainmodel.Provider) string {
 	if provider.ProjectID != nil {
 		return "project"
@@ -0,0 +1,278 @@
+package organization
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+type createModelAliasRequest struct {
+	Alias      string `json:"alias" binding:"required"`
+	ProviderID string `json:"provider_id" binding:"required"`
+	ModelKey   string `json:"model_key" binding:"required"`
+}
+
+type updateModelAliasRequest struct {
+	Alias      *string `json:"alias"`
+	ProviderID *string `json:"provider_id"`
+	ModelKey   *string `json:"model_key"`
+}
+
+type modelAliasResponse struct {
+	ID         string    `json:"id"`
+	Alias      string    `json:"alias"`
+	ProviderID string    `json:"provider_id"`
+	ModelKey   string    `json:"model_key"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type modelAliasListResponse struct {
+	Object string               `json:"object"`
+	Data   []modelAliasResponse `json:"data"`
+}
+
+func (route *ModelProviderRoute) listModelAliases(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	aliases, err := route.modelAliasService.ListByOrganization(ctx, orgEntity.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	providerIDs, ok := route.accessibleProviderPublicIDs(reqCtx, orgEntity.ID)
+	if !ok {
+		return
+	}
+
+	data := make([]modelAliasResponse, 0, len(aliases))
+	for _, alias := range aliases {
+		data = append(data, toModelAliasResponse(alias, providerIDs[alias.ProviderID]))
+	}
+	reqCtx.JSON(http.StatusOK, modelAliasListResponse{
+		Object: "list",
+		Data:   data,
+	})
+}
+
+func (route *ModelProviderRoute) createModelAlias(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	var request createModelAliasRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "2b6e9d4a-7c1f-4e38-a5d0-f3c8b1e7a926",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	provider, ok := route.findAliasTarget(reqCtx, orgEntity.ID, request.ProviderID, request.ModelKey)
+	if !ok {
+		return
+	}
+
+	alias, err := route.modelAliasService.CreateAlias(ctx, orgEntity.ID, request.Alias, provider, request.ModelKey)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(modelAliasErrorStatus(err.GetCode()), responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusCreated, toModelAliasResponse(alias, provider.PublicID))
+}
+
+func (route *ModelProviderRoute) updateModelAlias(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	alias, ok := route.findModelAlias(reqCtx, orgEntity.ID)
+	if !ok {
+		return
+	}
+
+	var request updateModelAliasRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "8f3a1c7e-4d9b-4b62-9e05-c6d2a8f1b473",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	// Repointing is validated against the resulting (provider, model_key) pair, so changing
+	// only one of them still has to land on a model the provider serves.
+	var provider *domainmodel.Provider
+	if request.ProviderID != nil || request.ModelKey != nil {
+		providerPublicID := ""
+		if request.ProviderID != nil {
+			providerPublicID = *request.ProviderID
+		} else {
+			providerIDs, ok := route.accessibleProviderPublicIDs(reqCtx, orgEntity.ID)
+			if !ok {
+				return
+			}
+			providerPublicID = providerIDs[alias.ProviderID]
+		}
+		modelKey := alias.ModelKey
+		if request.ModelKey != nil {
+			modelKey = *request.ModelKey
+		}
+		provider, ok = route.findAliasTarget(reqCtx, orgEntity.ID, providerPublicID, modelKey)
+		if !ok {
+			return
+		}
+	}
+
+	updated, err := route.modelAliasService.UpdateAlias(ctx, alias, request.Alias, provider, request.ModelKey)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(modelAliasErrorStatus(err.GetCode()), responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	providerIDs, ok := route.accessibleProviderPublicIDs(reqCtx, orgEntity.ID)
+	if !ok {
+		return
+	}
+	reqCtx.JSON(http.StatusOK, toModelAliasResponse(updated, providerIDs[updated.ProviderID]))
+}
+
+func (route *ModelProviderRoute) deleteModelAlias(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	alias, ok := route.findModelAlias(reqCtx, orgEntity.ID)
+	if !ok {
+		return
+	}
+
+	if err := route.modelAliasService.DeleteAlias(ctx, alias); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.Status(http.StatusNoContent)
+}
+
+func (route *ModelProviderRoute) findModelAlias(reqCtx *gin.Context, organizationID uint) (*domainmodel.ModelAlias, bool) {
+	publicID := strings.TrimSpace(reqCtx.Param("alias_public_id"))
+	if publicID == "" {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "c4e8a2f6-1b7d-4d39-8a05-e9f3b6c1d728",
+			Error: "alias id is required",
+		})
+		return nil, false
+	}
+
+	alias, err := route.modelAliasService.FindByPublicID(reqCtx.Request.Context(), organizationID, publicID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(modelAliasErrorStatus(err.GetCode()), responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return nil, false
+	}
+	return alias, true
+}
+
+// findAliasTarget checks that the provider is an organization or global provider the caller's
+// organization can route to, and that it serves the model key.
+func (route *ModelProviderRoute) findAliasTarget(reqCtx *gin.Context, organizationID uint, providerPublicID string, modelKey string) (*domainmodel.Provider, bool) {
+	ctx := reqCtx.Request.Context()
+	provider, err := route.providerRegistry.FindByPublicID(ctx, strings.TrimSpace(providerPublicID))
+	if err != nil || provider.ProjectID != nil || (provider.OrganizationID != nil && *provider.OrganizationID != organizationID) {
+		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+			Code:  "5d9b3f7a-2e6c-4a14-b8d0-a1f7c4e9d365",
+			Error: "provider not found",
+		})
+		return nil, false
+	}
+
+	providerModel, modelErr := route.providerRegistry.FindProviderModel(ctx, provider.ID, strings.TrimSpace(modelKey))
+	if modelErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "e1a7c5d3-9f2b-4e86-b4a0-d8c6f2e9a157",
+			ErrorInstance: modelErr,
+		})
+		return nil, false
+	}
+	if providerModel == nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "a6f2d8b4-3c9e-4b51-9d07-f4b1e8c3a296",
+			Error: "provider does not serve model '" + strings.TrimSpace(modelKey) + "'",
+		})
+		return nil, false
+	}
+	return provider, true
+}
+
+// accessibleProviderPublicIDs maps the internal IDs of the providers the organization can route to
+// onto their public IDs, for rendering aliases.
+func (route *ModelProviderRoute) accessibleProviderPublicIDs(reqCtx *gin.Context, organizationID uint) (map[uint]string, bool) {
+	providers, err := route.providerRegistry.ListAccessibleProviders(reqCtx.Request.Context(), organizationID, nil)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "7b4d1e9f-6a3c-4f28-a5e0-c2b8d6f1e743",
+			ErrorInstance: err,
+		})
+		return nil, false
+	}
+	publicIDs := make(map[uint]string, len(providers))
+	for _, provider := range providers {
+		publicIDs[provider.ID] = provider.PublicID
+	}
+	return publicIDs, true
+}
+
+func modelAliasErrorStatus(code string) int {
+	switch code {
+	case domainmodel.ErrCodeModelAliasNotFound:
+		return http.StatusNotFound
+	case domainmodel.ErrCodeModelAliasExists:
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func toModelAliasResponse(alias *domainmodel.ModelAlias, providerPublicID string) modelAliasResponse {
+	return modelAliasResponse{
+		ID:         alias.PublicID,
+		Alias:      alias.Alias,
+		ProviderID: providerPublicID,
+		ModelKey:   alias.ModelKey,
+		CreatedAt:  alias.CreatedAt,
+		UpdatedAt:  alias.UpdatedAt,
+	}
+}
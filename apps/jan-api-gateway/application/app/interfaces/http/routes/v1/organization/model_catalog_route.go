@@ -0,0 +1,76 @@
+package organization
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+type updateModelCatalogRequest struct {
+	Notes       *string `json:"notes"`
+	IsModerated *bool   `json:"is_moderated"`
+	Status      *string `json:"status"`
+}
+
+type modelCatalogResponse struct {
+	ID           string     `json:"id"`
+	Notes        *string    `json:"notes,omitempty"`
+	IsModerated  *bool      `json:"is_moderated,omitempty"`
+	Status       string     `json:"status"`
+	Tags         []string   `json:"tags,omitempty"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// updateModelCatalog curates a catalog entry. Edited entries are marked updated so later syncs
+// keep them.
+func (route *ModelProviderRoute) updateModelCatalog(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+
+	var request updateModelCatalogRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "9e4b7c2d-3a61-4f85-b0d9-e6c1a8f5d372",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	input := domainmodel.UpdateModelCatalogInput{
+		Notes:       request.Notes,
+		IsModerated: request.IsModerated,
+	}
+	if request.Status != nil {
+		status := domainmodel.ModelCatalogStatus(*request.Status)
+		input.Status = &status
+	}
+
+	catalog, err := route.catalogService.UpdateCatalog(ctx, reqCtx.Param("catalog_public_id"), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.GetCode() {
+		case domainmodel.ErrCodeModelCatalogNotFound:
+			status = http.StatusNotFound
+		case domainmodel.ErrCodeInvalidModelCatalogStatus:
+			status = http.StatusBadRequest
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusOK, modelCatalogResponse{
+		ID:           catalog.PublicID,
+		Notes:        catalog.Notes,
+		IsModerated:  catalog.IsModerated,
+		Status:       string(catalog.Status),
+		Tags:         catalog.Tags,
+		LastSyncedAt: catalog.LastSyncedAt,
+		UpdatedAt:    catalog.UpdatedAt,
+	})
+}
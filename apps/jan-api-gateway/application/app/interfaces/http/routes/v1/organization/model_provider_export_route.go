@@ -0,0 +1,107 @@
+package organization
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+type providerImportItem struct {
+	Slug       string  `json:"slug"`
+	ID         *string `json:"id,omitempty"`
+	ModelCount int     `json:"model_count"`
+	ErrorCode  *string `json:"error_code,omitempty"`
+	Error      *string `json:"error,omitempty"`
+}
+
+type providerImportResponse struct {
+	Object string               `json:"object"`
+	Data   []providerImportItem `json:"data"`
+}
+
+// exportProviders returns the organization's providers as a versioned document without secrets.
+func (route *ModelProviderRoute) exportProviders(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	export, err := route.providerRegistry.ExportProviders(ctx, orgEntity.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+	reqCtx.JSON(http.StatusOK, export)
+}
+
+// importProviders recreates the providers of an export document. Each entry reports its own
+// outcome, so one failing provider does not hide the others. An Idempotency-Key makes a retried
+// import replay the first outcome instead of creating the providers again.
+func (route *ModelProviderRoute) importProviders(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	var request domainmodel.ProviderExport
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "1f7d4b9e-6a28-4c53-8e0b-d3a6c9f2e471",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	registration, replayed := route.beginIdempotentRegistration(reqCtx, orgEntity.ID, request)
+	if replayed {
+		return
+	}
+	defer registration.Release(ctx)
+
+	results, err := route.providerRegistry.ImportProviders(ctx, orgEntity.ID, request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.GetCode() == domainmodel.ErrCodeInvalidProviderImport {
+			status = http.StatusBadRequest
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	resp := providerImportResponse{
+		Object: "list",
+		Data:   make([]providerImportItem, 0, len(results)),
+	}
+	for _, result := range results {
+		item := providerImportItem{Slug: result.Slug}
+		for _, model := range result.Models {
+			if model.Error == nil {
+				item.ModelCount++
+			}
+		}
+		if result.Provider != nil {
+			item.ID = &result.Provider.PublicID
+			item.Slug = result.Provider.Slug
+		}
+		if result.Error != nil {
+			code := result.Error.GetCode()
+			message := result.Error.GetMessage()
+			item.ErrorCode = &code
+			item.Error = &message
+		}
+		resp.Data = append(resp.Data, item)
+	}
+	registration.Complete(ctx, http.StatusOK, resp)
+	reqCtx.JSON(http.StatusOK, resp)
+}
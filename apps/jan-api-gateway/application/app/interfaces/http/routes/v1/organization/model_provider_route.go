@@ -1,32 +1,58 @@
 package organization
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"menlo.ai/jan-api-gateway/app/domain/auth"
 	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/user"
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
 	"menlo.ai/jan-api-gateway/app/utils/ptr"
 )
 
+// providerConnectionTestTimeout bounds the upstream call made by the connection dry-run.
+const providerConnectionTestTimeout = 20 * time.Second
+
+// idempotencyKeyHeader lets clients retry a provider registration without creating a duplicate.
+const idempotencyKeyHeader = "Idempotency-Key"
+
 type ModelProviderRoute struct {
 	authService       *auth.AuthService
 	providerRegistry  *domainmodel.ProviderRegistryService
 	inferenceProvider *inference.InferenceProvider
+	userService       *user.UserService
+	projectService    *project.ProjectService
+	modelAliasService *domainmodel.ModelAliasService
+	catalogService    *domainmodel.ModelCatalogService
 }
 
 func NewModelProviderRoute(
 	authService *auth.AuthService,
 	providerRegistry *domainmodel.ProviderRegistryService,
 	inferenceProvider *inference.InferenceProvider,
+	userService *user.UserService,
+	projectService *project.ProjectService,
+	modelAliasService *domainmodel.ModelAliasService,
+	catalogService *domainmodel.ModelCatalogService,
 ) *ModelProviderRoute {
 	return &ModelProviderRoute{
 		authService:       authService,
 		providerRegistry:  providerRegistry,
 		inferenceProvider: inferenceProvider,
+		userService:       userService,
+		projectService:    projectService,
+		modelAliasService: modelAliasService,
+		catalogService:    catalogService,
 	}
 }
 
@@ -37,16 +63,95 @@ func (route *ModelProviderRoute) RegisterRouter(router *gin.RouterGroup) {
 		route.authService.OrganizationMemberRoleMiddleware(auth.OrganizationMemberRuleOwnerOnly),
 	)
 	group.POST("", route.registerProvider)
+	group.POST("/reslug", route.reslugProviders)
+	group.POST("/test", route.testProviderConnection)
+	group.GET("/kinds", route.listProviderKinds)
+	group.GET("/export", route.exportProviders)
+	group.POST("/import", route.importProviders)
+	group.GET("/:provider_public_id", route.getProvider)
+	group.GET("/:provider_public_id/models", route.listProviderModels)
+	group.PATCH("/:provider_public_id/models/:model_public_id", route.updateProviderModel)
 	group.PATCH("/:provider_public_id", route.updateProvider)
+	group.DELETE("/:provider_public_id", route.deleteProvider)
+	group.POST("/:provider_public_id/rotate-key", route.rotateProviderKey)
+	group.POST("/:provider_public_id/verify-key", route.verifyProviderKey)
+	group.POST("/:provider_public_id/refresh", route.refreshProviderModels)
+	group.POST("/:provider_public_id/sync", route.syncProviderModels)
+
+	modelsGroup := router.Group("/models",
+		route.authService.AdminUserAuthMiddleware(),
+		route.authService.RegisteredUserMiddleware(),
+		route.authService.OrganizationMemberRoleMiddleware(auth.OrganizationMemberRuleOwnerOnly),
+	)
+	modelsGroup.GET("/resolve", route.resolveModelProvider)
+	modelsGroup.GET("/aliases", route.listModelAliases)
+	modelsGroup.POST("/aliases", route.createModelAlias)
+	modelsGroup.PATCH("/aliases/:alias_public_id", route.updateModelAlias)
+	modelsGroup.DELETE("/aliases/:alias_public_id", route.deleteModelAlias)
+	modelsGroup.PATCH("/catalogs/:catalog_public_id", route.updateModelCatalog)
+}
+
+type providerKindResponse struct {
+	Kind             string   `json:"kind"`
+	DisplayName      string   `json:"display_name"`
+	Vendors          []string `json:"vendors"`
+	RequiresAPIKey   bool     `json:"requires_api_key"`
+	RequiredMetadata []string `json:"required_metadata"`
+}
+
+type providerKindListResponse struct {
+	Object string                 `json:"object"`
+	Data   []providerKindResponse `json:"data"`
+}
+
+// listProviderKinds lists the supported provider kinds with the fields each needs on registration.
+func (route *ModelProviderRoute) listProviderKinds(reqCtx *gin.Context) {
+	kinds := domainmodel.ProviderKinds()
+	data := make([]providerKindResponse, 0, len(kinds))
+	for _, info := range kinds {
+		requiredMetadata := info.RequiredMetadata
+		if requiredMetadata == nil {
+			requiredMetadata = []string{}
+		}
+		data = append(data, providerKindResponse{
+			Kind:             string(info.Kind),
+			DisplayName:      info.DisplayName,
+			Vendors:          info.Vendors,
+			RequiresAPIKey:   info.RequiresAPIKey,
+			RequiredMetadata: requiredMetadata,
+		})
+	}
+	reqCtx.JSON(http.StatusOK, providerKindListResponse{
+		Object: "list",
+		Data:   data,
+	})
 }
 
 type registerProviderRequest struct {
-	Name     string            `json:"name" binding:"required"`
-	Vendor   string            `json:"vendor" binding:"required"`
-	BaseURL  string            `json:"base_url" binding:"required"`
-	APIKey   string            `json:"api_key"`
-	Metadata map[string]string `json:"metadata"`
-	Active   *bool             `json:"active"`
+	Name        string            `json:"name" binding:"required"`
+	Vendor      string            `json:"vendor" binding:"required"`
+	BaseURL     string            `json:"base_url" binding:"required"`
+	APIKey      string            `json:"api_key"`
+	Metadata    map[string]string `json:"metadata"`
+	Headers     map[string]string `json:"headers"`
+	Active      *bool             `json:"active"`
+	Shadow      bool              `json:"shadow"`
+	ValidateKey bool              `json:"validate_key"`
+	// ProjectPublicID registers the provider for a single project instead of the organization.
+	ProjectPublicID string `json:"project_public_id"`
+	// Slug overrides the generated slug; registration fails with 409 if it is taken.
+	Slug string `json:"slug"`
+	// ModelAllowlist and ModelDenylist are model key glob patterns, e.g. "openai/gpt-4*", limiting
+	// which upstream models are synced as active.
+	ModelAllowlist []string `json:"model_allowlist"`
+	ModelDenylist  []string `json:"model_denylist"`
+	// Priority orders the provider above others at the same scope when higher; defaults to 0.
+	Priority int `json:"priority"`
+	// PathPrefix, e.g. "/api/v1", is inserted between base_url and the operation paths.
+	PathPrefix string `json:"path_prefix"`
+	// KeyMode "passthrough" makes completions use the caller's x-jan-provider-key header instead of
+	// api_key; defaults to "stored".
+	KeyMode string `json:"key_mode"`
 }
 
 type registerProviderResponse struct {
@@ -60,12 +165,24 @@ type registerProviderResponse struct {
 	Models   []registerProviderModelSummary `json:"models"`
 }
 
+// registerProviderModelSummary describes a synced model. ErrorCode and Error are set instead
+// of the model's ID when it could not be synced.
 type registerProviderModelSummary struct {
 	ID            string  `json:"id"`
 	ModelKey      string  `json:"model_key"`
 	DisplayName   string  `json:"display_name"`
 	CatalogID     *string `json:"catalog_id,omitempty"`
 	CatalogStatus *string `json:"catalog_status,omitempty"`
+	ErrorCode     *string `json:"error_code,omitempty"`
+	Error         *string `json:"error,omitempty"`
+}
+
+// providerConflictResponse is returned with 409 when the organization or project already has a
+// provider of the requested kind.
+type providerConflictResponse struct {
+	Code                  string `json:"code"`
+	Error                 string `json:"error"`
+	ConflictingProviderID string `json:"conflicting_provider_id"`
 }
 
 type updateProviderRequest struct {
@@ -73,17 +190,39 @@ type updateProviderRequest struct {
 	BaseURL  *string            `json:"base_url"`
 	APIKey   *string            `json:"api_key"`
 	Metadata *map[string]string `json:"metadata"`
+	Headers  *map[string]string `json:"headers"`
 	Active   *bool              `json:"active"`
+	Shadow   *bool              `json:"shadow"`
+	// ModelAllowlist and ModelDenylist replace the provider's lists and apply from the next refresh.
+	ModelAllowlist *[]string `json:"model_allowlist"`
+	ModelDenylist  *[]string `json:"model_denylist"`
+	Priority       *int      `json:"priority"`
+	PathPrefix     *string   `json:"path_prefix"`
+	KeyMode        *string   `json:"key_mode"`
 }
 
 type providerDetailResponse struct {
-	ID       string            `json:"id"`
-	Slug     string            `json:"slug"`
-	Name     string            `json:"name"`
-	Vendor   string            `json:"vendor"`
-	BaseURL  string            `json:"base_url"`
-	Active   bool              `json:"active"`
-	Metadata map[string]string `json:"metadata,omitempty"`
+	ID                string            `json:"id"`
+	Slug              string            `json:"slug"`
+	Name              string            `json:"name"`
+	Vendor            string            `json:"vendor"`
+	BaseURL           string            `json:"base_url"`
+	PathPrefix        string            `json:"path_prefix,omitempty"`
+	Active            bool              `json:"active"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+	HeaderNames       []string          `json:"header_names,omitempty"`
+	ModelAllowlist    []string          `json:"model_allowlist,omitempty"`
+	ModelDenylist     []string          `json:"model_denylist,omitempty"`
+	APIKeyHint        *string           `json:"api_key_hint,omitempty"`
+	LastSyncedAt      *time.Time        `json:"last_synced_at,omitempty"`
+	IsModerated       bool              `json:"is_moderated"`
+	Shadow            bool              `json:"shadow"`
+	Priority          int               `json:"priority"`
+	KeyMode           string            `json:"key_mode"`
+	ModelCount        int64             `json:"model_count"`
+	LastHealthCheckAt *time.Time        `json:"last_health_check_at,omitempty"`
+	LastHealthError   *string           `json:"last_health_error,omitempty"`
+	FallbackFailures  int64             `json:"fallback_failures"`
 }
 
 func (route *ModelProviderRoute) registerProvider(reqCtx *gin.Context) {
@@ -107,23 +246,63 @@ func (route *ModelProviderRoute) registerProvider(reqCtx *gin.Context) {
 		active = *request.Active
 	}
 
-	result, err := route.providerRegistry.RegisterProvider(ctx, domainmodel.RegisterProviderInput{
+	input := domainmodel.RegisterProviderInput{
 		OrganizationID: orgEntity.ID,
 		Name:           request.Name,
 		Vendor:         request.Vendor,
 		BaseURL:        request.BaseURL,
 		APIKey:         request.APIKey,
 		Metadata:       request.Metadata,
+		Headers:        request.Headers,
+		ModelAllowlist: request.ModelAllowlist,
+		ModelDenylist:  request.ModelDenylist,
 		Active:         active,
-	})
+		Shadow:         request.Shadow,
+		Priority:       request.Priority,
+		PathPrefix:     request.PathPrefix,
+		KeyMode:        request.KeyMode,
+		ValidateKey:    request.ValidateKey,
+		Slug:           request.Slug,
+	}
+	if projectPublicID := strings.TrimSpace(request.ProjectPublicID); projectPublicID != "" {
+		proj, ok := route.findManagedProject(reqCtx, orgEntity.ID, projectPublicID)
+		if !ok {
+			return
+		}
+		input.ProjectID = proj.ID
+	}
+
+	registration, replayed := route.beginIdempotentRegistration(reqCtx, orgEntity.ID, request)
+	if replayed {
+		return
+	}
+	defer registration.Release(ctx)
+
+	result, err := route.providerRegistry.RegisterProvider(ctx, input)
 	if err != nil {
+		var conflict *domainmodel.ProviderConflictError
+		if errors.As(err.GetError(), &conflict) {
+			reqCtx.AbortWithStatusJSON(http.StatusConflict, providerConflictResponse{
+				Code:                  err.GetCode(),
+				Error:                 err.GetMessage(),
+				ConflictingProviderID: conflict.ProviderPublicID,
+			})
+			return
+		}
 		status := http.StatusBadRequest
+		switch err.GetCode() {
+		case domainmodel.ErrCodeDuplicateCustomProvider, domainmodel.ErrCodeProviderSlugTaken:
+			status = http.StatusConflict
+		}
 		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
 			Code:  err.GetCode(),
 			Error: err.GetMessage(),
 		})
 		return
 	}
+	// The provider exists from here on, so a retry must replay it even if the model sync below
+	// fails; the full response replaces this one once the models are in.
+	registration.Complete(ctx, http.StatusOK, toRegisterProviderResponse(result))
 
 	models, fetchErr := route.inferenceProvider.ListModels(ctx, result.Provider)
 	if fetchErr != nil {
@@ -145,6 +324,106 @@ func (route *ModelProviderRoute) registerProvider(reqCtx *gin.Context) {
 	result.Models = syncResults
 
 	resp := toRegisterProviderResponse(result)
+	registration.Complete(ctx, http.StatusOK, resp)
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+// beginIdempotentRegistration claims the request's Idempotency-Key, if any. It reports true when
+// the request has already been answered, either by replaying the stored response of an earlier
+// request with the same key or by aborting with an error.
+func (route *ModelProviderRoute) beginIdempotentRegistration(reqCtx *gin.Context, organizationID uint, request any) (*domainmodel.IdempotentRegistration, bool) {
+	idempotencyKey := strings.TrimSpace(reqCtx.GetHeader(idempotencyKeyHeader))
+	if idempotencyKey == "" {
+		return nil, false
+	}
+	registration, stored, err := route.providerRegistry.BeginIdempotentRegistration(reqCtx.Request.Context(), organizationID, idempotencyKey, request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.GetCode() {
+		case domainmodel.ErrCodeIdempotencyKeyReused:
+			status = http.StatusUnprocessableEntity
+		case domainmodel.ErrCodeIdempotencyKeyInProgress:
+			status = http.StatusConflict
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return nil, true
+	}
+	if stored != nil {
+		reqCtx.Header("Idempotent-Replayed", "true")
+		reqCtx.Data(stored.Status, "application/json; charset=utf-8", stored.Body)
+		return nil, true
+	}
+	return registration, false
+}
+
+type testProviderConnectionRequest struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor" binding:"required"`
+	BaseURL string `json:"base_url" binding:"required"`
+	APIKey  string `json:"api_key"`
+	// PathPrefix, Metadata and Headers are tested the same way they are applied to a registered
+	// provider.
+	PathPrefix string            `json:"path_prefix"`
+	Metadata   map[string]string `json:"metadata"`
+	Headers    map[string]string `json:"headers"`
+}
+
+type testProviderConnectionResponse struct {
+	Reachable      bool   `json:"reachable"`
+	ModelCount     int    `json:"model_count"`
+	LatencyMs      int64  `json:"latency_ms"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+func (route *ModelProviderRoute) testProviderConnection(reqCtx *gin.Context) {
+	var request testProviderConnectionRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "8d3b6f2e-7a19-4c45-b2e0-9f4c1a6d8e53",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(reqCtx.Request.Context(), providerConnectionTestTimeout)
+	defer cancel()
+
+	result, err := route.providerRegistry.TestProviderConnection(ctx, domainmodel.RegisterProviderInput{
+		Name:       request.Name,
+		Vendor:     request.Vendor,
+		BaseURL:    request.BaseURL,
+		APIKey:     request.APIKey,
+		PathPrefix: request.PathPrefix,
+		Metadata:   request.Metadata,
+		Headers:    request.Headers,
+	})
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	resp := testProviderConnectionResponse{
+		Reachable:      result.Reachable,
+		ModelCount:     result.ModelCount,
+		LatencyMs:      result.Latency.Milliseconds(),
+		UpstreamStatus: result.UpstreamStatus,
+		Error:          result.Error,
+	}
+	if !result.Reachable {
+		status := http.StatusBadGateway
+		if ctx.Err() == context.DeadlineExceeded {
+			status = http.StatusGatewayTimeout
+		}
+		reqCtx.AbortWithStatusJSON(status, resp)
+		return
+	}
 	reqCtx.JSON(http.StatusOK, resp)
 }
 
@@ -157,10 +436,20 @@ func toRegisterProviderResponse(result *domainmodel.ProviderRegistrationResult)
 		Vendor:   strings.ToLower(string(provider.Kind)),
 		BaseURL:  provider.BaseURL,
 		Active:   provider.Active,
-		Metadata: provider.Metadata,
+		Metadata: domainmodel.MaskedMetadata(provider.Metadata),
 	}
 
 	for _, model := range result.Models {
+		if model.Error != nil {
+			code := model.Error.GetCode()
+			message := model.Error.GetMessage()
+			resp.Models = append(resp.Models, registerProviderModelSummary{
+				ModelKey:  model.ModelKey,
+				ErrorCode: &code,
+				Error:     &message,
+			})
+			continue
+		}
 		item := registerProviderModelSummary{
 			ID:          model.ProviderModel.PublicID,
 			ModelKey:    model.ProviderModel.ModelKey,
@@ -177,11 +466,13 @@ func toRegisterProviderResponse(result *domainmodel.ProviderRegistrationResult)
 	return resp
 }
 
-func (route *ModelProviderRoute) updateProvider(reqCtx *gin.Context) {
+// findManageableProvider loads the provider named in the path and checks that it is owned by the
+// caller's organization and, for project providers, that the caller can manage the project.
+func (route *ModelProviderRoute) findManageableProvider(reqCtx *gin.Context) (*domainmodel.Provider, bool) {
 	ctx := reqCtx.Request.Context()
 	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
 	if !ok {
-		return
+		return nil, false
 	}
 	publicID := strings.TrimSpace(reqCtx.Param("provider_public_id"))
 	if publicID == "" {
@@ -189,7 +480,7 @@ func (route *ModelProviderRoute) updateProvider(reqCtx *gin.Context) {
 			Code:  "28dd6e4a-b7df-4e75-bb70-2b7f2a44d8ec",
 			Error: "provider id is required",
 		})
-		return
+		return nil, false
 	}
 
 	provider, err := route.providerRegistry.FindByPublicID(ctx, publicID)
@@ -202,23 +493,230 @@ func (route *ModelProviderRoute) updateProvider(reqCtx *gin.Context) {
 			Code:  err.GetCode(),
 			Error: err.GetMessage(),
 		})
-		return
+		return nil, false
 	}
-	if provider.OrganizationID == nil || *provider.OrganizationID != orgEntity.ID {
-		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+	var proj *project.Project
+	if provider.ProjectID != nil && provider.OrganizationID != nil && *provider.OrganizationID == orgEntity.ID {
+		if found, err := route.projectService.FindProjectByID(ctx, *provider.ProjectID); err == nil {
+			proj = found
+		}
+	}
+	if status, errResp := route.providerAccess(provider, orgEntity.ID, proj); errResp != nil {
+		reqCtx.AbortWithStatusJSON(status, *errResp)
+		return nil, false
+	}
+	return provider, true
+}
+
+// providerAccess returns the status and error a caller of the organization is refused the
+// provider with, or a nil error when the provider can be managed. proj is the provider's project,
+// nil for organization providers or when it could not be loaded.
+func (route *ModelProviderRoute) providerAccess(provider *domainmodel.Provider, organizationID uint, proj *project.Project) (int, *responses.ErrorResponse) {
+	if provider.OrganizationID == nil || *provider.OrganizationID != organizationID {
+		return http.StatusNotFound, &responses.ErrorResponse{
 			Code:  "a2b8c03f-4a15-4431-9a0f-0a5c8ef0e83d",
 			Error: "provider not found",
+		}
+	}
+	if provider.ProjectID != nil && (proj == nil || proj.ID != *provider.ProjectID || !route.canManageProject(proj, organizationID)) {
+		return http.StatusForbidden, &responses.ErrorResponse{
+			Code:  "7e2d9b41-5c83-4f06-a1e9-d4b8c6f3a025",
+			Error: "provider belongs to a project you cannot manage",
+		}
+	}
+	return 0, nil
+}
+
+// findManagedProject loads a project by public ID for provider registration and checks that the
+// caller can manage it.
+func (route *ModelProviderRoute) findManagedProject(reqCtx *gin.Context, organizationID uint, projectPublicID string) (*project.Project, bool) {
+	proj, err := route.projectService.FindProjectByPublicID(reqCtx.Request.Context(), projectPublicID)
+	if err != nil || proj == nil || proj.OrganizationID != organizationID {
+		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+			Code:  "c3f8a6d2-9b14-4e57-8d0a-2f6e1b9c7a43",
+			Error: "project not found",
+		})
+		return nil, false
+	}
+	if !route.canManageProject(proj, organizationID) {
+		reqCtx.AbortWithStatusJSON(http.StatusForbidden, responses.ErrorResponse{
+			Code:  "1a9e4c7b-3d62-4f85-b0c8-e5d2a7f9b316",
+			Error: "project cannot be managed",
+		})
+		return nil, false
+	}
+	return proj, true
+}
+
+// canManageProject reports whether providers can be managed for the project. These routes are
+// restricted to organization owners, who manage every active project of their organization.
+func (route *ModelProviderRoute) canManageProject(proj *project.Project, organizationID uint) bool {
+	return proj.OrganizationID == organizationID &&
+		proj.ArchivedAt == nil &&
+		proj.Status != string(project.ProjectStatusArchived)
+}
+
+func (route *ModelProviderRoute) getProvider(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	modelCount, err := route.providerRegistry.CountProviderModels(ctx, provider.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "6b2e8d4f-9a1c-4f37-b5e0-c8d3a7f1e296",
+			ErrorInstance: err,
 		})
 		return
 	}
-	if provider.ProjectID != nil {
+
+	resp := toProviderDetailResponse(provider, modelCount)
+	resp.FallbackFailures = route.providerFallbackFailures(ctx, provider)
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+type providerModelItem struct {
+	ID                 string                   `json:"id"`
+	ModelKey           string                   `json:"model_key"`
+	DisplayName        string                   `json:"display_name"`
+	Family             *string                  `json:"family,omitempty"`
+	Pricing            []domainmodel.PriceLine  `json:"pricing"`
+	PriceOverride      []domainmodel.PriceLine  `json:"price_override,omitempty"`
+	TokenLimits        *domainmodel.TokenLimits `json:"token_limits,omitempty"`
+	SupportsImages     bool                     `json:"supports_images"`
+	SupportsEmbeddings bool                     `json:"supports_embeddings"`
+	SupportsReasoning  bool                     `json:"supports_reasoning"`
+	Active             bool                     `json:"active"`
+	Disabled           bool                     `json:"disabled"`
+}
+
+type providerModelsResponse struct {
+	Object string              `json:"object"`
+	Data   []providerModelItem `json:"data"`
+}
+
+func (route *ModelProviderRoute) listProviderModels(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	var active *bool
+	if activeStr := reqCtx.Query("active"); activeStr != "" {
+		value, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "a7d3f1c9-4e2b-4b68-8f05-c1e9b6d2a437",
+				Error: "invalid active value",
+			})
+			return
+		}
+		active = &value
+	}
+
+	models, err := route.providerRegistry.ListModelsForProvider(ctx, provider.ID, active)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "e8b4c2a6-3d7f-4f19-9c50-b2a6d8e4f173",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	sort.SliceStable(models, func(i, j int) bool {
+		return models[i].DisplayName < models[j].DisplayName
+	})
+
+	resp := providerModelsResponse{
+		Object: "list",
+		Data:   make([]providerModelItem, 0, len(models)),
+	}
+	for _, model := range models {
+		resp.Data = append(resp.Data, toProviderModelItem(model))
+	}
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+func toProviderModelItem(model *domainmodel.ProviderModel) providerModelItem {
+	pricing := model.Pricing.Lines
+	if pricing == nil {
+		pricing = []domainmodel.PriceLine{}
+	}
+	var priceOverride []domainmodel.PriceLine
+	if model.PriceOverride != nil {
+		priceOverride = model.PriceOverride.Lines
+	}
+	return providerModelItem{
+		ID:                 model.PublicID,
+		ModelKey:           model.ModelKey,
+		DisplayName:        model.DisplayName,
+		Family:             model.Family,
+		Pricing:            pricing,
+		PriceOverride:      priceOverride,
+		TokenLimits:        model.TokenLimits,
+		SupportsImages:     model.SupportsImages,
+		SupportsEmbeddings: model.SupportsEmbeddings,
+		SupportsReasoning:  model.SupportsReasoning,
+		Active:             model.Active,
+		Disabled:           model.Disabled,
+	}
+}
+
+type updateProviderModelRequest struct {
+	Active        *bool                `json:"active"`
+	PriceOverride *domainmodel.Pricing `json:"price_override"`
+}
+
+// updateProviderModel turns a single model of the provider on or off without touching its other
+// models. A model turned off stops resolving for completions and is hidden from model listings.
+func (route *ModelProviderRoute) updateProviderModel(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	var request updateProviderModelRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
 		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
-			Code:  "4b4ff5ab-6a55-4aa7-842c-9a8d6fd8b061",
-			Error: "only organization providers can be updated here",
+			Code:          "c5e1a8d3-9b4f-4f26-a7d0-3e6b2c9f1a85",
+			ErrorInstance: err,
 		})
 		return
 	}
 
+	model, err := route.providerRegistry.UpdateProviderModel(ctx, provider, reqCtx.Param("model_public_id"), domainmodel.UpdateProviderModelInput{
+		Active:        request.Active,
+		PriceOverride: request.PriceOverride,
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.GetCode() {
+		case domainmodel.ErrCodeProviderModelNotFound:
+			status = http.StatusNotFound
+		case domainmodel.ErrCodeInvalidProviderModelUpdate:
+			status = http.StatusBadRequest
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusOK, toProviderModelItem(model))
+}
+
+func (route *ModelProviderRoute) updateProvider(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
 	var request updateProviderRequest
 	if err := reqCtx.ShouldBindJSON(&request); err != nil {
 		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
@@ -229,33 +727,451 @@ func (route *ModelProviderRoute) updateProvider(reqCtx *gin.Context) {
 	}
 
 	input := domainmodel.UpdateProviderInput{
-		Name:     request.Name,
-		BaseURL:  request.BaseURL,
-		APIKey:   request.APIKey,
-		Metadata: request.Metadata,
-		Active:   request.Active,
+		Name:           request.Name,
+		BaseURL:        request.BaseURL,
+		APIKey:         request.APIKey,
+		Metadata:       request.Metadata,
+		Headers:        request.Headers,
+		ModelAllowlist: request.ModelAllowlist,
+		ModelDenylist:  request.ModelDenylist,
+		Active:         request.Active,
+		Shadow:         request.Shadow,
+		Priority:       request.Priority,
+		PathPrefix:     request.PathPrefix,
+		KeyMode:        request.KeyMode,
 	}
 
 	updated, updateErr := route.providerRegistry.UpdateProvider(ctx, provider, input)
 	if updateErr != nil {
-		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+		status := http.StatusBadRequest
+		if updateErr.GetCode() == domainmodel.ErrCodeDuplicateCustomProvider {
+			status = http.StatusConflict
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
 			Code:  updateErr.GetCode(),
 			Error: updateErr.GetMessage(),
 		})
 		return
 	}
 
-	reqCtx.JSON(http.StatusOK, toProviderDetailResponse(updated))
+	modelCount, err := route.providerRegistry.CountProviderModels(ctx, updated.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "d0a5c3e7-2f8b-4b61-9e4d-a1f6b9c2e853",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	resp := toProviderDetailResponse(updated, modelCount)
+	resp.FallbackFailures = route.providerFallbackFailures(ctx, updated)
+	reqCtx.JSON(http.StatusOK, resp)
 }
 
-func toProviderDetailResponse(provider *domainmodel.Provider) providerDetailResponse {
+func (route *ModelProviderRoute) deleteProvider(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := route.providerRegistry.DeleteProvider(ctx, provider); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.Status(http.StatusNoContent)
+}
+
+func (route *ModelProviderRoute) refreshProviderModels(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	syncResults, err := route.providerRegistry.RefreshProviderModels(ctx, provider)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.GetCode() == domainmodel.ErrCodeProviderModelFetchFailed {
+			status = http.StatusBadGateway
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	resp := toRegisterProviderResponse(&domainmodel.ProviderRegistrationResult{
+		Provider: provider,
+		Models:   syncResults,
+	})
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+// providerSyncPreviewResponse lists the catalogs and models a sync would change.
+type providerSyncPreviewResponse struct {
+	ID     string `json:"id"`
+	DryRun bool   `json:"dry_run"`
+	domainmodel.ProviderSyncDiff
+}
+
+// syncProviderModels refreshes the provider's models like refreshProviderModels; with
+// dry_run=true it only reports what the refresh would change.
+func (route *ModelProviderRoute) syncProviderModels(reqCtx *gin.Context) {
+	dryRun := false
+	if dryRunStr := reqCtx.Query("dry_run"); dryRunStr != "" {
+		value, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+				Code:  "c2f8a5d1-7b3e-4d90-a6c4-e9b1f7d3a528",
+				Error: "invalid dry_run value",
+			})
+			return
+		}
+		dryRun = value
+	}
+	if !dryRun {
+		route.refreshProviderModels(reqCtx)
+		return
+	}
+
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	diff, err := route.providerRegistry.PreviewRefresh(ctx, provider)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.GetCode() == domainmodel.ErrCodeProviderModelFetchFailed {
+			status = http.StatusBadGateway
+		}
+		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusOK, providerSyncPreviewResponse{
+		ID:               provider.PublicID,
+		DryRun:           true,
+		ProviderSyncDiff: *diff,
+	})
+}
+
+type rotateProviderKeyRequest struct {
+	APIKey string `json:"api_key" binding:"required"`
+}
+
+type rotateProviderKeyResponse struct {
+	ID                 string     `json:"id"`
+	APIKeyHint         *string    `json:"api_key_hint,omitempty"`
+	PreviousAPIKeyHint *string    `json:"previous_api_key_hint,omitempty"`
+	KeyRotatedAt       *time.Time `json:"key_rotated_at,omitempty"`
+}
+
+func (route *ModelProviderRoute) rotateProviderKey(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	var request rotateProviderKeyRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "4f1c8e3a-6b2d-4a97-bd05-d9e7a2c6f318",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	rotated, err := route.providerRegistry.RotateAPIKey(ctx, provider, request.APIKey)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	reqCtx.JSON(http.StatusOK, rotateProviderKeyResponse{
+		ID:                 rotated.PublicID,
+		APIKeyHint:         rotated.APIKeyHint,
+		PreviousAPIKeyHint: rotated.PreviousAPIKeyHint,
+		KeyRotatedAt:       rotated.KeyRotatedAt,
+	})
+}
+
+// verifyProviderKeyResponse reports whether the stored key still works; the key itself is never returned.
+type verifyProviderKeyResponse struct {
+	Decryptable    bool   `json:"decryptable"`
+	AuthOK         bool   `json:"auth_ok"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+	Code           string `json:"code,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// verifyProviderKey checks that the provider's stored key decrypts with the current
+// MODEL_PROVIDER_SECRET and authenticates upstream, for validating a secret rotation. Decryption
+// failures return 422 and upstream failures 502, each with its own error code.
+func (route *ModelProviderRoute) verifyProviderKey(reqCtx *gin.Context) {
+	provider, ok := route.findManageableProvider(reqCtx)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(reqCtx.Request.Context(), providerConnectionTestTimeout)
+	defer cancel()
+
+	result, err := route.providerRegistry.VerifyProviderKey(ctx, provider)
+	resp := verifyProviderKeyResponse{
+		Decryptable:    result.Decryptable,
+		AuthOK:         result.AuthOK,
+		UpstreamStatus: result.UpstreamStatus,
+	}
+	if err != nil {
+		resp.Code = err.GetCode()
+		resp.Error = err.GetMessage()
+		status := http.StatusBadGateway
+		if err.GetCode() == domainmodel.ErrCodeProviderKeyDecryptFailed {
+			status = http.StatusUnprocessableEntity
+		}
+		reqCtx.AbortWithStatusJSON(status, resp)
+		return
+	}
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+type resolveProviderCandidate struct {
+	ID          string `json:"id"`
+	Slug        string `json:"slug"`
+	Scope       string `json:"scope"`
+	Active      bool   `json:"active"`
+	HasModel    bool   `json:"has_model"`
+	CircuitOpen bool   `json:"circuit_open"`
+}
+
+type resolveWeightedProvider struct {
+	ID     string `json:"id"`
+	Slug   string `json:"slug"`
+	Weight int    `json:"weight"`
+}
+
+type resolveModelProviderResponse struct {
+	Model      string                     `json:"model"`
+	Candidates []resolveProviderCandidate `json:"candidates"`
+	ChosenID   string                     `json:"chosen_id,omitempty"`
+	ChosenSlug string                     `json:"chosen_slug,omitempty"`
+	Weighted   []resolveWeightedProvider  `json:"weighted,omitempty"`
+	Resolution string                     `json:"resolution"`
+	Fallback   bool                       `json:"fallback"`
+}
+
+// resolveModelProvider reports which provider a completion for the given model would be routed to,
+// scoped to a project or to the projects a user belongs to. No upstream call is made.
+func (route *ModelProviderRoute) resolveModelProvider(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	modelKey := strings.TrimSpace(reqCtx.Query("model"))
+	if modelKey == "" {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  "5e1c9a47-3d28-4b6f-a0e7-9f4b2c8d6a13",
+			Error: "model is required",
+		})
+		return
+	}
+
+	projectIDs, ok := route.resolveProjectScope(reqCtx, orgEntity.ID)
+	if !ok {
+		return
+	}
+
+	trace, err := route.providerRegistry.TraceProviderForModel(ctx, modelKey, orgEntity.ID, projectIDs)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "c7a3f5e9-1b46-4d82-9f0a-3e8b6d2c4a57",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	candidates := make([]resolveProviderCandidate, 0, len(trace.Candidates))
+	for _, candidate := range trace.Candidates {
+		candidates = append(candidates, resolveProviderCandidate{
+			ID:          candidate.Provider.PublicID,
+			Slug:        candidate.Provider.Slug,
+			Scope:       string(candidate.Provider.Scope()),
+			Active:      candidate.Provider.Active,
+			HasModel:    candidate.HasModel,
+			CircuitOpen: candidate.CircuitOpen,
+		})
+	}
+
+	resp := resolveModelProviderResponse{
+		Model:      modelKey,
+		Candidates: candidates,
+		Resolution: string(trace.Resolution),
+		Fallback:   trace.Resolution != domainmodel.ProviderResolutionMatched && trace.Resolution != domainmodel.ProviderResolutionNone,
+	}
+	if trace.Chosen != nil {
+		resp.ChosenID = trace.Chosen.PublicID
+		resp.ChosenSlug = trace.Chosen.Slug
+	}
+	for _, weighted := range trace.Weighted {
+		resp.Weighted = append(resp.Weighted, resolveWeightedProvider{
+			ID:     weighted.Provider.PublicID,
+			Slug:   weighted.Provider.Slug,
+			Weight: weighted.Weight,
+		})
+	}
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+// resolveProjectScope returns the project IDs used for resolution: the `project` query parameter
+// when present, otherwise the organization projects the `user` query parameter is a member of.
+func (route *ModelProviderRoute) resolveProjectScope(reqCtx *gin.Context, organizationID uint) ([]uint, bool) {
+	ctx := reqCtx.Request.Context()
+	if projectPublicID := strings.TrimSpace(reqCtx.Query("project")); projectPublicID != "" {
+		proj, err := route.projectService.FindProjectByPublicID(ctx, projectPublicID)
+		if err != nil || proj == nil || proj.OrganizationID != organizationID {
+			reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+				Code:  "8d4b2f61-7e3a-4c95-b1d0-6a9e5c3f7b28",
+				Error: "project not found",
+			})
+			return nil, false
+		}
+		return []uint{proj.ID}, true
+	}
+
+	userPublicID := strings.TrimSpace(reqCtx.Query("user"))
+	if userPublicID == "" {
+		return nil, true
+	}
+	userEntity, err := route.userService.FindByPublicID(ctx, userPublicID)
+	if err != nil || userEntity == nil {
+		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+			Code:  "2f9c6e13-a8d4-4b70-9e52-c1b7d3a6f084",
+			Error: "user not found",
+		})
+		return nil, false
+	}
+	projects, err := route.projectService.Find(ctx, project.ProjectFilter{
+		OrganizationID: ptr.ToUint(organizationID),
+		MemberID:       ptr.ToUint(userEntity.ID),
+	}, nil)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "e6a1d8b3-4c27-4f9e-8b05-7d3c2a9f1e64",
+			ErrorInstance: err,
+		})
+		return nil, false
+	}
+	projectIDs := make([]uint, 0, len(projects))
+	for _, proj := range projects {
+		projectIDs = append(projectIDs, proj.ID)
+	}
+	return projectIDs, true
+}
+
+type reslugProviderItem struct {
+	ID      string `json:"id"`
+	OldSlug string `json:"old_slug"`
+	NewSlug string `json:"new_slug"`
+}
+
+type reslugProvidersResponse struct {
+	Object string               `json:"object"`
+	Data   []reslugProviderItem `json:"data"`
+}
+
+func (route *ModelProviderRoute) reslugProviders(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	results, err := route.providerRegistry.RepairSlugs(ctx, orgEntity.ID)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:  err.GetCode(),
+			Error: err.GetMessage(),
+		})
+		return
+	}
+
+	resp := reslugProvidersResponse{
+		Object: "list",
+		Data:   make([]reslugProviderItem, 0, len(results)),
+	}
+	for _, result := range results {
+		resp.Data = append(resp.Data, reslugProviderItem{
+			ID:      result.Provider.PublicID,
+			OldSlug: result.OldSlug,
+			NewSlug: result.NewSlug,
+		})
+	}
+	reqCtx.JSON(http.StatusOK, resp)
+}
+
+func toProviderDetailResponse(provider *domainmodel.Provider, modelCount int64) providerDetailResponse {
 	return providerDetailResponse{
-		ID:       provider.PublicID,
-		Slug:     provider.Slug,
-		Name:     provider.DisplayName,
-		Vendor:   strings.ToLower(string(provider.Kind)),
-		BaseURL:  provider.BaseURL,
-		Active:   provider.Active,
-		Metadata: provider.Metadata,
+		ID:                provider.PublicID,
+		Slug:              provider.Slug,
+		Name:              provider.DisplayName,
+		Vendor:            strings.ToLower(string(provider.Kind)),
+		BaseURL:           provider.BaseURL,
+		PathPrefix:        provider.PathPrefix,
+		Active:            provider.Active,
+		Metadata:          domainmodel.MaskedMetadata(provider.Metadata),
+		HeaderNames:       providerHeaderNames(provider),
+		ModelAllowlist:    provider.ModelAllowlist,
+		ModelDenylist:     provider.ModelDenylist,
+		APIKeyHint:        provider.APIKeyHint,
+		LastSyncedAt:      provider.LastSyncedAt,
+		IsModerated:       provider.IsModerated,
+		Shadow:            provider.Shadow,
+		Priority:          provider.Priority,
+		KeyMode:           string(provider.KeyMode),
+		ModelCount:        modelCount,
+		LastHealthCheckAt: provider.LastHealthCheckAt,
+		LastHealthError:   provider.LastHealthError,
+	}
+}
+
+// providerFallbackFailures returns the provider's recent fallback failure count. The count is
+// informational, so a cache error is logged and reported as zero.
+func (route *ModelProviderRoute) providerFallbackFailures(ctx context.Context, provider *domainmodel.Provider) int64 {
+	failures, err := route.providerRegistry.ProviderFallbackFailures(ctx, provider.ID)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to read fallback failures for provider %s: %v", provider.PublicID, err)
+		return 0
+	}
+	return failures
+}
+
+// providerHeaderNames lists the custom header names of a provider. Values are left out because
+// custom headers often carry credentials.
+func providerHeaderNames(provider *domainmodel.Provider) []string {
+	if len(provider.Headers) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(provider.Headers))
+	for name := range provider.Headers {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
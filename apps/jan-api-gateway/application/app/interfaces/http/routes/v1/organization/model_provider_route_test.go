@@ -0,0 +1,128 @@
+package organization
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/model/modeltest"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+)
+
+func TestProviderAccess(t *testing.T) {
+	orgID := uint(5)
+	otherOrgID := uint(6)
+	projectID := uint(9)
+	archivedAt := time.Now()
+	activeProject := &project.Project{ID: projectID, OrganizationID: orgID, Status: string(project.ProjectStatusActive)}
+
+	tests := []struct {
+		name     string
+		provider *domainmodel.Provider
+		proj     *project.Project
+		status   int
+	}{
+		{
+			name:     "organization provider",
+			provider: &domainmodel.Provider{OrganizationID: &orgID},
+		},
+		{
+			name:     "provider of another organization",
+			provider: &domainmodel.Provider{OrganizationID: &otherOrgID},
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "global provider",
+			provider: &domainmodel.Provider{},
+			status:   http.StatusNotFound,
+		},
+		{
+			name:     "project provider of an active project",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			proj:     activeProject,
+		},
+		{
+			name:     "project provider whose project could not be loaded",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "project provider of an archived project",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			proj:     &project.Project{ID: projectID, OrganizationID: orgID, Status: string(project.ProjectStatusArchived)},
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "project provider of a project with archived_at set",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			proj:     &project.Project{ID: projectID, OrganizationID: orgID, Status: string(project.ProjectStatusActive), ArchivedAt: &archivedAt},
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "project provider of a project in another organization",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			proj:     &project.Project{ID: projectID, OrganizationID: otherOrgID, Status: string(project.ProjectStatusActive)},
+			status:   http.StatusForbidden,
+		},
+		{
+			name:     "project provider checked against a different project",
+			provider: &domainmodel.Provider{OrganizationID: &orgID, ProjectID: &projectID},
+			proj:     &project.Project{ID: projectID + 1, OrganizationID: orgID, Status: string(project.ProjectStatusActive)},
+			status:   http.StatusForbidden,
+		},
+	}
+	route := &ModelProviderRoute{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, errResp := route.providerAccess(tt.provider, orgID, tt.proj)
+			if tt.status == 0 {
+				if errResp != nil {
+					t.Fatalf("providerAccess refused with %d %s, want access", status, errResp.Error)
+				}
+				return
+			}
+			if errResp == nil || status != tt.status {
+				t.Fatalf("providerAccess = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestDeleteProviderNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orgID := uint(5)
+	otherOrgID := uint(6)
+	registry := modeltest.NewRegistry()
+	registry.Providers.Add(&domainmodel.Provider{PublicID: "prov-other", Slug: "other", OrganizationID: &otherOrgID})
+	route := &ModelProviderRoute{providerRegistry: registry.ProviderRegistryService}
+
+	tests := []struct {
+		name     string
+		publicID string
+	}{
+		{name: "unknown provider", publicID: "prov-missing"},
+		{name: "provider of another organization", publicID: "prov-other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			reqCtx, _ := gin.CreateTestContext(recorder)
+			reqCtx.Request = httptest.NewRequest(http.MethodDelete, "/v1/organization/models/providers/"+tt.publicID, nil)
+			reqCtx.Params = gin.Params{{Key: "provider_public_id", Value: tt.publicID}}
+			auth.SetAdminOrganizationToContext(reqCtx, &organization.Organization{ID: orgID})
+
+			route.deleteProvider(reqCtx)
+			if recorder.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d", recorder.Code, http.StatusNotFound)
+			}
+			if registry.Providers.Calls("DeleteByID") != 0 {
+				t.Fatal("provider was deleted")
+			}
+		})
+	}
+}
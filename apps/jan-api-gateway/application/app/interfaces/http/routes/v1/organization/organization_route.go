@@ -12,15 +12,17 @@ type OrganizationRoute struct {
 	projectsRoute      *projects.ProjectsRoute
 	inviteRoute        *invites.InvitesRoute
 	modelProviderRoute *ModelProviderRoute
+	settingsRoute      *OrganizationSettingsRoute
 	authService        *auth.AuthService
 }
 
-func NewOrganizationRoute(adminApiKeyAPI *AdminApiKeyAPI, projectsRoute *projects.ProjectsRoute, inviteRoute *invites.InvitesRoute, modelProviderRoute *ModelProviderRoute, authService *auth.AuthService) *OrganizationRoute {
+func NewOrganizationRoute(adminApiKeyAPI *AdminApiKeyAPI, projectsRoute *projects.ProjectsRoute, inviteRoute *invites.InvitesRoute, modelProviderRoute *ModelProviderRoute, settingsRoute *OrganizationSettingsRoute, authService *auth.AuthService) *OrganizationRoute {
 	return &OrganizationRoute{
 		adminApiKeyAPI:     adminApiKeyAPI,
 		projectsRoute:      projectsRoute,
 		inviteRoute:        inviteRoute,
 		modelProviderRoute: modelProviderRoute,
+		settingsRoute:      settingsRoute,
 		authService:        authService,
 	}
 }
@@ -31,4 +33,5 @@ func (organizationRoute *OrganizationRoute) RegisterRouter(router gin.IRouter) {
 	organizationRoute.projectsRoute.RegisterRouter(organizationRouter)
 	organizationRoute.inviteRoute.RegisterRouter(organizationRouter)
 	organizationRoute.modelProviderRoute.RegisterRouter(organizationRouter)
+	organizationRoute.settingsRoute.RegisterRouter(organizationRouter)
 }
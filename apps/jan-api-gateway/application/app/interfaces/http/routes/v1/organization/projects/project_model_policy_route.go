@@ -0,0 +1,133 @@
+package projects
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+// ModelPolicyRequest replaces a project's model policy. Entries are model key globs such as
+// "openai/gpt-4*"; an empty allow list permits every model that is not denied.
+type ModelPolicyRequest struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// ModelPolicyResponse is a project's model policy; empty lists mean every model is permitted.
+type ModelPolicyResponse struct {
+	ProjectID string   `json:"project_id"`
+	Allow     []string `json:"allow"`
+	Deny      []string `json:"deny"`
+}
+
+// getModelPolicy godoc
+// @Summary Get Project Model Policy
+// @Description Retrieves the models the project's API keys may request.
+// @Tags Administration API
+// @Security BearerAuth
+// @Param project_id path string true "ID of the project"
+// @Success 200 {object} ModelPolicyResponse "The project's model policy"
+// @Failure 404 {object} responses.ErrorResponse "Not Found - project with the given ID does not exist"
+// @Router /v1/organization/projects/{project_id}/model-policy [get]
+func (api *ProjectsRoute) getModelPolicy(reqCtx *gin.Context) {
+	projectEntity, ok := api.modelPolicyProject(reqCtx)
+	if !ok {
+		return
+	}
+	reqCtx.JSON(http.StatusOK, toModelPolicyResponse(projectEntity))
+}
+
+// updateModelPolicy godoc
+// @Summary Update Project Model Policy
+// @Description Replaces the models the project's API keys may request. Chat completions for other models are rejected with 403.
+// @Tags Administration API
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param project_id path string true "ID of the project"
+// @Param body body ModelPolicyRequest true "Model policy"
+// @Success 200 {object} ModelPolicyResponse "The updated model policy"
+// @Failure 400 {object} responses.ErrorResponse "Bad request - invalid payload or pattern"
+// @Failure 404 {object} responses.ErrorResponse "Not Found - project with the given ID does not exist"
+// @Router /v1/organization/projects/{project_id}/model-policy [put]
+func (api *ProjectsRoute) updateModelPolicy(reqCtx *gin.Context) {
+	projectEntity, ok := api.modelPolicyProject(reqCtx)
+	if !ok {
+		return
+	}
+	var request ModelPolicyRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "6d2b8f4a-3e7c-4a19-b5d6-f0c9e1a7b382",
+			ErrorInstance: err,
+		})
+		return
+	}
+	policy, policyErr := project.NewProjectModelPolicy(request.Allow, request.Deny)
+	if policyErr != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:  policyErr.GetCode(),
+			Error: policyErr.GetMessage(),
+		})
+		return
+	}
+	api.saveModelPolicy(reqCtx, projectEntity, policy)
+}
+
+// deleteModelPolicy godoc
+// @Summary Delete Project Model Policy
+// @Description Removes the project's model policy so its API keys may request every model again.
+// @Tags Administration API
+// @Security BearerAuth
+// @Param project_id path string true "ID of the project"
+// @Success 200 {object} ModelPolicyResponse "The now empty model policy"
+// @Failure 404 {object} responses.ErrorResponse "Not Found - project with the given ID does not exist"
+// @Router /v1/organization/projects/{project_id}/model-policy [delete]
+func (api *ProjectsRoute) deleteModelPolicy(reqCtx *gin.Context) {
+	projectEntity, ok := api.modelPolicyProject(reqCtx)
+	if !ok {
+		return
+	}
+	api.saveModelPolicy(reqCtx, projectEntity, nil)
+}
+
+func (api *ProjectsRoute) modelPolicyProject(reqCtx *gin.Context) (*project.Project, bool) {
+	projectEntity, ok := auth.GetProjectFromContext(reqCtx)
+	if !ok {
+		reqCtx.AbortWithStatusJSON(http.StatusNotFound, responses.ErrorResponse{
+			Code:  "42ad3a04-6c17-40db-a10f-640be569c93f",
+			Error: "project not found",
+		})
+		return nil, false
+	}
+	return projectEntity, true
+}
+
+func (api *ProjectsRoute) saveModelPolicy(reqCtx *gin.Context, projectEntity *project.Project, policy *project.ProjectModelPolicy) {
+	projectEntity.ModelPolicy = policy
+	updated, err := api.projectService.UpdateProject(reqCtx.Request.Context(), projectEntity)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "b9e4c1d7-5a3f-4e82-9c06-d8f2a7b3e549",
+			ErrorInstance: err,
+		})
+		return
+	}
+	reqCtx.JSON(http.StatusOK, toModelPolicyResponse(updated))
+}
+
+func toModelPolicyResponse(projectEntity *project.Project) ModelPolicyResponse {
+	resp := ModelPolicyResponse{
+		ProjectID: projectEntity.PublicID,
+		Allow:     []string{},
+		Deny:      []string{},
+	}
+	if policy := projectEntity.ModelPolicy; policy != nil {
+		resp.Allow = append(resp.Allow, policy.Allow...)
+		resp.Deny = append(resp.Deny, policy.Deny...)
+	}
+	return resp
+}
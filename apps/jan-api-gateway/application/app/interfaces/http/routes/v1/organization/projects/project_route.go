@@ -89,6 +89,17 @@ func (projectsRoute *ProjectsRoute) RegisterRouter(router gin.IRouter) {
 		permissionOwnerOnly,
 		projectsRoute.updateProjectProvider,
 	)
+	projectIdRouter.GET("/model-policy",
+		projectsRoute.getModelPolicy,
+	)
+	projectIdRouter.PUT("/model-policy",
+		permissionOwnerOnly,
+		projectsRoute.updateModelPolicy,
+	)
+	projectIdRouter.DELETE("/model-policy",
+		permissionOwnerOnly,
+		projectsRoute.deleteModelPolicy,
+	)
 	projectsRoute.projectApiKeyRoute.RegisterRouter(projectIdRouter)
 }
 
@@ -324,6 +335,13 @@ func (api *ProjectsRoute) UpdateProject(reqCtx *gin.Context) {
 	if requestPayload.Name != nil {
 		entity.Name = *requestPayload.Name
 	}
+	if requestPayload.MonthlySpendLimitMicroUSD != nil {
+		if *requestPayload.MonthlySpendLimitMicroUSD > 0 {
+			entity.MonthlySpendLimitMicroUSD = requestPayload.MonthlySpendLimitMicroUSD
+		} else {
+			entity.MonthlySpendLimitMicroUSD = nil
+		}
+	}
 
 	updatedEntity, err := projectService.UpdateProject(ctx, entity)
 	if err != nil {
@@ -376,20 +394,27 @@ func (api *ProjectsRoute) ArchiveProject(reqCtx *gin.Context) {
 }
 
 type registerProjectProviderRequest struct {
-	Name     string            `json:"name" binding:"required"`
-	Vendor   string            `json:"vendor" binding:"required"`
-	BaseURL  string            `json:"base_url" binding:"required"`
-	APIKey   string            `json:"api_key"`
-	Metadata map[string]string `json:"metadata"`
-	Active   *bool             `json:"active"`
+	Name        string            `json:"name" binding:"required"`
+	Vendor      string            `json:"vendor" binding:"required"`
+	BaseURL     string            `json:"base_url" binding:"required"`
+	APIKey      string            `json:"api_key"`
+	Metadata    map[string]string `json:"metadata"`
+	Headers     map[string]string `json:"headers"`
+	Active      *bool             `json:"active"`
+	ValidateKey bool              `json:"validate_key"`
+	Slug        string            `json:"slug"`
 }
 
+// registerProjectProviderModelSummary describes a synced model. ErrorCode and Error are set instead
+// of the model's ID when it could not be synced.
 type registerProjectProviderModelSummary struct {
 	ID            string  `json:"id"`
 	ModelKey      string  `json:"model_key"`
 	DisplayName   string  `json:"display_name"`
 	CatalogID     *string `json:"catalog_id,omitempty"`
 	CatalogStatus *string `json:"catalog_status,omitempty"`
+	ErrorCode     *string `json:"error_code,omitempty"`
+	Error         *string `json:"error,omitempty"`
 }
 
 type registerProjectProviderResponse struct {
@@ -409,6 +434,7 @@ type updateProjectProviderRequest struct {
 	BaseURL  *string            `json:"base_url"`
 	APIKey   *string            `json:"api_key"`
 	Metadata *map[string]string `json:"metadata"`
+	Headers  *map[string]string `json:"headers"`
 	Active   *bool              `json:"active"`
 }
 
@@ -449,10 +475,16 @@ func (api *ProjectsRoute) registerProjectProvider(reqCtx *gin.Context) {
 		BaseURL:        request.BaseURL,
 		APIKey:         request.APIKey,
 		Metadata:       request.Metadata,
+		Headers:        request.Headers,
 		Active:         active,
+		ValidateKey:    request.ValidateKey,
+		Slug:           request.Slug,
 	})
 	if err != nil {
 		status := http.StatusBadRequest
+		if err.GetCode() == domainmodel.ErrCodeProviderSlugTaken {
+			status = http.StatusConflict
+		}
 		reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
 			Code:  err.GetCode(),
 			Error: err.GetMessage(),
@@ -547,6 +579,7 @@ func (api *ProjectsRoute) updateProjectProvider(reqCtx *gin.Context) {
 		BaseURL:  request.BaseURL,
 		APIKey:   request.APIKey,
 		Metadata: request.Metadata,
+		Headers:  request.Headers,
 		Active:   request.Active,
 	}
 
@@ -571,11 +604,21 @@ func toProjectRegisterProviderResponse(result *domainmodel.ProviderRegistrationR
 		Vendor:    strings.ToLower(string(provider.Kind)),
 		BaseURL:   provider.BaseURL,
 		Active:    provider.Active,
-		Metadata:  provider.Metadata,
+		Metadata:  domainmodel.MaskedMetadata(provider.Metadata),
 		ProjectID: projectPublicID,
 	}
 
 	for _, model := range result.Models {
+		if model.Error != nil {
+			code := model.Error.GetCode()
+			message := model.Error.GetMessage()
+			resp.Models = append(resp.Models, registerProjectProviderModelSummary{
+				ModelKey:  model.ModelKey,
+				ErrorCode: &code,
+				Error:     &message,
+			})
+			continue
+		}
 		item := registerProjectProviderModelSummary{
 			ID:          model.ProviderModel.PublicID,
 			ModelKey:    model.ProviderModel.ModelKey,
@@ -600,19 +643,20 @@ func toProjectProviderResponse(provider *domainmodel.Provider, projectPublicID s
 		Vendor:    strings.ToLower(string(provider.Kind)),
 		BaseURL:   provider.BaseURL,
 		Active:    provider.Active,
-		Metadata:  provider.Metadata,
+		Metadata:  domainmodel.MaskedMetadata(provider.Metadata),
 		ProjectID: projectPublicID,
 	}
 }
 
 // ProjectResponse defines the response structure for a project.
 type ProjectResponse struct {
-	Object     string `json:"object" example:"project" description:"The type of the object, 'project'"`
-	ID         string `json:"id" example:"proj_1234567890" description:"Unique identifier for the project"`
-	Name       string `json:"name" example:"My First Project" description:"The name of the project"`
-	CreatedAt  int64  `json:"created_at" example:"1698765432" description:"Unix timestamp when the project was created"`
-	ArchivedAt *int64 `json:"archived_at,omitempty" example:"1698765432" description:"Unix timestamp when the project was archived, if applicable"`
-	Status     string `json:"status"`
+	Object                    string `json:"object" example:"project" description:"The type of the object, 'project'"`
+	ID                        string `json:"id" example:"proj_1234567890" description:"Unique identifier for the project"`
+	Name                      string `json:"name" example:"My First Project" description:"The name of the project"`
+	CreatedAt                 int64  `json:"created_at" example:"1698765432" description:"Unix timestamp when the project was created"`
+	ArchivedAt                *int64 `json:"archived_at,omitempty" example:"1698765432" description:"Unix timestamp when the project was archived, if applicable"`
+	Status                    string `json:"status"`
+	MonthlySpendLimitMicroUSD *int64 `json:"monthly_spend_limit_micro_usd,omitempty" example:"50000000" description:"Monthly inference spend limit for the project in micro-USD, if set"`
 }
 
 // CreateProjectRequest defines the request payload for creating a project.
@@ -623,6 +667,8 @@ type CreateProjectRequest struct {
 // UpdateProjectRequest defines the request payload for updating a project.
 type UpdateProjectRequest struct {
 	Name *string `json:"name" example:"Updated AI Project" description:"The new name for the project"`
+	// MonthlySpendLimitMicroUSD caps the project's monthly spend within the organization's limit; zero or less removes the cap.
+	MonthlySpendLimitMicroUSD *int64 `json:"monthly_spend_limit_micro_usd" example:"50000000" description:"Monthly inference spend limit for the project in micro-USD"`
 }
 
 // ProjectListResponse defines the response structure for a list of projects.
@@ -640,11 +686,12 @@ func domainToProjectResponse(p *project.Project) ProjectResponse {
 		archivedAt = ptr.ToInt64(p.CreatedAt.Unix())
 	}
 	return ProjectResponse{
-		Object:     string(openai.ObjectKeyProject),
-		ID:         p.PublicID,
-		Name:       p.Name,
-		CreatedAt:  p.CreatedAt.Unix(),
-		ArchivedAt: archivedAt,
-		Status:     p.Status,
+		Object:                    string(openai.ObjectKeyProject),
+		ID:                        p.PublicID,
+		Name:                      p.Name,
+		CreatedAt:                 p.CreatedAt.Unix(),
+		ArchivedAt:                archivedAt,
+		Status:                    p.Status,
+		MonthlySpendLimitMicroUSD: p.MonthlySpendLimitMicroUSD,
 	}
 }
@@ -0,0 +1,141 @@
+package organization
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/domain/auth"
+	domainmodel "menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/organization"
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/responses"
+)
+
+type OrganizationSettingsRoute struct {
+	authService      *auth.AuthService
+	providerRegistry *domainmodel.ProviderRegistryService
+	webhookService   *webhook.WebhookService
+}
+
+func NewOrganizationSettingsRoute(
+	authService *auth.AuthService,
+	providerRegistry *domainmodel.ProviderRegistryService,
+	webhookService *webhook.WebhookService,
+) *OrganizationSettingsRoute {
+	return &OrganizationSettingsRoute{
+		authService:      authService,
+		providerRegistry: providerRegistry,
+		webhookService:   webhookService,
+	}
+}
+
+func (route *OrganizationSettingsRoute) RegisterRouter(router *gin.RouterGroup) {
+	group := router.Group("/settings",
+		route.authService.AdminUserAuthMiddleware(),
+		route.authService.RegisteredUserMiddleware(),
+	)
+	group.GET("",
+		route.authService.OrganizationMemberRoleMiddleware(auth.OrganizationMemberRuleAll),
+		route.getSettings,
+	)
+	group.PATCH("",
+		route.authService.OrganizationMemberRoleMiddleware(auth.OrganizationMemberRuleOwnerOnly),
+		route.updateSettings,
+	)
+}
+
+type organizationSettingsResponse struct {
+	// DefaultProviderID is the public ID of the provider serving models no provider advertises;
+	// null means the Jan provider is used.
+	DefaultProviderID *string `json:"default_provider_id"`
+	// WebhookURL receives signed provider.sync.completed and provider.health.changed events.
+	WebhookURL *string `json:"webhook_url"`
+	// WebhookSecret signs the events. It is only returned by the update that sets WebhookURL.
+	WebhookSecret *string `json:"webhook_secret,omitempty"`
+}
+
+type updateOrganizationSettingsRequest struct {
+	// DefaultProviderID sets the default provider; an empty string clears it.
+	DefaultProviderID *string `json:"default_provider_id"`
+	// WebhookURL sets the webhook URL; an empty string turns webhooks off.
+	WebhookURL *string `json:"webhook_url"`
+}
+
+func (route *OrganizationSettingsRoute) getSettings(reqCtx *gin.Context) {
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+	route.respondSettings(reqCtx, orgEntity, "")
+}
+
+func (route *OrganizationSettingsRoute) updateSettings(reqCtx *gin.Context) {
+	ctx := reqCtx.Request.Context()
+	orgEntity, ok := auth.GetAdminOrganizationFromContext(reqCtx)
+	if !ok {
+		return
+	}
+
+	var request updateOrganizationSettingsRequest
+	if err := reqCtx.ShouldBindJSON(&request); err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusBadRequest, responses.ErrorResponse{
+			Code:          "7a3c9e1f-5d2b-4b68-8f04-e1d6b3a9c752",
+			ErrorInstance: err,
+		})
+		return
+	}
+
+	if request.DefaultProviderID != nil {
+		if _, err := route.providerRegistry.SetOrganizationDefaultProvider(ctx, orgEntity, *request.DefaultProviderID); err != nil {
+			status := http.StatusInternalServerError
+			if err.GetCode() == domainmodel.ErrCodeInvalidDefaultProvider {
+				status = http.StatusBadRequest
+			}
+			reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+				Code:  err.GetCode(),
+				Error: err.GetMessage(),
+			})
+			return
+		}
+	}
+	var webhookSecret string
+	if request.WebhookURL != nil {
+		secret, err := route.webhookService.SetOrganizationWebhookURL(ctx, orgEntity, *request.WebhookURL)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if err.GetCode() == webhook.ErrCodeInvalidWebhookURL {
+				status = http.StatusBadRequest
+			}
+			reqCtx.AbortWithStatusJSON(status, responses.ErrorResponse{
+				Code:  err.GetCode(),
+				Error: err.GetMessage(),
+			})
+			return
+		}
+		webhookSecret = secret
+	}
+	route.respondSettings(reqCtx, orgEntity, webhookSecret)
+}
+
+// respondSettings writes the organization settings. webhookSecret is the signing secret created by
+// this request, if any; stored secrets are never returned.
+func (route *OrganizationSettingsRoute) respondSettings(reqCtx *gin.Context, orgEntity *organization.Organization, webhookSecret string) {
+	provider, err := route.providerRegistry.FindOrganizationDefaultProvider(reqCtx.Request.Context(), orgEntity)
+	if err != nil {
+		reqCtx.AbortWithStatusJSON(http.StatusInternalServerError, responses.ErrorResponse{
+			Code:          "e4b8d2f6-1c9a-4e37-a5d0-9f3c7b1e6a28",
+			ErrorInstance: err,
+		})
+		return
+	}
+	resp := organizationSettingsResponse{
+		WebhookURL: orgEntity.WebhookURL,
+	}
+	if provider != nil {
+		resp.DefaultProviderID = &provider.PublicID
+	}
+	if webhookSecret != "" {
+		resp.WebhookSecret = &webhookSecret
+	}
+	reqCtx.JSON(http.StatusOK, resp)
+}
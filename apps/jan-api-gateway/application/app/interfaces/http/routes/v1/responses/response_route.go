@@ -210,6 +210,8 @@ func (responseRoute *ResponseRoute) CreateResponse(reqCtx *gin.Context) {
 
 // handleResponseCreation handles both streaming and non-streaming response creation
 func (responseRoute *ResponseRoute) handleResponseCreation(reqCtx *gin.Context, result *response.ResponseCreationResult, request *requesttypes.CreateResponseRequest) {
+	responses.SetProviderFallbackHeaders(reqCtx, result.FallbackReason)
+
 	// Set up streaming headers if needed
 	if result.IsStreaming {
 		reqCtx.Header("Content-Type", "text/event-stream")
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/admin"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/auth"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/chat"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/conv"
@@ -26,6 +27,7 @@ type V1Route struct {
 	mcpAPI             *mcp.MCPAPI
 	authRoute          *auth.AuthRoute
 	responsesRoute     *responses.ResponseRoute
+	adminProviderRoute *admin.AdminProviderRoute
 }
 
 func NewV1Route(
@@ -39,6 +41,7 @@ func NewV1Route(
 	mcpAPI *mcp.MCPAPI,
 	authRoute *auth.AuthRoute,
 	responsesRoute *responses.ResponseRoute,
+	adminProviderRoute *admin.AdminProviderRoute,
 ) *V1Route {
 	return &V1Route{
 		organizationRoute,
@@ -51,6 +54,7 @@ func NewV1Route(
 		mcpAPI,
 		authRoute,
 		responsesRoute,
+		adminProviderRoute,
 	}
 }
 
@@ -67,6 +71,7 @@ func (v1Route *V1Route) RegisterRouter(router gin.IRouter) {
 	v1Route.organizationRoute.RegisterRouter(v1Router)
 	v1Route.authRoute.RegisterRouter(v1Router)
 	v1Route.responsesRoute.RegisterRouter(v1Router)
+	v1Route.adminProviderRoute.RegisterRouter(v1Router)
 }
 
 // GetVersion godoc
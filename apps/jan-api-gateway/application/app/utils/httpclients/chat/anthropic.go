@@ -0,0 +1,352 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// DefaultAnthropicVersion is sent as the anthropic-version header when the provider does not
+// configure one.
+const DefaultAnthropicVersion = "2023-06-01"
+
+// defaultAnthropicMaxTokens is used when the request sets no limit, since the Messages API
+// requires max_tokens.
+const defaultAnthropicMaxTokens = 4096
+
+// WithAnthropicMessages translates requests to Anthropic's Messages API, POST /messages, and maps
+// its responses and stream events back to the OpenAI chat completion shape.
+func WithAnthropicMessages() ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.anthropicMessages = true
+	}
+}
+
+func (cfg endpointConfig) anthropic() bool {
+	return cfg.anthropicMessages
+}
+
+type anthropicMessagesRequest struct {
+	Model         string                    `json:"model"`
+	System        string                    `json:"system,omitempty"`
+	Messages      []anthropicMessage        `json:"messages"`
+	MaxTokens     int                       `json:"max_tokens"`
+	Temperature   *float32                  `json:"temperature,omitempty"`
+	TopP          *float32                  `json:"top_p,omitempty"`
+	StopSequences []string                  `json:"stop_sequences,omitempty"`
+	Stream        bool                      `json:"stream,omitempty"`
+	Tools         []anthropicTool           `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice      `json:"tool_choice,omitempty"`
+	Metadata      *anthropicRequestMetadata `json:"metadata,omitempty"`
+}
+
+type anthropicRequestMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type      string                `json:"type"`
+	Text      string                `json:"text,omitempty"`
+	Thinking  string                `json:"thinking,omitempty"`
+	Source    *anthropicImageSource `json:"source,omitempty"`
+	ID        string                `json:"id,omitempty"`
+	Name      string                `json:"name,omitempty"`
+	Input     json.RawMessage       `json:"input,omitempty"`
+	ToolUseID string                `json:"tool_use_id,omitempty"`
+	Content   string                `json:"content,omitempty"`
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicMessagesResponse struct {
+	ID         string                  `json:"id"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// toAnthropicRequest translates an OpenAI chat completion request into a Messages API request.
+// System and developer messages move to the top-level system field, tool results become
+// tool_result blocks of a user turn and consecutive turns of the same role are merged.
+func toAnthropicRequest(request openai.ChatCompletionRequest) anthropicMessagesRequest {
+	result := anthropicMessagesRequest{
+		Model:         request.Model,
+		MaxTokens:     request.MaxCompletionTokens,
+		StopSequences: request.Stop,
+		Stream:        request.Stream,
+	}
+	if result.MaxTokens <= 0 {
+		result.MaxTokens = request.MaxTokens
+	}
+	if result.MaxTokens <= 0 {
+		result.MaxTokens = defaultAnthropicMaxTokens
+	}
+	if request.Temperature != 0 {
+		result.Temperature = &request.Temperature
+	}
+	if request.TopP != 0 {
+		result.TopP = &request.TopP
+	}
+	if request.User != "" {
+		result.Metadata = &anthropicRequestMetadata{UserID: request.User}
+	}
+
+	var system []string
+	for _, message := range request.Messages {
+		switch message.Role {
+		case openai.ChatMessageRoleSystem, openai.ChatMessageRoleDeveloper:
+			if text := openAIMessageText(message); text != "" {
+				system = append(system, text)
+			}
+		case openai.ChatMessageRoleTool:
+			result.Messages = appendAnthropicTurn(result.Messages, "user", anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: message.ToolCallID,
+				Content:   openAIMessageText(message),
+			})
+		case openai.ChatMessageRoleAssistant:
+			blocks := make([]anthropicContentBlock, 0, len(message.ToolCalls)+1)
+			if text := openAIMessageText(message); text != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: text})
+			}
+			for _, toolCall := range message.ToolCalls {
+				input := json.RawMessage(toolCall.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    toolCall.ID,
+					Name:  toolCall.Function.Name,
+					Input: input,
+				})
+			}
+			result.Messages = appendAnthropicTurn(result.Messages, "assistant", blocks...)
+		default:
+			result.Messages = appendAnthropicTurn(result.Messages, "user", anthropicUserBlocks(message)...)
+		}
+	}
+	result.System = strings.Join(system, "\n\n")
+
+	for _, tool := range request.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		result.Tools = append(result.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	result.ToolChoice = toAnthropicToolChoice(request.ToolChoice)
+	return result
+}
+
+// appendAnthropicTurn adds blocks to the conversation, extending the last turn when it has the
+// same role.
+func appendAnthropicTurn(messages []anthropicMessage, role string, blocks ...anthropicContentBlock) []anthropicMessage {
+	if len(blocks) == 0 {
+		return messages
+	}
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == role {
+		messages[last].Content = append(messages[last].Content, blocks...)
+		return messages
+	}
+	return append(messages, anthropicMessage{Role: role, Content: blocks})
+}
+
+func anthropicUserBlocks(message openai.ChatCompletionMessage) []anthropicContentBlock {
+	if len(message.MultiContent) == 0 {
+		if message.Content == "" {
+			return nil
+		}
+		return []anthropicContentBlock{{Type: "text", Text: message.Content}}
+	}
+	blocks := make([]anthropicContentBlock, 0, len(message.MultiContent))
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case openai.ChatMessagePartTypeText:
+			if part.Text != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: part.Text})
+			}
+		case openai.ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil && part.ImageURL.URL != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "image", Source: anthropicImageSourceFromURL(part.ImageURL.URL)})
+			}
+		}
+	}
+	return blocks
+}
+
+// anthropicImageSourceFromURL inlines data URLs as base64 sources and passes other URLs through.
+func anthropicImageSourceFromURL(imageURL string) *anthropicImageSource {
+	if rest, ok := strings.CutPrefix(imageURL, "data:"); ok {
+		if header, data, found := strings.Cut(rest, ","); found {
+			if mediaType, isBase64 := strings.CutSuffix(header, ";base64"); isBase64 {
+				return &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: data}
+			}
+		}
+	}
+	return &anthropicImageSource{Type: "url", URL: imageURL}
+}
+
+// openAIMessageText returns the text of a message, joining the text parts of multi-part content.
+func openAIMessageText(message openai.ChatCompletionMessage) string {
+	if len(message.MultiContent) == 0 {
+		return message.Content
+	}
+	parts := make([]string, 0, len(message.MultiContent))
+	for _, part := range message.MultiContent {
+		if part.Type == openai.ChatMessagePartTypeText && part.Text != "" {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toAnthropicToolChoice maps the OpenAI tool_choice, which is either a string or a named tool.
+func toAnthropicToolChoice(choice any) *anthropicToolChoice {
+	switch v := choice.(type) {
+	case nil:
+		return nil
+	case string:
+		switch v {
+		case "auto":
+			return &anthropicToolChoice{Type: "auto"}
+		case "none":
+			return &anthropicToolChoice{Type: "none"}
+		case "required":
+			return &anthropicToolChoice{Type: "any"}
+		}
+		return nil
+	case openai.ToolChoice:
+		return &anthropicToolChoice{Type: "tool", Name: v.Function.Name}
+	case *openai.ToolChoice:
+		if v == nil {
+			return nil
+		}
+		return &anthropicToolChoice{Type: "tool", Name: v.Function.Name}
+	case map[string]any:
+		if function, ok := v["function"].(map[string]any); ok {
+			if name, ok := function["name"].(string); ok && name != "" {
+				return &anthropicToolChoice{Type: "tool", Name: name}
+			}
+		}
+	}
+	return nil
+}
+
+// fromAnthropicResponse maps a Messages API response onto an OpenAI chat completion response.
+func fromAnthropicResponse(response anthropicMessagesResponse) *openai.ChatCompletionResponse {
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+	var content, reasoning strings.Builder
+	for _, block := range response.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, openai.ToolCall{
+				ID:   block.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      block.Name,
+					Arguments: arguments,
+				},
+			})
+		}
+	}
+	message.Content = content.String()
+	message.ReasoningContent = reasoning.String()
+
+	return &openai.ChatCompletionResponse{
+		ID:      response.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   response.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Index:        0,
+			Message:      message,
+			FinishReason: anthropicFinishReason(response.StopReason),
+		}},
+		Usage: openai.Usage{
+			PromptTokens:     response.Usage.InputTokens,
+			CompletionTokens: response.Usage.OutputTokens,
+			TotalTokens:      response.Usage.InputTokens + response.Usage.OutputTokens,
+		},
+	}
+}
+
+func anthropicFinishReason(stopReason string) openai.FinishReason {
+	switch stopReason {
+	case "":
+		return ""
+	case "max_tokens":
+		return openai.FinishReasonLength
+	case "tool_use":
+		return openai.FinishReasonToolCalls
+	case "refusal":
+		return openai.FinishReasonContentFilter
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func (c *ChatCompletionClient) createAnthropicMessage(ctx context.Context, apiKey string, request openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	anthropicRequest := toAnthropicRequest(request)
+	anthropicRequest.Stream = false
+	var respBody anthropicMessagesResponse
+	resp, err := c.postWithRateLimitRetry(ctx, func() (*resty.Response, error) {
+		return c.prepareRequest(ctx, apiKey).
+			SetBody(anthropicRequest).
+			SetResult(&respBody).
+			Post(c.endpointURL("/messages"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, c.errorFromResponse(resp, "request failed")
+	}
+	return fromAnthropicResponse(respBody), nil
+}
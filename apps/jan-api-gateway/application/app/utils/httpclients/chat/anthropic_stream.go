@@ -0,0 +1,166 @@
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+type anthropicStreamEvent struct {
+	Type         string                     `json:"type"`
+	Index        int                        `json:"index"`
+	Message      *anthropicMessagesResponse `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock     `json:"content_block,omitempty"`
+	Delta        *anthropicStreamDelta      `json:"delta,omitempty"`
+	Usage        *anthropicUsage            `json:"usage,omitempty"`
+	Error        *upstreamErrorObject       `json:"error,omitempty"`
+}
+
+type anthropicStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	PartialJSON string `json:"partial_json"`
+	Thinking    string `json:"thinking"`
+	StopReason  string `json:"stop_reason"`
+}
+
+// anthropicStreamReader turns a Messages API event stream into an OpenAI chat completion chunk
+// stream, so the rest of the client can relay and accumulate it like any other provider's.
+// Usage is attached to the chunk carrying the finish reason.
+type anthropicStreamReader struct {
+	translatedStream
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	name    string
+
+	inputTokens int
+	// toolCalls maps content block indexes to OpenAI tool call indexes.
+	toolCalls map[int]int
+}
+
+func newAnthropicStreamReader(body io.ReadCloser, name, model string) *anthropicStreamReader {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, scannerInitialBuffer), scannerMaxBuffer)
+	return &anthropicStreamReader{
+		translatedStream: newTranslatedStream(model),
+		body:             body,
+		scanner:          scanner,
+		name:             name,
+		toolCalls:        make(map[int]int),
+	}
+}
+
+func (r *anthropicStreamReader) Read(p []byte) (int, error) {
+	return r.read(p, r.advance)
+}
+
+func (r *anthropicStreamReader) Close() error {
+	return r.body.Close()
+}
+
+// advance consumes upstream lines until at least one chunk has been produced or the stream ends.
+func (r *anthropicStreamReader) advance() error {
+	for r.pending.Len() == 0 && !r.done {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return err
+			}
+			return io.ErrUnexpectedEOF
+		}
+		data, found := strings.CutPrefix(r.scanner.Text(), dataPrefix)
+		if !found {
+			continue
+		}
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("%s: failed to parse stream event: %w", r.name, err)
+		}
+		if err := r.handle(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *anthropicStreamReader) handle(event anthropicStreamEvent) error {
+	switch event.Type {
+	case "message_start":
+		if event.Message != nil {
+			r.id = event.Message.ID
+			if event.Message.Model != "" {
+				r.model = event.Message.Model
+			}
+			r.inputTokens = event.Message.Usage.InputTokens
+		}
+		return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "", nil)
+	case "content_block_start":
+		if event.ContentBlock == nil || event.ContentBlock.Type != "tool_use" {
+			return nil
+		}
+		index := len(r.toolCalls)
+		r.toolCalls[event.Index] = index
+		return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{
+			ToolCalls: []openai.ToolCall{{
+				Index: &index,
+				ID:    event.ContentBlock.ID,
+				Type:  openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name: event.ContentBlock.Name,
+				},
+			}},
+		}, "", nil)
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil
+		}
+		switch event.Delta.Type {
+		case "text_delta":
+			return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{Content: event.Delta.Text}, "", nil)
+		case "thinking_delta":
+			return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{ReasoningContent: event.Delta.Thinking}, "", nil)
+		case "input_json_delta":
+			index, ok := r.toolCalls[event.Index]
+			if !ok {
+				return nil
+			}
+			return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{
+				ToolCalls: []openai.ToolCall{{
+					Index:    &index,
+					Function: openai.FunctionCall{Arguments: event.Delta.PartialJSON},
+				}},
+			}, "", nil)
+		}
+		return nil
+	case "message_delta":
+		var usage *openai.Usage
+		if event.Usage != nil {
+			usage = &openai.Usage{
+				PromptTokens:     r.inputTokens,
+				CompletionTokens: event.Usage.OutputTokens,
+				TotalTokens:      r.inputTokens + event.Usage.OutputTokens,
+			}
+		}
+		stopReason := ""
+		if event.Delta != nil {
+			stopReason = event.Delta.StopReason
+		}
+		return r.writeChunk(openai.ChatCompletionStreamChoiceDelta{}, anthropicFinishReason(stopReason), usage)
+	case "message_stop":
+		r.finish()
+		return nil
+	case "error":
+		upstreamErr := &UpstreamError{summary: fmt.Sprintf("%s: streaming error event", r.name)}
+		if event.Error != nil {
+			upstreamErr.Type = event.Error.Type
+			upstreamErr.Message = event.Error.Message
+			upstreamErr.summary = fmt.Sprintf("%s: streaming error event: %s: %s", r.name, event.Error.Type, event.Error.Message)
+		}
+		return upstreamErr
+	}
+	// ping and content_block_stop carry nothing to relay
+	return nil
+}
@@ -0,0 +1,293 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+func TestToAnthropicRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   openai.ChatCompletionRequest
+		system    string
+		messages  []anthropicMessage
+		maxTokens int
+	}{
+		{
+			name: "system and developer messages move to the system field",
+			request: openai.ChatCompletionRequest{Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+				{Role: openai.ChatMessageRoleUser, Content: "Hi"},
+				{Role: openai.ChatMessageRoleDeveloper, MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeText, Text: "Answer in French."},
+					{Type: openai.ChatMessagePartTypeText, Text: "No emojis."},
+				}},
+			}},
+			system: "Be brief.\n\nAnswer in French.\nNo emojis.",
+			messages: []anthropicMessage{
+				{Role: "user", Content: []anthropicContentBlock{{Type: "text", Text: "Hi"}}},
+			},
+			maxTokens: defaultAnthropicMaxTokens,
+		},
+		{
+			name: "consecutive user turns are merged",
+			request: openai.ChatCompletionRequest{MaxTokens: 64, Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleUser, Content: "Look at this"},
+				{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "data:image/png;base64,iVBORw0KGgo="}},
+					{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "https://example.com/cat.jpg"}},
+				}},
+			}},
+			messages: []anthropicMessage{
+				{Role: "user", Content: []anthropicContentBlock{
+					{Type: "text", Text: "Look at this"},
+					{Type: "image", Source: &anthropicImageSource{Type: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="}},
+					{Type: "image", Source: &anthropicImageSource{Type: "url", URL: "https://example.com/cat.jpg"}},
+				}},
+			},
+			maxTokens: 64,
+		},
+		{
+			name: "tool calls and results",
+			request: openai.ChatCompletionRequest{MaxTokens: 64, MaxCompletionTokens: 128, Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleUser, Content: "Weather in Paris?"},
+				{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{
+					{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+					{ID: "call_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "time", Arguments: `not json`}},
+				}},
+				{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: "18C"},
+				{Role: openai.ChatMessageRoleTool, ToolCallID: "call_2", Content: "14:00"},
+			}},
+			messages: []anthropicMessage{
+				{Role: "user", Content: []anthropicContentBlock{{Type: "text", Text: "Weather in Paris?"}}},
+				{Role: "assistant", Content: []anthropicContentBlock{
+					{Type: "tool_use", ID: "call_1", Name: "weather", Input: json.RawMessage(`{"city":"Paris"}`)},
+					{Type: "tool_use", ID: "call_2", Name: "time", Input: json.RawMessage(`{}`)},
+				}},
+				{Role: "user", Content: []anthropicContentBlock{
+					{Type: "tool_result", ToolUseID: "call_1", Content: "18C"},
+					{Type: "tool_result", ToolUseID: "call_2", Content: "14:00"},
+				}},
+			},
+			maxTokens: 128,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toAnthropicRequest(tt.request)
+			if got.System != tt.system {
+				t.Fatalf("system = %q, want %q", got.System, tt.system)
+			}
+			if !reflect.DeepEqual(got.Messages, tt.messages) {
+				gotJSON, _ := json.Marshal(got.Messages)
+				wantJSON, _ := json.Marshal(tt.messages)
+				t.Fatalf("messages = %s, want %s", gotJSON, wantJSON)
+			}
+			if got.MaxTokens != tt.maxTokens {
+				t.Fatalf("max_tokens = %d, want %d", got.MaxTokens, tt.maxTokens)
+			}
+		})
+	}
+}
+
+func TestToAnthropicToolChoice(t *testing.T) {
+	tests := []struct {
+		name   string
+		choice any
+		want   *anthropicToolChoice
+	}{
+		{name: "unset", choice: nil},
+		{name: "auto", choice: "auto", want: &anthropicToolChoice{Type: "auto"}},
+		{name: "none", choice: "none", want: &anthropicToolChoice{Type: "none"}},
+		{name: "required", choice: "required", want: &anthropicToolChoice{Type: "any"}},
+		{name: "named tool", choice: openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: "weather"}}, want: &anthropicToolChoice{Type: "tool", Name: "weather"}},
+		{name: "decoded named tool", choice: map[string]any{"type": "function", "function": map[string]any{"name": "weather"}}, want: &anthropicToolChoice{Type: "tool", Name: "weather"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toAnthropicToolChoice(tt.choice); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("tool choice = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnthropicMessages(t *testing.T) {
+	upstream, calls := recordingUpstream(t, `{
+		"id": "msg_1",
+		"model": "claude-sonnet-4",
+		"content": [
+			{"type": "thinking", "thinking": "The user wants the weather."},
+			{"type": "text", "text": "Let me check."},
+			{"type": "tool_use", "id": "toolu_1", "name": "weather", "input": {"city": "Paris"}}
+		],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 20, "output_tokens": 9}
+	}`)
+	client := NewChatCompletionClient(resty.New(), "anthropic", upstream.URL+"/v1", WithAnthropicMessages())
+
+	request := streamRequest()
+	request.Model = "claude-sonnet-4"
+	response, err := client.CreateChatCompletion(context.Background(), "", request)
+	if err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+
+	call := (*calls)[0]
+	if call.Path != "/v1/messages" {
+		t.Fatalf("request went to %s, want /v1/messages", call.Path)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(call.Body, &body); err != nil {
+		t.Fatalf("invalid request body %s: %v", call.Body, err)
+	}
+	if _, ok := body["stream"]; ok {
+		t.Fatalf("non-streaming request body has stream set: %s", call.Body)
+	}
+
+	message := response.Choices[0].Message
+	if message.Content != "Let me check." || message.ReasoningContent != "The user wants the weather." {
+		t.Fatalf("message = %+v, want the text and thinking blocks", message)
+	}
+	if len(message.ToolCalls) != 1 || message.ToolCalls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Fatalf("tool calls = %+v, want the weather call", message.ToolCalls)
+	}
+	if response.Choices[0].FinishReason != openai.FinishReasonToolCalls {
+		t.Fatalf("finish reason = %q, want tool_calls", response.Choices[0].FinishReason)
+	}
+	if response.Usage.PromptTokens != 20 || response.Usage.CompletionTokens != 9 || response.Usage.TotalTokens != 29 {
+		t.Fatalf("usage = %+v, want 20 + 9", response.Usage)
+	}
+}
+
+func TestAnthropicStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    []string
+		content   string
+		reasoning string
+		toolCalls []openai.ToolCall
+		finish    openai.FinishReason
+		usage     openai.Usage
+		wantErr   string
+		upstream  bool
+	}{
+		{
+			name: "text",
+			events: []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\",\"model\":\"claude-sonnet-4\",\"usage\":{\"input_tokens\":12}}}",
+				"event: ping\ndata: {\"type\":\"ping\"}",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"thinking\",\"thinking\":\"\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"thinking_delta\",\"thinking\":\"Easy.\"}}",
+				"event: content_block_stop\ndata: {\"type\":\"content_block_stop\",\"index\":0}",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":1,\"content_block\":{\"type\":\"text\",\"text\":\"\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"Par\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":1,\"delta\":{\"type\":\"text_delta\",\"text\":\"is.\"}}",
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"max_tokens\"},\"usage\":{\"output_tokens\":3}}",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+			},
+			content:   "Paris.",
+			reasoning: "Easy.",
+			finish:    openai.FinishReasonLength,
+			usage:     openai.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15},
+		},
+		{
+			name: "tool use",
+			events: []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_2\",\"usage\":{\"input_tokens\":30}}}",
+				"event: content_block_start\ndata: {\"type\":\"content_block_start\",\"index\":0,\"content_block\":{\"type\":\"tool_use\",\"id\":\"toolu_1\",\"name\":\"weather\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"{\\\"city\\\":\"}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"input_json_delta\",\"partial_json\":\"\\\"Paris\\\"}\"}}",
+				"event: message_delta\ndata: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"tool_use\"},\"usage\":{\"output_tokens\":8}}",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+			},
+			toolCalls: []openai.ToolCall{{ID: "toolu_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}}},
+			finish:    openai.FinishReasonToolCalls,
+			usage:     openai.Usage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38},
+		},
+		{
+			name: "error event",
+			events: []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_3\",\"usage\":{\"input_tokens\":12}}}",
+				"event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}",
+			},
+			wantErr:  "overloaded_error: Overloaded",
+			upstream: true,
+		},
+		{
+			name: "stream cut before message_stop",
+			events: []string{
+				"event: message_start\ndata: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_4\",\"usage\":{\"input_tokens\":12}}}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Par\"}}",
+			},
+			wantErr: "unexpected EOF",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := sseUpstream(t, tt.events...)
+			client := NewChatCompletionClient(resty.New(), "anthropic", upstream.URL, WithAnthropicMessages())
+			reqCtx, recorder := newStreamTestContext()
+
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", streamRequest())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				var upstreamErr *UpstreamError
+				if tt.upstream && !errors.As(err, &upstreamErr) {
+					t.Fatalf("err = %v, want an UpstreamError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+
+			// Clients receive OpenAI chunks, never the Anthropic event names.
+			for _, line := range strings.Split(recorder.Body.String(), "\n") {
+				if line == "" {
+					continue
+				}
+				data, ok := strings.CutPrefix(line, "data: ")
+				if !ok {
+					t.Fatalf("unexpected line %q in the translated stream", line)
+				}
+				if data == "[DONE]" {
+					continue
+				}
+				var chunk openai.ChatCompletionStreamResponse
+				if err := json.Unmarshal([]byte(data), &chunk); err != nil || chunk.Object != "chat.completion.chunk" {
+					t.Fatalf("line %q is not an OpenAI chunk: %v", line, err)
+				}
+			}
+
+			message := result.Choices[0].Message
+			if message.Content != tt.content || message.ReasoningContent != tt.reasoning {
+				t.Fatalf("message = %+v, want content %q and reasoning %q", message, tt.content, tt.reasoning)
+			}
+			if len(message.ToolCalls) != len(tt.toolCalls) {
+				t.Fatalf("tool calls = %+v, want %+v", message.ToolCalls, tt.toolCalls)
+			}
+			for i, want := range tt.toolCalls {
+				got := message.ToolCalls[i]
+				if got.ID != want.ID || got.Function != want.Function {
+					t.Fatalf("tool call %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if !strings.Contains(recorder.Body.String(), `"finish_reason":"`+string(tt.finish)+`"`) {
+				t.Fatalf("stream has no %s finish reason: %s", tt.finish, recorder.Body.String())
+			}
+			if result.UsageEstimated || result.Usage != tt.usage {
+				t.Fatalf("usage = %+v (estimated %v), want %+v", result.Usage, result.UsageEstimated, tt.usage)
+			}
+		})
+	}
+}
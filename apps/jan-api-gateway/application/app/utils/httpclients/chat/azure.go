@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultAzureAPIVersion is sent to Azure OpenAI when the provider does not configure one.
+const DefaultAzureAPIVersion = "2024-10-21"
+
+// ClientOption customizes how a chat client builds upstream URLs.
+type ClientOption func(*endpointConfig)
+
+type endpointConfig struct {
+	azureAPIVersion       string
+	anthropicMessages     bool
+	bedrockRegion         string
+	geminiGenerateContent bool
+	openRouterPassthrough bool
+	pathPrefix            string
+	onUnsent              func()
+}
+
+// WithAzureDeployments routes requests through Azure OpenAI's deployment-scoped URLs,
+// /openai/deployments/{deployment}/chat/completions?api-version=..., using the model key
+// as the deployment name.
+func WithAzureDeployments(apiVersion string) ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.azureAPIVersion = strings.TrimSpace(apiVersion)
+		if cfg.azureAPIVersion == "" {
+			cfg.azureAPIVersion = DefaultAzureAPIVersion
+		}
+	}
+}
+
+func newEndpointConfig(opts []ClientOption) endpointConfig {
+	cfg := endpointConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return cfg
+}
+
+func (cfg endpointConfig) azure() bool {
+	return cfg.azureAPIVersion != ""
+}
+
+// azureEndpoint builds an Azure OpenAI URL below the resource's /openai root. Deployment-scoped
+// operations pass the deployment name; resource-level ones such as /models pass an empty one.
+func (cfg endpointConfig) azureEndpoint(baseURL, deployment, path string) string {
+	root := strings.TrimSuffix(baseURL, "/openai") + "/openai"
+	if deployment != "" {
+		root += "/deployments/" + url.PathEscape(deployment)
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return root + path + "?api-version=" + url.QueryEscape(cfg.azureAPIVersion)
+}
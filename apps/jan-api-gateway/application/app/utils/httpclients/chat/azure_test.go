@@ -0,0 +1,93 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+func TestAzureDeploymentURLs(t *testing.T) {
+	tests := []struct {
+		name           string
+		basePath       string
+		opts           []ClientOption
+		completionPath string
+		embeddingPath  string
+		modelsPath     string
+		apiVersion     string
+		keyHeader      string
+		keyValue       string
+	}{
+		{
+			name:           "openai",
+			basePath:       "/v1",
+			completionPath: "/v1/chat/completions",
+			embeddingPath:  "/v1/embeddings",
+			modelsPath:     "/v1/models",
+			keyHeader:      "Authorization",
+			keyValue:       "Bearer sk-test",
+		},
+		{
+			name:           "azure with the default api-version",
+			opts:           []ClientOption{WithAzureDeployments("")},
+			completionPath: "/openai/deployments/gpt-4o-prod/chat/completions",
+			embeddingPath:  "/openai/deployments/embed-prod/embeddings",
+			modelsPath:     "/openai/models",
+			apiVersion:     DefaultAzureAPIVersion,
+			keyHeader:      "api-key",
+			keyValue:       "sk-test",
+		},
+		{
+			name:           "azure base URL ending in /openai",
+			basePath:       "/openai",
+			opts:           []ClientOption{WithAzureDeployments("2025-01-01-preview")},
+			completionPath: "/openai/deployments/gpt-4o-prod/chat/completions",
+			embeddingPath:  "/openai/deployments/embed-prod/embeddings",
+			modelsPath:     "/openai/models",
+			apiVersion:     "2025-01-01-preview",
+			keyHeader:      "api-key",
+			keyValue:       "sk-test",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream, calls := recordingUpstream(t, `{"object":"list","data":[]}`)
+			baseURL := upstream.URL + tt.basePath
+			ctx := context.Background()
+
+			completions := NewChatCompletionClient(resty.New(), "upstream", baseURL, tt.opts...)
+			if _, err := completions.CreateChatCompletion(ctx, "sk-test", openai.ChatCompletionRequest{
+				Model:    "gpt-4o-prod",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			}); err != nil {
+				t.Fatalf("CreateChatCompletion: %v", err)
+			}
+			if _, err := completions.CreateEmbeddings(ctx, "sk-test", openai.EmbeddingRequest{Model: "embed-prod", Input: "hi"}); err != nil {
+				t.Fatalf("CreateEmbeddings: %v", err)
+			}
+			if _, err := NewChatModelClient(resty.New(), "upstream", baseURL, tt.opts...).ListModels(ctx); err != nil {
+				t.Fatalf("ListModels: %v", err)
+			}
+
+			if len(*calls) != 3 {
+				t.Fatalf("upstream received %d requests, want 3", len(*calls))
+			}
+			for i, path := range []string{tt.completionPath, tt.embeddingPath, tt.modelsPath} {
+				call := (*calls)[i]
+				if call.Path != path {
+					t.Errorf("request %d went to %s, want %s", i, call.Path, path)
+				}
+				if got := call.Query.Get("api-version"); got != tt.apiVersion {
+					t.Errorf("request %d api-version = %q, want %q", i, got, tt.apiVersion)
+				}
+			}
+			for i, call := range (*calls)[:2] {
+				if got := call.Header.Get(tt.keyHeader); got != tt.keyValue {
+					t.Errorf("request %d %s = %q, want %q", i, tt.keyHeader, got, tt.keyValue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// bedrockAnthropicVersion is the anthropic_version Bedrock requires in Claude request bodies.
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// WithBedrockInvoke translates requests to AWS Bedrock's InvokeModel and
+// InvokeModelWithResponseStream operations, POST /model/{model}/invoke, in the given region. Only
+// Anthropic Claude models are supported; their bodies use the Messages API shape.
+func WithBedrockInvoke(region string) ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.bedrockRegion = strings.TrimSpace(region)
+	}
+}
+
+func (cfg endpointConfig) bedrock() bool {
+	return cfg.bedrockRegion != ""
+}
+
+// bedrockAnthropicRequest is a Messages API request as Bedrock takes it: the model is part of the
+// URL and streaming is selected by the operation, so neither is in the body.
+type bedrockAnthropicRequest struct {
+	AnthropicVersion string                    `json:"anthropic_version"`
+	System           string                    `json:"system,omitempty"`
+	Messages         []anthropicMessage        `json:"messages"`
+	MaxTokens        int                       `json:"max_tokens"`
+	Temperature      *float32                  `json:"temperature,omitempty"`
+	TopP             *float32                  `json:"top_p,omitempty"`
+	StopSequences    []string                  `json:"stop_sequences,omitempty"`
+	Tools            []anthropicTool           `json:"tools,omitempty"`
+	ToolChoice       *anthropicToolChoice      `json:"tool_choice,omitempty"`
+	Metadata         *anthropicRequestMetadata `json:"metadata,omitempty"`
+}
+
+func toBedrockRequest(request openai.ChatCompletionRequest) bedrockAnthropicRequest {
+	anthropicRequest := toAnthropicRequest(request)
+	return bedrockAnthropicRequest{
+		AnthropicVersion: bedrockAnthropicVersion,
+		System:           anthropicRequest.System,
+		Messages:         anthropicRequest.Messages,
+		MaxTokens:        anthropicRequest.MaxTokens,
+		Temperature:      anthropicRequest.Temperature,
+		TopP:             anthropicRequest.TopP,
+		StopSequences:    anthropicRequest.StopSequences,
+		Tools:            anthropicRequest.Tools,
+		ToolChoice:       anthropicRequest.ToolChoice,
+		Metadata:         anthropicRequest.Metadata,
+	}
+}
+
+// bedrockSupportsModel reports whether the model is an Anthropic Claude model, addressed by its
+// model ID, an inference profile such as us.anthropic.claude-... or an ARN.
+func bedrockSupportsModel(model string) bool {
+	return strings.Contains(model, "anthropic.")
+}
+
+func (c *ChatCompletionClient) checkBedrockModel(model string) error {
+	if !bedrockSupportsModel(model) {
+		return fmt.Errorf("%s: model %q is not supported on AWS Bedrock, only Anthropic Claude models are", c.name, model)
+	}
+	return nil
+}
+
+// bedrockModelEndpoint builds the URL of an invoke operation. The base URL defaults to the
+// regional runtime endpoint, and the model ID is fully escaped because it may contain colons.
+func (c *ChatCompletionClient) bedrockModelEndpoint(model, operation string) string {
+	baseURL := c.endpoint.prefixedBaseURL(c.baseURL)
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", c.endpoint.bedrockRegion)
+	}
+	return baseURL + "/model/" + strings.ReplaceAll(url.QueryEscape(model), "+", "%20") + "/" + operation
+}
+
+func (c *ChatCompletionClient) createBedrockMessage(ctx context.Context, apiKey string, request openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	if err := c.checkBedrockModel(request.Model); err != nil {
+		return nil, c.unsent(err)
+	}
+	var respBody anthropicMessagesResponse
+	resp, err := c.postWithRateLimitRetry(ctx, func() (*resty.Response, error) {
+		return c.prepareRequest(ctx, apiKey).
+			SetBody(toBedrockRequest(request)).
+			SetResult(&respBody).
+			Post(c.bedrockModelEndpoint(request.Model, "invoke"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, c.errorFromResponse(resp, "request failed")
+	}
+	if respBody.Model == "" {
+		respBody.Model = request.Model
+	}
+	return fromAnthropicResponse(respBody), nil
+}
+
+type bedrockModelsResponse struct {
+	ModelSummaries []bedrockModelSummary `json:"modelSummaries"`
+}
+
+type bedrockModelSummary struct {
+	ModelID                    string   `json:"modelId"`
+	ModelName                  string   `json:"modelName"`
+	ProviderName               string   `json:"providerName"`
+	InputModalities            []string `json:"inputModalities"`
+	OutputModalities           []string `json:"outputModalities"`
+	ResponseStreamingSupported bool     `json:"responseStreamingSupported"`
+	InferenceTypesSupported    []string `json:"inferenceTypesSupported"`
+}
+
+// listBedrockModels lists the Anthropic foundation models of the region through the Bedrock
+// control plane, as those are the only models the client can invoke.
+func (c *ChatModelClient) listBedrockModels(ctx context.Context) (*ModelsResponse, error) {
+	var page bedrockModelsResponse
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParam("byProvider", "anthropic").
+		SetResult(&page).
+		Get(fmt.Sprintf("https://bedrock.%s.amazonaws.com/foundation-models", c.endpoint.bedrockRegion))
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, c.errorFromResponse(resp, "list models request failed")
+	}
+	result := &ModelsResponse{Object: "list", Data: []Model{}}
+	for _, model := range page.ModelSummaries {
+		if !bedrockSupportsModel(model.ModelID) {
+			continue
+		}
+		displayName := model.ModelName
+		if displayName == "" {
+			displayName = model.ModelID
+		}
+		result.Data = append(result.Data, Model{
+			ID:          model.ModelID,
+			Object:      "model",
+			OwnedBy:     "anthropic",
+			DisplayName: displayName,
+			Name:        displayName,
+			Raw: map[string]any{
+				"id":                model.ModelID,
+				"input_modalities":  model.InputModalities,
+				"output_modalities": model.OutputModalities,
+				"streaming":         model.ResponseStreamingSupported,
+				"inference_types":   model.InferenceTypesSupported,
+			},
+		})
+	}
+	return result, nil
+}
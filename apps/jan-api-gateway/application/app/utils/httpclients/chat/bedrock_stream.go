@@ -0,0 +1,174 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	// eventStreamPreludeLength covers the total length, headers length and prelude CRC.
+	eventStreamPreludeLength = 12
+	eventStreamMaxMessage    = 16 << 20
+)
+
+type bedrockStreamChunk struct {
+	Bytes string `json:"bytes"`
+}
+
+type bedrockStreamException struct {
+	Message string `json:"message"`
+}
+
+// bedrockEventStreamReader decodes the binary AWS event stream of InvokeModelWithResponseStream.
+// Each chunk event carries one base64 encoded Anthropic stream event, which is re-emitted as an SSE
+// data line so anthropicStreamReader can translate Bedrock streams like native Anthropic ones.
+type bedrockEventStreamReader struct {
+	body    io.ReadCloser
+	name    string
+	pending bytes.Buffer
+}
+
+func newBedrockEventStreamReader(body io.ReadCloser, name string) *bedrockEventStreamReader {
+	return &bedrockEventStreamReader{body: body, name: name}
+}
+
+func (r *bedrockEventStreamReader) Read(p []byte) (int, error) {
+	for r.pending.Len() == 0 {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	return r.pending.Read(p)
+}
+
+func (r *bedrockEventStreamReader) Close() error {
+	return r.body.Close()
+}
+
+// next decodes one event stream message. Exceptions sent mid-stream are returned as errors.
+func (r *bedrockEventStreamReader) next() error {
+	headers, payload, err := r.readMessage()
+	if err != nil {
+		return err
+	}
+	switch headers[":message-type"] {
+	case "event":
+		if headers[":event-type"] != "chunk" {
+			return nil
+		}
+		var chunk bedrockStreamChunk
+		if err := json.Unmarshal(payload, &chunk); err != nil {
+			return fmt.Errorf("%s: failed to parse stream chunk: %w", r.name, err)
+		}
+		event, err := base64.StdEncoding.DecodeString(chunk.Bytes)
+		if err != nil {
+			return fmt.Errorf("%s: failed to decode stream chunk: %w", r.name, err)
+		}
+		r.pending.WriteString(dataPrefix)
+		r.pending.Write(event)
+		r.pending.WriteString(newlineChar + newlineChar)
+		return nil
+	case "exception", "error":
+		errorType := headers[":exception-type"]
+		if errorType == "" {
+			errorType = headers[":error-code"]
+		}
+		var exception bedrockStreamException
+		_ = json.Unmarshal(payload, &exception)
+		if exception.Message == "" {
+			exception.Message = headers[":error-message"]
+		}
+		return &UpstreamError{
+			Type:    errorType,
+			Message: exception.Message,
+			summary: fmt.Sprintf("%s: streaming error event: %s: %s", r.name, errorType, exception.Message),
+		}
+	}
+	return nil
+}
+
+// readMessage reads one message: a prelude with the total and headers lengths and its CRC, the
+// headers, the payload and a CRC of the whole message.
+func (r *bedrockEventStreamReader) readMessage() (map[string]string, []byte, error) {
+	prelude := make([]byte, eventStreamPreludeLength)
+	if _, err := io.ReadFull(r.body, prelude); err != nil {
+		if err == io.EOF {
+			return nil, nil, io.EOF
+		}
+		return nil, nil, fmt.Errorf("%s: failed to read stream message: %w", r.name, err)
+	}
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[0:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, nil, fmt.Errorf("%s: stream message prelude checksum mismatch", r.name)
+	}
+	if totalLength > eventStreamMaxMessage || uint64(totalLength) < uint64(eventStreamPreludeLength)+uint64(headersLength)+4 {
+		return nil, nil, fmt.Errorf("%s: invalid stream message length %d", r.name, totalLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude)
+	if _, err := io.ReadFull(r.body, message[eventStreamPreludeLength:]); err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to read stream message: %w", r.name, err)
+	}
+	if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+		return nil, nil, fmt.Errorf("%s: stream message checksum mismatch", r.name)
+	}
+
+	headersEnd := eventStreamPreludeLength + headersLength
+	headers, err := parseEventStreamHeaders(message[eventStreamPreludeLength:headersEnd])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", r.name, err)
+	}
+	return headers, message[headersEnd : totalLength-4], nil
+}
+
+// parseEventStreamHeaders returns the string headers of a message; headers of other types are
+// skipped.
+func parseEventStreamHeaders(data []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, fmt.Errorf("truncated stream message header")
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // boolean true and false carry no value
+		case 2:
+			size = 1
+		case 3:
+			size = 2
+		case 4:
+			size = 4
+		case 5, 8:
+			size = 8
+		case 9:
+			size = 16
+		case 6, 7:
+			if len(data) < 2 {
+				return nil, fmt.Errorf("truncated stream message header")
+			}
+			size = 2 + int(binary.BigEndian.Uint16(data[:2]))
+		default:
+			return nil, fmt.Errorf("unknown stream message header type %d", valueType)
+		}
+		if len(data) < size {
+			return nil, fmt.Errorf("truncated stream message header")
+		}
+		if valueType == 7 {
+			headers[name] = string(data[2:size])
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
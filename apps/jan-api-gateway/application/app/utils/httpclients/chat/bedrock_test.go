@@ -0,0 +1,219 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+const bedrockClaude = "anthropic.claude-3-haiku-20240307-v1:0"
+
+// eventStreamMessage encodes an AWS event stream message with string headers.
+func eventStreamMessage(headers map[string]string, payload []byte) []byte {
+	var encodedHeaders bytes.Buffer
+	for name, value := range headers {
+		encodedHeaders.WriteByte(byte(len(name)))
+		encodedHeaders.WriteString(name)
+		encodedHeaders.WriteByte(7)
+		_ = binary.Write(&encodedHeaders, binary.BigEndian, uint16(len(value)))
+		encodedHeaders.WriteString(value)
+	}
+	totalLength := eventStreamPreludeLength + encodedHeaders.Len() + len(payload) + 4
+	message := make([]byte, 0, totalLength)
+	message = binary.BigEndian.AppendUint32(message, uint32(totalLength))
+	message = binary.BigEndian.AppendUint32(message, uint32(encodedHeaders.Len()))
+	message = binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+	message = append(message, encodedHeaders.Bytes()...)
+	message = append(message, payload...)
+	return binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+}
+
+// bedrockChunk wraps an Anthropic stream event the way InvokeModelWithResponseStream sends it.
+func bedrockChunk(event string) []byte {
+	payload, _ := json.Marshal(bedrockStreamChunk{Bytes: base64.StdEncoding.EncodeToString([]byte(event))})
+	return eventStreamMessage(map[string]string{
+		":message-type": "event",
+		":event-type":   "chunk",
+		":content-type": "application/json",
+	}, payload)
+}
+
+// corruptMessage flips a payload byte, so the message fails its checksum.
+func corruptMessage(message []byte) []byte {
+	message[len(message)-5] ^= 0xff
+	return message
+}
+
+// bedrockStreamUpstream serves the given event stream messages as a Bedrock streaming upstream.
+func bedrockStreamUpstream(t *testing.T, messages ...[]byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		for _, message := range messages {
+			_, _ = w.Write(message)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBedrockInvoke(t *testing.T) {
+	upstream, calls := recordingUpstream(t, `{
+		"id": "msg_1",
+		"content": [{"type": "text", "text": "Paris."}],
+		"stop_reason": "end_turn",
+		"usage": {"input_tokens": 12, "output_tokens": 2}
+	}`)
+	client := NewChatCompletionClient(resty.New(), "bedrock", upstream.URL, WithBedrockInvoke("us-east-1"))
+
+	response, err := client.CreateChatCompletion(context.Background(), "", openai.ChatCompletionRequest{
+		Model: bedrockClaude,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Answer briefly."},
+			{Role: openai.ChatMessageRoleUser, Content: "Capital of France?"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+
+	if len(*calls) != 1 {
+		t.Fatalf("upstream received %d requests, want 1", len(*calls))
+	}
+	call := (*calls)[0]
+	if want := "/model/" + bedrockClaude + "/invoke"; call.Path != want {
+		t.Fatalf("request went to %s, want %s", call.Path, want)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(call.Body, &body); err != nil {
+		t.Fatalf("invalid request body %s: %v", call.Body, err)
+	}
+	if got := string(body["anthropic_version"]); got != `"`+bedrockAnthropicVersion+`"` {
+		t.Fatalf("anthropic_version = %s, want %q", got, bedrockAnthropicVersion)
+	}
+	if got := string(body["system"]); got != `"Answer briefly."` {
+		t.Fatalf("system = %s, want the system message", got)
+	}
+	for _, field := range []string{"model", "stream"} {
+		if _, ok := body[field]; ok {
+			t.Fatalf("request body has %s, which Bedrock takes from the URL: %s", field, call.Body)
+		}
+	}
+
+	if response.Model != bedrockClaude {
+		t.Fatalf("model = %q, want the requested model", response.Model)
+	}
+	choice := response.Choices[0]
+	if choice.Message.Content != "Paris." || choice.FinishReason != openai.FinishReasonStop {
+		t.Fatalf("choice = %+v, want Paris. with stop", choice)
+	}
+	if response.Usage.TotalTokens != 14 {
+		t.Fatalf("usage = %+v, want 14 total tokens", response.Usage)
+	}
+}
+
+func TestBedrockRejectsOtherModels(t *testing.T) {
+	upstream, calls := recordingUpstream(t, `{}`)
+	client := NewChatCompletionClient(resty.New(), "bedrock", upstream.URL, WithBedrockInvoke("us-east-1"))
+
+	request := streamRequest()
+	request.Model = "meta.llama3-70b-instruct-v1:0"
+	if _, err := client.CreateChatCompletion(context.Background(), "", request); err == nil {
+		t.Fatal("CreateChatCompletion succeeded for a Llama model")
+	}
+	reqCtx, _ := newStreamTestContext()
+	if _, err := client.StreamChatCompletionToContext(reqCtx, "", request); err == nil {
+		t.Fatal("StreamChatCompletionToContext succeeded for a Llama model")
+	}
+	if len(*calls) != 0 {
+		t.Fatalf("upstream received %d requests, want none", len(*calls))
+	}
+}
+
+func TestBedrockStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages [][]byte
+		content  string
+		wantErr  string
+		upstream bool
+	}{
+		{
+			name: "chunks",
+			messages: [][]byte{
+				bedrockChunk(`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":12}}}`),
+				bedrockChunk(`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`),
+				bedrockChunk(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Par"}}`),
+				eventStreamMessage(map[string]string{":message-type": "event", ":event-type": "metadata"}, []byte(`{}`)),
+				bedrockChunk(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"is."}}`),
+				bedrockChunk(`{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`),
+				bedrockChunk(`{"type":"message_stop"}`),
+			},
+			content: "Paris.",
+		},
+		{
+			name: "exception",
+			messages: [][]byte{
+				bedrockChunk(`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":12}}}`),
+				eventStreamMessage(map[string]string{
+					":message-type":   "exception",
+					":exception-type": "throttlingException",
+				}, []byte(`{"message":"Too many requests"}`)),
+			},
+			wantErr:  "throttlingException: Too many requests",
+			upstream: true,
+		},
+		{
+			name: "corrupt message",
+			messages: [][]byte{
+				corruptMessage(bedrockChunk(`{"type":"message_start","message":{"id":"msg_1"}}`)),
+			},
+			wantErr: "checksum mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := bedrockStreamUpstream(t, tt.messages...)
+			client := NewChatCompletionClient(resty.New(), "bedrock", upstream.URL, WithBedrockInvoke("us-east-1"))
+			request := streamRequest()
+			request.Model = bedrockClaude
+			reqCtx, recorder := newStreamTestContext()
+
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", request)
+			if tt.wantErr != "" {
+				var upstreamErr *UpstreamError
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if tt.upstream && !errors.As(err, &upstreamErr) {
+					t.Fatalf("err = %v, want an UpstreamError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+			if got := result.Choices[0].Message.Content; got != tt.content {
+				t.Fatalf("content = %q, want %q", got, tt.content)
+			}
+			if result.UsageEstimated || result.Usage.PromptTokens != 12 || result.Usage.CompletionTokens != 2 {
+				t.Fatalf("usage = %+v (estimated %v), want the upstream usage", result.Usage, result.UsageEstimated)
+			}
+			if !strings.HasSuffix(strings.TrimSpace(recorder.Body.String()), "data: [DONE]") {
+				t.Fatalf("stream does not end with [DONE]: %q", recorder.Body.String())
+			}
+		})
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/gin-gonic/gin"
 	openai "github.com/sashabaranov/go-openai"
 	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/tokenizer"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
 	"resty.dev/v3"
 )
 
@@ -46,10 +48,22 @@ func WithAcceptEncodingIdentity() StreamOption {
 	return WithHeader("Accept-Encoding", "identity")
 }
 
+// StreamCompletionResult is the response accumulated from a streamed completion.
+type StreamCompletionResult struct {
+	openai.ChatCompletionResponse
+	// UsageEstimated is set when the upstream never emitted a usage chunk and the
+	// token counts were approximated from the request and the streamed deltas.
+	UsageEstimated bool
+	// JSONValidationError is set when JSON stream validation is enabled and the assembled
+	// content of a json_object stream did not parse. The client has received an error event.
+	JSONValidationError error
+}
+
 type ChatCompletionClient struct {
-	client  *resty.Client
-	baseURL string
-	name    string
+	client   *resty.Client
+	baseURL  string
+	name     string
+	endpoint endpointConfig
 }
 
 type functionCallAccumulator struct {
@@ -69,25 +83,57 @@ type toolCallAccumulator struct {
 	Complete bool
 }
 
-func NewChatCompletionClient(client *resty.Client, name, baseURL string) *ChatCompletionClient {
+func NewChatCompletionClient(client *resty.Client, name, baseURL string, opts ...ClientOption) *ChatCompletionClient {
 	return &ChatCompletionClient{
-		client:  client,
-		baseURL: normalizeBaseURL(baseURL),
-		name:    name,
+		client:   client,
+		baseURL:  normalizeBaseURL(baseURL),
+		name:     name,
+		endpoint: newEndpointConfig(opts),
 	}
 }
 
 func (c *ChatCompletionClient) CreateChatCompletion(ctx context.Context, apiKey string, request openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	if c.endpoint.anthropic() {
+		return c.createAnthropicMessage(ctx, apiKey, request)
+	}
+	if c.endpoint.bedrock() {
+		return c.createBedrockMessage(ctx, apiKey, request)
+	}
+	if c.endpoint.gemini() {
+		return c.createGeminiContent(ctx, apiKey, request)
+	}
+	body, err := c.completionBody(ctx, request)
+	if err != nil {
+		return nil, c.unsent(err)
+	}
 	var respBody openai.ChatCompletionResponse
+	resp, err := c.postWithRateLimitRetry(ctx, func() (*resty.Response, error) {
+		return c.prepareRequest(ctx, apiKey).
+			SetBody(body).
+			SetResult(&respBody).
+			Post(c.modelEndpoint("/chat/completions", request.Model))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, c.errorFromResponse(resp, "request failed")
+	}
+	return &respBody, nil
+}
+
+// CreateEmbeddings proxies an embeddings request to the provider's /embeddings endpoint.
+func (c *ChatCompletionClient) CreateEmbeddings(ctx context.Context, apiKey string, request openai.EmbeddingRequest) (*openai.EmbeddingResponse, error) {
+	var respBody openai.EmbeddingResponse
 	resp, err := c.prepareRequest(ctx, apiKey).
 		SetBody(request).
 		SetResult(&respBody).
-		Post(c.endpoint("/chat/completions"))
+		Post(c.modelEndpoint("/embeddings", string(request.Model)))
 	if err != nil {
 		return nil, err
 	}
 	if resp.IsError() {
-		return nil, c.errorFromResponse(resp, "request failed")
+		return nil, c.errorFromResponse(resp, "embeddings request failed")
 	}
 	return &respBody, nil
 }
@@ -99,8 +145,10 @@ func (c *ChatCompletionClient) CreateChatCompletionStream(ctx context.Context, a
 	}
 
 	reader, writer := io.Pipe()
+	stopAbort := abortOnDone(ctx, resp)
 
 	go func() {
+		defer stopAbort()
 		defer func() {
 			if closeErr := resp.RawResponse.Body.Close(); closeErr != nil {
 				logger.GetLogger().Errorf("%s: unable to close response body: %v", c.name, closeErr)
@@ -119,8 +167,13 @@ func (c *ChatCompletionClient) CreateChatCompletionStream(ctx context.Context, a
 
 // StreamChatCompletionToContext streams the completion to the provided Gin context while
 // accumulating the complete response, mirroring the SSE handling found in the conversation
-// completion flow.
-func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context, apiKey string, request openai.ChatCompletionRequest, opts ...StreamOption) (*openai.ChatCompletionResponse, error) {
+// completion flow. When the upstream omits usage, the usage is estimated and, if the caller
+// asked for it via stream_options.include_usage, emitted as a final chunk before [DONE].
+// json_object streams are validated at the end when CHAT_STREAM_JSON_VALIDATION is set. While
+// no upstream chunk has arrived, keep-alive comments are sent every CHAT_STREAM_HEARTBEAT_SECONDS.
+// The SSE headers are only written once the upstream accepted the request, so an upstream
+// rejection still reaches the client with its own status.
+func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context, apiKey string, request openai.ChatCompletionRequest, opts ...StreamOption) (*StreamCompletionResult, error) {
 	if reqCtx == nil {
 		return nil, fmt.Errorf("%s: streaming request failed: nil gin context", c.name)
 	}
@@ -128,30 +181,63 @@ func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context
 	ctx, cancel := context.WithTimeout(reqCtx.Request.Context(), requestTimeout)
 	defer cancel()
 
-	c.SetupSSEHeaders(reqCtx)
-
 	dataChan := make(chan string, channelBufferSize)
 	errChan := make(chan error, errorBufferSize)
+	connectedChan := make(chan struct{})
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	go c.streamResponseToChannel(ctx, apiKey, request, dataChan, errChan, &wg, opts)
+	go c.streamResponseToChannel(ctx, apiKey, request, dataChan, errChan, connectedChan, &wg, opts)
 
 	var contentBuilder strings.Builder
 	var reasoningBuilder strings.Builder
 	functionCallAccumulator := make(map[int]*functionCallAccumulator)
 	toolCallAccumulator := make(map[int]*toolCallAccumulator)
+	var upstreamUsage *openai.Usage
+	deltaCount := 0
+	doneLine := ""
 
 	streamingComplete := false
 
+	var heartbeat <-chan time.Time
+	var heartbeatTicker *time.Ticker
+	defer func() {
+		if heartbeatTicker != nil {
+			heartbeatTicker.Stop()
+		}
+	}()
+	connected := (<-chan struct{})(connectedChan)
+
 	for !streamingComplete {
 		select {
+		case <-connected:
+			// Heartbeats commit the response status, so they wait for the upstream to accept
+			connected = nil
+			if interval := streamHeartbeatInterval(); interval > 0 {
+				heartbeatTicker = time.NewTicker(interval)
+				heartbeat = heartbeatTicker.C
+			}
+
 		case line, ok := <-dataChan:
 			if !ok {
 				streamingComplete = true
 				break
 			}
+			// Upstream data now keeps the connection busy.
+			connected = nil
+			if heartbeatTicker != nil {
+				heartbeatTicker.Stop()
+				heartbeat = nil
+			}
+
+			// Hold back [DONE] so an estimated usage chunk can still be written ahead of it.
+			if data, found := strings.CutPrefix(line, dataPrefix); found && data == doneMarker {
+				doneLine = line
+				streamingComplete = true
+				cancel()
+				break
+			}
 
 			if err := c.writeSSELine(reqCtx, line); err != nil {
 				cancel()
@@ -160,13 +246,11 @@ func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context
 			}
 
 			if data, found := strings.CutPrefix(line, dataPrefix); found {
-				if data == doneMarker {
-					streamingComplete = true
-					cancel()
-					break
-				}
+				contentChunk, reasoningChunk, functionCallChunk, toolCallChunk, usageChunk := c.processStreamChunkForChannel(data)
 
-				contentChunk, reasoningChunk, functionCallChunk, toolCallChunk := c.processStreamChunkForChannel(data)
+				if contentChunk != "" || reasoningChunk != "" {
+					deltaCount++
+				}
 
 				if contentChunk != "" {
 					contentBuilder.WriteString(contentChunk)
@@ -176,6 +260,10 @@ func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context
 					reasoningBuilder.WriteString(reasoningChunk)
 				}
 
+				if usageChunk != nil {
+					upstreamUsage = usageChunk
+				}
+
 				if functionCallChunk != nil {
 					c.handleStreamingFunctionCall(functionCallChunk, functionCallAccumulator)
 				}
@@ -185,6 +273,13 @@ func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context
 				}
 			}
 
+		case <-heartbeat:
+			if err := c.writeHeartbeat(reqCtx); err != nil {
+				cancel()
+				wg.Wait()
+				return nil, fmt.Errorf("%s: unable to write heartbeat: %w", c.name, err)
+			}
+
 		case err, ok := <-errChan:
 			if ok && err != nil {
 				cancel()
@@ -218,7 +313,135 @@ func (c *ChatCompletionClient) StreamChatCompletionToContext(reqCtx *gin.Context
 		request,
 	)
 
-	return &response, nil
+	result := &StreamCompletionResult{ChatCompletionResponse: response}
+	if upstreamUsage != nil {
+		result.Usage = *upstreamUsage
+	} else {
+		result.Usage = c.estimateStreamUsage(request, response, deltaCount)
+		result.UsageEstimated = true
+		if request.StreamOptions != nil && request.StreamOptions.IncludeUsage {
+			if err := c.writeUsageChunk(reqCtx, request.Model, result.Usage); err != nil {
+				return nil, fmt.Errorf("%s: unable to write usage chunk: %w", c.name, err)
+			}
+		}
+	}
+
+	if jsonStreamValidationEnabled(request) {
+		content := ""
+		if len(response.Choices) > 0 {
+			content = response.Choices[0].Message.Content
+		}
+		parsed, validationErr := validateJSONContent(content)
+		if validationErr != nil {
+			result.JSONValidationError = validationErr
+			if err := c.writeSSEEvent(reqCtx, "error", jsonStreamError{Error: jsonStreamErrorBody{
+				Message: validationErr.Error(),
+				Type:    "invalid_json_output",
+			}}); err != nil {
+				return nil, fmt.Errorf("%s: unable to write error event: %w", c.name, err)
+			}
+			return result, nil
+		}
+		if environment_variables.EnvironmentVariables.CHAT_STREAM_JSON_EMIT_OBJECT {
+			if err := c.writeSSEEvent(reqCtx, "json_object", parsed); err != nil {
+				return nil, fmt.Errorf("%s: unable to write json object event: %w", c.name, err)
+			}
+		}
+	}
+
+	if doneLine != "" {
+		if err := c.writeSSELine(reqCtx, doneLine); err != nil {
+			return nil, fmt.Errorf("%s: unable to write SSE line: %w", c.name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// estimateStreamUsage approximates usage for streams that never reported it: prompt tokens
+// come from the request and completion tokens from the number of streamed deltas, falling
+// back to the accumulated message when nothing but tool calls was streamed.
+func (c *ChatCompletionClient) estimateStreamUsage(request openai.ChatCompletionRequest, response openai.ChatCompletionResponse, deltaCount int) openai.Usage {
+	promptTokens := tokenizer.EstimateTokens(request.Messages)
+	completionTokens := deltaCount
+	if completionTokens == 0 && len(response.Choices) > 0 {
+		completionTokens = tokenizer.EstimateTokens([]openai.ChatCompletionMessage{response.Choices[0].Message})
+	}
+	return openai.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+}
+
+func (c *ChatCompletionClient) writeUsageChunk(reqCtx *gin.Context, model string, usage openai.Usage) error {
+	chunk := openai.ChatCompletionStreamResponse{
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []openai.ChatCompletionStreamChoice{},
+		Usage:   &usage,
+	}
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	if err := c.writeSSELine(reqCtx, dataPrefix+string(data)); err != nil {
+		return err
+	}
+	return c.writeSSELine(reqCtx, "")
+}
+
+type jsonStreamError struct {
+	Error jsonStreamErrorBody `json:"error"`
+}
+
+type jsonStreamErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// jsonStreamValidationEnabled reports whether a stream requested json_object output and the
+// deployment opted in to validating it.
+func jsonStreamValidationEnabled(request openai.ChatCompletionRequest) bool {
+	if !environment_variables.EnvironmentVariables.CHAT_STREAM_JSON_VALIDATION {
+		return false
+	}
+	return request.ResponseFormat != nil && request.ResponseFormat.Type == openai.ChatCompletionResponseFormatTypeJSONObject
+}
+
+// validateJSONContent parses the assembled content of a json_object stream.
+func validateJSONContent(content string) (map[string]any, error) {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return nil, fmt.Errorf("response_format json_object produced empty content")
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return nil, fmt.Errorf("response_format json_object produced invalid JSON: %w", err)
+	}
+	return parsed, nil
+}
+
+func (c *ChatCompletionClient) writeSSEEvent(reqCtx *gin.Context, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if err := c.writeSSELine(reqCtx, "event: "+event); err != nil {
+		return err
+	}
+	if err := c.writeSSELine(reqCtx, dataPrefix+string(data)); err != nil {
+		return err
+	}
+	return c.writeSSELine(reqCtx, "")
+}
+
+// ensureSSEHeaders writes the SSE headers before the first byte of a stream.
+func (c *ChatCompletionClient) ensureSSEHeaders(reqCtx *gin.Context) {
+	if !reqCtx.Writer.Written() {
+		c.SetupSSEHeaders(reqCtx)
+	}
 }
 
 // SetupSSEHeaders configures the Gin context for Server-Sent Events responses.
@@ -240,46 +463,74 @@ func (c *ChatCompletionClient) prepareRequest(ctx context.Context, apiKey string
 	req := c.client.R().SetContext(ctx)
 	req.SetHeader("Content-Type", "application/json")
 	if strings.TrimSpace(apiKey) != "" {
-		req.SetHeader("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+		// A per-request key replaces the stored one, so it goes in the header the upstream reads
+		switch {
+		case c.endpoint.azure():
+			req.SetHeader("api-key", apiKey)
+		case c.endpoint.anthropicMessages:
+			req.SetHeader("x-api-key", apiKey)
+		case c.endpoint.geminiGenerateContent:
+			req.SetHeader("x-goog-api-key", apiKey)
+		default:
+			req.SetHeader("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+		}
 	}
 	return req
 }
 
-func (c *ChatCompletionClient) endpoint(path string) string {
+// modelEndpoint resolves the URL of a model-scoped operation. Azure OpenAI addresses the
+// model through its deployment; every other provider takes the model in the request body.
+func (c *ChatCompletionClient) modelEndpoint(path, model string) string {
+	if c.endpoint.azure() {
+		return c.endpoint.azureEndpoint(c.baseURL, model, path)
+	}
+	return c.endpointURL(path)
+}
+
+func (c *ChatCompletionClient) endpointURL(path string) string {
+	baseURL := c.endpoint.prefixedBaseURL(c.baseURL)
 	if path == "" {
-		return c.baseURL
+		return baseURL
 	}
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		return path
 	}
-	if c.baseURL == "" {
+	if baseURL == "" {
 		return path
 	}
 	if strings.HasPrefix(path, "/") {
-		return c.baseURL + path
+		return baseURL + path
 	}
-	return c.baseURL + "/" + path
+	return baseURL + "/" + path
 }
 
 func (c *ChatCompletionClient) errorFromResponse(resp *resty.Response, message string) error {
-	if resp == nil || resp.RawResponse == nil || resp.RawResponse.Body == nil {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	defer resp.RawResponse.Body.Close()
-	body, err := io.ReadAll(resp.RawResponse.Body)
-	if err != nil {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	trimmed := strings.TrimSpace(string(body))
-	if trimmed == "" {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	return fmt.Errorf("%s: %s with status %d: %s", c.name, message, statusCode(resp), trimmed)
+	return newUpstreamError(c.name, message, resp)
 }
 
 func (c *ChatCompletionClient) doStreamingRequest(ctx context.Context, apiKey string, request openai.ChatCompletionRequest, opts ...StreamOption) (*resty.Response, error) {
+	body, err := c.completionBody(ctx, request)
+	if err != nil {
+		return nil, c.unsent(err)
+	}
+	url := c.modelEndpoint("/chat/completions", request.Model)
+	if c.endpoint.anthropic() {
+		body = toAnthropicRequest(request)
+		url = c.endpointURL("/messages")
+	}
+	if c.endpoint.gemini() {
+		body = toGeminiRequest(request)
+		url = c.geminiModelEndpoint(request.Model, "streamGenerateContent")
+	}
+	if c.endpoint.bedrock() {
+		if err := c.checkBedrockModel(request.Model); err != nil {
+			return nil, c.unsent(err)
+		}
+		body = toBedrockRequest(request)
+		url = c.bedrockModelEndpoint(request.Model, "invoke-with-response-stream")
+	}
 	req := c.prepareRequest(ctx, apiKey).
-		SetBody(request).
+		SetBody(body).
 		SetDoNotParseResponse(true)
 
 	for _, opt := range opts {
@@ -293,7 +544,7 @@ func (c *ChatCompletionClient) doStreamingRequest(ctx context.Context, apiKey st
 		req.SetHeader("Accept-Encoding", "identity")
 	}
 
-	resp, err := req.Post(c.endpoint("/chat/completions"))
+	resp, err := req.Post(url)
 	if err != nil {
 		return nil, err
 	}
@@ -303,11 +554,20 @@ func (c *ChatCompletionClient) doStreamingRequest(ctx context.Context, apiKey st
 	if resp.RawResponse == nil || resp.RawResponse.Body == nil {
 		return nil, fmt.Errorf("%s: streaming request failed: empty response body", c.name)
 	}
+	if c.endpoint.anthropic() {
+		resp.RawResponse.Body = newAnthropicStreamReader(resp.RawResponse.Body, c.name, request.Model)
+	}
+	if c.endpoint.gemini() {
+		resp.RawResponse.Body = newGeminiStreamReader(resp.RawResponse.Body, c.name, request.Model)
+	}
+	if c.endpoint.bedrock() {
+		resp.RawResponse.Body = newAnthropicStreamReader(newBedrockEventStreamReader(resp.RawResponse.Body, c.name), c.name, request.Model)
+	}
 
 	return resp, nil
 }
 
-func (c *ChatCompletionClient) streamResponseToChannel(ctx context.Context, apiKey string, request openai.ChatCompletionRequest, dataChan chan<- string, errChan chan<- error, wg *sync.WaitGroup, opts []StreamOption) {
+func (c *ChatCompletionClient) streamResponseToChannel(ctx context.Context, apiKey string, request openai.ChatCompletionRequest, dataChan chan<- string, errChan chan<- error, connectedChan chan<- struct{}, wg *sync.WaitGroup, opts []StreamOption) {
 	defer wg.Done()
 
 	resp, err := c.doStreamingRequest(ctx, apiKey, request, opts...)
@@ -315,6 +575,8 @@ func (c *ChatCompletionClient) streamResponseToChannel(ctx context.Context, apiK
 		c.sendAsyncError(errChan, err)
 		return
 	}
+	close(connectedChan)
+	defer abortOnDone(ctx, resp)()
 
 	defer func() {
 		if closeErr := resp.RawResponse.Body.Close(); closeErr != nil {
@@ -348,10 +610,21 @@ func (c *ChatCompletionClient) streamResponseToChannel(ctx context.Context, apiK
 	}
 }
 
+// abortOnDone closes the upstream response body once ctx is done, typically because the client
+// disconnected. A read blocked on the body then fails right away and the upstream connection is
+// dropped, so the provider stops generating tokens nobody will receive. The returned function
+// releases the watch when the stream ends normally.
+func abortOnDone(ctx context.Context, resp *resty.Response) func() bool {
+	return context.AfterFunc(ctx, func() {
+		_ = resp.RawResponse.Body.Close()
+	})
+}
+
 func (c *ChatCompletionClient) writeSSELine(reqCtx *gin.Context, line string) error {
 	if reqCtx == nil {
 		return fmt.Errorf("%s: nil gin context provided", c.name)
 	}
+	c.ensureSSEHeaders(reqCtx)
 	_, err := reqCtx.Writer.Write([]byte(line + newlineChar))
 	if err != nil {
 		return err
@@ -360,7 +633,7 @@ func (c *ChatCompletionClient) writeSSELine(reqCtx *gin.Context, line string) er
 	return nil
 }
 
-func (c *ChatCompletionClient) processStreamChunkForChannel(data string) (string, string, *openai.FunctionCall, *openai.ToolCall) {
+func (c *ChatCompletionClient) processStreamChunkForChannel(data string) (string, string, *openai.FunctionCall, *openai.ToolCall, *openai.Usage) {
 	var streamData struct {
 		Choices []struct {
 			Delta struct {
@@ -370,11 +643,12 @@ func (c *ChatCompletionClient) processStreamChunkForChannel(data string) (string
 				ToolCalls        []openai.ToolCall    `json:"tool_calls,omitempty"`
 			} `json:"delta"`
 		} `json:"choices"`
+		Usage *openai.Usage `json:"usage,omitempty"`
 	}
 
 	if err := json.Unmarshal([]byte(data), &streamData); err != nil {
 		logger.GetLogger().Errorf("%s: failed to parse stream chunk JSON: %v, data: %s", c.name, err, data)
-		return "", "", nil, nil
+		return "", "", nil, nil, nil
 	}
 
 	var contentChunk string
@@ -400,7 +674,7 @@ func (c *ChatCompletionClient) processStreamChunkForChannel(data string) (string
 		}
 	}
 
-	return contentChunk, reasoningChunk, functionCall, toolCall
+	return contentChunk, reasoningChunk, functionCall, toolCall, streamData.Usage
 }
 
 func (c *ChatCompletionClient) handleStreamingFunctionCall(functionCall *openai.FunctionCall, accumulator map[int]*functionCallAccumulator) {
@@ -505,8 +779,8 @@ func (c *ChatCompletionClient) buildCompleteResponse(content string, reasoning s
 		},
 	}
 
-	promptTokens := c.estimateTokens(request.Messages)
-	completionTokens := c.estimateTokens([]openai.ChatCompletionMessage{message})
+	promptTokens := tokenizer.EstimateTokens(request.Messages)
+	completionTokens := tokenizer.EstimateTokens([]openai.ChatCompletionMessage{message})
 	totalTokens := promptTokens + completionTokens
 
 	return openai.ChatCompletionResponse{
@@ -523,35 +797,6 @@ func (c *ChatCompletionClient) buildCompleteResponse(content string, reasoning s
 	}
 }
 
-func (c *ChatCompletionClient) estimateTokens(messages []openai.ChatCompletionMessage) int {
-	var allText strings.Builder
-
-	for _, msg := range messages {
-		allText.WriteString(msg.Content)
-		allText.WriteString(" ")
-
-		if msg.FunctionCall != nil {
-			allText.WriteString(msg.FunctionCall.Name)
-			allText.WriteString(" ")
-			allText.WriteString(msg.FunctionCall.Arguments)
-			allText.WriteString(" ")
-		}
-
-		for _, toolCall := range msg.ToolCalls {
-			allText.WriteString(toolCall.ID)
-			allText.WriteString(" ")
-			allText.WriteString(toolCall.Function.Name)
-			allText.WriteString(" ")
-			allText.WriteString(toolCall.Function.Arguments)
-			allText.WriteString(" ")
-		}
-	}
-
-	normalized := strings.Join(strings.Fields(allText.String()), " ")
-	words := strings.Fields(normalized)
-	return len(words)
-}
-
 func (c *ChatCompletionClient) sendAsyncError(errChan chan<- error, err error) {
 	if err == nil {
 		return
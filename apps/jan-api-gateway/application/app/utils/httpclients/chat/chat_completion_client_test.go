@@ -0,0 +1,119 @@
+package chat
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// newStreamTestContext returns a Gin context recording what a stream writes to the client.
+func newStreamTestContext() (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	reqCtx, _ := gin.CreateTestContext(recorder)
+	reqCtx.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	return reqCtx, recorder
+}
+
+// sseUpstream serves the given SSE lines as an OpenAI-compatible streaming upstream.
+func sseUpstream(t *testing.T, lines ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range lines {
+			_, _ = w.Write([]byte(line + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// upstreamCall is a request received by a recordingUpstream.
+type upstreamCall struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// recordingUpstream answers every request with the JSON body and records what it received.
+func recordingUpstream(t *testing.T, body string) (*httptest.Server, *[]upstreamCall) {
+	t.Helper()
+	var mu sync.Mutex
+	calls := []upstreamCall{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, upstreamCall{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Body: data})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func streamRequest() openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
+		Model:    "gpt-test",
+		Stream:   true,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+	}
+}
+
+func TestStreamChatCompletionToContextWritesSSEHeadersOnFirstChunk(t *testing.T) {
+	upstream := sseUpstream(t,
+		`data: {"choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+		`data: {"choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+		`data: [DONE]`,
+	)
+	reqCtx, recorder := newStreamTestContext()
+
+	client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+	result, err := client.StreamChatCompletionToContext(reqCtx, "", streamRequest())
+	if err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", contentType)
+	}
+	if got := result.Choices[0].Message.Content; got != "Hello" {
+		t.Fatalf("content = %q, want Hello", got)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(recorder.Body.String()), "data: [DONE]") {
+		t.Fatalf("stream does not end with [DONE]: %q", recorder.Body.String())
+	}
+}
+
+func TestStreamChatCompletionToContextLeavesErrorsUncommitted(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit_error"}}`))
+	}))
+	defer upstream.Close()
+	reqCtx, _ := newStreamTestContext()
+
+	client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+	_, err := client.StreamChatCompletionToContext(reqCtx, "", streamRequest())
+	var upstreamErr *UpstreamError
+	if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want upstream 429", err)
+	}
+	if reqCtx.Writer.Written() {
+		t.Fatal("SSE headers were written for a rejected stream")
+	}
+}
@@ -3,17 +3,16 @@ package chat
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"io"
 	"strings"
 
 	"resty.dev/v3"
 )
 
 type ChatModelClient struct {
-	client  *resty.Client
-	baseURL string
-	name    string
+	client   *resty.Client
+	baseURL  string
+	name     string
+	endpoint endpointConfig
 }
 
 type ModelsResponse struct {
@@ -78,20 +77,27 @@ func (m *Model) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func NewChatModelClient(client *resty.Client, name, baseURL string) *ChatModelClient {
+func NewChatModelClient(client *resty.Client, name, baseURL string, opts ...ClientOption) *ChatModelClient {
 	return &ChatModelClient{
-		client:  client,
-		baseURL: normalizeBaseURL(baseURL),
-		name:    name,
+		client:   client,
+		baseURL:  normalizeBaseURL(baseURL),
+		name:     name,
+		endpoint: newEndpointConfig(opts),
 	}
 }
 
 func (c *ChatModelClient) ListModels(ctx context.Context) (*ModelsResponse, error) {
+	if c.endpoint.gemini() {
+		return c.listGeminiModels(ctx)
+	}
+	if c.endpoint.bedrock() {
+		return c.listBedrockModels(ctx)
+	}
 	var respBody ModelsResponse
 	resp, err := c.client.R().
 		SetContext(ctx).
 		SetResult(&respBody).
-		Get(c.endpoint("/models"))
+		Get(c.endpointURL("/models"))
 	if err != nil {
 		return nil, err
 	}
@@ -101,34 +107,26 @@ func (c *ChatModelClient) ListModels(ctx context.Context) (*ModelsResponse, erro
 	return &respBody, nil
 }
 
-func (c *ChatModelClient) endpoint(path string) string {
+func (c *ChatModelClient) endpointURL(path string) string {
+	if c.endpoint.azure() {
+		return c.endpoint.azureEndpoint(c.baseURL, "", path)
+	}
+	baseURL := c.endpoint.prefixedBaseURL(c.baseURL)
 	if path == "" {
-		return c.baseURL
+		return baseURL
 	}
 	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 		return path
 	}
-	if c.baseURL == "" {
+	if baseURL == "" {
 		return path
 	}
 	if strings.HasPrefix(path, "/") {
-		return c.baseURL + path
+		return baseURL + path
 	}
-	return c.baseURL + "/" + path
+	return baseURL + "/" + path
 }
 
 func (c *ChatModelClient) errorFromResponse(resp *resty.Response, message string) error {
-	if resp == nil || resp.RawResponse == nil || resp.RawResponse.Body == nil {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	defer resp.RawResponse.Body.Close()
-	body, err := io.ReadAll(resp.RawResponse.Body)
-	if err != nil {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	trimmed := strings.TrimSpace(string(body))
-	if trimmed == "" {
-		return fmt.Errorf("%s: %s with status %d", c.name, message, statusCode(resp))
-	}
-	return fmt.Errorf("%s: %s with status %d: %s", c.name, message, statusCode(resp), trimmed)
+	return newUpstreamError(c.name, message, resp)
 }
@@ -0,0 +1,468 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// geminiModelsPageSize is the largest page the Gemini models listing accepts.
+const geminiModelsPageSize = 1000
+
+// WithGeminiGenerateContent translates requests to Google's Gemini API, generateContent and
+// streamGenerateContent, and maps its responses and streamed chunks back to the OpenAI chat
+// completion shape.
+func WithGeminiGenerateContent() ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.geminiGenerateContent = true
+	}
+}
+
+func (cfg endpointConfig) gemini() bool {
+	return cfg.geminiGenerateContent
+}
+
+// geminiModelEndpoint builds the URL of a model method such as generateContent. Model keys may
+// carry the "models/" prefix the Gemini API uses for resource names.
+func (c *ChatCompletionClient) geminiModelEndpoint(model, method string) string {
+	return c.endpointURL("/models/" + url.PathEscape(strings.TrimPrefix(model, "models/")) + ":" + method)
+}
+
+type geminiRequest struct {
+	Contents          []geminiContent         `json:"contents"`
+	SystemInstruction *geminiContent          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *geminiGenerationConfig `json:"generationConfig,omitempty"`
+	Tools             []geminiTool            `json:"tools,omitempty"`
+	ToolConfig        *geminiToolConfig       `json:"toolConfig,omitempty"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiPart struct {
+	Text             string                  `json:"text,omitempty"`
+	Thought          bool                    `json:"thought,omitempty"`
+	InlineData       *geminiBlob             `json:"inlineData,omitempty"`
+	FileData         *geminiFileData         `json:"fileData,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiBlob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type geminiFileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+type geminiFunctionCall struct {
+	ID   string          `json:"id,omitempty"`
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	ID       string          `json:"id,omitempty"`
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+type geminiGenerationConfig struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	TopP             *float32 `json:"topP,omitempty"`
+	MaxOutputTokens  int      `json:"maxOutputTokens,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	CandidateCount   int      `json:"candidateCount,omitempty"`
+	ResponseMimeType string   `json:"responseMimeType,omitempty"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig geminiFunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+type geminiFunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+type geminiResponse struct {
+	ResponseID    string            `json:"responseId"`
+	ModelVersion  string            `json:"modelVersion"`
+	Candidates    []geminiCandidate `json:"candidates"`
+	UsageMetadata *geminiUsage      `json:"usageMetadata,omitempty"`
+}
+
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason"`
+}
+
+type geminiUsage struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// toGeminiRequest translates an OpenAI chat completion request into a generateContent request.
+// System and developer messages become the system instruction, assistant turns use the "model"
+// role and tool results are sent back as function responses named after the originating call.
+func toGeminiRequest(request openai.ChatCompletionRequest) geminiRequest {
+	result := geminiRequest{}
+
+	config := geminiGenerationConfig{
+		MaxOutputTokens: request.MaxCompletionTokens,
+		StopSequences:   request.Stop,
+	}
+	if config.MaxOutputTokens <= 0 {
+		config.MaxOutputTokens = request.MaxTokens
+	}
+	if request.Temperature != 0 {
+		config.Temperature = &request.Temperature
+	}
+	if request.TopP != 0 {
+		config.TopP = &request.TopP
+	}
+	if request.N > 1 {
+		config.CandidateCount = request.N
+	}
+	if request.ResponseFormat != nil && (request.ResponseFormat.Type == openai.ChatCompletionResponseFormatTypeJSONObject ||
+		request.ResponseFormat.Type == openai.ChatCompletionResponseFormatTypeJSONSchema) {
+		config.ResponseMimeType = "application/json"
+	}
+	result.GenerationConfig = &config
+
+	// Tool results only carry the call ID, but Gemini matches function responses by name
+	toolNames := make(map[string]string)
+	var system []geminiPart
+	for _, message := range request.Messages {
+		switch message.Role {
+		case openai.ChatMessageRoleSystem, openai.ChatMessageRoleDeveloper:
+			if text := openAIMessageText(message); text != "" {
+				system = append(system, geminiPart{Text: text})
+			}
+		case openai.ChatMessageRoleTool:
+			name := message.Name
+			if name == "" {
+				name = toolNames[message.ToolCallID]
+			}
+			result.Contents = appendGeminiTurn(result.Contents, "user", geminiPart{
+				FunctionResponse: &geminiFunctionResponse{
+					Name:     name,
+					Response: geminiFunctionResponseBody(openAIMessageText(message)),
+				},
+			})
+		case openai.ChatMessageRoleAssistant:
+			parts := make([]geminiPart, 0, len(message.ToolCalls)+1)
+			if text := openAIMessageText(message); text != "" {
+				parts = append(parts, geminiPart{Text: text})
+			}
+			for _, toolCall := range message.ToolCalls {
+				toolNames[toolCall.ID] = toolCall.Function.Name
+				args := json.RawMessage(toolCall.Function.Arguments)
+				if !json.Valid(args) {
+					args = json.RawMessage("{}")
+				}
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{
+					Name: toolCall.Function.Name,
+					Args: args,
+				}})
+			}
+			result.Contents = appendGeminiTurn(result.Contents, "model", parts...)
+		default:
+			result.Contents = appendGeminiTurn(result.Contents, "user", geminiUserParts(message)...)
+		}
+	}
+	if len(system) > 0 {
+		result.SystemInstruction = &geminiContent{Parts: system}
+	}
+
+	declarations := make([]geminiFunctionDeclaration, 0, len(request.Tools))
+	for _, tool := range request.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		declarations = append(declarations, geminiFunctionDeclaration{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+	if len(declarations) > 0 {
+		result.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+	}
+	result.ToolConfig = toGeminiToolConfig(request.ToolChoice)
+	return result
+}
+
+// appendGeminiTurn adds parts to the conversation, extending the last turn when it has the same
+// role.
+func appendGeminiTurn(contents []geminiContent, role string, parts ...geminiPart) []geminiContent {
+	if len(parts) == 0 {
+		return contents
+	}
+	if last := len(contents) - 1; last >= 0 && contents[last].Role == role {
+		contents[last].Parts = append(contents[last].Parts, parts...)
+		return contents
+	}
+	return append(contents, geminiContent{Role: role, Parts: parts})
+}
+
+func geminiUserParts(message openai.ChatCompletionMessage) []geminiPart {
+	if len(message.MultiContent) == 0 {
+		if message.Content == "" {
+			return nil
+		}
+		return []geminiPart{{Text: message.Content}}
+	}
+	parts := make([]geminiPart, 0, len(message.MultiContent))
+	for _, part := range message.MultiContent {
+		switch part.Type {
+		case openai.ChatMessagePartTypeText:
+			if part.Text != "" {
+				parts = append(parts, geminiPart{Text: part.Text})
+			}
+		case openai.ChatMessagePartTypeImageURL:
+			if part.ImageURL != nil && part.ImageURL.URL != "" {
+				parts = append(parts, geminiImagePart(part.ImageURL.URL))
+			}
+		}
+	}
+	return parts
+}
+
+// geminiImagePart inlines data URLs and references other URLs as file data.
+func geminiImagePart(imageURL string) geminiPart {
+	source := anthropicImageSourceFromURL(imageURL)
+	if source.Type == "base64" {
+		return geminiPart{InlineData: &geminiBlob{MimeType: source.MediaType, Data: source.Data}}
+	}
+	return geminiPart{FileData: &geminiFileData{FileURI: imageURL}}
+}
+
+// geminiFunctionResponseBody wraps a tool result in the object Gemini expects, passing JSON
+// objects through unchanged.
+func geminiFunctionResponseBody(content string) json.RawMessage {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	body, _ := json.Marshal(map[string]string{"content": content})
+	return body
+}
+
+// toGeminiToolConfig maps the OpenAI tool_choice onto Gemini's function calling mode.
+func toGeminiToolConfig(choice any) *geminiToolConfig {
+	anthropicChoice := toAnthropicToolChoice(choice)
+	if anthropicChoice == nil {
+		return nil
+	}
+	config := &geminiToolConfig{}
+	switch anthropicChoice.Type {
+	case "auto":
+		config.FunctionCallingConfig.Mode = "AUTO"
+	case "none":
+		config.FunctionCallingConfig.Mode = "NONE"
+	case "any":
+		config.FunctionCallingConfig.Mode = "ANY"
+	case "tool":
+		config.FunctionCallingConfig.Mode = "ANY"
+		config.FunctionCallingConfig.AllowedFunctionNames = []string{anthropicChoice.Name}
+	}
+	return config
+}
+
+// fromGeminiResponse maps a generateContent response onto an OpenAI chat completion response.
+func fromGeminiResponse(response geminiResponse, model string) *openai.ChatCompletionResponse {
+	result := &openai.ChatCompletionResponse{
+		ID:      response.ResponseID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: make([]openai.ChatCompletionChoice, 0, len(response.Candidates)),
+	}
+	if response.ModelVersion != "" {
+		result.Model = response.ModelVersion
+	}
+	toolIndex := 0
+	for i, candidate := range response.Candidates {
+		message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant}
+		var content, reasoning strings.Builder
+		for _, part := range candidate.Content.Parts {
+			switch {
+			case part.FunctionCall != nil:
+				message.ToolCalls = append(message.ToolCalls, geminiToolCall(part.FunctionCall, toolIndex))
+				toolIndex++
+			case part.Thought:
+				reasoning.WriteString(part.Text)
+			default:
+				content.WriteString(part.Text)
+			}
+		}
+		message.Content = content.String()
+		message.ReasoningContent = reasoning.String()
+		finishReason := geminiFinishReason(candidate.FinishReason)
+		if len(message.ToolCalls) > 0 && finishReason == openai.FinishReasonStop {
+			finishReason = openai.FinishReasonToolCalls
+		}
+		result.Choices = append(result.Choices, openai.ChatCompletionChoice{
+			Index:        i,
+			Message:      message,
+			FinishReason: finishReason,
+		})
+	}
+	if response.UsageMetadata != nil {
+		result.Usage = geminiUsageToOpenAI(*response.UsageMetadata)
+	}
+	return result
+}
+
+// geminiToolCall converts a function call, generating an ID when the model did not send one.
+func geminiToolCall(call *geminiFunctionCall, index int) openai.ToolCall {
+	id := call.ID
+	if id == "" {
+		id = fmt.Sprintf("call_%d", index)
+	}
+	arguments := string(call.Args)
+	if arguments == "" {
+		arguments = "{}"
+	}
+	return openai.ToolCall{
+		ID:   id,
+		Type: openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      call.Name,
+			Arguments: arguments,
+		},
+	}
+}
+
+func geminiUsageToOpenAI(usage geminiUsage) openai.Usage {
+	total := usage.TotalTokenCount
+	if total == 0 {
+		total = usage.PromptTokenCount + usage.CandidatesTokenCount
+	}
+	return openai.Usage{
+		PromptTokens:     usage.PromptTokenCount,
+		CompletionTokens: usage.CandidatesTokenCount,
+		TotalTokens:      total,
+	}
+}
+
+func geminiFinishReason(finishReason string) openai.FinishReason {
+	switch finishReason {
+	case "", "FINISH_REASON_UNSPECIFIED":
+		return ""
+	case "MAX_TOKENS":
+		return openai.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return openai.FinishReasonContentFilter
+	default:
+		return openai.FinishReasonStop
+	}
+}
+
+func (c *ChatCompletionClient) createGeminiContent(ctx context.Context, apiKey string, request openai.ChatCompletionRequest) (*openai.ChatCompletionResponse, error) {
+	var respBody geminiResponse
+	resp, err := c.postWithRateLimitRetry(ctx, func() (*resty.Response, error) {
+		return c.prepareRequest(ctx, apiKey).
+			SetBody(toGeminiRequest(request)).
+			SetResult(&respBody).
+			Post(c.geminiModelEndpoint(request.Model, "generateContent"))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, c.errorFromResponse(resp, "request failed")
+	}
+	return fromGeminiResponse(respBody, request.Model), nil
+}
+
+type geminiModelsResponse struct {
+	Models        []geminiModel `json:"models"`
+	NextPageToken string        `json:"nextPageToken"`
+}
+
+type geminiModel struct {
+	Name                       string   `json:"name"`
+	DisplayName                string   `json:"displayName"`
+	Description                string   `json:"description"`
+	InputTokenLimit            int      `json:"inputTokenLimit"`
+	OutputTokenLimit           int      `json:"outputTokenLimit"`
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	Thinking                   bool     `json:"thinking"`
+}
+
+// listGeminiModels pages through the Gemini models listing and keeps the models that support
+// generateContent, keyed by their name without the "models/" prefix.
+func (c *ChatModelClient) listGeminiModels(ctx context.Context) (*ModelsResponse, error) {
+	result := &ModelsResponse{Object: "list", Data: []Model{}}
+	pageToken := ""
+	for {
+		var page geminiModelsResponse
+		req := c.client.R().
+			SetContext(ctx).
+			SetQueryParam("pageSize", fmt.Sprint(geminiModelsPageSize)).
+			SetResult(&page)
+		if pageToken != "" {
+			req.SetQueryParam("pageToken", pageToken)
+		}
+		resp, err := req.Get(c.endpointURL("/models"))
+		if err != nil {
+			return nil, err
+		}
+		if resp.IsError() {
+			return nil, c.errorFromResponse(resp, "list models request failed")
+		}
+		for _, model := range page.Models {
+			if !slices.Contains(model.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			id := strings.TrimPrefix(model.Name, "models/")
+			displayName := model.DisplayName
+			if displayName == "" {
+				displayName = id
+			}
+			result.Data = append(result.Data, Model{
+				ID:          id,
+				Object:      "model",
+				OwnedBy:     "google",
+				DisplayName: displayName,
+				Name:        displayName,
+				Raw: map[string]any{
+					"id":                id,
+					"description":       model.Description,
+					"context_length":    model.InputTokenLimit,
+					"max_output_tokens": model.OutputTokenLimit,
+					"thinking":          model.Thinking,
+				},
+			})
+		}
+		if page.NextPageToken == "" {
+			return result, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
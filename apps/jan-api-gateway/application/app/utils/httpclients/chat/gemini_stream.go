@@ -0,0 +1,157 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// geminiStreamReader turns the JSON array streamed by streamGenerateContent into an OpenAI chat
+// completion chunk stream. Each array element is a partial response whose parts become deltas;
+// the usage of the final element is attached to the chunk carrying the finish reason.
+type geminiStreamReader struct {
+	translatedStream
+	body    io.ReadCloser
+	input   *eofReader
+	decoder *json.Decoder
+	name    string
+
+	started   bool
+	toolCalls int
+}
+
+func newGeminiStreamReader(body io.ReadCloser, name, model string) *geminiStreamReader {
+	input := &eofReader{reader: body}
+	return &geminiStreamReader{
+		translatedStream: newTranslatedStream(model),
+		body:             body,
+		input:            input,
+		decoder:          json.NewDecoder(input),
+		name:             name,
+	}
+}
+
+func (r *geminiStreamReader) Read(p []byte) (int, error) {
+	return r.read(p, r.advance)
+}
+
+func (r *geminiStreamReader) Close() error {
+	return r.body.Close()
+}
+
+// advance decodes the next array element, or ends the stream at the closing bracket.
+func (r *geminiStreamReader) advance() error {
+	if !r.started {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return r.decodeError(err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("%s: unexpected stream start %v", r.name, token)
+		}
+		r.started = true
+		if err := r.writeChunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, "", nil); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	if !r.decoder.More() {
+		if _, err := r.decoder.Token(); err != nil {
+			return r.decodeError(err)
+		}
+		r.finish()
+		return nil
+	}
+
+	var element struct {
+		geminiResponse
+		Error *upstreamErrorObject `json:"error,omitempty"`
+	}
+	if err := r.decoder.Decode(&element); err != nil {
+		return r.decodeError(err)
+	}
+	if element.Error != nil {
+		return &UpstreamError{
+			Type:    element.Error.Type,
+			Message: element.Error.Message,
+			summary: fmt.Sprintf("%s: streaming error: %s", r.name, element.Error.Message),
+		}
+	}
+	return r.handle(element.geminiResponse)
+}
+
+func (r *geminiStreamReader) handle(response geminiResponse) error {
+	if response.ResponseID != "" {
+		r.id = response.ResponseID
+	}
+	if response.ModelVersion != "" {
+		r.model = response.ModelVersion
+	}
+	if len(response.Candidates) == 0 {
+		return nil
+	}
+	candidate := response.Candidates[0]
+
+	delta := openai.ChatCompletionStreamChoiceDelta{}
+	var content, reasoning strings.Builder
+	for _, part := range candidate.Content.Parts {
+		switch {
+		case part.FunctionCall != nil:
+			// Gemini sends each function call whole, so it is relayed as a single delta
+			index := r.toolCalls
+			toolCall := geminiToolCall(part.FunctionCall, index)
+			toolCall.Index = &index
+			delta.ToolCalls = append(delta.ToolCalls, toolCall)
+			r.toolCalls++
+		case part.Thought:
+			reasoning.WriteString(part.Text)
+		default:
+			content.WriteString(part.Text)
+		}
+	}
+	delta.Content = content.String()
+	delta.ReasoningContent = reasoning.String()
+
+	finishReason := geminiFinishReason(candidate.FinishReason)
+	if finishReason == openai.FinishReasonStop && r.toolCalls > 0 {
+		finishReason = openai.FinishReasonToolCalls
+	}
+	var usage *openai.Usage
+	if finishReason != "" && response.UsageMetadata != nil {
+		converted := geminiUsageToOpenAI(*response.UsageMetadata)
+		usage = &converted
+	}
+
+	if delta.Content == "" && delta.ReasoningContent == "" && len(delta.ToolCalls) == 0 && finishReason == "" {
+		return nil
+	}
+	return r.writeChunk(delta, finishReason, usage)
+}
+
+// decodeError reports a stream that ended before the array was closed as a truncated stream.
+// The decoder describes a body ending mid-value as a syntax error, so the end of the input is
+// tracked separately.
+func (r *geminiStreamReader) decodeError(err error) error {
+	if err == io.EOF || r.input.eof {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// eofReader records whether the wrapped reader reached the end of its input.
+type eofReader struct {
+	reader io.Reader
+	eof    bool
+}
+
+func (r *eofReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
@@ -0,0 +1,294 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// chunkedUpstream writes the given pieces of a response body one flush at a time and records
+// the requests it received.
+func chunkedUpstream(t *testing.T, pieces ...string) (*httptest.Server, *[]upstreamCall) {
+	t.Helper()
+	var mu sync.Mutex
+	calls := []upstreamCall{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, upstreamCall{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone()})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		for _, piece := range pieces {
+			_, _ = w.Write([]byte(piece))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestToGeminiRequest(t *testing.T) {
+	request := openai.ChatCompletionRequest{
+		MaxTokens:      256,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject},
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "What is on this image?"},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "data:image/png;base64,iVBORw0KGgo="}},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "gs://bucket/cat.jpg"}},
+			}},
+			{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{
+				{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "time", Arguments: `not json`}},
+			}},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_1", Content: `{"celsius":18}`},
+			{Role: openai.ChatMessageRoleTool, ToolCallID: "call_2", Content: "14:00"},
+		},
+		ToolChoice: openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{Name: "weather"}},
+	}
+
+	got := toGeminiRequest(request)
+
+	if got.SystemInstruction == nil || !reflect.DeepEqual(got.SystemInstruction.Parts, []geminiPart{{Text: "Be brief."}}) {
+		t.Fatalf("systemInstruction = %+v, want the system message", got.SystemInstruction)
+	}
+	want := []geminiContent{
+		{Role: "user", Parts: []geminiPart{
+			{Text: "What is on this image?"},
+			{InlineData: &geminiBlob{MimeType: "image/png", Data: "iVBORw0KGgo="}},
+			{FileData: &geminiFileData{FileURI: "gs://bucket/cat.jpg"}},
+		}},
+		{Role: "model", Parts: []geminiPart{
+			{FunctionCall: &geminiFunctionCall{Name: "weather", Args: json.RawMessage(`{"city":"Paris"}`)}},
+			{FunctionCall: &geminiFunctionCall{Name: "time", Args: json.RawMessage(`{}`)}},
+		}},
+		{Role: "user", Parts: []geminiPart{
+			{FunctionResponse: &geminiFunctionResponse{Name: "weather", Response: json.RawMessage(`{"celsius":18}`)}},
+			{FunctionResponse: &geminiFunctionResponse{Name: "time", Response: json.RawMessage(`{"content":"14:00"}`)}},
+		}},
+	}
+	if !reflect.DeepEqual(got.Contents, want) {
+		gotJSON, _ := json.Marshal(got.Contents)
+		wantJSON, _ := json.Marshal(want)
+		t.Fatalf("contents = %s, want %s", gotJSON, wantJSON)
+	}
+	if got.GenerationConfig.MaxOutputTokens != 256 || got.GenerationConfig.ResponseMimeType != "application/json" {
+		t.Fatalf("generationConfig = %+v, want 256 tokens of application/json", got.GenerationConfig)
+	}
+	wantToolConfig := &geminiToolConfig{FunctionCallingConfig: geminiFunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{"weather"}}}
+	if !reflect.DeepEqual(got.ToolConfig, wantToolConfig) {
+		t.Fatalf("toolConfig = %+v, want %+v", got.ToolConfig, wantToolConfig)
+	}
+}
+
+func TestGeminiGenerateContent(t *testing.T) {
+	upstream, calls := recordingUpstream(t, `{
+		"responseId": "resp_1",
+		"modelVersion": "gemini-2.5-flash-001",
+		"candidates": [{
+			"content": {"role": "model", "parts": [
+				{"text": "Checking the forecast.", "thought": true},
+				{"text": "One moment."},
+				{"functionCall": {"name": "weather", "args": {"city": "Paris"}}}
+			]},
+			"finishReason": "STOP"
+		}],
+		"usageMetadata": {"promptTokenCount": 20, "candidatesTokenCount": 9}
+	}`)
+	client := NewChatCompletionClient(resty.New(), "gemini", upstream.URL+"/v1beta", WithGeminiGenerateContent())
+
+	request := streamRequest()
+	request.Model = "models/gemini-2.5-flash"
+	request.Stream = false
+	response, err := client.CreateChatCompletion(context.Background(), "", request)
+	if err != nil {
+		t.Fatalf("CreateChatCompletion: %v", err)
+	}
+
+	if call := (*calls)[0]; call.Path != "/v1beta/models/gemini-2.5-flash:generateContent" {
+		t.Fatalf("request went to %s, want /v1beta/models/gemini-2.5-flash:generateContent", call.Path)
+	}
+	if response.Model != "gemini-2.5-flash-001" || response.ID != "resp_1" {
+		t.Fatalf("response = %s/%s, want resp_1 of gemini-2.5-flash-001", response.ID, response.Model)
+	}
+	choice := response.Choices[0]
+	if choice.Message.Content != "One moment." || choice.Message.ReasoningContent != "Checking the forecast." {
+		t.Fatalf("message = %+v, want the text and thought parts", choice.Message)
+	}
+	wantCall := openai.ToolCall{ID: "call_0", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city": "Paris"}`}}
+	if len(choice.Message.ToolCalls) != 1 || !reflect.DeepEqual(choice.Message.ToolCalls[0], wantCall) {
+		t.Fatalf("tool calls = %+v, want %+v", choice.Message.ToolCalls, wantCall)
+	}
+	if choice.FinishReason != openai.FinishReasonToolCalls {
+		t.Fatalf("finish reason = %q, want tool_calls", choice.FinishReason)
+	}
+	if response.Usage.TotalTokens != 29 {
+		t.Fatalf("usage = %+v, want 29 total tokens", response.Usage)
+	}
+}
+
+func TestGeminiFinishReason(t *testing.T) {
+	tests := map[string]openai.FinishReason{
+		"":                          "",
+		"FINISH_REASON_UNSPECIFIED": "",
+		"STOP":                      openai.FinishReasonStop,
+		"MAX_TOKENS":                openai.FinishReasonLength,
+		"SAFETY":                    openai.FinishReasonContentFilter,
+		"RECITATION":                openai.FinishReasonContentFilter,
+		"OTHER":                     openai.FinishReasonStop,
+	}
+	for finishReason, want := range tests {
+		if got := geminiFinishReason(finishReason); got != want {
+			t.Errorf("geminiFinishReason(%q) = %q, want %q", finishReason, got, want)
+		}
+	}
+}
+
+func TestGeminiStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		pieces    []string
+		content   string
+		toolCalls []string
+		finish    openai.FinishReason
+		usage     openai.Usage
+		wantErr   string
+		upstream  bool
+	}{
+		{
+			name: "text split across writes",
+			pieces: []string{
+				`[{"responseId":"resp_1","candidates":[{"content":{"role":"model","parts":[{"text":"Par"}]}}]}`,
+				`,{"candidates":[{"content":{"role":"model","parts":[{"text":"is."}]},"finish`,
+				`Reason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":2,"totalTokenCount":14}}]`,
+			},
+			content: "Paris.",
+			finish:  openai.FinishReasonStop,
+			usage:   openai.Usage{PromptTokens: 12, CompletionTokens: 2, TotalTokens: 14},
+		},
+		{
+			name: "function calls",
+			pieces: []string{
+				`[{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"weather","args":{"city":"Paris"}}}]}}]},`,
+				`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"time","args":{}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":30,"candidatesTokenCount":8}}]`,
+			},
+			toolCalls: []string{"weather", "time"},
+			finish:    openai.FinishReasonToolCalls,
+			usage:     openai.Usage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38},
+		},
+		{
+			name: "error element",
+			pieces: []string{
+				`[{"candidates":[{"content":{"role":"model","parts":[{"text":"Par"}]}}]},`,
+				`{"error":{"code":503,"message":"The model is overloaded.","status":"UNAVAILABLE"}}]`,
+			},
+			wantErr:  "The model is overloaded.",
+			upstream: true,
+		},
+		{
+			name:    "array never closed",
+			pieces:  []string{`[{"candidates":[{"content":{"role":"model","parts":[{"text":"Par"}]}}]}`},
+			wantErr: "unexpected EOF",
+		},
+		{
+			name:    "not an array",
+			pieces:  []string{`{"candidates":[]}`},
+			wantErr: "unexpected stream start",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream, calls := chunkedUpstream(t, tt.pieces...)
+			client := NewChatCompletionClient(resty.New(), "gemini", upstream.URL, WithGeminiGenerateContent())
+			request := streamRequest()
+			request.Model = "gemini-2.5-flash"
+			reqCtx, recorder := newStreamTestContext()
+
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", request)
+			if call := (*calls)[0]; call.Path != "/models/gemini-2.5-flash:streamGenerateContent" {
+				t.Errorf("stream went to %s, want /models/gemini-2.5-flash:streamGenerateContent", call.Path)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				var upstreamErr *UpstreamError
+				if tt.upstream && !errors.As(err, &upstreamErr) {
+					t.Fatalf("err = %v, want an UpstreamError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+
+			message := result.Choices[0].Message
+			if message.Content != tt.content {
+				t.Fatalf("content = %q, want %q", message.Content, tt.content)
+			}
+			names := make([]string, 0, len(message.ToolCalls))
+			for _, toolCall := range message.ToolCalls {
+				names = append(names, toolCall.Function.Name)
+			}
+			if len(names) != len(tt.toolCalls) || (len(names) > 0 && !reflect.DeepEqual(names, tt.toolCalls)) {
+				t.Fatalf("tool calls = %v, want %v", names, tt.toolCalls)
+			}
+			if !strings.Contains(recorder.Body.String(), `"finish_reason":"`+string(tt.finish)+`"`) {
+				t.Fatalf("stream has no %s finish reason: %s", tt.finish, recorder.Body.String())
+			}
+			if result.UsageEstimated || result.Usage != tt.usage {
+				t.Fatalf("usage = %+v (estimated %v), want %+v", result.Usage, result.UsageEstimated, tt.usage)
+			}
+			if !strings.HasSuffix(strings.TrimSpace(recorder.Body.String()), "data: [DONE]") {
+				t.Fatalf("stream does not end with [DONE]: %q", recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestListGeminiModels(t *testing.T) {
+	var mu sync.Mutex
+	var pageTokens []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pageTokens = append(pageTokens, r.URL.Query().Get("pageToken"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("pageToken") == "" {
+			_, _ = w.Write([]byte(`{"models":[
+				{"name":"models/gemini-2.5-flash","displayName":"Gemini 2.5 Flash","supportedGenerationMethods":["generateContent","countTokens"]},
+				{"name":"models/text-embedding-004","supportedGenerationMethods":["embedContent"]}
+			],"nextPageToken":"page-2"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[{"name":"models/gemini-2.5-pro","supportedGenerationMethods":["generateContent"]}]}`))
+	}))
+	defer upstream.Close()
+
+	response, err := NewChatModelClient(resty.New(), "gemini", upstream.URL, WithGeminiGenerateContent()).ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels: %v", err)
+	}
+	if !reflect.DeepEqual(pageTokens, []string{"", "page-2"}) {
+		t.Fatalf("page tokens = %v, want both pages", pageTokens)
+	}
+	ids := make([]string, 0, len(response.Data))
+	for _, model := range response.Data {
+		ids = append(ids, model.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"gemini-2.5-flash", "gemini-2.5-pro"}) {
+		t.Fatalf("models = %v, want the generateContent models without the models/ prefix", ids)
+	}
+	if response.Data[0].DisplayName != "Gemini 2.5 Flash" || response.Data[1].DisplayName != "gemini-2.5-pro" {
+		t.Fatalf("display names = %q, %q", response.Data[0].DisplayName, response.Data[1].DisplayName)
+	}
+}
@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+)
+
+// heartbeatComment is an SSE comment line; clients ignore it, but it keeps idle proxies from
+// closing a stream whose first token is slow to arrive.
+const heartbeatComment = ": keep-alive"
+
+// heartbeatBytesKey records on the Gin context how many response bytes were heartbeats.
+const heartbeatBytesKey = "chat.stream_heartbeat_bytes"
+
+// streamHeartbeatInterval returns how often heartbeats are sent while waiting for the first
+// upstream chunk, or zero when CHAT_STREAM_HEARTBEAT_SECONDS leaves them disabled.
+func streamHeartbeatInterval() time.Duration {
+	seconds := environment_variables.EnvironmentVariables.CHAT_STREAM_HEARTBEAT_SECONDS
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *ChatCompletionClient) writeHeartbeat(reqCtx *gin.Context) error {
+	before := reqCtx.Writer.Size()
+	if err := c.writeSSELine(reqCtx, heartbeatComment); err != nil {
+		return err
+	}
+	if err := c.writeSSELine(reqCtx, ""); err != nil {
+		return err
+	}
+	reqCtx.Set(heartbeatBytesKey, reqCtx.GetInt(heartbeatBytesKey)+reqCtx.Writer.Size()-max(before, 0))
+	return nil
+}
+
+// StreamDataWritten reports whether a stream already sent the client anything besides
+// heartbeats, after which the request can no longer move to another provider.
+func StreamDataWritten(reqCtx *gin.Context) bool {
+	return reqCtx.Writer.Size() > reqCtx.GetInt(heartbeatBytesKey)
+}
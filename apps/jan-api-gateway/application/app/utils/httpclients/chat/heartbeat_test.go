@@ -0,0 +1,100 @@
+package chat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+// delayedUpstream accepts the stream right away, waits firstByte before the first SSE line and
+// between before each later one.
+func delayedUpstream(t *testing.T, firstByte, between time.Duration, lines ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(firstByte)
+		for i, line := range lines {
+			if i > 0 {
+				time.Sleep(between)
+			}
+			_, _ = w.Write([]byte(line + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStreamHeartbeats(t *testing.T) {
+	env := &environment_variables.EnvironmentVariables
+	previous := env.CHAT_STREAM_HEARTBEAT_SECONDS
+	t.Cleanup(func() { env.CHAT_STREAM_HEARTBEAT_SECONDS = previous })
+
+	lines := []string{
+		`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+		`data: [DONE]`,
+	}
+	tests := []struct {
+		name       string
+		seconds    int
+		firstByte  time.Duration
+		heartbeats int
+	}{
+		{name: "sent while waiting for the first chunk", seconds: 1, firstByte: 2500 * time.Millisecond, heartbeats: 2},
+		{name: "disabled by default", seconds: 0, firstByte: 1200 * time.Millisecond, heartbeats: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.CHAT_STREAM_HEARTBEAT_SECONDS = tt.seconds
+			// The gap after the first chunk is longer than the interval, so a heartbeat would show
+			// up before [DONE] if the ticker kept running.
+			upstream := delayedUpstream(t, tt.firstByte, 1200*time.Millisecond, lines...)
+			reqCtx, recorder := newStreamTestContext()
+
+			client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", streamRequest())
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+			if got := result.Choices[0].Message.Content; got != "Hello" {
+				t.Fatalf("content = %q, want Hello", got)
+			}
+
+			body := recorder.Body.String()
+			if got := strings.Count(body, heartbeatComment); got != tt.heartbeats {
+				t.Fatalf("stream has %d heartbeats, want %d: %q", got, tt.heartbeats, body)
+			}
+			if last := strings.LastIndex(body, heartbeatComment); last > strings.Index(body, "data: ") {
+				t.Fatalf("heartbeat sent after the first chunk: %q", body)
+			}
+		})
+	}
+}
+
+func TestStreamDataWrittenIgnoresHeartbeats(t *testing.T) {
+	reqCtx, _ := newStreamTestContext()
+	client := NewChatCompletionClient(resty.New(), "upstream", "http://upstream.invalid")
+
+	if err := client.writeHeartbeat(reqCtx); err != nil {
+		t.Fatalf("writeHeartbeat: %v", err)
+	}
+	if err := client.writeHeartbeat(reqCtx); err != nil {
+		t.Fatalf("writeHeartbeat: %v", err)
+	}
+	if StreamDataWritten(reqCtx) {
+		t.Fatal("StreamDataWritten = true after only heartbeats, want the stream still movable to another provider")
+	}
+	if err := client.writeSSELine(reqCtx, `data: {"choices":[]}`); err != nil {
+		t.Fatalf("writeSSELine: %v", err)
+	}
+	if !StreamDataWritten(reqCtx) {
+		t.Fatal("StreamDataWritten = false after a data line")
+	}
+}
@@ -0,0 +1,74 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// openRouterPassthroughFields are OpenRouter request fields that openai.ChatCompletionRequest has
+// no place for: the fallback model list, provider routing preferences, the routing strategy and
+// prompt transforms.
+var openRouterPassthroughFields = []string{"models", "provider", "route", "transforms"}
+
+type requestBodyKey struct{}
+
+// WithRequestBody attaches the client's raw chat completion body to ctx. Clients built with
+// WithOpenRouterPassthrough copy the OpenRouter-specific fields from it into the upstream request;
+// other clients ignore it.
+func WithRequestBody(ctx context.Context, body []byte) context.Context {
+	return context.WithValue(ctx, requestBodyKey{}, body)
+}
+
+// WithOpenRouterPassthrough forwards the OpenRouter-specific fields of the body attached with
+// WithRequestBody, which the OpenAI request struct would otherwise drop.
+func WithOpenRouterPassthrough() ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.openRouterPassthrough = true
+	}
+}
+
+// completionBody returns the body sent for an OpenAI-compatible chat completion: the request
+// itself, or its JSON with the passthrough fields of the original body merged in.
+func (c *ChatCompletionClient) completionBody(ctx context.Context, request openai.ChatCompletionRequest) (any, error) {
+	if !c.endpoint.openRouterPassthrough {
+		return request, nil
+	}
+	fields := openRouterFields(ctx)
+	if len(fields) == 0 {
+		return request, nil
+	}
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &body); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	return json.Marshal(body)
+}
+
+// openRouterFields extracts the passthrough fields from the body attached to ctx. A body that
+// does not parse has already been rejected by the route, so it simply yields no fields.
+func openRouterFields(ctx context.Context) map[string]json.RawMessage {
+	raw, ok := ctx.Value(requestBodyKey{}).([]byte)
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil
+	}
+	fields := make(map[string]json.RawMessage, len(openRouterPassthroughFields))
+	for _, key := range openRouterPassthroughFields {
+		if value, ok := body[key]; ok {
+			fields[key] = value
+		}
+	}
+	return fields
+}
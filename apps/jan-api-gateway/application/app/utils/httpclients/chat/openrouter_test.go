@@ -0,0 +1,116 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+func TestOpenRouterPassthrough(t *testing.T) {
+	const clientBody = `{
+		"model": "openai/gpt-4o",
+		"messages": [{"role": "user", "content": "hi"}],
+		"models": ["openai/gpt-4o", "anthropic/claude-3.5-sonnet"],
+		"provider": {"order": ["Azure", "OpenAI"], "allow_fallbacks": false, "data_collection": "deny"},
+		"route": "fallback",
+		"transforms": ["middle-out"],
+		"unknown": true
+	}`
+	request := openai.ChatCompletionRequest{
+		Model:    "openai/gpt-4o",
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+	}
+
+	tests := []struct {
+		name        string
+		opts        []ClientOption
+		body        string
+		stream      bool
+		passthrough bool
+	}{
+		{name: "openrouter", opts: []ClientOption{WithOpenRouterPassthrough()}, body: clientBody, passthrough: true},
+		{name: "openrouter stream", opts: []ClientOption{WithOpenRouterPassthrough()}, body: clientBody, stream: true, passthrough: true},
+		{name: "openrouter without a request body", opts: []ClientOption{WithOpenRouterPassthrough()}},
+		{name: "openai keeps strict fields", body: clientBody},
+		{name: "openai stream keeps strict fields", body: clientBody, stream: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := `{"id":"gen-1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hello"}}]}`
+			if tt.stream {
+				response = "data: [DONE]\n\n"
+			}
+			upstream, calls := recordingUpstream(t, response)
+			client := NewChatCompletionClient(resty.New(), "openrouter", upstream.URL+"/api/v1", tt.opts...)
+			ctx := context.Background()
+			if tt.body != "" {
+				ctx = WithRequestBody(ctx, []byte(tt.body))
+			}
+
+			if tt.stream {
+				streamed := request
+				streamed.Stream = true
+				reader, err := client.CreateChatCompletionStream(ctx, "", streamed)
+				if err != nil {
+					t.Fatalf("CreateChatCompletionStream: %v", err)
+				}
+				_, _ = io.ReadAll(reader)
+				_ = reader.Close()
+			} else {
+				resp, err := client.CreateChatCompletion(ctx, "", request)
+				if err != nil {
+					t.Fatalf("CreateChatCompletion: %v", err)
+				}
+				if resp.Choices[0].Message.Content != "hello" {
+					t.Fatalf("content = %q, want hello", resp.Choices[0].Message.Content)
+				}
+			}
+
+			if len(*calls) != 1 || (*calls)[0].Path != "/api/v1/chat/completions" {
+				t.Fatalf("upstream calls = %+v, want one POST to /api/v1/chat/completions", *calls)
+			}
+			var sent map[string]json.RawMessage
+			if err := json.Unmarshal((*calls)[0].Body, &sent); err != nil {
+				t.Fatalf("upstream body %s: %v", (*calls)[0].Body, err)
+			}
+			if string(sent["model"]) != `"openai/gpt-4o"` || sent["messages"] == nil {
+				t.Fatalf("upstream body %s lost the OpenAI fields", (*calls)[0].Body)
+			}
+			if _, ok := sent["unknown"]; ok {
+				t.Fatalf("upstream body %s carries a field that is not passed through", (*calls)[0].Body)
+			}
+			if !tt.passthrough {
+				for _, key := range openRouterPassthroughFields {
+					if _, ok := sent[key]; ok {
+						t.Fatalf("upstream body %s carries %s", (*calls)[0].Body, key)
+					}
+				}
+				return
+			}
+
+			var provider struct {
+				Order          []string `json:"order"`
+				AllowFallbacks *bool    `json:"allow_fallbacks"`
+				DataCollection string   `json:"data_collection"`
+			}
+			if err := json.Unmarshal(sent["provider"], &provider); err != nil {
+				t.Fatalf("provider %s: %v", sent["provider"], err)
+			}
+			if len(provider.Order) != 2 || provider.Order[0] != "Azure" || provider.AllowFallbacks == nil || *provider.AllowFallbacks || provider.DataCollection != "deny" {
+				t.Fatalf("provider preferences = %s, want them unchanged", sent["provider"])
+			}
+			var models []string
+			_ = json.Unmarshal(sent["models"], &models)
+			if len(models) != 2 || models[1] != "anthropic/claude-3.5-sonnet" {
+				t.Fatalf("models = %s, want the fallback list", sent["models"])
+			}
+			if string(sent["route"]) != `"fallback"` || string(sent["transforms"]) != `["middle-out"]` {
+				t.Fatalf("route = %s and transforms = %s, want them passed through", sent["route"], sent["transforms"])
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package chat
+
+import "strings"
+
+// WithPathPrefix inserts prefix, e.g. "/api/v1", between the base URL and the operation paths
+// such as /chat/completions, /models and /embeddings, for gateways that expose an
+// OpenAI-compatible API below a prefix. Azure OpenAI deployment URLs are not affected.
+func WithPathPrefix(prefix string) ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.pathPrefix = strings.TrimRight(strings.TrimSpace(prefix), "/")
+	}
+}
+
+// prefixedBaseURL appends the configured path prefix to the base URL.
+func (cfg endpointConfig) prefixedBaseURL(baseURL string) string {
+	if baseURL == "" {
+		return baseURL
+	}
+	return baseURL + cfg.pathPrefix
+}
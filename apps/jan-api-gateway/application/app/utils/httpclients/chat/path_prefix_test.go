@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+func TestPathPrefixEndpoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []ClientOption
+		paths []string
+	}{
+		{
+			name:  "no prefix",
+			paths: []string{"/gateway/chat/completions", "/gateway/embeddings", "/gateway/models"},
+		},
+		{
+			name:  "prefix",
+			opts:  []ClientOption{WithPathPrefix("/api/v1")},
+			paths: []string{"/gateway/api/v1/chat/completions", "/gateway/api/v1/embeddings", "/gateway/api/v1/models"},
+		},
+		{
+			name:  "prefix with a trailing slash",
+			opts:  []ClientOption{WithPathPrefix(" /api/v1/ ")},
+			paths: []string{"/gateway/api/v1/chat/completions", "/gateway/api/v1/embeddings", "/gateway/api/v1/models"},
+		},
+		{
+			name:  "azure deployments ignore the prefix",
+			opts:  []ClientOption{WithAzureDeployments(""), WithPathPrefix("/api/v1")},
+			paths: []string{"/gateway/openai/deployments/gpt-4o/chat/completions", "/gateway/openai/deployments/embed/embeddings", "/gateway/openai/models"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream, calls := recordingUpstream(t, `{"object":"list","data":[]}`)
+			baseURL := upstream.URL + "/gateway"
+			ctx := context.Background()
+
+			completions := NewChatCompletionClient(resty.New(), "upstream", baseURL, tt.opts...)
+			if _, err := completions.CreateChatCompletion(ctx, "", openai.ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			}); err != nil {
+				t.Fatalf("CreateChatCompletion: %v", err)
+			}
+			if _, err := completions.CreateEmbeddings(ctx, "", openai.EmbeddingRequest{Model: "embed", Input: "hi"}); err != nil {
+				t.Fatalf("CreateEmbeddings: %v", err)
+			}
+			if _, err := NewChatModelClient(resty.New(), "upstream", baseURL, tt.opts...).ListModels(ctx); err != nil {
+				t.Fatalf("ListModels: %v", err)
+			}
+
+			if len(*calls) != len(tt.paths) {
+				t.Fatalf("upstream received %d requests, want %d", len(*calls), len(tt.paths))
+			}
+			for i, path := range tt.paths {
+				if got := (*calls)[i].Path; got != path {
+					t.Errorf("request %d went to %s, want %s", i, got, path)
+				}
+			}
+		})
+	}
+}
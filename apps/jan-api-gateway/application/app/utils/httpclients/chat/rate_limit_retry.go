@@ -0,0 +1,80 @@
+package chat
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+// DefaultRateLimitRetryMaxWait is the longest Retry-After a rate-limited completion waits out
+// before its single retry when CHAT_RATE_LIMIT_RETRY_MAX_WAIT is unset.
+const DefaultRateLimitRetryMaxWait = 10 * time.Second
+
+// rateLimitRetryMaxWait returns CHAT_RATE_LIMIT_RETRY_MAX_WAIT, a Go duration such as 5s, falling
+// back to the default when it is unset or invalid. Zero disables the retry.
+func rateLimitRetryMaxWait() time.Duration {
+	value := strings.TrimSpace(environment_variables.EnvironmentVariables.CHAT_RATE_LIMIT_RETRY_MAX_WAIT)
+	if value == "" {
+		return DefaultRateLimitRetryMaxWait
+	}
+	maxWait, err := time.ParseDuration(value)
+	if err != nil || maxWait < 0 {
+		logger.GetLogger().Errorf("invalid CHAT_RATE_LIMIT_RETRY_MAX_WAIT %q, using %s", value, DefaultRateLimitRetryMaxWait)
+		return DefaultRateLimitRetryMaxWait
+	}
+	return maxWait
+}
+
+// retryAfterDelay parses a Retry-After header given either in seconds or as an HTTP date. A date
+// in the past yields zero.
+func retryAfterDelay(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// postWithRateLimitRetry sends a non-streaming request and, when the upstream answers 429 with a
+// Retry-After within the configured maximum wait, sends it once more after that delay. Streams
+// are never retried, as bytes may already have reached the client.
+func (c *ChatCompletionClient) postWithRateLimitRetry(ctx context.Context, send func() (*resty.Response, error)) (*resty.Response, error) {
+	resp, err := send()
+	if err != nil || resp.StatusCode() != http.StatusTooManyRequests {
+		return resp, err
+	}
+	maxWait := rateLimitRetryMaxWait()
+	if maxWait <= 0 {
+		return resp, nil
+	}
+	delay, ok := retryAfterDelay(resp.Header().Get("Retry-After"), time.Now())
+	if !ok || delay > maxWait {
+		return resp, nil
+	}
+
+	logger.GetLogger().Infof("%s rate limited the request, retrying once in %s", c.name, delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+	}
+	return send()
+}
@@ -0,0 +1,103 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", value: "3", want: 3 * time.Second, wantOK: true},
+		{name: "http date", value: now.Add(5 * time.Second).Format(http.TimeFormat), want: 5 * time.Second, wantOK: true},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "missing", value: "", wantOK: false},
+		{name: "negative", value: "-1", wantOK: false},
+		{name: "garbage", value: "soon", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterDelay(tt.value, now)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("retryAfterDelay(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionRetriesRateLimitOnce(t *testing.T) {
+	env := &environment_variables.EnvironmentVariables
+	previous := env.CHAT_RATE_LIMIT_RETRY_MAX_WAIT
+	defer func() { env.CHAT_RATE_LIMIT_RETRY_MAX_WAIT = previous }()
+
+	tests := []struct {
+		name       string
+		maxWait    string
+		retryAfter string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{name: "retried after Retry-After", maxWait: "5s", retryAfter: "0", wantCalls: 2},
+		{name: "delay above the cap", maxWait: "1s", retryAfter: "30", wantCalls: 1, wantErr: true},
+		{name: "retry disabled", maxWait: "0", retryAfter: "0", wantCalls: 1, wantErr: true},
+		{name: "no Retry-After", maxWait: "5s", wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.CHAT_RATE_LIMIT_RETRY_MAX_WAIT = tt.maxWait
+			calls := 0
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				if calls == 1 {
+					if tt.retryAfter != "" {
+						w.Header().Set("Retry-After", tt.retryAfter)
+					}
+					w.WriteHeader(http.StatusTooManyRequests)
+					_, _ = w.Write([]byte(`{"error":{"message":"slow down","type":"rate_limit_error"}}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`))
+			}))
+			defer upstream.Close()
+
+			client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+			response, err := client.CreateChatCompletion(context.Background(), "", openai.ChatCompletionRequest{
+				Model:    "gpt-test",
+				Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}},
+			})
+			if calls != tt.wantCalls {
+				t.Fatalf("upstream called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr {
+				var upstreamErr *UpstreamError
+				if !errors.As(err, &upstreamErr) || upstreamErr.StatusCode != http.StatusTooManyRequests {
+					t.Fatalf("err = %v, want upstream 429", err)
+				}
+				if upstreamErr.RetryAfter != tt.retryAfter {
+					t.Fatalf("RetryAfter = %q, want %q", upstreamErr.RetryAfter, tt.retryAfter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("completion failed: %v", err)
+			}
+			if got := response.Choices[0].Message.Content; got != "ok" {
+				t.Fatalf("content = %q, want ok", got)
+			}
+		})
+	}
+}
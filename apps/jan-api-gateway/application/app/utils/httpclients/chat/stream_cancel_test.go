@@ -0,0 +1,96 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"resty.dev/v3"
+)
+
+func TestStreamStopsWhenTheClientDisconnects(t *testing.T) {
+	reqCtx, _ := newStreamTestContext()
+	ctx, cancel := context.WithCancel(reqCtx.Request.Context())
+	defer cancel()
+	reqCtx.Request = reqCtx.Request.WithContext(ctx)
+
+	upstreamGone := make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"Once"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		// The user hits stop while a long generation is under way.
+		cancel()
+		<-r.Context().Done()
+		close(upstreamGone)
+	}))
+	defer upstream.Close()
+
+	client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.StreamChatCompletionToContext(reqCtx, "", streamRequest())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream kept running after the client disconnected")
+	}
+	select {
+	case <-upstreamGone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream request was not aborted")
+	}
+}
+
+// trackedBody records whether it was closed.
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func TestAbortOnDone(t *testing.T) {
+	t.Run("closes the body when the context ends", func(t *testing.T) {
+		body := &trackedBody{Reader: strings.NewReader("")}
+		ctx, cancel := context.WithCancel(context.Background())
+		abortOnDone(ctx, &resty.Response{RawResponse: &http.Response{Body: body}})
+		cancel()
+		deadline := time.Now().Add(5 * time.Second)
+		for !body.closed.Load() && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if !body.closed.Load() {
+			t.Fatal("body was not closed after the context ended")
+		}
+	})
+
+	t.Run("leaves the body alone once released", func(t *testing.T) {
+		body := &trackedBody{Reader: strings.NewReader("")}
+		ctx, cancel := context.WithCancel(context.Background())
+		release := abortOnDone(ctx, &resty.Response{RawResponse: &http.Response{Body: body}})
+		if !release() {
+			t.Fatal("release reported the watch had already fired")
+		}
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		if body.closed.Load() {
+			t.Fatal("body was closed after the watch was released")
+		}
+	})
+}
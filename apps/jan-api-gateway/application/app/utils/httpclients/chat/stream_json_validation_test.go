@@ -0,0 +1,76 @@
+package chat
+
+import (
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+func jsonModeStream(fragments ...string) []string {
+	lines := make([]string, 0, len(fragments)+1)
+	for _, fragment := range fragments {
+		lines = append(lines, `data: {"choices":[{"index":0,"delta":{"content":`+fragment+`}}]}`)
+	}
+	return append(lines, `data: [DONE]`)
+}
+
+func TestStreamJSONModeValidation(t *testing.T) {
+	env := &environment_variables.EnvironmentVariables
+	previousValidation, previousEmit := env.CHAT_STREAM_JSON_VALIDATION, env.CHAT_STREAM_JSON_EMIT_OBJECT
+	t.Cleanup(func() {
+		env.CHAT_STREAM_JSON_VALIDATION, env.CHAT_STREAM_JSON_EMIT_OBJECT = previousValidation, previousEmit
+	})
+
+	valid := jsonModeStream(`"{\"city\":"`, `" \"Paris\"}"`)
+	malformed := jsonModeStream(`"{\"city\":"`, `" \"Par"`)
+	tests := []struct {
+		name       string
+		lines      []string
+		validation bool
+		emit       bool
+		format     openai.ChatCompletionResponseFormatType
+		invalid    bool
+		event      string
+		done       bool
+	}{
+		{name: "valid stream", lines: valid, validation: true, format: openai.ChatCompletionResponseFormatTypeJSONObject, done: true},
+		{name: "valid stream with the parsed object", lines: valid, validation: true, emit: true, format: openai.ChatCompletionResponseFormatTypeJSONObject, event: "event: json_object\ndata: {\"city\":\"Paris\"}", done: true},
+		{name: "malformed stream", lines: malformed, validation: true, format: openai.ChatCompletionResponseFormatTypeJSONObject, invalid: true, event: "event: error\ndata: {\"error\":{\"message\":"},
+		{name: "malformed stream without the flag", lines: malformed, format: openai.ChatCompletionResponseFormatTypeJSONObject, done: true},
+		{name: "malformed text stream", lines: malformed, validation: true, format: openai.ChatCompletionResponseFormatTypeText, done: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.CHAT_STREAM_JSON_VALIDATION, env.CHAT_STREAM_JSON_EMIT_OBJECT = tt.validation, tt.emit
+			upstream := sseUpstream(t, tt.lines...)
+			reqCtx, recorder := newStreamTestContext()
+			request := streamRequest()
+			request.ResponseFormat = &openai.ChatCompletionResponseFormat{Type: tt.format}
+
+			client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", request)
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+			if (result.JSONValidationError != nil) != tt.invalid {
+				t.Fatalf("JSONValidationError = %v, want invalid %t", result.JSONValidationError, tt.invalid)
+			}
+			body := recorder.Body.String()
+			if tt.event != "" && !strings.Contains(body, tt.event) {
+				t.Fatalf("stream is missing %q: %q", tt.event, body)
+			}
+			if tt.event == "" && strings.Contains(body, "event: ") {
+				t.Fatalf("stream has an unexpected event: %q", body)
+			}
+			if tt.invalid && !strings.Contains(body, `"type":"invalid_json_output"`) {
+				t.Fatalf("error event is missing its type: %q", body)
+			}
+			if ends := strings.HasSuffix(strings.TrimSpace(body), "data: [DONE]"); ends != tt.done {
+				t.Fatalf("stream ends with [DONE] = %t, want %t: %q", ends, tt.done, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package chat
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"resty.dev/v3"
+)
+
+// sentenceStream streams "The capital of France is Paris." one token per delta, as OpenAI
+// does, so the stream has 7 completion tokens. The prompt has 12 tokens.
+var sentenceStream = []string{
+	`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"The"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":" capital"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":" of"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":" France"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":" is"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":" Paris"}}]}`,
+	`data: {"choices":[{"index":0,"delta":{"content":"."},"finish_reason":"stop"}]}`,
+}
+
+const (
+	sentencePromptTokens     = 12
+	sentenceCompletionTokens = 7
+)
+
+func sentenceRequest(includeUsage bool) openai.ChatCompletionRequest {
+	request := streamRequest()
+	request.Messages = []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "What is the capital of France? Answer in one sentence."},
+	}
+	if includeUsage {
+		request.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	return request
+}
+
+// withinTolerance reports whether got is within a quarter of want.
+func withinTolerance(got, want int) bool {
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff*4 <= want
+}
+
+// usageChunks returns the usage of every chunk in the stream that carries one, and whether a
+// usage chunk came after [DONE].
+func usageChunks(t *testing.T, body string) ([]openai.Usage, bool) {
+	t.Helper()
+	var usages []openai.Usage
+	done := false
+	afterDone := false
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, dataPrefix)
+		if !ok {
+			continue
+		}
+		if data == doneMarker {
+			done = true
+			continue
+		}
+		var chunk openai.ChatCompletionStreamResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid chunk %q: %v", data, err)
+		}
+		if chunk.Usage != nil {
+			usages = append(usages, *chunk.Usage)
+			afterDone = afterDone || done
+		}
+	}
+	return usages, afterDone
+}
+
+func TestStreamUsageEstimate(t *testing.T) {
+	upstreamUsage := `data: {"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7,"total_tokens":19}}`
+	tests := []struct {
+		name         string
+		lines        []string
+		includeUsage bool
+		estimated    bool
+		usageChunks  int
+	}{
+		{name: "estimated and surfaced with include_usage", lines: sentenceStream, includeUsage: true, estimated: true, usageChunks: 1},
+		{name: "estimated without include_usage", lines: sentenceStream, estimated: true},
+		{name: "upstream usage wins", lines: append(append([]string{}, sentenceStream...), upstreamUsage), includeUsage: true, usageChunks: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream := sseUpstream(t, append(tt.lines, `data: [DONE]`)...)
+			reqCtx, recorder := newStreamTestContext()
+
+			client := NewChatCompletionClient(resty.New(), "upstream", upstream.URL)
+			result, err := client.StreamChatCompletionToContext(reqCtx, "", sentenceRequest(tt.includeUsage))
+			if err != nil {
+				t.Fatalf("stream failed: %v", err)
+			}
+			if result.UsageEstimated != tt.estimated {
+				t.Fatalf("UsageEstimated = %t, want %t", result.UsageEstimated, tt.estimated)
+			}
+			usage := result.Usage
+			if usage.CompletionTokens != sentenceCompletionTokens {
+				t.Fatalf("completion tokens = %d, want %d", usage.CompletionTokens, sentenceCompletionTokens)
+			}
+			if !withinTolerance(usage.PromptTokens, sentencePromptTokens) {
+				t.Fatalf("prompt tokens = %d, want %d within 25%%", usage.PromptTokens, sentencePromptTokens)
+			}
+			if usage.TotalTokens != usage.PromptTokens+usage.CompletionTokens {
+				t.Fatalf("total tokens = %d, want %d", usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens)
+			}
+
+			written, afterDone := usageChunks(t, recorder.Body.String())
+			if len(written) != tt.usageChunks {
+				t.Fatalf("stream carried %d usage chunks, want %d", len(written), tt.usageChunks)
+			}
+			if afterDone {
+				t.Fatal("usage chunk was written after [DONE]")
+			}
+			if len(written) > 0 && written[0] != usage {
+				t.Fatalf("streamed usage %+v, want %+v", written[0], usage)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// translatedStreamChunk is the OpenAI chunk written for translated streams. It leaves out the
+// Azure-specific fields openai.ChatCompletionStreamResponse always serializes.
+type translatedStreamChunk struct {
+	ID      string                   `json:"id"`
+	Object  string                   `json:"object"`
+	Created int64                    `json:"created"`
+	Model   string                   `json:"model"`
+	Choices []translatedStreamChoice `json:"choices"`
+	Usage   *openai.Usage            `json:"usage,omitempty"`
+}
+
+type translatedStreamChoice struct {
+	Index        int                                    `json:"index"`
+	Delta        openai.ChatCompletionStreamChoiceDelta `json:"delta"`
+	FinishReason *openai.FinishReason                   `json:"finish_reason"`
+}
+
+// translatedStream buffers the OpenAI SSE lines produced from a vendor-native stream. Vendor
+// readers embed it and fill it from their advance function.
+type translatedStream struct {
+	id      string
+	model   string
+	created int64
+
+	pending bytes.Buffer
+	done    bool
+}
+
+func newTranslatedStream(model string) translatedStream {
+	return translatedStream{model: model, created: time.Now().Unix()}
+}
+
+// read serves buffered lines, calling advance whenever the buffer runs dry.
+func (s *translatedStream) read(p []byte, advance func() error) (int, error) {
+	for s.pending.Len() == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := advance(); err != nil {
+			return 0, err
+		}
+	}
+	return s.pending.Read(p)
+}
+
+func (s *translatedStream) writeChunk(delta openai.ChatCompletionStreamChoiceDelta, finishReason openai.FinishReason, usage *openai.Usage) error {
+	choice := translatedStreamChoice{Delta: delta}
+	if finishReason != "" {
+		choice.FinishReason = &finishReason
+	}
+	chunk := translatedStreamChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: s.created,
+		Model:   s.model,
+		Choices: []translatedStreamChoice{choice},
+		Usage:   usage,
+	}
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	s.pending.WriteString(dataPrefix)
+	s.pending.Write(data)
+	s.pending.WriteString(newlineChar + newlineChar)
+	return nil
+}
+
+// finish writes the [DONE] marker and ends the stream.
+func (s *translatedStream) finish() {
+	s.pending.WriteString(dataPrefix + doneMarker + newlineChar + newlineChar)
+	s.done = true
+}
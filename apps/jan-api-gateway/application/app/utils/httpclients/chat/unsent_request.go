@@ -0,0 +1,18 @@
+package chat
+
+// WithUnsentRequestHook registers fn to run whenever a completion fails before its request is
+// sent, for example on an unsupported Bedrock model or a body that does not encode. The upstream
+// then never saw the request, so callers tracking its outcome hear about it here.
+func WithUnsentRequestHook(fn func()) ClientOption {
+	return func(cfg *endpointConfig) {
+		cfg.onUnsent = fn
+	}
+}
+
+// unsent reports a request that failed before reaching the upstream and returns its error.
+func (c *ChatCompletionClient) unsent(err error) error {
+	if c.endpoint.onUnsent != nil {
+		c.endpoint.onUnsent()
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"resty.dev/v3"
+)
+
+// UpstreamError is returned when the provider answers with a non-success HTTP status. Type, Code
+// and Message are taken from an OpenAI-shaped error body when the provider sent one.
+type UpstreamError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Param      string
+	Message    string
+	// RetryAfter is the upstream's Retry-After header, kept so rate limits reach the client.
+	RetryAfter string
+	summary    string
+}
+
+func (e *UpstreamError) Error() string {
+	return e.summary
+}
+
+// upstreamErrorBody covers the OpenAI shape, {"error": {"message", "type", "code", "param"}}, and
+// the looser {"error": "..."} and {"message": "..."} bodies some compatible servers return.
+type upstreamErrorBody struct {
+	Error   json.RawMessage `json:"error"`
+	Message string          `json:"message"`
+	Type    string          `json:"type"`
+}
+
+type upstreamErrorObject struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+	Param   any    `json:"param"`
+}
+
+func newUpstreamError(name string, message string, resp *resty.Response) *UpstreamError {
+	status := statusCode(resp)
+	upstreamErr := &UpstreamError{
+		StatusCode: status,
+		summary:    fmt.Sprintf("%s: %s with status %d", name, message, status),
+	}
+	if resp == nil || resp.RawResponse == nil {
+		return upstreamErr
+	}
+	upstreamErr.RetryAfter = strings.TrimSpace(resp.Header().Get("Retry-After"))
+	if resp.RawResponse.Body == nil {
+		return upstreamErr
+	}
+	defer resp.RawResponse.Body.Close()
+	body, err := io.ReadAll(resp.RawResponse.Body)
+	if err != nil {
+		return upstreamErr
+	}
+	trimmed := strings.TrimSpace(string(body))
+	if trimmed == "" {
+		return upstreamErr
+	}
+	upstreamErr.summary = fmt.Sprintf("%s: %s with status %d: %s", name, message, status, trimmed)
+	upstreamErr.parseBody([]byte(trimmed))
+	return upstreamErr
+}
+
+func (e *UpstreamError) parseBody(body []byte) {
+	var parsed upstreamErrorBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return
+	}
+	e.Message = parsed.Message
+	e.Type = parsed.Type
+	if len(parsed.Error) == 0 {
+		return
+	}
+	var object upstreamErrorObject
+	if err := json.Unmarshal(parsed.Error, &object); err == nil {
+		e.Message = object.Message
+		if object.Type != "" {
+			e.Type = object.Type
+		}
+		e.Code = stringifyErrorField(object.Code)
+		e.Param = stringifyErrorField(object.Param)
+		return
+	}
+	var text string
+	if err := json.Unmarshal(parsed.Error, &text); err == nil {
+		e.Message = text
+	}
+}
+
+// stringifyErrorField renders code and param values, which providers send as strings or numbers.
+func stringifyErrorField(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/httpclients"
+	"menlo.ai/jan-api-gateway/config/environment_variables"
+	"resty.dev/v3"
+)
+
+// requestTimeout bounds the moderation call, which sits on the critical path of every completion.
+const requestTimeout = 10 * time.Second
+
+var ModerationRestyClient *resty.Client
+
+func Init() {
+	ModerationRestyClient = httpclients.NewClient("ModerationClient")
+}
+
+// ModerationClient calls an OpenAI-compatible /moderations endpoint.
+type ModerationClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewModerationClient() *ModerationClient {
+	return &ModerationClient{
+		baseURL: strings.TrimRight(strings.TrimSpace(environment_variables.EnvironmentVariables.MODERATION_API_URL), "/"),
+		apiKey:  environment_variables.EnvironmentVariables.MODERATION_API_KEY,
+		model:   environment_variables.EnvironmentVariables.MODERATION_MODEL,
+	}
+}
+
+// Configured reports whether a moderation endpoint has been set.
+func (c *ModerationClient) Configured() bool {
+	return c.baseURL != ""
+}
+
+type ModerationRequest struct {
+	Input []string `json:"input"`
+	Model string   `json:"model,omitempty"`
+}
+
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+func (c *ModerationClient) Moderate(ctx context.Context, input []string) (*ModerationResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var result ModerationResponse
+	req := ModerationRestyClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetBody(ModerationRequest{Input: input, Model: c.model}).
+		SetResult(&result)
+	if strings.TrimSpace(c.apiKey) != "" {
+		req.SetHeader("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := req.Post(c.baseURL + "/moderations")
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("moderation API error: %s", resp.Status())
+	}
+	return &result, nil
+}
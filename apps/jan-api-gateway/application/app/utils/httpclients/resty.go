@@ -11,7 +11,13 @@ import (
 )
 
 func NewClient(clientName string) *resty.Client {
-	client := resty.New()
+	return NewClientWithTransportSettings(clientName, nil)
+}
+
+// NewClientWithTransportSettings is NewClient with custom dialer and connection pool settings; nil
+// keeps the resty defaults.
+func NewClientWithTransportSettings(clientName string, settings *resty.TransportSettings) *resty.Client {
+	client := resty.NewWithTransportSettings(settings)
 	client.AddRequestMiddleware(func(c *resty.Client, r *resty.Request) error {
 		start := time.Now()
 		ctx := context.WithValue(r.Context(), contextkeys.HttpClientStartsAt{}, start)
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"menlo.ai/jan-api-gateway/app/utils/httpclients"
+	"resty.dev/v3"
+)
+
+// requestTimeout bounds a single delivery attempt.
+const requestTimeout = 10 * time.Second
+
+const (
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the request body.
+	SignatureHeader = "X-Jan-Signature"
+	// EventHeader carries the event type, e.g. provider.sync.completed.
+	EventHeader = "X-Jan-Event"
+)
+
+// ErrNonPublicAddress is returned when a webhook host is or resolves to an address that is not
+// publicly routable, so organizations cannot make the gateway call its own network.
+var ErrNonPublicAddress = errors.New("webhook address is not publicly routable")
+
+var WebhookRestyClient *resty.Client
+
+func Init() {
+	WebhookRestyClient = httpclients.NewClient("WebhookClient")
+	// Hostnames are checked where they resolve, so a public name pointing at an internal
+	// address is refused as well.
+	if transport, err := WebhookRestyClient.HTTPTransport(); err == nil {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   rejectNonPublicDial,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+}
+
+// IsPublicAddress reports whether ip may receive webhooks. Loopback, private, link-local
+// (including the 169.254.169.254 metadata endpoint), shared, unspecified and multicast
+// addresses are refused.
+func IsPublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is internal to the provider network.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckHost rejects webhook hosts that name a non-public address directly: IP literals and
+// localhost names. Other hostnames are checked when they are dialed.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrNonPublicAddress
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !IsPublicAddress(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+func rejectNonPublicDial(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Sign returns the SignatureHeader value of body under secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts a signed event body to url. Non-2xx responses are returned as errors.
+func Deliver(ctx context.Context, url string, eventType string, body []byte, secret string) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	resp, err := WebhookRestyClient.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader(EventHeader, eventType).
+		SetHeader(SignatureHeader, Sign(secret, body)).
+		SetBody(body).
+		Post(url)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return fmt.Errorf("webhook endpoint returned %s", resp.Status())
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{host: "hooks.example.com", allowed: true},
+		{host: "203.0.113.10", allowed: true},
+		{host: "2001:4860:4860::8888", allowed: true},
+		{host: "localhost", allowed: false},
+		{host: "api.localhost.", allowed: false},
+		{host: "127.0.0.1", allowed: false},
+		{host: "::1", allowed: false},
+		{host: "10.0.0.5", allowed: false},
+		{host: "172.16.3.4", allowed: false},
+		{host: "192.168.1.1", allowed: false},
+		{host: "169.254.169.254", allowed: false},
+		{host: "100.64.0.1", allowed: false},
+		{host: "0.0.0.0", allowed: false},
+		{host: "fd00:ec2::254", allowed: false},
+		{host: "fe80::1", allowed: false},
+		{host: "::ffff:127.0.0.1", allowed: false},
+	}
+	for _, tt := range tests {
+		err := CheckHost(tt.host)
+		if tt.allowed && err != nil {
+			t.Errorf("CheckHost(%q) = %v, want allowed", tt.host, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("CheckHost(%q) = %v, want ErrNonPublicAddress", tt.host, err)
+		}
+	}
+}
+
+func TestIsPublicAddressRejectsMetadataEndpoint(t *testing.T) {
+	if IsPublicAddress(netip.MustParseAddr("169.254.169.254")) {
+		t.Fatal("metadata address reported as public")
+	}
+}
+
+func TestDeliverRefusesNonPublicAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	Init()
+
+	// The test server listens on loopback, which webhooks must never reach even by hostname.
+	err := Deliver(context.Background(), server.URL, "provider.sync.completed", []byte(`{}`), "secret")
+	if !errors.Is(err, ErrNonPublicAddress) {
+		t.Fatalf("Deliver error = %v, want ErrNonPublicAddress", err)
+	}
+	if called {
+		t.Fatal("webhook reached a loopback server")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"id":"evt"}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=12e2aad92c7ef49f17a6c4eb30b559f19c5b691389f8fd287be38637cdefab09"
+	if got := Sign("secret", []byte(`{"id":"evt"}`)); got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// CompletionsTotal counts chat completions by the provider that handled them, the model and
+	// the HTTP status returned to the client.
+	CompletionsTotal = NewCounterVec("jan_completions_total",
+		"Chat completions by provider, model and response status.", "provider", "model", "status")
+	// CompletionDuration observes how long chat completions take end to end.
+	CompletionDuration = NewHistogramVec("jan_completion_duration_seconds",
+		"Chat completion duration in seconds by provider and model.", DefaultDurationBuckets, "provider", "model")
+	// ProviderUp is 1 when the last health check of a provider succeeded and 0 when it failed.
+	ProviderUp = NewGaugeVec("jan_provider_up",
+		"Whether the last health check of the provider succeeded.", "slug")
+	// UpstreamErrorsTotal counts failed upstream calls by provider and error type.
+	UpstreamErrorsTotal = NewCounterVec("jan_upstream_errors_total",
+		"Failed upstream calls by provider and error type.", "provider", "type")
+)
+
+// DefaultDurationBuckets suit request latencies from tens of milliseconds up to long generations.
+var DefaultDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	registryMu sync.Mutex
+	registry   []*family
+)
+
+// Write renders every registered metric in the Prometheus text exposition format.
+func Write(w io.Writer) error {
+	registryMu.Lock()
+	families := append([]*family(nil), registry...)
+	registryMu.Unlock()
+
+	for _, f := range families {
+		if err := f.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// bucketCounts holds non-cumulative counts per histogram bucket; the last entry is +Inf.
+	bucketCounts []uint64
+	count        uint64
+}
+
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func register(name, help, kind string, buckets []float64, labelNames []string) *family {
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	registryMu.Lock()
+	registry = append(registry, f)
+	registryMu.Unlock()
+	return f
+}
+
+// with returns the series for the label values, creating it on first use. The caller holds f.mu.
+func (f *family) with(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == "histogram" {
+			s.bucketCounts = make([]uint64, len(f.buckets)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (f *family) write(w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		if f.kind != "histogram" {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", f.name, f.labels(s.labelValues, ""), formatFloat(s.value)); err != nil {
+				return err
+			}
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += s.bucketCounts[i]
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labels(s.labelValues, formatFloat(upper)), cumulative); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			f.name, f.labels(s.labelValues, "+Inf"), s.count,
+			f.name, f.labels(s.labelValues, ""), formatFloat(s.value),
+			f.name, f.labels(s.labelValues, ""), s.count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labels renders the label set of a series, adding the histogram le label when le is set.
+func (f *family) labels(labelValues []string, le string) string {
+	pairs := make([]string, 0, len(labelValues)+1)
+	for i, name := range f.labelNames {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(labelValues[i])))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf(`le="%s"`, le))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct {
+	f *family
+}
+
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{f: register(name, help, "counter", nil, labelNames)}
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.with(labelValues).value += delta
+}
+
+// GaugeVec is a gauge partitioned by label values.
+type GaugeVec struct {
+	f *family
+}
+
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{f: register(name, help, "gauge", nil, labelNames)}
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.with(labelValues).value = value
+}
+
+// HistogramVec is a histogram partitioned by label values.
+type HistogramVec struct {
+	f *family
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	return &HistogramVec{f: register(name, help, "histogram", buckets, labelNames)}
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.with(labelValues)
+	index := sort.SearchFloat64s(h.f.buckets, value)
+	s.bucketCounts[index]++
+	s.value += value
+	s.count++
+}
@@ -0,0 +1,57 @@
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy configures Do. Retryable decides whether an error is worth another attempt; when it is
+// nil every error is retried.
+type Policy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Retryable func(error) bool
+}
+
+// Do calls fn until it succeeds, returns an error the policy does not retry, or the attempts run
+// out. Attempt n waits a random duration up to BaseDelay*2^(n-1), capped at MaxDelay, so callers
+// retrying together do not hit the upstream in lockstep. The last error is returned, or the
+// context error when ctx ends while waiting.
+func Do(ctx context.Context, policy Policy, fn func(ctx context.Context) error) error {
+	attempts := max(policy.Attempts, 1)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt == attempts || (policy.Retryable != nil && !policy.Retryable(err)) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff(policy, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// backoff returns the full-jitter delay before the attempt following attempt.
+func backoff(policy Policy, attempt int) time.Duration {
+	if policy.BaseDelay <= 0 {
+		return 0
+	}
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
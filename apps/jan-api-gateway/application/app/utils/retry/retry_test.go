@@ -0,0 +1,74 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+	policy := Policy{
+		Attempts:  3,
+		Retryable: func(err error) bool { return errors.Is(err, errTransient) },
+	}
+
+	tests := []struct {
+		name     string
+		failures []error
+		want     error
+		calls    int
+	}{
+		{name: "first attempt succeeds", calls: 1},
+		{name: "transient then success", failures: []error{errTransient, errTransient}, calls: 3},
+		{name: "persistent failure", failures: []error{errTransient, errTransient, errTransient, errTransient}, want: errTransient, calls: 3},
+		{name: "error that is not retried", failures: []error{errFatal}, want: errFatal, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), policy, func(ctx context.Context) error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("Do = %v, want %v", err, tt.want)
+			}
+			if calls != tt.calls {
+				t.Fatalf("fn called %d times, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestDoStopsWhenTheContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Do(ctx, Policy{Attempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errors.New("transient")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("Do = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 40: 300 * time.Millisecond} {
+		for range 100 {
+			if delay := backoff(policy, attempt); delay < 0 || delay > limit {
+				t.Fatalf("backoff(%d) = %s, want within [0, %s]", attempt, delay, limit)
+			}
+		}
+	}
+	if delay := backoff(Policy{}, 1); delay != 0 {
+		t.Fatalf("backoff without a base delay = %s, want 0", delay)
+	}
+}
@@ -0,0 +1,36 @@
+package tokenizer
+
+import (
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// TODO it's raw solution, we need to use the official openai tokenizer like tiktoken
+// EstimateTokens provides a rough estimation of token count for messages
+func EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	var allText strings.Builder
+
+	for _, msg := range messages {
+		allText.WriteString(msg.Content)
+		allText.WriteString(" ")
+
+		if msg.FunctionCall != nil {
+			allText.WriteString(msg.FunctionCall.Name)
+			allText.WriteString(" ")
+			allText.WriteString(msg.FunctionCall.Arguments)
+			allText.WriteString(" ")
+		}
+
+		for _, toolCall := range msg.ToolCalls {
+			allText.WriteString(toolCall.ID)
+			allText.WriteString(" ")
+			allText.WriteString(toolCall.Function.Name)
+			allText.WriteString(" ")
+			allText.WriteString(toolCall.Function.Arguments)
+			allText.WriteString(" ")
+		}
+	}
+
+	return len(strings.Fields(allText.String()))
+}
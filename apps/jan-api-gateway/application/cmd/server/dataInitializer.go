@@ -3,14 +3,25 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/cron"
 	"menlo.ai/jan-api-gateway/app/domain/model"
 	"menlo.ai/jan-api-gateway/app/domain/organization"
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
+	"menlo.ai/jan-api-gateway/app/utils/logger"
+	"menlo.ai/jan-api-gateway/app/utils/ptr"
 	"menlo.ai/jan-api-gateway/config/environment_variables"
 )
 
+const (
+	modelWarmupConcurrency = 4
+	modelWarmupTimeout     = 30 * time.Second
+)
+
 type DataInitializer struct {
 	authService         *auth.AuthService
 	providerRegistry    *model.ProviderRegistryService
@@ -24,6 +35,8 @@ func (d *DataInitializer) Install(ctx context.Context) error {
 		return err
 	}
 
+	d.checkProviderSecret(ctx)
+
 	if environment_variables.EnvironmentVariables.JAN_INFERENCE_SETUP {
 		err = d.setupJanProvider(ctx)
 		if err != nil {
@@ -31,6 +44,8 @@ func (d *DataInitializer) Install(ctx context.Context) error {
 		}
 	}
 
+	go d.warmModels(context.Background())
+
 	return nil
 }
 
@@ -38,6 +53,23 @@ func (d *DataInitializer) installDefaultOrganization(ctx context.Context) error
 	return d.authService.InitOrganization(ctx)
 }
 
+// checkProviderSecret warns when MODEL_PROVIDER_SECRET is unset although providers store encrypted
+// API keys, since those keys cannot be decrypted and calls to the providers will fail. Providers
+// without a key keep working, so startup continues.
+func (d *DataInitializer) checkProviderSecret(ctx context.Context) {
+	if strings.TrimSpace(environment_variables.EnvironmentVariables.MODEL_PROVIDER_SECRET) != "" {
+		return
+	}
+	count, err := d.providerRegistry.CountProvidersWithAPIKey(ctx)
+	if err != nil {
+		logger.GetLogger().Errorf("failed to count providers with an API key: %v", err)
+		return
+	}
+	if count > 0 {
+		logger.GetLogger().Warnf("MODEL_PROVIDER_SECRET is not set but %d provider(s) store an encrypted API key; their keys cannot be decrypted until the secret is configured", count)
+	}
+}
+
 func (d *DataInitializer) setupJanProvider(ctx context.Context) error {
 	// Skip if default organization is not set
 	if organization.DEFAULT_ORGANIZATION == nil {
@@ -86,3 +118,57 @@ func (d *DataInitializer) setupJanProvider(ctx context.Context) error {
 
 	return nil
 }
+
+// warmModels primes the accessible provider cache of the default organization and lists the
+// models of each of its active providers, which also opens the pooled upstream connections, so
+// the first requests after a deploy do not pay for it. It runs in the background with bounded
+// concurrency after a random jitter, so replicas restarted together by a deploy do not list the
+// upstream models at once; failures are logged and do not affect startup.
+func (d *DataInitializer) warmModels(ctx context.Context) {
+	if organization.DEFAULT_ORGANIZATION == nil {
+		return
+	}
+	if !cron.Jitter(ctx) {
+		return
+	}
+	start := time.Now()
+	organizationID := organization.DEFAULT_ORGANIZATION.ID
+
+	if err := d.providerRegistry.WarmAccessibleProviderModels(ctx, organizationID); err != nil {
+		logger.GetLogger().Warnf("model warmup: failed to load accessible providers: %v", err)
+	}
+
+	providers, err := d.providerRegistry.ListAccessibleProvidersByFilter(ctx, organizationID, nil, model.ProviderFilter{Active: ptr.ToBool(true)})
+	if err != nil {
+		logger.GetLogger().Errorf("model warmup: failed to list providers: %v", err)
+		return
+	}
+
+	var warmed, failed int
+	var mu sync.Mutex
+	sem := make(chan struct{}, modelWarmupConcurrency)
+	var wg sync.WaitGroup
+	for _, provider := range providers {
+		if provider == nil || provider.ProjectID != nil {
+			continue
+		}
+		warmed++
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(provider *model.Provider) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			warmCtx, cancel := context.WithTimeout(ctx, modelWarmupTimeout)
+			defer cancel()
+			if _, err := d.inferenceProvider.ListModels(warmCtx, provider); err != nil {
+				logger.GetLogger().Warnf("model warmup: failed to list models for %s: %v", provider.Slug, err)
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(provider)
+	}
+	wg.Wait()
+
+	logger.GetLogger().Infof("model warmup completed for %d provider(s) in %s, %d failed", warmed, time.Since(start).Round(time.Millisecond), failed)
+}
@@ -11,7 +11,9 @@ import (
 	"menlo.ai/jan-api-gateway/app/domain/cron"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database"
 	apphttp "menlo.ai/jan-api-gateway/app/interfaces/http"
+	"menlo.ai/jan-api-gateway/app/utils/httpclients/moderation"
 	"menlo.ai/jan-api-gateway/app/utils/httpclients/serper"
+	"menlo.ai/jan-api-gateway/app/utils/httpclients/webhook"
 	"menlo.ai/jan-api-gateway/app/utils/logger"
 	"menlo.ai/jan-api-gateway/config/environment_variables"
 )
@@ -21,22 +23,25 @@ type Application struct {
 	CronService *cron.CronService
 }
 
-func (application *Application) Start() {
+func (application *Application) Start() error {
 	// Start cron service
 	cronTab := crontab.New()
 	background := context.Background()
-	application.CronService.Start(background, cronTab)
+	if err := application.CronService.Start(background, cronTab); err != nil {
+		cronTab.Shutdown()
+		return err
+	}
 
 	// Start HTTP server
-	if err := application.HttpServer.Run(); err != nil {
-		panic(err)
-	}
+	return application.HttpServer.Run()
 }
 
 func init() {
 	logger.GetLogger()
 	environment_variables.EnvironmentVariables.LoadFromEnv()
 	serper.Init()
+	moderation.Init()
+	webhook.Init()
 }
 
 // @title Jan Server
@@ -50,6 +55,9 @@ func init() {
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
 	background := context.Background()
+	if err := cron.ValidateSchedules(); err != nil {
+		logger.GetLogger().Fatalf("invalid configuration: %v", err)
+	}
 
 	// Expose pprof endpoints for profiling (for Grafana Alloy/Pyroscope Go pull mode)
 	go func() {
@@ -64,6 +72,8 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+	// Metrics are unauthenticated, so they are only served on the internal pprof listener
+	nethttp.Handle("/metrics", application.HttpServer.MetricsHandler())
 	err = database.Migration()
 	if err != nil {
 		panic(err)
@@ -76,5 +86,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	application.Start()
+	if err := application.Start(); err != nil {
+		logger.GetLogger().Fatalf("failed to start: %v", err)
+	}
 }
@@ -10,15 +10,20 @@ import (
 	"gorm.io/gorm"
 	"menlo.ai/jan-api-gateway/app/domain/apikey"
 	"menlo.ai/jan-api-gateway/app/domain/auth"
+	"menlo.ai/jan-api-gateway/app/domain/contentfilter"
 	"menlo.ai/jan-api-gateway/app/domain/conversation"
 	"menlo.ai/jan-api-gateway/app/domain/cron"
 	"menlo.ai/jan-api-gateway/app/domain/invite"
 	"menlo.ai/jan-api-gateway/app/domain/mcp/serpermcp"
 	"menlo.ai/jan-api-gateway/app/domain/model"
+	"menlo.ai/jan-api-gateway/app/domain/moderation"
 	"menlo.ai/jan-api-gateway/app/domain/organization"
 	"menlo.ai/jan-api-gateway/app/domain/project"
+	"menlo.ai/jan-api-gateway/app/domain/ratelimit"
 	"menlo.ai/jan-api-gateway/app/domain/response"
+	"menlo.ai/jan-api-gateway/app/domain/spend"
 	"menlo.ai/jan-api-gateway/app/domain/user"
+	"menlo.ai/jan-api-gateway/app/domain/webhook"
 	"menlo.ai/jan-api-gateway/app/domain/workspace"
 	"menlo.ai/jan-api-gateway/app/infrastructure/cache"
 	"menlo.ai/jan-api-gateway/app/infrastructure/database"
@@ -36,6 +41,7 @@ import (
 	"menlo.ai/jan-api-gateway/app/infrastructure/inference"
 	"menlo.ai/jan-api-gateway/app/interfaces/http"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1"
+	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/admin"
 	auth2 "menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/auth"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/auth/google"
 	"menlo.ai/jan-api-gateway/app/interfaces/http/routes/v1/chat"
@@ -83,17 +89,28 @@ func CreateApplication() (*Application, error) {
 	providerModelService := model.NewProviderModelService(providerModelRepository)
 	modelCatalogRepository := modelrepo.NewModelCatalogGormRepository(transactionDatabase)
 	modelCatalogService := model.NewModelCatalogService(modelCatalogRepository)
-	providerRegistryService := model.NewProviderRegistryService(providerRepository, providerModelService, modelCatalogService)
 	inferenceProvider := inference.NewInferenceProvider()
+	modelAliasRepository := modelrepo.NewModelAliasGormRepository(transactionDatabase)
+	modelAliasService := model.NewModelAliasService(modelAliasRepository)
+	webhookService := webhook.NewWebhookService(organizationService)
+	providerRegistryService := model.NewProviderRegistryService(providerRepository, providerModelService, modelCatalogService, inferenceProvider, inferenceProvider, modelAliasService, organizationService, webhookService, redisCacheService, transactionDatabase)
 	projectsRoute := projects.NewProjectsRoute(projectService, apiKeyService, authService, projectApiKeyRoute, providerRegistryService, inferenceProvider)
 	invitesRoute := invites.NewInvitesRoute(inviteService, projectService, organizationService, authService)
-	modelProviderRoute := organization2.NewModelProviderRoute(authService, providerRegistryService, inferenceProvider)
-	organizationRoute := organization2.NewOrganizationRoute(adminApiKeyAPI, projectsRoute, invitesRoute, modelProviderRoute, authService)
-	completionAPI := chat.NewCompletionAPI(inferenceProvider, providerRegistryService)
-	chatRoute := chat.NewChatRoute(completionAPI)
+	modelProviderRoute := organization2.NewModelProviderRoute(authService, providerRegistryService, inferenceProvider, userService, projectService, modelAliasService, modelCatalogService)
+	organizationSettingsRoute := organization2.NewOrganizationSettingsRoute(authService, providerRegistryService, webhookService)
+	organizationRoute := organization2.NewOrganizationRoute(adminApiKeyAPI, projectsRoute, invitesRoute, modelProviderRoute, organizationSettingsRoute, authService)
+	contentFilterService := contentfilter.NewContentFilterService()
+	spendTracker := spend.NewSpendTracker(redisCacheService, organizationService, projectService, authService)
+	modelRateLimiter := ratelimit.NewModelRateLimiter(redisCacheService)
+	moderationService := moderation.NewModerationService()
 	conversationRepository := conversationrepo.NewConversationGormRepository(transactionDatabase)
 	itemRepository := itemrepo.NewItemGormRepository(transactionDatabase)
 	conversationService := conversation.NewService(conversationRepository, itemRepository)
+	workspaceRepository := workspacerepo.NewWorkspaceGormRepository(transactionDatabase)
+	workspaceService := workspace.NewWorkspaceService(workspaceRepository, conversationRepository)
+	completionAPI := chat.NewCompletionAPI(inferenceProvider, providerRegistryService, contentFilterService, spendTracker, modelRateLimiter, moderationService, authService, conversationService, workspaceService)
+	embeddingsAPI := chat.NewEmbeddingsAPI(inferenceProvider, providerRegistryService, providerModelService, authService)
+	chatRoute := chat.NewChatRoute(completionAPI, embeddingsAPI)
 	completionNonStreamHandler := conv.NewCompletionNonStreamHandler(inferenceProvider, conversationService)
 	completionStreamHandler := conv.NewCompletionStreamHandler(inferenceProvider, conversationService)
 	convCompletionAPI := conv.NewConvCompletionAPI(completionNonStreamHandler, completionStreamHandler, conversationService, authService, projectService, providerRegistryService, providerModelService, inferenceProvider)
@@ -101,11 +118,9 @@ func CreateApplication() (*Application, error) {
 	serperMCP := mcpimpl.NewSerperMCP(serperService)
 	convMCPAPI := conv.NewConvMCPAPI(authService, serperMCP)
 	convChatRoute := conv.NewConvChatRoute(authService, convCompletionAPI, convMCPAPI)
-	workspaceRepository := workspacerepo.NewWorkspaceGormRepository(transactionDatabase)
-	workspaceService := workspace.NewWorkspaceService(workspaceRepository, conversationRepository)
 	workspaceRoute := conv.NewWorkspaceRoute(authService, workspaceService)
 	conversationAPI := conversations.NewConversationAPI(conversationService, authService, workspaceService)
-	modelAPI := modelroute.NewModelAPI(inferenceProvider, authService, projectService, providerRegistryService, providerModelService)
+	modelAPI := modelroute.NewModelAPI(inferenceProvider, authService, projectService, providerRegistryService, providerModelService, modelCatalogService)
 	providersAPI := modelroute.NewProvidersAPI(authService, projectService, providerRegistryService)
 	mcpapi := mcp.NewMCPAPI(serperMCP, authService)
 	googleAuthAPI := google.NewGoogleAuthAPI(userService, authService)
@@ -116,9 +131,11 @@ func CreateApplication() (*Application, error) {
 	streamModelService := response.NewStreamModelService(responseModelService)
 	nonStreamModelService := response.NewNonStreamModelService(responseModelService)
 	responseRoute := responses.NewResponseRoute(responseModelService, authService, responseService, streamModelService, nonStreamModelService)
-	v1Route := v1.NewV1Route(organizationRoute, chatRoute, convChatRoute, workspaceRoute, conversationAPI, modelAPI, providersAPI, mcpapi, authRoute, responseRoute)
-	httpServer := http.NewHttpServer(v1Route)
-	cronService := cron.NewCronService()
+	adminProviderRoute := admin.NewAdminProviderRoute(authService, providerRegistryService, organizationService, projectService)
+	v1Route := v1.NewV1Route(organizationRoute, chatRoute, convChatRoute, workspaceRoute, conversationAPI, modelAPI, providersAPI, mcpapi, authRoute, responseRoute, adminProviderRoute)
+	httpServer := http.NewHttpServer(v1Route, inferenceProvider)
+	providerHealthChecker := model.NewProviderHealthChecker(providerRepository, inferenceProvider, webhookService)
+	cronService := cron.NewCronService(providerHealthChecker)
 	application := &Application{
 		HttpServer:  httpServer,
 		CronService: cronService,
@@ -146,8 +163,11 @@ func CreateDataInitializer() (*DataInitializer, error) {
 	providerModelService := model.NewProviderModelService(providerModelRepository)
 	modelCatalogRepository := modelrepo.NewModelCatalogGormRepository(transactionDatabase)
 	modelCatalogService := model.NewModelCatalogService(modelCatalogRepository)
-	providerRegistryService := model.NewProviderRegistryService(providerRepository, providerModelService, modelCatalogService)
 	inferenceProvider := inference.NewInferenceProvider()
+	modelAliasRepository := modelrepo.NewModelAliasGormRepository(transactionDatabase)
+	modelAliasService := model.NewModelAliasService(modelAliasRepository)
+	webhookService := webhook.NewWebhookService(organizationService)
+	providerRegistryService := model.NewProviderRegistryService(providerRepository, providerModelService, modelCatalogService, inferenceProvider, inferenceProvider, modelAliasService, organizationService, webhookService, redisCacheService, transactionDatabase)
 	dataInitializer := &DataInitializer{
 		authService:         authService,
 		providerRegistry:    providerRegistryService,
@@ -23,14 +23,75 @@ type EnvironmentVariable struct {
 	DB_POSTGRESQL_READ1_DSN     string
 	APIKEY_SECRET               string
 	MODEL_PROVIDER_SECRET       string
-	ALLOWED_CORS_HOSTS          []string
-	SMTP_HOST                   string
-	SMTP_PORT                   int
-	SMTP_USERNAME               string
-	SMTP_PASSWORD               string
-	SMTP_SENDER_EMAIL           string
-	INVITE_REDIRECT_URL         string
-	ORGANIZATION_ADMIN_EMAILS   []string
+	// Reject custom providers that reuse a base URL already registered in the same scope.
+	MODEL_PROVIDER_UNIQUE_CUSTOM_BASE_URL bool
+	// Hosts provider base URLs may point at although they are not publicly routable (loopback,
+	// private or link-local addresses, localhost names): hostnames, IP addresses or CIDR prefixes.
+	MODEL_PROVIDER_ALLOWED_PRIVATE_HOSTS []string
+	// Validate json_object streams once complete and send an error event instead of [DONE] when malformed.
+	CHAT_STREAM_JSON_VALIDATION bool
+	// With JSON stream validation on, also send the parsed object in a final event.
+	CHAT_STREAM_JSON_EMIT_OBJECT bool
+	// Seconds between SSE keep-alive comments sent while a stream waits for its first upstream chunk; zero disables them.
+	CHAT_STREAM_HEARTBEAT_SECONDS int
+	// Longest Retry-After, as a Go duration, waited out before retrying a rate-limited non-streaming completion once; defaults to 10s, 0 disables the retry.
+	CHAT_RATE_LIMIT_RETRY_MAX_WAIT string
+	// Provider circuit breaker: consecutive upstream failures within the window open the circuit for the cooldown.
+	PROVIDER_CIRCUIT_FAILURE_THRESHOLD int
+	PROVIDER_CIRCUIT_WINDOW_SECONDS    int
+	PROVIDER_CIRCUIT_COOLDOWN_SECONDS  int
+	// Number of provider HTTP clients kept for connection reuse, and idle connections each keeps per upstream host.
+	PROVIDER_CLIENT_CACHE_SIZE       int
+	PROVIDER_MAX_IDLE_CONNS_PER_HOST int
+	// Go durations bounding how long a provider client waits to connect (dial and TLS handshake) and for a whole request.
+	PROVIDER_CONNECT_TIMEOUT string
+	PROVIDER_REQUEST_TIMEOUT string
+	// Largest request body accepted by chat completions and embeddings, in bytes; defaults to 10MB.
+	MAX_REQUEST_BODY_BYTES int
+	// Most inputs embedded by one /v1/embeddings request; defaults to 2048.
+	MAX_EMBEDDING_INPUTS int
+	// Gzip JSON responses of /v1/models and non-streaming chat completions from RESPONSE_COMPRESSION_MIN_BYTES up (default 1024).
+	ENABLE_RESPONSE_COMPRESSION    bool
+	RESPONSE_COMPRESSION_MIN_BYTES int
+	// Maximum number of providers a chat completion is attempted against before giving up.
+	MODEL_PROVIDER_FALLBACK_ATTEMPTS int
+	// Percentage of served completions replayed against shadow providers.
+	MODEL_PROVIDER_SHADOW_SAMPLE_PERCENT int
+	// Default monthly inference spend cap per organization in micro-USD; zero disables the cap.
+	MONTHLY_SPEND_LIMIT_MICRO_USD int
+	// Cron schedule of the provider health-check sweep; defaults to every 5 minutes.
+	PROVIDER_HEALTH_CHECK_SCHEDULE string
+	// How long the providers accessible to an organization and the model keys they serve are
+	// cached, as a Go duration; defaults to 30s.
+	MODELS_CACHE_TTL string
+	// Cron schedule of the job that reloads these environment variables; defaults to every minute.
+	MODELS_REFRESH_CRON string
+	// Longest random delay, as a Go duration, before each cron job and the startup model warmup run,
+	// spreading replicas' upstream calls; defaults to 20s, 0 disables it.
+	CRON_JITTER_WINDOW string
+	// Log the structured per-request chat completion line at info level rather than debug.
+	COMPLETION_REQUEST_LOG_VERBOSE bool
+	// Maximum number of workspaces a user may own; defaults to 100.
+	MAX_WORKSPACES_PER_USER int
+	// Maximum length of a workspace instruction in characters; defaults to 8000.
+	MAX_WORKSPACE_INSTRUCTION_LENGTH int
+	// Moderate user input through MODERATION_API_URL (an OpenAI-compatible /moderations endpoint)
+	// before sending it to providers that do not moderate upstream.
+	ENABLE_INPUT_MODERATION   bool
+	MODERATION_API_URL        string
+	MODERATION_API_KEY        string
+	MODERATION_MODEL          string
+	ALLOWED_CORS_HOSTS        []string
+	SMTP_HOST                 string
+	SMTP_PORT                 int
+	SMTP_USERNAME             string
+	SMTP_PASSWORD             string
+	SMTP_SENDER_EMAIL         string
+	INVITE_REDIRECT_URL       string
+	ORGANIZATION_ADMIN_EMAILS []string
+	// Comma-separated emails of platform admins, who may call the cross-organization /v1/admin
+	// endpoints. Organization owners are not platform admins unless listed here.
+	PLATFORM_ADMIN_EMAILS []string
 	// Redis configuration
 	REDIS_URL      string
 	REDIS_PASSWORD string
@@ -328,7 +328,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Conversation not found or user not found",
+                        "description": "Conversation, user or parent_message_id not found",
                         "schema": {
                             "$ref": "#/definitions/menlo_ai_jan-api-gateway_app_interfaces_http_responses.ErrorResponse"
                         }